@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/actions"
@@ -460,7 +461,9 @@ func (g *gnmiAction) runSet(ctx context.Context, tc *types.TargetConfig, in *act
 		return nil, fmt.Errorf("target %q SetRequest failed: %v", t.Config.Name, err)
 	}
 	defer t.Close()
+	start := time.Now()
 	resp, err := t.Set(ctx, req)
+	types.AuditSet(tc.Name, req, resp, err, start)
 	if err != nil {
 		return nil, err
 	}
@@ -1225,7 +1225,6 @@ func TimestampNow() func(msg proto.Message) error {
 	return Timestamp(time.Now().UnixNano())
 }
 
-
 // Atomic sets the .Atomic field in a gnmi.Notification message
 func Atomic(b bool) func(msg proto.Message) error {
 	return func(msg proto.Message) error {
@@ -0,0 +1,139 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/utils"
+)
+
+const (
+	defaultAnomaliesMaxEntries = 100000
+	// minimum interval between two logged anomalies of a target
+	anomalyLogInterval = 10 * time.Second
+)
+
+// anomalyDetector tracks the last timestamp of each path of a target
+// and detects the updates with a timestamp older than or equal to it.
+// It is used by a single target listener, only its counters are read concurrently.
+type anomalyDetector struct {
+	drop       bool
+	maxEntries int
+	last       map[string]int64
+	// set once the max entries warning is logged
+	full bool
+
+	lastLog    time.Time
+	suppressed int
+
+	outOfOrder uint64
+	duplicates uint64
+	dropped    uint64
+}
+
+// newAnomalyDetector returns the timestamps anomaly detector of target t,
+// or nil if --detect-anomalies is not set.
+func (a *App) newAnomalyDetector(t *target.Target) *anomalyDetector {
+	if !a.Config.LocalFlags.SubscribeDetectAnomalies {
+		return nil
+	}
+	d := &anomalyDetector{
+		drop:       a.Config.LocalFlags.SubscribeDropOutOfOrder,
+		maxEntries: a.Config.LocalFlags.SubscribeAnomaliesMaxEntries,
+		last:       make(map[string]int64),
+	}
+	a.wireStatsLock.Lock()
+	a.anomalyDetectors[t.Config.Name] = d
+	a.wireStatsLock.Unlock()
+	return d
+}
+
+// checkTimestamps compares the timestamp of the updates in rsp of target tName with the previous
+// one of their path. With --drop-out-of-order, the updates not newer than the previous one are removed
+// from rsp. It returns false if nothing is left in rsp to be exported.
+func (a *App) checkTimestamps(d *anomalyDetector, tName string, rsp *target.SubscribeResponse, now time.Time) bool {
+	if d == nil {
+		return true
+	}
+	n := rsp.Response.GetUpdate()
+	if n == nil || n.GetTimestamp() == 0 {
+		return true
+	}
+	ts := n.GetTimestamp()
+	prefix := utils.GnmiPathToXPath(n.GetPrefix(), false)
+	kept := make([]*gnmi.Update, 0, len(n.GetUpdate()))
+	for _, u := range n.GetUpdate() {
+		key := prefix + "|" + utils.GnmiPathToXPath(u.GetPath(), false)
+		prev, ok := d.last[key]
+		if !ok {
+			if len(d.last) >= d.maxEntries {
+				if !d.full {
+					d.full = true
+					a.Logger.Printf("target %q: anomalies detection max entries %d reached, new paths are not tracked", tName, d.maxEntries)
+				}
+			} else {
+				d.last[key] = ts
+			}
+			kept = append(kept, u)
+			continue
+		}
+		if ts > prev {
+			d.last[key] = ts
+			kept = append(kept, u)
+			continue
+		}
+		kind := "out-of-order"
+		if ts == prev {
+			kind = "duplicate"
+			atomic.AddUint64(&d.duplicates, 1)
+		} else {
+			atomic.AddUint64(&d.outOfOrder, 1)
+		}
+		d.logAnomaly(a, now, "target %q: subscription %q: %s timestamp %d for path %q, previous one is %d",
+			tName, rsp.SubscriptionName, kind, ts, key, prev)
+		if d.drop {
+			atomic.AddUint64(&d.dropped, 1)
+			continue
+		}
+		kept = append(kept, u)
+	}
+	n.Update = kept
+	return len(n.GetUpdate()) > 0 || len(n.GetDelete()) > 0
+}
+
+// logAnomaly logs an anomaly at most once per anomalyLogInterval,
+// with the number of anomalies not logged since the previous one.
+func (d *anomalyDetector) logAnomaly(a *App, now time.Time, format string, args ...interface{}) {
+	if now.Sub(d.lastLog) < anomalyLogInterval {
+		d.suppressed++
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if d.suppressed > 0 {
+		msg = fmt.Sprintf("%s (%d more since the last log)", msg, d.suppressed)
+	}
+	a.Logger.Print(msg)
+	d.lastLog = now
+	d.suppressed = 0
+}
+
+// summary returns the anomalies counts printed with --stats.
+func (d *anomalyDetector) summary() string {
+	s := fmt.Sprintf("%d out-of-order and %d duplicate timestamp(s)",
+		atomic.LoadUint64(&d.outOfOrder), atomic.LoadUint64(&d.duplicates))
+	if d.drop {
+		s += fmt.Sprintf(", %d update(s) dropped", atomic.LoadUint64(&d.dropped))
+	}
+	return s
+}
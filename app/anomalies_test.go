@@ -0,0 +1,91 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestCheckTimestamps(t *testing.T) {
+	sc := &types.SubscriptionConfig{Name: "sub1", Mode: "stream"}
+	type update struct {
+		p  string
+		ts int64
+	}
+	tests := map[string]struct {
+		drop           bool
+		maxEntries     int
+		updates        []update
+		wantKept       []int64
+		wantOutOfOrder uint64
+		wantDuplicates uint64
+	}{
+		"monotonic": {
+			updates:  []update{{"/a", 1}, {"/a", 2}, {"/b", 1}, {"/a", 3}},
+			wantKept: []int64{1, 2, 1, 3},
+		},
+		"detect_only": {
+			updates:        []update{{"/a", 2}, {"/a", 1}, {"/a", 2}, {"/a", 2}},
+			wantKept:       []int64{2, 1, 2, 2},
+			wantOutOfOrder: 1,
+			wantDuplicates: 2,
+		},
+		"drop": {
+			drop:           true,
+			updates:        []update{{"/a", 2}, {"/a", 1}, {"/b", 1}, {"/a", 2}, {"/a", 3}},
+			wantKept:       []int64{2, 1, 3},
+			wantOutOfOrder: 1,
+			wantDuplicates: 1,
+		},
+		"max_entries": {
+			drop:       true,
+			maxEntries: 1,
+			// /b is not tracked
+			updates:  []update{{"/a", 2}, {"/b", 2}, {"/b", 1}, {"/a", 1}},
+			wantKept: []int64{2, 2, 1},
+			// only /a is checked
+			wantOutOfOrder: 1,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.LocalFlags.SubscribeDetectAnomalies = true
+			a.Config.LocalFlags.SubscribeDropOutOfOrder = item.drop
+			a.Config.LocalFlags.SubscribeAnomaliesMaxEntries = defaultAnomaliesMaxEntries
+			if item.maxEntries > 0 {
+				a.Config.LocalFlags.SubscribeAnomaliesMaxEntries = item.maxEntries
+			}
+			d := a.newAnomalyDetector(&target.Target{Config: &types.TargetConfig{Name: "t1"}})
+			kept := make([]int64, 0)
+			now := time.Now()
+			for _, u := range item.updates {
+				rsp := downsampleUpdate(t, sc, u.p, 1)
+				rsp.Response.GetUpdate().Timestamp = u.ts
+				if a.checkTimestamps(d, "t1", rsp, now) {
+					kept = append(kept, u.ts)
+				}
+			}
+			if !reflect.DeepEqual(kept, item.wantKept) || d.outOfOrder != item.wantOutOfOrder || d.duplicates != item.wantDuplicates {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: kept %v, %d out-of-order, %d duplicates", item.wantKept, item.wantOutOfOrder, item.wantDuplicates)
+				t.Logf("     got: kept %v, %d out-of-order, %d duplicates", kept, d.outOfOrder, d.duplicates)
+				t.Fail()
+			}
+		})
+	}
+}
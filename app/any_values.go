@@ -0,0 +1,159 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// anyResolver resolves the type URL of Any values against the message types
+// loaded from the --proto-file descriptors, then against the global proto registry.
+type anyResolver struct {
+	types *protoregistry.Types
+}
+
+func (r *anyResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if r.types != nil {
+		if mt, err := r.types.FindMessageByName(name); err == nil {
+			return mt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindMessageByName(name)
+}
+
+func (r *anyResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	if r.types != nil {
+		if mt, err := r.types.FindMessageByURL(url); err == nil {
+			return mt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindMessageByURL(url)
+}
+
+func (r *anyResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if r.types != nil {
+		if xt, err := r.types.FindExtensionByName(field); err == nil {
+			return xt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r *anyResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if r.types != nil {
+		if xt, err := r.types.FindExtensionByNumber(message, field); err == nil {
+			return xt, nil
+		}
+	}
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+// loadAnyTypes parses the --proto-file descriptors and registers their message types,
+// including the ones of their imports, to decode the Any values.
+func (a *App) loadAnyTypes() error {
+	if len(a.Config.ProtoFile) == 0 || a.Config.AnyAsBytes {
+		return nil
+	}
+	p := protoparse.Parser{ImportPaths: a.Config.ProtoDir}
+	fds, err := p.ParseFiles(a.Config.ProtoFile...)
+	if err != nil {
+		return fmt.Errorf("failed to parse proto files: %v", err)
+	}
+	types := new(protoregistry.Types)
+	seen := make(map[string]struct{})
+	for _, fd := range fds {
+		registerFileMessages(types, fd, seen)
+	}
+	a.anyTypes = &anyResolver{types: types}
+	return nil
+}
+
+func registerFileMessages(types *protoregistry.Types, fd *desc.FileDescriptor, seen map[string]struct{}) {
+	if _, ok := seen[fd.GetName()]; ok {
+		return
+	}
+	seen[fd.GetName()] = struct{}{}
+	for _, dep := range fd.GetDependencies() {
+		registerFileMessages(types, dep, seen)
+	}
+	registerMessages(types, fd.UnwrapFile().Messages())
+}
+
+func registerMessages(types *protoregistry.Types, mds protoreflect.MessageDescriptors) {
+	for i := 0; i < mds.Len(); i++ {
+		md := mds.Get(i)
+		if md.IsMapEntry() {
+			continue
+		}
+		// a type already registered by another file is kept
+		_ = types.RegisterMessage(dynamicpb.NewMessageType(md))
+		registerMessages(types, md.Messages())
+	}
+}
+
+// decodeAnyValues replaces, in place, the Any values of the notification
+// with their JSON rendering.
+// The values of an unknown type are rendered as their type URL and base64 encoded payload.
+func (a *App) decodeAnyValues(n *gnmi.Notification) {
+	if n == nil || a.Config.AnyAsBytes {
+		return
+	}
+	for _, upd := range n.GetUpdate() {
+		anyVal, ok := upd.GetVal().GetValue().(*gnmi.TypedValue_AnyVal)
+		if !ok || anyVal.AnyVal == nil {
+			continue
+		}
+		b, err := a.anyValueJSON(anyVal.AnyVal)
+		if err != nil {
+			a.Logger.Printf("failed to decode Any value of type %q: %v", anyVal.AnyVal.GetTypeUrl(), err)
+			continue
+		}
+		upd.Val.Value = &gnmi.TypedValue_JsonVal{JsonVal: b}
+	}
+}
+
+// anyValueJSON renders the Any value v as JSON.
+// The message fields of a known type are added next to the "@type" key.
+func (a *App) anyValueJSON(v *anypb.Any) ([]byte, error) {
+	r := a.anyTypes
+	if r == nil {
+		r = &anyResolver{}
+	}
+	_, err := r.FindMessageByURL(v.GetTypeUrl())
+	if errors.Is(err, protoregistry.NotFound) {
+		a.warnUnknownAnyType(v.GetTypeUrl())
+		return json.Marshal(map[string]interface{}{
+			"@type": v.GetTypeUrl(),
+			"value": v.GetValue(),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Resolver: r}.Marshal(v)
+}
+
+// warnUnknownAnyType prints, once per type URL, a hint to load the message descriptors.
+func (a *App) warnUnknownAnyType(url string) {
+	if _, loaded := a.anyTypeWarnings.LoadOrStore(url, struct{}{}); loaded {
+		return
+	}
+	a.logWarning(fmt.Sprintf("cannot decode Any value of unknown type %q, load its descriptors with --proto-file", url))
+}
@@ -0,0 +1,152 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const anyTestProto = `syntax = "proto3";
+package gnmic.test;
+
+message Counters {
+  string name = 1;
+  uint64 in_octets = 2;
+}
+`
+
+// newAnyTestApp returns an App with the gnmic.test.Counters message type
+// loaded from a proto file, and an Any value of that type.
+func newAnyTestApp(t *testing.T) (*App, *anypb.Any) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "counters.proto"), []byte(anyTestProto), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	a.out = new(bytes.Buffer)
+	a.Config.ProtoDir = []string{dir}
+	a.Config.ProtoFile = []string{"counters.proto"}
+	err = a.loadAnyTypes()
+	if err != nil {
+		t.Fatalf("failed to load proto files: %v", err)
+	}
+	mt, err := a.anyTypes.FindMessageByName("gnmic.test.Counters")
+	if err != nil {
+		t.Fatalf("message type not registered: %v", err)
+	}
+	m := mt.New()
+	fields := m.Descriptor().Fields()
+	m.Set(fields.ByName("name"), protoreflect.ValueOfString("ethernet-1/1"))
+	m.Set(fields.ByName("in_octets"), protoreflect.ValueOfUint64(42))
+	b, err := proto.Marshal(m.Interface())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, &anypb.Any{TypeUrl: "type.googleapis.com/gnmic.test.Counters", Value: b}
+}
+
+func anyTestResponse(v *anypb.Any) *gnmi.SubscribeResponse {
+	return &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 1,
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counters"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_AnyVal{AnyVal: v}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDecodeAnyValuesSubscribeOutput(t *testing.T) {
+	a, v := newAnyTestApp(t)
+	fileName := filepath.Join(t.TempDir(), "out.json")
+	o := outputs.Outputs["file"]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := o.Init(ctx, "test", map[string]interface{}{
+		"filename": fileName,
+		"format":   "event",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Outputs["test"] = o
+
+	a.Export(ctx, anyTestResponse(v), outputs.Meta{"source": "r1"})
+	o.Close()
+
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evs []map[string]interface{}
+	err = json.Unmarshal(b, &evs)
+	if err != nil {
+		t.Fatalf("failed to decode output %q: %v", string(b), err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d: %s", len(evs), string(b))
+	}
+	values, _ := evs[0]["values"].(map[string]interface{})
+	exp := map[string]interface{}{
+		"/counters/@type":    "type.googleapis.com/gnmic.test.Counters",
+		"/counters/name":     "ethernet-1/1",
+		"/counters/inOctets": "42",
+	}
+	for k, ev := range exp {
+		if values[k] != ev {
+			t.Errorf("expected value %q to be %v, got %v: %s", k, ev, values[k], string(b))
+		}
+	}
+}
+
+func TestDecodeAnyValuesUnknownType(t *testing.T) {
+	a, v := newAnyTestApp(t)
+	v.TypeUrl = "type.googleapis.com/gnmic.test.Unknown"
+	rsp := anyTestResponse(v)
+	a.decodeAnyValues(rsp.GetUpdate())
+	jv := rsp.GetUpdate().GetUpdate()[0].GetVal().GetJsonVal()
+	var m map[string]interface{}
+	err := json.Unmarshal(jv, &m)
+	if err != nil {
+		t.Fatalf("unexpected value %q: %v", string(jv), err)
+	}
+	if m["@type"] != v.TypeUrl {
+		t.Errorf("expected type URL %q, got %v", v.TypeUrl, m["@type"])
+	}
+	if _, ok := m["value"].(string); !ok {
+		t.Errorf("expected a base64 encoded payload, got %v", m["value"])
+	}
+
+	a.Config.AnyAsBytes = true
+	rsp = anyTestResponse(v)
+	a.decodeAnyValues(rsp.GetUpdate())
+	if rsp.GetUpdate().GetUpdate()[0].GetVal().GetAnyVal() == nil {
+		t.Errorf("expected the Any value to be left undecoded with --any-as-bytes")
+	}
+}
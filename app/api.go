@@ -40,14 +40,22 @@ func (a *App) newAPIServer() (*http.Server, error) {
 		a.reg.MustRegister(collectors.NewGoCollector())
 		a.reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 		a.reg.MustRegister(subscribeResponseReceivedCounter)
+		a.registerMetrics(&wireStatsCollector{a: a})
 		go a.startClusterMetrics()
 	}
+	if a.Config.APIServer.EnableRPCs && a.Config.APIServer.Token == "" && !isLoopbackAddress(a.Config.APIServer.Address) {
+		return nil, fmt.Errorf("the RPC endpoints require an api-server token or a loopback address, got %q", a.Config.APIServer.Address)
+	}
 	s := &http.Server{
 		Addr:         a.Config.APIServer.Address,
-		Handler:      a.router,
+		Handler:      a.tokenMiddleware(a.router),
 		ReadTimeout:  a.Config.APIServer.Timeout / 2,
 		WriteTimeout: a.Config.APIServer.Timeout / 2,
 	}
+	if a.Config.APIServer.EnableRPCs {
+		// allow for the target dial and the RPC itself
+		s.WriteTimeout += 2*a.Config.Timeout + a.Config.APIServer.SubscribeOnceTimeout
+	}
 
 	if tlscfg != nil {
 		s.TLSConfig = tlscfg
@@ -168,6 +176,24 @@ func (a *App) handleTargetsGet(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(APIErrors{Errors: []string{"no targets found"}})
 }
 
+func (a *App) handleTargetsStatusGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tss := a.targetsStatusTable()
+	if id == "" {
+		a.handlerCommonGet(w, r, tss)
+		return
+	}
+	for _, ts := range tss {
+		if ts.Name == id {
+			a.handlerCommonGet(w, r, ts)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
+}
+
 func (a *App) handleTargetsPost(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
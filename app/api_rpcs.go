@@ -0,0 +1,436 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+// apiRPCRequest is the body of the RPC endpoints requests,
+// its fields mirror the CLI flags.
+type apiRPCRequest struct {
+	Targets  []string       `json:"targets,omitempty"`
+	Prefix   string         `json:"prefix,omitempty"`
+	Target   string         `json:"target,omitempty"`
+	Encoding string         `json:"encoding,omitempty"`
+	Paths    []string       `json:"paths,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Models   []string       `json:"models,omitempty"`
+	Delete   []string       `json:"delete,omitempty"`
+	Update   []*apiSetValue `json:"update,omitempty"`
+	Replace  []*apiSetValue `json:"replace,omitempty"`
+	// subscribe-once max duration
+	Timeout string `json:"timeout,omitempty"`
+}
+
+type apiSetValue struct {
+	Path  string      `json:"path,omitempty"`
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type apiRPCResult struct {
+	Responses []json.RawMessage `json:"responses,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// APIPreRunE runs before the api command
+func (a *App) APIPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	a.createCollectorDialOpts()
+	return nil
+}
+
+// APIRunE starts an HTTP server exposing the Capabilities, Get, Set
+// and Subscribe ONCE RPCs.
+func (a *App) APIRunE(cmd *cobra.Command, args []string) error {
+	tcs, err := a.Config.GetTargets()
+	if err != nil && !errors.Is(err, config.ErrNoTargetsFound) {
+		return fmt.Errorf("failed reading targets config: %v", err)
+	}
+	err = a.Config.SetMissingCredentials(tcs)
+	if err != nil {
+		return err
+	}
+	a.Config.SetDefaultAPIServer()
+	a.Config.APIServer.EnableRPCs = true
+	s, err := a.newAPIServer()
+	if err != nil {
+		return fmt.Errorf("failed to create a new API server: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		a.Logger.Printf("starting API server on %q", s.Addr)
+		if s.TLSConfig != nil {
+			errCh <- s.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- s.ListenAndServe()
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-a.ctx.Done():
+		ctx, cancel := context.WithTimeout(context.Background(), a.Config.APIServer.Timeout)
+		defer cancel()
+		return s.Shutdown(ctx)
+	}
+}
+
+func (a *App) rpcRoutes(r *mux.Router) {
+	r.HandleFunc("/capabilities", a.handleCapabilitiesPost).Methods(http.MethodPost)
+	r.HandleFunc("/get", a.handleGetPost).Methods(http.MethodPost)
+	r.HandleFunc("/set", a.handleSetPost).Methods(http.MethodPost)
+	r.HandleFunc("/subscribe-once", a.handleSubscribeOncePost).Methods(http.MethodPost)
+}
+
+func (a *App) tokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.Config.APIServer == nil || a.Config.APIServer.Token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.Config.APIServer.Token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{"unauthorized"}})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *App) handleCapabilitiesPost(w http.ResponseWriter, r *http.Request) {
+	a.handleRPC(w, r, func(ctx context.Context, tc *types.TargetConfig, _ *apiRPCRequest) ([]proto.Message, error) {
+		rsp, err := a.ClientCapabilities(ctx, tc)
+		if err != nil {
+			return nil, err
+		}
+		return []proto.Message{rsp}, nil
+	})
+}
+
+func (a *App) handleGetPost(w http.ResponseWriter, r *http.Request) {
+	a.handleRPC(w, r, func(ctx context.Context, tc *types.TargetConfig, req *apiRPCRequest) ([]proto.Message, error) {
+		getReq, err := req.getRequest(a.Config.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		rsp, err := a.ClientGet(ctx, tc, getReq)
+		if err != nil {
+			return nil, err
+		}
+		return []proto.Message{rsp}, nil
+	})
+}
+
+func (a *App) handleSetPost(w http.ResponseWriter, r *http.Request) {
+	if a.Config.ReadOnly {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{types.ErrReadOnly.Error()}})
+		return
+	}
+	a.handleRPC(w, r, func(ctx context.Context, tc *types.TargetConfig, req *apiRPCRequest) ([]proto.Message, error) {
+		setReq, err := req.setRequest(a.Config.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		rsp, err := a.ClientSet(ctx, tc, setReq)
+		if err != nil {
+			return nil, err
+		}
+		return []proto.Message{rsp}, nil
+	})
+}
+
+func (a *App) handleSubscribeOncePost(w http.ResponseWriter, r *http.Request) {
+	a.handleRPC(w, r, func(ctx context.Context, tc *types.TargetConfig, req *apiRPCRequest) ([]proto.Message, error) {
+		subReq, err := req.subscribeOnceRequest(a.Config.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		timeout := a.Config.APIServer.SubscribeOnceTimeout
+		if req.Timeout != "" {
+			d, err := time.ParseDuration(req.Timeout)
+			if err != nil {
+				return nil, err
+			}
+			if d < timeout {
+				timeout = d
+			}
+		}
+		a.operLock.Lock()
+		t, err := a.initTarget(tc)
+		a.operLock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		a.operLock.RLock()
+		err = a.CreateGNMIClient(ctx, t)
+		a.operLock.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		start := time.Now()
+		rsps, err := t.SubscribeOnce(ctx, subReq)
+		a.auditRPC(tc.Name, auditRPCSubscribe, subReq, nil, err, start)
+		if err != nil {
+			return nil, err
+		}
+		msgs := make([]proto.Message, 0, len(rsps))
+		for _, rsp := range rsps {
+			msgs = append(msgs, rsp)
+		}
+		return msgs, nil
+	})
+}
+
+type apiRPCFn func(context.Context, *types.TargetConfig, *apiRPCRequest) ([]proto.Message, error)
+
+// handleRPC decodes the request body, runs the RPC function
+// against each of the requested targets concurrently and writes
+// the per target results keyed by target name.
+func (a *App) handleRPC(w http.ResponseWriter, r *http.Request, fn apiRPCFn) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	req := new(apiRPCRequest)
+	if len(body) > 0 {
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+			return
+		}
+	}
+	tcs, adHoc, err := a.apiTargetsConfig(req.Targets)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	mo := &formatters.MarshalOptions{Format: formatJSON}
+	m := new(sync.Mutex)
+	results := make(map[string]*apiRPCResult, len(tcs))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(tcs))
+	for _, tc := range tcs {
+		if _, ok := adHoc[tc.Name]; ok {
+			a.acquireAPITarget(tc.Name)
+		}
+		go func(tc *types.TargetConfig) {
+			defer wg.Done()
+			res := new(apiRPCResult)
+			msgs, err := fn(r.Context(), tc, req)
+			if _, ok := adHoc[tc.Name]; ok {
+				a.releaseAPITarget(tc.Name)
+			}
+			if err != nil {
+				res.Error = err.Error()
+			}
+			for _, msg := range msgs {
+				b, err := mo.Marshal(msg, map[string]string{"source": tc.Name})
+				if err != nil {
+					res.Error = err.Error()
+					break
+				}
+				res.Responses = append(res.Responses, b)
+			}
+			m.Lock()
+			results[tc.Name] = res
+			m.Unlock()
+		}(tc)
+	}
+	wg.Wait()
+	b, err := json.Marshal(results)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	w.Write(b)
+}
+
+// apiTargetsConfig returns the configuration of the targets referenced in an API request,
+// and the names of the ad-hoc targets among them.
+// Unknown names are rejected, unless api-server/allow-ad-hoc-targets is set,
+// in which case they are used as target addresses with the global flags values.
+// If no target is referenced, all the configured targets are returned.
+func (a *App) apiTargetsConfig(names []string) (map[string]*types.TargetConfig, map[string]struct{}, error) {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	tcs := make(map[string]*types.TargetConfig)
+	if len(names) == 0 {
+		for n, tc := range a.Config.Targets {
+			tcs[n] = tc
+		}
+		if len(tcs) == 0 {
+			return nil, nil, errors.New("no targets found")
+		}
+		return tcs, nil, nil
+	}
+	adHoc := make(map[string]struct{})
+	for _, n := range names {
+		if tc, ok := a.Config.Targets[n]; ok {
+			tcs[n] = tc
+			continue
+		}
+		if !a.Config.APIServer.AllowAdHocTargets {
+			return nil, nil, fmt.Errorf("unknown target %q", n)
+		}
+		tc := &types.TargetConfig{Name: n, Address: n}
+		err := a.Config.SetTargetConfigDefaults(tc)
+		if err != nil {
+			return nil, nil, err
+		}
+		tcs[n] = tc
+		adHoc[n] = struct{}{}
+	}
+	return tcs, adHoc, nil
+}
+
+// acquireAPITarget counts an API request using the ad-hoc target name.
+func (a *App) acquireAPITarget(name string) {
+	a.apiTargetsLock.Lock()
+	defer a.apiTargetsLock.Unlock()
+	a.apiAdHocTargets[name]++
+}
+
+// releaseAPITarget closes the ad-hoc target name and removes it
+// from the targets once the last API request using it completes.
+func (a *App) releaseAPITarget(name string) {
+	a.apiTargetsLock.Lock()
+	defer a.apiTargetsLock.Unlock()
+	a.apiAdHocTargets[name]--
+	if a.apiAdHocTargets[name] > 0 {
+		return
+	}
+	delete(a.apiAdHocTargets, name)
+	a.operLock.Lock()
+	defer a.operLock.Unlock()
+	if t, ok := a.Targets[name]; ok {
+		delete(a.Targets, name)
+		t.Close()
+	}
+}
+
+// isLoopbackAddress returns true if the listen address addr is bound to a loopback interface.
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (r *apiRPCRequest) encoding(defEncoding string) string {
+	if r.Encoding != "" {
+		return r.Encoding
+	}
+	return defEncoding
+}
+
+func (r *apiRPCRequest) getRequest(defEncoding string) (*gnmi.GetRequest, error) {
+	if len(r.Paths) == 0 {
+		return nil, errors.New("no paths provided")
+	}
+	dataType := r.Type
+	if dataType == "" {
+		dataType = "ALL"
+	}
+	opts := []api.GNMIOption{
+		api.Encoding(r.encoding(defEncoding)),
+		api.DataType(dataType),
+		api.Prefix(r.Prefix),
+		api.Target(r.Target),
+	}
+	for _, p := range r.Paths {
+		opts = append(opts, api.Path(strings.TrimSpace(p)))
+	}
+	for _, m := range r.Models {
+		opts = append(opts, api.UseModel(m, "", ""))
+	}
+	return api.NewGetRequest(opts...)
+}
+
+func (r *apiRPCRequest) setRequest(defEncoding string) (*gnmi.SetRequest, error) {
+	if len(r.Delete)+len(r.Update)+len(r.Replace) == 0 {
+		return nil, errors.New("no paths provided")
+	}
+	opts := []api.GNMIOption{
+		api.Prefix(r.Prefix),
+		api.Target(r.Target),
+	}
+	for _, p := range r.Delete {
+		opts = append(opts, api.Delete(strings.TrimSpace(p)))
+	}
+	for _, u := range r.Update {
+		opts = append(opts, api.Update(u.options(r.encoding(defEncoding))...))
+	}
+	for _, u := range r.Replace {
+		opts = append(opts, api.Replace(u.options(r.encoding(defEncoding))...))
+	}
+	return api.NewSetRequest(opts...)
+}
+
+func (r *apiRPCRequest) subscribeOnceRequest(defEncoding string) (*gnmi.SubscribeRequest, error) {
+	if len(r.Paths) == 0 {
+		return nil, errors.New("no paths provided")
+	}
+	opts := []api.GNMIOption{
+		api.Encoding(r.encoding(defEncoding)),
+		api.Prefix(r.Prefix),
+		api.Target(r.Target),
+		api.SubscriptionListModeONCE(),
+	}
+	for _, p := range r.Paths {
+		opts = append(opts, api.Subscription(api.Path(strings.TrimSpace(p))))
+	}
+	return api.NewSubscribeRequest(opts...)
+}
+
+func (v *apiSetValue) options(defEncoding string) []api.GNMIOption {
+	enc := v.Type
+	if enc == "" {
+		enc = defEncoding
+	}
+	return []api.GNMIOption{
+		api.Path(strings.TrimSpace(v.Path)),
+		api.Value(v.Value, enc),
+	}
+}
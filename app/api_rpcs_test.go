@@ -0,0 +1,221 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// apiTestServer is a gNMI server replying to the Get requests,
+// its Subscribe RPCs never sync and return when cancelled.
+type apiTestServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *apiTestServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{{Timestamp: 42}}}, nil
+}
+
+func (s *apiTestServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func startAPITestServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, &apiTestServer{})
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+	return l.Addr().String()
+}
+
+func TestAPIRPCs(t *testing.T) {
+	addr := startAPITestServer(t)
+	tests := map[string]struct {
+		token    string
+		header   string
+		readOnly bool
+		adHoc    bool
+		endpoint string
+		body     string
+		// maximum duration of the request
+		maxElapsed time.Duration
+		wantCode   int
+		// expected in the response body
+		wantBody []string
+	}{
+		"missing_token": {
+			token:    "s3cret",
+			endpoint: "/get",
+			body:     `{"targets": ["t1"], "paths": ["/system"]}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: []string{"unauthorized"},
+		},
+		"wrong_token": {
+			token:    "s3cret",
+			header:   "Bearer wrong",
+			endpoint: "/get",
+			body:     `{"targets": ["t1"], "paths": ["/system"]}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: []string{"unauthorized"},
+		},
+		"valid_token": {
+			token:    "s3cret",
+			header:   "Bearer s3cret",
+			endpoint: "/get",
+			body:     `{"targets": ["t1"], "paths": ["/system"]}`,
+			wantCode: http.StatusOK,
+			wantBody: []string{`"t1":{"responses"`, `"source":"t1"`},
+		},
+		"read_only_set": {
+			readOnly: true,
+			endpoint: "/set",
+			body:     `{"targets": ["t1"], "update": [{"path": "/system/name", "value": "r1"}]}`,
+			wantCode: http.StatusForbidden,
+			wantBody: []string{types.ErrReadOnly.Error()},
+		},
+		"unknown_target": {
+			endpoint: "/get",
+			body:     `{"targets": ["` + addr + `"], "paths": ["/system"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: []string{"unknown target"},
+		},
+		"ad_hoc_target": {
+			adHoc:    true,
+			endpoint: "/get",
+			body:     `{"targets": ["` + addr + `"], "paths": ["/system"]}`,
+			wantCode: http.StatusOK,
+			wantBody: []string{`"source":"` + addr + `"`},
+		},
+		"subscribe_once_timeout_cap": {
+			endpoint:   "/subscribe-once",
+			body:       `{"targets": ["t1"], "paths": ["/system"], "timeout": "1m"}`,
+			maxElapsed: 2 * time.Second,
+			wantCode:   http.StatusOK,
+			wantBody:   []string{`"t1":{"error"`, "DeadlineExceeded"},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			defer a.Cfn()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Insecure = true
+			a.Config.Encoding = "json"
+			a.Config.Timeout = 5 * time.Second
+			a.Config.ReadOnly = item.readOnly
+			a.Config.APIServer = &config.APIServer{
+				Token:                item.token,
+				EnableRPCs:           true,
+				SubscribeOnceTimeout: 200 * time.Millisecond,
+				AllowAdHocTargets:    item.adHoc,
+			}
+			insecure := true
+			a.Config.Targets = map[string]*types.TargetConfig{
+				"t1": {Name: "t1", Address: addr, Insecure: &insecure, Timeout: 5 * time.Second},
+			}
+			a.createCollectorDialOpts()
+			a.routes()
+			srv := httptest.NewServer(a.tokenMiddleware(a.router))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1"+item.endpoint, strings.NewReader(item.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.header != "" {
+				req.Header.Set("Authorization", item.header)
+			}
+			start := time.Now()
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			defer rsp.Body.Close()
+			elapsed := time.Since(start)
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rsp.StatusCode != item.wantCode {
+				t.Errorf("failed at item %q: expected status %d, got %d: %s", name, item.wantCode, rsp.StatusCode, b)
+			}
+			if item.maxElapsed > 0 && elapsed > item.maxElapsed {
+				t.Errorf("failed at item %q: request took %s, expected less than %s", name, elapsed, item.maxElapsed)
+			}
+			// the JSON output and the expected strings are compared without spaces
+			got := stripSpaces(string(b))
+			for _, s := range item.wantBody {
+				if !strings.Contains(got, stripSpaces(s)) {
+					t.Errorf("failed at item %q: expected %q in the response body: %s", name, s, b)
+				}
+			}
+			if item.adHoc {
+				a.operLock.RLock()
+				_, ok := a.Targets[addr]
+				a.operLock.RUnlock()
+				if ok {
+					t.Errorf("failed at item %q: the ad-hoc target was not removed after the request", name)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIServerListener(t *testing.T) {
+	tests := map[string]struct {
+		address string
+		token   string
+		wantErr bool
+	}{
+		"loopback_ipv4":        {address: "127.0.0.1:7890"},
+		"loopback_ipv6":        {address: "[::1]:7890"},
+		"localhost":            {address: "localhost:7890"},
+		"all_interfaces":       {address: ":7890", wantErr: true},
+		"all_interfaces_token": {address: ":7890", token: "s3cret"},
+		"external_address":     {address: "10.1.1.1:7890", wantErr: true},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			defer a.Cfn()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.APIServer = &config.APIServer{
+				Address:    item.address,
+				Token:      item.token,
+				EnableRPCs: true,
+			}
+			_, err := a.newAPIServer()
+			if (err != nil) != item.wantErr {
+				t.Errorf("failed at item %q: expected error %v, got: %v", name, item.wantErr, err)
+			}
+		})
+	}
+}
+
+func stripSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
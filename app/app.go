@@ -15,9 +15,10 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
-	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -34,12 +35,14 @@ import (
 	"github.com/openconfig/gnmic/outputs"
 	"github.com/openconfig/gnmic/target"
 	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
 	"github.com/openconfig/goyang/pkg/yang"
 	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/grpclog"
@@ -49,6 +52,7 @@ import (
 
 const (
 	defaultHTTPClientTimeout = 5 * time.Second
+	addressFileReloadDelay   = 500 * time.Millisecond
 )
 
 type App struct {
@@ -64,23 +68,35 @@ type App struct {
 	dialOpts      []grpc.DialOption
 	operLock      *sync.RWMutex
 	Outputs       map[string]outputs.Output
+	outputQueues  map[string]*outputQueue
+	outputStats   map[string]*outputStats
 	Inputs        map[string]inputs.Input
 	Targets       map[string]*target.Target
 	targetsChan   chan *target.Target
 	activeTargets map[string]struct{}
 	targetsLockFn map[string]context.CancelFunc
 	rootDesc      desc.Descriptor
+	statusLock    *sync.RWMutex
+	targetsStatus map[string]*targetStatus
 	// end collector
 	router *mux.Router
 	locker lockers.Locker
 	// api
 	apiServices map[string]*lockers.Service
 	isLeader    bool
+	// number of running API requests per ad-hoc target
+	apiTargetsLock  *sync.Mutex
+	apiAdHocTargets map[string]int
 	// prometheus registry
 	reg *prometheus.Registry
 	//
 	Logger *log.Logger
 	out    io.Writer
+	// timezone of the log lines and printed timestamps
+	location *time.Location
+	// output file and pager
+	outputFile *os.File
+	pager      *pager
 	// prompt mode
 	PromptMode    bool
 	PromptHistory []string
@@ -91,6 +107,31 @@ type App struct {
 	wg        *sync.WaitGroup
 	printLock *sync.Mutex
 	errCh     chan error
+	// audit log
+	audit *auditLog
+	// capabilities collected for --save-inventory
+	inventory *inventoryCollector
+	// RPC rate limiter shared by all targets
+	rpcLimiter *rate.Limiter
+	// parsed --prefix-format template
+	prefixTmpl *template.Template
+	// compiled --query expression
+	query *formatters.Query
+	// gRPC wire stats per target
+	wireStatsLock *sync.Mutex
+	wireStats     map[string]*wireStats
+	// timestamps anomalies detectors per target, protected by wireStatsLock
+	anomalyDetectors map[string]*anomalyDetector
+	// per target sequence numbers, set with --sequence-numbers
+	sequences *sequencer
+	// targets progress of the running operation, set with --progress
+	progress *progress
+	// subscribe requests loaded with --again, per subscription name
+	againSubscribeRequests map[string]*gnmi.SubscribeRequest
+	// message types loaded from --proto-file to decode the Any values,
+	// and the unknown Any type URLs already reported
+	anyTypes        *anyResolver
+	anyTypeWarnings sync.Map
 	// gnmi server
 	gnmi.UnimplementedGNMIServer
 	// gRPC server where the gNMI service will be registered
@@ -122,22 +163,34 @@ func New() *App {
 		operLock:      new(sync.RWMutex),
 		Targets:       make(map[string]*target.Target),
 		Outputs:       make(map[string]outputs.Output),
+		outputQueues:  make(map[string]*outputQueue),
+		outputStats:   make(map[string]*outputStats),
 		Inputs:        make(map[string]inputs.Input),
 		targetsChan:   make(chan *target.Target),
 		activeTargets: make(map[string]struct{}),
 		targetsLockFn: make(map[string]context.CancelFunc),
+		statusLock:    new(sync.RWMutex),
+		targetsStatus: make(map[string]*targetStatus),
 		//
 		router:        mux.NewRouter(),
 		apiServices:   make(map[string]*lockers.Service),
 		Logger:        log.New(io.Discard, "[gnmic] ", log.LstdFlags|log.Lmsgprefix),
 		out:           os.Stdout,
+		location:      time.Local,
 		PromptHistory: make([]string, 0, 128),
 		SchemaTree: &yang.Entry{
 			Dir: make(map[string]*yang.Entry),
 		},
 
+		apiTargetsLock:  new(sync.Mutex),
+		apiAdHocTargets: make(map[string]int),
+
 		wg:        new(sync.WaitGroup),
 		printLock: new(sync.Mutex),
+		//
+		wireStatsLock:    new(sync.Mutex),
+		anomalyDetectors: make(map[string]*anomalyDetector),
+		wireStats:        make(map[string]*wireStats),
 		// tunnel server
 		ttm:          new(sync.RWMutex),
 		tunTargets:   make(map[tunnel.Target]struct{}),
@@ -158,45 +211,96 @@ func (a *App) Context() context.Context {
 func (a *App) InitGlobalFlags() {
 	a.RootCmd.ResetFlags()
 
-	a.RootCmd.PersistentFlags().StringVar(&a.Config.CfgFile, "config", "", "config file (default is $HOME/gnmic.yaml)")
+	a.RootCmd.PersistentFlags().StringArrayVar(&a.Config.CfgFile, "config", nil, "config file(s), can be repeated, later files override earlier ones (default is $HOME/gnmic.yaml)")
 	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.Address, "address", "a", []string{}, "comma separated gnmi targets addresses")
+	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.Group, "group", "", []string{}, "select the configured targets member of one of the groups, repeatable and combinable with --address")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Username, "username", "u", "", "username")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Password, "password", "p", "", "password")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Port, "port", "", defaultGrpcPort, "gRPC port")
-	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Encoding, "encoding", "e", "json", fmt.Sprintf("one of %q. Case insensitive", encodingNames))
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Encoding, "encoding", "e", "json", fmt.Sprintf("one of %q. Case insensitive. Overridden by the target encoding, then by the get-encoding, set-encoding and subscribe-encoding config keys", encodingNames))
+	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.EncodingFallback, "encoding-fallback", "", []string{}, "comma separated encodings the Get and Subscribe requests rejected by a target for their encoding are sent again with, in order, e.g: JSON,PROTO. Set requests never fall back")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Insecure, "insecure", "", false, "insecure connection")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSCa, "tls-ca", "", "", "tls certificate authority")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSCert, "tls-cert", "", "", "tls certificate")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSKey, "tls-key", "", "", "tls key")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.TLSIgnoreCertErrors, "tls-ignore-cert-errors", "", false, "log the TLS certificate, key and CA files loading errors and connect without them instead of failing")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Force, "force", "", false, "allow --insecure with the TLS flags, the TLS flags are ignored and the connection is plaintext")
 	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.Timeout, "timeout", "", 10*time.Second, "grpc timeout, valid formats: 10s, 1m30s, 1h")
+	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.TotalTimeout, "total-timeout", "", 0, "timeout of the whole command across all the targets, the outstanding targets are cancelled when it is reached, 0 means no limit")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Debug, "debug", "d", false, "debug mode")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.SkipVerify, "skip-verify", "", false, "skip verify tls connection")
-	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.NoPrefix, "no-prefix", "", false, "do not add [ip:port] prefix to print output in case of multiple targets")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.NoPrefix, "no-prefix", "", false, "do not prefix the printed output and errors with the target name")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.PrefixFormat, "prefix-format", "", "", "Go template of the prefix of the printed output and errors, executed with the target .Name, .Address, .Tags and .EventTags, e.g: '[{{ index .EventTags \"site\" }}/{{ .Name }}]'")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ClientID, "client-id", "", "", "identifier of this gnmic instance, sent as the client-id metadata key on every RPC")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.AuthScheme, "auth-scheme", "", types.AuthSchemeMetadata, fmt.Sprintf("how the username and password are sent to the targets, one of %q", types.AuthSchemes))
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.AuthUsernameKey, "auth-username-key", "", types.DefaultAuthUsernameKey, "metadata key the username is sent with")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.AuthPasswordKey, "auth-password-key", "", types.DefaultAuthPasswordKey, "metadata key the password is sent with")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.SplitMixedSubscriptions, "split-mixed-subscriptions", "", false, "send the stream subscriptions mixing modes, e.g: on-change and sample, as one SubscribeRequest per mode")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.AllowDuplicateTargets, "allow-duplicate-targets", "", false, "keep the targets resolving to the same address as separate targets instead of merging them")
+	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.SkipTarget, "skip-target", "", []string{}, "name of a target excluded from the command, as if it was disabled in the config, can be repeated")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.ProxyFromEnv, "proxy-from-env", "", false, "use proxy from environment")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Format, "format", "", "", fmt.Sprintf("output format, one of: %q", formatNames))
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.JSONIndent, "json-indent", "", defaultJSONIndent, "indentation of the JSON output, an empty string prints compact JSON")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Query, "query", "", "", "jq expression applied to each JSON result before printing it, e.g: '.updates[].values | to_entries[] | select(.value > 100)'")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.QueryRaw, "query-raw", "", false, "print the string results of --query without quotes")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.IncludeMeta, "include-meta", "", false, "include the notifications prefix origin and target in the json and flat formats")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.NumAsString, "num-as-string", "", false, "print the integer values as strings in the json and event formats, JSON numbers above 2^53 lose precision in most decoders")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.LogFile, "log-file", "", "", "log file path")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Log, "log", "", false, "write log messages to stderr")
-	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxMsgSize, "max-msg-size", "", msgSize, "max grpc msg size")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxMsgSize, "max-msg-size", "", msgSize, "max grpc msg size, applies to both sent and received messages")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.PrintRequest, "print-request", "", false, "print request as well as the response(s)")
 	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.Retry, "retry", "", defaultRetryTimer, "retry timer for RPCs")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSMinVersion, "tls-min-version", "", "", fmt.Sprintf("minimum TLS supported version, one of %q", tlsVersions))
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSMaxVersion, "tls-max-version", "", "", fmt.Sprintf("maximum TLS supported version, one of %q", tlsVersions))
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSVersion, "tls-version", "", "", fmt.Sprintf("set TLS version. Overwrites --tls-min-version and --tls-max-version, one of %q", tlsVersions))
+	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.TLSPin, "tls-pin", "", nil, "accept the target certificate only if its SHA-256 fingerprint matches, formatted as sha256:<hex>, instead of verifying it against the CAs. Repeatable")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.LogTLSSecret, "log-tls-secret", "", false, "enable logging of a TLS pre-master secret to a file")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ClusterName, "cluster-name", "", defaultClusterName, "cluster name the gnmic instance belongs to, this is used for target loadsharing via a locker")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.InstanceName, "instance-name", "", "", "gnmic instance name")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.API, "api", "", "", "gnmic api address")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.ProtoFile, "proto-file", "", nil, "proto file(s) name(s)")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.ProtoDir, "proto-dir", "", nil, "directory to look for proto files specified with --proto-file")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.AnyAsBytes, "any-as-bytes", "", false, "do not decode the Any values, print them as their type URL and base64 encoded payload")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TargetsFile, "targets-file", "", "", "path to file with targets configuration")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.AddressFile, "address-file", "", "", "path to a YAML file with a list of targets addresses or a targets configuration map")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Gzip, "gzip", "", false, "enable gzip compression on gRPC connections")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Stats, "stats", "", false, "print the gRPC messages and wire bytes sent to and received from each target to stderr when the command ends")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Progress, "progress", "", "", fmt.Sprintf("write the progress of the get, set, subscribe and backup commands to stderr, one of %q", progressModes))
+	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.ProgressInterval, "progress-interval", "", defaultProgressInterval, "interval between two progress records written with --progress")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.OutputFile, "output-file", "", "", "write the printed responses to a file instead of stdout")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.OutputFileSync, "output-file-sync", "", false, "fsync the --output-file after each write and set the default sync value of the file outputs, trading throughput for durability")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxLines, "max-lines", "", 0, "maximum number of printed lines per target response, the rest is replaced by a trailer. 0 means no limit, ignored with --output-file")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Pager, "pager", "", false, "pipe the printed output through $PAGER (default less) when stdout is a terminal")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.ReadOnly, "read-only", "", false, "refuse to send Set RPCs to the targets, Get, Subscribe and Capabilities RPCs are not affected")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Token, "token", "", "", "token value, used for gRPC token based authentication")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.UseKeyring, "use-keyring", "", false, "read the targets passwords from the OS keyring, keyed by target name")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ConfigKeyFile, "config-key-file", "", "", "path to a file containing the key used to decrypt the encrypted configuration values, overridden by env var GNMIC_CONFIG_KEY")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.NoPrompt, "no-prompt", "", false, "never prompt for the password of a target with a username and no password, fail instead. Implied when stdin is not a terminal")
 
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.File, "file", "", nil, "YANG file(s)")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.Dir, "dir", "", nil, "YANG dir(s)")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.Exclude, "exclude", "", nil, "YANG module names to be excluded")
 
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.UseTunnelServer, "use-tunnel-server", "", false, "use tunnel server to dial targets")
+	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.EventTag, "event-tag", "", nil, "static tag added to all events, formatted as key=value. Overridden by the targets event-tags")
+
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.AuditFile, "audit-file", "", "", "path to a file where a JSON record of each RPC is appended")
+	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.AuditRPCs, "audit-rpcs", "", []string{}, fmt.Sprintf("list of RPCs to record in the audit file, one or more of %q, defaults to all", auditRPCs))
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.SaveRequest, "save-request", "", "", "directory where each request is saved as prototext and protojson files before being sent, created if missing")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Profile, "profile", "", "", "name of the profile, defined under the config file 'profiles' section, whose flags are applied unless set explicitly")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Dev, "dev", "", false, "apply the development profile: insecure connection to a localhost target on port 9339 or 57400, json_ietf encoding and short timeouts. Refuses non-loopback addresses")
+
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.GRPCRetry, "grpc-retry", "", false, "retry the Capabilities and Get RPCs failing with a retryable status code at the gRPC layer")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.GRPCRetryMaxAttempts, "grpc-retry-max-attempts", "", defaultGRPCRetryMaxAttempts, fmt.Sprintf("maximum number of attempts of a retried RPC, including the first one, up to %d", maxGRPCRetryAttempts))
+	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.GRPCRetryInitialBackoff, "grpc-retry-initial-backoff", "", defaultGRPCRetryInitialBackoff, "maximum backoff before the first retry")
+	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.GRPCRetryMaxBackoff, "grpc-retry-max-backoff", "", defaultGRPCRetryMaxBackoff, "maximum backoff between retries")
+	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.GRPCRetryCodes, "grpc-retry-codes", "", defaultGRPCRetryCodes, "comma separated gRPC status codes triggering a retry")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.GRPCRetrySet, "grpc-retry-set", "", false, "also retry the Set RPC, only safe if the Set requests are idempotent, requires --grpc-retry")
+
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Timezone, "timezone", "", "", "timezone of the log lines and printed timestamps, an IANA name such as Europe/Paris, local or utc. Defaults to local")
+
+	a.RootCmd.PersistentFlags().Float64VarP(&a.Config.GlobalFlags.RPS, "rps", "", 0, "maximum number of RPCs started per second across all targets, 0 means no limit")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.Burst, "burst", "", defaultBurst, "maximum number of RPCs started at once when --rps is set")
 
 	a.RootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(flag.Name, flag)
@@ -205,7 +309,15 @@ func (a *App) InitGlobalFlags() {
 
 func (a *App) PreRunE(cmd *cobra.Command, args []string) error {
 	a.Config.SetPersistentFlagsFromFile(a.RootCmd)
+	err := a.Config.ApplyProfile(cmd)
+	if err != nil {
+		return err
+	}
 
+	a.location, err = a.Config.Location()
+	if err != nil {
+		return err
+	}
 	logOutput, flags, err := a.Config.SetLogger()
 	if err != nil {
 		return err
@@ -213,43 +325,157 @@ func (a *App) PreRunE(cmd *cobra.Command, args []string) error {
 	a.Logger.SetOutput(logOutput)
 	a.Logger.SetFlags(flags)
 	a.Config.Address = config.SanitizeArrayFlagValue(a.Config.Address)
+	a.Config.Group = config.SanitizeArrayFlagValue(a.Config.Group)
 	a.Logger.Printf("version=%s, commit=%s, date=%s, gitURL=%s, docs=https://gnmic.openconfig.net", version, commit, date, gitURL)
 
 	if a.Config.Debug {
 		grpclog.SetLogger(a.Logger) //lint:ignore SA1019 see https://github.com/karimra/gnmic/issues/59
 	}
-	a.Logger.Printf("using config file %q", a.Config.FileConfig.ConfigFileUsed())
+	if len(a.Config.CfgFile) > 1 {
+		a.Logger.Printf("using config files %q", a.Config.CfgFile)
+	} else {
+		a.Logger.Printf("using config file %q", a.Config.FileConfig.ConfigFileUsed())
+	}
+	if profile := a.Config.ProfileName(); profile != "" {
+		a.Logger.Printf("using profile %q, addresses=%v", profile, a.Config.Address)
+	}
 	a.logConfigKVs()
-	return a.validateGlobals(cmd)
+	err = a.validateGlobals(cmd)
+	if err != nil {
+		return err
+	}
+	// the prompt mode commands share the root context,
+	// it is bounded only for a single command run.
+	if !a.PromptMode && cmd.Name() != "prompt" {
+		a.applyTotalTimeout()
+	}
+	if a.Config.SkipVerify && !a.Config.Insecure {
+		a.logWarning("TLS certificate verification is disabled (--skip-verify), the targets identity is not checked")
+	}
+	// the prompt mode commands print to stdout.
+	if !a.PromptMode && cmd.Name() != "prompt" {
+		err = a.openOutputFile()
+		if err != nil {
+			return err
+		}
+		a.startPager()
+	}
+	err = a.initSaveRequestDir()
+	if err != nil {
+		return err
+	}
+	err = a.loadAnyTypes()
+	if err != nil {
+		return err
+	}
+	return a.initAuditLog()
 }
 
 func (a *App) validateGlobals(cmd *cobra.Command) error {
 	if a.Config.Insecure {
-		if a.Config.SkipVerify {
-			return errors.New("flags --insecure and --skip-verify are mutually exclusive")
+		for _, f := range a.insecureConflictingFlags() {
+			if a.Config.Force {
+				a.logWarning(fmt.Sprintf("flag --%s is ignored with --insecure (--force), the connection is plaintext", f))
+				continue
+			}
+			return fmt.Errorf("flags --insecure and --%s are mutually exclusive, set --force to connect in plaintext anyway", f)
 		}
-		if a.Config.TLSCa != "" {
-			return errors.New("flags --insecure and --tls-ca are mutually exclusive")
+	}
+	if a.Config.RPS < 0 {
+		return errors.New("flag --rps cannot be negative")
+	}
+	if a.Config.RPS > 0 && a.Config.Burst < 1 {
+		return errors.New("flag --burst must be at least 1")
+	}
+	if a.Config.GRPCRetrySet && !a.Config.GRPCRetry {
+		return errors.New("flag --grpc-retry-set requires --grpc-retry")
+	}
+	if a.Config.GRPCRetry {
+		_, err := a.grpcRetryServiceConfig()
+		if err != nil {
+			return err
 		}
-		if a.Config.TLSCert != "" {
-			return errors.New("flags --insecure and --tls-cert are mutually exclusive")
+	}
+	for _, pin := range a.Config.TLSPin {
+		if _, err := utils.ParseCertificatePin(pin); err != nil {
+			return fmt.Errorf("flag --tls-pin: %v", err)
 		}
-		if a.Config.TLSKey != "" {
-			return errors.New("flags --insecure and --tls-key are mutually exclusive")
+	}
+	for _, enc := range a.Config.EncodingFallback {
+		if _, ok := target.ParseEncoding(enc); !ok {
+			return fmt.Errorf("flag --encoding-fallback: unknown encoding %q, expected one of %q", enc, encodingNames)
 		}
-		if a.Config.TLSVersion != "" {
-			return errors.New("flags --insecure and --tls-version are mutually exclusive")
+	}
+	if a.Config.TotalTimeout < 0 {
+		return errors.New("flag --total-timeout cannot be negative")
+	}
+	if a.Config.AuthScheme != "" && !strInList(a.Config.AuthScheme, types.AuthSchemes) {
+		return fmt.Errorf("flag --auth-scheme: unknown value %q, expected one of %q", a.Config.AuthScheme, types.AuthSchemes)
+	}
+	if a.Config.Progress != "" {
+		if !strInList(a.Config.Progress, progressModes) {
+			return fmt.Errorf("flag --progress: unknown value %q, expected one of %q", a.Config.Progress, progressModes)
 		}
-		if a.Config.TLSMaxVersion != "" {
-			return errors.New("flags --insecure and --tls-max-version are mutually exclusive")
+		if a.Config.ProgressInterval <= 0 {
+			return errors.New("flag --progress-interval must be positive")
 		}
-		if a.Config.TLSMinVersion != "" {
-			return errors.New("flags --insecure and --tls-min-version are mutually exclusive")
+	}
+	if a.Config.MaxLines < 0 {
+		return errors.New("flag --max-lines cannot be negative")
+	}
+	if f := a.RootCmd.PersistentFlags().Lookup("read-only"); f != nil && f.Changed && !a.Config.ReadOnly {
+		return errors.New("flag --read-only cannot be set to false, remove it and the read-only config key to allow Set RPCs")
+	}
+	if a.Config.PrefixFormat != "" {
+		if a.Config.NoPrefix {
+			return errors.New("flags --no-prefix and --prefix-format are mutually exclusive")
 		}
+		var err error
+		a.prefixTmpl, err = parsePrefixFormat(a.Config.PrefixFormat)
+		if err != nil {
+			return err
+		}
+	}
+	if a.Config.Query != "" {
+		if a.Config.Format != "" && a.Config.Format != formatJSON {
+			return fmt.Errorf("flag --query requires the json format, got --format %s", a.Config.Format)
+		}
+		var err error
+		a.query, err = formatters.NewQuery(a.Config.Query, a.Config.QueryRaw)
+		if err != nil {
+			return err
+		}
+	} else if a.Config.QueryRaw {
+		return errors.New("flag --query-raw requires --query")
 	}
 	return nil
 }
 
+// insecureConflictingFlags returns the set TLS flags ignored by a plaintext connection.
+func (a *App) insecureConflictingFlags() []string {
+	flags := make([]string, 0)
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{name: "skip-verify", set: a.Config.SkipVerify},
+		{name: "tls-ca", set: a.Config.TLSCa != ""},
+		{name: "tls-cert", set: a.Config.TLSCert != ""},
+		{name: "tls-key", set: a.Config.TLSKey != ""},
+		{name: "tls-version", set: a.Config.TLSVersion != ""},
+		{name: "tls-max-version", set: a.Config.TLSMaxVersion != ""},
+		{name: "tls-min-version", set: a.Config.TLSMinVersion != ""},
+		{name: "tls-pin", set: len(a.Config.TLSPin) > 0},
+		{name: "tls-ignore-cert-errors", set: a.Config.TLSIgnoreCertErrors},
+		{name: "log-tls-secret", set: a.Config.LogTLSSecret},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
 func (a *App) logConfigKVs() {
 	if a.Config.Debug {
 		b, err := yaml.Marshal(a.Config.FileConfig.AllSettings())
@@ -272,31 +498,53 @@ func (a *App) logConfigKVs() {
 }
 
 func (a *App) PrintMsg(address string, msgName string, msg proto.Message) error {
+	return a.printMsg(address, msgName, msg, nil)
+}
+
+// printMsg prints msg like PrintMsg, the meta keys are added to the structured formats.
+func (a *App) printMsg(address string, msgName string, msg proto.Message, meta map[string]string) error {
 	a.printLock.Lock()
 	defer a.printLock.Unlock()
+	a.clearProgressBar()
 	if a.Config.PrintRequest {
 		fmt.Fprint(os.Stderr, msgName)
 		fmt.Fprintln(os.Stderr, "")
 	}
-	printPrefix := ""
-	if len(a.Config.TargetsList()) > 1 && !a.Config.NoPrefix {
-		printPrefix = fmt.Sprintf("[%s] ", address)
+	valuesOnly := a.Config.GetValuesOnly || a.Config.SubscribeValuesOnly
+	_, isCapRsp := msg.ProtoReflect().Interface().(*gnmi.CapabilityResponse)
+	structured := structuredFormat(a.Config.Format) && !valuesOnly && !(isCapRsp && a.Config.Format == "")
+	printPrefix := a.printPrefix(address, structured)
+	if valuesOnly && printPrefix != "" {
+		// the values are separated from their prefix with a tab,
+		// the default prefix is the target name without brackets.
+		if a.prefixTmpl == nil {
+			printPrefix = address + "\t"
+		} else {
+			printPrefix = a.formatPrefix(address) + "\t"
+		}
 	}
 
 	switch msg := msg.ProtoReflect().Interface().(type) {
 	case *gnmi.CapabilityResponse:
 		if len(a.Config.Format) == 0 {
-			a.printCapResponse(printPrefix, msg)
+			a.printCapResponse(printPrefix, msg, meta)
 			return nil
 		}
 	}
 	mo := formatters.MarshalOptions{
-		Multiline:  true,
-		Indent:     "  ",
-		Format:     a.Config.Format,
-		ValuesOnly: a.Config.GetValuesOnly,
+		Multiline:   true,
+		Indent:      a.Config.JSONIndent,
+		Format:      a.Config.Format,
+		ValuesOnly:  valuesOnly,
+		NumAsString: a.Config.NumAsString,
+		IncludeMeta: a.Config.IncludeMeta,
+		Location:    a.location,
+	}
+	m := map[string]string{"source": address}
+	for k, v := range meta {
+		m[k] = v
 	}
-	b, err := mo.Marshal(msg, map[string]string{"source": address})
+	b, err := mo.Marshal(msg, m)
 	if err != nil {
 		a.Logger.Printf("error marshaling message: %v", err)
 		if !a.Config.Log {
@@ -304,24 +552,47 @@ func (a *App) PrintMsg(address string, msgName string, msg proto.Message) error
 		}
 		return err
 	}
-	sb := strings.Builder{}
-	sb.Write(b)
-	fmt.Fprintf(a.out, "%s\n", indent(printPrefix, sb.String()))
-	return nil
+	if a.query != nil && structured && !isRequestMsg(msg) {
+		b, err = a.query.Apply(b, mo.Indent)
+		if err != nil {
+			return err
+		}
+		if len(b) == 0 {
+			return nil
+		}
+	}
+	return a.writeOutput(printPrefix, b)
+}
+
+// isRequestMsg returns true if msg is a gNMI request,
+// the requests printed with --print-request are not queried.
+func isRequestMsg(msg proto.Message) bool {
+	switch msg.ProtoReflect().Interface().(type) {
+	case *gnmi.CapabilityRequest, *gnmi.GetRequest, *gnmi.SetRequest, *gnmi.SubscribeRequest:
+		return true
+	}
+	return false
 }
 
 func (a *App) createCollectorDialOpts() []grpc.DialOption {
 	opts := []grpc.DialOption{grpc.WithBlock()}
 	if a.Config.MaxMsgSize > 0 {
-		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(a.Config.MaxMsgSize)))
+		opts = append(opts, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(a.Config.MaxMsgSize),
+			grpc.MaxCallSendMsgSize(a.Config.MaxMsgSize),
+		))
 	}
 	if !a.Config.ProxyFromEnv {
 		opts = append(opts, grpc.WithNoProxy())
 	}
-	opts = append(opts, grpc.WithUserAgent(fmt.Sprintf("gNMIc/%s", version)))
+	opts = append(opts, grpc.WithUserAgent(defaultUserAgent()))
 	if a.Config.Gzip {
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
 	}
+	opts = append(opts, a.rateLimitDialOpts()...)
+	if a.Config.GRPCRetry {
+		opts = append(opts, a.grpcRetryDialOpts()...)
+	}
 	if a.Config.APIServer != nil && a.Config.APIServer.EnableMetrics && a.reg != nil {
 		grpcClientMetrics := grpc_prometheus.NewClientMetrics()
 		opts = append(opts,
@@ -336,10 +607,77 @@ func (a *App) createCollectorDialOpts() []grpc.DialOption {
 
 func (a *App) watchConfig() {
 	a.Logger.Printf("watching config...")
-	a.Config.FileConfig.OnConfigChange(a.loadTargets)
+	if len(a.Config.CfgFile) > 1 {
+		// only the last file is watched and re-read on change,
+		// merge it again with the previous ones.
+		a.Config.FileConfig.OnConfigChange(func(e fsnotify.Event) {
+			err := a.Config.ReadConfigFiles(a.ctx, a.Config.CfgFile)
+			if err != nil {
+				a.Logger.Printf("failed to read config files: %v", err)
+				return
+			}
+			a.loadTargets(e)
+		})
+	} else {
+		a.Config.FileConfig.OnConfigChange(a.loadTargets)
+	}
 	a.Config.FileConfig.WatchConfig()
 }
 
+// watchAddressFile watches the file set with --address-file
+// and reloads the targets when it changes.
+func (a *App) watchAddressFile() {
+	if a.Config.AddressFile == "" {
+		a.Logger.Printf("--watch-file is set without --address-file, nothing to watch")
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		a.Logger.Printf("failed to create address file watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+	fileName := filepath.Clean(a.Config.AddressFile)
+	// watch the parent directory to keep track of the file
+	// when an editor replaces it instead of writing to it.
+	err = watcher.Add(filepath.Dir(fileName))
+	if err != nil {
+		a.Logger.Printf("failed to watch address file %q: %v", fileName, err)
+		return
+	}
+	a.Logger.Printf("watching address file %q...", fileName)
+	var timer *time.Timer
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(e.Name) != fileName {
+				continue
+			}
+			if e.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			// a single save can trigger multiple events,
+			// reload the targets once they settle.
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(addressFileReloadDelay, func() {
+				a.loadTargets(fsnotify.Event{Name: fileName, Op: fsnotify.Write})
+			})
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			a.Logger.Printf("address file watcher error: %v", err)
+		}
+	}
+}
+
 func (a *App) loadTargets(e fsnotify.Event) {
 	a.Logger.Printf("got config change notification: %v", e)
 	ctx, cancel := context.WithCancel(a.ctx)
@@ -357,14 +695,17 @@ func (a *App) loadTargets(e fsnotify.Event) {
 			a.Logger.Printf("failed getting targets from new config: %v", err)
 			return
 		}
+		err = a.Config.SetMissingCredentials(newTargets)
+		if err != nil {
+			a.Logger.Printf("failed getting targets from new config: %v", err)
+			return
+		}
 		if !a.inCluster() {
 			currentTargets := a.Targets
 			// delete targets
 			for n := range currentTargets {
 				if _, ok := newTargets[n]; !ok {
-					if a.Config.Debug {
-						a.Logger.Printf("target %q deleted from config", n)
-					}
+					a.Logger.Printf("target %q deleted from config", n)
 					err = a.DeleteTarget(a.ctx, n)
 					if err != nil {
 						a.Logger.Printf("failed to delete target %q: %v", n, err)
@@ -374,9 +715,7 @@ func (a *App) loadTargets(e fsnotify.Event) {
 			// add targets
 			for n, tc := range newTargets {
 				if _, ok := currentTargets[n]; !ok {
-					if a.Config.Debug {
-						a.Logger.Printf("target %q added to config", n)
-					}
+					a.Logger.Printf("target %q added to config", n)
 					a.AddTargetConfig(tc)
 					a.wg.Add(1)
 					go a.TargetSubscribeStream(a.ctx, tc)
@@ -457,8 +796,9 @@ func (a *App) LoadProtoFiles() (desc.Descriptor, error) {
 	}
 	rootDesc, err := descSource.FindSymbol("Nokia.SROS.root")
 	if err != nil {
-		a.Logger.Printf("could not get symbol 'Nokia.SROS.root': %v", err)
-		return nil, err
+		// the proto files may only describe the Any values types
+		a.Logger.Printf("could not get symbol 'Nokia.SROS.root', proto bytes values will not be decoded: %v", err)
+		return nil, nil
 	}
 	a.Logger.Printf("loaded proto files")
 	a.rootDesc = rootDesc
@@ -493,15 +833,33 @@ func (a *App) GetTargets() (map[string]*types.TargetConfig, error) {
 	} else if err != nil {
 		return nil, err
 	}
-
+	err = a.Config.SetMissingCredentials(targetsConfig)
+	if err != nil {
+		return nil, err
+	}
 	return targetsConfig, nil
 }
 
+// defaultUserAgent returns the user-agent of the gRPC connections
+// of the targets without one.
+func defaultUserAgent() string {
+	return fmt.Sprintf("gnmic/%s", version)
+}
+
+// targetDialOpts returns the dial options of target t,
+// in a new slice that can be appended to.
+func (a *App) targetDialOpts(t *target.Target) []grpc.DialOption {
+	opts := make([]grpc.DialOption, 0, len(a.dialOpts)+2)
+	opts = append(opts, a.dialOpts...)
+	opts = append(opts, a.balancedDialOpts(t)...)
+	return append(opts, a.wireStatsDialOpts(t)...)
+}
+
 func (a *App) CreateGNMIClient(ctx context.Context, t *target.Target) error {
 	if t.Client != nil {
 		return nil
 	}
-	targetDialOpts := a.dialOpts
+	targetDialOpts := a.targetDialOpts(t)
 	if a.Config.UseTunnelServer {
 		targetDialOpts = append(targetDialOpts,
 			grpc.WithContextDialer(a.tunDialerFn(ctx, t.Config)),
@@ -509,7 +867,17 @@ func (a *App) CreateGNMIClient(ctx context.Context, t *target.Target) error {
 		t.Config.Address = t.Config.Name
 	}
 	a.Logger.Printf("creating gRPC client for target %q", t.Config.Name)
+	if a.Config.Debug {
+		userAgent := t.Config.UserAgent
+		if userAgent == "" {
+			userAgent = defaultUserAgent()
+		}
+		a.Logger.Printf("target %q: user-agent=%q, client-id=%q, auth-scheme=%q", t.Config.Name, userAgent, t.Config.ClientID, t.Config.GetAuthScheme())
+	}
 	if err := t.CreateGNMIClient(ctx, targetDialOpts...); err != nil {
+		if a.totalTimeoutReached() {
+			return fmt.Errorf("failed to create a gRPC client for target %q: %w", t.Config.Name, a.ctx.Err())
+		}
 		if errors.Is(err, context.DeadlineExceeded) {
 			return fmt.Errorf("failed to create a gRPC client for target %q, timeout (%s) reached", t.Config.Name, t.Config.Timeout)
 		}
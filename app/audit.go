@@ -0,0 +1,257 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	auditRPCCapabilities = "capabilities"
+	auditRPCGet          = "get"
+	auditRPCSet          = "set"
+	auditRPCSubscribe    = "subscribe"
+
+	redactedValue = "****"
+)
+
+var auditRPCs = []string{auditRPCCapabilities, auditRPCGet, auditRPCSet, auditRPCSubscribe}
+
+type auditLog struct {
+	m        *sync.Mutex
+	f        *os.File
+	operator string
+	rpcs     map[string]struct{}
+}
+
+type auditRecord struct {
+	Timestamp  string          `json:"timestamp,omitempty"`
+	Operator   string          `json:"operator,omitempty"`
+	Target     string          `json:"target,omitempty"`
+	RPC        string          `json:"rpc,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration-ms"`
+}
+
+// initAuditLog opens the audit file in append mode if
+// the flag --audit-file is set.
+func (a *App) initAuditLog() error {
+	if a.Config.AuditFile == "" || a.audit != nil {
+		return nil
+	}
+	rpcs := make(map[string]struct{})
+	for _, r := range a.Config.AuditRPCs {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" {
+			continue
+		}
+		if !strInList(r, auditRPCs) {
+			return fmt.Errorf("unknown audit RPC %q, must be one of %q", r, auditRPCs)
+		}
+		rpcs[r] = struct{}{}
+	}
+	f, err := os.OpenFile(a.Config.AuditFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %v", err)
+	}
+	operator := ""
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	a.audit = &auditLog{
+		m:        new(sync.Mutex),
+		f:        f,
+		operator: operator,
+		rpcs:     rpcs,
+	}
+	types.RegisterSetAudit(a.auditSet)
+	return nil
+}
+
+// CloseAuditLog syncs and closes the audit file,
+// the RPCs ending after it are not recorded.
+func (a *App) CloseAuditLog() {
+	if a.audit == nil {
+		return
+	}
+	types.RegisterSetAudit(nil)
+	a.audit.m.Lock()
+	defer a.audit.m.Unlock()
+	if a.audit.f == nil {
+		return
+	}
+	if err := a.audit.f.Sync(); err != nil {
+		a.Logger.Printf("audit: failed to sync the audit file: %v", err)
+	}
+	if err := a.audit.f.Close(); err != nil {
+		a.Logger.Printf("audit: failed to close the audit file: %v", err)
+	}
+	a.audit.f = nil
+}
+
+// auditSet records a Set RPC sent by the gnmi actions or the gnmi output,
+// it is registered as the types.SetAuditFunc while the audit file is open.
+func (a *App) auditSet(targetName string, req *gnmi.SetRequest, rsp *gnmi.SetResponse, err error, start time.Time) {
+	var msg proto.Message
+	if rsp != nil {
+		msg = rsp
+	}
+	a.auditRPC(targetName, auditRPCSet, req, msg, err, start)
+}
+
+// auditRPC appends a record of an RPC to the audit file.
+// it is safe to call concurrently from the per target goroutines.
+func (a *App) auditRPC(targetName, rpc string, req, rsp proto.Message, rpcErr error, start time.Time) {
+	if a.audit == nil {
+		return
+	}
+	if len(a.audit.rpcs) > 0 {
+		if _, ok := a.audit.rpcs[rpc]; !ok {
+			return
+		}
+	}
+	rec := &auditRecord{
+		Timestamp:  start.Format(time.RFC3339Nano),
+		Operator:   a.audit.operator,
+		Target:     targetName,
+		RPC:        rpc,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	var err error
+	if req != nil {
+		rec.Request, err = protojson.Marshal(redactRequest(req))
+		if err != nil {
+			a.Logger.Printf("audit: failed to marshal %s request: %v", rpc, err)
+		}
+	}
+	if rpcErr == nil && rsp != nil {
+		rec.Response, err = protojson.Marshal(rsp)
+		if err != nil {
+			a.Logger.Printf("audit: failed to marshal %s response: %v", rpc, err)
+		}
+	}
+	if rpcErr != nil {
+		rec.Error = rpcErr.Error()
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		a.Logger.Printf("audit: failed to marshal record: %v", err)
+		return
+	}
+	a.audit.m.Lock()
+	defer a.audit.m.Unlock()
+	if a.audit.f == nil {
+		return
+	}
+	_, err = a.audit.f.Write(append(b, '\n'))
+	if err != nil {
+		a.Logger.Printf("audit: failed to write record: %v", err)
+	}
+}
+
+// redactRequest returns a copy of a SetRequest where the values
+// of leaves with a name containing "password" are masked.
+// JSON and ASCII values are searched for such leaves, ASCII values
+// that are not JSON are masked if they contain "password" and
+// proto values are always masked.
+// Other messages are returned as is.
+func redactRequest(msg proto.Message) proto.Message {
+	req, ok := msg.(*gnmi.SetRequest)
+	if !ok {
+		return msg
+	}
+	req = proto.Clone(req).(*gnmi.SetRequest)
+	for _, upds := range [][]*gnmi.Update{req.GetUpdate(), req.GetReplace()} {
+		for _, upd := range upds {
+			redactUpdate(upd)
+		}
+	}
+	return req
+}
+
+func redactUpdate(upd *gnmi.Update) {
+	if upd == nil {
+		return
+	}
+	if elems := upd.GetPath().GetElem(); len(elems) > 0 && isPasswordKey(elems[len(elems)-1].GetName()) {
+		upd.Val = &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: redactedValue}}
+		return
+	}
+	switch val := upd.GetVal().GetValue().(type) {
+	case *gnmi.TypedValue_JsonVal:
+		val.JsonVal = redactJSON(val.JsonVal)
+	case *gnmi.TypedValue_JsonIetfVal:
+		val.JsonIetfVal = redactJSON(val.JsonIetfVal)
+	case *gnmi.TypedValue_AsciiVal:
+		val.AsciiVal = redactASCII(val.AsciiVal)
+	case *gnmi.TypedValue_ProtoBytes:
+		val.ProtoBytes = []byte(redactedValue)
+	}
+}
+
+func redactASCII(s string) string {
+	if json.Valid([]byte(s)) {
+		return string(redactJSON([]byte(s)))
+	}
+	if strings.Contains(strings.ToLower(s), "password") {
+		return redactedValue
+	}
+	return s
+}
+
+func redactJSON(b []byte) []byte {
+	var v interface{}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return b
+	}
+	nb, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return b
+	}
+	return nb
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range v {
+			if isPasswordKey(k) {
+				v[k] = redactedValue
+				continue
+			}
+			v[k] = redactValue(vv)
+		}
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = redactValue(vv)
+		}
+	}
+	return v
+}
+
+func isPasswordKey(k string) bool {
+	if idx := strings.Index(k, ":"); idx >= 0 {
+		k = k[idx+1:]
+	}
+	return strings.Contains(strings.ToLower(k), "password")
+}
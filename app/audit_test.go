@@ -0,0 +1,161 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/proto"
+)
+
+var redactJSONTestSet = map[string][]string{
+	"no_password": {
+		`{"name":"admin"}`,
+		`{"name":"admin"}`,
+	},
+	"password": {
+		`{"name":"admin","password":"secret"}`,
+		`{"name":"admin","password":"****"}`,
+	},
+	"nested_with_prefix": {
+		`{"users":[{"name":"admin","srl:password":"secret"}]}`,
+		`{"users":[{"name":"admin","srl:password":"****"}]}`,
+	},
+	"not_json": {
+		`admin`,
+		`admin`,
+	},
+}
+
+func TestRedactJSON(t *testing.T) {
+	for name, item := range redactJSONTestSet {
+		t.Run(name, func(t *testing.T) {
+			r := string(redactJSON([]byte(item[0])))
+			if r != item[1] {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item[1])
+				t.Logf("	 got: %q", r)
+				t.Fail()
+			}
+		})
+	}
+}
+
+var redactUpdateTestSet = map[string][]*gnmi.TypedValue{
+	"ascii_json": {
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: `{"name":"admin","password":"secret"}`}},
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: `{"name":"admin","password":"****"}`}},
+	},
+	"ascii_password": {
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "user admin password secret"}},
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: redactedValue}},
+	},
+	"ascii_no_password": {
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "user admin"}},
+		{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "user admin"}},
+	},
+	"proto": {
+		{Value: &gnmi.TypedValue_ProtoBytes{ProtoBytes: []byte("secret")}},
+		{Value: &gnmi.TypedValue_ProtoBytes{ProtoBytes: []byte(redactedValue)}},
+	},
+}
+
+func TestRedactUpdate(t *testing.T) {
+	for name, item := range redactUpdateTestSet {
+		t.Run(name, func(t *testing.T) {
+			upd := &gnmi.Update{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}}},
+				Val:  proto.Clone(item[0]).(*gnmi.TypedValue),
+			}
+			redactUpdate(upd)
+			if !proto.Equal(upd.Val, item[1]) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item[1])
+				t.Logf("	 got: %v", upd.Val)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestAuditSubscribe(t *testing.T) {
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	a.Config.AuditFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	a.Config.AuditRPCs = []string{"subscribe"}
+	if err := a.initAuditLog(); err != nil {
+		t.Fatalf("failed to init the audit log: %v", err)
+	}
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{Mode: gnmi.SubscriptionList_ONCE},
+		},
+	}
+	a.auditRPC("r1", auditRPCSubscribe, req, nil, nil, time.Now())
+	// filtered out by --audit-rpcs
+	a.auditRPC("r1", auditRPCGet, &gnmi.GetRequest{}, nil, nil, time.Now())
+	a.CloseAuditLog()
+
+	b, err := os.ReadFile(a.Config.AuditFile)
+	if err != nil {
+		t.Fatalf("failed to read the audit file: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 record, got %d: %q", len(lines), b)
+	}
+	rec := new(auditRecord)
+	if err := json.Unmarshal(lines[0], rec); err != nil {
+		t.Fatalf("failed to unmarshal the record %q: %v", lines[0], err)
+	}
+	if rec.Target != "r1" || rec.RPC != auditRPCSubscribe || rec.Request == nil {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestAuditSetAndClose(t *testing.T) {
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	a.Config.AuditFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := a.initAuditLog(); err != nil {
+		t.Fatalf("failed to init the audit log: %v", err)
+	}
+	req := &gnmi.SetRequest{Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "system"}}}}}
+	types.AuditSet("r1", req, nil, errors.New("set failed"), time.Now())
+	a.CloseAuditLog()
+	// not recorded after the file is closed
+	types.AuditSet("r2", req, &gnmi.SetResponse{}, nil, time.Now())
+	a.CloseAuditLog()
+
+	b, err := os.ReadFile(a.Config.AuditFile)
+	if err != nil {
+		t.Fatalf("failed to read the audit file: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 record, got %d: %q", len(lines), b)
+	}
+	rec := new(auditRecord)
+	if err := json.Unmarshal(lines[0], rec); err != nil {
+		t.Fatalf("failed to unmarshal the record %q: %v", lines[0], err)
+	}
+	if rec.Target != "r1" || rec.RPC != auditRPCSet || rec.Error != "set failed" || rec.Response != nil {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
@@ -0,0 +1,527 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultBackupDir   = "backups"
+	backupFileExt      = ".json"
+	backupDiffContext  = 3
+	backupFilePerm     = 0600
+	backupDirPerm      = 0700
+	backupStatusSaved  = "saved"
+	backupStatusFailed = "failed"
+)
+
+type backupResult struct {
+	target string
+	file   string
+	// diff against the previous snapshot, empty if the configuration did not change
+	diff string
+	// false if there is no previous snapshot
+	hasPrevious bool
+	err         error
+}
+
+func (a *App) BackupPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if len(a.Config.LocalFlags.BackupPath) == 0 {
+		a.Config.LocalFlags.BackupPath = []string{"/"}
+	}
+	a.Config.LocalFlags.BackupPath = config.SanitizeArrayFlagValue(a.Config.LocalFlags.BackupPath)
+	if a.Config.LocalFlags.BackupDir == "" {
+		a.Config.LocalFlags.BackupDir = defaultBackupDir
+	}
+	if a.Config.LocalFlags.BackupKeep < 0 {
+		return errors.New("flag --keep must be a positive number")
+	}
+
+	a.createCollectorDialOpts()
+	return a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+}
+
+func (a *App) BackupRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitBackupFlags(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := a.GetTargets()
+	if err != nil {
+		return fmt.Errorf("failed getting targets config: %v", err)
+	}
+	req, err := a.Config.CreateBackupGetRequest()
+	if err != nil {
+		return err
+	}
+	// the snapshots are named in UTC unless a timezone is set.
+	now := time.Now().UTC()
+	if a.Config.Timezone != "" {
+		now = now.In(a.location)
+	}
+	numTargets := len(a.Config.Targets)
+	results := make(chan *backupResult, numTargets)
+	stopProgress := a.startProgress("backup", numTargets)
+	a.wg.Add(numTargets)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			r := a.backupTarget(ctx, tc, req, now)
+			a.progressDone(tc.Name, r.err != nil)
+			results <- r
+		}(tc)
+	}
+	a.wg.Wait()
+	stopProgress()
+	close(results)
+
+	rs := make([]*backupResult, 0, numTargets)
+	for r := range results {
+		rs = append(rs, r)
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].target < rs[j].target
+	})
+	return a.printBackupSummary(rs)
+}
+
+// InitBackupFlags used to init or reset backupCmd flags for gnmic-prompt mode
+func (a *App) InitBackupFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.BackupPath, "path", "", []string{}, "backup request paths, defaults to /")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.BackupPrefix, "prefix", "", "", "backup request prefix")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.BackupTarget, "target", "", "", "backup request target")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.BackupDir, "dir", "", defaultBackupDir, "directory the snapshots are written to, under a sub directory per target")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BackupKeep, "keep", "", 0, "number of snapshots to keep per target, 0 keeps all the snapshots")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.BackupDiffLast, "diff-last", "", false, "print the diff against the previous snapshot, exits with code 1 if the configuration changed")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// backupTarget gets the configuration of target tc and writes it to a new snapshot file.
+func (a *App) backupTarget(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest, now time.Time) *backupResult {
+	r := &backupResult{target: tc.Name}
+	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
+		req.Prefix, req.Path, req.Type, req.Encoding, req.UseModels, req.Extension, tc.Name)
+	rsp, err := a.ClientGet(ctx, tc, req)
+	if err != nil {
+		r.err = fmt.Errorf("get request failed: %v", err)
+		return r
+	}
+	doc, err := configDocument(rsp)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	b, err := formatters.MarshalJSON(doc, defaultJSONIndent)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	b = append(b, '\n')
+	dir := filepath.Join(a.Config.LocalFlags.BackupDir, tc.Name)
+	err = os.MkdirAll(dir, backupDirPerm)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	snapshots, err := backupSnapshots(dir)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	if a.Config.LocalFlags.BackupDiffLast && len(snapshots) > 0 {
+		prev, err := os.ReadFile(filepath.Join(dir, snapshots[len(snapshots)-1]))
+		if err != nil {
+			r.err = fmt.Errorf("failed to read the previous snapshot: %v", err)
+			return r
+		}
+		r.hasPrevious = true
+		if !bytes.Equal(prev, b) {
+			r.diff = lineDiff(string(prev), string(b), backupDiffContext)
+		}
+	}
+	r.file = filepath.Join(dir, now.Format(time.RFC3339)+backupFileExt)
+	err = os.WriteFile(r.file, b, backupFilePerm)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	snapshots = append(snapshots, filepath.Base(r.file))
+	err = pruneSnapshots(dir, snapshots, a.Config.LocalFlags.BackupKeep)
+	if err != nil {
+		a.logTargetError(tc.Name, fmt.Errorf("failed to remove old snapshots: %v", err))
+	}
+	return r
+}
+
+func (a *App) printBackupSummary(rs []*backupResult) error {
+	numFailed := 0
+	numChanged := 0
+	for _, r := range rs {
+		if r.diff != "" {
+			numChanged++
+			fmt.Fprintf(a.out, "%q configuration changed:\n%s\n", r.target, r.diff)
+		}
+	}
+	for _, r := range rs {
+		if r.err != nil {
+			numFailed++
+			fmt.Fprintf(a.out, "%s: %s: %v\n", r.target, backupStatusFailed, r.err)
+			continue
+		}
+		status := fmt.Sprintf("%s: %s %s", r.target, backupStatusSaved, r.file)
+		if a.Config.LocalFlags.BackupDiffLast {
+			switch {
+			case !r.hasPrevious:
+				status += " (no previous snapshot)"
+			case r.diff != "":
+				status += " (changed)"
+			default:
+				status += " (unchanged)"
+			}
+		}
+		fmt.Fprintln(a.out, status)
+	}
+	switch {
+	case numFailed > 0 && numChanged > 0:
+		return fmt.Errorf("%d/%d target(s) backup failed, %d target(s) configuration changed", numFailed, len(rs), numChanged)
+	case numFailed > 0:
+		return fmt.Errorf("%d/%d target(s) backup failed", numFailed, len(rs))
+	case numChanged > 0:
+		return fmt.Errorf("%d target(s) configuration changed", numChanged)
+	}
+	return nil
+}
+
+// configDocument merges the notifications of a GetResponse into a single JSON document,
+// lists are encoded as arrays of entries in the order they are received.
+func configDocument(rsp *gnmi.GetResponse) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	for _, n := range rsp.GetNotification() {
+		err := mergeNotification(doc, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// mergeNotification removes the deleted paths of notification n from doc,
+// then merges its updates values.
+func mergeNotification(doc map[string]interface{}, n *gnmi.Notification) error {
+	for _, del := range n.GetDelete() {
+		removeDocumentValue(doc, utils.PathElems(n.GetPrefix(), del))
+	}
+	for _, upd := range n.GetUpdate() {
+		v, err := backupValue(upd.GetVal())
+		if err != nil {
+			return fmt.Errorf("failed to decode the value of path %q: %v",
+				gnmiFullPathString(n.GetPrefix(), upd.GetPath()), err)
+		}
+		if sa, ok := v.(*gnmi.ScalarArray); ok {
+			l := make([]interface{}, 0, len(sa.GetElement()))
+			for _, e := range sa.GetElement() {
+				ev, err := formatters.GetValue(e)
+				if err != nil {
+					return err
+				}
+				l = append(l, ev)
+			}
+			v = l
+		}
+		err = insertDocumentValue(doc, utils.PathElems(n.GetPrefix(), upd.GetPath()), v)
+		if err != nil {
+			return fmt.Errorf("path %q: %v", gnmiFullPathString(n.GetPrefix(), upd.GetPath()), err)
+		}
+	}
+	return nil
+}
+
+// backupValue decodes the typed value tv, the JSON values numbers
+// are kept as json.Number to not lose the precision of the 64 bits integers.
+func backupValue(tv *gnmi.TypedValue) (interface{}, error) {
+	var b []byte
+	switch tv.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		b = tv.GetJsonIetfVal()
+	case *gnmi.TypedValue_JsonVal:
+		b = tv.GetJsonVal()
+	default:
+		return formatters.GetValue(tv)
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// insertDocumentValue sets the value v in doc under the path elements elems,
+// creating the intermediate containers and list entries.
+// Map values are merged with the existing ones.
+func insertDocumentValue(doc map[string]interface{}, elems []*gnmi.PathElem, v interface{}) error {
+	if len(elems) == 0 {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return errors.New("the root value is not a JSON object")
+		}
+		utils.MergeMaps(doc, m)
+		return nil
+	}
+	node := doc
+	for i, pe := range elems {
+		last := i == len(elems)-1
+		if len(pe.GetKey()) == 0 {
+			if last {
+				mergeDocumentValue(node, pe.GetName(), v)
+				return nil
+			}
+			child, ok := node[pe.GetName()].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[pe.GetName()] = child
+			}
+			node = child
+			continue
+		}
+		entry := listEntry(node, pe)
+		if last {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("the value of list entry %q is not a JSON object", pe.GetName())
+			}
+			utils.MergeMaps(entry, m)
+			return nil
+		}
+		node = entry
+	}
+	return nil
+}
+
+func mergeDocumentValue(node map[string]interface{}, name string, v interface{}) {
+	if m, ok := v.(map[string]interface{}); ok {
+		if existing, ok := node[name].(map[string]interface{}); ok {
+			utils.MergeMaps(existing, m)
+			return
+		}
+	}
+	node[name] = v
+}
+
+// listEntry returns the entry of list pe.Name in node with the keys of pe,
+// the entry is created if it does not exist.
+func listEntry(node map[string]interface{}, pe *gnmi.PathElem) map[string]interface{} {
+	list, _ := node[pe.GetName()].([]interface{})
+	for _, e := range list {
+		em, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		match := true
+		for k, kv := range pe.GetKey() {
+			if fmt.Sprintf("%v", em[k]) != kv {
+				match = false
+				break
+			}
+		}
+		if match {
+			return em
+		}
+	}
+	entry := make(map[string]interface{}, len(pe.GetKey()))
+	for k, kv := range pe.GetKey() {
+		entry[k] = kv
+	}
+	node[pe.GetName()] = append(list, entry)
+	return entry
+}
+
+// removeDocumentValue removes the node under the path elements elems from doc,
+// a `*` key value matches all the list entries.
+func removeDocumentValue(doc map[string]interface{}, elems []*gnmi.PathElem) {
+	if len(elems) == 0 {
+		for k := range doc {
+			delete(doc, k)
+		}
+		return
+	}
+	pe := elems[0]
+	if len(pe.GetKey()) == 0 {
+		if len(elems) == 1 {
+			delete(doc, pe.GetName())
+			return
+		}
+		if child, ok := doc[pe.GetName()].(map[string]interface{}); ok {
+			removeDocumentValue(child, elems[1:])
+		}
+		return
+	}
+	list, _ := doc[pe.GetName()].([]interface{})
+	kept := list[:0]
+	for _, e := range list {
+		em, ok := e.(map[string]interface{})
+		if !ok || !entryMatches(em, pe.GetKey()) {
+			kept = append(kept, e)
+			continue
+		}
+		if len(elems) > 1 {
+			removeDocumentValue(em, elems[1:])
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == 0 {
+		delete(doc, pe.GetName())
+		return
+	}
+	doc[pe.GetName()] = kept
+}
+
+func entryMatches(entry map[string]interface{}, keys map[string]string) bool {
+	for k, kv := range keys {
+		if kv != "*" && fmt.Sprintf("%v", entry[k]) != kv {
+			return false
+		}
+	}
+	return true
+}
+
+// backupSnapshots returns the snapshot files names in dir, oldest first.
+func backupSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]string, 0, len(entries))
+	times := make(map[string]time.Time, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != backupFileExt {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, strings.TrimSuffix(e.Name(), backupFileExt))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, e.Name())
+		times[e.Name()] = ts
+	}
+	// the snapshots may be named in different timezones,
+	// sort them by their timestamp rather than by name.
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		ti, tj := times[snapshots[i]], times[snapshots[j]]
+		if ti.Equal(tj) {
+			return snapshots[i] < snapshots[j]
+		}
+		return ti.Before(tj)
+	})
+	return snapshots, nil
+}
+
+// pruneSnapshots removes the oldest snapshots in dir to keep at most keep of them.
+func pruneSnapshots(dir string, snapshots []string, keep int) error {
+	if keep <= 0 || len(snapshots) <= keep {
+		return nil
+	}
+	for _, s := range snapshots[:len(snapshots)-keep] {
+		err := os.Remove(filepath.Join(dir, s))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineDiff returns a unified diff of the lines of a and b,
+// with numContext lines of context around the changes.
+func lineDiff(a, b string, numContext int) string {
+	dmp := diffmatchpatch.New()
+	ca, cb, lines := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(ca, cb, false), lines)
+	type diffLine struct {
+		op   diffmatchpatch.Operation
+		text string
+	}
+	dls := make([]diffLine, 0)
+	for _, d := range diffs {
+		for _, l := range strings.SplitAfter(d.Text, "\n") {
+			if l == "" {
+				continue
+			}
+			dls = append(dls, diffLine{op: d.Type, text: strings.TrimSuffix(l, "\n")})
+		}
+	}
+	// mark the lines to print: the changes and their context
+	show := make([]bool, len(dls))
+	for i, dl := range dls {
+		if dl.op == diffmatchpatch.DiffEqual {
+			continue
+		}
+		for j := i - numContext; j <= i+numContext; j++ {
+			if j >= 0 && j < len(dls) {
+				show[j] = true
+			}
+		}
+	}
+	sb := new(strings.Builder)
+	for i, dl := range dls {
+		if !show[i] {
+			if i > 0 && show[i-1] {
+				sb.WriteString("...\n")
+			}
+			continue
+		}
+		switch dl.op {
+		case diffmatchpatch.DiffInsert:
+			sb.WriteString("+ ")
+		case diffmatchpatch.DiffDelete:
+			sb.WriteString("- ")
+		default:
+			sb.WriteString("  ")
+		}
+		sb.WriteString(dl.text)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,183 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+var configDocumentTestSet = map[string]struct {
+	in  *gnmi.GetResponse
+	out string
+}{
+	"root_json": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+								JsonIetfVal: []byte(`{"system":{"name":"r1"}}`),
+							}},
+						},
+					},
+				},
+			},
+		},
+		out: `{"system":{"name":"r1"}}`,
+	},
+	"merged_notifications": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Prefix: summaryPath("system"),
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("name"),
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "r1"}},
+						},
+					},
+				},
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("system"),
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+								JsonIetfVal: []byte(`{"contact":"noc"}`),
+							}},
+						},
+					},
+				},
+			},
+		},
+		out: `{"system":{"contact":"noc","name":"r1"}}`,
+	},
+	"list_entries": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Prefix: summaryPath("interfaces"),
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "interface", Key: map[string]string{"name": "e2"}},
+								{Name: "mtu"},
+							}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 9000}},
+						},
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "interface", Key: map[string]string{"name": "e1"}},
+							}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+								JsonIetfVal: []byte(`{"name":"e1","mtu":1500}`),
+							}},
+						},
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "interface", Key: map[string]string{"name": "e2"}},
+								{Name: "enabled"},
+							}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: true}},
+						},
+					},
+				},
+			},
+		},
+		out: `{"interfaces":{"interface":[{"enabled":true,"mtu":9000,"name":"e2"},{"mtu":1500,"name":"e1"}]}}`,
+	},
+	"large_integers": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("counters"),
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+								JsonIetfVal: []byte(`{"in-octets":18446744073709551615,"out-octets":9007199254740993}`),
+							}},
+						},
+					},
+				},
+			},
+		},
+		out: `{"counters":{"in-octets":18446744073709551615,"out-octets":9007199254740993}}`,
+	},
+}
+
+func TestConfigDocument(t *testing.T) {
+	for name, item := range configDocumentTestSet {
+		t.Run(name, func(t *testing.T) {
+			doc, err := configDocument(item.in)
+			if err != nil {
+				t.Logf("failed at item %q", name)
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != item.out {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.out)
+				t.Logf("     got: %s", string(b))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"2023-03-02T02:00:00Z.json",
+		"2023-03-01T02:00:00Z.json",
+		"2023-03-03T02:00:00Z.json",
+		"notes.txt",
+	}
+	for _, f := range files {
+		err := os.WriteFile(filepath.Join(dir, f), []byte("{}"), backupFilePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := backupSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"2023-03-01T02:00:00Z.json",
+		"2023-03-02T02:00:00Z.json",
+		"2023-03-03T02:00:00Z.json",
+	}
+	if !reflect.DeepEqual(snapshots, expected) {
+		t.Fatalf("unexpected snapshots: %v", snapshots)
+	}
+	err = pruneSnapshots(dir, snapshots, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err = backupSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshots, expected[1:]) {
+		t.Fatalf("unexpected snapshots after pruning: %v", snapshots)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("non snapshot file removed: %v", err)
+	}
+}
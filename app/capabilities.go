@@ -10,6 +10,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -37,6 +38,13 @@ func (a *App) CapRunE(cmd *cobra.Command, args []string) error {
 	if a.Config.Format == formatEvent {
 		return fmt.Errorf("format event not supported for Capabilities RPC")
 	}
+	if a.Config.LocalFlags.CapabilitiesDiffInventory && a.Config.LocalFlags.CapabilitiesSaveInventory == "" {
+		return errors.New("flag --diff-inventory requires --save-inventory")
+	}
+	a.inventory = nil
+	if a.Config.LocalFlags.CapabilitiesSaveInventory != "" {
+		a.inventory = newInventoryCollector()
+	}
 	ctx, cancel := context.WithCancel(a.ctx)
 	defer cancel()
 	//
@@ -57,7 +65,15 @@ func (a *App) CapRunE(cmd *cobra.Command, args []string) error {
 		go a.ReqCapabilities(ctx, tc)
 	}
 	a.wg.Wait()
-	return a.checkErrors()
+	err = a.checkErrors()
+	// the inventory keeps the records of the targets that answered
+	if a.inventory != nil {
+		serr := a.saveInventory()
+		if serr != nil {
+			return serr
+		}
+	}
+	return err
 }
 
 func (a *App) ReqCapabilities(ctx context.Context, tc *types.TargetConfig) {
@@ -68,20 +84,28 @@ func (a *App) ReqCapabilities(ctx context.Context, tc *types.TargetConfig) {
 			Extension: ext,
 		})
 		if err != nil {
-			a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+			a.logTargetError(tc.Name, err)
 		}
 	}
 
 	a.Logger.Printf("sending gNMI CapabilityRequest: gnmi_ext.Extension='%v' to %s", ext, tc.Name)
+	ctx, md := rpcMetadataContext(ctx)
 	response, err := a.ClientCapabilities(ctx, tc, ext...)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q, capabilities request failed: %v", tc.Name, err))
+		a.logRPCError(tc.Name, "Capabilities", err)
 		return
 	}
+	if a.inventory != nil {
+		a.inventory.add(tc.Name, response)
+		// the changes are printed instead of the responses
+		if a.Config.LocalFlags.CapabilitiesDiffInventory {
+			return
+		}
+	}
 
-	err = a.PrintMsg(tc.Name, "Capabilities Response:", response)
+	err = a.printMsg(tc.Name, "Capabilities Response:", response, a.rpcMeta(tc, md))
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, err)
 	}
 }
 
@@ -89,6 +113,8 @@ func (a *App) InitCapabilitiesFlags(cmd *cobra.Command) {
 	cmd.ResetFlags()
 
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CapabilitiesVersion, "version", "", false, "show gnmi version only")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CapabilitiesSaveInventory, "save-inventory", "", "", "merge the capabilities of the targets into the JSON inventory `file`")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CapabilitiesDiffInventory, "diff-inventory", "", false, "print the changes since the last run saved in the --save-inventory file instead of the responses")
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
 	})
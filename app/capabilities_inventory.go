@@ -0,0 +1,267 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const inventoryFilePerm = 0644
+
+var (
+	// maximum wait for the lock of the inventory file held by another gnmic process
+	inventoryLockTimeout = 10 * time.Second
+	inventoryLockRetry   = 100 * time.Millisecond
+)
+
+// capInventory is the content of the --save-inventory file,
+// one record per target name.
+type capInventory struct {
+	Targets map[string]*inventoryRecord `json:"targets,omitempty"`
+}
+
+// inventoryRecord is the last known capabilities of a target.
+// Changed is set if the target models set differs from the previous record.
+type inventoryRecord struct {
+	GNMIVersion string    `json:"gnmi-version,omitempty"`
+	Encodings   []string  `json:"encodings,omitempty"`
+	Models      []string  `json:"models,omitempty"`
+	ModelsHash  string    `json:"models-hash,omitempty"`
+	FirstSeen   time.Time `json:"first-seen,omitempty"`
+	LastSeen    time.Time `json:"last-seen,omitempty"`
+	Changed     bool      `json:"changed"`
+}
+
+// inventoryCollector collects the records of the targets
+// answering the Capabilities requests of a run.
+type inventoryCollector struct {
+	m       sync.Mutex
+	records map[string]*inventoryRecord
+}
+
+func newInventoryCollector() *inventoryCollector {
+	return &inventoryCollector{records: make(map[string]*inventoryRecord)}
+}
+
+func (c *inventoryCollector) add(name string, rsp *gnmi.CapabilityResponse) {
+	rec := newInventoryRecord(rsp, time.Now())
+	c.m.Lock()
+	c.records[name] = rec
+	c.m.Unlock()
+}
+
+// newInventoryRecord builds the record of the Capabilities response rsp seen at time now.
+// The encodings and models are sorted, the models hash does not depend on their order.
+func newInventoryRecord(rsp *gnmi.CapabilityResponse, now time.Time) *inventoryRecord {
+	rec := &inventoryRecord{
+		GNMIVersion: rsp.GetGNMIVersion(),
+		Encodings:   make([]string, 0, len(rsp.GetSupportedEncodings())),
+		Models:      make([]string, 0, len(rsp.GetSupportedModels())),
+		FirstSeen:   now,
+		LastSeen:    now,
+	}
+	for _, e := range rsp.GetSupportedEncodings() {
+		rec.Encodings = append(rec.Encodings, e.String())
+	}
+	sort.Strings(rec.Encodings)
+	for _, m := range rsp.GetSupportedModels() {
+		rec.Models = append(rec.Models, fmt.Sprintf("%s:%s", m.GetName(), m.GetVersion()))
+	}
+	sort.Strings(rec.Models)
+	h := sha256.New()
+	for _, m := range rec.Models {
+		h.Write([]byte(m))
+		h.Write([]byte{'\n'})
+	}
+	rec.ModelsHash = hex.EncodeToString(h.Sum(nil))
+	return rec
+}
+
+// mergeInventory merges the records of a run into inv,
+// the records of the targets missing from the run are kept as is.
+// It returns the changes since the previous records, sorted by target name.
+func mergeInventory(inv *capInventory, records map[string]*inventoryRecord) []string {
+	if inv.Targets == nil {
+		inv.Targets = make(map[string]*inventoryRecord)
+	}
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	changes := make([]string, 0)
+	for _, name := range names {
+		rec := records[name]
+		prev, ok := inv.Targets[name]
+		inv.Targets[name] = rec
+		if !ok {
+			changes = append(changes, fmt.Sprintf("target %q: new target, gNMI version %s, %d models", name, rec.GNMIVersion, len(rec.Models)))
+			continue
+		}
+		rec.FirstSeen = prev.FirstSeen
+		rec.Changed = rec.ModelsHash != prev.ModelsHash
+		if rec.GNMIVersion != prev.GNMIVersion {
+			changes = append(changes, fmt.Sprintf("target %q: gNMI version changed: %s -> %s", name, prev.GNMIVersion, rec.GNMIVersion))
+		}
+		if strings.Join(rec.Encodings, ",") != strings.Join(prev.Encodings, ",") {
+			changes = append(changes, fmt.Sprintf("target %q: encodings changed: %v -> %v", name, prev.Encodings, rec.Encodings))
+		}
+		if !rec.Changed {
+			continue
+		}
+		added, removed := diffSorted(prev.Models, rec.Models)
+		for _, m := range added {
+			changes = append(changes, fmt.Sprintf("target %q: model added: %s", name, m))
+		}
+		for _, m := range removed {
+			changes = append(changes, fmt.Sprintf("target %q: model removed: %s", name, m))
+		}
+	}
+	return changes
+}
+
+// diffSorted returns the values of the sorted list b missing from the sorted list a,
+// and the values of a missing from b.
+func diffSorted(a, b []string) ([]string, []string) {
+	added := make([]string, 0)
+	removed := make([]string, 0)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			removed = append(removed, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			added = append(added, b[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return added, removed
+}
+
+// readInventory reads the inventory file f, a missing file is an empty inventory.
+func readInventory(f string) (*capInventory, error) {
+	inv := &capInventory{Targets: make(map[string]*inventoryRecord)}
+	b, err := os.ReadFile(f)
+	if errors.Is(err, os.ErrNotExist) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, inv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode inventory file %s: %v", f, err)
+	}
+	return inv, nil
+}
+
+// writeInventory writes inv to a temporary file renamed to f,
+// a reader never sees a partially written inventory.
+func writeInventory(f string, inv *capInventory) error {
+	b, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f), filepath.Base(f)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(append(b, '\n'))
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	// os.CreateTemp creates the file with mode 0600
+	err = tmp.Chmod(inventoryFilePerm)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f)
+}
+
+// lockInventory serializes the updates of the inventory file f by concurrent gnmic processes,
+// it creates the lock file f.lock, waiting for up to inventoryLockTimeout if it exists.
+// The returned function removes the lock file.
+func lockInventory(f string) (func(), error) {
+	lockFile := f + ".lock"
+	deadline := time.Now().Add(inventoryLockTimeout)
+	for {
+		lf, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, inventoryFilePerm)
+		if err == nil {
+			fmt.Fprintf(lf, "%d\n", os.Getpid())
+			lf.Close()
+			return func() { os.Remove(lockFile) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("inventory file %s is locked by another process, remove %s if it is stale", f, lockFile)
+		}
+		time.Sleep(inventoryLockRetry)
+	}
+}
+
+// saveInventory merges the records collected during the run into the --save-inventory file.
+// With --diff-inventory, the changes since the previous run are printed.
+func (a *App) saveInventory() error {
+	f := a.Config.LocalFlags.CapabilitiesSaveInventory
+	unlock, err := lockInventory(f)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	inv, err := readInventory(f)
+	if err != nil {
+		return err
+	}
+	a.inventory.m.Lock()
+	changes := mergeInventory(inv, a.inventory.records)
+	a.inventory.m.Unlock()
+	err = writeInventory(f, inv)
+	if err != nil {
+		return fmt.Errorf("failed to write inventory file %s: %v", f, err)
+	}
+	a.Logger.Printf("saved the capabilities of %d target(s) to inventory %s", len(a.inventory.records), f)
+	if !a.Config.LocalFlags.CapabilitiesDiffInventory {
+		return nil
+	}
+	a.printLock.Lock()
+	defer a.printLock.Unlock()
+	if len(changes) == 0 {
+		fmt.Fprintln(a.out, "no change since the last run")
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Fprintln(a.out, c)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func testCapResponse(version string, models ...string) *gnmi.CapabilityResponse {
+	rsp := &gnmi.CapabilityResponse{
+		GNMIVersion:        version,
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON_IETF, gnmi.Encoding_JSON},
+	}
+	for _, m := range models {
+		rsp.SupportedModels = append(rsp.SupportedModels, &gnmi.ModelData{Name: m, Version: "1.0"})
+	}
+	return rsp
+}
+
+func TestMergeInventory(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	tests := map[string]struct {
+		prev        *inventoryRecord
+		rsp         *gnmi.CapabilityResponse
+		wantChanged bool
+		wantChanges []string
+	}{
+		"new_target": {
+			rsp:         testCapResponse("0.7.0", "m1", "m2"),
+			wantChanges: []string{`target "t1": new target, gNMI version 0.7.0, 2 models`},
+		},
+		"unchanged": {
+			prev:        newInventoryRecord(testCapResponse("0.7.0", "m2", "m1"), t0),
+			rsp:         testCapResponse("0.7.0", "m1", "m2"),
+			wantChanges: []string{},
+		},
+		"version_changed": {
+			prev: newInventoryRecord(testCapResponse("0.7.0", "m1"), t0),
+			rsp:  testCapResponse("0.8.0", "m1"),
+			wantChanges: []string{
+				`target "t1": gNMI version changed: 0.7.0 -> 0.8.0`,
+			},
+		},
+		"models_changed": {
+			prev:        newInventoryRecord(testCapResponse("0.7.0", "m1", "m2"), t0),
+			rsp:         testCapResponse("0.7.0", "m1", "m3"),
+			wantChanged: true,
+			wantChanges: []string{
+				`target "t1": model added: m3:1.0`,
+				`target "t1": model removed: m2:1.0`,
+			},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			inv := &capInventory{Targets: map[string]*inventoryRecord{
+				"other": newInventoryRecord(testCapResponse("0.6.0"), t0),
+			}}
+			if item.prev != nil {
+				inv.Targets["t1"] = item.prev
+			}
+			changes := mergeInventory(inv, map[string]*inventoryRecord{
+				"t1": newInventoryRecord(item.rsp, t1),
+			})
+			if !reflect.DeepEqual(changes, item.wantChanges) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.wantChanges)
+				t.Logf("     got: %q", changes)
+				t.Fail()
+			}
+			rec := inv.Targets["t1"]
+			if rec.Changed != item.wantChanged {
+				t.Errorf("failed at item %q: expected changed=%v, got %v", name, item.wantChanged, rec.Changed)
+			}
+			wantFirstSeen := t1
+			if item.prev != nil {
+				wantFirstSeen = t0
+			}
+			if !rec.FirstSeen.Equal(wantFirstSeen) || !rec.LastSeen.Equal(t1) {
+				t.Errorf("failed at item %q: unexpected first-seen %s or last-seen %s", name, rec.FirstSeen, rec.LastSeen)
+			}
+			if _, ok := inv.Targets["other"]; !ok {
+				t.Errorf("failed at item %q: the record of a target missing from the run was removed", name)
+			}
+		})
+	}
+}
+
+func TestWriteInventory(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "inventory.json")
+	inv, err := readInventory(f)
+	if err != nil {
+		t.Fatalf("reading a missing inventory failed: %v", err)
+	}
+	mergeInventory(inv, map[string]*inventoryRecord{
+		"t1": newInventoryRecord(testCapResponse("0.7.0", "m1"), time.Now()),
+	})
+	err = writeInventory(f, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readInventory(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Targets["t1"].ModelsHash != inv.Targets["t1"].ModelsHash {
+		t.Errorf("unexpected models hash read back: %q", got.Targets["t1"].ModelsHash)
+	}
+	// no temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the inventory file, got %d entries", len(entries))
+	}
+}
+
+func TestLockInventory(t *testing.T) {
+	timeout, retry := inventoryLockTimeout, inventoryLockRetry
+	inventoryLockTimeout, inventoryLockRetry = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { inventoryLockTimeout, inventoryLockRetry = timeout, retry }()
+
+	f := filepath.Join(t.TempDir(), "inventory.json")
+	unlock, err := lockInventory(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lockInventory(f)
+	if err == nil {
+		t.Fatalf("expected the second lock to time out")
+	}
+	// the lock is acquired once released
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		unlock()
+	}()
+	unlock2, err := lockInventory(f)
+	if err != nil {
+		t.Fatalf("expected the lock to be acquired after its release: %v", err)
+	}
+	unlock2()
+}
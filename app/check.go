@@ -0,0 +1,313 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	checkPass  = "PASS"
+	checkFail  = "FAIL"
+	checkError = "ERROR"
+)
+
+// checkResult is the result of a check evaluated against a target.
+type checkResult struct {
+	Target   string   `json:"target"`
+	Check    string   `json:"check"`
+	Path     string   `json:"path"`
+	Operator string   `json:"operator"`
+	Expected string   `json:"expected,omitempty"`
+	Result   string   `json:"result"`
+	Failures []string `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// InitCheckFlags used to init or reset checkCmd flags for gnmic-prompt mode
+func (a *App) InitCheckFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CheckFile, "file", "", "", "YAML file listing the checks, each with a path, an operator and an expected value")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CheckPrefix, "prefix", "", "", "prefix of the checks paths")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CheckTarget, "target", "", "", "get request target")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CheckType, "type", "t", "ALL", "data type requested from the target. one of: ALL, CONFIG, STATE, OPERATIONAL")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CheckSub, "sub", "", false, "use subscribe ONCE mode instead of a get request")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CheckOnlyFailures, "only-failures", "", false, "report only the failed checks")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+func (a *App) CheckPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if a.Config.LocalFlags.CheckFile == "" {
+		return fmt.Errorf("missing required flag --file")
+	}
+	if a.Config.Format != "" && a.Config.Format != formatJSON {
+		return fmt.Errorf("format %q not supported by the check command, the report is printed as a table or with --format json", a.Config.Format)
+	}
+
+	a.createCollectorDialOpts()
+	return a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+}
+
+func (a *App) CheckRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitCheckFlags(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checks, err := a.Config.ReadChecksFile()
+	if err != nil {
+		return err
+	}
+	assertions := make([]*assertion, 0, len(checks))
+	for _, ch := range checks {
+		as, err := newCheckAssertion(ch)
+		if err != nil {
+			return err
+		}
+		assertions = append(assertions, as)
+	}
+	_, err = a.GetTargets()
+	if err != nil {
+		return fmt.Errorf("failed getting targets config: %v", err)
+	}
+	if a.PromptMode {
+		for _, tc := range a.Config.Targets {
+			a.AddTargetConfig(tc)
+		}
+	}
+	numTargets := len(a.Config.Targets)
+	rsps := make(chan []*checkResult, numTargets)
+	a.wg.Add(numTargets)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			rsps <- a.checkTarget(ctx, tc, checks, assertions)
+		}(tc)
+	}
+	a.wg.Wait()
+	close(rsps)
+
+	results := make([]*checkResult, 0, numTargets*len(checks))
+	for rs := range rsps {
+		results = append(results, rs...)
+	}
+	// keep the checks file order within a target
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Target < results[j].Target
+	})
+	numFailed := 0
+	for _, r := range results {
+		if r.Result != checkPass {
+			numFailed++
+		}
+	}
+	if a.Config.LocalFlags.CheckOnlyFailures {
+		failed := make([]*checkResult, 0, numFailed)
+		for _, r := range results {
+			if r.Result != checkPass {
+				failed = append(failed, r)
+			}
+		}
+		results = failed
+	}
+	err = a.printCheckReport(a.out, results)
+	if err != nil {
+		return err
+	}
+	if numFailed > 0 {
+		return fmt.Errorf("%d/%d check(s) failed", numFailed, numTargets*len(checks))
+	}
+	return nil
+}
+
+// newCheckAssertion returns the assertion evaluating check ch.
+func newCheckAssertion(ch *config.CheckConfig) (*assertion, error) {
+	as := &assertion{op: ch.Operator}
+	switch ch.Operator {
+	case assertExists, assertAbsent:
+		as.expr = ch.Operator
+		return as, nil
+	}
+	lit := fmt.Sprintf("%v", ch.Value)
+	// a string value is only compared as a number by the numeric operators
+	_, quoted := ch.Value.(string)
+	quoted = quoted && (as.op == "==" || as.op == "!=")
+	if quoted {
+		as.expr = fmt.Sprintf("value %s '%s'", ch.Operator, lit)
+	} else {
+		as.expr = fmt.Sprintf("value %s %s", ch.Operator, lit)
+	}
+	err := as.setValue(lit, quoted)
+	if err != nil {
+		return nil, fmt.Errorf("check %q: %v", ch.Name, err)
+	}
+	return as, nil
+}
+
+// checkTarget evaluates the checks against target tc, one request per check.
+func (a *App) checkTarget(ctx context.Context, tc *types.TargetConfig, checks []*config.CheckConfig, assertions []*assertion) []*checkResult {
+	results := make([]*checkResult, 0, len(checks))
+	for i, ch := range checks {
+		r := &checkResult{
+			Target:   tc.Name,
+			Check:    ch.Name,
+			Path:     ch.Path,
+			Operator: ch.Operator,
+			Result:   checkPass,
+		}
+		if ch.Value != nil {
+			r.Expected = fmt.Sprintf("%v", ch.Value)
+		}
+		results = append(results, r)
+		values, err := a.checkValues(ctx, tc, ch)
+		if err != nil {
+			a.Logger.Printf("target %q: check %q failed: %v", tc.Name, ch.Name, err)
+			r.Result = checkError
+			r.Error = err.Error()
+			continue
+		}
+		r.Failures = assertions[i].check(values)
+		if len(r.Failures) > 0 {
+			r.Result = checkFail
+		}
+	}
+	return results
+}
+
+// checkValues returns the flattened values of the check path,
+// a path not found by the target has no values.
+func (a *App) checkValues(ctx context.Context, tc *types.TargetConfig, ch *config.CheckConfig) (map[string]interface{}, error) {
+	if a.Config.LocalFlags.CheckSub {
+		rsps, err := a.checkSubscribeOnce(ctx, tc, ch)
+		if err != nil {
+			return nil, err
+		}
+		msgs := make([]proto.Message, 0, len(rsps))
+		for _, r := range rsps {
+			msgs = append(msgs, r)
+		}
+		return formatters.ResponsesFlat(msgs...)
+	}
+	req, err := a.Config.CreateCheckGetRequest(ch)
+	if err != nil {
+		return nil, err
+	}
+	err = api.Encoding(a.Config.RPCEncoding(config.RPCGet, tc))(req)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := a.sendGetRequest(ctx, tc, req)
+	if status.Code(err) == codes.NotFound {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return formatters.ResponsesFlat(rsp)
+}
+
+func (a *App) checkSubscribeOnce(ctx context.Context, tc *types.TargetConfig, ch *config.CheckConfig) ([]*gnmi.SubscribeResponse, error) {
+	req, err := a.Config.CreateCheckSubscribeRequest(ch)
+	if err != nil {
+		return nil, err
+	}
+	return a.subscribeOnceResponses(ctx, tc, req)
+}
+
+// subscribeOnceResponses sends the ONCE mode SubscribeRequest req to target tc
+// and returns the received responses, a NotFound error returns no response.
+func (a *App) subscribeOnceResponses(ctx context.Context, tc *types.TargetConfig, req *gnmi.SubscribeRequest) ([]*gnmi.SubscribeResponse, error) {
+	a.operLock.Lock()
+	t, err := a.initTarget(tc)
+	a.operLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	a.operLock.RLock()
+	err = a.CreateGNMIClient(ctx, t)
+	a.operLock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, tc.Timeout)
+	defer cancel()
+	a.Logger.Printf("sending gNMI SubscribeRequest: subscribe='%+v', mode='%+v', encoding='%+v', to %s",
+		req.Request, req.GetSubscribe().GetMode(), req.GetSubscribe().GetEncoding(), tc.Name)
+	a.saveRequest(tc, saveRPCSubscribe, req)
+	start := time.Now()
+	rsps, err := t.SubscribeOnce(ctx, req)
+	a.auditRPC(tc.Name, auditRPCSubscribe, req, nil, err, start)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	return rsps, err
+}
+
+// printCheckReport prints the check results as a table, or as a JSON list with --format json.
+func (a *App) printCheckReport(w io.Writer, results []*checkResult) error {
+	if a.Config.Format == formatJSON {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w, "no check to report")
+		return nil
+	}
+	tabData := make([][]string, 0, len(results))
+	for _, r := range results {
+		details := r.Error
+		if len(r.Failures) > 0 {
+			details = r.Failures[0]
+			if len(r.Failures) > 1 {
+				details = fmt.Sprintf("%s (+%d more)", details, len(r.Failures)-1)
+			}
+		}
+		tabData = append(tabData, []string{r.Target, r.Check, r.Operator, r.Expected, r.Result, details})
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Target", "Check", "Operator", "Expected", "Result", "Details"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+	return nil
+}
@@ -0,0 +1,100 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/openconfig/gnmic/config"
+)
+
+func TestCheckAssertion(t *testing.T) {
+	values := map[string]interface{}{
+		"system/name":     "10",
+		"system/cpu/idle": uint64(15),
+	}
+	for name, item := range map[string]struct {
+		check    *config.CheckConfig
+		failures int
+	}{
+		"string_equals": {
+			check:    &config.CheckConfig{Operator: "==", Value: "10.0"},
+			failures: 2,
+		},
+		"number_equals": {
+			check:    &config.CheckConfig{Operator: "!=", Value: 10.0},
+			failures: 1,
+		},
+		"string_number_ge": {
+			check:    &config.CheckConfig{Operator: ">=", Value: "10"},
+			failures: 0,
+		},
+		"regex": {
+			check:    &config.CheckConfig{Operator: "=~", Value: "^1"},
+			failures: 0,
+		},
+		"absent": {
+			check:    &config.CheckConfig{Operator: "absent"},
+			failures: 1,
+		},
+	} {
+		as, err := newCheckAssertion(item.check)
+		if err != nil {
+			t.Fatalf("failed at item %q: %v", name, err)
+		}
+		failures := as.check(values)
+		if len(failures) != item.failures {
+			t.Logf("failed at item %q", name)
+			t.Logf("expected %d failure(s)", item.failures)
+			t.Logf("     got: %q", failures)
+			t.Fail()
+		}
+	}
+	_, err := newCheckAssertion(&config.CheckConfig{Name: "cpu", Operator: "<", Value: "high"})
+	if err == nil {
+		t.Errorf("expected an error for a non numeric lower than check")
+	}
+}
+
+func TestPrintCheckReport(t *testing.T) {
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	results := []*checkResult{
+		{Target: "r1", Check: "name", Path: "/system/name", Operator: "exists", Result: checkPass},
+		{Target: "r1", Check: "cpu", Path: "/system/cpu", Operator: "<", Expected: "80", Result: checkFail,
+			Failures: []string{"a", "b", "c"}},
+	}
+	buf := new(bytes.Buffer)
+	err := a.printCheckReport(buf, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("a (+2 more)")) {
+		t.Errorf("unexpected table report:\n%s", buf.String())
+	}
+
+	a.Config.Format = formatJSON
+	buf.Reset()
+	err = a.printCheckReport(buf, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]*checkResult, 0)
+	err = json.Unmarshal(buf.Bytes(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || len(out[1].Failures) != 3 {
+		t.Errorf("unexpected JSON report:\n%s", buf.String())
+	}
+}
@@ -13,20 +13,29 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/config"
 	"github.com/openconfig/gnmic/outputs"
 	"github.com/openconfig/gnmic/target"
 	"github.com/openconfig/gnmic/types"
 	"github.com/openconfig/gnmic/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
 const (
 	subscriptionModeONCE = "ONCE"
 	subscriptionModePOLL = "POLL"
+	// clockSkewWarningInterval is the minimum interval
+	// between two clock skew warnings of the same target.
+	clockSkewWarningInterval = time.Minute
 )
 
 func (a *App) StartCollector(ctx context.Context) {
@@ -58,20 +67,51 @@ func (a *App) StartCollector(ctx context.Context) {
 
 		a.Logger.Printf("starting target %q listener", t.Config.Name)
 		go func(t *target.Target) {
+			defer a.recoverTarget(t.Config.Name)
 			numOnceSubscriptions := t.NumberOfOnceSubscriptions()
 			remainingOnceSubscriptions := numOnceSubscriptions
 			numSubscriptions := len(t.Subscriptions)
+			var lastSkewWarning time.Time
+			// backend serving each subscription of a balanced target
+			backends := make(map[string]string)
+			// header of each subscription stream, logged when it changes
+			headers := make(map[string]string)
+			var staleCh <-chan time.Time
+			if ticker := a.staleCheckTicker(t); ticker != nil {
+				defer ticker.Stop()
+				staleCh = ticker.C
+			}
+			lastStaleCheck := time.Now()
+			ad := a.newAnomalyDetector(t)
+			ds := a.newDownsampler(t)
+			var downsampleCh <-chan time.Time
+			if ds != nil {
+				ticker := time.NewTicker(ds.interval / 10)
+				defer ticker.Stop()
+				downsampleCh = ticker.C
+			}
 			rspChan, errChan := t.ReadSubscriptions()
 			for {
 				select {
+				case now := <-staleCh:
+					a.checkStaleTarget(t, now, &lastStaleCheck)
+				case now := <-downsampleCh:
+					for _, r := range ds.flush(now) {
+						a.setSequence(t.Config.Name, r.rsp, r.meta)
+						go a.Export(ctx, r.rsp, r.meta, t.Config.Outputs...)
+					}
 				case rsp := <-rspChan:
 					subscribeResponseReceivedCounter.WithLabelValues(t.Config.Name, rsp.SubscriptionConfig.Name).Add(1)
+					if size := proto.Size(rsp.Response); a.targetResponseReceived(t.Config.Name, size) {
+						a.checkRecvMsgSize(t.Config.Name, "Subscribe", size)
+					}
 					if a.Config.Debug {
 						a.Logger.Printf("target %q: gNMI Subscribe Response: %+v", t.Config.Name, rsp)
 					}
 					err := t.DecodeProtoBytes(rsp.Response)
 					if err != nil {
 						a.Logger.Printf("target %q: failed to decode proto bytes: %v", t.Config.Name, err)
+						subscribeResponseDroppedCounter.WithLabelValues(t.Config.Name, rsp.SubscriptionConfig.Name).Add(1)
 						continue
 					}
 					m := outputs.Meta{
@@ -79,12 +119,35 @@ func (a *App) StartCollector(ctx context.Context) {
 						"format":            a.Config.Format,
 						"subscription-name": rsp.SubscriptionName,
 					}
+					if mode := a.subscriptionMode(rsp.SubscriptionName); mode != "" {
+						m["subscription-mode"] = mode
+					}
 					if rsp.SubscriptionConfig.Target != "" {
 						m["subscription-target"] = rsp.SubscriptionConfig.Target
 					}
+					a.logStreamBackend(t, rsp, backends)
+					a.logStreamHeader(t, rsp, m, headers)
+					if rsp.Backend != "" && a.Config.IncludeMeta {
+						m["backend"] = rsp.Backend
+					}
+					if a.Config.IncludeMeta {
+						m["security-mode"] = t.Config.SecurityMode()
+					}
+					if rsp.Encoding != "" {
+						m[target.MetaEncoding] = rsp.Encoding
+					}
 					for k, v := range t.Config.EventTags {
 						m[k] = v
 					}
+					a.setReceiveTimestamp(t.Config.Name, rsp.Response, rsp.RecvTimestamp, m, &lastSkewWarning)
+					a.decomposeResponse(rsp.Response)
+					if !a.checkTimestamps(ad, t.Config.Name, rsp, time.Now()) {
+						continue
+					}
+					if !a.downsample(ds, t.Config.Name, rsp, m, time.Now()) {
+						continue
+					}
+					a.setSequence(t.Config.Name, rsp.Response, m)
 					if a.subscriptionMode(rsp.SubscriptionName) == subscriptionModeONCE {
 						a.Export(ctx, rsp.Response, m, t.Config.Outputs...)
 					} else {
@@ -105,10 +168,30 @@ func (a *App) StartCollector(ctx context.Context) {
 						return
 					}
 				case tErr := <-errChan:
+					if errors.Is(tErr.Err, target.ErrEncodingFallback) {
+						a.logEncodingFallback(fmt.Sprintf("target %q: subscription %s: %v", t.Config.Name, tErr.SubscriptionName, tErr.Err))
+						continue
+					}
+					if errors.Is(tErr.Err, target.ErrSubscribeRetry) {
+						subscribeReconnectsCounter.WithLabelValues(t.Config.Name, tErr.SubscriptionName).Add(1)
+						a.targetReconnect(t.Config.Name)
+					} else {
+						a.targetLastError(t.Config.Name, fmt.Errorf("subscription %s: %v", tErr.SubscriptionName, tErr.Err))
+					}
 					if errors.Is(tErr.Err, io.EOF) {
 						a.Logger.Printf("target %q: subscription %s closed stream(EOF)", t.Config.Name, tErr.SubscriptionName)
+					} else if rErr := a.subscriptionRejectedError(t.Config.Name, tErr.SubscriptionName, tErr.Err); rErr != nil {
+						a.Logger.Print(rErr)
+						if !a.Config.Log {
+							fmt.Fprintln(os.Stderr, rErr)
+						}
+					} else if rErr := a.multiOriginRejectedError(t.Config.Name, tErr.SubscriptionName, tErr.Err); rErr != nil {
+						a.Logger.Print(rErr)
+						if !a.Config.Log {
+							fmt.Fprintln(os.Stderr, rErr)
+						}
 					} else {
-						a.Logger.Printf("target %q: subscription %s rcv error: %v", t.Config.Name, tErr.SubscriptionName, tErr.Err)
+						a.Logger.Printf("target %q: subscription %s rcv error: %v", t.Config.Name, tErr.SubscriptionName, a.msgSizeError(tErr.Err))
 					}
 					if remainingOnceSubscriptions > 0 {
 						if a.subscriptionMode(tErr.SubscriptionName) == subscriptionModeONCE {
@@ -125,12 +208,14 @@ func (a *App) StartCollector(ctx context.Context) {
 					a.operLock.Lock()
 					delete(a.activeTargets, t.Config.Name)
 					a.operLock.Unlock()
+					a.setTargetState(t.Config.Name, targetStateStopped, nil)
 					a.Logger.Printf("target %q: listener stopped", t.Config.Name)
 					return
 				case <-ctx.Done():
 					a.operLock.Lock()
 					delete(a.activeTargets, t.Config.Name)
 					a.operLock.Unlock()
+					a.setTargetState(t.Config.Name, targetStateStopped, nil)
 					return
 				}
 			}
@@ -145,37 +230,67 @@ func (a *App) Export(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs
 	if rsp == nil {
 		return
 	}
+	a.decodeAnyValues(rsp.GetUpdate())
 	go a.updateCache(ctx, rsp, m)
 	wg := new(sync.WaitGroup)
 	// target has no outputs explicitly defined
 	if len(outs) == 0 {
 		wg.Add(len(a.Outputs))
-		for _, o := range a.Outputs {
-			go func(o outputs.Output) {
+		for name, o := range a.Outputs {
+			go func(name string, o outputs.Output) {
 				defer wg.Done()
 				defer a.operLock.RUnlock()
 				a.operLock.RLock()
-				o.Write(ctx, rsp, m)
-			}(o)
+				writeToOutput(ctx, a.outputQueues[name], a.outputStats[name], o, rsp, m)
+			}(name, o)
 		}
 		wg.Wait()
 		return
 	}
+	// with --tee, the messages of targets with explicit outputs
+	// are printed to stdout as well
+	if a.Config.LocalFlags.SubscribeTee && !strInList(config.DefaultStdoutOutput, outs) {
+		outs = append(outs[:len(outs):len(outs)], config.DefaultStdoutOutput)
+	}
 	// write to the outputs defined under the target
 	for _, name := range outs {
 		a.operLock.RLock()
 		if o, ok := a.Outputs[name]; ok {
 			wg.Add(1)
-			go func(o outputs.Output) {
+			go func(q *outputQueue, s *outputStats, o outputs.Output) {
 				defer wg.Done()
-				o.Write(ctx, rsp, m)
-			}(o)
+				writeToOutput(ctx, q, s, o, rsp, m)
+			}(a.outputQueues[name], a.outputStats[name], o)
 		}
 		a.operLock.RUnlock()
 	}
 	wg.Wait()
 }
 
+// setReceiveTimestamp adds the local receive time of a notification to the meta m.
+// It logs a warning, at most once per clockSkewWarningInterval, if the notification timestamp
+// is off by more than --max-clock-skew, and replaces it with the receive time
+// if --override-timestamps is set.
+func (a *App) setReceiveTimestamp(name string, rsp *gnmi.SubscribeResponse, recvTS int64, m outputs.Meta, lastWarning *time.Time) {
+	notif := rsp.GetUpdate()
+	if notif == nil || recvTS == 0 {
+		return
+	}
+	m["recv-timestamp"] = strconv.FormatInt(recvTS, 10)
+	maxSkew := a.Config.LocalFlags.SubscribeMaxClockSkew
+	if skew := target.ClockSkew(rsp, recvTS); maxSkew > 0 && skew > maxSkew {
+		recvTime := time.Unix(0, recvTS)
+		if recvTime.Sub(*lastWarning) >= clockSkewWarningInterval {
+			*lastWarning = recvTime
+			a.Logger.Printf("target %q: warning: notification timestamp %s is %s away from the receive time %s, exceeding the max clock skew %s",
+				name, time.Unix(0, notif.GetTimestamp()).In(a.location).Format(time.RFC3339Nano), skew, recvTime.In(a.location).Format(time.RFC3339Nano), maxSkew)
+		}
+	}
+	if a.Config.LocalFlags.SubscribeOverrideTS {
+		notif.Timestamp = recvTS
+	}
+}
+
 func (a *App) updateCache(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs.Meta) {
 	if a.c == nil {
 		return
@@ -209,6 +324,53 @@ func (a *App) subscriptionMode(name string) string {
 	return ""
 }
 
+// subscriptionRejectedError returns a descriptive error if err is target tName rejecting
+// subscription subName with a ResourceExhausted status, which is what a target returns
+// when its maximum number of subscriptions or subscribed paths is exceeded.
+// It returns nil for any other error.
+func (a *App) subscriptionRejectedError(tName, subName string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted || strings.Contains(st.Message(), "larger than max") {
+		return nil
+	}
+	numPaths := 0
+	if sc, ok := a.Config.Subscriptions[subName]; ok {
+		numPaths = len(sc.Paths)
+	}
+	return fmt.Errorf("target %q rejected subscription %s with %d path(s), the target maximum number of subscriptions or paths is likely exceeded: %s",
+		tName, subName, numPaths, st.Message())
+}
+
+// multiOriginRejectedError returns a descriptive error if err is target tName rejecting
+// subscription subName mixing paths of different origins, which some targets do not support
+// in a single subscription list. It returns nil for the other errors and subscriptions.
+func (a *App) multiOriginRejectedError(tName, subName string, err error) error {
+	sc, ok := a.Config.Subscriptions[subName]
+	if !ok {
+		return nil
+	}
+	origins := config.PathsOrigins(sc)
+	if len(origins) < 2 {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	switch st.Code() {
+	case codes.InvalidArgument, codes.Unimplemented, codes.FailedPrecondition:
+	default:
+		return nil
+	}
+	for i, o := range origins {
+		if o == "" {
+			origins[i] = "<none>"
+		}
+	}
+	return fmt.Errorf("target %q rejected subscription %s mixing the path origins %s, the target may not support multiple origins in a subscription, split it into one subscription per origin: %s",
+		tName, subName, strings.Join(origins, ", "), st.Message())
+}
+
 func (a *App) GetModels(ctx context.Context, tc *types.TargetConfig) ([]*gnmi.ModelData, error) {
 	capRsp, err := a.ClientCapabilities(ctx, tc)
 	if err != nil {
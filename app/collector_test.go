@@ -0,0 +1,197 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func notificationAt(ts int64) *gnmi.SubscribeResponse {
+	return &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{Timestamp: ts},
+		},
+	}
+}
+
+var setReceiveTimestampTestSet = map[string]struct {
+	maxSkew    time.Duration
+	overrideTS bool
+	// notifications timestamps offsets from the receive times
+	offsets []time.Duration
+	// receive times offsets from the first one
+	recvOffsets []time.Duration
+	warnings    int
+}{
+	"no_skew": {
+		maxSkew:     5 * time.Minute,
+		offsets:     []time.Duration{0, time.Second, -time.Second},
+		recvOffsets: []time.Duration{0, time.Second, 2 * time.Second},
+		warnings:    0,
+	},
+	"skew_rate_limited": {
+		maxSkew:     5 * time.Minute,
+		offsets:     []time.Duration{-time.Hour, -time.Hour, time.Hour},
+		recvOffsets: []time.Duration{0, time.Second, 2 * time.Second},
+		warnings:    1,
+	},
+	"skew_after_interval": {
+		maxSkew:     5 * time.Minute,
+		offsets:     []time.Duration{-time.Hour, -time.Hour, -time.Hour},
+		recvOffsets: []time.Duration{0, time.Second, clockSkewWarningInterval + time.Second},
+		warnings:    2,
+	},
+	"check_disabled": {
+		maxSkew:     0,
+		offsets:     []time.Duration{-time.Hour},
+		recvOffsets: []time.Duration{0},
+		warnings:    0,
+	},
+	"override_timestamps": {
+		maxSkew:     5 * time.Minute,
+		overrideTS:  true,
+		offsets:     []time.Duration{-time.Hour},
+		recvOffsets: []time.Duration{0},
+		warnings:    1,
+	},
+}
+
+func TestSetReceiveTimestamp(t *testing.T) {
+	for name, item := range setReceiveTimestampTestSet {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			a := New()
+			a.Logger = log.New(buf, "", 0)
+			a.Config.LocalFlags.SubscribeMaxClockSkew = item.maxSkew
+			a.Config.LocalFlags.SubscribeOverrideTS = item.overrideTS
+			var lastWarning time.Time
+			start := time.Now()
+			for i, offset := range item.offsets {
+				recvTS := start.Add(item.recvOffsets[i]).UnixNano()
+				targetTS := recvTS + int64(offset)
+				rsp := notificationAt(targetTS)
+				m := outputs.Meta{}
+				a.setReceiveTimestamp("t1", rsp, recvTS, m, &lastWarning)
+				if m["recv-timestamp"] != strconv.FormatInt(recvTS, 10) {
+					t.Logf("failed at item %q", name)
+					t.Errorf("unexpected recv-timestamp meta %q", m["recv-timestamp"])
+				}
+				expectedTS := targetTS
+				if item.overrideTS {
+					expectedTS = recvTS
+				}
+				if rsp.GetUpdate().GetTimestamp() != expectedTS {
+					t.Logf("failed at item %q", name)
+					t.Errorf("expected timestamp %d, got %d", expectedTS, rsp.GetUpdate().GetTimestamp())
+				}
+			}
+			warnings := strings.Count(buf.String(), "exceeding the max clock skew")
+			if warnings != item.warnings {
+				t.Logf("failed at item %q", name)
+				t.Errorf("expected %d warnings, got %d: %s", item.warnings, warnings, buf.String())
+			}
+		})
+	}
+}
+
+func TestSubscriptionRejectedError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"max_subscriptions": {
+			err:  status.Error(codes.ResourceExhausted, "too many paths"),
+			want: `target "r1" rejected subscription sub1 with 3 path(s), the target maximum number of subscriptions or paths is likely exceeded: too many paths`,
+		},
+		"max_msg_size": {
+			err: status.Error(codes.ResourceExhausted, "grpc: received message larger than max (5000 vs. 4096)"),
+		},
+		"other_code": {
+			err: status.Error(codes.Unavailable, "connection refused"),
+		},
+		"not_a_status": {
+			err: io.ErrUnexpectedEOF,
+		},
+	}
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", Paths: []string{"/a", "/b", "/c"}},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ""
+			if err := a.subscriptionRejectedError("r1", "sub1", item.err); err != nil {
+				got = err.Error()
+			}
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestMultiOriginRejectedError(t *testing.T) {
+	tests := map[string]struct {
+		sub  string
+		err  error
+		want string
+	}{
+		"multi_origin_invalid_argument": {
+			sub:  "multi",
+			err:  status.Error(codes.InvalidArgument, "mixed origins not supported"),
+			want: `target "r1" rejected subscription multi mixing the path origins <none>, native, openconfig, the target may not support multiple origins in a subscription, split it into one subscription per origin: mixed origins not supported`,
+		},
+		"multi_origin_other_code": {
+			sub: "multi",
+			err: status.Error(codes.Unavailable, "connection refused"),
+		},
+		"single_origin": {
+			sub: "single",
+			err: status.Error(codes.InvalidArgument, "unknown path"),
+		},
+		"unknown_subscription": {
+			sub: "unknown",
+			err: status.Error(codes.InvalidArgument, "unknown path"),
+		},
+	}
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"multi":  {Name: "multi", Paths: []string{"native:/a", "openconfig:/b", "/c"}},
+		"single": {Name: "single", Paths: []string{"openconfig:/a", "openconfig:/b"}},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ""
+			if err := a.multiOriginRejectedError("r1", item.sub, item.err); err != nil {
+				got = err.Error()
+			}
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (a *App) ConfigEncryptRunE(cmd *cobra.Command, args []string) error {
+	key, err := a.Config.ConfigKey()
+	if err != nil {
+		return err
+	}
+	value := a.Config.ConfigEncryptValue
+	if value == "" {
+		value, err = config.ReadPassword("value to encrypt")
+		if err != nil {
+			return err
+		}
+	}
+	if value == "" {
+		return errors.New("empty value")
+	}
+	enc, err := config.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.out, enc)
+	return nil
+}
+
+func (a *App) InitConfigEncryptFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigEncryptValue, "value", "", "", "value to encrypt, read from stdin if not set")
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
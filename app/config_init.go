@@ -0,0 +1,197 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// minimalConfigFlags are the global flags written by `config init --minimal`.
+var minimalConfigFlags = []string{"address", "username", "password"}
+
+const configSkeletonHeader = `# gnmic configuration file
+# documentation: https://gnmic.openconfig.net
+#
+# each global flag can be set in this file using its long name as key,
+# the values below are the flags default values.
+`
+
+const configSkeletonSections = `
+# targets configuration, the global values above apply
+# to the fields not set under a target.
+targets:
+  # the target name, used as the target address if address is not set.
+  router1:
+    # target address in the format host:port
+    address: router1:{{ .Port }}
+    # per target overrides of the global flags
+    username: admin
+    password: admin
+    skip-verify: true
+    timeout: {{ .Timeout }}
+    # subscriptions to create on this target, defaults to all the subscriptions
+    subscriptions:
+      - port-stats
+      - system-events
+    # outputs the target subscription responses are written to, defaults to all the outputs
+    outputs:
+      - stdout
+    # static tags added to all the events of this target
+    event-tags:
+      site: site1
+  router2:
+    address: router2:{{ .Port }}
+    insecure: true
+    subscriptions:
+      - port-stats
+
+# named subscriptions, referenced by the targets subscriptions list
+# or by the subscribe command --name flag.
+subscriptions:
+  port-stats:
+    paths:
+      - /interfaces/interface/statistics
+    # one of once, stream or poll
+    mode: stream
+    # one of sample, on-change or target-defined
+    stream-mode: sample
+    sample-interval: 10s
+    # overrides the global encoding
+    encoding: {{ .Encoding }}
+  system-events:
+    paths:
+      - /system/state
+    mode: stream
+    stream-mode: on-change
+
+# outputs the subscription responses are written to,
+# referenced by the targets outputs list or by the subscribe command --output flag.
+outputs:
+  stdout:
+    # output type, one of {{ .OutputTypes }}
+    type: file
+    file-type: stdout
+    # event format, required by the event processors
+    format: event
+    # event processors applied to the events before they are written
+    event-processors:
+      - drop-debug-counters
+
+# event processors, referenced by the outputs event-processors list.
+processors:
+  drop-debug-counters:
+    # processor type and its configuration
+    event-drop:
+      value-names:
+        - ".*debug.*"
+`
+
+func (a *App) ConfigInitRunE(cmd *cobra.Command, args []string) error {
+	output := a.out
+	if a.Config.ConfigInitOutput != "" {
+		f, err := os.OpenFile(a.Config.ConfigInitOutput, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+	return a.writeConfigSkeleton(output, a.Config.ConfigInitMinimal)
+}
+
+func (a *App) InitConfigInitFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigInitOutput, "output", "o", "", "output file, defaults to stdout")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ConfigInitMinimal, "minimal", "", false, "only write the address, username and password fields")
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// writeConfigSkeleton writes a commented configuration file to w,
+// the global flags are set to their default values.
+func (a *App) writeConfigSkeleton(w io.Writer, minimal bool) error {
+	sb := new(strings.Builder)
+	sb.WriteString(configSkeletonHeader)
+	if minimal {
+		for _, name := range minimalConfigFlags {
+			f := a.RootCmd.PersistentFlags().Lookup(name)
+			if f == nil {
+				continue
+			}
+			writeFlagEntry(sb, f)
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	a.RootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		// a configuration file cannot point to another one
+		if f.Name == "config" {
+			return
+		}
+		writeFlagEntry(sb, f)
+	})
+	tpl, err := template.New("config-skeleton").Parse(configSkeletonSections)
+	if err != nil {
+		return err
+	}
+	outputTypes := make([]string, 0, len(outputs.OutputTypes))
+	for ot := range outputs.OutputTypes {
+		outputTypes = append(outputTypes, ot)
+	}
+	sort.Strings(outputTypes)
+	err = tpl.Execute(sb, map[string]interface{}{
+		"Port":        a.RootCmd.PersistentFlags().Lookup("port").DefValue,
+		"Timeout":     a.RootCmd.PersistentFlags().Lookup("timeout").DefValue,
+		"Encoding":    a.RootCmd.PersistentFlags().Lookup("encoding").DefValue,
+		"OutputTypes": strings.Join(outputTypes, ", "),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// writeFlagEntry writes the flag usage as a comment
+// followed by the flag name and its default value as a YAML entry.
+func writeFlagEntry(sb *strings.Builder, f *pflag.Flag) {
+	fmt.Fprintf(sb, "\n# %s\n", f.Usage)
+	fmt.Fprintf(sb, "%s: %s\n", f.Name, flagDefaultYAML(f))
+}
+
+// flagDefaultYAML returns the flag default value formatted as a YAML value.
+func flagDefaultYAML(f *pflag.Flag) string {
+	switch f.Value.Type() {
+	case "string":
+		return strconv.Quote(f.DefValue)
+	case "stringSlice", "stringArray":
+		items := strings.Trim(f.DefValue, "[]")
+		if items == "" {
+			return "[]"
+		}
+		vals := strings.Split(items, ",")
+		for i, v := range vals {
+			vals[i] = strconv.Quote(v)
+		}
+		return "[" + strings.Join(vals, ", ") + "]"
+	default:
+		return f.DefValue
+	}
+}
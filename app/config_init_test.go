@@ -0,0 +1,64 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/spf13/viper"
+)
+
+var configSkeletonTestSet = map[string]struct {
+	minimal bool
+	keys    []string
+}{
+	"full": {
+		minimal: false,
+		keys:    []string{"address", "port", "timeout", "targets", "subscriptions", "outputs", "processors"},
+	},
+	"minimal": {
+		minimal: true,
+		keys:    []string{"address", "username", "password"},
+	},
+}
+
+func TestWriteConfigSkeleton(t *testing.T) {
+	for name, item := range configSkeletonTestSet {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			a.InitGlobalFlags()
+			b := new(bytes.Buffer)
+			err := a.writeConfigSkeleton(b, item.minimal)
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			v := viper.NewWithOptions(viper.KeyDelimiter("/"))
+			v.SetConfigType("yaml")
+			err = v.ReadConfig(bytes.NewReader(b.Bytes()))
+			if err != nil {
+				t.Fatalf("failed at item %q: generated file is not valid YAML: %v\n%s", name, err, b.String())
+			}
+			for _, k := range item.keys {
+				if !v.InConfig(k) {
+					t.Errorf("failed at item %q: missing key %q", name, k)
+				}
+			}
+			if item.minimal && len(v.AllKeys()) != len(item.keys) {
+				t.Errorf("failed at item %q: expected %d keys, got %v", name, len(item.keys), v.AllKeys())
+			}
+			issues := config.Lint(v, a.flagKeys())
+			for _, i := range issues {
+				t.Logf("failed at item %q: lint issue: %s", name, i)
+				t.Fail()
+			}
+		})
+	}
+}
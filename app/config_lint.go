@@ -0,0 +1,80 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// ConfigPreRunE replaces the root command PreRunE for the config commands:
+// the configuration file is checked, not applied.
+func (a *App) ConfigPreRunE(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (a *App) ConfigLintRunE(cmd *cobra.Command, args []string) error {
+	file := a.Config.FileConfig.ConfigFileUsed()
+	if len(args) > 0 {
+		file = args[0]
+	}
+	if file == "" {
+		return errors.New("no configuration file found")
+	}
+	b, err := utils.ReadFile(a.ctx, file)
+	if err != nil {
+		return err
+	}
+	v := viper.NewWithOptions(viper.KeyDelimiter("/"))
+	v.SetConfigFile(file)
+	err = v.ReadConfig(bytes.NewBuffer(b))
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file %q: %v", file, err)
+	}
+	issues := config.Lint(v, a.flagKeys())
+	for _, i := range issues {
+		fmt.Fprintln(a.out, i)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issue(s) found in configuration file %q", len(issues), file)
+	}
+	fmt.Fprintf(a.out, "configuration file %q is valid\n", file)
+	return nil
+}
+
+// flagKeys returns the configuration file keys matching a command flag,
+// `<flag>` for the global flags and `<cmd>-<flag>` for the local ones.
+func (a *App) flagKeys() []string {
+	keys := make([]string, 0)
+	a.RootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		keys = append(keys, f.Name)
+	})
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		for _, c := range cmd.Commands() {
+			c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+				keys = append(keys, fmt.Sprintf("%s-%s", c.Name(), f.Name))
+			})
+			visit(c)
+		}
+	}
+	visit(a.RootCmd)
+	// keys without a flag
+	keys = append(keys, config.EncodingKeys...)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,347 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// outputDestinationKeys are the outputs configuration keys
+// describing where the messages are sent, by precedence.
+var outputDestinationKeys = []string{"address", "url", "endpoint", "listen", "filename", "file-type"}
+
+// configTarget is a target as printed by the config targets command.
+type configTarget struct {
+	*types.TargetConfig
+	Reachable *bool  `json:"reachable,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// configOutput is an output as printed by the config outputs command.
+type configOutput struct {
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Config map[string]interface{} `json:"config"`
+}
+
+func (a *App) InitConfigTargetsFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ConfigTargetsTest, "test", "", false, "dial each target and report whether it is reachable")
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+func (a *App) ConfigTargetsRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	tcs, err := a.Config.GetTargets()
+	if err != nil && !errors.Is(err, config.ErrNoTargetsFound) {
+		return err
+	}
+	targets := make([]*configTarget, 0, len(tcs))
+	for _, tc := range tcs {
+		targets = append(targets, &configTarget{TargetConfig: redactTargetConfig(tc)})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	if !a.Config.LocalFlags.ConfigTargetsTest {
+		return a.printConfigTargets(a.out, targets)
+	}
+	a.createCollectorDialOpts()
+	a.testTargets(cmd.Context(), tcs, targets)
+	err = a.printConfigTargets(a.out, targets)
+	if err != nil {
+		return err
+	}
+	numFailed := 0
+	for _, t := range targets {
+		if !*t.Reachable {
+			numFailed++
+		}
+	}
+	if numFailed > 0 {
+		return fmt.Errorf("%d/%d target(s) unreachable", numFailed, len(targets))
+	}
+	return nil
+}
+
+// testTargets dials the targets tcs, in parallel, and sets the result in the matching targets entry.
+func (a *App) testTargets(ctx context.Context, tcs map[string]*types.TargetConfig, targets []*configTarget) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	wg := new(sync.WaitGroup)
+	wg.Add(len(targets))
+	for _, ct := range targets {
+		go func(ct *configTarget) {
+			defer wg.Done()
+			err := a.dialTarget(ctx, tcs[ct.Name])
+			reachable := err == nil
+			ct.Reachable = &reachable
+			if err != nil {
+				a.Logger.Printf("target %q: %v", ct.Name, err)
+				ct.Error = err.Error()
+			}
+		}(ct)
+	}
+	wg.Wait()
+}
+
+// dialTarget creates a gRPC client for target tc then closes it.
+func (a *App) dialTarget(ctx context.Context, tc *types.TargetConfig) error {
+	a.operLock.Lock()
+	t, err := a.initTarget(tc)
+	a.operLock.Unlock()
+	if err != nil {
+		return err
+	}
+	a.operLock.RLock()
+	err = a.CreateGNMIClient(ctx, t)
+	a.operLock.RUnlock()
+	if err != nil {
+		return err
+	}
+	return t.Close()
+}
+
+// redactTargetConfig returns a copy of target config tc with its password and token masked.
+func redactTargetConfig(tc *types.TargetConfig) *types.TargetConfig {
+	ntc := *tc
+	redacted := redactedValue
+	if ntc.Password != nil && *ntc.Password != "" {
+		ntc.Password = &redacted
+	}
+	if ntc.Token != nil && *ntc.Token != "" {
+		ntc.Token = &redacted
+	}
+	return &ntc
+}
+
+// printConfigTargets prints the targets as a table, or as a JSON list with --format json.
+// The reachability column is only printed if the targets were tested.
+func (a *App) printConfigTargets(w io.Writer, targets []*configTarget) error {
+	if a.Config.Format == formatJSON {
+		return printJSONList(w, targets)
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(w, "no target configured")
+		return nil
+	}
+	tested := targets[0].Reachable != nil
+	header := []string{"Name", "Address", "Username", "TLS", "Timeout", "Subscriptions", "Outputs"}
+	if tested {
+		header = append(header, "Reachable")
+	}
+	tabData := make([][]string, 0, len(targets))
+	for _, t := range targets {
+		var username string
+		if t.Username != nil {
+			username = *t.Username
+		}
+		row := []string{
+			t.Name,
+			t.Address,
+			username,
+			targetTLSMode(t.TargetConfig),
+			t.Timeout.String(),
+			listOrAll(t.Subscriptions),
+			listOrAll(t.Outputs),
+		}
+		if tested {
+			reachable := "yes"
+			if !*t.Reachable {
+				reachable = fmt.Sprintf("no: %s", t.Error)
+			}
+			row = append(row, reachable)
+		}
+		tabData = append(tabData, row)
+	}
+	renderTable(w, header, tabData)
+	return nil
+}
+
+func (a *App) ConfigSubscriptionsRunE(cmd *cobra.Command, args []string) error {
+	subs, err := a.Config.GetSubscriptions(cmd)
+	if err != nil {
+		return err
+	}
+	subscriptions := make([]*types.SubscriptionConfig, 0, len(subs))
+	for _, sc := range subs {
+		nsc := *sc
+		err = a.Config.ResolveSubscription(&nsc)
+		if err != nil {
+			return err
+		}
+		subscriptions = append(subscriptions, &nsc)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].Name < subscriptions[j].Name
+	})
+	return a.printConfigSubscriptions(a.out, subscriptions)
+}
+
+// printConfigSubscriptions prints the subscriptions as a table, or as a JSON list with --format json.
+func (a *App) printConfigSubscriptions(w io.Writer, subscriptions []*types.SubscriptionConfig) error {
+	if a.Config.Format == formatJSON {
+		return printJSONList(w, subscriptions)
+	}
+	if len(subscriptions) == 0 {
+		fmt.Fprintln(w, "no subscription configured")
+		return nil
+	}
+	tabData := make([][]string, 0, len(subscriptions))
+	for _, sc := range subscriptions {
+		var sample, heartbeat string
+		if sc.SampleInterval != nil {
+			sample = sc.SampleInterval.String()
+		}
+		if sc.HeartbeatInterval != nil {
+			heartbeat = sc.HeartbeatInterval.String()
+		}
+		tabData = append(tabData, []string{
+			sc.Name,
+			strings.ToUpper(sc.Mode),
+			strings.ToUpper(sc.StreamMode),
+			strings.ToUpper(sc.Encoding),
+			sample,
+			heartbeat,
+			sc.Prefix,
+			strings.Join(sc.Paths, "\n"),
+		})
+	}
+	renderTable(w, []string{"Name", "Mode", "Stream Mode", "Encoding", "Sample Interval", "Heartbeat Interval", "Prefix", "Paths"}, tabData)
+	return nil
+}
+
+func (a *App) ConfigOutputsRunE(cmd *cobra.Command, args []string) error {
+	outs, err := a.Config.GetOutputs()
+	if err != nil {
+		return err
+	}
+	outputs := make([]*configOutput, 0, len(outs))
+	for name, cfg := range outs {
+		outType, _ := cfg["type"].(string)
+		outputs = append(outputs, &configOutput{
+			Name:   name,
+			Type:   outType,
+			Config: redactSettings(cfg).(map[string]interface{}),
+		})
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		return outputs[i].Name < outputs[j].Name
+	})
+	return a.printConfigOutputs(a.out, outputs)
+}
+
+// printConfigOutputs prints the outputs as a table, or as a JSON list with --format json.
+func (a *App) printConfigOutputs(w io.Writer, outputs []*configOutput) error {
+	if a.Config.Format == formatJSON {
+		return printJSONList(w, outputs)
+	}
+	if len(outputs) == 0 {
+		fmt.Fprintln(w, "no output configured")
+		return nil
+	}
+	tabData := make([][]string, 0, len(outputs))
+	for _, o := range outputs {
+		var format string
+		if f, ok := o.Config["format"]; ok {
+			format = fmt.Sprintf("%v", f)
+		}
+		var processors string
+		if eps, ok := o.Config["event-processors"].([]interface{}); ok {
+			names := make([]string, 0, len(eps))
+			for _, ep := range eps {
+				names = append(names, fmt.Sprintf("%v", ep))
+			}
+			processors = strings.Join(names, ", ")
+		}
+		tabData = append(tabData, []string{o.Name, o.Type, outputDestination(o.Config), format, processors})
+	}
+	renderTable(w, []string{"Name", "Type", "Destination", "Format", "Event Processors"}, tabData)
+	return nil
+}
+
+// outputDestination returns the address, URL or file an output sends its messages to.
+func outputDestination(cfg map[string]interface{}) string {
+	for _, k := range outputDestinationKeys {
+		v, ok := cfg[k]
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case []interface{}:
+			addrs := make([]string, 0, len(v))
+			for _, vv := range v {
+				addrs = append(addrs, fmt.Sprintf("%v", vv))
+			}
+			return strings.Join(addrs, ",")
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+// targetTLSMode returns how the connection to target tc is secured:
+// insecure, skip-verify or tls.
+func targetTLSMode(tc *types.TargetConfig) string {
+	if tc.Insecure != nil && *tc.Insecure {
+		return "insecure"
+	}
+	if tc.SkipVerify != nil && *tc.SkipVerify {
+		return "skip-verify"
+	}
+	return "tls"
+}
+
+// listOrAll returns the comma separated list l, or "all" if it is empty.
+func listOrAll(l []string) string {
+	if len(l) == 0 {
+		return "all"
+	}
+	return strings.Join(l, ", ")
+}
+
+func printJSONList(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(b))
+	return nil
+}
+
+func renderTable(w io.Writer, header []string, tabData [][]string) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+}
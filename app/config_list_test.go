@@ -0,0 +1,116 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestRedactTargetConfig(t *testing.T) {
+	username, password, token := "admin", "secret", "abc"
+	tc := &types.TargetConfig{Name: "r1", Username: &username, Password: &password, Token: &token}
+	rtc := redactTargetConfig(tc)
+	if *rtc.Password != redactedValue || *rtc.Token != redactedValue {
+		t.Errorf("secrets not redacted: %s", rtc)
+	}
+	if *rtc.Username != username {
+		t.Errorf("unexpected username: %q", *rtc.Username)
+	}
+	if *tc.Password != password || *tc.Token != token {
+		t.Errorf("target config modified")
+	}
+}
+
+func TestPrintConfigTargets(t *testing.T) {
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	insecure := true
+	reachable, unreachable := true, false
+	targets := []*configTarget{
+		{
+			TargetConfig: &types.TargetConfig{Name: "r1", Address: "10.0.0.1:57400", Timeout: 10 * time.Second, Insecure: &insecure},
+			Reachable:    &reachable,
+		},
+		{
+			TargetConfig: &types.TargetConfig{Name: "r2", Address: "10.0.0.2:57400", Timeout: 10 * time.Second, Outputs: []string{"o1"}},
+			Reachable:    &unreachable,
+			Error:        "timeout",
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := a.printConfigTargets(buf, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Reachable", "insecure", "no: timeout", "o1"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("missing %q in table:\n%s", s, buf.String())
+		}
+	}
+
+	a.Config.Format = formatJSON
+	buf.Reset()
+	err = a.printConfigTargets(buf, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]map[string]interface{}, 0)
+	err = json.Unmarshal(buf.Bytes(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[0]["name"] != "r1" || out[1]["reachable"] != false || out[1]["error"] != "timeout" {
+		t.Errorf("unexpected JSON list:\n%s", buf.String())
+	}
+}
+
+var outputDestinationTestSet = map[string]struct {
+	in  map[string]interface{}
+	out string
+}{
+	"address": {
+		in:  map[string]interface{}{"type": "nats", "address": "localhost:4222"},
+		out: "localhost:4222",
+	},
+	"url": {
+		in:  map[string]interface{}{"type": "influxdb", "url": "http://localhost:8086"},
+		out: "http://localhost:8086",
+	},
+	"stdout": {
+		in:  map[string]interface{}{"type": "file", "file-type": "stdout"},
+		out: "stdout",
+	},
+	"list": {
+		in:  map[string]interface{}{"type": "kafka", "address": []interface{}{"k1:9092", "k2:9092"}},
+		out: "k1:9092,k2:9092",
+	},
+	"none": {
+		in:  map[string]interface{}{"type": "asciigraph"},
+		out: "",
+	},
+}
+
+func TestOutputDestination(t *testing.T) {
+	for name, item := range outputDestinationTestSet {
+		t.Run(name, func(t *testing.T) {
+			out := outputDestination(item.in)
+			if out != item.out {
+				t.Errorf("failed at item %q: expected %q, got %q", name, item.out, out)
+			}
+		})
+	}
+}
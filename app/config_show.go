@@ -0,0 +1,191 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+func (a *App) ConfigShowRunE(cmd *cobra.Command, args []string) error {
+	if a.Config.ConfigShowSources {
+		return a.printSettingsSources(cmd)
+	}
+	var settings map[string]interface{}
+	if a.Config.ConfigShowResolved {
+		settings = a.resolvedSettings()
+	} else {
+		settings = a.Config.FileSettings()
+	}
+	settings = redactSettings(settings).(map[string]interface{})
+	var b []byte
+	var err error
+	if a.Config.Format == formatJSON {
+		b, err = json.MarshalIndent(settings, "", "  ")
+	} else {
+		b, err = yaml.Marshal(settings)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.out, strings.TrimSpace(string(b)))
+	return nil
+}
+
+func (a *App) InitConfigShowFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ConfigShowResolved, "resolved", "", false, "show the effective configuration: the merged configuration files, the environment variables and the flags values")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ConfigShowSources, "sources", "", false, "show each effective setting with its source: flag, env, file and line, or default")
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// resolvedSettings returns the global flags values and the configuration sections,
+// the commands local flags are only included if they are set in the configuration files
+// or in the environment.
+func (a *App) resolvedSettings() map[string]interface{} {
+	settings := a.Config.FileConfig.AllSettings()
+	flagKeys := a.flagKeys()
+	for k := range settings {
+		if a.RootCmd.PersistentFlags().Lookup(k) != nil {
+			continue
+		}
+		if !strInList(k, flagKeys) {
+			// configuration section
+			continue
+		}
+		if !a.Config.FileConfig.InConfig(k) {
+			delete(settings, k)
+		}
+	}
+	return settings
+}
+
+// redactSettings returns a copy of v where the values of the keys
+// holding a secret, such as passwords and tokens, are masked.
+func redactSettings(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			if s, ok := vv.(string); ok && s != "" && isSecretKey(k) {
+				m[k] = redactedValue
+				continue
+			}
+			m[k] = redactSettings(vv)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, vv := range v {
+			l = append(l, redactSettings(vv))
+		}
+		return l
+	}
+	return v
+}
+
+// printSettingsSources prints each effective setting, sorted by key, with where its value comes from.
+func (a *App) printSettingsSources(cmd *cobra.Command) error {
+	vss := a.settingsSources(cmd)
+	if a.Config.Format == formatJSON {
+		b, err := json.MarshalIndent(vss, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, string(b))
+		return nil
+	}
+	for _, vs := range vss {
+		v, err := json.Marshal(vs.Value)
+		if err != nil {
+			v = []byte(fmt.Sprintf("%v", vs.Value))
+		}
+		fmt.Fprintf(a.out, "%s: %s (%s)\n", vs.Key, v, vs)
+	}
+	return nil
+}
+
+// settingsSources returns the source of the global flags, of the local flags
+// set in the configuration files or in the environment, and of the configuration sections keys.
+// The secret values are redacted.
+func (a *App) settingsSources(cmd *cobra.Command) []*config.ValueSource {
+	keys := make([]string, 0)
+	a.RootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		keys = append(keys, f.Name)
+	})
+	flagKeys := a.flagKeys()
+	for _, k := range a.Config.FileConfig.AllKeys() {
+		if strInList(k, keys) {
+			continue
+		}
+		if strInList(k, flagKeys) && a.Config.KeySource(k).Source == config.SourceDefault {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	// the running command flags set on the command line
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		k := fmt.Sprintf("%s-%s", cmd.Name(), f.Name)
+		if f.Changed && !strInList(k, keys) {
+			keys = append(keys, k)
+		}
+	})
+	sort.Strings(keys)
+	vss := make([]*config.ValueSource, 0, len(keys))
+	for _, k := range keys {
+		vs := a.Config.KeySource(k)
+		if f := a.cliFlag(cmd, k); f != nil {
+			vs.Source = config.SourceFlag
+			vs.Name = f.Name
+			vs.File = ""
+			vs.Line = 0
+		}
+		if s, ok := vs.Value.(string); ok && s != "" && isSecretKey(k[strings.LastIndex(k, "/")+1:]) {
+			vs.Value = redactedValue
+		} else {
+			vs.Value = redactSettings(vs.Value)
+		}
+		vss = append(vss, vs)
+	}
+	return vss
+}
+
+// cliFlag returns the flag matching the configuration key k if it is set on the command line.
+func (a *App) cliFlag(cmd *cobra.Command, k string) *pflag.Flag {
+	if f := a.RootCmd.PersistentFlags().Lookup(k); f != nil {
+		if f.Changed {
+			return f
+		}
+		return nil
+	}
+	if !strings.HasPrefix(k, cmd.Name()+"-") {
+		return nil
+	}
+	if f := cmd.LocalFlags().Lookup(strings.TrimPrefix(k, cmd.Name()+"-")); f != nil && f.Changed {
+		return f
+	}
+	return nil
+}
+
+func isSecretKey(k string) bool {
+	if isPasswordKey(k) {
+		return true
+	}
+	k = strings.ToLower(k)
+	return strings.Contains(k, "token") || strings.Contains(k, "secret")
+}
@@ -0,0 +1,74 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+var redactSettingsTestSet = map[string]struct {
+	in  map[string]interface{}
+	out map[string]interface{}
+}{
+	"globals": {
+		in: map[string]interface{}{
+			"username":       "admin",
+			"password":       "admin",
+			"token":          "",
+			"log-tls-secret": true,
+		},
+		out: map[string]interface{}{
+			"username":       "admin",
+			"password":       redactedValue,
+			"token":          "",
+			"log-tls-secret": true,
+		},
+	},
+	"nested": {
+		in: map[string]interface{}{
+			"targets": map[string]interface{}{
+				"router1": map[string]interface{}{
+					"address":  "10.0.0.1:57400",
+					"password": "pass1",
+					"token":    "abc",
+				},
+			},
+			"outputs": []interface{}{
+				map[string]interface{}{"client-secret": "s3cr3t"},
+			},
+		},
+		out: map[string]interface{}{
+			"targets": map[string]interface{}{
+				"router1": map[string]interface{}{
+					"address":  "10.0.0.1:57400",
+					"password": redactedValue,
+					"token":    redactedValue,
+				},
+			},
+			"outputs": []interface{}{
+				map[string]interface{}{"client-secret": redactedValue},
+			},
+		},
+	},
+}
+
+func TestRedactSettings(t *testing.T) {
+	for name, item := range redactSettingsTestSet {
+		t.Run(name, func(t *testing.T) {
+			out := redactSettings(item.in)
+			if !reflect.DeepEqual(out, item.out) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.out)
+				t.Logf("     got: %v", out)
+				t.Fail()
+			}
+		})
+	}
+}
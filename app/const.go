@@ -14,6 +14,7 @@ const (
 	defaultGrpcPort   = "57400"
 	msgSize           = 512 * 1024 * 1024
 	defaultRetryTimer = 10 * time.Second
+	defaultJSONIndent = "  "
 
 	formatJSON      = "json"
 	formatPROTOJSON = "protojson"
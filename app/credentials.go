@@ -0,0 +1,47 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) CredentialsSetRunE(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	pass, err := config.ReadPassword(fmt.Sprintf("password for target %q", target))
+	if err != nil {
+		return err
+	}
+	if pass == "" {
+		return errors.New("empty password")
+	}
+	err = config.KeyringSetPassword(target, pass)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "password of target %q saved in the OS keyring\n", target)
+	return nil
+}
+
+func (a *App) CredentialsDeleteRunE(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	err := config.KeyringDeletePassword(target)
+	if err != nil {
+		if errors.Is(err, config.ErrKeyringNotFound) {
+			return fmt.Errorf("target %q: %w", target, err)
+		}
+		return err
+	}
+	fmt.Fprintf(a.out, "password of target %q deleted from the OS keyring\n", target)
+	return nil
+}
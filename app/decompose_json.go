@@ -0,0 +1,287 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// listKeyNames are the leaves identifying the entries of a list
+// when they are present in all the entries, in order of preference.
+var listKeyNames = []string{"name", "id", "index", "key"}
+
+func validateDecomposeFlags(decompose, keepCompound, nullAsDelete bool) error {
+	if decompose {
+		return nil
+	}
+	if keepCompound {
+		return errors.New("flag --keep-compound requires --decompose-json")
+	}
+	if nullAsDelete {
+		return errors.New("flag --null-as-delete requires --decompose-json")
+	}
+	return nil
+}
+
+// decomposeResponse replaces the JSON and JSON_IETF updates of rsp holding
+// a container or a list with an update per leaf, if --decompose-json is set.
+func (a *App) decomposeResponse(rsp *gnmi.SubscribeResponse) {
+	if !a.Config.LocalFlags.SubscribeDecomposeJSON {
+		return
+	}
+	n := rsp.GetUpdate()
+	if n == nil {
+		return
+	}
+	decomposeJSON(n, a.Config.LocalFlags.SubscribeKeepCompound, a.Config.LocalFlags.SubscribeNullAsDelete)
+}
+
+// decomposeJSON replaces the updates of n with a JSON or JSON_IETF object or list value
+// with an update per leaf, the update path is extended with the nested member names,
+// without their module prefix, and the list entries keys.
+// The arrays of scalars are leaf-lists, kept as a single update.
+// The compound updates are kept before their leaves if keepCompound is set,
+// the null values are deletes if nullAsDelete is set.
+func decomposeJSON(n *gnmi.Notification, keepCompound, nullAsDelete bool) {
+	updates := make([]*gnmi.Update, 0, len(n.GetUpdate()))
+	for _, u := range n.GetUpdate() {
+		d := newJSONDecomposer(u)
+		if d == nil {
+			updates = append(updates, u)
+			continue
+		}
+		d.nullAsDelete = nullAsDelete
+		d.walk(u.GetPath(), d.value)
+		// an empty object is kept as is
+		if !d.decomposed || len(d.updates)+len(d.deletes) == 0 {
+			updates = append(updates, u)
+			continue
+		}
+		if keepCompound {
+			updates = append(updates, u)
+		}
+		updates = append(updates, d.updates...)
+		n.Delete = append(n.Delete, d.deletes...)
+	}
+	n.Update = updates
+}
+
+// jsonDecomposer builds the leaf updates of a JSON update.
+type jsonDecomposer struct {
+	ietf         bool
+	value        interface{}
+	nullAsDelete bool
+	// true if the value is an object or a list with keys
+	decomposed bool
+	updates    []*gnmi.Update
+	deletes    []*gnmi.Path
+}
+
+// newJSONDecomposer returns the decomposer of update u,
+// or nil if u value is not a JSON object or a JSON array of objects.
+func newJSONDecomposer(u *gnmi.Update) *jsonDecomposer {
+	d := new(jsonDecomposer)
+	var b []byte
+	switch v := u.GetVal().GetValue().(type) {
+	case *gnmi.TypedValue_JsonVal:
+		b = v.JsonVal
+	case *gnmi.TypedValue_JsonIetfVal:
+		b = v.JsonIetfVal
+		d.ietf = true
+	default:
+		return nil
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || (b[0] != '{' && b[0] != '[') {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&d.value)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// walk adds the leaf updates of value v found at path p.
+func (d *jsonDecomposer) walk(p *gnmi.Path, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		d.decomposed = true
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			d.walk(childPath(p, memberName(name)), v[name])
+		}
+	case []interface{}:
+		entries, keys, ok := listEntries(v)
+		if !ok || len(p.GetElem()) == 0 {
+			// leaf-list, or list without identifiable keys or path
+			d.addLeaf(p, v)
+			return
+		}
+		d.decomposed = true
+		for i, e := range entries {
+			d.walk(entryPath(p, keys[i]), e)
+		}
+	case nil:
+		if d.nullAsDelete {
+			d.deletes = append(d.deletes, p)
+			return
+		}
+		d.addLeaf(p, v)
+	default:
+		d.addLeaf(p, v)
+	}
+}
+
+func (d *jsonDecomposer) addLeaf(p *gnmi.Path, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	tv := &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: b}}
+	if d.ietf {
+		tv.Value = &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: b}
+	}
+	d.updates = append(d.updates, &gnmi.Update{Path: p, Val: tv})
+}
+
+// listEntries returns the entries of the JSON array v and their keys,
+// ok is false if v is not an array of objects with identifiable keys:
+// the first of the listKeyNames leaves present in all the entries,
+// or, for more than one entry, the first leaf in name order present in all the entries
+// with a distinct scalar value in each.
+func listEntries(v []interface{}) ([]map[string]interface{}, []map[string]string, bool) {
+	if len(v) == 0 {
+		return nil, nil, false
+	}
+	entries := make([]map[string]interface{}, 0, len(v))
+	for _, e := range v {
+		em, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, nil, false
+		}
+		entries = append(entries, em)
+	}
+	// scalar leaves present in all the entries, by name without module prefix
+	common := make(map[string]string)
+	for name, lv := range entries[0] {
+		if _, ok := scalarString(lv); ok {
+			common[memberName(name)] = name
+		}
+	}
+	for _, e := range entries[1:] {
+		for mn, name := range common {
+			if _, ok := scalarString(e[name]); !ok {
+				delete(common, mn)
+			}
+		}
+	}
+	keyName := ""
+	for _, kn := range listKeyNames {
+		if _, ok := common[kn]; ok {
+			keyName = kn
+			break
+		}
+	}
+	if keyName == "" && len(entries) > 1 {
+		names := make([]string, 0, len(common))
+		for mn := range common {
+			names = append(names, mn)
+		}
+		sort.Strings(names)
+		for _, mn := range names {
+			if distinctValues(entries, common[mn]) {
+				keyName = mn
+				break
+			}
+		}
+	}
+	if keyName == "" || !distinctValues(entries, common[keyName]) {
+		return nil, nil, false
+	}
+	keys := make([]map[string]string, 0, len(entries))
+	for _, e := range entries {
+		kv, _ := scalarString(e[common[keyName]])
+		keys = append(keys, map[string]string{keyName: kv})
+	}
+	return entries, keys, true
+}
+
+// distinctValues returns true if the leaf name has a different value in each entry.
+func distinctValues(entries []map[string]interface{}, name string) bool {
+	seen := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		v, _ := scalarString(e[name])
+		if _, ok := seen[v]; ok {
+			return false
+		}
+		seen[v] = struct{}{}
+	}
+	return true
+}
+
+// scalarString returns the string representation of the JSON scalar v,
+// ok is false if v is not a scalar.
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// memberName returns the JSON member name without its module prefix,
+// e.g: openconfig-interfaces:interfaces is returned as interfaces.
+func memberName(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// childPath returns a copy of p extended with the element name.
+func childPath(p *gnmi.Path, name string) *gnmi.Path {
+	cp := new(gnmi.Path)
+	if p != nil {
+		cp = proto.Clone(p).(*gnmi.Path)
+	}
+	cp.Elem = append(cp.Elem, &gnmi.PathElem{Name: name})
+	return cp
+}
+
+// entryPath returns a copy of the list path p, its last element keys are set to keys.
+func entryPath(p *gnmi.Path, keys map[string]string) *gnmi.Path {
+	cp := proto.Clone(p).(*gnmi.Path)
+	last := cp.Elem[len(cp.Elem)-1]
+	if last.Key == nil {
+		last.Key = make(map[string]string, len(keys))
+	}
+	for k, v := range keys {
+		last.Key[k] = v
+	}
+	return cp
+}
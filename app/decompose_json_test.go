@@ -0,0 +1,159 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/utils"
+)
+
+func TestDecomposeJSON(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		val          string
+		ietf         bool
+		keepCompound bool
+		nullAsDelete bool
+		// path to JSON value
+		wantUpdates []string
+		wantDeletes []string
+	}{
+		"container": {
+			path: "/interfaces/interface[name=ethernet-1/1]/state",
+			val:  `{"oper-status":"UP","counters":{"in-octets":"100","out-octets":"200"},"mtu":1500}`,
+			wantUpdates: []string{
+				"interfaces/interface[name=ethernet-1/1]/state/counters/in-octets=\"100\"",
+				"interfaces/interface[name=ethernet-1/1]/state/counters/out-octets=\"200\"",
+				"interfaces/interface[name=ethernet-1/1]/state/mtu=1500",
+				"interfaces/interface[name=ethernet-1/1]/state/oper-status=\"UP\"",
+			},
+		},
+		"list_with_name_keys": {
+			path: "/interfaces",
+			val:  `{"openconfig-interfaces:interface":[{"name":"ethernet-1/1","mtu":1500},{"name":"ethernet-1/2","mtu":9000}]}`,
+			ietf: true,
+			wantUpdates: []string{
+				"interfaces/interface[name=ethernet-1/1]/mtu=1500",
+				"interfaces/interface[name=ethernet-1/1]/name=\"ethernet-1/1\"",
+				"interfaces/interface[name=ethernet-1/2]/mtu=9000",
+				"interfaces/interface[name=ethernet-1/2]/name=\"ethernet-1/2\"",
+			},
+		},
+		"list_with_wildcard_path": {
+			path: "/network-instance[name=*]/protocols/bgp/neighbor",
+			val:  `[{"peer-address":"10.0.0.1","peer-as":65001},{"peer-address":"10.0.0.2","peer-as":65001}]`,
+			wantUpdates: []string{
+				"network-instance[name=*]/protocols/bgp/neighbor[peer-address=10.0.0.1]/peer-address=\"10.0.0.1\"",
+				"network-instance[name=*]/protocols/bgp/neighbor[peer-address=10.0.0.1]/peer-as=65001",
+				"network-instance[name=*]/protocols/bgp/neighbor[peer-address=10.0.0.2]/peer-address=\"10.0.0.2\"",
+				"network-instance[name=*]/protocols/bgp/neighbor[peer-address=10.0.0.2]/peer-as=65001",
+			},
+		},
+		"list_without_keys": {
+			path: "/system",
+			val:  `{"servers":[{"port":53},{"port":53}]}`,
+			wantUpdates: []string{
+				`system/servers=[{"port":53},{"port":53}]`,
+			},
+		},
+		"leaf_list": {
+			path: "/system/dns",
+			val:  `{"servers":["10.0.0.1","10.0.0.2"],"search":[]}`,
+			wantUpdates: []string{
+				"system/dns/search=[]",
+				`system/dns/servers=["10.0.0.1","10.0.0.2"]`,
+			},
+		},
+		"scalar": {
+			path:        "/system/name",
+			val:         `"router1"`,
+			wantUpdates: []string{`system/name="router1"`},
+		},
+		"null_as_update": {
+			path:        "/system",
+			val:         `{"name":null}`,
+			wantUpdates: []string{"system/name=null"},
+		},
+		"null_as_delete": {
+			path:         "/system",
+			val:          `{"name":null,"location":"lab"}`,
+			nullAsDelete: true,
+			wantUpdates:  []string{`system/location="lab"`},
+			wantDeletes:  []string{"system/name"},
+		},
+		"keep_compound": {
+			path:         "/system",
+			val:          `{"name":"router1"}`,
+			keepCompound: true,
+			wantUpdates: []string{
+				`system={"name":"router1"}`,
+				`system/name="router1"`,
+			},
+		},
+		"empty_container": {
+			path:        "/system",
+			val:         `{}`,
+			wantUpdates: []string{"system={}"},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			tv := &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: []byte(item.val)}}
+			if item.ietf {
+				tv.Value = &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(item.val)}
+			}
+			n := &gnmi.Notification{
+				Update: []*gnmi.Update{{Path: mustParsePath(t, item.path), Val: tv}},
+			}
+			decomposeJSON(n, item.keepCompound, item.nullAsDelete)
+			gotUpdates := make([]string, 0, len(n.GetUpdate()))
+			for _, u := range n.GetUpdate() {
+				var b []byte
+				switch v := u.GetVal().GetValue().(type) {
+				case *gnmi.TypedValue_JsonVal:
+					b = v.JsonVal
+				case *gnmi.TypedValue_JsonIetfVal:
+					if !item.ietf {
+						t.Errorf("failed at item %q: unexpected JSON_IETF value", name)
+					}
+					b = v.JsonIetfVal
+				}
+				gotUpdates = append(gotUpdates, utils.GnmiPathToXPath(u.GetPath(), false)+"="+string(b))
+			}
+			if !reflect.DeepEqual(gotUpdates, item.wantUpdates) {
+				t.Errorf("failed at item %q: expected updates %q, got %q", name, item.wantUpdates, gotUpdates)
+			}
+			gotDeletes := make([]string, 0, len(n.GetDelete()))
+			for _, p := range n.GetDelete() {
+				gotDeletes = append(gotDeletes, utils.GnmiPathToXPath(p, false))
+			}
+			if len(item.wantDeletes) == 0 {
+				item.wantDeletes = []string{}
+			}
+			if !reflect.DeepEqual(gotDeletes, item.wantDeletes) {
+				t.Errorf("failed at item %q: expected deletes %q, got %q", name, item.wantDeletes, gotDeletes)
+			}
+		})
+	}
+}
+
+func TestValidateDecomposeFlags(t *testing.T) {
+	if err := validateDecomposeFlags(false, true, false); err == nil {
+		t.Errorf("expected an error for --keep-compound without --decompose-json")
+	}
+	if err := validateDecomposeFlags(false, false, true); err == nil {
+		t.Errorf("expected an error for --null-as-delete without --decompose-json")
+	}
+	if err := validateDecomposeFlags(true, true, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
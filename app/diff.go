@@ -158,7 +158,7 @@ func (a *App) subscribeBasedDiff(ctx context.Context, cmd *cobra.Command, ref *t
 
 	go func() {
 		defer a.wg.Done()
-		err = refTarget.CreateGNMIClient(ctx, a.dialOpts...)
+		err = refTarget.CreateGNMIClient(ctx, a.targetDialOpts(refTarget)...)
 		if err != nil {
 			a.logError(err)
 			return
@@ -193,7 +193,7 @@ func (a *App) subscribeBasedDiff(ctx context.Context, cmd *cobra.Command, ref *t
 		}
 		go func(tName string) {
 			defer a.wg.Done()
-			err = t.CreateGNMIClient(ctx, a.dialOpts...)
+			err = t.CreateGNMIClient(ctx, a.targetDialOpts(t)...)
 			if err != nil {
 				a.logError(err)
 				return
@@ -269,7 +269,7 @@ func (a *App) getBasedDiff(ctx context.Context, ref *types.TargetConfig, compare
 			getReq.Prefix, getReq.Path, getReq.Type, getReq.Encoding, getReq.UseModels, getReq.Extension, ref)
 		refResponse, err = a.ClientGet(ctx, ref, getReq)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q get request failed: %v", ref, err))
+			a.logTargetError(ref.Name, fmt.Errorf("get request failed: %v", err))
 			return
 		}
 	}()
@@ -281,7 +281,7 @@ func (a *App) getBasedDiff(ctx context.Context, ref *types.TargetConfig, compare
 				getReq.Prefix, getReq.Path, getReq.Type, getReq.Encoding, getReq.UseModels, getReq.Extension, tc.Name)
 			response, err := a.ClientGet(ctx, tc, getReq)
 			if err != nil {
-				a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
+				a.logTargetError(tc.Name, fmt.Errorf("get request failed: %v", err))
 				return
 			}
 			rspChan <- &targetDiffResponse{
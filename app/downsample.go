@@ -0,0 +1,179 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+)
+
+// updates kept per path and per downsample interval
+const (
+	downsampleKeepFirst = "first"
+	downsampleKeepLast  = "last"
+	// downsampleExpiryIntervals is the number of downsample intervals
+	// without any update after which the state of a path is removed.
+	downsampleExpiryIntervals = 3
+)
+
+func validateDownsampleFlags(interval time.Duration, keep string) error {
+	if interval < 0 {
+		return fmt.Errorf("flag --downsample must not be negative")
+	}
+	switch keep {
+	case downsampleKeepFirst, downsampleKeepLast:
+	default:
+		return fmt.Errorf("unknown --downsample-keep %q, one of: %s, %s", keep, downsampleKeepFirst, downsampleKeepLast)
+	}
+	return nil
+}
+
+// downsampleEntry is the downsampling state of a path.
+type downsampleEntry struct {
+	// start of the current interval
+	start time.Time
+	// last time an update of the path was received
+	lastSeen time.Time
+	// with --downsample-keep last, the latest update received during
+	// the current interval, sent at its end, and its metadata.
+	pending *gnmi.Notification
+	meta    outputs.Meta
+}
+
+// downsampledResponse is a held update sent at the end of its interval.
+type downsampledResponse struct {
+	rsp  *gnmi.SubscribeResponse
+	meta outputs.Meta
+}
+
+// downsampler forwards at most one update per subscription path
+// and per interval, it is used by a single target listener.
+type downsampler struct {
+	interval time.Duration
+	keep     string
+	entries  map[string]*downsampleEntry
+}
+
+// newDownsampler returns the downsampler of target t,
+// or nil if --downsample is not set or if t has no stream subscription.
+func (a *App) newDownsampler(t *target.Target) *downsampler {
+	if a.Config.LocalFlags.SubscribeDownsample <= 0 {
+		return nil
+	}
+	for _, sc := range t.Subscriptions {
+		if isDownsampled(sc) {
+			return &downsampler{
+				interval: a.Config.LocalFlags.SubscribeDownsample,
+				keep:     a.Config.LocalFlags.SubscribeDownsampleKeep,
+				entries:  make(map[string]*downsampleEntry),
+			}
+		}
+	}
+	return nil
+}
+
+// isDownsampled returns true if the updates of subscription sc are downsampled,
+// i.e: it is a sample or target defined stream subscription.
+func isDownsampled(sc *types.SubscriptionConfig) bool {
+	if sc == nil {
+		return false
+	}
+	mode := strings.ToUpper(sc.Mode)
+	if mode != "" && mode != "STREAM" {
+		return false
+	}
+	return strings.Replace(strings.ToUpper(sc.StreamMode), "-", "_", -1) != "ON_CHANGE"
+}
+
+// downsample removes from rsp the updates dropped or held by the downsampler d of target tName.
+// The deletes are always kept. It returns false if nothing is left in rsp to be exported.
+func (a *App) downsample(d *downsampler, tName string, rsp *target.SubscribeResponse, m outputs.Meta, now time.Time) bool {
+	if d == nil || !isDownsampled(rsp.SubscriptionConfig) {
+		return true
+	}
+	n := rsp.Response.GetUpdate()
+	if n == nil {
+		return true
+	}
+	dropped := d.filter(rsp.SubscriptionName, n, m, now)
+	if dropped > 0 {
+		subscribeDownsampledCounter.WithLabelValues(tName, rsp.SubscriptionName).Add(float64(dropped))
+	}
+	return len(n.GetUpdate()) > 0 || len(n.GetDelete()) > 0
+}
+
+// filter keeps in notification n the updates to be forwarded now,
+// and returns the number of dropped updates.
+func (d *downsampler) filter(subName string, n *gnmi.Notification, m outputs.Meta, now time.Time) int {
+	dropped := 0
+	kept := make([]*gnmi.Update, 0, len(n.GetUpdate()))
+	prefix := utils.GnmiPathToXPath(n.GetPrefix(), false)
+	for _, u := range n.GetUpdate() {
+		key := subName + "|" + prefix + "|" + utils.GnmiPathToXPath(u.GetPath(), false)
+		e, ok := d.entries[key]
+		if !ok {
+			e = &downsampleEntry{}
+			d.entries[key] = e
+		}
+		e.lastSeen = now
+		switch d.keep {
+		case downsampleKeepLast:
+			if e.pending != nil {
+				dropped++
+			} else {
+				e.start = now
+			}
+			e.pending = &gnmi.Notification{
+				Timestamp: n.GetTimestamp(),
+				Prefix:    n.GetPrefix(),
+				Update:    []*gnmi.Update{u},
+			}
+			e.meta = m
+		default:
+			if ok && now.Sub(e.start) < d.interval {
+				dropped++
+				continue
+			}
+			e.start = now
+			kept = append(kept, u)
+		}
+	}
+	n.Update = kept
+	return dropped
+}
+
+// flush returns the held updates whose interval ended at now,
+// and removes the state of the paths without updates for downsampleExpiryIntervals.
+func (d *downsampler) flush(now time.Time) []*downsampledResponse {
+	rsps := make([]*downsampledResponse, 0)
+	for key, e := range d.entries {
+		if e.pending != nil {
+			if now.Sub(e.start) < d.interval {
+				continue
+			}
+			rsps = append(rsps, &downsampledResponse{
+				rsp:  &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: e.pending}},
+				meta: e.meta,
+			})
+			e.pending = nil
+			e.meta = nil
+		}
+		if now.Sub(e.lastSeen) >= downsampleExpiryIntervals*d.interval {
+			delete(d.entries, key)
+		}
+	}
+	return rsps
+}
@@ -0,0 +1,205 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+)
+
+// downsampleUpdate returns a subscribe response of subscription sc
+// with an update of path p to value v.
+func downsampleUpdate(t *testing.T, sc *types.SubscriptionConfig, p string, v uint64) *target.SubscribeResponse {
+	return &target.SubscribeResponse{
+		SubscriptionName:   sc.Name,
+		SubscriptionConfig: sc,
+		Response: &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{
+					Update: []*gnmi.Update{{
+						Path: mustParsePath(t, p),
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: v}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	sampled := &types.SubscriptionConfig{Name: "sub1", Mode: "stream", StreamMode: "target-defined"}
+	onChange := &types.SubscriptionConfig{Name: "sub2", Mode: "stream", StreamMode: "on-change"}
+	type update struct {
+		sc *types.SubscriptionConfig
+		p  string
+		v  uint64
+		at time.Duration
+	}
+	tests := map[string]struct {
+		keep    string
+		updates []update
+		// flush time, after the last update
+		flushAt time.Duration
+		// values forwarded as received
+		wantForwarded []uint64
+		// values forwarded at the end of their interval
+		wantFlushed []uint64
+	}{
+		"first": {
+			keep: downsampleKeepFirst,
+			updates: []update{
+				{sc: sampled, p: "/a", v: 1, at: 0},
+				{sc: sampled, p: "/a", v: 2, at: 20 * time.Second},
+				{sc: sampled, p: "/b", v: 3, at: 30 * time.Second},
+				{sc: sampled, p: "/a", v: 4, at: 60 * time.Second},
+				{sc: sampled, p: "/a", v: 5, at: 70 * time.Second},
+			},
+			flushAt:       80 * time.Second,
+			wantForwarded: []uint64{1, 3, 4},
+			wantFlushed:   []uint64{},
+		},
+		"last": {
+			keep: downsampleKeepLast,
+			updates: []update{
+				{sc: sampled, p: "/a", v: 1, at: 0},
+				{sc: sampled, p: "/a", v: 2, at: 20 * time.Second},
+				{sc: sampled, p: "/a", v: 3, at: 50 * time.Second},
+			},
+			flushAt:       60 * time.Second,
+			wantForwarded: []uint64{},
+			wantFlushed:   []uint64{3},
+		},
+		"last_interval_not_ended": {
+			keep: downsampleKeepLast,
+			updates: []update{
+				{sc: sampled, p: "/a", v: 1, at: 0},
+				{sc: sampled, p: "/a", v: 2, at: 20 * time.Second},
+			},
+			flushAt:       30 * time.Second,
+			wantForwarded: []uint64{},
+			wantFlushed:   []uint64{},
+		},
+		"on_change": {
+			keep: downsampleKeepFirst,
+			updates: []update{
+				{sc: onChange, p: "/a", v: 1, at: 0},
+				{sc: onChange, p: "/a", v: 2, at: 10 * time.Second},
+				{sc: onChange, p: "/a", v: 3, at: 20 * time.Second},
+			},
+			flushAt:       30 * time.Second,
+			wantForwarded: []uint64{1, 2, 3},
+			wantFlushed:   []uint64{},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			d := &downsampler{
+				interval: time.Minute,
+				keep:     item.keep,
+				entries:  make(map[string]*downsampleEntry),
+			}
+			start := time.Now()
+			forwarded := make([]uint64, 0)
+			for _, u := range item.updates {
+				rsp := downsampleUpdate(t, u.sc, u.p, u.v)
+				if a.downsample(d, "t1", rsp, outputs.Meta{}, start.Add(u.at)) {
+					for _, upd := range rsp.Response.GetUpdate().GetUpdate() {
+						forwarded = append(forwarded, upd.GetVal().GetUintVal())
+					}
+				}
+			}
+			flushed := make([]uint64, 0)
+			for _, r := range d.flush(start.Add(item.flushAt)) {
+				for _, upd := range r.rsp.GetUpdate().GetUpdate() {
+					flushed = append(flushed, upd.GetVal().GetUintVal())
+				}
+			}
+			if !reflect.DeepEqual(forwarded, item.wantForwarded) || !reflect.DeepEqual(flushed, item.wantFlushed) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected forwarded: %v, flushed: %v", item.wantForwarded, item.wantFlushed)
+				t.Logf("     got forwarded: %v, flushed: %v", forwarded, flushed)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestDownsampleDeletesAndExpiry(t *testing.T) {
+	a := New()
+	d := &downsampler{
+		interval: time.Minute,
+		keep:     downsampleKeepFirst,
+		entries:  make(map[string]*downsampleEntry),
+	}
+	sc := &types.SubscriptionConfig{Name: "sub1", Mode: "stream", StreamMode: "sample"}
+	start := time.Now()
+	if !a.downsample(d, "t1", downsampleUpdate(t, sc, "/a", 1), outputs.Meta{}, start) {
+		t.Fatal("expected the first update to be forwarded")
+	}
+	rsp := downsampleUpdate(t, sc, "/a", 2)
+	rsp.Response.GetUpdate().Delete = []*gnmi.Path{mustParsePath(t, "/b")}
+	if !a.downsample(d, "t1", rsp, outputs.Meta{}, start.Add(time.Second)) {
+		t.Fatal("expected the delete to be forwarded")
+	}
+	if n := rsp.Response.GetUpdate(); len(n.GetUpdate()) != 0 || len(n.GetDelete()) != 1 {
+		t.Errorf("expected only the delete to be forwarded, got: %v", n)
+	}
+	d.flush(start.Add(2 * time.Minute))
+	if len(d.entries) != 1 {
+		t.Errorf("expected the path state to be kept, got %d entries", len(d.entries))
+	}
+	d.flush(start.Add(time.Second + downsampleExpiryIntervals*time.Minute))
+	if len(d.entries) != 0 {
+		t.Errorf("expected the path state to expire, got %d entries", len(d.entries))
+	}
+}
+
+func TestValidateDownsampleFlags(t *testing.T) {
+	tests := map[string]struct {
+		interval time.Duration
+		keep     string
+		wantErr  bool
+	}{
+		"disabled": {
+			keep: downsampleKeepFirst,
+		},
+		"last": {
+			interval: time.Minute,
+			keep:     downsampleKeepLast,
+		},
+		"negative_interval": {
+			interval: -time.Second,
+			keep:     downsampleKeepFirst,
+			wantErr:  true,
+		},
+		"unknown_keep": {
+			interval: time.Minute,
+			keep:     "average",
+			wantErr:  true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateDownsampleFlags(item.interval, item.keep)
+			if (err != nil) != item.wantErr {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected error: %v", item.wantErr)
+				t.Logf("           got: %v", err)
+				t.Fail()
+			}
+		})
+	}
+}
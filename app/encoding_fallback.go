@@ -0,0 +1,51 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// clientGetFallback sends the Get request req to target tc, and sends it again with
+// the next encoding of the target encoding-fallback list while it is rejected for its encoding.
+// The encoding used is set in the RPC metadata of ctx.
+func (a *App) clientGetFallback(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	rsp, err := a.ClientGet(ctx, tc, req)
+	for err != nil {
+		next, ok := target.NextEncoding(tc.EncodingFallback, req.GetEncoding(), err)
+		if !ok {
+			break
+		}
+		a.logEncodingFallback(fmt.Sprintf("target %q: Get: encoding %s: %v, falling back to encoding %s",
+			tc.Name, target.EncodingName(req.GetEncoding()), err, target.EncodingName(next)))
+		req = proto.Clone(req).(*gnmi.GetRequest)
+		req.Encoding = next
+		if md := target.RPCMetadataFromContext(ctx); md != nil {
+			md.Encoding = target.EncodingName(next)
+		}
+		rsp, err = a.ClientGet(ctx, tc, req)
+	}
+	return rsp, err
+}
+
+// logEncodingFallback logs msg reporting a request sent again with a fallback encoding,
+// it is also printed to stderr if the logs are not.
+func (a *App) logEncodingFallback(msg string) {
+	a.Logger.Print(msg)
+	if !a.Config.Log {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
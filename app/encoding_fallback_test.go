@@ -0,0 +1,109 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// encodingGetServer rejects the Get requests with an encoding other than accepted.
+type encodingGetServer struct {
+	gnmi.UnimplementedGNMIServer
+	accepted gnmi.Encoding
+	message  string
+}
+
+func (s *encodingGetServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	if req.GetEncoding() != s.accepted {
+		return nil, status.Error(codes.InvalidArgument, s.message)
+	}
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{{Timestamp: 42}}}, nil
+}
+
+func TestGetEncodingFallback(t *testing.T) {
+	tests := map[string]struct {
+		fallback []string
+		message  string
+		wantErr  bool
+		wantOut  []string
+	}{
+		"fallback": {
+			fallback: []string{"JSON", "PROTO"},
+			message:  "unsupported encoding",
+			wantOut:  []string{`"encoding":"proto"`, `"timestamp":42`},
+		},
+		"no_fallback": {
+			message: "unsupported encoding",
+			wantErr: true,
+		},
+		"not_an_encoding_error": {
+			fallback: []string{"JSON", "PROTO"},
+			message:  "invalid path",
+			wantErr:  true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, &encodingGetServer{accepted: gnmi.Encoding_PROTO, message: item.message})
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			defer a.Cfn()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.Config.IncludeMeta = true
+			a.Config.Encoding = "json_ietf"
+			out := new(bytes.Buffer)
+			a.out = out
+			a.createCollectorDialOpts()
+
+			insecure := true
+			tc := &types.TargetConfig{
+				Name:             "t1",
+				Address:          l.Addr().String(),
+				Insecure:         &insecure,
+				Timeout:          5 * time.Second,
+				EncodingFallback: item.fallback,
+			}
+			a.errCh = make(chan error, 3)
+			a.wg.Add(1)
+			a.GetRequest(a.ctx, tc, &gnmi.GetRequest{})
+			err = a.checkErrors()
+			if item.wantErr != (err != nil) {
+				t.Fatalf("failed at item %q: unexpected error: %v", name, err)
+			}
+			// the JSON output is compared without spaces
+			got := strings.Join(strings.Fields(out.String()), "")
+			for _, s := range item.wantOut {
+				if !strings.Contains(got, s) {
+					t.Errorf("failed at item %q: expected the output to contain %q, got: %s", name, s, got)
+				}
+			}
+		})
+	}
+}
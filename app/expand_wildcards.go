@@ -0,0 +1,410 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const defaultExpandMax = 20
+
+var errExpandAborted = errors.New("aborted after the wildcards expansion preview")
+
+// wildcardExpansion is the result of the expansion of a path
+// with wildcard keys on a single target.
+type wildcardExpansion struct {
+	target   string
+	path     string
+	expanded []string
+	err      error
+}
+
+// lastWildcardElem returns the index of the last path element
+// with a wildcard key value, -1 if the path has none.
+func lastWildcardElem(p *gnmi.Path) int {
+	idx := -1
+	for i, pe := range p.GetElem() {
+		for _, v := range pe.GetKey() {
+			if v == "*" {
+				idx = i
+				break
+			}
+		}
+	}
+	return idx
+}
+
+// joinPaths returns a path made of the prefix elements followed by the path elements,
+// the path origin takes precedence over the prefix one.
+func joinPaths(prefix, p *gnmi.Path) *gnmi.Path {
+	full := &gnmi.Path{
+		Origin: prefix.GetOrigin(),
+		Elem:   make([]*gnmi.PathElem, 0, len(prefix.GetElem())+len(p.GetElem())),
+	}
+	if p.GetOrigin() != "" {
+		full.Origin = p.GetOrigin()
+	}
+	full.Elem = append(full.Elem, prefix.GetElem()...)
+	full.Elem = append(full.Elem, p.GetElem()...)
+	return full
+}
+
+// expansionQuery returns the path sent to discover the keys of the list
+// holding the last wildcard of p: the path truncated at that list with
+// one of its wildcard keys as leaf, e.g: /interfaces/interface[name=*]/name.
+func expansionQuery(p *gnmi.Path) (*gnmi.Path, bool) {
+	last := lastWildcardElem(p)
+	if last < 0 {
+		return nil, false
+	}
+	q := &gnmi.Path{
+		Origin: p.GetOrigin(),
+		Elem:   make([]*gnmi.PathElem, 0, last+2),
+	}
+	for _, pe := range p.GetElem()[:last+1] {
+		q.Elem = append(q.Elem, proto.Clone(pe).(*gnmi.PathElem))
+	}
+	keys := make([]string, 0, len(p.Elem[last].GetKey()))
+	for k, v := range p.Elem[last].GetKey() {
+		if v == "*" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	q.Elem = append(q.Elem, &gnmi.PathElem{Name: keys[0]})
+	return q, true
+}
+
+// expandPath returns the sorted concrete paths obtained by replacing the wildcard keys
+// of p, up to its last wildcard element, with the keys found in the Get response.
+// The keys are read from the returned update paths, or from their JSON values when
+// the target returns the list as part of a higher level container.
+func expandPath(p *gnmi.Path, rsp *gnmi.GetResponse) []string {
+	last := lastWildcardElem(p)
+	if last < 0 {
+		return []string{utils.GnmiPathToXPath(p, false)}
+	}
+	found := make(map[string]struct{})
+	emit := func(keys []map[string]string) {
+		cp := proto.Clone(p).(*gnmi.Path)
+		for i := 0; i <= last; i++ {
+			for k, v := range keys[i] {
+				cp.Elem[i].Key[k] = v
+			}
+		}
+		found[utils.GnmiPathToXPath(cp, false)] = struct{}{}
+	}
+	for _, n := range rsp.GetNotification() {
+		for _, upd := range n.GetUpdate() {
+			full := joinPaths(n.GetPrefix(), upd.GetPath())
+			keys := make([]map[string]string, last+1)
+			depth := len(full.GetElem())
+			if depth > last+1 {
+				depth = last + 1
+			}
+			if !matchElems(p.Elem[:depth], full.Elem[:depth], keys) {
+				continue
+			}
+			if depth == last+1 {
+				emit(keys)
+				continue
+			}
+			val, ok := jsonValue(upd.GetVal())
+			if !ok {
+				continue
+			}
+			walkJSONKeys(p, depth, last, val, keys, emit)
+		}
+	}
+	expanded := make([]string, 0, len(found))
+	for xp := range found {
+		expanded = append(expanded, xp)
+	}
+	sort.Strings(expanded)
+	return expanded
+}
+
+// matchElems compares the requested path elements with the returned ones,
+// storing the values of the wildcard keys in keys.
+func matchElems(req, rcv []*gnmi.PathElem, keys []map[string]string) bool {
+	for i, pe := range req {
+		if stripModule(pe.GetName()) != stripModule(rcv[i].GetName()) {
+			return false
+		}
+		for k, v := range pe.GetKey() {
+			rv, ok := rcv[i].GetKey()[k]
+			if v == "*" {
+				if !ok || rv == "*" {
+					return false
+				}
+				if keys[i] == nil {
+					keys[i] = make(map[string]string)
+				}
+				keys[i][k] = rv
+				continue
+			}
+			if ok && rv != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkJSONKeys walks a decoded JSON value from the path element at index i
+// down to the last wildcard element, collecting the list keys of each entry.
+func walkJSONKeys(p *gnmi.Path, i, last int, node interface{}, keys []map[string]string, emit func([]map[string]string)) {
+	if i > last {
+		emit(keys)
+		return
+	}
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+	child, ok := jsonMember(obj, p.Elem[i].GetName())
+	if !ok {
+		return
+	}
+	if len(p.Elem[i].GetKey()) == 0 {
+		walkJSONKeys(p, i+1, last, child, keys, emit)
+		return
+	}
+	var entries []interface{}
+	switch child := child.(type) {
+	case []interface{}:
+		entries = child
+	case map[string]interface{}:
+		entries = []interface{}{child}
+	}
+ENTRIES:
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entryKeys := make(map[string]string, len(p.Elem[i].GetKey()))
+		for k, v := range p.Elem[i].GetKey() {
+			kv, ok := jsonMember(entry, k)
+			if !ok {
+				continue ENTRIES
+			}
+			s := jsonKeyString(kv)
+			if v != "*" {
+				if s != v {
+					continue ENTRIES
+				}
+				continue
+			}
+			entryKeys[k] = s
+		}
+		keys[i] = entryKeys
+		walkJSONKeys(p, i+1, last, entry, keys, emit)
+	}
+	keys[i] = nil
+}
+
+// jsonMember returns the member of obj named name,
+// with or without a module name prefix.
+func jsonMember(obj map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := obj[name]; ok {
+		return v, true
+	}
+	name = stripModule(name)
+	for k, v := range obj {
+		if stripModule(k) == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func jsonValue(tv *gnmi.TypedValue) (interface{}, bool) {
+	var b []byte
+	switch tv.GetValue().(type) {
+	case *gnmi.TypedValue_JsonVal:
+		b = tv.GetJsonVal()
+	case *gnmi.TypedValue_JsonIetfVal:
+		b = tv.GetJsonIetfVal()
+	default:
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func jsonKeyString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func stripModule(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// expandWildcards sends, to each target, a Get request per path with wildcard keys
+// to discover the concrete paths the wildcards expand to.
+// The paths of a target are returned by targetPaths.
+func (a *App) expandWildcards(ctx context.Context, encoding gnmi.Encoding, targetPaths func(tc *types.TargetConfig) []*gnmi.Path) []*wildcardExpansion {
+	result := make([]*wildcardExpansion, 0)
+	mu := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	for _, tc := range a.Config.Targets {
+		for _, p := range targetPaths(tc) {
+			q, ok := expansionQuery(p)
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func(tc *types.TargetConfig, p, q *gnmi.Path) {
+				defer wg.Done()
+				we := &wildcardExpansion{
+					target: tc.Name,
+					path:   utils.GnmiPathToXPath(p, false),
+				}
+				rsp, err := a.ClientGet(ctx, tc, &gnmi.GetRequest{
+					Path:     []*gnmi.Path{q},
+					Encoding: encoding,
+				})
+				if err != nil {
+					we.err = err
+				} else {
+					we.expanded = expandPath(p, rsp)
+				}
+				mu.Lock()
+				result = append(result, we)
+				mu.Unlock()
+			}(tc, p, q)
+		}
+	}
+	wg.Wait()
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].target == result[j].target {
+			return result[i].path < result[j].path
+		}
+		return result[i].target < result[j].target
+	})
+	return result
+}
+
+// printExpansions writes the expanded paths of each target, at most max per path,
+// and returns the total number of expanded paths.
+func printExpansions(w io.Writer, exps []*wildcardExpansion, max int) int {
+	total := 0
+	for _, we := range exps {
+		if we.err != nil {
+			fmt.Fprintf(w, "target %q: path %s: expansion failed: %v\n", we.target, we.path, we.err)
+			continue
+		}
+		total += len(we.expanded)
+		fmt.Fprintf(w, "target %q: path %s expands to %d path(s)\n", we.target, we.path, len(we.expanded))
+		for i, xp := range we.expanded {
+			if max > 0 && i >= max {
+				fmt.Fprintf(w, "  ... and %d more\n", len(we.expanded)-max)
+				break
+			}
+			fmt.Fprintf(w, "  %s\n", xp)
+		}
+	}
+	fmt.Fprintf(w, "total: %d expanded path(s)\n", total)
+	return total
+}
+
+// previewWildcards prints the expansion of the paths with wildcard keys
+// and asks for a confirmation unless yes is set.
+func (a *App) previewWildcards(ctx context.Context, encoding gnmi.Encoding, max int, yes bool, targetPaths func(tc *types.TargetConfig) []*gnmi.Path) error {
+	exps := a.expandWildcards(ctx, encoding, targetPaths)
+	if len(exps) == 0 {
+		a.Logger.Printf("no path with wildcard keys to expand")
+		return nil
+	}
+	total := printExpansions(os.Stderr, exps, max)
+	if yes {
+		return nil
+	}
+	if !confirmPrompt(fmt.Sprintf("proceed with the %d expanded path(s)", total)) {
+		return errExpandAborted
+	}
+	return nil
+}
+
+func (a *App) previewGetWildcards(ctx context.Context, req *gnmi.GetRequest) error {
+	return a.previewWildcards(ctx, req.GetEncoding(),
+		a.Config.LocalFlags.GetExpandMax, a.Config.LocalFlags.GetYes,
+		func(*types.TargetConfig) []*gnmi.Path {
+			paths := make([]*gnmi.Path, 0, len(req.GetPath()))
+			for _, p := range req.GetPath() {
+				paths = append(paths, joinPaths(req.GetPrefix(), p))
+			}
+			return paths
+		})
+}
+
+func (a *App) previewSubscribeWildcards(subCfg map[string]*types.SubscriptionConfig) error {
+	tcs, err := a.Config.GetTargets()
+	if err == nil {
+		err = a.Config.SetMissingCredentials(tcs)
+	}
+	if err != nil {
+		a.Logger.Printf("skipping the wildcards expansion preview: %v", err)
+		return nil
+	}
+	enc, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(a.Config.Encoding, "-", "_"))]
+	if !ok {
+		enc = int32(gnmi.Encoding_JSON)
+	}
+	return a.previewWildcards(a.ctx, gnmi.Encoding(enc),
+		a.Config.LocalFlags.SubscribeExpandMax, a.Config.LocalFlags.SubscribeYes,
+		func(tc *types.TargetConfig) []*gnmi.Path {
+			paths := make([]*gnmi.Path, 0)
+			for name, sc := range subCfg {
+				if len(tc.Subscriptions) > 0 && !strInList(name, tc.Subscriptions) {
+					continue
+				}
+				prefix, err := utils.ParsePath(sc.Prefix)
+				if err != nil {
+					continue
+				}
+				for _, sp := range sc.Paths {
+					p, err := utils.ParsePath(sp)
+					if err != nil {
+						continue
+					}
+					paths = append(paths, joinPaths(prefix, p))
+				}
+			}
+			return paths
+		})
+}
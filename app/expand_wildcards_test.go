@@ -0,0 +1,171 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/utils"
+)
+
+func TestExpansionQuery(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		want string
+		ok   bool
+	}{
+		"no_wildcard": {
+			path: "/interfaces/interface[name=ethernet-1/1]/state",
+		},
+		"single_list": {
+			path: "/interfaces/interface[name=*]/state/counters",
+			want: "interfaces/interface[name=*]/name",
+			ok:   true,
+		},
+		"nested_lists": {
+			path: "/interfaces/interface[name=*]/subinterfaces/subinterface[index=*]/state",
+			want: "interfaces/interface[name=*]/subinterfaces/subinterface[index=*]/index",
+			ok:   true,
+		},
+		"origin": {
+			path: "openconfig:/network-instances/network-instance[name=*]/state",
+			want: "openconfig:network-instances/network-instance[name=*]/name",
+			ok:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, ok := expansionQuery(mustParsePath(t, tc.path))
+			if ok != tc.ok {
+				t.Fatalf("failed at item %q: expected ok=%v, got %v", name, tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if got := utils.GnmiPathToXPath(q, false); got != tc.want {
+				t.Errorf("failed at item %q: expected %q, got %q", name, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		rsp  *gnmi.GetResponse
+		want []string
+	}{
+		"update_paths": {
+			path: "/interfaces/interface[name=*]/state/counters",
+			rsp: &gnmi.GetResponse{
+				Notification: []*gnmi.Notification{
+					{
+						Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}}},
+						Update: []*gnmi.Update{
+							{Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interface", Key: map[string]string{"name": "e1"}}, {Name: "name"}}}},
+							{Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interface", Key: map[string]string{"name": "e2"}}, {Name: "name"}}}},
+						},
+					},
+				},
+			},
+			want: []string{
+				"interfaces/interface[name=e1]/state/counters",
+				"interfaces/interface[name=e2]/state/counters",
+			},
+		},
+		"fixed_key_mismatch": {
+			path: "/interfaces/interface[name=e1]/subinterfaces/subinterface[index=*]",
+			rsp: &gnmi.GetResponse{
+				Notification: []*gnmi.Notification{
+					{
+						Update: []*gnmi.Update{
+							{Path: mustParsePath(t, "/interfaces/interface[name=e1]/subinterfaces/subinterface[index=0]/index")},
+							{Path: mustParsePath(t, "/interfaces/interface[name=e2]/subinterfaces/subinterface[index=1]/index")},
+						},
+					},
+				},
+			},
+			want: []string{
+				"interfaces/interface[name=e1]/subinterfaces/subinterface[index=0]",
+			},
+		},
+		"json_value": {
+			path: "/interfaces/interface[name=*]/subinterfaces/subinterface[index=*]/state",
+			rsp: &gnmi.GetResponse{
+				Notification: []*gnmi.Notification{
+					{
+						Update: []*gnmi.Update{
+							{
+								Path: mustParsePath(t, "/interfaces"),
+								Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{
+	"openconfig-interfaces:interface": [
+		{"name": "e1", "subinterfaces": {"subinterface": [{"index": 0}, {"index": 1}]}},
+		{"name": "e2", "subinterfaces": {"subinterface": {"index": 10}}}
+	]
+}`)}},
+							},
+						},
+					},
+				},
+			},
+			want: []string{
+				"interfaces/interface[name=e1]/subinterfaces/subinterface[index=0]/state",
+				"interfaces/interface[name=e1]/subinterfaces/subinterface[index=1]/state",
+				"interfaces/interface[name=e2]/subinterfaces/subinterface[index=10]/state",
+			},
+		},
+		"empty_response": {
+			path: "/interfaces/interface[name=*]",
+			rsp:  &gnmi.GetResponse{},
+			want: []string{},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := expandPath(mustParsePath(t, tc.path), tc.rsp)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("failed at item %q: expected %v, got %v", name, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPrintExpansions(t *testing.T) {
+	exps := []*wildcardExpansion{
+		{
+			target:   "t1",
+			path:     "interfaces/interface[name=*]",
+			expanded: []string{"interfaces/interface[name=e1]", "interfaces/interface[name=e2]", "interfaces/interface[name=e3]"},
+		},
+		{
+			target: "t2",
+			path:   "interfaces/interface[name=*]",
+			err:    errors.New("unreachable"),
+		},
+	}
+	buf := new(bytes.Buffer)
+	total := printExpansions(buf, exps, 2)
+	if total != 3 {
+		t.Errorf("expected a total of 3, got %d", total)
+	}
+	want := `target "t1": path interfaces/interface[name=*] expands to 3 path(s)
+  interfaces/interface[name=e1]
+  interfaces/interface[name=e2]
+  ... and 1 more
+target "t2": path interfaces/interface[name=*]: expansion failed: unreachable
+total: 3 expanded path(s)
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), want)
+	}
+}
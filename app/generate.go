@@ -27,6 +27,10 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// mandatoryLeafValue is the value of the mandatory leaves
+// without a default value in the generated payloads.
+const mandatoryLeafValue = "<mandatory>"
+
 // options for formatting keys when generating yaml/json payloads
 type keyOpts struct {
 	camelCase bool
@@ -65,7 +69,7 @@ func (a *App) GenerateRunE(cmd *cobra.Command, args []string) error {
 	}
 	for _, e := range a.SchemaTree.Dir {
 		e.FixChoice()
-		nm := toMap(e, a.Config.GenerateConfigOnly, kOpts)
+		nm := toMap(e, !a.Config.LocalFlags.GenerateState, kOpts)
 		if nm == nil {
 			continue
 		}
@@ -103,6 +107,9 @@ func (a *App) GeneratePreRunE(cmd *cobra.Command, args []string) error {
 	if a.Config.LocalFlags.GenerateCamelCase && a.Config.LocalFlags.GenerateSnakeCase {
 		return errors.New("flags --camel-case and --snake-case are mutually exclusive")
 	}
+	if a.Config.LocalFlags.GenerateConfigOnly && a.Config.LocalFlags.GenerateState {
+		return errors.New("flags --config-only and --state are mutually exclusive")
+	}
 	return a.yangFilesPreProcessing()
 }
 
@@ -206,9 +213,11 @@ func (a *App) InitGenerateFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolVarP(&a.Config.LocalFlags.GenerateJSON, "json", "j", false, "generate output as JSON format instead of YAML")
 	// local flags
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GenerateConfigOnly, "config-only", "", false, "generate output from YANG config nodes only")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GenerateState, "state", "", false, "include the YANG state nodes in the generated output")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.GeneratePath, "path", "", "", "generate marshaled YANG body under specified path")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GenerateCamelCase, "camel-case", "", false, "convert keys to camelCase")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GenerateSnakeCase, "snake-case", "", false, "convert keys to snake_case")
+	cmd.Flags().MarkDeprecated("config-only", "config nodes only is the default, use --state to include the state nodes")
 
 	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
@@ -394,7 +403,7 @@ func toMap(e *yang.Entry, configOnly bool, kopts *keyOpts) interface{} {
 		if e.Config == yang.TSFalse && configOnly {
 			return nil
 		}
-		return e.Default
+		return leafValue(e)
 	case e.ListAttr != nil: // list
 		for n, child := range e.Dir {
 			gChild := toMap(child, configOnly, kopts)
@@ -445,6 +454,39 @@ func toMap(e *yang.Entry, configOnly bool, kopts *keyOpts) interface{} {
 	}
 }
 
+// leafValue returns the value of a leaf or leaf-list in the generated payloads:
+// its default value, mandatoryLeafValue for a mandatory leaf or list key without default,
+// or an empty value.
+func leafValue(e *yang.Entry) interface{} {
+	if e.IsLeafList() {
+		defs := e.DefaultValues()
+		vs := make([]interface{}, 0, len(defs))
+		for _, d := range defs {
+			vs = append(vs, d)
+		}
+		return vs
+	}
+	if d, ok := e.SingleDefaultValue(); ok {
+		return d
+	}
+	if e.Mandatory == yang.TSTrue || isListKey(e) {
+		return mandatoryLeafValue
+	}
+	return ""
+}
+
+func isListKey(e *yang.Entry) bool {
+	if e.Parent == nil || !e.Parent.IsList() {
+		return false
+	}
+	for _, k := range strings.Fields(e.Parent.Key) {
+		if k == e.Name {
+			return true
+		}
+	}
+	return false
+}
+
 func pathToUpdateItem(p string, m map[string]interface{}, kopts *keyOpts) (*config.UpdateItem, error) {
 	v, err := getSubMapByPath(p, m, kopts)
 	return &config.UpdateItem{
@@ -477,7 +519,7 @@ func getSubMapByPath(p string, m map[string]interface{}, kopts *keyOpts) (interf
 			if r, ok := rValm[item]; ok {
 				rVal = r
 			} else {
-				return nil, fmt.Errorf("unknown path item %q in path %q", item, p)
+				return nil, unknownPathItemError(item, p, rValm)
 			}
 		case []interface{}:
 			if len(rValm) != 1 {
@@ -488,7 +530,7 @@ func getSubMapByPath(p string, m map[string]interface{}, kopts *keyOpts) (interf
 				if r, ok := rValmn[item]; ok {
 					rVal = r
 				} else {
-					return nil, fmt.Errorf("unknown path item %q in path %q", item, p)
+					return nil, unknownPathItemError(item, p, rValmn)
 				}
 			}
 		default:
@@ -498,6 +540,82 @@ func getSubMapByPath(p string, m map[string]interface{}, kopts *keyOpts) (interf
 	return rVal, nil
 }
 
+// unknownPathItemError returns an unknown path item error
+// suggesting the closest names found in m.
+func unknownPathItemError(item, p string, m map[string]interface{}) error {
+	candidates := make([]string, 0, len(m))
+	for k := range m {
+		candidates = append(candidates, k)
+	}
+	matches := closeMatches(item, candidates, 3)
+	if len(matches) == 0 {
+		return fmt.Errorf("unknown path item %q in path %q", item, p)
+	}
+	return fmt.Errorf("unknown path item %q in path %q, did you mean %q ?", item, p, strings.Join(matches, `", "`))
+}
+
+// closeMatches returns up to limit candidates containing s, contained in s
+// or within an edit distance of a third of the length of s, closest first.
+func closeMatches(s string, candidates []string, limit int) []string {
+	maxDist := len(s) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	dists := make(map[string]int)
+	for _, c := range candidates {
+		d := levenshtein(s, c)
+		if d <= maxDist || strings.Contains(c, s) || strings.Contains(s, c) {
+			dists[c] = d
+		}
+	}
+	matches := make([]string, 0, len(dists))
+	for c := range dists {
+		matches = append(matches, c)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if dists[matches[i]] == dists[matches[j]] {
+			return matches[i] < matches[j]
+		}
+		return dists[matches[i]] < dists[matches[j]]
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// levenshtein returns the edit distance between strings a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
 //////
 
 func resolveGlobs(globs []string) ([]string, error) {
@@ -0,0 +1,209 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const generateTestModule = `
+module test-interfaces {
+  yang-version 1.1;
+  namespace "urn:test:interfaces";
+  prefix ti;
+
+  typedef mtu-type {
+    type uint16;
+    default 1500;
+  }
+
+  container interfaces {
+    list interface {
+      key name;
+      leaf name {
+        type string;
+      }
+      container config {
+        leaf name {
+          type string;
+        }
+        leaf mtu {
+          type mtu-type;
+        }
+        leaf enabled {
+          type boolean;
+          default true;
+        }
+        leaf type {
+          type string;
+          mandatory true;
+        }
+        leaf-list tags {
+          type string;
+          default a;
+          default b;
+        }
+      }
+      container state {
+        config false;
+        leaf counter {
+          type uint64;
+        }
+      }
+    }
+  }
+}
+`
+
+func generateTestEntry(t *testing.T) *yang.Entry {
+	ms := yang.NewModules()
+	err := ms.Parse(generateTestModule, "test-interfaces.yang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e, errs := ms.GetModule("test-interfaces")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return e
+}
+
+func TestToMap(t *testing.T) {
+	configLeaves := map[string]interface{}{
+		"name":    "",
+		"mtu":     "1500",
+		"enabled": "true",
+		"type":    mandatoryLeafValue,
+		"tags":    []interface{}{"a", "b"},
+	}
+	tests := map[string]struct {
+		configOnly bool
+		want       interface{}
+	}{
+		"config_only": {
+			configOnly: true,
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{
+							"name":   mandatoryLeafValue,
+							"config": configLeaves,
+						},
+					},
+				},
+			},
+		},
+		"with_state": {
+			configOnly: false,
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{
+							"name":   mandatoryLeafValue,
+							"config": configLeaves,
+							"state": map[string]interface{}{
+								"counter": "",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	e := generateTestEntry(t)
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := toMap(e, item.configOnly, new(keyOpts))
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %#v", item.want)
+				t.Logf("     got: %#v", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestGetSubMapByPathSuggestions(t *testing.T) {
+	m, ok := toMap(generateTestEntry(t), true, new(keyOpts)).(map[string]interface{})
+	if !ok {
+		t.Fatal("unexpected generated map type")
+	}
+	tests := map[string]struct {
+		path string
+		want string
+	}{
+		"typo": {
+			path: "/interfaces/interfce/config",
+			want: `did you mean "interface" ?`,
+		},
+		"typo_in_list": {
+			path: "/interfaces/interface/confg",
+			want: `did you mean "config" ?`,
+		},
+		"no_match": {
+			path: "/interfaces/interface/subinterfaces",
+			want: `unknown path item "subinterfaces" in path "/interfaces/interface/subinterfaces"`,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := getSubMapByPath(item.path, m, new(keyOpts))
+			if err == nil || !strings.HasSuffix(err.Error(), item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected error ending with: %q", item.want)
+				t.Logf("                       got: %v", err)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestCloseMatches(t *testing.T) {
+	candidates := []string{"interface", "interfaces", "network-instance", "system"}
+	tests := map[string]struct {
+		in   string
+		want []string
+	}{
+		"exact": {
+			in:   "system",
+			want: []string{"system"},
+		},
+		"typo": {
+			in:   "interfce",
+			want: []string{"interface", "interfaces"},
+		},
+		"substring": {
+			in:   "network",
+			want: []string{"network-instance"},
+		},
+		"none": {
+			in:   "routing-policy",
+			want: []string{},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := closeMatches(item.in, candidates, 3)
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.want)
+				t.Logf("     got: %v", got)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -10,24 +10,67 @@ package app
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
 	"github.com/openconfig/gnmic/config"
 	"github.com/openconfig/gnmic/formatters"
 	"github.com/openconfig/gnmic/types"
 	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
 )
 
 func (a *App) GetPreRunE(cmd *cobra.Command, args []string) error {
 	a.Config.SetLocalFlagsFromFile(cmd)
 	a.Config.LocalFlags.GetPath = config.SanitizeArrayFlagValue(a.Config.LocalFlags.GetPath)
+	var err error
+	a.Config.LocalFlags.GetPath, err = config.MergePaths(a.Context(), a.Config.LocalFlags.GetPath, a.Config.LocalFlags.GetPathFile)
+	if err != nil {
+		return err
+	}
+	if len(a.Config.LocalFlags.GetPath) == 0 && !a.Config.LocalFlags.GetAgain {
+		return errors.New("at least one path is required, set it with --path or --path-file")
+	}
+	if a.Config.LocalFlags.GetAppend && !a.Config.LocalFlags.GetAgain {
+		return errors.New("flag --append requires --again")
+	}
 	a.Config.LocalFlags.GetModel = config.SanitizeArrayFlagValue(a.Config.LocalFlags.GetModel)
 	a.Config.LocalFlags.GetProcessor = config.SanitizeArrayFlagValue(a.Config.LocalFlags.GetProcessor)
+	if a.Config.LocalFlags.GetBatchSize < 0 {
+		return errors.New("flag --batch-size must not be negative")
+	}
+	if a.Config.LocalFlags.GetContinueOnBatchError && a.Config.LocalFlags.GetBatchSize == 0 {
+		return errors.New("flag --continue-on-batch-error requires --batch-size")
+	}
+	if a.Config.LocalFlags.GetValuesOnly {
+		if a.Config.Format != "" {
+			return fmt.Errorf("flag --values-only cannot be combined with --format %s", a.Config.Format)
+		}
+		if len(a.Config.LocalFlags.GetProcessor) > 0 {
+			return errors.New("flag --values-only cannot be combined with --processor")
+		}
+	}
+	if len(a.Config.LocalFlags.GetAssert) > 0 &&
+		(a.Config.LocalFlags.GetValuesOnly || len(a.Config.LocalFlags.GetProcessor) > 0) {
+		return errors.New("flag --assert cannot be combined with --values-only or --processor")
+	}
+	if a.Config.LocalFlags.GetSummaryOnly &&
+		(a.Config.LocalFlags.GetValuesOnly || len(a.Config.LocalFlags.GetProcessor) > 0 || len(a.Config.LocalFlags.GetAssert) > 0) {
+		return errors.New("flag --summary-only cannot be combined with --values-only, --processor or --assert")
+	}
+	if a.Config.LocalFlags.GetAggregate {
+		if a.Config.LocalFlags.GetValuesOnly || len(a.Config.LocalFlags.GetProcessor) > 0 ||
+			len(a.Config.LocalFlags.GetAssert) > 0 || a.Config.LocalFlags.GetSummaryOnly {
+			return errors.New("flag --aggregate cannot be combined with --values-only, --processor, --assert or --summary-only")
+		}
+		if a.Config.Format != "" && a.Config.Format != formatJSON {
+			return fmt.Errorf("flag --aggregate cannot be combined with --format %s", a.Config.Format)
+		}
+	}
 
 	a.createCollectorDialOpts()
 	return a.initTunnelServer(tunnel.ServerConfig{
@@ -41,7 +84,7 @@ func (a *App) GetPreRunE(cmd *cobra.Command, args []string) error {
 func (a *App) GetRun(cmd *cobra.Command, args []string) error {
 	defer a.InitGetFlags(cmd)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(a.ctx)
 	defer cancel()
 	// setupCloseHandler(cancel)
 	targetsConfig, err := a.GetTargets()
@@ -62,10 +105,36 @@ func (a *App) GetRun(cmd *cobra.Command, args []string) error {
 			a.AddTargetConfig(tc)
 		}
 	}
-	req, err := a.Config.CreateGetRequest()
+	var req *gnmi.GetRequest
+	if a.Config.LocalFlags.GetAgain {
+		req, err = a.againGetRequest(cmd)
+	} else {
+		req, err = a.Config.CreateGetRequest()
+	}
 	if err != nil {
 		return err
 	}
+	a.saveLastRequest(lastRequestGet, nil, req)
+	if a.Config.LocalFlags.GetExpandWildcards {
+		err = a.previewGetWildcards(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+	defer a.startProgress("get", len(a.Config.Targets))()
+	if len(a.Config.LocalFlags.GetAssert) > 0 {
+		assertions, err := a.parseGetAssertions()
+		if err != nil {
+			return err
+		}
+		return a.handleGetRequestAssert(ctx, req, assertions)
+	}
+	if a.Config.LocalFlags.GetSummaryOnly {
+		return a.handleGetRequestSummary(ctx, req)
+	}
+	if a.Config.LocalFlags.GetAggregate {
+		return a.handleGetRequestAggregate(ctx, req)
+	}
 	// event format
 	if len(a.Config.GetProcessor) > 0 {
 		a.Config.Format = formatEvent
@@ -90,19 +159,38 @@ func (a *App) GetRun(cmd *cobra.Command, args []string) error {
 
 func (a *App) GetRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest) {
 	defer a.wg.Done()
+	ctx, md := rpcMetadataContext(ctx)
 	response, err := a.getRequest(ctx, tc, req)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
+		// already reported by getRequest
 		return
 	}
-	err = a.PrintMsg(tc.Name, "Get Response:", response)
+	err = a.checkMaxMemory(response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, err)
+		return
+	}
+	for _, n := range response.GetNotification() {
+		a.decodeAnyValues(n)
+	}
+	err = a.printMsg(tc.Name, "Get Response:", response, a.rpcMeta(tc, md))
+	if err != nil {
+		a.logTargetError(tc.Name, err)
 	}
 }
 
-func (a *App) getRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
-	xreq := req
+func (a *App) getRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest) (response *gnmi.GetResponse, err error) {
+	defer func() {
+		a.progressDone(tc.Name, err != nil)
+	}()
+	// the request is shared by all targets,
+	// it is copied before setting the target encoding and models.
+	xreq := proto.Clone(req).(*gnmi.GetRequest)
+	err = api.Encoding(a.Config.RPCEncoding(config.RPCGet, tc))(xreq)
+	if err != nil {
+		a.logTargetError(tc.Name, err)
+		return nil, err
+	}
 	if len(a.Config.LocalFlags.GetModel) > 0 {
 		spModels, unspModels, err := a.filterModels(ctx, tc, a.Config.LocalFlags.GetModel)
 		if err != nil {
@@ -116,21 +204,42 @@ func (a *App) getRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.
 			xreq.UseModels = append(xreq.UseModels, m)
 		}
 	}
+	reqs := splitGetRequest(xreq, a.Config.LocalFlags.GetBatchSize)
+	if len(reqs) > 1 {
+		return a.getRequestBatches(ctx, tc, reqs)
+	}
+	response, err = a.sendGetRequest(ctx, tc, xreq)
+	if err != nil {
+		a.logRPCError(tc.Name, "Get", err)
+		return nil, err
+	}
+	return response, nil
+}
+
+func (a *App) sendGetRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
 	if a.Config.PrintRequest {
 		err := a.PrintMsg(tc.Name, "Get Request:", req)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q Get Request printing failed: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("Get Request printing failed: %v", err))
 		}
 	}
 	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
-		xreq.Prefix, xreq.Path, xreq.Type, xreq.Encoding, xreq.UseModels, xreq.Extension, tc.Name)
+		req.Prefix, req.Path, req.Type, req.Encoding, req.UseModels, req.Extension, tc.Name)
+	return a.clientGetFallback(ctx, tc, req)
+}
 
-	response, err := a.ClientGet(ctx, tc, xreq)
-	if err != nil {
-		a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
-		return nil, err
+// checkMaxMemory returns an error if the encoded size of the GetResponse exceeds
+// the --max-memory limit, the formatted output being several times larger than
+// the encoded response, it is checked before formatting the response.
+func (a *App) checkMaxMemory(rsp *gnmi.GetResponse) error {
+	if a.Config.LocalFlags.GetMaxMemory <= 0 {
+		return nil
 	}
-	return response, nil
+	size := proto.Size(rsp)
+	if size > a.Config.LocalFlags.GetMaxMemory {
+		return fmt.Errorf("response size (%d bytes) exceeds --max-memory (%d bytes), use --summary-only or narrower paths", size, a.Config.LocalFlags.GetMaxMemory)
+	}
+	return nil
 }
 
 func (a *App) filterModels(ctx context.Context, tc *types.TargetConfig, modelsNames []string) (map[string]*gnmi.ModelData, []string, error) {
@@ -162,13 +271,24 @@ func (a *App) InitGetFlags(cmd *cobra.Command) {
 	cmd.ResetFlags()
 
 	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.GetPath, "path", "", []string{}, "get request paths")
-	cmd.MarkFlagRequired("path")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.GetPathFile, "path-file", "", "", "file with the get request paths, one per line. Merged with the --path values")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.GetPrefix, "prefix", "", "", "get request prefix")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.GetModel, "model", "", []string{}, "get request models")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.GetType, "type", "t", "ALL", "data type requested from the target. one of: ALL, CONFIG, STATE, OPERATIONAL")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.GetTarget, "target", "", "", "get request target")
-	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetValuesOnly, "values-only", "", false, "print GetResponse values only")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetValuesOnly, "values-only", "", false, "print GetResponse values only, one per line")
 	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.GetProcessor, "processor", "", []string{}, "list of processor names to run")
+	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.GetAssert, "assert", "", []string{}, "assertion evaluated against the returned values, e.g: 'value < -3.0', 'value == \"up\"', 'value =~ ^up' or 'exists'. Exits with code 1 if any assertion fails")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetSummaryOnly, "summary-only", "", false, "print, per target, the number of notifications, update leaves and top-level containers and the encoded size of the response instead of its values")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetAggregate, "aggregate", "", false, "group the targets returning identical responses, ignoring timestamps and target prefixes, and print each unique response once with the list of targets that returned it")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.GetBatchSize, "batch-size", "", 0, "maximum number of paths per GetRequest, the paths are split into sequential GetRequests whose responses are merged. 0 means a single GetRequest")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetContinueOnBatchError, "continue-on-batch-error", "", false, "with --batch-size, send the remaining batches if a batch fails")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.GetMaxMemory, "max-memory", "", 0, "maximum encoded size in bytes of a target Get response to be formatted, larger responses are skipped with an error. 0 means no limit")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetAgain, "again", "", false, "re-send the last get request built by gnmic, saved in ~/.gnmic/last-get.json, the request flags set on the command line override the saved values")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetAppend, "append", "", false, "with --again, append the --path values to the saved paths instead of replacing them")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetExpandWildcards, "expand-wildcards", "", false, "before the request, discover the keys matching the wildcard keys of the paths with a Get per target, print the expanded paths and ask for a confirmation")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.GetExpandMax, "expand-max", "", defaultExpandMax, "maximum number of expanded paths printed per path and target by --expand-wildcards, 0 means no limit")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.GetYes, "yes", "", false, "proceed after the --expand-wildcards preview without asking for a confirmation")
 
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
@@ -221,6 +341,11 @@ func (a *App) handleGetRequestEvent(ctx context.Context, req *gnmi.GetRequest, e
 				a.errCh <- err
 				return
 			}
+			err = a.checkMaxMemory(resp)
+			if err != nil {
+				a.errCh <- fmt.Errorf("target %q: %v", tc.Name, err)
+				return
+			}
 			evs, err := formatters.GetResponseToEventMsgs(resp, map[string]string{"source": tc.Name}, evps...)
 			if err != nil {
 				a.errCh <- err
@@ -240,19 +365,16 @@ func (a *App) handleGetRequestEvent(ctx context.Context, req *gnmi.GetRequest, e
 		return err
 	}
 	//
-	sb := strings.Builder{}
 	for name, r := range responses {
-		sb.Reset()
-		printPrefix := ""
-		if len(a.Config.TargetsList()) > 1 && !a.Config.NoPrefix {
-			printPrefix = fmt.Sprintf("[%s] ", name)
+		printPrefix := a.printPrefix(name, true)
+		b, err := formatters.MarshalJSON(r, a.Config.JSONIndent)
+		if err != nil {
+			return err
 		}
-		b, err := json.MarshalIndent(r, "", "  ")
+		err = a.writeOutput(printPrefix, b)
 		if err != nil {
 			return err
 		}
-		sb.Write(b)
-		fmt.Fprintf(a.out, "%s\n", indent(printPrefix, sb.String()))
 	}
 
 	return nil
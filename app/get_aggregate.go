@@ -0,0 +1,136 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+)
+
+// getResponseGroup is a set of targets that returned the same
+// normalized GetResponse.
+type getResponseGroup struct {
+	Value   map[string]interface{} `json:"value"`
+	Targets []string               `json:"targets"`
+}
+
+// normalizeGetResponse flattens the GetResponse into a map of xpath to value.
+// The notification timestamps and the prefix target are not part of the result,
+// so responses with the same values from different targets are equal.
+// The returned key is the canonical JSON encoding of the map.
+func normalizeGetResponse(rsp *gnmi.GetResponse) (map[string]interface{}, string, error) {
+	if rsp == nil {
+		return nil, "", errors.New("nil response")
+	}
+	value, err := formatters.ResponsesFlat(rsp)
+	if err != nil {
+		return nil, "", err
+	}
+	// encoding/json sorts map keys
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", err
+	}
+	return value, string(b), nil
+}
+
+// groupGetResponses groups the targets by identical normalized responses.
+// The groups are sorted by decreasing size, then by their first target name.
+func groupGetResponses(rsps map[string]*gnmi.GetResponse) ([]*getResponseGroup, error) {
+	groups := make(map[string]*getResponseGroup)
+	for name, rsp := range rsps {
+		value, key, err := normalizeGetResponse(rsp)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %v", name, err)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &getResponseGroup{Value: value, Targets: make([]string, 0, 1)}
+			groups[key] = g
+		}
+		g.Targets = append(g.Targets, name)
+	}
+	result := make([]*getResponseGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Targets)
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Targets) != len(result[j].Targets) {
+			return len(result[i].Targets) > len(result[j].Targets)
+		}
+		return result[i].Targets[0] < result[j].Targets[0]
+	})
+	return result, nil
+}
+
+// handleGetRequestAggregate sends the GetRequest to all targets, buffers the responses
+// and prints each unique response once, with the list of targets that returned it.
+func (a *App) handleGetRequestAggregate(ctx context.Context, req *gnmi.GetRequest) error {
+	numTargets := len(a.Config.Targets)
+	a.errCh = make(chan error, numTargets*3)
+	a.wg.Add(numTargets)
+	rsps := make(map[string]*gnmi.GetResponse, numTargets)
+	m := new(sync.Mutex)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			resp, err := a.getRequest(ctx, tc, req)
+			if err != nil {
+				// already reported by getRequest
+				return
+			}
+			m.Lock()
+			rsps[tc.Name] = resp
+			m.Unlock()
+		}(tc)
+	}
+	a.wg.Wait()
+
+	groups, err := groupGetResponses(rsps)
+	if err != nil {
+		return err
+	}
+	if a.Config.Format == formatJSON {
+		b, err := formatters.MarshalJSON(groups, a.Config.JSONIndent)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, string(b))
+	} else {
+		a.printGetResponseGroups(groups, numTargets)
+	}
+	return a.checkErrors()
+}
+
+func (a *App) printGetResponseGroups(groups []*getResponseGroup, numTargets int) {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(a.out)
+		}
+		fmt.Fprintf(a.out, "%d/%d target(s): %s\n", len(g.Targets), numTargets, strings.Join(g.Targets, ", "))
+		paths := make([]string, 0, len(g.Value))
+		for p := range g.Value {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			fmt.Fprintf(a.out, "  %s: %v\n", p, g.Value[p])
+		}
+	}
+}
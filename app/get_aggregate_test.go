@@ -0,0 +1,95 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func aggregateResponse(target string, ts int64, domain string) *gnmi.GetResponse {
+	return &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{
+			{
+				Timestamp: ts,
+				Prefix:    &gnmi.Path{Target: target, Elem: []*gnmi.PathElem{{Name: "system"}}},
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+							{Name: "config"},
+							{Name: "domain-name"},
+						}},
+						Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: domain}},
+					},
+				},
+			},
+		},
+	}
+}
+
+var groupGetResponsesTestSet = map[string]struct {
+	in  map[string]*gnmi.GetResponse
+	out []*getResponseGroup
+}{
+	"empty": {
+		in:  map[string]*gnmi.GetResponse{},
+		out: []*getResponseGroup{},
+	},
+	"identical_ignoring_timestamp_and_target": {
+		in: map[string]*gnmi.GetResponse{
+			"r2": aggregateResponse("r2", 2, "lab"),
+			"r1": aggregateResponse("r1", 1, "lab"),
+		},
+		out: []*getResponseGroup{
+			{
+				Value:   map[string]interface{}{"system/config/domain-name": "lab"},
+				Targets: []string{"r1", "r2"},
+			},
+		},
+	},
+	"sorted_by_group_size": {
+		in: map[string]*gnmi.GetResponse{
+			"r1": aggregateResponse("r1", 1, "prod"),
+			"r2": aggregateResponse("r2", 1, "lab"),
+			"r3": aggregateResponse("r3", 3, "lab"),
+			"r4": aggregateResponse("r4", 4, "dev"),
+		},
+		out: []*getResponseGroup{
+			{
+				Value:   map[string]interface{}{"system/config/domain-name": "lab"},
+				Targets: []string{"r2", "r3"},
+			},
+			{
+				Value:   map[string]interface{}{"system/config/domain-name": "prod"},
+				Targets: []string{"r1"},
+			},
+			{
+				Value:   map[string]interface{}{"system/config/domain-name": "dev"},
+				Targets: []string{"r4"},
+			},
+		},
+	},
+}
+
+func TestGroupGetResponses(t *testing.T) {
+	for name, item := range groupGetResponsesTestSet {
+		t.Run(name, func(t *testing.T) {
+			groups, err := groupGetResponses(item.in)
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			if !reflect.DeepEqual(groups, item.out) {
+				t.Logf("failed at item %q", name)
+				t.Errorf("expected %+v, got %+v", item.out, groups)
+			}
+		})
+	}
+}
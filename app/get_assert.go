@@ -0,0 +1,239 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+)
+
+const (
+	assertExists = "exists"
+	assertAbsent = "absent"
+	assertRegex  = "=~"
+)
+
+// assertion operators, two characters operators first
+var assertOperators = []string{assertRegex, "<=", ">=", "==", "!=", "<", ">"}
+
+// assertion is a --assert expression, either `exists`, `absent`
+// or `value <op> <literal>`.
+type assertion struct {
+	expr string
+	op   string
+	// literal
+	value string
+	// set if the literal is a number
+	num   float64
+	isNum bool
+	// set if the operator is =~
+	re *regexp.Regexp
+}
+
+func parseAssertion(expr string) (*assertion, error) {
+	s := strings.TrimSpace(expr)
+	if s == assertExists || s == assertAbsent {
+		return &assertion{expr: expr, op: s}, nil
+	}
+	if !strings.HasPrefix(s, "value") {
+		return nil, fmt.Errorf("invalid assertion %q: must be %q, %q or start with \"value\"", expr, assertExists, assertAbsent)
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "value"))
+	as := &assertion{expr: expr}
+	for _, op := range assertOperators {
+		if strings.HasPrefix(s, op) {
+			as.op = op
+			break
+		}
+	}
+	if as.op == "" {
+		return nil, fmt.Errorf("invalid assertion %q: missing operator, must be one of %q", expr, assertOperators)
+	}
+	lit := strings.TrimSpace(strings.TrimPrefix(s, as.op))
+	if lit == "" {
+		return nil, fmt.Errorf("invalid assertion %q: missing value", expr)
+	}
+	quoted := len(lit) >= 2 && (lit[0] == '"' || lit[0] == '\'') && lit[len(lit)-1] == lit[0]
+	if quoted {
+		lit = lit[1 : len(lit)-1]
+	}
+	err := as.setValue(lit, quoted)
+	if err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// setValue sets the assertion literal, a quoted literal is never compared as a number.
+func (as *assertion) setValue(lit string, quoted bool) error {
+	as.value = lit
+	if as.op == assertRegex {
+		var err error
+		as.re, err = regexp.Compile(lit)
+		if err != nil {
+			return fmt.Errorf("invalid assertion %q: %v", as.expr, err)
+		}
+		return nil
+	}
+	if !quoted {
+		var err error
+		as.num, err = strconv.ParseFloat(lit, 64)
+		as.isNum = err == nil
+	}
+	if !as.isNum && as.op != "==" && as.op != "!=" {
+		return fmt.Errorf("invalid assertion %q: operator %q requires a numeric value", as.expr, as.op)
+	}
+	return nil
+}
+
+// eval returns true if the value v satisfies the assertion.
+func (as *assertion) eval(v interface{}) bool {
+	sv := fmt.Sprintf("%v", v)
+	if as.re != nil {
+		return as.re.MatchString(sv)
+	}
+	if !as.isNum {
+		switch as.op {
+		case "==":
+			return sv == as.value
+		case "!=":
+			return sv != as.value
+		}
+		return false
+	}
+	fv, err := strconv.ParseFloat(sv, 64)
+	if err != nil {
+		// a non numeric value is only different from a number
+		return as.op == "!="
+	}
+	switch as.op {
+	case "<":
+		return fv < as.num
+	case "<=":
+		return fv <= as.num
+	case ">":
+		return fv > as.num
+	case ">=":
+		return fv >= as.num
+	case "==":
+		return fv == as.num
+	case "!=":
+		return fv != as.num
+	}
+	return false
+}
+
+// check evaluates the assertion against the flattened values of a response
+// and returns the failures, one per offending path/value pair.
+func (as *assertion) check(values map[string]interface{}) []string {
+	if as.op == assertExists {
+		if len(values) == 0 {
+			return []string{fmt.Sprintf("no value returned: assertion %q failed", as.expr)}
+		}
+		return nil
+	}
+	if as.op == assertAbsent {
+		if len(values) == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("%d value(s) returned: assertion %q failed", len(values), as.expr)}
+	}
+	paths := make([]string, 0, len(values))
+	for p := range values {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	failures := make([]string, 0)
+	for _, p := range paths {
+		if !as.eval(values[p]) {
+			failures = append(failures, fmt.Sprintf("%s: %v: assertion %q failed", p, values[p], as.expr))
+		}
+	}
+	return failures
+}
+
+func (a *App) parseGetAssertions() ([]*assertion, error) {
+	assertions := make([]*assertion, 0, len(a.Config.LocalFlags.GetAssert))
+	for _, expr := range a.Config.LocalFlags.GetAssert {
+		as, err := parseAssertion(expr)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, as)
+	}
+	return assertions, nil
+}
+
+// handleGetRequestAssert sends the GetRequest to all targets and evaluates
+// the assertions against the returned values.
+// It prints the offending path/value pairs and returns an error if any assertion fails.
+func (a *App) handleGetRequestAssert(ctx context.Context, req *gnmi.GetRequest, assertions []*assertion) error {
+	numTargets := len(a.Config.Targets)
+	a.errCh = make(chan error, numTargets*3)
+	a.wg.Add(numTargets)
+	rsps := make(chan *getResponseValues, numTargets)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			resp, err := a.getRequest(ctx, tc, req)
+			if err != nil {
+				// already reported by getRequest
+				return
+			}
+			values, err := formatters.ResponsesFlat(resp)
+			if err != nil {
+				a.errCh <- err
+				return
+			}
+			rsps <- &getResponseValues{name: tc.Name, values: values}
+		}(tc)
+	}
+	a.wg.Wait()
+	close(rsps)
+
+	err := a.checkErrors()
+	if err != nil {
+		return err
+	}
+	responses := make([]*getResponseValues, 0, numTargets)
+	for r := range rsps {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].name < responses[j].name
+	})
+	numFailures := 0
+	for _, r := range responses {
+		printPrefix := a.printPrefix(r.name, false)
+		for _, as := range assertions {
+			for _, f := range as.check(r.values) {
+				numFailures++
+				fmt.Fprintf(a.out, "%s%s\n", printPrefix, f)
+			}
+		}
+	}
+	if numFailures > 0 {
+		return fmt.Errorf("%d assertion(s) failed", numFailures)
+	}
+	return nil
+}
+
+type getResponseValues struct {
+	// target name
+	name   string
+	values map[string]interface{}
+}
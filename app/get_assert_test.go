@@ -0,0 +1,138 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import "testing"
+
+var assertionTestSet = map[string]struct {
+	expr     string
+	values   map[string]interface{}
+	failures int
+}{
+	"lower_than_pass": {
+		expr: "value < -3.0",
+		values: map[string]interface{}{
+			"optical-channel/input-power/instant":  -4.5,
+			"optical-channel/output-power/instant": "-3.2",
+		},
+		failures: 0,
+	},
+	"lower_than_fail": {
+		expr: "value<-3",
+		values: map[string]interface{}{
+			"optical-channel/input-power/instant":  -4.5,
+			"optical-channel/output-power/instant": float64(-1),
+		},
+		failures: 1,
+	},
+	"greater_or_equal": {
+		expr: "value >= 100",
+		values: map[string]interface{}{
+			"counters/in-octets":  uint64(100),
+			"counters/out-octets": int64(99),
+		},
+		failures: 1,
+	},
+	"string_equal": {
+		expr: `value == "UP"`,
+		values: map[string]interface{}{
+			"interface/oper-status": "UP",
+		},
+		failures: 0,
+	},
+	"string_not_equal": {
+		expr: "value != 'UP'",
+		values: map[string]interface{}{
+			"interface/oper-status": "UP",
+		},
+		failures: 1,
+	},
+	"numeric_against_string": {
+		expr: "value == 1",
+		values: map[string]interface{}{
+			"interface/oper-status": "UP",
+		},
+		failures: 1,
+	},
+	"exists_pass": {
+		expr: "exists",
+		values: map[string]interface{}{
+			"system/name": "router1",
+		},
+		failures: 0,
+	},
+	"exists_fail": {
+		expr:     "exists",
+		values:   map[string]interface{}{},
+		failures: 1,
+	},
+	"regex_pass": {
+		expr: `value =~ "^Ethernet[0-9]+/[0-9]+$"`,
+		values: map[string]interface{}{
+			"interface/name": "Ethernet1/1",
+		},
+		failures: 0,
+	},
+	"regex_fail": {
+		expr: "value =~ ^(UP|TESTING)$",
+		values: map[string]interface{}{
+			"interface[name=1]/oper-status": "UP",
+			"interface[name=2]/oper-status": "DOWN",
+		},
+		failures: 1,
+	},
+	"absent_pass": {
+		expr:     "absent",
+		values:   map[string]interface{}{},
+		failures: 0,
+	},
+	"absent_fail": {
+		expr: "absent",
+		values: map[string]interface{}{
+			"system/name": "router1",
+		},
+		failures: 1,
+	},
+}
+
+func TestAssertion(t *testing.T) {
+	for name, item := range assertionTestSet {
+		t.Run(name, func(t *testing.T) {
+			as, err := parseAssertion(item.expr)
+			if err != nil {
+				t.Fatalf("failed to parse assertion %q: %v", item.expr, err)
+			}
+			failures := as.check(item.values)
+			if len(failures) != item.failures {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected %d failure(s)", item.failures)
+				t.Logf("     got: %q", failures)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestParseAssertionErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"val < 3",
+		"value",
+		"value 3",
+		"value <",
+		"value < 'abc'",
+		"exist",
+		"value =~ '('",
+	} {
+		_, err := parseAssertion(expr)
+		if err == nil {
+			t.Errorf("expected an error for assertion %q", expr)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+)
+
+// splitGetRequest splits the paths of req into GetRequests of at most batchSize paths,
+// the other fields are shared by all the requests.
+// req is returned as is if batchSize is 0 or if it has batchSize paths or less.
+func splitGetRequest(req *gnmi.GetRequest, batchSize int) []*gnmi.GetRequest {
+	if batchSize <= 0 || len(req.GetPath()) <= batchSize {
+		return []*gnmi.GetRequest{req}
+	}
+	reqs := make([]*gnmi.GetRequest, 0, (len(req.Path)+batchSize-1)/batchSize)
+	for i := 0; i < len(req.Path); i += batchSize {
+		end := i + batchSize
+		if end > len(req.Path) {
+			end = len(req.Path)
+		}
+		reqs = append(reqs, &gnmi.GetRequest{
+			Prefix:    req.Prefix,
+			Path:      req.Path[i:end],
+			Type:      req.Type,
+			Encoding:  req.Encoding,
+			UseModels: req.UseModels,
+			Extension: req.Extension,
+		})
+	}
+	return reqs
+}
+
+// getRequestBatches sends the batch requests sequentially to target tc
+// and merges their notifications into a single GetResponse.
+// A failed batch is reported with its index, the remaining batches are sent
+// only with --continue-on-batch-error.
+// An error is returned if no batch succeeded, or on the first failure without
+// --continue-on-batch-error.
+func (a *App) getRequestBatches(ctx context.Context, tc *types.TargetConfig, reqs []*gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	response := new(gnmi.GetResponse)
+	var err error
+	numFailed := 0
+	for i, req := range reqs {
+		var rsp *gnmi.GetResponse
+		rsp, err = a.sendGetRequest(ctx, tc, req)
+		if err != nil {
+			numFailed++
+			err = fmt.Errorf("batch %d/%d (%d paths): %v", i+1, len(reqs), len(req.GetPath()), err)
+			a.logRPCError(tc.Name, "Get", err)
+			if !a.Config.LocalFlags.GetContinueOnBatchError {
+				return nil, err
+			}
+			continue
+		}
+		response.Notification = append(response.Notification, rsp.GetNotification()...)
+		response.Extension = append(response.Extension, rsp.GetExtension()...)
+	}
+	if numFailed == len(reqs) {
+		return nil, fmt.Errorf("all %d batches failed, last error: %v", len(reqs), err)
+	}
+	return response, nil
+}
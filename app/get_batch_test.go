@@ -0,0 +1,187 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchServer is a gNMI server replying to a Get request with one notification per path,
+// it fails the requests including the path failPath.
+type batchServer struct {
+	gnmi.UnimplementedGNMIServer
+	failPath string
+
+	m        sync.Mutex
+	numPaths []int
+}
+
+func (s *batchServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	s.m.Lock()
+	s.numPaths = append(s.numPaths, len(req.GetPath()))
+	s.m.Unlock()
+	rsp := new(gnmi.GetResponse)
+	for _, p := range req.GetPath() {
+		if p.GetElem()[0].GetName() == s.failPath {
+			return nil, status.Error(codes.InvalidArgument, "unsupported path")
+		}
+		rsp.Notification = append(rsp.Notification, &gnmi.Notification{
+			Update: []*gnmi.Update{{Path: p, Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 1}}}},
+		})
+	}
+	return rsp, nil
+}
+
+func batchTestRequest(names ...string) *gnmi.GetRequest {
+	req := &gnmi.GetRequest{Encoding: gnmi.Encoding_JSON}
+	for _, n := range names {
+		req.Path = append(req.Path, &gnmi.Path{Elem: []*gnmi.PathElem{{Name: n}}})
+	}
+	return req
+}
+
+func TestSplitGetRequest(t *testing.T) {
+	tests := map[string]struct {
+		numPaths  int
+		batchSize int
+		want      []int
+	}{
+		"no_batch": {
+			numPaths:  5,
+			batchSize: 0,
+			want:      []int{5},
+		},
+		"single_batch": {
+			numPaths:  3,
+			batchSize: 3,
+			want:      []int{3},
+		},
+		"even": {
+			numPaths:  6,
+			batchSize: 2,
+			want:      []int{2, 2, 2},
+		},
+		"remainder": {
+			numPaths:  7,
+			batchSize: 3,
+			want:      []int{3, 3, 1},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			names := make([]string, 0, item.numPaths)
+			for i := 0; i < item.numPaths; i++ {
+				names = append(names, string(rune('a'+i)))
+			}
+			reqs := splitGetRequest(batchTestRequest(names...), item.batchSize)
+			got := make([]int, 0, len(reqs))
+			for _, req := range reqs {
+				if req.GetEncoding() != gnmi.Encoding_JSON {
+					t.Errorf("expected the batch encoding to be preserved")
+				}
+				got = append(got, len(req.GetPath()))
+			}
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.want)
+				t.Logf("     got: %v", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestGetRequestBatches(t *testing.T) {
+	tests := map[string]struct {
+		failPath        string
+		continueOnError bool
+		wantNumPaths    []int
+		wantUpdates     int
+		wantErr         string
+	}{
+		"all_batches": {
+			wantNumPaths: []int{2, 2, 1},
+			wantUpdates:  5,
+		},
+		"stop_on_error": {
+			failPath:     "c",
+			wantNumPaths: []int{2, 2},
+			wantErr:      "batch 2/3 (2 paths)",
+		},
+		"continue_on_error": {
+			failPath:        "c",
+			continueOnError: true,
+			wantNumPaths:    []int{2, 2, 1},
+			wantUpdates:     3,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &batchServer{failPath: item.failPath}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, srv)
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.Config.Encoding = "json"
+			a.Config.LocalFlags.GetBatchSize = 2
+			a.Config.LocalFlags.GetContinueOnBatchError = item.continueOnError
+			a.errCh = make(chan error, 3)
+			a.createCollectorDialOpts()
+			insecure := true
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Timeout:  5 * time.Second,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			rsp, err := a.getRequest(ctx, tc, batchTestRequest("a", "b", "c", "d", "e"))
+			if item.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), item.wantErr) {
+					t.Fatalf("expected an error containing %q, got: %v", item.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(srv.numPaths, item.wantNumPaths) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected batches: %v", item.wantNumPaths)
+				t.Logf("     got batches: %v", srv.numPaths)
+				t.Fail()
+			}
+			if got := len(rsp.GetNotification()); got != item.wantUpdates {
+				t.Errorf("expected %d merged notifications, got %d", item.wantUpdates, got)
+			}
+		})
+	}
+}
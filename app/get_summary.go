@@ -0,0 +1,187 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// getResponseSummary describes the size of a GetResponse.
+type getResponseSummary struct {
+	Target        string   `json:"target,omitempty"`
+	Notifications int      `json:"notifications"`
+	Updates       int      `json:"updates"`
+	NumContainers int      `json:"num-containers"`
+	Containers    []string `json:"containers,omitempty"`
+	Size          int      `json:"size"`
+}
+
+// summarizeGetResponse walks the GetResponse notifications and counts
+// the notifications, the update leaves and the distinct top-level containers.
+// The size is the protobuf encoded size of the response.
+func summarizeGetResponse(rsp *gnmi.GetResponse) (*getResponseSummary, error) {
+	s := &getResponseSummary{
+		Notifications: len(rsp.GetNotification()),
+		Containers:    make([]string, 0),
+		Size:          proto.Size(rsp),
+	}
+	containers := make(map[string]struct{})
+	for _, n := range rsp.GetNotification() {
+		for _, upd := range n.GetUpdate() {
+			var value interface{}
+			var jsondata []byte
+			switch upd.GetVal().GetValue().(type) {
+			case *gnmi.TypedValue_JsonVal:
+				jsondata = upd.GetVal().GetJsonVal()
+			case *gnmi.TypedValue_JsonIetfVal:
+				jsondata = upd.GetVal().GetJsonIetfVal()
+			}
+			if len(jsondata) > 0 {
+				err := json.Unmarshal(jsondata, &value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode the value of path %q: %v",
+						gnmiFullPathString(n.GetPrefix(), upd.GetPath()), err)
+				}
+				s.Updates += countLeaves(value)
+			} else {
+				s.Updates++
+			}
+			for _, c := range topLevelContainers(n.GetPrefix(), upd.GetPath(), value) {
+				containers[c] = struct{}{}
+			}
+		}
+	}
+	for c := range containers {
+		s.Containers = append(s.Containers, c)
+	}
+	sort.Strings(s.Containers)
+	s.NumContainers = len(s.Containers)
+	return s, nil
+}
+
+// countLeaves returns the number of leaves in a decoded JSON value,
+// a list of scalar values (leaf-list) counts as a single leaf.
+func countLeaves(v interface{}) int {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n := 0
+		for _, vv := range v {
+			n += countLeaves(vv)
+		}
+		return n
+	case []interface{}:
+		n := 0
+		for _, vv := range v {
+			switch vv.(type) {
+			case map[string]interface{}, []interface{}:
+				n += countLeaves(vv)
+			}
+		}
+		if n == 0 {
+			return 1
+		}
+		return n
+	}
+	return 1
+}
+
+// topLevelContainers returns the first element of the update path,
+// or the top-level keys of the JSON value if the update is for the root path.
+func topLevelContainers(prefix, path *gnmi.Path, value interface{}) []string {
+	if len(prefix.GetElem()) > 0 {
+		return []string{prefix.GetElem()[0].GetName()}
+	}
+	if len(path.GetElem()) > 0 {
+		return []string{path.GetElem()[0].GetName()}
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	cs := make([]string, 0, len(m))
+	for k := range m {
+		cs = append(cs, k)
+	}
+	return cs
+}
+
+func gnmiFullPathString(prefix, path *gnmi.Path) string {
+	p := strings.TrimRight(utils.GnmiPathToXPath(prefix, false), "/")
+	return p + "/" + strings.TrimLeft(utils.GnmiPathToXPath(path, false), "/")
+}
+
+// handleGetRequestSummary sends the GetRequest to all targets and prints,
+// per target, a summary of the response instead of its values.
+func (a *App) handleGetRequestSummary(ctx context.Context, req *gnmi.GetRequest) error {
+	numTargets := len(a.Config.Targets)
+	a.errCh = make(chan error, numTargets*3)
+	a.wg.Add(numTargets)
+	rsps := make(chan *getResponseSummary, numTargets)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			resp, err := a.getRequest(ctx, tc, req)
+			if err != nil {
+				// already reported by getRequest
+				return
+			}
+			s, err := summarizeGetResponse(resp)
+			if err != nil {
+				a.errCh <- fmt.Errorf("target %q: %v", tc.Name, err)
+				return
+			}
+			s.Target = tc.Name
+			rsps <- s
+		}(tc)
+	}
+	a.wg.Wait()
+	close(rsps)
+
+	err := a.checkErrors()
+	if err != nil {
+		return err
+	}
+	summaries := make([]*getResponseSummary, 0, numTargets)
+	for s := range rsps {
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Target < summaries[j].Target
+	})
+	if a.Config.Format == formatJSON {
+		b, err := formatters.MarshalJSON(summaries, a.Config.JSONIndent)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, string(b))
+		return nil
+	}
+	for _, s := range summaries {
+		printPrefix := a.printPrefix(s.Target, false)
+		fmt.Fprintf(a.out, "%snotifications: %d\n", printPrefix, s.Notifications)
+		fmt.Fprintf(a.out, "%supdates: %d\n", printPrefix, s.Updates)
+		fmt.Fprintf(a.out, "%stop-level containers: %d", printPrefix, s.NumContainers)
+		if s.NumContainers > 0 {
+			fmt.Fprintf(a.out, " (%s)", strings.Join(s.Containers, ", "))
+		}
+		fmt.Fprintln(a.out)
+		fmt.Fprintf(a.out, "%ssize: %d bytes\n", printPrefix, s.Size)
+	}
+	return nil
+}
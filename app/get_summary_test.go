@@ -0,0 +1,128 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func summaryPath(elems ...string) *gnmi.Path {
+	p := new(gnmi.Path)
+	for _, e := range elems {
+		p.Elem = append(p.Elem, &gnmi.PathElem{Name: e})
+	}
+	return p
+}
+
+var summarizeGetResponseTestSet = map[string]struct {
+	in  *gnmi.GetResponse
+	out *getResponseSummary
+}{
+	"empty": {
+		in:  &gnmi.GetResponse{},
+		out: &getResponseSummary{Containers: []string{}},
+	},
+	"scalar_updates": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("interfaces", "interface", "state", "mtu"),
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 9000}},
+						},
+						{
+							Path: summaryPath("system", "state", "hostname"),
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "r1"}},
+						},
+					},
+				},
+			},
+		},
+		out: &getResponseSummary{
+			Notifications: 1,
+			Updates:       2,
+			NumContainers: 2,
+			Containers:    []string{"interfaces", "system"},
+		},
+	},
+	"json_value_with_prefix": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Prefix: summaryPath("interfaces"),
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("interface"),
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+								JsonIetfVal: []byte(`[{"name":"e1","mtu":1500,"ips":["10.0.0.1","10.0.0.2"]},{"name":"e2","mtu":9000}]`),
+							}},
+						},
+					},
+				},
+			},
+		},
+		out: &getResponseSummary{
+			Notifications: 1,
+			Updates:       5,
+			NumContainers: 1,
+			Containers:    []string{"interfaces"},
+		},
+	},
+	"root_json_value": {
+		in: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+								JsonVal: []byte(`{"system":{"hostname":"r1"},"interfaces":{"interface":[{"name":"e1"}]}}`),
+							}},
+						},
+					},
+				},
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: summaryPath("system", "clock"),
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "UTC"}},
+						},
+					},
+				},
+			},
+		},
+		out: &getResponseSummary{
+			Notifications: 2,
+			Updates:       3,
+			NumContainers: 2,
+			Containers:    []string{"interfaces", "system"},
+		},
+	},
+}
+
+func TestSummarizeGetResponse(t *testing.T) {
+	for name, item := range summarizeGetResponseTestSet {
+		t.Run(name, func(t *testing.T) {
+			s, err := summarizeGetResponse(item.in)
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			item.out.Size = proto.Size(item.in)
+			if !reflect.DeepEqual(s, item.out) {
+				t.Logf("failed at item %q", name)
+				t.Errorf("expected %+v, got %+v", item.out, s)
+			}
+		})
+	}
+}
@@ -25,6 +25,9 @@ import (
 )
 
 func (a *App) GetSetPreRunE(cmd *cobra.Command, args []string) error {
+	if err := a.checkReadOnly(cmd); err != nil {
+		return err
+	}
 	a.Config.SetLocalFlagsFromFile(cmd)
 	a.Config.LocalFlags.GetSetModel = config.SanitizeArrayFlagValue(a.Config.LocalFlags.GetSetModel)
 
@@ -88,19 +91,19 @@ func (a *App) GetSetRequest(ctx context.Context, tc *types.TargetConfig, req *gn
 	if a.Config.PrintRequest {
 		err := a.PrintMsg(tc.Name, "Get Request:", req)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q Get Request printing failed: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("Get Request printing failed: %v", err))
 		}
 	}
 	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
 		xreq.Prefix, xreq.Path, xreq.Type, xreq.Encoding, xreq.UseModels, xreq.Extension, tc.Name)
 	response, err := a.ClientGet(ctx, tc, xreq)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("get request failed: %v", err))
 		return
 	}
 	err = a.PrintMsg(tc.Name, "Get Response:", response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, err)
 	}
 	//
 	q, err := gojq.Parse(a.Config.LocalFlags.GetSetCondition)
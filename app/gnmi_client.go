@@ -10,11 +10,12 @@ package app
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/proto"
 )
 
 func (a *App) ClientCapabilities(ctx context.Context, tc *types.TargetConfig, ext ...*gnmi_ext.Extension) (*gnmi.CapabilityResponse, error) {
@@ -32,11 +33,16 @@ func (a *App) ClientCapabilities(ctx context.Context, tc *types.TargetConfig, ex
 	if err != nil {
 		return nil, err
 	}
+	ctx, md := rpcMetadataContext(ctx)
 	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
 	defer cancel()
+	a.saveRequest(tc, saveRPCCapabilities, &gnmi.CapabilityRequest{Extension: ext})
+	start := time.Now()
 	capResponse, err := t.Capabilities(ctx, ext...)
+	a.auditRPC(tc.Name, auditRPCCapabilities, &gnmi.CapabilityRequest{Extension: ext}, capResponse, err, start)
+	a.logRPCMetadata(tc.Name, "Capabilities", md)
 	if err != nil {
-		return nil, fmt.Errorf("%q CapabilitiesRequest failed: %v", t.Config.Address, err)
+		return nil, wrapStatusError("CapabilitiesRequest failed", err)
 	}
 	return capResponse, nil
 
@@ -56,16 +62,29 @@ func (a *App) ClientGet(ctx context.Context, tc *types.TargetConfig, req *gnmi.G
 	if err != nil {
 		return nil, err
 	}
+	ctx, md := rpcMetadataContext(ctx)
 	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
 	defer cancel()
+	a.saveRequest(tc, saveRPCGet, req)
+	start := time.Now()
 	getResponse, err := t.Get(ctx, req)
+	a.auditRPC(tc.Name, auditRPCGet, req, getResponse, err, start)
+	a.logRPCMetadata(tc.Name, "Get", md)
 	if err != nil {
-		return nil, fmt.Errorf("%q GetRequest failed: %v", t.Config.Address, err)
+		return nil, wrapStatusError("GetRequest failed", a.msgSizeError(err))
 	}
+	a.checkRecvMsgSize(tc.Name, "Get", proto.Size(getResponse))
 	return getResponse, nil
 }
 
 func (a *App) ClientSet(ctx context.Context, tc *types.TargetConfig, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	if a.Config.ReadOnly {
+		return nil, types.ErrReadOnly
+	}
+	err := a.checkSendMsgSize("Set", proto.Size(req))
+	if err != nil {
+		return nil, err
+	}
 	a.operLock.Lock()
 	t, err := a.initTarget(tc)
 	a.operLock.Unlock()
@@ -79,11 +98,16 @@ func (a *App) ClientSet(ctx context.Context, tc *types.TargetConfig, req *gnmi.S
 	if err != nil {
 		return nil, err
 	}
+	ctx, md := rpcMetadataContext(ctx)
 	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
 	defer cancel()
+	a.saveRequest(tc, saveRPCSet, req)
+	start := time.Now()
 	setResponse, err := t.Set(ctx, req)
+	a.auditRPC(tc.Name, auditRPCSet, req, setResponse, err, start)
+	a.logRPCMetadata(tc.Name, "Set", md)
 	if err != nil {
-		return nil, fmt.Errorf("target %q SetRequest failed: %v", t.Config.Name, err)
+		return nil, wrapStatusError("SetRequest failed", a.msgSizeError(err))
 	}
 	return setResponse, nil
 }
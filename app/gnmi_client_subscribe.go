@@ -23,6 +23,8 @@ import (
 	"github.com/openconfig/gnmic/types"
 	"github.com/openconfig/grpctunnel/tunnel"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/proto"
 )
 
 type subscriptionRequest struct {
@@ -35,6 +37,7 @@ type subscriptionRequest struct {
 func (a *App) TargetSubscribeStream(ctx context.Context, tc *types.TargetConfig) {
 	lockKey := a.targetLockKey(tc.Name)
 START:
+	a.setTargetState(tc.Name, targetStateInitializing, nil)
 	nctx, cancel := context.WithCancel(ctx)
 	a.operLock.Lock()
 	if cfn, ok := a.targetsLockFn[tc.Name]; ok {
@@ -45,6 +48,7 @@ START:
 	a.operLock.Unlock()
 	if err != nil {
 		a.Logger.Printf("failed to initialize target %q: %v", tc.Name, err)
+		a.setTargetState(tc.Name, targetStateFailed, err)
 		return
 	}
 	select {
@@ -53,6 +57,7 @@ START:
 		return
 	default:
 		if a.locker != nil {
+			a.setTargetState(tc.Name, targetStateLocking, nil)
 			a.Logger.Printf("acquiring lock for target %q", tc.Name)
 			ok, err := a.locker.Lock(nctx, lockKey, []byte(a.Config.Clustering.InstanceName))
 			if err == lockers.ErrCanceled {
@@ -61,6 +66,7 @@ START:
 			}
 			if err != nil {
 				a.Logger.Printf("failed to lock target %q: %v", tc.Name, err)
+				a.targetLastError(tc.Name, fmt.Errorf("failed to lock target: %v", err))
 				time.Sleep(a.Config.LocalFlags.SubscribeLockRetry)
 				goto START
 			}
@@ -173,7 +179,7 @@ func (a *App) clientSubscribe(ctx context.Context, tc *types.TargetConfig) error
 	}
 	subRequests := make([]subscriptionRequest, 0)
 	for _, sc := range subscriptionsConfigs {
-		req, err := a.Config.CreateSubscribeRequest(sc, tc.Name)
+		req, err := a.createSubscribeRequest(sc, tc)
 		if err != nil {
 			return err
 		}
@@ -189,7 +195,7 @@ CRCLIENT:
 	case <-gnmiCtx.Done():
 		return gnmiCtx.Err()
 	default:
-		targetDialOpts := a.dialOpts
+		targetDialOpts := a.targetDialOpts(t)
 		if a.Config.UseTunnelServer {
 			a.ttm.Lock()
 			a.tunTargetCfn[tunnel.Target{ID: tc.Name, Type: tc.TunnelTargetType}] = cancel
@@ -200,25 +206,49 @@ CRCLIENT:
 			// overwrite target address
 			t.Config.Address = t.Config.Name
 		}
-		err := t.CreateGNMIClient(ctx, targetDialOpts...)
+		a.setTargetState(tc.Name, targetStateConnecting, nil)
+		// the target context bounds the dial, canceling the target stops its retries
+		err := t.CreateGNMIClient(gnmiCtx, targetDialOpts...)
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				a.Logger.Printf("failed to initialize target %q timeout (%s) reached", tc.Name, t.Config.Timeout)
 			} else {
 				a.Logger.Printf("failed to initialize target %q: %v", tc.Name, err)
 			}
+			a.setTargetState(tc.Name, targetStateRetrying, err)
 			a.Logger.Printf("retrying target %q in %s", tc.Name, t.Config.RetryTimer)
-			time.Sleep(t.Config.RetryTimer)
+			select {
+			case <-gnmiCtx.Done():
+				a.setTargetState(tc.Name, targetStateStopped, nil)
+				return gnmiCtx.Err()
+			case <-time.After(t.Config.RetryTimer):
+			}
 			goto CRCLIENT
 		}
 	}
 	a.Logger.Printf("target %q gNMI client created", t.Config.Name)
+	if a.Config.LocalFlags.SubscribeLogConnState || a.Config.Debug {
+		go t.WatchConnState(gnmiCtx, func(from, to connectivity.State) {
+			a.Logger.Printf("target %q connection state changed: %s -> %s", t.Config.Name, from, to)
+		})
+	}
 
 	for _, sreq := range subRequests {
 		a.Logger.Printf("sending gNMI SubscribeRequest: subscribe='%+v', mode='%+v', encoding='%+v', to %s",
 			sreq.req, sreq.req.GetSubscribe().GetMode(), sreq.req.GetSubscribe().GetEncoding(), t.Config.Name)
+		a.saveRequest(tc, saveRPCSubscribe, sreq.req)
+		a.auditRPC(tc.Name, auditRPCSubscribe, sreq.req, nil, nil, time.Now())
+		if tc.SplitMixedSubscriptions != nil && *tc.SplitMixedSubscriptions {
+			reqs := target.SplitMixedModes(sreq.req)
+			if len(reqs) > 1 {
+				a.Logger.Printf("target %q: subscription %q mixes subscription modes, split into %d streams", tc.Name, sreq.name, len(reqs))
+			}
+			go t.SubscribeSplit(gnmiCtx, reqs, sreq.name)
+			continue
+		}
 		go t.Subscribe(gnmiCtx, sreq.req, sreq.name)
 	}
+	a.setTargetState(tc.Name, targetStateSubscribed, nil)
 	return nil
 }
 
@@ -241,7 +271,7 @@ func (a *App) clientSubscribeOnce(ctx context.Context, tc *types.TargetConfig) e
 	}
 	subRequests := make([]subscriptionRequest, 0)
 	for _, sc := range subscriptionsConfigs {
-		req, err := a.Config.CreateSubscribeRequest(sc, tc.Name)
+		req, err := a.createSubscribeRequest(sc, tc)
 		if err != nil {
 			return err
 		}
@@ -250,7 +280,7 @@ func (a *App) clientSubscribeOnce(ctx context.Context, tc *types.TargetConfig) e
 	gnmiCtx, cancel := context.WithCancel(ctx)
 	t.Cfn = cancel
 CRCLIENT:
-	targetDialOpts := a.dialOpts
+	targetDialOpts := a.targetDialOpts(t)
 	if a.Config.UseTunnelServer {
 		a.ttm.Lock()
 		a.tunTargetCfn[tunnel.Target{ID: tc.Name, Type: tc.TunnelTargetType}] = cancel
@@ -273,11 +303,20 @@ CRCLIENT:
 
 	}
 	a.Logger.Printf("target %q gNMI client created", t.Config.Name)
+	if a.Config.LocalFlags.SubscribeMerge {
+		return a.subscribeOnceMerge(gnmiCtx, t, subRequests)
+	}
 OUTER:
 	for _, sreq := range subRequests {
+		// set once the subscription fell back to another encoding
+		var encoding string
+	SEND:
 		a.Logger.Printf("sending gNMI SubscribeRequest: subscribe='%+v', mode='%+v', encoding='%+v', to %s",
 			sreq.req, sreq.req.GetSubscribe().GetMode(), sreq.req.GetSubscribe().GetEncoding(), t.Config.Name)
+		a.saveRequest(tc, saveRPCSubscribe, sreq.req)
+		a.auditRPC(tc.Name, auditRPCSubscribe, sreq.req, nil, nil, time.Now())
 		rspCh, errCh := t.SubscribeOnceChan(gnmiCtx, sreq.req)
+		var lastSkewWarning time.Time
 		for {
 			select {
 			case err := <-errCh:
@@ -287,6 +326,17 @@ OUTER:
 					// next subscription or end
 					continue OUTER
 				}
+				if next, ok := target.NextEncoding(tc.EncodingFallback, sreq.req.GetSubscribe().GetEncoding(), err); ok {
+					a.logEncodingFallback(fmt.Sprintf("target %q: subscription %s: encoding %s: %v, falling back to encoding %s",
+						t.Config.Name, sreq.name, target.EncodingName(sreq.req.GetSubscribe().GetEncoding()), err, target.EncodingName(next)))
+					sreq.req = proto.Clone(sreq.req).(*gnmi.SubscribeRequest)
+					sreq.req.GetSubscribe().Encoding = next
+					encoding = target.EncodingName(next)
+					goto SEND
+				}
+				if rErr := a.multiOriginRejectedError(t.Config.Name, sreq.name, err); rErr != nil {
+					return rErr
+				}
 				return err
 			case rsp := <-rspCh:
 				switch rsp.Response.(type) {
@@ -294,7 +344,29 @@ OUTER:
 					a.Logger.Printf("target %q, subscription %q received sync response", t.Config.Name, sreq.name)
 					return nil
 				default:
-					m := outputs.Meta{"source": t.Config.Name, "format": a.Config.Format, "subscription-name": sreq.name}
+					if a.Config.LocalFlags.SubscribeValuesOnly {
+						a.decodeAnyValues(rsp.GetUpdate())
+						err := a.PrintMsg(t.Config.Name, "Subscribe Response:", rsp)
+						if err != nil {
+							a.logTargetError(t.Config.Name, err)
+						}
+						continue
+					}
+					m := outputs.Meta{
+						"source":            t.Config.Name,
+						"format":            a.Config.Format,
+						"subscription-name": sreq.name,
+						"subscription-mode": subscriptionModeONCE,
+					}
+					if a.Config.IncludeMeta {
+						m["security-mode"] = t.Config.SecurityMode()
+					}
+					if encoding != "" {
+						m[target.MetaEncoding] = encoding
+					}
+					a.setReceiveTimestamp(t.Config.Name, rsp, time.Now().UnixNano(), m, &lastSkewWarning)
+					a.decomposeResponse(rsp)
+					a.setSequence(t.Config.Name, rsp, m)
 					a.Export(ctx, rsp, m, t.Config.Outputs...)
 				}
 			}
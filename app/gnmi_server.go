@@ -56,6 +56,9 @@ func (a *App) startGnmiServer() {
 		return
 	}
 
+	if sc, ok := a.c.(cache.StatsCache); ok {
+		a.registerMetrics(newCacheMetrics(sc)...)
+	}
 	a.subscribeRPCsem = semaphore.NewWeighted(a.Config.GnmiServer.MaxSubscriptions)
 	a.unaryRPCsem = semaphore.NewWeighted(a.Config.GnmiServer.MaxUnaryRPC)
 	//
@@ -268,6 +271,9 @@ func (a *App) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse,
 	if _, ok := origins["gnmic"]; ok {
 		return a.handlegNMIcInternalGet(ctx, req)
 	}
+	if a.Config.GnmiServer.GetFromCache && a.c != nil {
+		return a.handleGetFromCache(ctx, req)
+	}
 
 	targetName := req.GetPrefix().GetTarget()
 	pr, _ := peer.FromContext(ctx)
@@ -359,7 +365,50 @@ func (a *App) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse,
 	return response, nil
 }
 
+// handleGetFromCache builds the GetResponse from the values stored in the local cache,
+// with their original timestamps.
+func (a *App) handleGetFromCache(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	targetName := req.GetPrefix().GetTarget()
+	if targetName == "" {
+		targetName = "*"
+	}
+	pr, _ := peer.FromContext(ctx)
+	a.Logger.Printf("received Get request from %q to target %q, reading from cache", pr.Addr, targetName)
+
+	paths := req.GetPath()
+	if len(paths) == 0 {
+		paths = []*gnmi.Path{{}}
+	}
+	response := new(gnmi.GetResponse)
+	for _, p := range paths {
+		fp := &gnmi.Path{
+			Origin: p.GetOrigin(),
+			Elem:   make([]*gnmi.PathElem, 0, len(req.GetPrefix().GetElem())+len(p.GetElem())),
+		}
+		if fp.Origin == "" {
+			fp.Origin = req.GetPrefix().GetOrigin()
+		}
+		fp.Elem = append(fp.Elem, req.GetPrefix().GetElem()...)
+		fp.Elem = append(fp.Elem, p.GetElem()...)
+		rsp, err := a.c.Read("*", targetName, fp)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read from cache: %v", err)
+		}
+		for _, notifications := range rsp {
+			response.Notification = append(response.Notification, notifications...)
+		}
+	}
+	if len(response.Notification) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no cached value found for target %q", targetName)
+	}
+	a.Logger.Printf("sending GetResponse to %q: %+v", pr.Addr, response)
+	return response, nil
+}
+
 func (a *App) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	if a.Config.ReadOnly {
+		return nil, status.Error(codes.FailedPrecondition, types.ErrReadOnly.Error())
+	}
 	ok := a.unaryRPCsem.TryAcquire(1)
 	if !ok {
 		return nil, status.Errorf(codes.ResourceExhausted, "max number of Unary RPC reached")
@@ -0,0 +1,75 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/openconfig/gnmic/target"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+
+	// registers the client side health checking function
+	_ "google.golang.org/grpc/health"
+)
+
+// service config health check, as defined in
+// https://github.com/grpc/proposal/blob/master/A17-client-side-health-checking.md
+type grpcHealthCheckConfig struct {
+	// an empty service name checks the overall health of the backend
+	ServiceName string `json:"serviceName"`
+}
+
+// balancedServiceConfig returns the JSON service config of a target with a dns:/// address:
+// the RPCs are balanced round robin across the resolved backends reported healthy,
+// the backends not implementing the gRPC health service are considered healthy.
+// It includes the retry policy if --grpc-retry is set.
+func (a *App) balancedServiceConfig() (string, error) {
+	sc := &grpcServiceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{roundrobin.Name: {}}},
+		HealthCheckConfig:   &grpcHealthCheckConfig{},
+	}
+	if a.Config.GRPCRetry {
+		mc, err := a.grpcRetryMethodConfig()
+		if err != nil {
+			return "", err
+		}
+		sc.MethodConfig = []*grpcMethodConfig{mc}
+	}
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// balancedDialOpts returns the dial option installing the balanced service config
+// if the target address is a dns:/// address. It overrides the retry service config.
+func (a *App) balancedDialOpts(t *target.Target) []grpc.DialOption {
+	if !t.Config.Balanced() {
+		return nil
+	}
+	sc, err := a.balancedServiceConfig()
+	if err != nil {
+		a.Logger.Printf("target %q: failed to build the gRPC balancing service config: %v", t.Config.Name, err)
+		return nil
+	}
+	a.Logger.Printf("target %q: gRPC balancing service config: %s", t.Config.Name, sc)
+	return []grpc.DialOption{grpc.WithDefaultServiceConfig(sc)}
+}
+
+// logStreamBackend logs the backend serving a subscription stream of a target
+// with a dns:/// address when it changes, for e.g: after a failover.
+func (a *App) logStreamBackend(t *target.Target, rsp *target.SubscribeResponse, backends map[string]string) {
+	if rsp.Backend == "" || backends[rsp.SubscriptionName] == rsp.Backend {
+		return
+	}
+	backends[rsp.SubscriptionName] = rsp.Backend
+	a.Logger.Printf("target %q: subscription %q served by backend %s", t.Config.Name, rsp.SubscriptionName, rsp.Backend)
+}
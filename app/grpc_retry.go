@@ -0,0 +1,210 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	defaultGRPCRetryMaxAttempts    = 3
+	defaultGRPCRetryInitialBackoff = 100 * time.Millisecond
+	defaultGRPCRetryMaxBackoff     = time.Second
+	// gRPC caps the number of attempts to 5
+	maxGRPCRetryAttempts       = 5
+	grpcRetryBackoffMultiplier = 2
+	gnmiServiceName            = "gnmi.gNMI"
+)
+
+var defaultGRPCRetryCodes = []string{"UNAVAILABLE"}
+
+// gRPC service config, as defined in
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type grpcServiceConfig struct {
+	LoadBalancingConfig []map[string]struct{}  `json:"loadBalancingConfig,omitempty"`
+	HealthCheckConfig   *grpcHealthCheckConfig `json:"healthCheckConfig,omitempty"`
+	MethodConfig        []*grpcMethodConfig    `json:"methodConfig,omitempty"`
+}
+
+type grpcMethodConfig struct {
+	Name        []*grpcMethodName `json:"name,omitempty"`
+	RetryPolicy *grpcRetryPolicy  `json:"retryPolicy,omitempty"`
+}
+
+type grpcMethodName struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+type grpcRetryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts,omitempty"`
+	InitialBackoff       string   `json:"initialBackoff,omitempty"`
+	MaxBackoff           string   `json:"maxBackoff,omitempty"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier,omitempty"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes,omitempty"`
+}
+
+// grpcRetryServiceConfig returns the JSON service config enabling the retries
+// of the Capabilities and Get RPCs, and of the Set RPC if --grpc-retry-set is set.
+func (a *App) grpcRetryServiceConfig() (string, error) {
+	mc, err := a.grpcRetryMethodConfig()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(&grpcServiceConfig{MethodConfig: []*grpcMethodConfig{mc}})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// grpcRetryMethodConfig returns the service config methods retry policy.
+func (a *App) grpcRetryMethodConfig() (*grpcMethodConfig, error) {
+	gf := a.Config.GlobalFlags
+	if gf.GRPCRetryMaxAttempts < 2 || gf.GRPCRetryMaxAttempts > maxGRPCRetryAttempts {
+		return nil, fmt.Errorf("--grpc-retry-max-attempts must be between 2 and %d", maxGRPCRetryAttempts)
+	}
+	if gf.GRPCRetryInitialBackoff <= 0 {
+		return nil, fmt.Errorf("--grpc-retry-initial-backoff must be greater than 0")
+	}
+	if gf.GRPCRetryMaxBackoff < gf.GRPCRetryInitialBackoff {
+		return nil, fmt.Errorf("--grpc-retry-max-backoff must be greater than or equal to --grpc-retry-initial-backoff")
+	}
+	if len(gf.GRPCRetryCodes) == 0 {
+		return nil, fmt.Errorf("--grpc-retry-codes cannot be empty")
+	}
+	retryCodes := make([]string, 0, len(gf.GRPCRetryCodes))
+	for _, c := range gf.GRPCRetryCodes {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		var code codes.Code
+		err := code.UnmarshalJSON([]byte(strconv.Quote(c)))
+		if err != nil || code == codes.OK {
+			return nil, fmt.Errorf("unknown retryable gRPC status code %q", c)
+		}
+		retryCodes = append(retryCodes, c)
+	}
+	methods := []string{"Capabilities", "Get"}
+	if gf.GRPCRetrySet {
+		methods = append(methods, "Set")
+	}
+	mc := &grpcMethodConfig{
+		Name: make([]*grpcMethodName, 0, len(methods)),
+		RetryPolicy: &grpcRetryPolicy{
+			MaxAttempts:          gf.GRPCRetryMaxAttempts,
+			InitialBackoff:       serviceConfigDuration(gf.GRPCRetryInitialBackoff),
+			MaxBackoff:           serviceConfigDuration(gf.GRPCRetryMaxBackoff),
+			BackoffMultiplier:    grpcRetryBackoffMultiplier,
+			RetryableStatusCodes: retryCodes,
+		},
+	}
+	for _, m := range methods {
+		mc.Name = append(mc.Name, &grpcMethodName{Service: gnmiServiceName, Method: m})
+	}
+	return mc, nil
+}
+
+// serviceConfigDuration formats d as a service config duration, e.g: 0.1s
+func serviceConfigDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// grpcRetryDialOpts returns the dial options installing the retry service config
+// and logging the retried attempts.
+func (a *App) grpcRetryDialOpts() []grpc.DialOption {
+	sc, err := a.grpcRetryServiceConfig()
+	if err != nil {
+		a.Logger.Printf("failed to build the gRPC retry service config: %v", err)
+		return nil
+	}
+	a.Logger.Printf("gRPC retry service config: %s", sc)
+	return []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(sc),
+		grpc.WithChainUnaryInterceptor(rpcAttemptsInterceptor),
+		grpc.WithStatsHandler(&retryStatsHandler{logger: a.Logger, limiter: a.rpcLimiter}),
+	}
+}
+
+type rpcAttemptsKey struct{}
+
+// rpcAttempts tracks the attempts of a unary RPC.
+type rpcAttempts struct {
+	target string
+	method string
+
+	m       *sync.Mutex
+	count   int
+	lastErr error
+}
+
+// rpcAttemptsInterceptor stores an rpcAttempts in the RPC context,
+// it is shared by all the attempts of the RPC.
+func rpcAttemptsInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx = context.WithValue(ctx, rpcAttemptsKey{}, &rpcAttempts{
+		target: cc.Target(),
+		method: method,
+		m:      new(sync.Mutex),
+	})
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// retryStatsHandler logs the attempts of a unary RPC following a failed one.
+// If a rate limiter is set, each retry waits for it like a new RPC.
+type retryStatsHandler struct {
+	logger  *log.Logger
+	limiter *rate.Limiter
+}
+
+func (h *retryStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *retryStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	ra, ok := ctx.Value(rpcAttemptsKey{}).(*rpcAttempts)
+	if !ok {
+		return
+	}
+	switch s := s.(type) {
+	case *stats.Begin:
+		ra.m.Lock()
+		ra.count++
+		count, lastErr := ra.count, ra.lastErr
+		ra.m.Unlock()
+		if count < 2 {
+			return
+		}
+		h.logger.Printf("target %q: retrying RPC %s, attempt %d, previous attempt error: %v",
+			ra.target, ra.method, count, lastErr)
+		if h.limiter != nil {
+			// the error is returned by the retried attempt itself once ctx is done
+			_ = h.limiter.Wait(ctx)
+		}
+	case *stats.End:
+		ra.m.Lock()
+		ra.lastErr = s.Error
+		ra.m.Unlock()
+	}
+}
+
+func (h *retryStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *retryStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
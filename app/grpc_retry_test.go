@@ -0,0 +1,181 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// flakyServer fails the first failures calls of each unary RPC with codes.Unavailable.
+type flakyServer struct {
+	gnmi.UnimplementedGNMIServer
+	failures int
+
+	m     sync.Mutex
+	calls map[string]int
+}
+
+func (s *flakyServer) call(rpc string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.calls[rpc]++
+	if s.calls[rpc] <= s.failures {
+		return status.Error(codes.Unavailable, "transient failure")
+	}
+	return nil
+}
+
+func (s *flakyServer) Capabilities(context.Context, *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	if err := s.call("Capabilities"); err != nil {
+		return nil, err
+	}
+	return &gnmi.CapabilityResponse{GNMIVersion: "0.8.0"}, nil
+}
+
+func (s *flakyServer) Set(context.Context, *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	if err := s.call("Set"); err != nil {
+		return nil, err
+	}
+	return &gnmi.SetResponse{}, nil
+}
+
+func TestGRPCRetry(t *testing.T) {
+	tests := map[string]struct {
+		rpc       string
+		retrySet  bool
+		wantErr   bool
+		wantCalls int
+	}{
+		"capabilities_retried": {
+			rpc:       "Capabilities",
+			wantCalls: 3,
+		},
+		"set_not_retried": {
+			rpc:       "Set",
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		"set_retried": {
+			rpc:       "Set",
+			retrySet:  true,
+			wantCalls: 3,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &flakyServer{failures: 2, calls: make(map[string]int)}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, srv)
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			logs := new(bytes.Buffer)
+			a := New()
+			a.Logger = log.New(logs, "", 0)
+			a.Config.GRPCRetry = true
+			a.Config.GRPCRetrySet = item.retrySet
+			a.Config.GRPCRetryMaxAttempts = defaultGRPCRetryMaxAttempts
+			a.Config.GRPCRetryInitialBackoff = 10 * time.Millisecond
+			a.Config.GRPCRetryMaxBackoff = 10 * time.Millisecond
+			a.Config.GRPCRetryCodes = defaultGRPCRetryCodes
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			opts := append(a.createCollectorDialOpts(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			conn, err := grpc.DialContext(ctx, l.Addr().String(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			client := gnmi.NewGNMIClient(conn)
+			switch item.rpc {
+			case "Capabilities":
+				_, err = client.Capabilities(ctx, new(gnmi.CapabilityRequest))
+			case "Set":
+				_, err = client.Set(ctx, new(gnmi.SetRequest))
+			}
+			if item.wantErr != (err != nil) {
+				t.Errorf("failed at item %q: unexpected error: %v", name, err)
+			}
+			if srv.calls[item.rpc] != item.wantCalls {
+				t.Errorf("failed at item %q: expected %d call(s), got %d", name, item.wantCalls, srv.calls[item.rpc])
+			}
+			retried := strings.Contains(logs.String(), "retrying RPC /gnmi.gNMI/"+item.rpc)
+			if retried != (item.wantCalls > 1) {
+				t.Errorf("failed at item %q: unexpected retry logs: %s", name, logs.String())
+			}
+		})
+	}
+}
+
+func TestGRPCRetryServiceConfig(t *testing.T) {
+	a := New()
+	a.Config.GRPCRetryMaxAttempts = 4
+	a.Config.GRPCRetryInitialBackoff = 100 * time.Millisecond
+	a.Config.GRPCRetryMaxBackoff = 2 * time.Second
+	a.Config.GRPCRetryCodes = []string{"unavailable", "RESOURCE_EXHAUSTED"}
+	sc, err := a.grpcRetryServiceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"methodConfig":[{"name":[{"service":"gnmi.gNMI","method":"Capabilities"},{"service":"gnmi.gNMI","method":"Get"}],` +
+		`"retryPolicy":{"maxAttempts":4,"initialBackoff":"0.1s","maxBackoff":"2s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE","RESOURCE_EXHAUSTED"]}}]}`
+	if sc != want {
+		t.Errorf("unexpected service config:\nexpected: %s\n     got: %s", want, sc)
+	}
+	a.Config.GRPCRetryCodes = []string{"NOT_A_CODE"}
+	if _, err = a.grpcRetryServiceConfig(); err == nil {
+		t.Errorf("expected an error for an unknown status code")
+	}
+}
+
+func TestBalancedServiceConfig(t *testing.T) {
+	a := New()
+	sc, err := a.balancedServiceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+	if sc != want {
+		t.Errorf("unexpected service config:\nexpected: %s\n     got: %s", want, sc)
+	}
+	// the retry policy is kept
+	a.Config.GRPCRetry = true
+	a.Config.GRPCRetryMaxAttempts = 3
+	a.Config.GRPCRetryInitialBackoff = time.Second
+	a.Config.GRPCRetryMaxBackoff = time.Second
+	a.Config.GRPCRetryCodes = []string{"UNAVAILABLE"}
+	sc, err = a.balancedServiceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""},` +
+		`"methodConfig":[{"name":[{"service":"gnmi.gNMI","method":"Capabilities"},{"service":"gnmi.gNMI","method":"Get"}],` +
+		`"retryPolicy":{"maxAttempts":3,"initialBackoff":"1s","maxBackoff":"1s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE"]}}]}`
+	if sc != want {
+		t.Errorf("unexpected service config:\nexpected: %s\n     got: %s", want, sc)
+	}
+}
@@ -0,0 +1,367 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	lastRequestDirName  = ".gnmic"
+	lastRequestDirPerm  = 0700
+	lastRequestFilePerm = 0600
+
+	lastRequestGet       = "get"
+	lastRequestSet       = "set"
+	lastRequestSubscribe = "subscribe"
+)
+
+// lastRequest is the content of the ~/.gnmic/last-<rpc>.json files.
+// It only holds the gNMI request messages, the targets addresses
+// and credentials are never written.
+type lastRequest struct {
+	Command  string              `json:"command,omitempty"`
+	Time     time.Time           `json:"time,omitempty"`
+	Requests []*lastRequestEntry `json:"requests,omitempty"`
+}
+
+type lastRequestEntry struct {
+	// subscription name
+	Name    string          `json:"name,omitempty"`
+	Request json.RawMessage `json:"request,omitempty"`
+}
+
+func lastRequestFile(rpc string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, lastRequestDirName, fmt.Sprintf("last-%s.json", rpc)), nil
+}
+
+// saveLastRequest writes the requests reqs of command rpc to ~/.gnmic/last-<rpc>.json,
+// names are the optional subscriptions names. A failure is logged without failing the command.
+func (a *App) saveLastRequest(rpc string, names []string, reqs ...proto.Message) {
+	err := writeLastRequest(rpc, names, reqs...)
+	if err != nil {
+		a.Logger.Printf("failed to save the last %s request: %v", rpc, err)
+	}
+}
+
+func writeLastRequest(rpc string, names []string, reqs ...proto.Message) error {
+	lr := &lastRequest{
+		Command:  rpc,
+		Time:     time.Now(),
+		Requests: make([]*lastRequestEntry, 0, len(reqs)),
+	}
+	for i, req := range reqs {
+		b, err := protojson.Marshal(req)
+		if err != nil {
+			return err
+		}
+		e := &lastRequestEntry{Request: b}
+		if i < len(names) {
+			e.Name = names[i]
+		}
+		lr.Requests = append(lr.Requests, e)
+	}
+	b, err := json.MarshalIndent(lr, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := lastRequestFile(rpc)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(f), lastRequestDirPerm)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(f, append(b, '\n'), lastRequestFilePerm)
+	if err != nil {
+		return err
+	}
+	// os.WriteFile keeps the mode of an existing file
+	return os.Chmod(f, lastRequestFilePerm)
+}
+
+// readLastRequest reads the requests of command rpc from ~/.gnmic/last-<rpc>.json,
+// newMsg returns the message the requests are decoded into.
+func readLastRequest(rpc string, newMsg func() proto.Message) ([]string, []proto.Message, error) {
+	f, err := lastRequestFile(rpc)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := os.ReadFile(f)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("no saved %s request found in %s, run the command once without --again", rpc, f)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	lr := new(lastRequest)
+	err = json.Unmarshal(b, lr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s: %v", f, err)
+	}
+	if lr.Command != rpc || len(lr.Requests) == 0 {
+		return nil, nil, fmt.Errorf("%s does not hold a %s request", f, rpc)
+	}
+	names := make([]string, 0, len(lr.Requests))
+	msgs := make([]proto.Message, 0, len(lr.Requests))
+	for i, e := range lr.Requests {
+		m := newMsg()
+		err = protojson.Unmarshal(e.Request, m)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode request %d in %s: %v", i, f, err)
+		}
+		names = append(names, e.Name)
+		msgs = append(msgs, m)
+	}
+	return names, msgs, nil
+}
+
+// mergePrefix returns the saved prefix with its path and target
+// replaced by the --prefix and --target flags values, if they are set.
+func mergePrefix(cmd *cobra.Command, saved *gnmi.Path, prefix, target string) (*gnmi.Path, error) {
+	prefixSet := cmd.Flags().Changed("prefix")
+	targetSet := cmd.Flags().Changed("target")
+	if !prefixSet && !targetSet {
+		return saved, nil
+	}
+	fp, err := utils.CreatePrefix(prefix, target)
+	if err != nil {
+		return nil, err
+	}
+	p := new(gnmi.Path)
+	if saved != nil {
+		p = proto.Clone(saved).(*gnmi.Path)
+	}
+	if prefixSet {
+		p.Origin, p.Elem = fp.GetOrigin(), fp.GetElem()
+	}
+	if targetSet {
+		p.Target = fp.GetTarget()
+	}
+	if p.Origin == "" && len(p.Elem) == 0 && p.Target == "" {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// againGetRequest returns the saved get request merged with the request flags set on the command line,
+// the --path values replace the saved paths or are appended to them with --append.
+func (a *App) againGetRequest(cmd *cobra.Command) (*gnmi.GetRequest, error) {
+	_, msgs, err := readLastRequest(lastRequestGet, func() proto.Message { return new(gnmi.GetRequest) })
+	if err != nil {
+		return nil, err
+	}
+	req := msgs[0].(*gnmi.GetRequest)
+	freq, err := a.Config.CreateGetRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(freq.GetPath()) > 0 {
+		if a.Config.LocalFlags.GetAppend {
+			req.Path = append(req.Path, freq.GetPath()...)
+		} else {
+			req.Path = freq.GetPath()
+		}
+	}
+	if cmd.Flags().Changed("type") {
+		req.Type = freq.GetType()
+	}
+	req.Prefix, err = mergePrefix(cmd, req.GetPrefix(), a.Config.LocalFlags.GetPrefix, a.Config.LocalFlags.GetTarget)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// againSetRequests returns, per target, the saved set requests merged with the request flags set on the command line,
+// the operations built from the flags replace the saved operations of the same kind or are appended to them with --append.
+func (a *App) againSetRequests(cmd *cobra.Command, targets []*types.TargetConfig) (map[string][]*gnmi.SetRequest, error) {
+	_, msgs, err := readLastRequest(lastRequestSet, func() proto.Message { return new(gnmi.SetRequest) })
+	if err != nil {
+		return nil, err
+	}
+	saved := make([]*gnmi.SetRequest, 0, len(msgs))
+	for _, m := range msgs {
+		req := m.(*gnmi.SetRequest)
+		req.Prefix, err = mergePrefix(cmd, req.GetPrefix(), a.Config.LocalFlags.SetPrefix, a.Config.LocalFlags.SetTarget)
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, req)
+	}
+	targetsReqs := make(map[string][]*gnmi.SetRequest, len(targets))
+	if !a.Config.HasSetInput() {
+		for _, tc := range targets {
+			reqs := make([]*gnmi.SetRequest, 0, len(saved))
+			for _, req := range saved {
+				reqs = append(reqs, proto.Clone(req).(*gnmi.SetRequest))
+			}
+			targetsReqs[tc.Name] = reqs
+		}
+		return targetsReqs, nil
+	}
+	if len(saved) != 1 {
+		return nil, fmt.Errorf("the saved set has %d requests, set flags can only be merged into a single request", len(saved))
+	}
+	flagReqs, err := a.createSetRequests(targets)
+	if err != nil {
+		return nil, err
+	}
+	for _, tc := range targets {
+		if len(flagReqs[tc.Name]) != 1 {
+			return nil, fmt.Errorf("the set flags built %d requests, only a single request can be merged into the saved one", len(flagReqs[tc.Name]))
+		}
+		req := proto.Clone(saved[0]).(*gnmi.SetRequest)
+		mergeSetOperations(req, flagReqs[tc.Name][0], a.Config.LocalFlags.SetAppend)
+		targetsReqs[tc.Name] = []*gnmi.SetRequest{req}
+	}
+	return targetsReqs, nil
+}
+
+// mergeSetOperations merges the operations of the flags set request freq into req.
+func mergeSetOperations(req, freq *gnmi.SetRequest, appendOps bool) {
+	if appendOps {
+		req.Delete = append(req.Delete, freq.GetDelete()...)
+		req.Replace = append(req.Replace, freq.GetReplace()...)
+		req.Update = append(req.Update, freq.GetUpdate()...)
+		return
+	}
+	if len(freq.GetDelete()) > 0 {
+		req.Delete = freq.GetDelete()
+	}
+	if len(freq.GetReplace()) > 0 {
+		req.Replace = freq.GetReplace()
+	}
+	if len(freq.GetUpdate()) > 0 {
+		req.Update = freq.GetUpdate()
+	}
+}
+
+// againSubscriptions loads the saved subscribe requests, merged with the --path subscriptions and the
+// --prefix and --target flags, and returns the subscriptions configs matching them.
+// The requests are sent as is to all the targets instead of being built from the configs.
+func (a *App) againSubscriptions(cmd *cobra.Command) (map[string]*types.SubscriptionConfig, error) {
+	names, msgs, err := readLastRequest(lastRequestSubscribe, func() proto.Message { return new(gnmi.SubscribeRequest) })
+	if err != nil {
+		return nil, err
+	}
+	var fsubs []*gnmi.Subscription
+	if len(a.Config.LocalFlags.SubscribePath) > 0 {
+		subs, err := a.Config.GetSubscriptions(cmd)
+		if err != nil {
+			return nil, err
+		}
+		for _, sc := range subs {
+			freq, err := a.Config.CreateSubscribeRequest(sc, "")
+			if err != nil {
+				return nil, err
+			}
+			fsubs = append(fsubs, freq.GetSubscribe().GetSubscription()...)
+		}
+	}
+	subCfg := make(map[string]*types.SubscriptionConfig, len(msgs))
+	a.againSubscribeRequests = make(map[string]*gnmi.SubscribeRequest, len(msgs))
+	for i, m := range msgs {
+		req := m.(*gnmi.SubscribeRequest)
+		sl := req.GetSubscribe()
+		if sl == nil || names[i] == "" {
+			return nil, fmt.Errorf("saved subscribe request %d is not a named subscription list", i)
+		}
+		if len(fsubs) > 0 {
+			if a.Config.LocalFlags.SubscribeAppend {
+				sl.Subscription = append(sl.Subscription, fsubs...)
+			} else {
+				sl.Subscription = fsubs
+			}
+		}
+		sl.Prefix, err = mergePrefix(cmd, sl.GetPrefix(), a.Config.LocalFlags.SubscribePrefix, a.Config.LocalFlags.SubscribeTarget)
+		if err != nil {
+			return nil, err
+		}
+		sc := &types.SubscriptionConfig{
+			Name:     names[i],
+			Mode:     strings.ToLower(sl.GetMode().String()),
+			Encoding: strings.ToLower(sl.GetEncoding().String()),
+			Paths:    make([]string, 0, len(sl.GetSubscription())),
+		}
+		for _, sub := range sl.GetSubscription() {
+			sc.Paths = append(sc.Paths, utils.GnmiPathToXPath(sub.GetPath(), false))
+		}
+		if sl.GetMode() == gnmi.SubscriptionList_STREAM && len(sl.GetSubscription()) > 0 {
+			sc.StreamMode = strings.ToLower(strings.ReplaceAll(sl.GetSubscription()[0].GetMode().String(), "_", "-"))
+		}
+		subCfg[sc.Name] = sc
+		a.againSubscribeRequests[sc.Name] = req
+	}
+	a.Config.Subscriptions = subCfg
+	return subCfg, nil
+}
+
+// getSubscriptions returns the subscriptions configs from the flags and the config file,
+// or from the last subscribe requests with --again, and saves their requests.
+func (a *App) getSubscriptions(cmd *cobra.Command) (map[string]*types.SubscriptionConfig, error) {
+	var subCfg map[string]*types.SubscriptionConfig
+	var err error
+	a.againSubscribeRequests = nil
+	if a.Config.LocalFlags.SubscribeAgain {
+		subCfg, err = a.againSubscriptions(cmd)
+	} else {
+		subCfg, err = a.Config.GetSubscriptions(cmd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(subCfg))
+	for n := range subCfg {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	reqs := make([]proto.Message, 0, len(subCfg))
+	for _, n := range names {
+		req, err := a.createSubscribeRequest(subCfg[n], nil)
+		if err != nil {
+			// reported when the request is created for a target
+			return subCfg, nil
+		}
+		reqs = append(reqs, req)
+	}
+	if len(reqs) > 0 {
+		a.saveLastRequest(lastRequestSubscribe, names, reqs...)
+	}
+	return subCfg, nil
+}
+
+// createSubscribeRequest creates the subscribe request of subscription sc sent to target tc,
+// the requests loaded with --again are sent unchanged.
+func (a *App) createSubscribeRequest(sc *types.SubscriptionConfig, tc *types.TargetConfig) (*gnmi.SubscribeRequest, error) {
+	if req, ok := a.againSubscribeRequests[sc.Name]; ok {
+		return proto.Clone(req).(*gnmi.SubscribeRequest), nil
+	}
+	return a.Config.CreateSubscribeRequest(sc, tc.Name)
+}
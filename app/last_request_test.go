@@ -0,0 +1,180 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+func setTestHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	return home
+}
+
+func TestSaveLastRequestMode(t *testing.T) {
+	home := setTestHome(t)
+	f := filepath.Join(home, lastRequestDirName, "last-get.json")
+	err := os.MkdirAll(filepath.Dir(f), lastRequestDirPerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an existing file is made private
+	err = os.WriteFile(f, []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeLastRequest(lastRequestGet, nil, &gnmi.GetRequest{Path: []*gnmi.Path{mustParsePath(t, "/system/name")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != lastRequestFilePerm {
+		t.Errorf("expected file mode %o, got %o", lastRequestFilePerm, fi.Mode().Perm())
+	}
+}
+
+func TestAgainGetRequest(t *testing.T) {
+	saved := &gnmi.GetRequest{
+		Prefix: &gnmi.Path{Target: "t1", Elem: []*gnmi.PathElem{{Name: "interfaces"}}},
+		Path:   []*gnmi.Path{mustParsePath(t, "/interface[name=e1]/state")},
+		Type:   gnmi.GetRequest_STATE,
+	}
+	tests := map[string]struct {
+		args []string
+		want *gnmi.GetRequest
+	}{
+		"saved": {
+			want: saved,
+		},
+		"replace_path": {
+			args: []string{"--path", "/interface[name=e2]/config"},
+			want: &gnmi.GetRequest{
+				Prefix: saved.Prefix,
+				Path:   []*gnmi.Path{mustParsePath(t, "/interface[name=e2]/config")},
+				Type:   gnmi.GetRequest_STATE,
+			},
+		},
+		"append_path": {
+			args: []string{"--path", "/interface[name=e2]/config", "--append"},
+			want: &gnmi.GetRequest{
+				Prefix: saved.Prefix,
+				Path: []*gnmi.Path{
+					mustParsePath(t, "/interface[name=e1]/state"),
+					mustParsePath(t, "/interface[name=e2]/config"),
+				},
+				Type: gnmi.GetRequest_STATE,
+			},
+		},
+		"prefix_and_type": {
+			args: []string{"--prefix", "/network-instances", "--type", "config"},
+			want: &gnmi.GetRequest{
+				Prefix: &gnmi.Path{Target: "t1", Elem: []*gnmi.PathElem{{Name: "network-instances"}}},
+				Path:   saved.Path,
+				Type:   gnmi.GetRequest_CONFIG,
+			},
+		},
+		"target": {
+			args: []string{"--target", "t2"},
+			want: &gnmi.GetRequest{
+				Prefix: &gnmi.Path{Target: "t2", Elem: []*gnmi.PathElem{{Name: "interfaces"}}},
+				Path:   saved.Path,
+				Type:   gnmi.GetRequest_STATE,
+			},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			setTestHome(t)
+			err := writeLastRequest(lastRequestGet, nil, saved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := New()
+			a.Config.Encoding = "json"
+			cmd := &cobra.Command{Use: "get"}
+			a.InitGetFlags(cmd)
+			err = cmd.ParseFlags(append([]string{"--again"}, item.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := a.againGetRequest(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the encoding is set per target when the request is sent
+			got.Encoding = 0
+			if !proto.Equal(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.want)
+				t.Logf("     got: %v", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestAgainGetRequestMissing(t *testing.T) {
+	setTestHome(t)
+	a := New()
+	cmd := &cobra.Command{Use: "get"}
+	a.InitGetFlags(cmd)
+	_, err := a.againGetRequest(cmd)
+	if err == nil {
+		t.Fatal("expected an error without a saved request")
+	}
+}
+
+func TestMergeSetOperations(t *testing.T) {
+	upd := func(p string) *gnmi.Update {
+		return &gnmi.Update{Path: mustParsePath(t, p), Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "v"}}}
+	}
+	tests := map[string]struct {
+		freq      *gnmi.SetRequest
+		appendOps bool
+		want      *gnmi.SetRequest
+	}{
+		"replace_updates": {
+			freq: &gnmi.SetRequest{Update: []*gnmi.Update{upd("/b")}},
+			want: &gnmi.SetRequest{Delete: []*gnmi.Path{mustParsePath(t, "/d")}, Update: []*gnmi.Update{upd("/b")}},
+		},
+		"append": {
+			freq:      &gnmi.SetRequest{Update: []*gnmi.Update{upd("/b")}, Delete: []*gnmi.Path{mustParsePath(t, "/e")}},
+			appendOps: true,
+			want: &gnmi.SetRequest{
+				Delete: []*gnmi.Path{mustParsePath(t, "/d"), mustParsePath(t, "/e")},
+				Update: []*gnmi.Update{upd("/a"), upd("/b")},
+			},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &gnmi.SetRequest{Delete: []*gnmi.Path{mustParsePath(t, "/d")}, Update: []*gnmi.Update{upd("/a")}}
+			mergeSetOperations(req, item.freq, item.appendOps)
+			if !proto.Equal(req, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.want)
+				t.Logf("     got: %v", req)
+				t.Fail()
+			}
+		})
+	}
+}
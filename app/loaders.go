@@ -66,6 +66,13 @@ START:
 				a.Logger.Printf("failed parsing new target configuration %#v: %v", add, err)
 				continue
 			}
+			// a discovered target can be a configured one under another name
+			a.configLock.Lock()
+			merged := a.Config.MergeDuplicateTarget(add)
+			a.configLock.Unlock()
+			if merged {
+				continue
+			}
 			// not clustered, add target and subscribe
 			if !a.inCluster() {
 				a.Config.Targets[add.Name] = add
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 )
 
 func (a *App) logError(err error) {
@@ -28,6 +29,15 @@ func (a *App) logError(err error) {
 	a.errCh <- err
 }
 
+// logWarning logs msg and prints it to stderr if logging is not enabled,
+// unlike logError it does not fail the command.
+func (a *App) logWarning(msg string) {
+	a.Logger.Printf("warning: %s", msg)
+	if !a.Config.Log {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+	}
+}
+
 func (a *App) checkErrors() error {
 	if a.errCh == nil {
 		return nil
@@ -45,5 +55,16 @@ func (a *App) checkErrors() error {
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}
-	return errors.New("one or more requests failed")
+	if a.Config.Format == formatJSON {
+		a.printRPCErrorsSummary(errs)
+	}
+	var skippedHint string
+	if skipped := a.skippedTargets(); len(skipped) > 0 {
+		skippedHint = fmt.Sprintf(", %d target(s) skipped: %s", len(skipped), strings.Join(skipped, ", "))
+	}
+	if sources := timedOutSources(errs); len(sources) > 0 {
+		return fmt.Errorf("one or more requests failed, %s, %d target(s) timed out: %s%s",
+			a.totalTimeoutHint(), len(sources), strings.Join(sources, ", "), skippedHint)
+	}
+	return errors.New("one or more requests failed" + skippedHint)
 }
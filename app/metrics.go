@@ -10,14 +10,21 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/openconfig/gnmic/cache"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
 	clusterMetricsUpdatePeriod = 10 * time.Second
+	targetMetricsUpdatePeriod  = 10 * time.Second
+	metricsServerReadTimeout   = 5 * time.Second
 )
 
 // subscribe
@@ -28,6 +35,57 @@ var subscribeResponseReceivedCounter = prometheus.NewCounterVec(prometheus.Count
 	Help:      "Total number of received subscribe response messages",
 }, []string{"source", "subscription"})
 
+var subscribeResponseDroppedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "subscribe",
+	Name:      "number_of_dropped_subscribe_response_messages_total",
+	Help:      "Total number of received subscribe response messages dropped before being exported",
+}, []string{"source", "subscription"})
+
+var subscribeReconnectsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "subscribe",
+	Name:      "number_of_reconnects_total",
+	Help:      "Total number of subscriptions re-established after a failure",
+}, []string{"source", "subscription"})
+
+var subscribeStaleCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "subscribe",
+	Name:      "number_of_stale_detections_total",
+	Help:      "Total number of times a target was found stale, without any response for the stale timeout",
+}, []string{"source"})
+
+var subscribeDownsampledCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "subscribe",
+	Name:      "number_of_downsampled_updates_total",
+	Help:      "Total number of updates dropped by the subscribe downsampling",
+}, []string{"source", "subscription"})
+
+var subscribeActiveSubscriptions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "subscribe",
+	Name:      "number_of_active_subscriptions",
+	Help:      "Number of active subscriptions per target",
+}, []string{"source"})
+
+// output
+var outputWriteErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "write_errors_total",
+	Help:      "Total number of failed writes reported by the output",
+}, []string{"output"})
+
+// target
+var targetConnectionState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "connection_state",
+	Help:      "gRPC connection state to the target, 0: UNKNOWN, 1: IDLE, 2: CONNECTING, 3: READY, 4: TRANSIENT_FAILURE, 5: SHUTDOWN",
+}, []string{"source"})
+
 // cluster
 var clusterNumberOfLockedTargets = prometheus.NewGauge(prometheus.GaugeOpts{
 	Namespace: "gnmic",
@@ -42,6 +100,24 @@ var clusterIsLeader = prometheus.NewGauge(prometheus.GaugeOpts{
 	Help:      "Has value 1 if this gnmic instance is the cluster leader, 0 otherwise",
 })
 
+// cache
+func newCacheMetrics(sc cache.StatsCache) []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "gnmic",
+			Subsystem: "cache",
+			Name:      "number_of_entries",
+			Help:      "Number of values stored in the local cache",
+		}, func() float64 { return float64(sc.Stats().Entries) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "gnmic",
+			Subsystem: "cache",
+			Name:      "number_of_evictions_total",
+			Help:      "Total number of values evicted from the local cache",
+		}, func() float64 { return float64(sc.Stats().Evictions) }),
+	}
+}
+
 func (a *App) startClusterMetrics() {
 	if a.Config.APIServer == nil || !a.Config.APIServer.EnableMetrics || a.Config.Clustering == nil {
 		return
@@ -92,3 +168,99 @@ func (a *App) startClusterMetrics() {
 		}
 	}
 }
+
+// startMetricsServer starts an HTTP server exposing the registered
+// metrics under /metrics if the flag --metrics-address is set.
+// The server is shut down when the app context is done.
+func (a *App) startMetricsServer() {
+	if a.Config.LocalFlags.SubscribeMetricsAddress == "" {
+		return
+	}
+	a.registerMetrics(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		subscribeResponseReceivedCounter,
+		subscribeResponseDroppedCounter,
+		subscribeReconnectsCounter,
+		subscribeStaleCounter,
+		subscribeDownsampledCounter,
+		subscribeActiveSubscriptions,
+		targetConnectionState,
+		&wireStatsCollector{a: a},
+		outputQueueWrittenCounter,
+		outputQueueDroppedCounter,
+		outputQueueLength,
+		outputWriteErrorsCounter,
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(a.reg, promhttp.HandlerOpts{}))
+	s := &http.Server{
+		Addr:        a.Config.LocalFlags.SubscribeMetricsAddress,
+		Handler:     mux,
+		ReadTimeout: metricsServerReadTimeout,
+	}
+	go func() {
+		a.Logger.Printf("starting metrics server on %q", s.Addr)
+		err := s.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Printf("metrics server err: %v", err)
+		}
+	}()
+	go func() {
+		<-a.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), metricsServerReadTimeout)
+		defer cancel()
+		s.Shutdown(ctx)
+	}()
+	go a.startTargetsMetrics()
+}
+
+// registerMetrics registers the given collectors,
+// ignoring the ones already registered by the API server.
+func (a *App) registerMetrics(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		err := a.reg.Register(c)
+		if err == nil {
+			continue
+		}
+		if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			continue
+		}
+		a.Logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (a *App) startTargetsMetrics() {
+	ticker := time.NewTicker(targetMetricsUpdatePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.operLock.RLock()
+			for name, t := range a.Targets {
+				subscribeActiveSubscriptions.WithLabelValues(name).Set(float64(t.NumberOfActiveSubscriptions()))
+				targetConnectionState.WithLabelValues(name).Set(connStateValue(t.ConnState()))
+			}
+			a.operLock.RUnlock()
+		}
+	}
+}
+
+func connStateValue(s string) float64 {
+	switch s {
+	case "IDLE":
+		return 1
+	case "CONNECTING":
+		return 2
+	case "READY":
+		return 3
+	case "TRANSIENT_FAILURE":
+		return 4
+	case "SHUTDOWN":
+		return 5
+	default:
+		return 0
+	}
+}
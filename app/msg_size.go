@@ -0,0 +1,68 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// gRPC default max received message size, used when --max-msg-size is not set
+	grpcDefaultMaxRecvMsgSize = 4 * 1024 * 1024
+	// a warning is logged when a received message size exceeds
+	// msgSizeWarningPercent of the max message size.
+	msgSizeWarningPercent = 80
+)
+
+// maxRecvMsgSize returns the max size of a message received from a target.
+func (a *App) maxRecvMsgSize() int {
+	if a.Config.MaxMsgSize > 0 {
+		return a.Config.MaxMsgSize
+	}
+	return grpcDefaultMaxRecvMsgSize
+}
+
+// checkRecvMsgSize logs a warning if the size of a message received from
+// target source is close to the max message size.
+func (a *App) checkRecvMsgSize(source, rpc string, size int) {
+	limit := a.maxRecvMsgSize()
+	if size*100 < limit*msgSizeWarningPercent {
+		return
+	}
+	a.logWarning(fmt.Sprintf("target %q: %s response size (%d bytes) is over %d%% of the max message size (%d bytes), raise it with --max-msg-size",
+		source, rpc, size, msgSizeWarningPercent, limit))
+}
+
+// checkSendMsgSize returns an error if a message to be sent to a target
+// is larger than the max message size.
+func (a *App) checkSendMsgSize(rpc string, size int) error {
+	if a.Config.MaxMsgSize <= 0 || size <= a.Config.MaxMsgSize {
+		return nil
+	}
+	return fmt.Errorf("%s request size (%d bytes) exceeds the max message size (%d bytes), raise it with --max-msg-size",
+		rpc, size, a.Config.MaxMsgSize)
+}
+
+// msgSizeError adds the max message size and the flag to raise it
+// to the ResourceExhausted errors returned by gRPC for a too large message.
+// The gRPC status code of err is kept.
+func (a *App) msgSizeError(err error) error {
+	if err == nil || !strings.Contains(err.Error(), "larger than max") {
+		return err
+	}
+	hint := fmt.Sprintf("the message exceeds the max message size (%d bytes), raise it with --max-msg-size", a.maxRecvMsgSize())
+	if st, ok := status.FromError(err); ok && st.Code() == codes.ResourceExhausted {
+		return status.Errorf(codes.ResourceExhausted, "%s: %s", st.Message(), hint)
+	}
+	return fmt.Errorf("%v: %s", err, hint)
+}
@@ -0,0 +1,120 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// sizeServer is a gNMI server replying to Get requests with a value of valueSize bytes
+// and counting the received Set requests.
+type sizeServer struct {
+	gnmi.UnimplementedGNMIServer
+	valueSize int64
+	numSet    int64
+}
+
+func (s *sizeServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{
+			{
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "data"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: strings.Repeat("a", int(atomic.LoadInt64(&s.valueSize)))}},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *sizeServer) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	atomic.AddInt64(&s.numSet, 1)
+	return &gnmi.SetResponse{}, nil
+}
+
+func TestMsgSize(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := new(sizeServer)
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	logs := new(bytes.Buffer)
+	a := New()
+	a.Logger = log.New(logs, "", 0)
+	a.Config.Log = true
+	a.Config.MaxMsgSize = 64 * 1024
+	a.createCollectorDialOpts()
+	insecure := true
+	tc := &types.TargetConfig{
+		Name:     "t1",
+		Address:  l.Addr().String(),
+		Insecure: &insecure,
+		Timeout:  5 * time.Second,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// response close to the limit
+	atomic.StoreInt64(&srv.valueSize, 60*1024)
+	_, err = a.ClientGet(ctx, tc, &gnmi.GetRequest{})
+	if err != nil {
+		t.Fatalf("unexpected get error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "warning: target \"t1\": Get response size") {
+		t.Errorf("expected a message size warning, got logs: %s", logs.String())
+	}
+
+	// response over the limit
+	atomic.StoreInt64(&srv.valueSize, 100*1024)
+	_, err = a.ClientGet(ctx, tc, &gnmi.GetRequest{})
+	if err == nil {
+		t.Fatal("expected the get request to fail")
+	}
+	if !strings.Contains(err.Error(), "--max-msg-size") || !strings.Contains(err.Error(), "65536 bytes") {
+		t.Errorf("expected an actionable error, got: %v", err)
+	}
+
+	// request over the limit
+	req := &gnmi.SetRequest{
+		Update: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "data"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: strings.Repeat("a", 100*1024)}},
+			},
+		},
+	}
+	_, err = a.ClientSet(ctx, tc, req)
+	if err == nil {
+		t.Fatal("expected the set request to fail")
+	}
+	if !strings.Contains(err.Error(), "Set request size") || !strings.Contains(err.Error(), "--max-msg-size") {
+		t.Errorf("unexpected set error: %v", err)
+	}
+	if n := atomic.LoadInt64(&srv.numSet); n != 0 {
+		t.Errorf("expected the set request not to be sent, the server received %d", n)
+	}
+}
@@ -0,0 +1,242 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	queueDropNewest = "drop-newest"
+	queueDropOldest = "drop-oldest"
+	// defaultOutputQueueSize is the queue size of the outputs
+	// without a queue-size.
+	defaultOutputQueueSize = 1000
+	// outputQueueDrainTimeout bounds the time spent writing
+	// the queued messages to an output on shutdown.
+	outputQueueDrainTimeout = 5 * time.Second
+)
+
+var outputQueueWrittenCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "number_of_queued_messages_written_total",
+	Help:      "Total number of messages taken from the output queue and written to the output",
+}, []string{"output"})
+
+var outputQueueDroppedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "number_of_queued_messages_dropped_total",
+	Help:      "Total number of messages dropped because the output queue is full",
+}, []string{"output"})
+
+var outputQueueLength = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "queue_length",
+	Help:      "Number of messages waiting in the output queue",
+}, []string{"output"})
+
+// outputQueueConfig holds the queue settings common to all output types.
+type outputQueueConfig struct {
+	QueueSize       int    `mapstructure:"queue-size,omitempty"`
+	QueueDropPolicy string `mapstructure:"queue-drop-policy,omitempty"`
+}
+
+// outputStats are the messages counters of an output,
+// printed with --stats.
+type outputStats struct {
+	written uint64
+	dropped uint64
+	errors  uint64
+}
+
+type queuedMsg struct {
+	rsp  *gnmi.SubscribeResponse
+	meta outputs.Meta
+}
+
+// outputQueue decouples an output from the other outputs:
+// messages are written to the output by a dedicated goroutine,
+// and dropped according to the drop policy when the queue is full.
+type outputQueue struct {
+	name       string
+	out        outputs.Output
+	ch         chan *queuedMsg
+	dropOldest bool
+	stats      *outputStats
+	cfn        context.CancelFunc
+	done       chan struct{}
+}
+
+// newOutputQueue returns an output queue of defaultOutputQueueSize messages
+// if the output config does not set a queue-size,
+// it returns nil if the queue is disabled with a queue-size of 0.
+func newOutputQueue(name string, out outputs.Output, stats *outputStats, cfg map[string]interface{}) (*outputQueue, error) {
+	qcfg := &outputQueueConfig{QueueSize: defaultOutputQueueSize}
+	err := outputs.DecodeConfig(cfg, qcfg)
+	if err != nil {
+		return nil, err
+	}
+	if qcfg.QueueSize <= 0 {
+		return nil, nil
+	}
+	switch qcfg.QueueDropPolicy {
+	case "", queueDropNewest, queueDropOldest:
+	default:
+		return nil, fmt.Errorf("unknown queue-drop-policy %q, must be one of %q or %q",
+			qcfg.QueueDropPolicy, queueDropNewest, queueDropOldest)
+	}
+	return &outputQueue{
+		name:       name,
+		out:        out,
+		ch:         make(chan *queuedMsg, qcfg.QueueSize),
+		dropOldest: qcfg.QueueDropPolicy == queueDropOldest,
+		stats:      stats,
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func (q *outputQueue) start(ctx context.Context) {
+	ctx, q.cfn = context.WithCancel(ctx)
+	go func() {
+		defer close(q.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m := <-q.ch:
+				q.write(ctx, m)
+			}
+		}
+	}()
+}
+
+func (q *outputQueue) write(ctx context.Context, m *queuedMsg) {
+	q.out.Write(ctx, m.rsp, m.meta)
+	atomic.AddUint64(&q.stats.written, 1)
+	outputQueueWrittenCounter.WithLabelValues(q.name).Inc()
+	outputQueueLength.WithLabelValues(q.name).Set(float64(len(q.ch)))
+}
+
+func (q *outputQueue) stop() {
+	if q.cfn != nil {
+		q.cfn()
+	}
+}
+
+// drain stops the queue goroutine and writes the messages
+// still in the queue to the output, within timeout.
+func (q *outputQueue) drain(timeout time.Duration) {
+	q.stop()
+	if q.cfn != nil {
+		<-q.done
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-q.ch:
+			q.write(ctx, m)
+		default:
+			return
+		}
+	}
+}
+
+// FlushOutputs writes the messages waiting in the outputs queues
+// to their outputs, the queues are drained in parallel.
+// It returns once all the queues are empty or their drain timed out.
+func (a *App) FlushOutputs() {
+	a.operLock.RLock()
+	queues := make([]*outputQueue, 0, len(a.outputQueues))
+	for _, q := range a.outputQueues {
+		queues = append(queues, q)
+	}
+	a.operLock.RUnlock()
+	wg := new(sync.WaitGroup)
+	wg.Add(len(queues))
+	for _, q := range queues {
+		go func(q *outputQueue) {
+			defer wg.Done()
+			q.drain(outputQueueDrainTimeout)
+		}(q)
+	}
+	wg.Wait()
+}
+
+// enqueue adds a message to the queue without blocking.
+func (q *outputQueue) enqueue(m *queuedMsg) {
+	for {
+		select {
+		case q.ch <- m:
+			outputQueueLength.WithLabelValues(q.name).Set(float64(len(q.ch)))
+			return
+		default:
+		}
+		if !q.dropOldest {
+			q.dropped()
+			return
+		}
+		// make room for the new message
+		select {
+		case <-q.ch:
+			q.dropped()
+		default:
+		}
+	}
+}
+
+func (q *outputQueue) dropped() {
+	atomic.AddUint64(&q.stats.dropped, 1)
+	outputQueueDroppedCounter.WithLabelValues(q.name).Inc()
+}
+
+// writeToOutput writes the response to the output o,
+// through its queue q if one is configured.
+// The messages written without a queue are counted in s, if not nil.
+func writeToOutput(ctx context.Context, q *outputQueue, s *outputStats, o outputs.Output, rsp *gnmi.SubscribeResponse, m outputs.Meta) {
+	if q != nil {
+		q.enqueue(&queuedMsg{rsp: rsp, meta: m})
+		return
+	}
+	o.Write(ctx, rsp, m)
+	if s != nil {
+		atomic.AddUint64(&s.written, 1)
+	}
+}
+
+// printOutputStats writes the messages counters of each output to w.
+func (a *App) printOutputStats(w io.Writer) {
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	names := make([]string, 0, len(a.outputStats))
+	for name := range a.outputStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := a.outputStats[name]
+		fmt.Fprintf(w, "output %q: written %d message(s), dropped %d message(s), %d write error(s)\n", name,
+			atomic.LoadUint64(&s.written), atomic.LoadUint64(&s.dropped), atomic.LoadUint64(&s.errors))
+	}
+}
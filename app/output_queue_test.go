@@ -0,0 +1,174 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+// testOutput records the timestamps of the written notifications.
+type testOutput struct {
+	m          sync.Mutex
+	timestamps []int64
+}
+
+func (o *testOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return nil
+}
+
+func (o *testOutput) Write(_ context.Context, rsp proto.Message, _ outputs.Meta) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.timestamps = append(o.timestamps, rsp.(*gnmi.SubscribeResponse).GetUpdate().GetTimestamp())
+}
+
+func (o *testOutput) WriteEvent(context.Context, *formatters.EventMsg) {}
+func (o *testOutput) Close() error                                     { return nil }
+func (o *testOutput) RegisterMetrics(*prometheus.Registry)             {}
+func (o *testOutput) String() string                                   { return "test" }
+func (o *testOutput) SetLogger(*log.Logger)                            {}
+func (o *testOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) {
+}
+func (o *testOutput) SetName(string)                                  {}
+func (o *testOutput) SetClusterName(string)                           {}
+func (o *testOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func queueTestMsg(ts int64) *queuedMsg {
+	return &queuedMsg{
+		rsp: &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{Timestamp: ts},
+			},
+		},
+	}
+}
+
+func TestNewOutputQueue(t *testing.T) {
+	tests := map[string]struct {
+		cfg      map[string]interface{}
+		wantSize int
+		wantErr  bool
+	}{
+		"default": {
+			cfg:      map[string]interface{}{"type": "file"},
+			wantSize: defaultOutputQueueSize,
+		},
+		"queue_size": {
+			cfg:      map[string]interface{}{"type": "file", "queue-size": 10},
+			wantSize: 10,
+		},
+		"disabled": {
+			cfg:      map[string]interface{}{"type": "file", "queue-size": 0},
+			wantSize: 0,
+		},
+		"unknown_drop_policy": {
+			cfg:     map[string]interface{}{"type": "file", "queue-drop-policy": "drop-all"},
+			wantErr: true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := newOutputQueue(name, &testOutput{}, new(outputStats), item.cfg)
+			if (err != nil) != item.wantErr {
+				t.Fatalf("failed at item %q: expected error %v, got: %v", name, item.wantErr, err)
+			}
+			if item.wantErr {
+				return
+			}
+			size := 0
+			if q != nil {
+				size = cap(q.ch)
+			}
+			if size != item.wantSize {
+				t.Errorf("failed at item %q: expected a queue of %d messages, got %d", name, item.wantSize, size)
+			}
+		})
+	}
+}
+
+func TestOutputQueueEnqueue(t *testing.T) {
+	tests := map[string]struct {
+		dropPolicy string
+		// timestamps of the messages written to the output
+		want []int64
+	}{
+		"drop_newest": {
+			dropPolicy: queueDropNewest,
+			want:       []int64{1, 2},
+		},
+		"drop_oldest": {
+			dropPolicy: queueDropOldest,
+			want:       []int64{2, 3},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &testOutput{}
+			stats := new(outputStats)
+			q, err := newOutputQueue(name, out, stats, map[string]interface{}{
+				"queue-size":        2,
+				"queue-drop-policy": item.dropPolicy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the queue is not started, the third message does not fit
+			for ts := int64(1); ts <= 3; ts++ {
+				q.enqueue(queueTestMsg(ts))
+			}
+			q.drain(time.Second)
+			if !reflect.DeepEqual(out.timestamps, item.want) {
+				t.Errorf("failed at item %q: expected written messages %v, got %v", name, item.want, out.timestamps)
+			}
+			if stats.written != 2 || stats.dropped != 1 {
+				t.Errorf("failed at item %q: unexpected stats: %+v", name, stats)
+			}
+		})
+	}
+}
+
+func TestOutputQueueStart(t *testing.T) {
+	out := &testOutput{}
+	stats := new(outputStats)
+	q, err := newOutputQueue("test", out, stats, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.start(ctx)
+	for ts := int64(1); ts <= 10; ts++ {
+		writeToOutput(ctx, q, stats, out, queueTestMsg(ts).rsp, nil)
+	}
+	q.drain(time.Second)
+	if len(out.timestamps) != 10 {
+		t.Errorf("expected 10 written messages, got %d", len(out.timestamps))
+	}
+	for i, ts := range out.timestamps {
+		if ts != int64(i+1) {
+			t.Errorf("unexpected written messages order: %v", out.timestamps)
+			break
+		}
+	}
+	if stats.written != 10 || stats.dropped != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
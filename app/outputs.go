@@ -11,6 +11,8 @@ package app
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/openconfig/gnmic/outputs"
 	"github.com/openconfig/gnmic/types"
@@ -27,6 +29,12 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 			a.Logger.Printf("starting output type %s", outType)
 			if initializer, ok := outputs.Outputs[outType.(string)]; ok {
 				out := initializer()
+				stats := new(outputStats)
+				q, err := newOutputQueue(name, out, stats, cfg)
+				if err != nil {
+					a.Logger.Printf("failed to init output %q queue: %v", name, err)
+					return
+				}
 				go func() {
 					err := out.Init(ctx, name, cfg,
 						outputs.WithLogger(a.Logger),
@@ -40,6 +48,11 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 						outputs.WithName(a.Config.InstanceName),
 						outputs.WithClusterName(a.Config.ClusterName),
 						outputs.WithTargetsConfig(tcs),
+						outputs.WithReadOnly(a.Config.ReadOnly),
+						outputs.WithWriteErrorHandler(func(error) {
+							atomic.AddUint64(&stats.errors, 1)
+							outputWriteErrorsCounter.WithLabelValues(name).Inc()
+						}),
 					)
 					if err != nil {
 						a.Logger.Printf("failed to init output type %q: %v", outType, err)
@@ -47,6 +60,11 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 				}()
 				a.operLock.Lock()
 				a.Outputs[name] = out
+				a.outputStats[name] = stats
+				if q != nil {
+					q.start(ctx)
+					a.outputQueues[name] = q
+				}
 				a.operLock.Unlock()
 			}
 		}
@@ -85,11 +103,39 @@ func (a *App) DeleteOutput(name string) error {
 	if _, ok := a.Outputs[name]; !ok {
 		return fmt.Errorf("output %q does not exist", name)
 	}
+	if q, ok := a.outputQueues[name]; ok {
+		q.stop()
+		delete(a.outputQueues, name)
+	}
 	o := a.Outputs[name]
 	err := o.Close()
 	if err != nil {
 		a.Logger.Printf("failed to close output %q: %v", name, err)
 	}
 	delete(a.Outputs, name)
+	delete(a.outputStats, name)
 	return nil
 }
+
+// CloseOutputs closes all the outputs in parallel,
+// the outputs buffering messages send them before returning.
+func (a *App) CloseOutputs() {
+	a.operLock.RLock()
+	outs := make(map[string]outputs.Output, len(a.Outputs))
+	for name, o := range a.Outputs {
+		outs[name] = o
+	}
+	a.operLock.RUnlock()
+	wg := new(sync.WaitGroup)
+	wg.Add(len(outs))
+	for name, o := range outs {
+		go func(name string, o outputs.Output) {
+			defer wg.Done()
+			err := o.Close()
+			if err != nil {
+				a.Logger.Printf("failed to close output %q: %v", name, err)
+			}
+		}(name, o)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,214 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+const defaultPager = "less"
+
+// pager is the output of the printed responses when --pager is set,
+// it writes to the standard input of the $PAGER process.
+type pager struct {
+	cmd  *exec.Cmd
+	w    io.WriteCloser
+	done chan struct{}
+
+	m      *sync.Mutex
+	closed bool
+}
+
+// Write writes p to the pager, once the pager exited, for e.g: the user quit less
+// before the end of the output, the writes are discarded.
+func (p *pager) Write(b []byte) (int, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.closed {
+		return len(b), nil
+	}
+	n, err := p.w.Write(b)
+	if err != nil {
+		if errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed) {
+			p.closed = true
+			return len(b), nil
+		}
+		return n, err
+	}
+	return n, nil
+}
+
+// close closes the pager input and waits for the user to quit it.
+func (p *pager) close() error {
+	p.m.Lock()
+	p.closed = true
+	p.w.Close()
+	p.m.Unlock()
+	<-p.done
+	return nil
+}
+
+// startPager sets the app output to the $PAGER process if --pager is set
+// and stdout is a terminal, the output is left untouched otherwise.
+// The app context is canceled when the user quits the pager.
+func (a *App) startPager() {
+	if !a.Config.Pager || a.Config.OutputFile != "" {
+		return
+	}
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	pagerCmd := strings.TrimSpace(os.Getenv("PAGER"))
+	if pagerCmd == "" {
+		pagerCmd = defaultPager
+	}
+	cmd := exec.Command("sh", "-c", pagerCmd)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// quit if the output fits on one screen, keep the colors and do not clear the screen.
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		a.Logger.Printf("failed to start pager %q: %v", pagerCmd, err)
+		return
+	}
+	err = cmd.Start()
+	if err != nil {
+		a.Logger.Printf("failed to start pager %q: %v", pagerCmd, err)
+		return
+	}
+	p := &pager{
+		cmd:  cmd,
+		w:    w,
+		done: make(chan struct{}),
+		m:    new(sync.Mutex),
+	}
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			a.Logger.Printf("pager %q exited: %v", pagerCmd, err)
+		}
+		p.m.Lock()
+		p.closed = true
+		p.m.Unlock()
+		close(p.done)
+		// stop the running RPCs, nobody reads their output anymore.
+		a.Cfn()
+	}()
+	a.pager = p
+	a.out = p
+}
+
+// openOutputFile sets the app output to the file set with --output-file.
+func (a *App) openOutputFile() error {
+	if a.Config.OutputFile == "" {
+		return nil
+	}
+	f, err := os.Create(a.Config.OutputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	a.outputFile = f
+	a.out = f
+	if a.Config.OutputFileSync {
+		a.out = &syncFile{f}
+	}
+	return nil
+}
+
+// syncFile commits the file content to disk after each write.
+type syncFile struct {
+	f *os.File
+}
+
+func (s *syncFile) Write(b []byte) (int, error) {
+	n, err := s.f.Write(b)
+	if err != nil {
+		return n, err
+	}
+	return n, s.f.Sync()
+}
+
+// CloseOutput closes the output file and waits for the user
+// to quit the pager, if any.
+func (a *App) CloseOutput() {
+	if a.pager != nil {
+		a.pager.close()
+		a.pager = nil
+	}
+	if a.outputFile != nil {
+		err := a.outputFile.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close output file: %v\n", err)
+		}
+		a.outputFile = nil
+	}
+	a.out = os.Stdout
+}
+
+// maxLines returns the maximum number of printed lines per target response,
+// the output is not truncated when written to a file.
+func (a *App) maxLines() int {
+	if a.Config.OutputFile != "" {
+		return 0
+	}
+	return a.Config.MaxLines
+}
+
+// writeOutput writes b to the app output, each line preceded by prefix.
+// The output is truncated to --max-lines lines followed by a trailer.
+func (a *App) writeOutput(prefix string, b []byte) error {
+	b, more := truncateLines(b, a.maxLines())
+	err := writeIndented(a.out, prefix, b)
+	if err != nil || more == 0 {
+		return err
+	}
+	return writeIndented(a.out, prefix, []byte(fmt.Sprintf("... (%d more lines, use --output-file)", more)))
+}
+
+// truncateLines returns the first maxLines lines of b and the number of lines left out.
+// A maxLines lower than 1 means no limit.
+func truncateLines(b []byte, maxLines int) ([]byte, int) {
+	if maxLines < 1 {
+		return b, 0
+	}
+	n := 0
+	for i, c := range b {
+		if c != '\n' {
+			continue
+		}
+		n++
+		if n < maxLines {
+			continue
+		}
+		rest := b[i+1:]
+		if len(rest) == 0 {
+			return b, 0
+		}
+		more := bytes.Count(rest, []byte{'\n'})
+		if rest[len(rest)-1] != '\n' {
+			more++
+		}
+		return b[:i], more
+	}
+	return b, 0
+}
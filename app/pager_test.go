@@ -0,0 +1,115 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestTruncateLines(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		maxLines int
+		want     string
+		wantMore int
+	}{
+		"no_limit": {
+			in:       "a\nb\nc\n",
+			maxLines: 0,
+			want:     "a\nb\nc\n",
+		},
+		"under_limit": {
+			in:       "a\nb\n",
+			maxLines: 3,
+			want:     "a\nb\n",
+		},
+		"at_limit": {
+			in:       "a\nb\nc\n",
+			maxLines: 3,
+			want:     "a\nb\nc\n",
+		},
+		"at_limit_no_trailing_newline": {
+			in:       "a\nb\nc",
+			maxLines: 3,
+			want:     "a\nb\nc",
+		},
+		"over_limit": {
+			in:       "a\nb\nc\nd\n",
+			maxLines: 2,
+			want:     "a\nb",
+			wantMore: 2,
+		},
+		"over_limit_no_trailing_newline": {
+			in:       "a\nb\nc\nd",
+			maxLines: 1,
+			want:     "a",
+			wantMore: 3,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, more := truncateLines([]byte(item.in), item.maxLines)
+			if string(got) != item.want || more != item.wantMore {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q, %d more", item.want, item.wantMore)
+				t.Logf("     got: %q, %d more", string(got), more)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestWriteOutputMaxLines(t *testing.T) {
+	a := New()
+	buf := new(bytes.Buffer)
+	a.out = buf
+	a.Config.MaxLines = 2
+	err := a.writeOutput("[t1] ", []byte("{\n  \"a\": 1,\n  \"b\": 2\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[t1] {\n[t1]   \"a\": 1,\n[t1] ... (2 more lines, use --output-file)\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+	// the output file gets the whole output.
+	buf.Reset()
+	a.Config.OutputFile = "out.json"
+	err = a.writeOutput("", []byte("1\n2\n3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1\n2\n3\n" {
+		t.Errorf("expected the output not to be truncated, got %q", buf.String())
+	}
+}
+
+func TestPagerBrokenPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the user quit the pager.
+	r.Close()
+	p := &pager{w: w, done: make(chan struct{}), m: new(sync.Mutex)}
+	for i := 0; i < 2; i++ {
+		n, err := p.Write([]byte("line\n"))
+		if err != nil || n != 5 {
+			t.Fatalf("write %d: expected the output to be discarded, got n=%d, err=%v", i, n, err)
+		}
+	}
+	if !p.closed {
+		t.Error("expected the pager to be marked closed after a broken pipe")
+	}
+	close(p.done)
+	p.close()
+}
@@ -10,7 +10,6 @@ package app
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -18,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/manifoldco/promptui"
+	"github.com/openconfig/gnmic/formatters"
 	"github.com/openconfig/gnmic/utils"
 	"github.com/openconfig/goyang/pkg/yang"
 	"github.com/spf13/cobra"
@@ -105,7 +105,7 @@ func (a *App) PathCmdRun(d, f, e []string, pgo pathGenOpts) error {
 		gp.PathWithPrefix = collapsePrefixes(gp.PathWithPrefix)
 	}
 	if pgo.json {
-		b, err := json.MarshalIndent(gpaths, "", "  ")
+		b, err := formatters.MarshalJSON(gpaths, a.Config.JSONIndent)
 		if err != nil {
 			return err
 		}
@@ -148,20 +148,7 @@ func (a *App) PathCmdRun(d, f, e []string, pgo pathGenOpts) error {
 		Stdout:       os.Stdout,
 		HideSelected: true,
 		Searcher: func(input string, index int) bool {
-			kws := strings.Split(input, " ")
-			result := true
-			for _, kw := range kws {
-				if strings.HasPrefix(kw, "!") {
-					kw = strings.TrimLeft(kw, "!")
-					if kw == "" {
-						continue
-					}
-					result = result && !strings.Contains(paths[index], kw)
-				} else {
-					result = result && strings.Contains(paths[index], kw)
-				}
-			}
-			return result
+			return matchKeywords(paths[index], strings.Split(input, " "))
 		},
 		Keys: &promptui.SelectKeys{
 			Prev:     promptui.Key{Code: promptui.KeyPrev, Display: promptui.KeyPrevDisplay},
@@ -0,0 +1,215 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// schemaCacheVersion is part of the schema cache key,
+// it must be changed when the cached generatedPath format changes.
+const schemaCacheVersion = "1"
+
+func (a *App) PathSearchPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if len(args) == 0 {
+		return errors.New("at least one keyword is required")
+	}
+	return a.yangFilesPreProcessing()
+}
+
+func (a *App) PathSearchRunE(cmd *cobra.Command, args []string) error {
+	gpaths, err := a.schemaPaths()
+	if err != nil {
+		return err
+	}
+	found := make(map[string]*generatedPath)
+	for _, gp := range gpaths {
+		p := gp.Path
+		if !a.Config.LocalFlags.PathSearchWithKeys {
+			p = stripListKeys(p)
+		}
+		if !matchKeywords(p, args) {
+			continue
+		}
+		found[p] = gp
+	}
+	if len(found) == 0 {
+		return errors.New("no results found")
+	}
+	paths := make([]string, 0, len(found))
+	for p := range found {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if a.Config.LocalFlags.PathSearchTypes {
+			fmt.Fprintf(a.out, "%s\t(type=%s)\n", p, found[p].Type)
+			continue
+		}
+		fmt.Fprintln(a.out, p)
+	}
+	return nil
+}
+
+func (a *App) InitPathSearchFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathSearchTypes, "types", "", false, "print leaf type")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathSearchWithKeys, "with-keys", "", false, "render the lists keys placeholders, e.g: interface[name=*]")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathSearchNoCache, "no-cache", "", false, "do not use the cached paths, parse the YANG files")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// schemaPaths returns the xpaths of the leaves defined in the YANG files.
+// The paths are cached on disk, the cache is invalidated when a YANG file
+// is added, removed or modified.
+func (a *App) schemaPaths() ([]*generatedPath, error) {
+	cacheFile, err := a.schemaCacheFile()
+	if err != nil {
+		a.Logger.Printf("schema paths cache disabled: %v", err)
+	}
+	if cacheFile != "" && !a.Config.LocalFlags.PathSearchNoCache {
+		b, err := os.ReadFile(cacheFile)
+		if err == nil {
+			gpaths := make([]*generatedPath, 0)
+			err = json.Unmarshal(b, &gpaths)
+			if err == nil {
+				a.Logger.Printf("loaded %d paths from cache %s", len(gpaths), cacheFile)
+				return gpaths, nil
+			}
+			a.Logger.Printf("failed to decode schema paths cache %s: %v", cacheFile, err)
+		}
+	}
+	err = a.generateYangSchema(a.Config.GlobalFlags.Dir, a.Config.GlobalFlags.File, a.Config.GlobalFlags.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	gpaths := make([]*generatedPath, 0, 256)
+	for _, entry := range a.SchemaTree.Dir {
+		for _, e := range collectSchemaNodes(entry, true) {
+			gpaths = append(gpaths, a.generatePath(e, "xpath"))
+		}
+	}
+	if cacheFile == "" {
+		return gpaths, nil
+	}
+	b, err := json.Marshal(gpaths)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(cacheFile), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(cacheFile, b, 0600)
+	}
+	if err != nil {
+		a.Logger.Printf("failed to write schema paths cache: %v", err)
+	}
+	return gpaths, nil
+}
+
+// schemaCacheFile returns the cache file name of the paths generated from the
+// YANG files and directories set in the configuration.
+func (a *App) schemaCacheFile() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	key, err := schemaCacheKey(a.Config.GlobalFlags.Dir, a.Config.GlobalFlags.File, a.Config.GlobalFlags.Exclude)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "gnmic", "paths", key+".json"), nil
+}
+
+// schemaCacheKey hashes the name, size and modification time of the YANG files,
+// the files in the YANG directories, and the exclude regexes.
+func schemaCacheKey(dirs, files, excludes []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version=%s\n", schemaCacheVersion)
+	for _, e := range excludes {
+		fmt.Fprintf(h, "exclude=%s\n", e)
+	}
+	names := make([]string, 0, len(files))
+	names = append(names, files...)
+	for _, d := range dirs {
+		err := filepath.Walk(d, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(path) == ".yang" {
+				names = append(names, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fi, err := os.Stat(n)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file=%s size=%d mtime=%d\n", n, fi.Size(), fi.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// matchKeywords returns true if path contains all the keywords,
+// a keyword prefixed with `!` must not be in path.
+func matchKeywords(path string, kws []string) bool {
+	for _, kw := range kws {
+		if strings.HasPrefix(kw, "!") {
+			kw = strings.TrimLeft(kw, "!")
+			if kw == "" {
+				continue
+			}
+			if strings.Contains(path, kw) {
+				return false
+			}
+			continue
+		}
+		if !strings.Contains(path, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripListKeys removes the lists keys from an xpath.
+func stripListKeys(p string) string {
+	sb := new(strings.Builder)
+	depth := 0
+	for _, r := range p {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
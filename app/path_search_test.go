@@ -0,0 +1,113 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var matchKeywordsTestSet = map[string]struct {
+	kws   []string
+	match bool
+}{
+	"all_keywords": {
+		kws:   []string{"interface", "counters"},
+		match: true,
+	},
+	"missing_keyword": {
+		kws:   []string{"interface", "bgp"},
+		match: false,
+	},
+	"negated_keyword": {
+		kws:   []string{"counters", "!in-octets"},
+		match: false,
+	},
+	"negated_missing_keyword": {
+		kws:   []string{"counters", "!out-"},
+		match: true,
+	},
+	"empty_keywords": {
+		kws:   []string{"", "!"},
+		match: true,
+	},
+}
+
+func TestMatchKeywords(t *testing.T) {
+	p := "/interfaces/interface/state/counters/in-octets"
+	for name, item := range matchKeywordsTestSet {
+		t.Run(name, func(t *testing.T) {
+			if r := matchKeywords(p, item.kws); r != item.match {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.match)
+				t.Logf("     got: %v", r)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestStripListKeys(t *testing.T) {
+	r := stripListKeys("/network-instances/network-instance[name=*]/protocols/protocol[identifier=*][name=*]/config")
+	if r != "/network-instances/network-instance/protocols/protocol/config" {
+		t.Errorf("unexpected path: %q", r)
+	}
+}
+
+func TestSchemaCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.yang")
+	err := os.WriteFile(file, []byte("module a {}"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1, err := schemaCacheKey([]string{dir}, []string{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := schemaCacheKey([]string{dir}, []string{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1 != k2 {
+		t.Errorf("expected the same key for unchanged files")
+	}
+	k3, err := schemaCacheKey([]string{dir}, []string{file}, []string{"^ietf-.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k3 == k1 {
+		t.Errorf("expected a different key with excludes")
+	}
+	err = os.WriteFile(filepath.Join(dir, "b.yang"), []byte("module b {}"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k4, err := schemaCacheKey([]string{dir}, []string{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k4 == k1 {
+		t.Errorf("expected a different key after adding a file")
+	}
+	mtime := time.Now().Add(time.Hour)
+	err = os.Chtimes(file, mtime, mtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k5, err := schemaCacheKey([]string{dir}, []string{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k5 == k4 {
+		t.Errorf("expected a different key after modifying a file")
+	}
+}
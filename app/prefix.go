@@ -0,0 +1,90 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// prefixData is the data the --prefix-format template is executed with.
+type prefixData struct {
+	Name      string
+	Address   string
+	Tags      []string
+	EventTags map[string]string
+}
+
+func parsePrefixFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("prefix-format").Option("missingkey=zero").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --prefix-format: %v", err)
+	}
+	return tmpl, nil
+}
+
+// formatPrefix executes the --prefix-format template for target name.
+// It falls back to [name] if the template fails.
+func (a *App) formatPrefix(name string) string {
+	d := prefixData{Name: name, Address: name}
+	if tc, ok := a.Config.Targets[name]; ok {
+		d.Address = tc.Address
+		d.Tags = tc.Tags
+		d.EventTags = tc.EventTags
+	}
+	buf := new(bytes.Buffer)
+	err := a.prefixTmpl.Execute(buf, d)
+	if err != nil {
+		a.Logger.Printf("target %q: failed to execute the prefix format: %v", name, err)
+		return fmt.Sprintf("[%s]", name)
+	}
+	return buf.String()
+}
+
+// structuredFormat returns true if the messages printed with format are JSON objects.
+func structuredFormat(format string) bool {
+	switch format {
+	case "", formatJSON, "protojson", "event":
+		return true
+	}
+	return false
+}
+
+// printPrefix returns the prefix of the lines printed for target name.
+// The --prefix-format template is not applied to the structured (JSON) outputs,
+// they are prefixed with [name] when there are multiple targets, like without the template.
+func (a *App) printPrefix(name string, structured bool) string {
+	switch {
+	case a.Config.NoPrefix:
+		return ""
+	case a.prefixTmpl != nil && !structured:
+		return a.formatPrefix(name) + " "
+	case len(a.Config.TargetsList()) > 1:
+		return fmt.Sprintf("[%s] ", name)
+	}
+	return ""
+}
+
+// targetError prefixes err with the target name: `target "<name>": ` by default,
+// the --prefix-format prefix if set, or nothing with --no-prefix.
+func (a *App) targetError(name string, err error) error {
+	switch {
+	case a.Config.NoPrefix:
+		return err
+	case a.prefixTmpl != nil:
+		return fmt.Errorf("%s %v", a.formatPrefix(name), err)
+	}
+	return fmt.Errorf("target %q: %v", name, err)
+}
+
+// logTargetError logs the error err of target name, see logError.
+func (a *App) logTargetError(name string, err error) {
+	a.logError(a.targetError(name, err))
+}
@@ -0,0 +1,105 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestPrintPrefix(t *testing.T) {
+	tests := map[string]struct {
+		targets      []string
+		noPrefix     bool
+		prefixFormat string
+		structured   bool
+		want         string
+		wantErr      string
+	}{
+		"single_target": {
+			targets: []string{"r1"},
+			want:    "",
+		},
+		"multiple_targets": {
+			targets: []string{"r1", "r2"},
+			want:    "[r1] ",
+		},
+		"no_prefix": {
+			targets:  []string{"r1", "r2"},
+			noPrefix: true,
+			want:     "",
+			wantErr:  "get request failed",
+		},
+		"prefix_format": {
+			targets:      []string{"r1"},
+			prefixFormat: `[{{ index .EventTags "site" }}/{{ .Name }}]`,
+			want:         "[ams01/r1] ",
+			wantErr:      "[ams01/r1] get request failed",
+		},
+		"prefix_format_address": {
+			targets:      []string{"r1", "r2"},
+			prefixFormat: `{{ .Address }} |`,
+			want:         "10.0.0.1:57400 | ",
+			wantErr:      "10.0.0.1:57400 | get request failed",
+		},
+		"prefix_format_structured": {
+			targets:      []string{"r1", "r2"},
+			prefixFormat: `[{{ index .EventTags "site" }}/{{ .Name }}]`,
+			structured:   true,
+			want:         "[r1] ",
+			wantErr:      "[ams01/r1] get request failed",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			a.Config.NoPrefix = item.noPrefix
+			for _, n := range item.targets {
+				a.Config.Targets[n] = &types.TargetConfig{
+					Name:      n,
+					Address:   "10.0.0.1:57400",
+					EventTags: map[string]string{"site": "ams01"},
+				}
+			}
+			if item.prefixFormat != "" {
+				var err error
+				a.prefixTmpl, err = parsePrefixFormat(item.prefixFormat)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := a.printPrefix("r1", item.structured)
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+			wantErr := item.wantErr
+			if wantErr == "" {
+				wantErr = `target "r1": get request failed`
+			}
+			if gotErr := a.targetError("r1", errors.New("get request failed")).Error(); gotErr != wantErr {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected error: %q", wantErr)
+				t.Logf("     got error: %q", gotErr)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestParsePrefixFormat(t *testing.T) {
+	_, err := parsePrefixFormat("[{{ .Name }")
+	if err == nil {
+		t.Error("expected an invalid template error")
+	}
+}
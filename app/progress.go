@@ -0,0 +1,201 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	progressJSON = "json"
+	progressBar  = "bar"
+
+	progressBarWidth        = 30
+	defaultProgressInterval = time.Second
+)
+
+var progressModes = []string{progressJSON, progressBar}
+
+// progress tracks the targets of a get, set, subscribe or backup operation,
+// its records are written to stderr every --progress-interval with --progress.
+// The messages, bytes and connections are read from the targets wire stats.
+type progress struct {
+	op    string
+	total int
+	start time.Time
+	w     io.Writer
+
+	m sync.Mutex
+	// done targets, true if failed
+	done map[string]bool
+	// set while a progress bar is drawn on the last line
+	drawn bool
+}
+
+// progressRecord is a progress record written with --progress json.
+type progressRecord struct {
+	Operation string          `json:"operation"`
+	Targets   progressTargets `json:"targets"`
+	Messages  uint64          `json:"messages"`
+	Bytes     uint64          `json:"bytes"`
+	ElapsedMs int64           `json:"elapsed-ms"`
+	Done      bool            `json:"done"`
+}
+
+type progressTargets struct {
+	Total     int `json:"total"`
+	Connected int `json:"connected"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// startProgress starts writing the progress records of operation op over total targets,
+// it returns the function stopping it after writing the last record.
+// It does nothing without --progress, or with --progress bar if stderr is not a terminal.
+func (a *App) startProgress(op string, total int) func() {
+	if a.Config.Progress == "" {
+		return func() {}
+	}
+	if a.Config.Progress == progressBar && !term.IsTerminal(int(os.Stderr.Fd())) {
+		a.Logger.Printf("stderr is not a terminal, --progress bar disabled")
+		return func() {}
+	}
+	p := &progress{
+		op:    op,
+		total: total,
+		start: time.Now(),
+		w:     os.Stderr,
+		done:  make(map[string]bool),
+	}
+	// a.progress is read by the targets goroutines, it is set and reset
+	// with the print lock held.
+	a.printLock.Lock()
+	a.progress = p
+	a.printLock.Unlock()
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(a.Config.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				a.writeProgress(p, true)
+				return
+			case <-ticker.C:
+				a.writeProgress(p, false)
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+		a.printLock.Lock()
+		a.progress = nil
+		a.printLock.Unlock()
+	}
+}
+
+// progressDone records target name as completed, or as failed.
+func (a *App) progressDone(name string, failed bool) {
+	a.printLock.Lock()
+	p := a.progress
+	a.printLock.Unlock()
+	if p == nil {
+		return
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.done[name] = failed
+}
+
+// progressRecord returns the current record of progress p.
+func (a *App) progressRecord(p *progress, done bool) *progressRecord {
+	r := &progressRecord{
+		Operation: p.op,
+		Targets:   progressTargets{Total: p.total},
+		ElapsedMs: time.Since(p.start).Milliseconds(),
+		Done:      done,
+	}
+	a.wireStatsLock.Lock()
+	for _, ws := range a.wireStats {
+		s := ws.snapshot()
+		r.Messages += s.recvMsgs
+		r.Bytes += s.recvWireBytes
+		if s.conns > 0 {
+			r.Targets.Connected++
+		}
+	}
+	a.wireStatsLock.Unlock()
+	p.m.Lock()
+	for _, failed := range p.done {
+		if failed {
+			r.Targets.Failed++
+			continue
+		}
+		r.Targets.Completed++
+	}
+	p.m.Unlock()
+	return r
+}
+
+// writeProgress writes the current record of progress p, or redraws the progress bar.
+// The print lock is held so that a record is never written in the middle of a printed response.
+func (a *App) writeProgress(p *progress, done bool) {
+	r := a.progressRecord(p, done)
+	a.printLock.Lock()
+	defer a.printLock.Unlock()
+	if a.Config.Progress == progressJSON {
+		b, err := json.Marshal(r)
+		if err != nil {
+			a.Logger.Printf("failed to marshal progress record: %v", err)
+			return
+		}
+		fmt.Fprintf(p.w, "%s\n", b)
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[K%s", formatProgressBar(r))
+	p.drawn = !done
+	if done {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// clearProgressBar erases the progress bar before a response is printed,
+// it is redrawn at the next interval. The print lock must be held.
+func (a *App) clearProgressBar() {
+	if a.progress == nil || !a.progress.drawn {
+		return
+	}
+	fmt.Fprint(a.progress.w, "\r\033[K")
+	a.progress.drawn = false
+}
+
+// formatProgressBar formats r as a single line progress bar,
+// filled with the completed and failed targets.
+func formatProgressBar(r *progressRecord) string {
+	done := r.Targets.Completed + r.Targets.Failed
+	filled := progressBarWidth
+	if r.Targets.Total > 0 && done < r.Targets.Total {
+		filled = done * progressBarWidth / r.Targets.Total
+	}
+	elapsed := time.Duration(r.ElapsedMs) * time.Millisecond
+	return fmt.Sprintf("%s [%s%s] %d/%d target(s), %d failed, %d message(s), %d bytes, %s",
+		r.Operation, strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled),
+		done, r.Targets.Total, r.Targets.Failed, r.Messages, r.Bytes, elapsed.Round(100*time.Millisecond))
+}
@@ -0,0 +1,82 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressRecord(t *testing.T) {
+	a := New()
+	a.Config.Progress = progressJSON
+	out := new(bytes.Buffer)
+	p := &progress{
+		op:    "get",
+		total: 3,
+		start: time.Now(),
+		w:     out,
+		done:  make(map[string]bool),
+	}
+	a.progress = p
+	a.wireStats["r1"] = &wireStats{recvMsgs: 2, recvWireBytes: 100, conns: 1}
+	a.wireStats["r2"] = &wireStats{recvMsgs: 1, recvWireBytes: 50, conns: 1}
+	a.wireStats["r3"] = &wireStats{}
+	a.progressDone("r1", false)
+	a.progressDone("r3", true)
+
+	a.writeProgress(p, true)
+	r := new(progressRecord)
+	err := json.Unmarshal(out.Bytes(), r)
+	if err != nil {
+		t.Fatalf("failed to unmarshal the progress record %q: %v", out.String(), err)
+	}
+	want := progressTargets{Total: 3, Connected: 2, Completed: 1, Failed: 1}
+	if r.Targets != want {
+		t.Errorf("expected targets %+v, got %+v", want, r.Targets)
+	}
+	if r.Operation != "get" || r.Messages != 3 || r.Bytes != 150 || !r.Done {
+		t.Errorf("unexpected progress record: %+v", r)
+	}
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("expected a single line record, got %q", out.String())
+	}
+}
+
+func TestFormatProgressBar(t *testing.T) {
+	tests := map[string]struct {
+		in   *progressRecord
+		want string
+	}{
+		"half": {
+			in: &progressRecord{
+				Operation: "get",
+				Targets:   progressTargets{Total: 4, Connected: 4, Completed: 1, Failed: 1},
+				Messages:  2,
+				Bytes:     1024,
+				ElapsedMs: 1520,
+			},
+			want: "get [###############---------------] 2/4 target(s), 1 failed, 2 message(s), 1024 bytes, 1.5s",
+		},
+		"no_targets": {
+			in:   &progressRecord{Operation: "subscribe"},
+			want: "subscribe [##############################] 0/0 target(s), 0 failed, 0 message(s), 0 bytes, 0s",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := formatProgressBar(item.in); got != item.want {
+				t.Errorf("failed at item %q: expected %q, got %q", name, item.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestPrintMsgQuery(t *testing.T) {
+	rsp := &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{
+			{
+				Timestamp: 1,
+				Update: []*gnmi.Update{
+					{
+						Path: mustParsePath(t, "/interfaces/interface[name=e1]/state/counters/in-octets"),
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 150}},
+					},
+					{
+						Path: mustParsePath(t, "/interfaces/interface[name=e2]/state/counters/in-octets"),
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 50}},
+					},
+				},
+			},
+		},
+	}
+	for name, item := range map[string]struct {
+		query string
+		raw   bool
+		out   string
+	}{
+		"select": {
+			query: ".[].updates[].values | to_entries[] | select(.value > 100) | .value",
+			out:   "150\n",
+		},
+		"string": {
+			query: ".[].source",
+			out:   "\"r1\"\n",
+		},
+		"raw_string": {
+			query: ".[].source",
+			raw:   true,
+			out:   "r1\n",
+		},
+		"no_result": {
+			query: ".[].updates[].values[] | select(. > 1000)",
+			out:   "",
+		},
+	} {
+		a := New()
+		a.Logger = log.New(io.Discard, "", 0)
+		out := new(bytes.Buffer)
+		a.out = out
+		a.Config.Format = formatJSON
+		a.Config.Query = item.query
+		a.Config.QueryRaw = item.raw
+		err := a.validateGlobals(a.RootCmd)
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		err = a.PrintMsg("r1", "Get Response:", rsp)
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		if out.String() != item.out {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.out, out.String())
+		}
+	}
+}
+
+func TestValidateGlobalsQuery(t *testing.T) {
+	for name, item := range map[string]struct {
+		format string
+		query  string
+		raw    bool
+		err    bool
+	}{
+		"default_format": {
+			query: ".",
+		},
+		"json": {
+			format: formatJSON,
+			query:  ".",
+		},
+		"compile_error": {
+			format: formatJSON,
+			query:  ".[] |",
+			err:    true,
+		},
+		"unknown_function": {
+			query: "not_a_function(1)",
+			err:   true,
+		},
+		"flat_format": {
+			format: "flat",
+			query:  ".",
+			err:    true,
+		},
+		"raw_without_query": {
+			raw: true,
+			err: true,
+		},
+	} {
+		a := New()
+		a.Logger = log.New(io.Discard, "", 0)
+		a.Config.Format = item.format
+		a.Config.Query = item.query
+		a.Config.QueryRaw = item.raw
+		err := a.validateGlobals(a.RootCmd)
+		if item.err && err == nil {
+			t.Errorf("failed at item %q: expected an error", name)
+		}
+		if !item.err && err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+		}
+	}
+}
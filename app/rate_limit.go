@@ -0,0 +1,67 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+const defaultBurst = 1
+
+// rateLimitDialOpts creates the RPC rate limiter shared by all the targets
+// and returns the dial options gating the start of the unary RPCs and streams on it.
+// The messages received on a stream are not rate limited.
+func (a *App) rateLimitDialOpts() []grpc.DialOption {
+	if a.Config.RPS <= 0 {
+		a.rpcLimiter = nil
+		return nil
+	}
+	a.rpcLimiter = rate.NewLimiter(rate.Limit(a.Config.RPS), a.Config.Burst)
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(a.rateLimitUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(a.rateLimitStreamInterceptor),
+	}
+}
+
+func (a *App) rateLimitUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := a.waitRateLimit(ctx, cc.Target(), method)
+	if err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (a *App) rateLimitStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	err := a.waitRateLimit(ctx, cc.Target(), method)
+	if err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// waitRateLimit blocks until the rate limiter allows an RPC to start.
+func (a *App) waitRateLimit(ctx context.Context, target, method string) error {
+	if a.rpcLimiter == nil {
+		return nil
+	}
+	start := time.Now()
+	err := a.rpcLimiter.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("target %q: RPC %s rate limit: %v", target, method, err)
+	}
+	if d := time.Since(start); a.Config.Debug && d > time.Millisecond {
+		a.Logger.Printf("target %q: RPC %s delayed by %s by the rate limit", target, method, d)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := map[string]struct {
+		rps      float64
+		burst    int
+		failures int
+		retry    bool
+		calls    int
+		// minimum expected duration, the maximum is a generous multiple of it
+		minDuration time.Duration
+	}{
+		"burst_then_limited": {
+			rps:         20,
+			burst:       2,
+			calls:       6,
+			minDuration: 200 * time.Millisecond,
+		},
+		"retries_consume_tokens": {
+			rps:         10,
+			burst:       1,
+			failures:    2,
+			retry:       true,
+			calls:       1,
+			minDuration: 200 * time.Millisecond,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &flakyServer{failures: item.failures, calls: make(map[string]int)}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, srv)
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.RPS = item.rps
+			a.Config.Burst = item.burst
+			if item.retry {
+				a.Config.GRPCRetry = true
+				a.Config.GRPCRetryMaxAttempts = maxGRPCRetryAttempts
+				a.Config.GRPCRetryInitialBackoff = time.Millisecond
+				a.Config.GRPCRetryMaxBackoff = time.Millisecond
+				a.Config.GRPCRetryCodes = defaultGRPCRetryCodes
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			opts := append(a.createCollectorDialOpts(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			conn, err := grpc.DialContext(ctx, l.Addr().String(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			client := gnmi.NewGNMIClient(conn)
+
+			start := time.Now()
+			wg := new(sync.WaitGroup)
+			wg.Add(item.calls)
+			errs := make(chan error, item.calls)
+			for i := 0; i < item.calls; i++ {
+				go func() {
+					defer wg.Done()
+					_, err := client.Capabilities(ctx, new(gnmi.CapabilityRequest))
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			elapsed := time.Since(start)
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Errorf("failed at item %q: unexpected error: %v", name, err)
+				}
+			}
+			if elapsed < item.minDuration || elapsed > 10*item.minDuration {
+				t.Errorf("failed at item %q: expected a duration of at least %s, got %s", name, item.minDuration, elapsed)
+			}
+		})
+	}
+}
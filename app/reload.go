@@ -0,0 +1,385 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configDiff lists the changes between the running configuration and a reloaded one.
+type configDiff struct {
+	targetsAdded   []string
+	targetsDeleted []string
+	targetsUpdated []string
+	// targets flipped by their `enabled` field or --skip-target
+	targetsEnabled  []string
+	targetsDisabled []string
+	outputsAdded    []string
+	outputsDeleted  []string
+	outputsUpdated  []string
+	// added, deleted and modified subscriptions
+	subscriptions []string
+	// added, deleted and modified event processors
+	processors []string
+}
+
+func (d *configDiff) empty() bool {
+	return len(d.targetsAdded)+len(d.targetsDeleted)+len(d.targetsUpdated)+
+		len(d.targetsEnabled)+len(d.targetsDisabled)+
+		len(d.outputsAdded)+len(d.outputsDeleted)+len(d.outputsUpdated)+
+		len(d.subscriptions)+len(d.processors) == 0
+}
+
+func (d *configDiff) String() string {
+	if d.empty() {
+		return "no changes"
+	}
+	return fmt.Sprintf("targets added=%q deleted=%q updated=%q enabled=%q disabled=%q, outputs added=%q deleted=%q updated=%q, subscriptions changed=%q, processors changed=%q",
+		d.targetsAdded, d.targetsDeleted, d.targetsUpdated, d.targetsEnabled, d.targetsDisabled,
+		d.outputsAdded, d.outputsDeleted, d.outputsUpdated,
+		d.subscriptions, d.processors,
+	)
+}
+
+// handleReloadSignal reloads the configuration files
+// each time the process receives a SIGHUP.
+func (a *App) handleReloadSignal(ctx context.Context, cmd *cobra.Command) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			a.Logger.Printf("got SIGHUP, reloading config...")
+			d, err := a.reloadConfig(ctx, cmd)
+			if err != nil {
+				a.Logger.Printf("config reload rejected, keeping the running config: %v", err)
+				continue
+			}
+			a.Logger.Printf("config reloaded: %s", d)
+		}
+	}
+}
+
+// reloadConfig reads and validates the configuration files into a new config,
+// then applies its differences with the running one.
+// The running config is left untouched if the new one is invalid.
+func (a *App) reloadConfig(ctx context.Context, cmd *cobra.Command) (*configDiff, error) {
+	err := a.sem.Acquire(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer a.sem.Release(1)
+
+	nc, err := a.readReloadConfig(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	a.configLock.RLock()
+	d := diffConfigs(a.Config, nc)
+	a.configLock.RUnlock()
+	if !a.reloadTargets() {
+		if n := len(d.targetsAdded) + len(d.targetsDeleted) + len(d.targetsUpdated) +
+			len(d.targetsEnabled) + len(d.targetsDisabled); n > 0 {
+			a.Logger.Printf("config reload: %d target change(s) ignored, the targets are managed by the cluster, a loader or the tunnel server", n)
+		}
+		d.targetsAdded, d.targetsDeleted, d.targetsUpdated = nil, nil, nil
+		d.targetsEnabled, d.targetsDisabled = nil, nil
+	}
+	a.applyConfigDiff(ctx, nc, d)
+	return d, nil
+}
+
+// readReloadConfig reads the configuration files into a new config,
+// the flags set on the command line keep overriding the files values.
+func (a *App) readReloadConfig(ctx context.Context, cmd *cobra.Command) (*config.Config, error) {
+	nc := config.New()
+	nc.CfgFile = a.Config.CfgFile
+	if len(nc.CfgFile) == 0 {
+		nc.CfgFile = []string{a.Config.FileConfig.ConfigFileUsed()}
+	}
+	a.RootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		nc.FileConfig.BindPFlag(flag.Name, flag)
+	})
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		nc.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+	err := nc.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config files: %v", err)
+	}
+	v := viper.NewWithOptions(viper.KeyDelimiter("/"))
+	err = v.MergeConfigMap(nc.FileSettings())
+	if err != nil {
+		return nil, err
+	}
+	issues := config.Lint(v, a.flagKeys())
+	if len(issues) > 0 {
+		msgs := make([]string, 0, len(issues))
+		for _, i := range issues {
+			msgs = append(msgs, i.String())
+		}
+		return nil, fmt.Errorf("%d issue(s) found: %s", len(issues), strings.Join(msgs, "; "))
+	}
+	if a.Config.LocalFlags.SubscribeAgain {
+		// the subscriptions loaded with --again are not read from the files
+		nc.Subscriptions = a.Config.Subscriptions
+	} else {
+		_, err = nc.GetSubscriptions(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading subscriptions config: %v", err)
+		}
+	}
+	_, err = nc.GetOutputs()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading outputs config: %v", err)
+	}
+	_, err = nc.GetActions()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading actions config: %v", err)
+	}
+	_, err = nc.GetEventProcessors()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading event processors config: %v", err)
+	}
+	_, err = nc.GetTargets()
+	if err != nil && !errors.Is(err, config.ErrNoTargetsFound) {
+		return nil, fmt.Errorf("failed reading targets config: %v", err)
+	}
+	for _, tc := range nc.Targets {
+		for _, sc := range targetSubscriptions(nc, tc) {
+			if _, ok := a.againSubscribeRequests[sc.Name]; ok {
+				continue
+			}
+			_, err = nc.CreateSubscribeRequest(sc, tc.Name)
+			if err != nil {
+				return nil, fmt.Errorf("target %q: subscription %q: %v", tc.Name, sc.Name, err)
+			}
+		}
+	}
+	return nc, nil
+}
+
+// reloadTargets returns true if the targets are read from the configuration,
+// as opposed to being managed by the cluster leader, a loader or the tunnel server.
+func (a *App) reloadTargets() bool {
+	return !a.inCluster() && len(a.Config.Loader) == 0 && !a.Config.UseTunnelServer
+}
+
+// applyConfigDiff applies the changes d read from the new config nc,
+// only the changed outputs are restarted and the changed targets re-subscribed.
+func (a *App) applyConfigDiff(ctx context.Context, nc *config.Config, d *configDiff) {
+	a.configLock.Lock()
+	a.Config.Processors = nc.Processors
+	a.Config.Actions = nc.Actions
+	a.Config.Subscriptions = nc.Subscriptions
+	if len(d.targetsEnabled)+len(d.targetsDisabled) > 0 {
+		a.Config.SkippedTargets = nc.SkippedTargets
+	}
+	a.configLock.Unlock()
+	// outputs
+	for _, name := range append(d.outputsDeleted, d.outputsUpdated...) {
+		err := a.DeleteOutput(name)
+		if err != nil {
+			a.Logger.Printf("failed to delete output %q: %v", name, err)
+		}
+	}
+	a.configLock.Lock()
+	for _, name := range d.outputsDeleted {
+		delete(a.Config.Outputs, name)
+	}
+	for _, name := range append(d.outputsAdded, d.outputsUpdated...) {
+		a.Config.Outputs[name] = nc.Outputs[name]
+	}
+	a.configLock.Unlock()
+	for _, name := range append(d.outputsAdded, d.outputsUpdated...) {
+		a.InitOutput(ctx, name, a.Config.Targets)
+	}
+	// targets
+	for _, name := range d.targetsDisabled {
+		a.Logger.Printf("target %q disabled, stopping its subscriptions", name)
+	}
+	for _, name := range append(append(d.targetsDeleted, d.targetsUpdated...), d.targetsDisabled...) {
+		err := a.DeleteTarget(ctx, name)
+		if err != nil {
+			a.Logger.Printf("failed to delete target %q: %v", name, err)
+		}
+	}
+	for _, name := range d.targetsEnabled {
+		a.Logger.Printf("target %q enabled, starting its subscriptions", name)
+	}
+	for _, name := range append(append(d.targetsAdded, d.targetsUpdated...), d.targetsEnabled...) {
+		tc := nc.Targets[name]
+		a.AddTargetConfig(tc)
+		a.wg.Add(1)
+		go a.subscribeStream(ctx, tc)
+	}
+}
+
+// diffConfigs returns the differences between the running config cur and the new config nc.
+// A target is updated if its config changed or if one of its subscriptions changed,
+// an output is updated if its config changed or if one of its processors changed.
+func diffConfigs(cur, nc *config.Config) *configDiff {
+	d := new(configDiff)
+	d.subscriptions = changedKeys(subscriptionsKeys(cur.Subscriptions), subscriptionsKeys(nc.Subscriptions),
+		func(k string) bool { return !reflect.DeepEqual(cur.Subscriptions[k], nc.Subscriptions[k]) })
+	d.processors = changedKeys(mapsKeys(cur.Processors), mapsKeys(nc.Processors),
+		func(k string) bool { return !reflect.DeepEqual(cur.Processors[k], nc.Processors[k]) })
+
+	d.outputsAdded, d.outputsDeleted, d.outputsUpdated = diffKeys(mapsKeys(cur.Outputs), mapsKeys(nc.Outputs),
+		func(k string) bool {
+			return !reflect.DeepEqual(cur.Outputs[k], nc.Outputs[k]) ||
+				anyInList(outputProcessors(nc.Outputs[k]), d.processors)
+		})
+	d.targetsAdded, d.targetsDeleted, d.targetsUpdated = diffKeys(targetsKeys(cur.Targets), targetsKeys(nc.Targets),
+		func(k string) bool {
+			if !reflect.DeepEqual(cur.Targets[k], nc.Targets[k]) {
+				return true
+			}
+			subs := nc.Targets[k].Subscriptions
+			if len(subs) == 0 {
+				// the target uses all the subscriptions
+				return len(d.subscriptions) > 0
+			}
+			return anyInList(subs, d.subscriptions)
+		})
+	d.targetsAdded, d.targetsEnabled = splitSkipped(d.targetsAdded, cur.SkippedTargets)
+	d.targetsDeleted, d.targetsDisabled = splitSkipped(d.targetsDeleted, nc.SkippedTargets)
+	return d
+}
+
+// splitSkipped splits the target names into the ones not in skipped and the ones in skipped.
+func splitSkipped(names []string, skipped map[string]string) (others, inSkipped []string) {
+	for _, name := range names {
+		if _, ok := skipped[name]; ok {
+			inSkipped = append(inSkipped, name)
+			continue
+		}
+		others = append(others, name)
+	}
+	return others, inSkipped
+}
+
+// targetSubscriptions returns the subscriptions of target tc in config c.
+func targetSubscriptions(c *config.Config, tc *types.TargetConfig) []*types.SubscriptionConfig {
+	subs := make([]*types.SubscriptionConfig, 0, len(c.Subscriptions))
+	for _, name := range tc.Subscriptions {
+		if sc, ok := c.Subscriptions[name]; ok {
+			subs = append(subs, sc)
+		}
+	}
+	if len(subs) > 0 {
+		return subs
+	}
+	for _, sc := range c.Subscriptions {
+		subs = append(subs, sc)
+	}
+	return subs
+}
+
+// diffKeys returns the sorted keys only in next, only in cur,
+// and in both for which updated returns true.
+func diffKeys(cur, next []string, updated func(string) bool) (added, deleted, changed []string) {
+	curSet := make(map[string]struct{}, len(cur))
+	for _, k := range cur {
+		curSet[k] = struct{}{}
+	}
+	nextSet := make(map[string]struct{}, len(next))
+	for _, k := range next {
+		nextSet[k] = struct{}{}
+		if _, ok := curSet[k]; !ok {
+			added = append(added, k)
+			continue
+		}
+		if updated(k) {
+			changed = append(changed, k)
+		}
+	}
+	for _, k := range cur {
+		if _, ok := nextSet[k]; !ok {
+			deleted = append(deleted, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(deleted)
+	sort.Strings(changed)
+	return added, deleted, changed
+}
+
+// changedKeys returns the sorted keys added, deleted or updated between cur and next.
+func changedKeys(cur, next []string, updated func(string) bool) []string {
+	added, deleted, changed := diffKeys(cur, next, updated)
+	keys := append(append(added, deleted...), changed...)
+	sort.Strings(keys)
+	return keys
+}
+
+func mapsKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func subscriptionsKeys(m map[string]*types.SubscriptionConfig) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func targetsKeys(m map[string]*types.TargetConfig) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// outputProcessors returns the event processors names of an output config.
+func outputProcessors(cfg map[string]interface{}) []string {
+	switch eps := cfg["event-processors"].(type) {
+	case []string:
+		return eps
+	case []interface{}:
+		names := make([]string, 0, len(eps))
+		for _, ep := range eps {
+			names = append(names, fmt.Sprintf("%v", ep))
+		}
+		return names
+	}
+	return nil
+}
+
+func anyInList(ls, items []string) bool {
+	for _, s := range ls {
+		if strInList(s, items) {
+			return true
+		}
+	}
+	return false
+}
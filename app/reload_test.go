@@ -0,0 +1,148 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/types"
+)
+
+func reloadTestConfig(targets map[string]*types.TargetConfig, subs map[string]*types.SubscriptionConfig,
+	outs map[string]map[string]interface{}, procs map[string]map[string]interface{}) *config.Config {
+	c := config.New()
+	c.Targets = targets
+	c.Subscriptions = subs
+	c.Outputs = outs
+	c.Processors = procs
+	return c
+}
+
+func TestDiffConfigs(t *testing.T) {
+	si1 := 10 * time.Second
+	si2 := 30 * time.Second
+	cur := reloadTestConfig(
+		map[string]*types.TargetConfig{
+			"r1": {Name: "r1", Address: "10.0.0.1:57400", Subscriptions: []string{"sub1"}},
+			"r2": {Name: "r2", Address: "10.0.0.2:57400", Subscriptions: []string{"sub2"}},
+			"r3": {Name: "r3", Address: "10.0.0.3:57400", Subscriptions: []string{"sub2"}},
+			"r4": {Name: "r4", Address: "10.0.0.4:57400", Subscriptions: []string{"sub2"}},
+		},
+		map[string]*types.SubscriptionConfig{
+			"sub1": {Name: "sub1", Paths: []string{"/interface"}, SampleInterval: &si1},
+			"sub2": {Name: "sub2", Paths: []string{"/system"}},
+		},
+		map[string]map[string]interface{}{
+			"out1": {"type": "file", "format": "event"},
+			"out2": {"type": "nats", "event-processors": []interface{}{"proc1"}},
+			"out3": {"type": "kafka"},
+		},
+		map[string]map[string]interface{}{
+			"proc1": {"event-drop": map[string]interface{}{"value-names": []interface{}{".*"}}},
+		},
+	)
+	next := reloadTestConfig(
+		map[string]*types.TargetConfig{
+			// sub1 sample interval changed
+			"r1": {Name: "r1", Address: "10.0.0.1:57400", Subscriptions: []string{"sub1"}},
+			// unchanged
+			"r2": {Name: "r2", Address: "10.0.0.2:57400", Subscriptions: []string{"sub2"}},
+			// address changed
+			"r3": {Name: "r3", Address: "10.0.0.33:57400", Subscriptions: []string{"sub2"}},
+			"r5": {Name: "r5", Address: "10.0.0.5:57400"},
+		},
+		map[string]*types.SubscriptionConfig{
+			"sub1": {Name: "sub1", Paths: []string{"/interface"}, SampleInterval: &si2},
+			"sub2": {Name: "sub2", Paths: []string{"/system"}},
+		},
+		map[string]map[string]interface{}{
+			"out1": {"type": "file", "format": "event"},
+			// processor changed
+			"out2": {"type": "nats", "event-processors": []interface{}{"proc1"}},
+			"out4": {"type": "tcp"},
+		},
+		map[string]map[string]interface{}{
+			"proc1": {"event-drop": map[string]interface{}{"value-names": []interface{}{"^counter$"}}},
+		},
+	)
+	d := diffConfigs(cur, next)
+	expected := &configDiff{
+		targetsAdded:   []string{"r5"},
+		targetsDeleted: []string{"r4"},
+		targetsUpdated: []string{"r1", "r3"},
+		outputsAdded:   []string{"out4"},
+		outputsDeleted: []string{"out3"},
+		outputsUpdated: []string{"out2"},
+		subscriptions:  []string{"sub1"},
+		processors:     []string{"proc1"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Logf("expected: %s", expected)
+		t.Logf("     got: %s", d)
+		t.Fail()
+	}
+}
+
+func TestDiffConfigsSkippedTargets(t *testing.T) {
+	cur := reloadTestConfig(
+		map[string]*types.TargetConfig{
+			"r1": {Name: "r1", Address: "10.0.0.1:57400"},
+			"r2": {Name: "r2", Address: "10.0.0.2:57400"},
+		},
+		nil, nil, nil,
+	)
+	cur.SkippedTargets = map[string]string{"r3": "disabled in the config"}
+	next := reloadTestConfig(
+		map[string]*types.TargetConfig{
+			"r1": {Name: "r1", Address: "10.0.0.1:57400"},
+			"r3": {Name: "r3", Address: "10.0.0.3:57400"},
+			"r4": {Name: "r4", Address: "10.0.0.4:57400"},
+		},
+		nil, nil, nil,
+	)
+	next.SkippedTargets = map[string]string{"r2": "skipped with --skip-target"}
+	d := diffConfigs(cur, next)
+	expected := &configDiff{
+		targetsAdded:    []string{"r4"},
+		targetsEnabled:  []string{"r3"},
+		targetsDisabled: []string{"r2"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Logf("expected: %s", expected)
+		t.Logf("     got: %s", d)
+		t.Fail()
+	}
+}
+
+func TestDiffConfigsNoChanges(t *testing.T) {
+	newConfig := func() *config.Config {
+		return reloadTestConfig(
+			map[string]*types.TargetConfig{
+				"r1": {Name: "r1", Address: "10.0.0.1:57400"},
+			},
+			map[string]*types.SubscriptionConfig{
+				"sub1": {Name: "sub1", Paths: []string{"/interface"}},
+			},
+			map[string]map[string]interface{}{
+				"out1": {"type": "file", "format": "event"},
+			},
+			nil,
+		)
+	}
+	d := diffConfigs(newConfig(), newConfig())
+	if !d.empty() {
+		t.Errorf("expected no changes, got: %s", d)
+	}
+	if d.String() != "no changes" {
+		t.Errorf("unexpected summary: %s", d)
+	}
+}
@@ -0,0 +1,178 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+func (a *App) RestorePreRunE(cmd *cobra.Command, args []string) error {
+	if err := a.checkReadOnly(cmd); err != nil {
+		return err
+	}
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if a.Config.LocalFlags.RestoreFile == "" {
+		return errors.New("missing required flag --file")
+	}
+	if !a.Config.LocalFlags.RestoreYes && !a.Config.LocalFlags.RestoreDryRun && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("stdin is not a terminal, set --yes to apply the snapshot without confirmation or --dry-run")
+	}
+
+	a.createCollectorDialOpts()
+	return a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+}
+
+func (a *App) RestoreRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitRestoreFlags(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	doc, err := a.Config.ReadRestoreFile()
+	if err != nil {
+		return err
+	}
+	_, err = a.GetTargets()
+	if err != nil {
+		return fmt.Errorf("failed getting targets config: %v", err)
+	}
+	names := make([]string, 0, len(a.Config.Targets))
+	for n := range a.Config.Targets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	// targets are restored one by one, each restore is confirmed by the user
+	numFailed := 0
+	for _, n := range names {
+		err = a.restoreTarget(ctx, a.Config.Targets[n], doc)
+		if err != nil {
+			numFailed++
+			fmt.Fprintf(a.out, "%s: restore failed: %v\n", n, err)
+		}
+	}
+	if numFailed > 0 {
+		return fmt.Errorf("%d/%d target(s) restore failed", numFailed, len(names))
+	}
+	return nil
+}
+
+// InitRestoreFlags used to init or reset restoreCmd flags for gnmic-prompt mode
+func (a *App) InitRestoreFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.RestoreFile, "file", "", "", "configuration snapshot file to restore, as written by the backup command")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.RestoreGranular, "granular", "", false, "replace each top-level container separately instead of the root path")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.RestoreDryRun, "dry-run", "", false, "print the diff against the target configuration without applying the snapshot")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.RestoreYes, "yes", "y", false, "apply the snapshot without asking for confirmation")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// restoreTarget prints the diff between the target configuration and the snapshot doc,
+// then sends the SetRequest replacing the target configuration once confirmed.
+func (a *App) restoreTarget(ctx context.Context, tc *types.TargetConfig, doc map[string]interface{}) error {
+	setReq, err := a.Config.CreateRestoreSetRequest(tc, doc, a.Config.LocalFlags.RestoreGranular)
+	if err != nil {
+		return err
+	}
+	getReq, err := a.Config.CreateRestoreGetRequest(tc)
+	if err != nil {
+		return err
+	}
+	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
+		getReq.Prefix, getReq.Path, getReq.Type, getReq.Encoding, getReq.UseModels, getReq.Extension, tc.Name)
+	rsp, err := a.ClientGet(ctx, tc, getReq)
+	if err != nil {
+		return fmt.Errorf("failed to get the current configuration: %v", err)
+	}
+	current, err := configDocument(rsp)
+	if err != nil {
+		return err
+	}
+	if unknown := unknownContainers(doc, current); len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: target %q: the snapshot top-level containers %s are not in the target configuration, check the snapshot and the target models prefixes match\n",
+			tc.Name, strings.Join(unknown, ", "))
+	}
+	cb, err := formatters.MarshalJSON(current, defaultJSONIndent)
+	if err != nil {
+		return err
+	}
+	sb, err := formatters.MarshalJSON(doc, defaultJSONIndent)
+	if err != nil {
+		return err
+	}
+	if string(cb) == string(sb) {
+		fmt.Fprintf(a.out, "%s: configuration matches the snapshot, nothing to restore\n", tc.Name)
+		return nil
+	}
+	fmt.Fprintf(a.out, "%q configuration changes:\n%s\n", tc.Name, lineDiff(string(cb), string(sb), backupDiffContext))
+	if a.Config.LocalFlags.RestoreDryRun {
+		return nil
+	}
+	if !a.Config.LocalFlags.RestoreYes && !confirmPrompt(fmt.Sprintf("apply the snapshot to target %q", tc.Name)) {
+		fmt.Fprintf(a.out, "%s: restore skipped\n", tc.Name)
+		return nil
+	}
+	a.Logger.Printf("sending gNMI SetRequest: prefix='%v', delete='%v', replace='%v', update='%v', extension='%v' to %s",
+		setReq.Prefix, setReq.Delete, setReq.Replace, setReq.Update, setReq.Extension, tc.Name)
+	if a.Config.PrintRequest {
+		err = a.PrintMsg(tc.Name, "Set Request:", setReq)
+		if err != nil {
+			a.logTargetError(tc.Name, err)
+		}
+	}
+	_, err = a.ClientSet(ctx, tc, setReq)
+	if err != nil {
+		return fmt.Errorf("set request failed: %v", err)
+	}
+	fmt.Fprintf(a.out, "%s: restored %s\n", tc.Name, a.Config.LocalFlags.RestoreFile)
+	return nil
+}
+
+// unknownContainers returns the sorted top-level keys of the snapshot
+// that are not present in the target configuration.
+func unknownContainers(snapshot, current map[string]interface{}) []string {
+	unknown := make([]string, 0)
+	for k := range snapshot {
+		if _, ok := current[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func confirmPrompt(label string) bool {
+	p := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	_, err := p.Run()
+	return err == nil
+}
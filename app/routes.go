@@ -19,7 +19,9 @@ func (a *App) routes() {
 	a.clusterRoutes(apiV1)
 	a.configRoutes(apiV1)
 	a.targetRoutes(apiV1)
-
+	if a.Config.APIServer != nil && a.Config.APIServer.EnableRPCs {
+		a.rpcRoutes(apiV1)
+	}
 }
 
 func (a *App) clusterRoutes(r *mux.Router) {
@@ -58,4 +60,7 @@ func (a *App) targetRoutes(r *mux.Router) {
 	r.HandleFunc("/targets/{id}", a.handleTargetsGet).Methods(http.MethodGet)
 	r.HandleFunc("/targets/{id}", a.handleTargetsPost).Methods(http.MethodPost)
 	r.HandleFunc("/targets/{id}", a.handleTargetsDelete).Methods(http.MethodDelete)
+	// targets status
+	r.HandleFunc("/status/targets", a.handleTargetsStatusGet).Methods(http.MethodGet)
+	r.HandleFunc("/status/targets/{id}", a.handleTargetsStatusGet).Methods(http.MethodGet)
 }
@@ -0,0 +1,258 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	// registers the standard error details types (BadRequest, ErrorInfo,...)
+	// so that they can be decoded from a gRPC status.
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// rpcError is the structured form of a failed RPC,
+// printed instead of a text error with --format json.
+type rpcError struct {
+	Source   string            `json:"source,omitempty"`
+	RPC      string            `json:"rpc,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Hint     string            `json:"hint,omitempty"`
+	TimedOut bool              `json:"timed-out,omitempty"`
+	Details  []json.RawMessage `json:"details,omitempty"`
+}
+
+type rpcErrorMsg struct {
+	Error *rpcError `json:"error,omitempty"`
+}
+
+type rpcErrorsSummary struct {
+	Errors   int            `json:"errors,omitempty"`
+	Codes    map[string]int `json:"codes,omitempty"`
+	Sources  []string       `json:"sources,omitempty"`
+	TimedOut []string       `json:"timed-out,omitempty"`
+	Skipped  []string       `json:"skipped,omitempty"`
+}
+
+type rpcErrorsSummaryMsg struct {
+	Summary *rpcErrorsSummary `json:"summary,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	if e.Hint != "" {
+		return fmt.Sprintf("target %q %s request failed: %s: %s: %s", e.Source, strings.ToLower(e.RPC), e.Code, e.Message, e.Hint)
+	}
+	return fmt.Sprintf("target %q %s request failed: %s: %s", e.Source, strings.ToLower(e.RPC), e.Code, e.Message)
+}
+
+// rpcErrorHint classifies the Unimplemented errors returned by the RPC rpc:
+// a gRPC server without the gNMI service rejects all the RPCs as an unknown service,
+// a partial gNMI implementation rejects the RPCs it does not implement.
+// It returns an empty string for the other errors.
+func rpcErrorHint(rpc string, err error) string {
+	st := status.Convert(err)
+	if st.Code() != codes.Unimplemented {
+		return ""
+	}
+	if strings.Contains(st.Message(), "unknown service") {
+		return "the target speaks gRPC but not gNMI, the port may be wrong"
+	}
+	return fmt.Sprintf("the target gNMI service does not implement the %s RPC", rpc)
+}
+
+// newRPCError builds an rpcError from err,
+// decoding the gRPC status code and details if any.
+// A non gRPC status error is reported with code Unknown.
+func newRPCError(source, rpc string, err error) *rpcError {
+	st := status.Convert(err)
+	rerr := &rpcError{
+		Source:  source,
+		RPC:     rpc,
+		Code:    st.Code().String(),
+		Message: st.Message(),
+		Hint:    rpcErrorHint(rpc, err),
+	}
+	for _, d := range st.Proto().GetDetails() {
+		// protojson resolves the registered detail types,
+		// and includes their "@type"
+		b, merr := protojson.Marshal(d)
+		if merr != nil {
+			b, _ = json.Marshal(map[string]string{
+				"@type": d.GetTypeUrl(),
+				"value": base64.StdEncoding.EncodeToString(d.GetValue()),
+			})
+		}
+		rerr.Details = append(rerr.Details, b)
+	}
+	return rerr
+}
+
+// statusDetails renders the details of the gRPC status of err, one line per item.
+// The BadRequest, ErrorInfo and gNMI Error details are rendered as text,
+// the other registered types as JSON and the unknown types as their type URL
+// followed by their base64 encoded value.
+func statusDetails(err error) []string {
+	lines := make([]string, 0)
+	for _, d := range status.Convert(err).Proto().GetDetails() {
+		m, uerr := d.UnmarshalNew()
+		if uerr != nil {
+			lines = append(lines, fmt.Sprintf("%s: %s", d.GetTypeUrl(), base64.StdEncoding.EncodeToString(d.GetValue())))
+			continue
+		}
+		switch m := m.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range m.GetFieldViolations() {
+				lines = append(lines, fmt.Sprintf("bad request: %s: %s", v.GetField(), v.GetDescription()))
+			}
+		case *errdetails.ErrorInfo:
+			line := fmt.Sprintf("error info: reason %s", m.GetReason())
+			if m.GetDomain() != "" {
+				line += fmt.Sprintf(", domain %s", m.GetDomain())
+			}
+			keys := make([]string, 0, len(m.GetMetadata()))
+			for k := range m.GetMetadata() {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				line += fmt.Sprintf(", %s=%s", k, m.GetMetadata()[k])
+			}
+			lines = append(lines, line)
+		case *gnmi.Error:
+			lines = append(lines, fmt.Sprintf("gnmi error: code %d: %s", m.GetCode(), m.GetMessage()))
+		default:
+			b, merr := protojson.Marshal(m)
+			if merr != nil {
+				b = []byte(base64.StdEncoding.EncodeToString(d.GetValue()))
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", d.GetTypeUrl(), b))
+		}
+	}
+	return lines
+}
+
+// statusError annotates a gRPC status error with a context message,
+// keeping its status code and details available to status.Convert.
+type statusError struct {
+	msg string
+	err error
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func (e *statusError) GRPCStatus() *status.Status {
+	return status.Convert(e.err)
+}
+
+// wrapStatusError prefixes err with msg,
+// preserving its gRPC status if err is a status error.
+func wrapStatusError(msg string, err error) error {
+	if _, ok := status.FromError(err); !ok {
+		return fmt.Errorf("%s: %v", msg, err)
+	}
+	return &statusError{msg: msg, err: err}
+}
+
+// logRPCError reports a failed RPC to target source.
+// With --format json, the error is printed as a JSON object to the output,
+// otherwise it is logged as a text error.
+func (a *App) logRPCError(source, rpc string, err error) {
+	// the RPCs failing after the --total-timeout deadline were cancelled by it.
+	timedOut := a.totalTimeoutReached()
+	if a.Config.Format != formatJSON {
+		sb := new(strings.Builder)
+		fmt.Fprintf(sb, "%s request failed: %v", strings.ToLower(rpc), err)
+		hint := rpcErrorHint(rpc, err)
+		if timedOut {
+			hint = a.totalTimeoutHint()
+		}
+		if hint != "" {
+			sb.WriteString(": ")
+			sb.WriteString(hint)
+		}
+		// the status details are listed under the error
+		for _, d := range statusDetails(err) {
+			sb.WriteString("\n  - ")
+			sb.WriteString(d)
+		}
+		if timedOut {
+			a.logError(&timedOutError{source: source, err: a.targetError(source, errors.New(sb.String()))})
+			return
+		}
+		a.logTargetError(source, errors.New(sb.String()))
+		return
+	}
+	rerr := newRPCError(source, rpc, err)
+	if timedOut {
+		rerr.Hint = a.totalTimeoutHint()
+		rerr.TimedOut = true
+	}
+	a.Logger.Print(rerr)
+	b, merr := formatters.MarshalJSON(&rpcErrorMsg{Error: rerr}, a.Config.JSONIndent)
+	if merr != nil {
+		a.logError(rerr)
+		return
+	}
+	a.printLock.Lock()
+	fmt.Fprintln(a.out, string(b))
+	a.printLock.Unlock()
+	if a.errCh != nil {
+		a.errCh <- rerr
+	}
+}
+
+// printRPCErrorsSummary prints a JSON summary of the RPC errors found in errs.
+func (a *App) printRPCErrorsSummary(errs []error) {
+	summary := &rpcErrorsSummary{Codes: make(map[string]int)}
+	sources := make(map[string]struct{})
+	for _, err := range errs {
+		rerr, ok := err.(*rpcError)
+		if !ok {
+			continue
+		}
+		summary.Errors++
+		summary.Codes[rerr.Code]++
+		sources[rerr.Source] = struct{}{}
+	}
+	if summary.Errors == 0 {
+		return
+	}
+	for s := range sources {
+		summary.Sources = append(summary.Sources, s)
+	}
+	sort.Strings(summary.Sources)
+	if sources := timedOutSources(errs); len(sources) > 0 {
+		summary.TimedOut = sources
+	}
+	if skipped := a.skippedTargets(); len(skipped) > 0 {
+		summary.Skipped = skipped
+	}
+	b, err := formatters.MarshalJSON(&rpcErrorsSummaryMsg{Summary: summary}, a.Config.JSONIndent)
+	if err != nil {
+		a.Logger.Printf("failed to marshal errors summary: %v", err)
+		return
+	}
+	fmt.Fprintln(a.out, string(b))
+}
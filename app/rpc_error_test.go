@@ -0,0 +1,353 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// errServer is a gNMI server failing all Get requests with err.
+type errServer struct {
+	gnmi.UnimplementedGNMIServer
+	err error
+}
+
+func (s *errServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return nil, s.err
+}
+
+// getError sends a Get request to a server failing with err
+// and returns the error received by the client.
+func getError(t *testing.T, err error) error {
+	l, lerr := net.Listen("tcp", "127.0.0.1:0")
+	if lerr != nil {
+		t.Fatal(lerr)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, &errServer{err: err})
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	insecure := true
+	tg := target.NewTarget(&types.TargetConfig{
+		Name:     "t1",
+		Address:  l.Addr().String(),
+		Insecure: &insecure,
+		Timeout:  5 * time.Second,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cerr := tg.CreateGNMIClient(ctx)
+	if cerr != nil {
+		t.Fatalf("failed to create gNMI client: %v", cerr)
+	}
+	defer tg.Close()
+	_, rerr := tg.Get(ctx, &gnmi.GetRequest{})
+	if rerr == nil {
+		t.Fatal("expected the get request to fail")
+	}
+	return rerr
+}
+
+func TestLogRPCErrorJSON(t *testing.T) {
+	invalidArg, err := status.New(codes.InvalidArgument, "invalid path").
+		WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "path", Description: "unknown element foo"},
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := map[string]error{
+		"r1": getError(t, status.Error(codes.Unavailable, "target unavailable")),
+		"r2": getError(t, invalidArg.Err()),
+	}
+
+	a := New()
+	out := new(bytes.Buffer)
+	a.out = out
+	a.Config.Format = formatJSON
+	a.errCh = make(chan error, len(errs))
+	a.logRPCError("r1", "Get", errs["r1"])
+	a.logRPCError("r2", "Get", errs["r2"])
+	err = a.checkErrors()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 errors and a summary, got: %s", out.String())
+	}
+	msgs := make([]map[string]interface{}, 0, len(lines))
+	for _, l := range lines {
+		m := make(map[string]interface{})
+		err = json.Unmarshal([]byte(l), &m)
+		if err != nil {
+			t.Fatalf("output is not JSON: %q: %v", l, err)
+		}
+		msgs = append(msgs, m)
+	}
+
+	unavailable := msgs[0]["error"].(map[string]interface{})
+	if unavailable["source"] != "r1" || unavailable["rpc"] != "Get" ||
+		unavailable["code"] != "Unavailable" || unavailable["message"] != "target unavailable" {
+		t.Errorf("unexpected Unavailable error: %v", unavailable)
+	}
+	if _, ok := unavailable["details"]; ok {
+		t.Errorf("unexpected details in Unavailable error: %v", unavailable)
+	}
+
+	invalid := msgs[1]["error"].(map[string]interface{})
+	if invalid["source"] != "r2" || invalid["code"] != "InvalidArgument" || invalid["message"] != "invalid path" {
+		t.Errorf("unexpected InvalidArgument error: %v", invalid)
+	}
+	details, ok := invalid["details"].([]interface{})
+	if !ok || len(details) != 1 {
+		t.Fatalf("expected 1 error detail, got: %v", invalid["details"])
+	}
+	detail := details[0].(map[string]interface{})
+	if detail["@type"] != "type.googleapis.com/google.rpc.BadRequest" {
+		t.Errorf("unexpected error detail type: %v", detail["@type"])
+	}
+	violations, ok := detail["fieldViolations"].([]interface{})
+	if !ok || len(violations) != 1 ||
+		violations[0].(map[string]interface{})["field"] != "path" {
+		t.Errorf("unexpected field violations: %v", detail["fieldViolations"])
+	}
+
+	summary := msgs[2]["summary"].(map[string]interface{})
+	if summary["errors"] != float64(2) {
+		t.Errorf("unexpected summary errors count: %v", summary["errors"])
+	}
+	codesCount := summary["codes"].(map[string]interface{})
+	if codesCount["Unavailable"] != float64(1) || codesCount["InvalidArgument"] != float64(1) {
+		t.Errorf("unexpected summary codes: %v", codesCount)
+	}
+}
+
+func TestCapabilitiesUnimplemented(t *testing.T) {
+	tests := map[string]struct {
+		register func(gs *grpc.Server)
+		want     string
+	}{
+		"no_gnmi_service": {
+			// a gRPC server exposing the health service only
+			register: func(gs *grpc.Server) {
+				healthpb.RegisterHealthServer(gs, health.NewServer())
+			},
+			want: "the target speaks gRPC but not gNMI, the port may be wrong",
+		},
+		"capabilities_not_implemented": {
+			register: func(gs *grpc.Server) {
+				gnmi.RegisterGNMIServer(gs, &errServer{})
+			},
+			want: "the target gNMI service does not implement the Capabilities RPC",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer()
+			item.register(gs)
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.createCollectorDialOpts()
+			insecure := true
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Timeout:  5 * time.Second,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, err = a.ClientCapabilities(ctx, tc)
+			if err == nil {
+				t.Fatal("expected the capabilities request to fail")
+			}
+			if status.Code(err) != codes.Unimplemented {
+				t.Errorf("expected the Unimplemented code to be preserved, got: %v", err)
+			}
+			got := rpcErrorHint("Capabilities", err)
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+			rerr := newRPCError("t1", "Capabilities", err)
+			if rerr.Hint != item.want || !strings.HasSuffix(rerr.Error(), item.want) {
+				t.Errorf("unexpected JSON error: %+v", rerr)
+			}
+		})
+	}
+}
+
+func TestPrintCapResponseVersion(t *testing.T) {
+	tests := map[string]struct {
+		versionOnly bool
+		rsp         *gnmi.CapabilityResponse
+		want        string
+	}{
+		"version_only": {
+			versionOnly: true,
+			rsp: &gnmi.CapabilityResponse{
+				GNMIVersion:        "0.8.0",
+				SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON},
+			},
+			want: "gNMI version: 0.8.0\n\n",
+		},
+		"not_reported": {
+			rsp: &gnmi.CapabilityResponse{
+				SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON},
+			},
+			want: "gNMI version: not reported\nsupported models:\nsupported encodings:\n  - JSON\n\n",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			out := new(bytes.Buffer)
+			a.out = out
+			a.Config.LocalFlags.CapabilitiesVersion = item.versionOnly
+			a.printCapResponse("", item.rsp, nil)
+			if out.String() != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", out.String())
+				t.Fail()
+			}
+		})
+	}
+}
+
+// readStatusFixture decodes a google.rpc.Status from a testdata file,
+// base64 encoded as in the grpc-status-details-bin trailer.
+func readStatusFixture(t *testing.T, name string) error {
+	b, err := os.ReadFile(filepath.Join("testdata", "status_details", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := new(spb.Status)
+	err = proto.Unmarshal(pb, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status.ErrorProto(st)
+}
+
+func TestStatusDetails(t *testing.T) {
+	tests := map[string]struct {
+		fixture  string
+		wantText string
+		wantJSON string
+	}{
+		"bad_request": {
+			fixture: "set_bad_request.b64",
+			wantText: `target "r1": set request failed: rpc error: code = InvalidArgument desc = failed to apply set request
+  - bad request: update[0].path: /interfaces/interface[name=Ethernet99]: unknown interface
+  - bad request: update[1].val: /system/config/hostname: value exceeds 63 characters`,
+			wantJSON: `{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"update[0].path","description":"/interfaces/interface[name=Ethernet99]: unknown interface"},{"field":"update[1].val","description":"/system/config/hostname: value exceeds 63 characters"}]}`,
+		},
+		"error_info": {
+			fixture: "set_error_info.b64",
+			wantText: `target "r1": set request failed: rpc error: code = FailedPrecondition desc = commit failed
+  - error info: reason COMMIT_FAILED, domain gnmi.example.com, path=/interfaces/interface[name=Ethernet1]/config/mtu, session=gnmi-1`,
+			wantJSON: `{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"COMMIT_FAILED","domain":"gnmi.example.com","metadata":{"path":"/interfaces/interface[name=Ethernet1]/config/mtu","session":"gnmi-1"}}`,
+		},
+		"gnmi_error": {
+			fixture: "set_gnmi_error.b64",
+			wantText: `target "r1": set request failed: rpc error: code = InvalidArgument desc = set failed
+  - gnmi error: code 3: invalid value for leaf mtu`,
+			wantJSON: `{"@type":"type.googleapis.com/gnmi.Error","code":3,"message":"invalid value for leaf mtu"}`,
+		},
+		"unknown_detail": {
+			fixture: "set_unknown_detail.b64",
+			wantText: `target "r1": set request failed: rpc error: code = Internal desc = internal error
+  - type.googleapis.com/vendor.SetDiagnostics: ChFyb2xsYmFjayBjb21wbGV0ZQ==`,
+			wantJSON: `{"@type":"type.googleapis.com/vendor.SetDiagnostics","value":"ChFyb2xsYmFjayBjb21wbGV0ZQ=="}`,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the status is sent by a gRPC server to check that its details
+			// are received from the trailer.
+			rerr := getError(t, readStatusFixture(t, item.fixture))
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.errCh = make(chan error, 1)
+			a.logRPCError("r1", "Set", rerr)
+			got := (<-a.errCh).Error()
+			if got != item.wantText {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.wantText)
+				t.Logf("     got: %s", got)
+				t.Fail()
+			}
+
+			details := newRPCError("r1", "Set", rerr).Details
+			if len(details) != 1 {
+				t.Fatalf("expected 1 detail, got %d", len(details))
+			}
+			var gotJSON, wantJSON interface{}
+			err := json.Unmarshal(details[0], &gotJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = json.Unmarshal([]byte(item.wantJSON), &wantJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotJSON, wantJSON) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.wantJSON)
+				t.Logf("     got: %s", details[0])
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+)
+
+// rpcMetadataContext returns ctx capturing the headers and trailers of the unary RPCs
+// sent with it, along with their holder.
+// If ctx already captures them, it is returned unchanged.
+func rpcMetadataContext(ctx context.Context) (context.Context, *target.RPCMetadata) {
+	if md := target.RPCMetadataFromContext(ctx); md != nil {
+		return ctx, md
+	}
+	md := new(target.RPCMetadata)
+	return target.WithRPCMetadata(ctx, md), md
+}
+
+// logRPCMetadata prints the headers and trailers returned by target tName for an RPC
+// with --debug, or to stderr with --include-meta if the output format does not carry them.
+func (a *App) logRPCMetadata(tName, rpc string, md *target.RPCMetadata) {
+	if md.Empty() {
+		return
+	}
+	if a.Config.Debug {
+		a.Logger.Printf("target %q: %s response %s", tName, rpc, md)
+		return
+	}
+	if a.Config.IncludeMeta && a.Config.Format != "" && a.Config.Format != formatJSON {
+		fmt.Fprintf(os.Stderr, "target %q: %s response %s\n", tName, rpc, md)
+	}
+}
+
+// rpcMeta returns the headers and trailers and the connection security mode of target tc
+// to be added to the printed response meta, nil unless --include-meta is set.
+func (a *App) rpcMeta(tc *types.TargetConfig, md *target.RPCMetadata) map[string]string {
+	if !a.Config.IncludeMeta {
+		return nil
+	}
+	m := md.Meta()
+	m["security-mode"] = tc.SecurityMode()
+	return m
+}
+
+// logStreamHeader logs the header received on a subscription stream establishment
+// when it changes, with --debug.
+// With --include-meta, the header keys are added to the response meta m.
+func (a *App) logStreamHeader(t *target.Target, rsp *target.SubscribeResponse, m map[string]string, headers map[string]string) {
+	if len(rsp.Header) == 0 {
+		return
+	}
+	if a.Config.IncludeMeta {
+		for k, v := range (&target.RPCMetadata{Header: rsp.Header}).Meta() {
+			m[k] = v
+		}
+	}
+	if !a.Config.Debug {
+		return
+	}
+	h := target.FormatMD(rsp.Header)
+	if headers[rsp.SubscriptionName] == h {
+		return
+	}
+	headers[rsp.SubscriptionName] = h
+	a.Logger.Printf("target %q: subscription %q stream header: %s", t.Config.Name, rsp.SubscriptionName, h)
+}
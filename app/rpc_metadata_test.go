@@ -0,0 +1,83 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataServer is a gNMI server returning a header and a trailer with its Get responses.
+type metadataServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *metadataServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	grpc.SetHeader(ctx, metadata.Pairs("sw-version", "23.3.1"))
+	grpc.SetTrailer(ctx, metadata.Pairs("ratelimit-remaining", "9"))
+	return &gnmi.GetResponse{}, nil
+}
+
+func TestRPCMetadata(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, new(metadataServer))
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	logs := new(bytes.Buffer)
+	a := New()
+	a.Logger = log.New(logs, "", 0)
+	a.Config.Debug = true
+	a.Config.IncludeMeta = true
+	a.createCollectorDialOpts()
+	insecure := true
+	tc := &types.TargetConfig{
+		Name:     "t1",
+		Address:  l.Addr().String(),
+		Insecure: &insecure,
+		Timeout:  5 * time.Second,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ctx, md := rpcMetadataContext(ctx)
+	_, err = a.ClientGet(ctx, tc, &gnmi.GetRequest{})
+	if err != nil {
+		t.Fatalf("unexpected get error: %v", err)
+	}
+	meta := a.rpcMeta(tc, md)
+	if meta["header:sw-version"] != "23.3.1" || meta["trailer:ratelimit-remaining"] != "9" {
+		t.Errorf("unexpected response meta: %v", meta)
+	}
+	if meta["security-mode"] != types.SecurityModePlaintext {
+		t.Errorf("unexpected response meta: %v", meta)
+	}
+	if !strings.Contains(logs.String(), `target "t1": Get response header: sw-version=23.3.1, trailer: ratelimit-remaining=9`) {
+		t.Errorf("expected the response metadata to be logged, got logs: %s", logs.String())
+	}
+
+	a.Config.IncludeMeta = false
+	if meta := a.rpcMeta(tc, md); meta != nil {
+		t.Errorf("unexpected response meta without --include-meta: %v", meta)
+	}
+}
@@ -0,0 +1,462 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/openconfig/gnmic/api"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+const runSkipped = "SKIPPED"
+
+// runResult is the result of a run script step executed against a target.
+type runResult struct {
+	Step     string   `json:"step"`
+	RPC      string   `json:"rpc"`
+	Target   string   `json:"target,omitempty"`
+	Result   string   `json:"result"`
+	Failures []string `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func (a *App) RunPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if a.Config.Format != "" && a.Config.Format != formatJSON {
+		return fmt.Errorf("format %q not supported by the run command, the report is printed as a table or with --format json", a.Config.Format)
+	}
+
+	a.createCollectorDialOpts()
+	return a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+}
+
+func (a *App) RunRunE(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	script, err := config.ReadRunScript(args[0])
+	if err != nil {
+		return err
+	}
+	if script.HasSet() && a.Config.ReadOnly {
+		return fmt.Errorf("run script %q has set steps: %w", args[0], types.ErrReadOnly)
+	}
+	_, err = a.GetTargets()
+	if err != nil {
+		return fmt.Errorf("failed getting targets config: %v", err)
+	}
+	if a.PromptMode {
+		for _, tc := range a.Config.Targets {
+			a.AddTargetConfig(tc)
+		}
+	}
+	stepsTargets := make([][]*types.TargetConfig, 0, len(script.Steps))
+	for _, st := range script.Steps {
+		tcs, err := a.runStepTargets(st)
+		if err != nil {
+			return err
+		}
+		stepsTargets = append(stepsTargets, tcs)
+	}
+	// variables per target, captured values are only visible
+	// to the next steps of the same target.
+	vars := make(map[string]map[string]interface{}, len(a.Config.Targets))
+	for name := range a.Config.Targets {
+		vars[name] = make(map[string]interface{}, len(script.Vars))
+		for k, v := range script.Vars {
+			vars[name][k] = v
+		}
+	}
+
+	results := make([]*runResult, 0, len(script.Steps))
+	numFailed := 0
+	stopped := false
+	for i, st := range script.Steps {
+		if stopped {
+			results = append(results, &runResult{Step: st.Name, RPC: st.RPC, Result: runSkipped})
+			continue
+		}
+		if st.RPC == config.RunRPCSleep {
+			results = append(results, &runResult{Step: st.Name, RPC: st.RPC, Result: checkPass})
+			if !sleepContext(ctx, st.SleepDuration) {
+				return ctx.Err()
+			}
+			continue
+		}
+		rs := make([]*runResult, len(stepsTargets[i]))
+		wg := new(sync.WaitGroup)
+		wg.Add(len(rs))
+		for j, tc := range stepsTargets[i] {
+			go func(j int, tc *types.TargetConfig) {
+				defer wg.Done()
+				rs[j] = a.runStep(ctx, tc, st, vars[tc.Name])
+			}(j, tc)
+		}
+		wg.Wait()
+		results = append(results, rs...)
+		for _, r := range rs {
+			if r.Result != checkPass {
+				numFailed++
+				stopped = !st.ContinueOnError
+				break
+			}
+		}
+		if !stopped && !sleepContext(ctx, st.SleepDuration) {
+			return ctx.Err()
+		}
+	}
+	err = a.printRunReport(a.out, results)
+	if err != nil {
+		return err
+	}
+	if numFailed > 0 {
+		return fmt.Errorf("%d/%d step(s) failed", numFailed, len(script.Steps))
+	}
+	return nil
+}
+
+// sleepContext waits for d, it returns false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// runStepTargets returns the targets of step st, sorted by name,
+// or all the targets if the step does not list any.
+func (a *App) runStepTargets(st *config.RunStep) ([]*types.TargetConfig, error) {
+	tcs := make([]*types.TargetConfig, 0, len(a.Config.Targets))
+	if len(st.Targets) == 0 {
+		for _, tc := range a.Config.Targets {
+			tcs = append(tcs, tc)
+		}
+	} else {
+		for _, name := range st.Targets {
+			tc, ok := a.Config.Targets[name]
+			if !ok {
+				return nil, fmt.Errorf("step %q: unknown target %q", st.Name, name)
+			}
+			tcs = append(tcs, tc)
+		}
+	}
+	sort.Slice(tcs, func(i, j int) bool {
+		return tcs[i].Name < tcs[j].Name
+	})
+	return tcs, nil
+}
+
+// runStep executes step st against target tc, then evaluates its expectations
+// and sets the captured variables in vars.
+func (a *App) runStep(ctx context.Context, tc *types.TargetConfig, st *config.RunStep, vars map[string]interface{}) *runResult {
+	r := &runResult{Step: st.Name, RPC: st.RPC, Target: tc.Name, Result: checkPass}
+	var values map[string]interface{}
+	var err error
+	switch st.RPC {
+	case config.RunRPCGet:
+		values, err = a.runGet(ctx, tc, st, vars)
+	case config.RunRPCSubscribe:
+		values, err = a.runSubscribeOnce(ctx, tc, st, vars)
+	case config.RunRPCSet:
+		err = a.runSet(ctx, tc, st, vars)
+	}
+	if err != nil {
+		a.Logger.Printf("target %q: step %q failed: %v", tc.Name, st.Name, err)
+		r.Result = checkError
+		r.Error = err.Error()
+		return r
+	}
+	for _, ch := range st.Expect {
+		if v, ok := ch.Value.(string); ok {
+			v, err = runTemplate(v, tc, vars)
+			if err != nil {
+				r.Result = checkError
+				r.Error = err.Error()
+				return r
+			}
+			ch = &config.CheckConfig{Name: ch.Name, Path: ch.Path, Operator: ch.Operator, Value: v}
+		}
+		as, err := newCheckAssertion(ch)
+		if err != nil {
+			r.Result = checkError
+			r.Error = err.Error()
+			return r
+		}
+		r.Failures = append(r.Failures, as.check(runPathValues(values, ch.Path))...)
+	}
+	for name, p := range st.Capture {
+		v, ok := runCaptureValue(values, p)
+		if !ok {
+			r.Failures = append(r.Failures, fmt.Sprintf("capture %q: no value returned for path %q", name, p))
+			continue
+		}
+		vars[name] = v
+	}
+	if len(r.Failures) > 0 {
+		r.Result = checkFail
+	}
+	return r
+}
+
+func (a *App) runGet(ctx context.Context, tc *types.TargetConfig, st *config.RunStep, vars map[string]interface{}) (map[string]interface{}, error) {
+	prefix, paths, err := runStepPaths(tc, st, vars)
+	if err != nil {
+		return nil, err
+	}
+	enc := st.Encoding
+	if enc == "" {
+		enc = a.Config.RPCEncoding(config.RPCGet, tc)
+	}
+	opts := []api.GNMIOption{
+		api.Encoding(enc),
+		api.DataType(st.Type),
+		api.Prefix(prefix),
+	}
+	for _, p := range paths {
+		opts = append(opts, api.Path(p))
+	}
+	req, err := api.NewGetRequest(opts...)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := a.sendGetRequest(ctx, tc, req)
+	if err != nil {
+		return nil, err
+	}
+	return formatters.ResponsesFlat(rsp)
+}
+
+func (a *App) runSubscribeOnce(ctx context.Context, tc *types.TargetConfig, st *config.RunStep, vars map[string]interface{}) (map[string]interface{}, error) {
+	prefix, paths, err := runStepPaths(tc, st, vars)
+	if err != nil {
+		return nil, err
+	}
+	req, err := a.Config.CreateSubscribeRequest(&types.SubscriptionConfig{
+		Name:     st.Name,
+		Prefix:   prefix,
+		Paths:    paths,
+		Mode:     "ONCE",
+		Encoding: st.Encoding,
+	}, tc.Name)
+	if err != nil {
+		return nil, err
+	}
+	rsps, err := a.subscribeOnceResponses(ctx, tc, req)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]proto.Message, 0, len(rsps))
+	for _, r := range rsps {
+		msgs = append(msgs, r)
+	}
+	return formatters.ResponsesFlat(msgs...)
+}
+
+func (a *App) runSet(ctx context.Context, tc *types.TargetConfig, st *config.RunStep, vars map[string]interface{}) error {
+	prefix, err := runTemplate(st.Prefix, tc, vars)
+	if err != nil {
+		return err
+	}
+	opts := []api.GNMIOption{api.Prefix(prefix)}
+	for _, p := range st.Deletes {
+		p, err = runTemplate(p, tc, vars)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, api.Delete(p))
+	}
+	for _, sv := range st.Updates {
+		o, err := a.runSetValue(tc, st, sv, vars, api.Update)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, o)
+	}
+	for _, sv := range st.Replaces {
+		o, err := a.runSetValue(tc, st, sv, vars, api.Replace)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, o)
+	}
+	req, err := api.NewSetRequest(opts...)
+	if err != nil {
+		return err
+	}
+	_, err = a.ClientSet(ctx, tc, req)
+	return err
+}
+
+// runSetValue returns the update or replace option of set value sv,
+// a string value is executed as a template.
+func (a *App) runSetValue(tc *types.TargetConfig, st *config.RunStep, sv *config.RunSetValue, vars map[string]interface{},
+	fn func(...api.GNMIOption) func(proto.Message) error) (api.GNMIOption, error) {
+	p, err := runTemplate(sv.Path, tc, vars)
+	if err != nil {
+		return nil, err
+	}
+	v := sv.Value
+	if s, ok := v.(string); ok {
+		v, err = runTemplate(s, tc, vars)
+		if err != nil {
+			return nil, err
+		}
+	}
+	enc := sv.Encoding
+	if enc == "" {
+		enc = st.Encoding
+	}
+	if enc == "" {
+		enc = a.Config.RPCEncoding(config.RPCSet, tc)
+	}
+	return fn(api.Path(p), api.Value(v, strings.ToLower(enc))), nil
+}
+
+func runStepPaths(tc *types.TargetConfig, st *config.RunStep, vars map[string]interface{}) (string, []string, error) {
+	prefix, err := runTemplate(st.Prefix, tc, vars)
+	if err != nil {
+		return "", nil, err
+	}
+	paths := make([]string, 0, len(st.Paths))
+	for _, p := range st.Paths {
+		p, err = runTemplate(p, tc, vars)
+		if err != nil {
+			return "", nil, err
+		}
+		paths = append(paths, p)
+	}
+	return prefix, paths, nil
+}
+
+// runTemplate executes text as a template with the target name as .Target
+// and the target variables as .Vars.
+func runTemplate(text string, tc *types.TargetConfig, vars map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tpl, err := utils.CreateTemplate("run", text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %v", text, err)
+	}
+	b := new(bytes.Buffer)
+	err = tpl.Execute(b, map[string]interface{}{
+		"Target": tc.Name,
+		"Vars":   vars,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template %q: %v", text, err)
+	}
+	return b.String(), nil
+}
+
+var pathKeysRegex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// runPathMatch returns true if the flattened value path k is path p or one of its children,
+// p matches the path with or without its keys.
+func runPathMatch(k, p string) bool {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return true
+	}
+	k = strings.TrimLeft(k, "/")
+	for _, kp := range []string{k, pathKeysRegex.ReplaceAllString(k, "")} {
+		if kp == p || strings.HasPrefix(kp, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// runPathValues returns the values matching path p.
+func runPathValues(values map[string]interface{}, p string) map[string]interface{} {
+	rs := make(map[string]interface{})
+	for k, v := range values {
+		if runPathMatch(k, p) {
+			rs[k] = v
+		}
+	}
+	return rs
+}
+
+// runCaptureValue returns the value of path p, the first one
+// in lexical order of the paths if p matches multiple values.
+func runCaptureValue(values map[string]interface{}, p string) (interface{}, bool) {
+	var key string
+	found := false
+	for k := range values {
+		if runPathMatch(k, p) && (!found || k < key) {
+			key = k
+			found = true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+	return values[key], true
+}
+
+// printRunReport prints the steps results as a table, or as a JSON list with --format json.
+func (a *App) printRunReport(w io.Writer, results []*runResult) error {
+	if a.Config.Format == formatJSON {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	tabData := make([][]string, 0, len(results))
+	for _, r := range results {
+		details := r.Error
+		if len(r.Failures) > 0 {
+			details = r.Failures[0]
+			if len(r.Failures) > 1 {
+				details = fmt.Sprintf("%s (+%d more)", details, len(r.Failures)-1)
+			}
+		}
+		tabData = append(tabData, []string{r.Step, r.RPC, r.Target, r.Result, details})
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Step", "RPC", "Target", "Result", "Details"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+	return nil
+}
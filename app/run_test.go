@@ -0,0 +1,81 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestRunPathValues(t *testing.T) {
+	values := map[string]interface{}{
+		"interface[name=ethernet-1/1]/oper-state":  "up",
+		"interface[name=ethernet-1/2]/oper-state":  "down",
+		"interface[name=ethernet-1/1]/description": "uplink",
+		"system/name/host-name":                    "r1",
+	}
+	for name, item := range map[string]struct {
+		path string
+		num  int
+	}{
+		"root":           {path: "/", num: 4},
+		"exact":          {path: "/system/name/host-name", num: 1},
+		"parent":         {path: "system/name", num: 1},
+		"without_keys":   {path: "/interface/oper-state", num: 2},
+		"with_keys":      {path: "/interface[name=ethernet-1/1]", num: 2},
+		"partial_prefix": {path: "/system/na", num: 0},
+	} {
+		rs := runPathValues(values, item.path)
+		if len(rs) != item.num {
+			t.Logf("failed at item %q", name)
+			t.Logf("expected %d value(s)", item.num)
+			t.Logf("     got: %v", rs)
+			t.Fail()
+		}
+	}
+	v, ok := runCaptureValue(values, "/interface/oper-state")
+	if !ok || v != "up" {
+		t.Errorf("expected captured value \"up\", got %v", v)
+	}
+	_, ok = runCaptureValue(values, "/system/version")
+	if ok {
+		t.Errorf("expected no captured value")
+	}
+}
+
+func TestRunTemplate(t *testing.T) {
+	tc := &types.TargetConfig{Name: "router1"}
+	vars := map[string]interface{}{"hostname": "r1"}
+	for name, item := range map[string]struct {
+		in  string
+		out string
+		err bool
+	}{
+		"no_template": {in: "/system/name", out: "/system/name"},
+		"vars":        {in: "{{ .Vars.hostname }}-desc", out: "r1-desc"},
+		"target":      {in: "/system/config[name={{ .Target }}]", out: "/system/config[name=router1]"},
+		"invalid":     {in: "{{ .Vars.hostname", err: true},
+	} {
+		out, err := runTemplate(item.in, tc, vars)
+		if item.err {
+			if err == nil {
+				t.Errorf("failed at item %q: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		if out != item.out {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.out, out)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	saveRPCCapabilities = "capabilities"
+	saveRPCGet          = "get"
+	saveRPCSet          = "set"
+	saveRPCSubscribe    = "subscribe"
+
+	// timestamp of the saved requests file names, sortable and valid on all OSes
+	saveRequestTimeFormat = "20060102T150405.000000000Z"
+	// max number of attempts to find a free file name
+	saveRequestMaxAttempts = 1000
+)
+
+// characters replaced in the target names used in file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// initSaveRequestDir creates the directory set with --save-request if it does not exist.
+func (a *App) initSaveRequestDir() error {
+	if a.Config.SaveRequest == "" {
+		return nil
+	}
+	err := os.MkdirAll(a.Config.SaveRequest, 0750)
+	if err != nil {
+		return fmt.Errorf("failed to create the --save-request directory: %v", err)
+	}
+	return nil
+}
+
+// saveRequest writes req, about to be sent to target tc, to the --save-request directory
+// as prototext and protojson files named <timestamp>-<target>-<rpc>.{txt,json}.
+// The password values of a SetRequest are redacted, as in the audit file.
+// It is safe to call concurrently, each request gets its own files.
+func (a *App) saveRequest(tc *types.TargetConfig, rpc string, req proto.Message) {
+	if a.Config.SaveRequest == "" {
+		return
+	}
+	paths, err := writeSavedRequest(a.Config.SaveRequest, tc, rpc, redactRequest(req), time.Now().In(a.location))
+	if err != nil {
+		a.logTargetError(tc.Name, fmt.Errorf("failed to save the %s request: %v", rpc, err))
+		return
+	}
+	a.Logger.Printf("target %q: %s request saved to %q", tc.Name, rpc, paths)
+}
+
+func writeSavedRequest(dir string, tc *types.TargetConfig, rpc string, req proto.Message, now time.Time) ([]string, error) {
+	txt, err := prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	js, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	txt = append([]byte(savedRequestNotes(tc, rpc, now)), txt...)
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s-%s",
+		now.UTC().Format(saveRequestTimeFormat),
+		unsafeFileNameChars.ReplaceAllString(tc.Name, "_"),
+		rpc,
+	))
+	// the .txt file is created exclusively to reserve a base name,
+	// a suffix is added if another request got it first.
+	for i := 0; i < saveRequestMaxAttempts; i++ {
+		name := base
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		err = writeNewFile(name+".txt", txt)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = writeNewFile(name+".json", append(js, '\n'))
+		if err != nil {
+			return nil, err
+		}
+		return []string{name + ".txt", name + ".json"}, nil
+	}
+	return nil, fmt.Errorf("no free file name found for %q", base)
+}
+
+// savedRequestNotes returns the prototext comments heading a saved request,
+// the credentials sent as metadata are redacted.
+func savedRequestNotes(tc *types.TargetConfig, rpc string, now time.Time) string {
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "# %s request to target %q (%s) at %s\n", rpc, tc.Name, tc.Address, now.Format(time.RFC3339Nano))
+	md := make([]string, 0, 3)
+	if tc.Username != nil && *tc.Username != "" {
+		md = append(md, "username="+*tc.Username)
+	}
+	if tc.Password != nil && *tc.Password != "" {
+		md = append(md, "password="+redactedValue)
+	}
+	if tc.Token != nil && *tc.Token != "" {
+		md = append(md, "authorization="+redactedValue)
+	}
+	if len(md) > 0 {
+		fmt.Fprintf(sb, "# metadata: %s\n", strings.Join(md, " "))
+	}
+	return sb.String()
+}
+
+func writeNewFile(name string, b []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
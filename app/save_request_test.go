@@ -0,0 +1,111 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestWriteSavedRequestConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	tc := &types.TargetConfig{Name: "10.0.0.1:57400", Address: "10.0.0.1:57400"}
+	req := &gnmi.GetRequest{Path: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "system"}}}}}
+	now := time.Now()
+	numReqs := 20
+	wg := new(sync.WaitGroup)
+	wg.Add(numReqs)
+	errs := make(chan error, numReqs)
+	for i := 0; i < numReqs; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := writeSavedRequest(dir, tc, saveRPCGet, req, now)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("failed to save request: %v", err)
+		}
+	}
+	for _, ext := range []string{"txt", "json"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*-10.0.0.1_57400-get*."+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != numReqs {
+			t.Errorf("expected %d .%s files, got %d: %v", numReqs, ext, len(files), files)
+		}
+	}
+}
+
+func TestSaveRequestDryRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "requests")
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	a.out = new(bytes.Buffer)
+	a.Config.SaveRequest = dir
+	a.Config.SetDryRun = true
+	err := a.initSaveRequestDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &types.TargetConfig{
+		Name:     "router1",
+		Address:  "10.0.0.1:57400",
+		Username: pointer.ToString("admin"),
+		Password: pointer.ToString("s3cr3t"),
+	}
+	req := &gnmi.SetRequest{
+		Update: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}, {Name: "aaa"}, {Name: "password"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "n3w-s3cr3t"}},
+			},
+		},
+	}
+	a.setRequest(context.Background(), tc, req)
+
+	for _, ext := range []string{"txt", "json"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*-router1-set."+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("expected a single .%s file, got %v", ext, files)
+		}
+		b, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "s3cr3t") {
+			t.Errorf("%s: password not redacted:\n%s", files[0], b)
+		}
+		if !strings.Contains(string(b), redactedValue) {
+			t.Errorf("%s: expected a redacted value:\n%s", files[0], b)
+		}
+		if ext == "txt" && !strings.Contains(string(b), "# metadata: username=admin password=****") {
+			t.Errorf("%s: expected the redacted metadata notes:\n%s", files[0], b)
+		}
+	}
+}
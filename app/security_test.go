@@ -0,0 +1,124 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestValidateGlobalsInsecure(t *testing.T) {
+	for name, item := range map[string]struct {
+		set   func(c *config.Config)
+		force bool
+		err   bool
+	}{
+		"insecure": {
+			set: func(c *config.Config) {},
+		},
+		"skip_verify": {
+			set: func(c *config.Config) { c.SkipVerify = true },
+			err: true,
+		},
+		"tls_ca": {
+			set: func(c *config.Config) { c.TLSCa = "ca.pem" },
+			err: true,
+		},
+		"tls_cert": {
+			set: func(c *config.Config) { c.TLSCert = "cert.pem" },
+			err: true,
+		},
+		"tls_key": {
+			set: func(c *config.Config) { c.TLSKey = "key.pem" },
+			err: true,
+		},
+		"tls_version": {
+			set: func(c *config.Config) { c.TLSVersion = "1.3" },
+			err: true,
+		},
+		"tls_min_version": {
+			set: func(c *config.Config) { c.TLSMinVersion = "1.2" },
+			err: true,
+		},
+		"tls_max_version": {
+			set: func(c *config.Config) { c.TLSMaxVersion = "1.3" },
+			err: true,
+		},
+		"tls_ignore_cert_errors": {
+			set: func(c *config.Config) { c.TLSIgnoreCertErrors = true },
+			err: true,
+		},
+		"log_tls_secret": {
+			set: func(c *config.Config) { c.LogTLSSecret = true },
+			err: true,
+		},
+		"forced": {
+			set: func(c *config.Config) {
+				c.TLSCa = "ca.pem"
+				c.SkipVerify = true
+			},
+			force: true,
+		},
+	} {
+		a := New()
+		a.Logger = log.New(io.Discard, "", 0)
+		a.Config.Log = true
+		a.Config.Insecure = true
+		a.Config.Force = item.force
+		item.set(a.Config)
+		err := a.validateGlobals(a.RootCmd)
+		if item.err && err == nil {
+			t.Errorf("failed at item %q: expected an error", name)
+		}
+		if !item.err && err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+		}
+	}
+}
+
+func TestSecurityMode(t *testing.T) {
+	for name, item := range map[string]struct {
+		tc   *types.TargetConfig
+		mode string
+	}{
+		"plaintext": {
+			tc:   &types.TargetConfig{Insecure: pointer.ToBool(true), TLSCA: pointer.ToString("ca.pem")},
+			mode: types.SecurityModePlaintext,
+		},
+		"tls_verified": {
+			tc:   &types.TargetConfig{Insecure: pointer.ToBool(false), SkipVerify: pointer.ToBool(false), TLSCA: pointer.ToString("ca.pem")},
+			mode: types.SecurityModeTLSVerified,
+		},
+		"tls_verified_defaults": {
+			tc:   &types.TargetConfig{},
+			mode: types.SecurityModeTLSVerified,
+		},
+		"tls_skip_verify": {
+			tc:   &types.TargetConfig{SkipVerify: pointer.ToBool(true), TLSCert: pointer.ToString("cert.pem"), TLSKey: pointer.ToString("key.pem")},
+			mode: types.SecurityModeTLSSkipVerify,
+		},
+		"mtls": {
+			tc:   &types.TargetConfig{TLSCert: pointer.ToString("cert.pem"), TLSKey: pointer.ToString("key.pem")},
+			mode: types.SecurityModeMTLS,
+		},
+		"cert_without_key": {
+			tc:   &types.TargetConfig{TLSCert: pointer.ToString("cert.pem"), TLSKey: pointer.ToString("")},
+			mode: types.SecurityModeTLSVerified,
+		},
+	} {
+		if mode := item.tc.SecurityMode(); mode != item.mode {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.mode, mode)
+		}
+	}
+}
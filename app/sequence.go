@@ -0,0 +1,110 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+)
+
+// sequencer assigns the per target sequence numbers of --sequence-numbers.
+// The counters live as long as the process: a restart starts them over
+// with a new run ID, distinguishing a reset from a gap.
+type sequencer struct {
+	runID string
+
+	m    sync.Mutex
+	seqs map[string]uint64
+}
+
+func newSequencer() *sequencer {
+	return &sequencer{
+		runID: uuid.New().String(),
+		seqs:  make(map[string]uint64),
+	}
+}
+
+// next returns the next sequence number of target name, the first one is 1.
+func (s *sequencer) next(name string) uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.seqs[name]++
+	return s.seqs[name]
+}
+
+// snapshot returns a copy of the last sequence number assigned per target.
+func (s *sequencer) snapshot() map[string]uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+	seqs := make(map[string]uint64, len(s.seqs))
+	for k, v := range s.seqs {
+		seqs[k] = v
+	}
+	return seqs
+}
+
+// setSequence sets the next sequence number of target name and the run ID in the meta m
+// of the notification rsp. The other responses, e.g. the sync responses, are not numbered.
+func (a *App) setSequence(name string, rsp *gnmi.SubscribeResponse, m outputs.Meta) {
+	if a.sequences == nil || rsp.GetUpdate() == nil {
+		return
+	}
+	m[outputs.MetaSeq] = strconv.FormatUint(a.sequences.next(name), 10)
+	m[outputs.MetaRunID] = a.sequences.runID
+}
+
+// printSequences writes the last sequence number assigned to each target,
+// and the highest one written by each output, to w.
+// A written sequence lower than the assigned one is a loss in that output,
+// or messages still in flight.
+func (a *App) printSequences(w io.Writer) {
+	if a.sequences == nil {
+		return
+	}
+	assigned := a.sequences.snapshot()
+	names := make([]string, 0, len(assigned))
+	for name := range assigned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "run-id %s\n", a.sequences.runID)
+	for _, name := range names {
+		fmt.Fprintf(w, "target %q: last assigned sequence %d\n", name, assigned[name])
+	}
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	outNames := make([]string, 0, len(a.Outputs))
+	for name := range a.Outputs {
+		outNames = append(outNames, name)
+	}
+	sort.Strings(outNames)
+	for _, oName := range outNames {
+		r, ok := a.Outputs[oName].(outputs.SequenceReporter)
+		if !ok {
+			fmt.Fprintf(w, "output %q: written sequences not reported\n", oName)
+			continue
+		}
+		written := r.WrittenSequences()
+		for _, name := range names {
+			// skip the targets with explicit outputs not including this one
+			if tc, ok := a.Config.Targets[name]; ok && len(tc.Outputs) > 0 && !strInList(oName, tc.Outputs) {
+				continue
+			}
+			fmt.Fprintf(w, "output %q: target %q: highest written sequence %d, %d behind\n",
+				oName, name, written[name], assigned[name]-written[name])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+)
+
+// seqOutput is an output reporting its written sequences,
+// only the SequenceReporter methods are implemented.
+type seqOutput struct {
+	outputs.Output
+	outputs.SequenceTracker
+}
+
+func TestSequencer(t *testing.T) {
+	s := newSequencer()
+	numWorkers, numSeqs := 8, 1000
+	seen := make([]map[uint64]struct{}, numWorkers)
+	wg := new(sync.WaitGroup)
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		seen[i] = make(map[uint64]struct{})
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < numSeqs; j++ {
+				seen[i][s.next("t1")] = struct{}{}
+			}
+		}(i)
+	}
+	wg.Wait()
+	all := make(map[uint64]struct{})
+	for _, m := range seen {
+		for seq := range m {
+			if _, ok := all[seq]; ok {
+				t.Fatalf("sequence %d assigned twice", seq)
+			}
+			all[seq] = struct{}{}
+		}
+	}
+	want := uint64(numWorkers * numSeqs)
+	if got := s.snapshot()["t1"]; got != want {
+		t.Errorf("expected the last sequence to be %d, got %d", want, got)
+	}
+	for seq := uint64(1); seq <= want; seq++ {
+		if _, ok := all[seq]; !ok {
+			t.Fatalf("sequence %d was not assigned", seq)
+		}
+	}
+	if newSequencer().runID == s.runID {
+		t.Errorf("expected a new run-id per sequencer")
+	}
+}
+
+func TestSetSequence(t *testing.T) {
+	a := New()
+	a.sequences = newSequencer()
+	notif := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}}}
+	syncRsp := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}}
+	for i := 1; i <= 3; i++ {
+		m := outputs.Meta{"source": "t1"}
+		a.setSequence("t1", notif, m)
+		if m[outputs.MetaSeq] != strconv.Itoa(i) || m[outputs.MetaRunID] != a.sequences.runID {
+			t.Errorf("unexpected meta of notification %d: %v", i, m)
+		}
+		m = outputs.Meta{"source": "t1"}
+		a.setSequence("t1", syncRsp, m)
+		if _, ok := m[outputs.MetaSeq]; ok {
+			t.Errorf("the sync responses are not expected to be numbered")
+		}
+	}
+	// disabled
+	a.sequences = nil
+	m := outputs.Meta{}
+	a.setSequence("t1", notif, m)
+	if len(m) != 0 {
+		t.Errorf("expected no sequence without --sequence-numbers, got: %v", m)
+	}
+}
+
+func TestPrintSequences(t *testing.T) {
+	a := New()
+	a.sequences = newSequencer()
+	a.sequences.runID = "run1"
+	for i := 0; i < 5; i++ {
+		a.sequences.next("t1")
+	}
+	a.sequences.next("t2")
+	o1 := new(seqOutput)
+	o1.Record(outputs.Meta{"source": "t1", "seq": "3"})
+	o1.Record(outputs.Meta{"source": "t1", "seq": "2"})
+	o1.Record(outputs.Meta{"source": "t2", "seq": "1"})
+	a.Outputs["o1"] = o1
+	a.Outputs["o2"] = &seqOutput{}
+	a.Outputs["o3"] = new(struct{ outputs.Output })
+	// t2 writes to o1 only
+	a.Config.Targets = map[string]*types.TargetConfig{"t2": {Name: "t2", Outputs: []string{"o1"}}}
+
+	buf := new(bytes.Buffer)
+	a.printSequences(buf)
+	want := `run-id run1
+target "t1": last assigned sequence 5
+target "t2": last assigned sequence 1
+output "o1": target "t1": highest written sequence 3, 2 behind
+output "o1": target "t2": highest written sequence 1, 0 behind
+output "o2": target "t1": highest written sequence 0, 5 behind
+output "o3": written sequences not reported
+`
+	if buf.String() != want {
+		t.Logf("expected:\n%s", want)
+		t.Logf("     got:\n%s", buf.String())
+		t.Fail()
+	}
+}
@@ -10,7 +10,10 @@ package app
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/config"
@@ -18,14 +21,46 @@ import (
 	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
 )
 
+// max number of base64 characters of a bytes value printed in dry-run mode
+const bytesPreviewLen = 64
+
+// checkReadOnly returns an error if the read-only mode is enabled,
+// it is run first by the commands sending Set RPCs.
+func (a *App) checkReadOnly(cmd *cobra.Command) error {
+	if a.Config.ReadOnly {
+		return fmt.Errorf("command %q: %w", cmd.Name(), types.ErrReadOnly)
+	}
+	return nil
+}
+
 func (a *App) SetPreRunE(cmd *cobra.Command, args []string) error {
+	if err := a.checkReadOnly(cmd); err != nil {
+		return err
+	}
 	a.Config.SetLocalFlagsFromFile(cmd)
 	err := a.Config.ValidateSetInput()
 	if err != nil {
 		return err
 	}
+	if a.Config.LocalFlags.SetAppend && !a.Config.LocalFlags.SetAgain {
+		return errors.New("flag --append requires --again")
+	}
+	if a.Config.LocalFlags.SetSplitByContainer {
+		if d := a.Config.LocalFlags.SetSplitDepth; d < 1 || d > 2 {
+			return fmt.Errorf("flag --split-depth: invalid value %d, expected 1 or 2", d)
+		}
+	} else if cmd.Flags().Changed("split-depth") || a.Config.LocalFlags.SetContinueOnSplitError {
+		return errors.New("flags --split-depth and --continue-on-split-error require --split-set-by-container")
+	}
+	if a.Config.SetValidateYang {
+		err = a.yangFilesPreProcessing()
+		if err != nil {
+			return err
+		}
+	}
 
 	a.createCollectorDialOpts()
 	return a.initTunnelServer(tunnel.ServerConfig{
@@ -42,7 +77,7 @@ func (a *App) SetRunE(cmd *cobra.Command, args []string) error {
 	if a.Config.Format == formatEvent {
 		return fmt.Errorf("format event not supported for Set RPC")
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(a.ctx)
 	defer cancel()
 	// setupCloseHandler(cancel)
 	targetsConfig, err := a.GetTargets()
@@ -58,49 +93,157 @@ func (a *App) SetRunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed reading set request files: %v", err)
 	}
+	if a.Config.SetValidateYang {
+		err = a.validateSetRequests()
+		if err != nil {
+			return err
+		}
+	}
+	targets := a.Config.TargetsList()
+	var targetsReqs map[string][]*gnmi.SetRequest
+	if a.Config.LocalFlags.SetAgain {
+		targetsReqs, err = a.againSetRequests(cmd, targets)
+	} else {
+		targetsReqs, err = a.createSetRequests(targets)
+	}
+	if err != nil {
+		return err
+	}
+	if len(targets) > 0 {
+		a.saveLastRequest(lastRequestSet, nil, setRequestsMessages(targetsReqs[targets[0].Name])...)
+	}
+	defer a.startProgress("set", len(targets))()
+	if a.Config.LocalFlags.SetSplitByContainer {
+		return a.setSplitRequests(ctx, targets, targetsReqs)
+	}
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets*2)
+	if a.Config.SetDryRun {
+		// print the requests grouped by target
+		for _, tc := range targets {
+			for _, req := range targetsReqs[tc.Name] {
+				a.setRequest(ctx, tc, req)
+			}
+		}
+		return a.checkErrors()
+	}
 	a.wg.Add(numTargets)
 	for _, tc := range a.Config.Targets {
-		go a.SetRequest(ctx, tc)
+		go a.SetRequest(ctx, tc, targetsReqs[tc.Name])
 	}
 	a.wg.Wait()
 	return a.checkErrors()
 }
 
-func (a *App) SetRequest(ctx context.Context, tc *types.TargetConfig) {
-	defer a.wg.Done()
-	reqs, err := a.Config.CreateSetRequest(tc.Name)
-	if err != nil {
-		a.logError(fmt.Errorf("target %q: failed to create set request: %v", tc.Name, err))
-		return
+func setRequestsMessages(reqs []*gnmi.SetRequest) []proto.Message {
+	msgs := make([]proto.Message, 0, len(reqs))
+	for _, req := range reqs {
+		msgs = append(msgs, req)
+	}
+	return msgs
+}
+
+// createSetRequests creates the set requests of all the targets before any of them is sent,
+// it fails if the requests of any target cannot be created, e.g: a template rendering error.
+func (a *App) createSetRequests(targets []*types.TargetConfig) (map[string][]*gnmi.SetRequest, error) {
+	targetsReqs := make(map[string][]*gnmi.SetRequest, len(targets))
+	numErrs := 0
+	for _, tc := range targets {
+		reqs, err := a.Config.CreateSetRequest(tc.Name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, a.targetError(tc.Name, fmt.Errorf("failed to create set request: %v", err)))
+			numErrs++
+			continue
+		}
+		targetsReqs[tc.Name] = reqs
+	}
+	if numErrs > 0 {
+		return nil, fmt.Errorf("failed to create the set requests of %d target(s), no set request was sent", numErrs)
 	}
+	return targetsReqs, nil
+}
+
+func (a *App) SetRequest(ctx context.Context, tc *types.TargetConfig, reqs []*gnmi.SetRequest) {
+	defer a.wg.Done()
+	failed := false
 	for _, req := range reqs {
-		a.setRequest(ctx, tc, req)
+		if err := a.setRequest(ctx, tc, req); err != nil {
+			failed = true
+		}
 	}
+	a.progressDone(tc.Name, failed)
 }
 
-func (a *App) setRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.SetRequest) {
+// setRequest sends req to target tc and prints the response.
+// The returned error, already logged, reports a failed RPC or a failed operation in the response.
+func (a *App) setRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.SetRequest) error {
 	a.Logger.Printf("sending gNMI SetRequest: prefix='%v', delete='%v', replace='%v', update='%v', extension='%v' to %s",
 		req.Prefix, req.Delete, req.Replace, req.Update, req.Extension, tc.Name)
 	if a.Config.PrintRequest || a.Config.SetDryRun {
-		err := a.PrintMsg(tc.Name, "Set Request:", req)
+		printedReq := req
+		if a.Config.SetDryRun {
+			printedReq = previewBytesValues(req)
+		}
+		err := a.PrintMsg(tc.Name, "Set Request:", printedReq)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+			a.logTargetError(tc.Name, err)
 		}
 	}
 	if a.Config.SetDryRun {
-		return
+		// nothing is sent, the request is still saved with --save-request
+		a.saveRequest(tc, saveRPCSet, req)
+		return nil
 	}
+	ctx, md := rpcMetadataContext(ctx)
 	response, err := a.ClientSet(ctx, tc, req)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q set request failed: %v", tc.Name, err))
-		return
+		a.logRPCError(tc.Name, "Set", err)
+		return err
+	}
+	err = a.printMsg(tc.Name, "Set Response:", response, a.rpcMeta(tc, md))
+	if err != nil {
+		a.logTargetError(tc.Name, err)
 	}
-	err = a.PrintMsg(tc.Name, "Set Response:", response)
+	err = checkSetResponse(req, response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, err)
+	}
+	return err
+}
+
+// previewBytesValues returns a copy of the set request where the bytes values
+// are replaced with a truncated base64 preview, to avoid printing large binary blobs.
+func previewBytesValues(req *gnmi.SetRequest) *gnmi.SetRequest {
+	var preq *gnmi.SetRequest
+	for _, upds := range [][]*gnmi.Update{req.GetReplace(), req.GetUpdate()} {
+		for _, upd := range upds {
+			if _, ok := upd.GetVal().GetValue().(*gnmi.TypedValue_BytesVal); ok {
+				preq = proto.Clone(req).(*gnmi.SetRequest)
+				break
+			}
+		}
+	}
+	if preq == nil {
+		return req
+	}
+	for _, upds := range [][]*gnmi.Update{preq.GetReplace(), preq.GetUpdate()} {
+		for _, upd := range upds {
+			bv, ok := upd.GetVal().GetValue().(*gnmi.TypedValue_BytesVal)
+			if !ok {
+				continue
+			}
+			b64 := base64.StdEncoding.EncodeToString(bv.BytesVal)
+			if len(b64) > bytesPreviewLen {
+				b64 = b64[:bytesPreviewLen] + "..."
+			}
+			upd.Val = &gnmi.TypedValue{
+				Value: &gnmi.TypedValue_StringVal{
+					StringVal: fmt.Sprintf("base64:%s (%d bytes)", b64, len(bv.BytesVal)),
+				},
+			}
+		}
 	}
+	return preq
 }
 
 // InitSetFlags used to init or reset setCmd flags for gnmic-prompt mode
@@ -129,6 +272,14 @@ func (a *App) InitSetFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SetReplaceCliFile, "replace-cli-file", "", "", "path to a file containing a list of commands that will be sent as a set replace request")
 	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.SetUpdateCli, "update-cli", "", []string{}, "a cli command to be sent as a set update request")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SetUpdateCliFile, "update-cli-file", "", "", "path to a file containing a list of commands that will be sent as a set update request")
+	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.SetBytesFile, "update-bytes-file", "", []string{}, "set request path:::file to be updated with the file content as a bytes value")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SetValidateYang, "validate-yang", "", false, "validate the update and replace values against the YANG models loaded with --file before sending the request")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SetCliOrigin, "cli-origin", "", "cli", "gNMI path origin of the CLI commands set with --update-cli, --replace-cli, their -file variants or the cli: path")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SetAgain, "again", "", false, "re-send the last set request(s) built by gnmic, saved in ~/.gnmic/last-set.json, the request flags set on the command line override the saved values")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SetAppend, "append", "", false, "with --again, append the delete, replace and update operations to the saved ones instead of replacing them")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SetSplitByContainer, "split-set-by-container", "", false, "split a root-level replace into one replace per top-level container, sent as sequential set requests")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.SetSplitDepth, "split-depth", "", 1, "with --split-set-by-container, 2 splits the top-level containers per member and per list entry")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SetContinueOnSplitError, "continue-on-split-error", "", false, "with --split-set-by-container, send the remaining requests after a failed one")
 
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
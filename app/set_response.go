@@ -0,0 +1,48 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/utils"
+)
+
+// setOperationsCount returns the number of operations of req,
+// each of them expecting an UpdateResult in the SetResponse.
+func setOperationsCount(req *gnmi.SetRequest) int {
+	return len(req.GetDelete()) + len(req.GetReplace()) + len(req.GetUpdate())
+}
+
+// checkSetResponse returns an error describing the failed operations of rsp,
+// its message level error and a mismatch between the number of
+// operations sent in req and the number of results received.
+func checkSetResponse(req *gnmi.SetRequest, rsp *gnmi.SetResponse) error {
+	issues := make([]string, 0)
+	for _, u := range rsp.GetResponse() {
+		if e := formatters.SetResultError(u); e != nil {
+			issues = append(issues, fmt.Sprintf("%s %q failed: code %d: %s",
+				u.GetOp(), utils.GnmiPathToXPath(u.GetPath(), false), e.GetCode(), e.GetMessage()))
+		}
+	}
+	if e := formatters.SetResponseError(rsp); e != nil {
+		issues = append(issues, fmt.Sprintf("set response error: code %d: %s", e.GetCode(), e.GetMessage()))
+	}
+	if numOps, numResults := setOperationsCount(req), len(rsp.GetResponse()); numResults != numOps {
+		issues = append(issues, fmt.Sprintf("received %d result(s) for %d operation(s) sent", numResults, numOps))
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(issues, "; "))
+}
@@ -0,0 +1,224 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setResponseServer is a gNMI server replying to all Set requests with rsp or err.
+type setResponseServer struct {
+	gnmi.UnimplementedGNMIServer
+	rsp *gnmi.SetResponse
+	err error
+}
+
+func (s *setResponseServer) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	return s.rsp, s.err
+}
+
+func TestSetResponseOutput(t *testing.T) {
+	req := &gnmi.SetRequest{
+		Delete: []*gnmi.Path{mustParsePath(t, "/interfaces/interface[name=e2]")},
+		Update: []*gnmi.Update{
+			{
+				Path: mustParsePath(t, "/interfaces/interface[name=e1]/config/mtu"),
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 9000}},
+			},
+		},
+	}
+	deleteResult := &gnmi.UpdateResult{
+		Path: mustParsePath(t, "/interfaces/interface[name=e2]"),
+		Op:   gnmi.UpdateResult_DELETE,
+	}
+	updateResult := &gnmi.UpdateResult{
+		Path: mustParsePath(t, "/interfaces/interface[name=e1]/config/mtu"),
+		Op:   gnmi.UpdateResult_UPDATE,
+	}
+	failedUpdateResult := &gnmi.UpdateResult{
+		Path:    mustParsePath(t, "/interfaces/interface[name=e1]/config/mtu"),
+		Op:      gnmi.UpdateResult_UPDATE,
+		Message: &gnmi.Error{Code: uint32(codes.InvalidArgument), Message: "mtu out of range"},
+	}
+	invalidArg, err := status.New(codes.InvalidArgument, "invalid value").
+		WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "mtu", Description: "out of range"},
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		format  string
+		rsp     *gnmi.SetResponse
+		err     error
+		want    []string
+		wantErr string
+	}{
+		"success_json": {
+			format: formatJSON,
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Response:  []*gnmi.UpdateResult{deleteResult, updateResult},
+			},
+			want: []string{
+				`"timestamp":1700000000000000000`,
+				`{"operation":"DELETE","path":"interfaces/interface[name=e2]","result":"ok"}`,
+				`{"operation":"UPDATE","path":"interfaces/interface[name=e1]/config/mtu","result":"ok"}`,
+			},
+		},
+		"success_flat": {
+			format: "flat",
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Response:  []*gnmi.UpdateResult{deleteResult, updateResult},
+			},
+			want: []string{
+				"timestamp: 1700000000000000000 (",
+				"DELETE interfaces/interface[name=e2]: ok\n",
+				"UPDATE interfaces/interface[name=e1]/config/mtu: ok\n",
+			},
+		},
+		"per_op_failure_json": {
+			format: formatJSON,
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Response:  []*gnmi.UpdateResult{deleteResult, failedUpdateResult},
+			},
+			want: []string{
+				`{"operation":"DELETE","path":"interfaces/interface[name=e2]","result":"ok"}`,
+				`{"error":{"code":3,"message":"mtu out of range"},"operation":"UPDATE","path":"interfaces/interface[name=e1]/config/mtu","result":"failed"}`,
+			},
+			wantErr: `UPDATE "interfaces/interface[name=e1]/config/mtu" failed: code 3: mtu out of range`,
+		},
+		"per_op_failure_flat": {
+			format: "flat",
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Response:  []*gnmi.UpdateResult{deleteResult, failedUpdateResult},
+			},
+			want: []string{
+				"UPDATE interfaces/interface[name=e1]/config/mtu: failed: code 3: mtu out of range\n",
+			},
+			wantErr: `UPDATE "interfaces/interface[name=e1]/config/mtu" failed: code 3: mtu out of range`,
+		},
+		"missing_results": {
+			format: formatJSON,
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Response:  []*gnmi.UpdateResult{deleteResult},
+			},
+			want: []string{
+				`{"operation":"DELETE","path":"interfaces/interface[name=e2]","result":"ok"}`,
+			},
+			wantErr: "received 1 result(s) for 2 operation(s) sent",
+		},
+		"message_error": {
+			format: formatJSON,
+			rsp: &gnmi.SetResponse{
+				Timestamp: 1700000000000000000,
+				Message:   &gnmi.Error{Code: uint32(codes.Internal), Message: "commit failed"},
+			},
+			want: []string{
+				`"error":{"code":13,"message":"commit failed"}`,
+			},
+			wantErr: "set response error: code 13: commit failed",
+		},
+		"rpc_error": {
+			format: formatJSON,
+			err:    invalidArg.Err(),
+			want: []string{
+				`"code":"InvalidArgument"`,
+				`"message":"invalid value"`,
+				`"@type":"type.googleapis.com/google.rpc.BadRequest"`,
+				`"field":"mtu"`,
+			},
+			wantErr: "set request failed",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, &setResponseServer{rsp: item.rsp, err: item.err})
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.Config.Format = item.format
+			out := new(bytes.Buffer)
+			a.out = out
+			a.errCh = make(chan error, 1)
+			a.createCollectorDialOpts()
+			insecure := true
+			username, password := "admin", "admin"
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Username: &username,
+				Password: &password,
+				Timeout:  5 * time.Second,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			a.setRequest(ctx, tc, req)
+			close(a.errCh)
+
+			var output string
+			if item.format == formatJSON {
+				// compact the output to match the expected fragments
+				cb := new(bytes.Buffer)
+				err = json.Compact(cb, out.Bytes())
+				if err != nil {
+					t.Fatalf("output is not JSON: %q: %v", out.String(), err)
+				}
+				output = cb.String()
+			} else {
+				output = out.String()
+			}
+			for _, w := range item.want {
+				if !strings.Contains(output, w) {
+					t.Logf("failed at item %q", name)
+					t.Logf("expected output to contain: %s", w)
+					t.Logf("                       got: %s", output)
+					t.Fail()
+				}
+			}
+			err = <-a.errCh
+			if item.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if item.wantErr != "" && (err == nil || !strings.Contains(err.Error(), item.wantErr)) {
+				t.Fatalf("expected an error containing %q, got: %v", item.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+)
+
+// names of the leaves looked up, in order, as the key of the list entries
+// split with --split-depth 2.
+var splitListKeys = []string{"name", "id", "index"}
+
+// splitSetDeleteWarning is printed with --dry-run, the deletions of a root-level replace
+// are lost when it is split.
+const splitSetDeleteWarning = "warning: unlike a root-level replace, the split requests do not delete the containers, members or list entries absent from the payload"
+
+// setChunk is one of the replace requests a root-level replace is split into.
+type setChunk struct {
+	// xpath of the replaced container, list entry or leaf
+	name string
+	req  *gnmi.SetRequest
+}
+
+// splitSetResult is the outcome of the split requests sent to a target.
+type splitSetResult struct {
+	total   int
+	applied []string
+	failed  []string
+	notSent []string
+}
+
+// setSplitRequests splits the set requests of each target by container, then sends them
+// sequentially to each target, the targets are handled concurrently.
+// A summary of the applied, failed and not sent requests is printed per target.
+func (a *App) setSplitRequests(ctx context.Context, targets []*types.TargetConfig, targetsReqs map[string][]*gnmi.SetRequest) error {
+	targetsChunks := make(map[string][]*setChunk, len(targets))
+	numChunks := 0
+	for _, tc := range targets {
+		chunks, err := splitSetRequests(targetsReqs[tc.Name], a.Config.LocalFlags.SetSplitDepth)
+		if err != nil {
+			return fmt.Errorf("target %q: %v", tc.Name, err)
+		}
+		targetsChunks[tc.Name] = chunks
+		numChunks += len(chunks)
+	}
+	a.errCh = make(chan error, numChunks*2)
+	if a.Config.SetDryRun {
+		fmt.Fprintln(os.Stderr, splitSetDeleteWarning)
+		// print the planned requests sequence grouped by target
+		for _, tc := range targets {
+			a.sendSetChunks(ctx, tc, targetsChunks[tc.Name])
+		}
+		return a.checkErrors()
+	}
+	a.wg.Add(len(targets))
+	for _, tc := range targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			r := a.sendSetChunks(ctx, tc, targetsChunks[tc.Name])
+			a.printLock.Lock()
+			defer a.printLock.Unlock()
+			a.clearProgressBar()
+			printSplitSetSummary(os.Stderr, tc.Name, r)
+		}(tc)
+	}
+	a.wg.Wait()
+	return a.checkErrors()
+}
+
+// sendSetChunks sends the chunks sequentially to target tc, their progress is printed to stderr.
+// It stops at the first failed request, unless --continue-on-split-error is set.
+func (a *App) sendSetChunks(ctx context.Context, tc *types.TargetConfig, chunks []*setChunk) *splitSetResult {
+	r := &splitSetResult{total: len(chunks)}
+	for i, ch := range chunks {
+		a.printLock.Lock()
+		a.clearProgressBar()
+		fmt.Fprintf(os.Stderr, "target %q: [%d/%d] replace %s\n", tc.Name, i+1, len(chunks), ch.name)
+		a.printLock.Unlock()
+		err := a.setRequest(ctx, tc, ch.req)
+		if err != nil {
+			r.failed = append(r.failed, fmt.Sprintf("%s: %v", ch.name, err))
+			if a.Config.LocalFlags.SetContinueOnSplitError {
+				continue
+			}
+			for _, next := range chunks[i+1:] {
+				r.notSent = append(r.notSent, next.name)
+			}
+			break
+		}
+		r.applied = append(r.applied, ch.name)
+	}
+	a.progressDone(tc.Name, len(r.failed) > 0)
+	return r
+}
+
+// printSplitSetSummary writes to w the applied, failed and not sent requests of target name,
+// for the operator to know the state the target was left in.
+func printSplitSetSummary(w io.Writer, name string, r *splitSetResult) {
+	fmt.Fprintf(w, "target %q: split set: %d/%d request(s) applied", name, len(r.applied), r.total)
+	if len(r.failed) > 0 {
+		fmt.Fprintf(w, ", %d failed", len(r.failed))
+	}
+	if len(r.notSent) > 0 {
+		fmt.Fprintf(w, ", %d not sent", len(r.notSent))
+	}
+	fmt.Fprintln(w)
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{title: "applied", items: r.applied},
+		{title: "failed", items: r.failed},
+		{title: "not sent", items: r.notSent},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  %s:\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(w, "    %s\n", item)
+		}
+	}
+}
+
+// splitSetRequests splits each of reqs with splitSetRequest.
+func splitSetRequests(reqs []*gnmi.SetRequest, depth int) ([]*setChunk, error) {
+	chunks := make([]*setChunk, 0)
+	for _, req := range reqs {
+		rcs, err := splitSetRequest(req, depth)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, rcs...)
+	}
+	return chunks, nil
+}
+
+// splitSetRequest splits req, holding a single root-level replace with a JSON value,
+// into one replace request per top-level member of the value.
+// With depth 2, the top-level containers are split further into one replace per member,
+// and the lists into one replace per entry, keyed by one of splitListKeys.
+// The members are split in alphabetical order, the list entries in their payload order.
+func splitSetRequest(req *gnmi.SetRequest, depth int) ([]*setChunk, error) {
+	if len(req.GetReplace()) != 1 || len(req.GetUpdate()) > 0 || len(req.GetDelete()) > 0 {
+		return nil, errors.New("only a set request with a single root-level replace can be split by container")
+	}
+	upd := req.GetReplace()[0]
+	if p := joinPaths(req.GetPrefix(), upd.GetPath()); len(p.GetElem()) > 0 {
+		return nil, fmt.Errorf("replace path %q is not the root path, it cannot be split by container", utils.GnmiPathToXPath(p, false))
+	}
+	root, ok := splitJSONObject(upd.GetVal())
+	if !ok {
+		return nil, errors.New("the root-level replace value must be a JSON or JSON_IETF object to be split by container")
+	}
+	var chunks []*setChunk
+	add := func(v interface{}, elems ...*gnmi.PathElem) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		val := &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: b}}
+		if _, ok := upd.GetVal().GetValue().(*gnmi.TypedValue_JsonIetfVal); ok {
+			val.Value = &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: b}
+		}
+		chunks = append(chunks, &setChunk{
+			name: utils.GnmiPathToXPath(&gnmi.Path{Elem: elems}, false),
+			req: &gnmi.SetRequest{
+				Prefix: req.GetPrefix(),
+				Replace: []*gnmi.Update{{
+					Path: &gnmi.Path{Origin: upd.GetPath().GetOrigin(), Elem: elems},
+					Val:  val,
+				}},
+				Extension: req.GetExtension(),
+			},
+		})
+		return nil
+	}
+	for _, name := range sortedKeys(root) {
+		top := &gnmi.PathElem{Name: name}
+		container, ok := root[name].(map[string]interface{})
+		if depth < 2 || !ok || len(container) == 0 {
+			if err := add(root[name], top); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, member := range sortedKeys(container) {
+			entries, ok := container[member].([]interface{})
+			key := splitListKey(entries)
+			if !ok || key == "" {
+				if err := add(container[member], top, &gnmi.PathElem{Name: member}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			for _, e := range entries {
+				kv, _ := jsonMember(e.(map[string]interface{}), key)
+				list := &gnmi.PathElem{Name: member, Key: map[string]string{key: jsonKeyString(kv)}}
+				if err := add(e, top, list); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// splitJSONObject decodes the JSON object of tv,
+// the numbers are kept as json.Number to be encoded back as is.
+func splitJSONObject(tv *gnmi.TypedValue) (map[string]interface{}, bool) {
+	var b []byte
+	switch tv.GetValue().(type) {
+	case *gnmi.TypedValue_JsonVal:
+		b = tv.GetJsonVal()
+	case *gnmi.TypedValue_JsonIetfVal:
+		b = tv.GetJsonIetfVal()
+	default:
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// splitListKey returns the first of splitListKeys set with a scalar value in all the entries,
+// or an empty string if entries is not a list of objects or if no such key is found.
+func splitListKey(entries []interface{}) string {
+	if len(entries) == 0 {
+		return ""
+	}
+KEYS:
+	for _, key := range splitListKeys {
+		for _, e := range entries {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			v, ok := jsonMember(obj, key)
+			if !ok {
+				continue KEYS
+			}
+			switch v.(type) {
+			case string, json.Number, bool:
+			default:
+				continue KEYS
+			}
+		}
+		return key
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
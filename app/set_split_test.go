@@ -0,0 +1,231 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func rootReplace(val string) *gnmi.SetRequest {
+	return &gnmi.SetRequest{
+		Replace: []*gnmi.Update{{
+			Path: &gnmi.Path{},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(val)}},
+		}},
+	}
+}
+
+func TestSplitSetRequest(t *testing.T) {
+	tests := map[string]struct {
+		req       *gnmi.SetRequest
+		depth     int
+		wantNames []string
+		wantVals  []string
+		wantErr   bool
+	}{
+		"depth_1": {
+			req:       rootReplace(`{"system":{"hostname":"r1"},"interfaces":{"interface":[{"name":"e1"}]}}`),
+			depth:     1,
+			wantNames: []string{"interfaces", "system"},
+			wantVals:  []string{`{"interface":[{"name":"e1"}]}`, `{"hostname":"r1"}`},
+		},
+		"depth_2_list_entries": {
+			req:       rootReplace(`{"interfaces":{"interface":[{"name":"e1","mtu":9000},{"name":"e2"}]},"system":{"hostname":"r1"}}`),
+			depth:     2,
+			wantNames: []string{"interfaces/interface[name=e1]", "interfaces/interface[name=e2]", "system/hostname"},
+			wantVals:  []string{`{"mtu":9000,"name":"e1"}`, `{"name":"e2"}`, `"r1"`},
+		},
+		"depth_2_list_without_key": {
+			req:       rootReplace(`{"acl":{"entry":[{"seq":10},{"seq":20}]}}`),
+			depth:     2,
+			wantNames: []string{"acl/entry"},
+			wantVals:  []string{`[{"seq":10},{"seq":20}]`},
+		},
+		"large_numbers_kept": {
+			req:       rootReplace(`{"bgp":{"router-id":18446744073709551615}}`),
+			depth:     1,
+			wantNames: []string{"bgp"},
+			wantVals:  []string{`{"router-id":18446744073709551615}`},
+		},
+		"not_root": {
+			req: &gnmi.SetRequest{
+				Replace: []*gnmi.Update{{
+					Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}}},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: []byte(`{}`)}},
+				}},
+			},
+			depth:   1,
+			wantErr: true,
+		},
+		"other_operations": {
+			req: &gnmi.SetRequest{
+				Replace: rootReplace(`{"system":{}}`).Replace,
+				Delete:  []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "acl"}}}},
+			},
+			depth:   1,
+			wantErr: true,
+		},
+		"not_json": {
+			req: &gnmi.SetRequest{
+				Replace: []*gnmi.Update{{
+					Path: &gnmi.Path{},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "config"}},
+				}},
+			},
+			depth:   1,
+			wantErr: true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			chunks, err := splitSetRequest(item.req, item.depth)
+			if item.wantErr != (err != nil) {
+				t.Fatalf("failed at item %q: unexpected error: %v", name, err)
+			}
+			names := make([]string, 0, len(chunks))
+			vals := make([]string, 0, len(chunks))
+			for _, ch := range chunks {
+				names = append(names, ch.name)
+				upd := ch.req.GetReplace()[0]
+				if got := utils.GnmiPathToXPath(upd.GetPath(), false); got != ch.name {
+					t.Errorf("failed at item %q: expected the replace path %q, got %q", name, ch.name, got)
+				}
+				vals = append(vals, string(upd.GetVal().GetJsonIetfVal()))
+			}
+			if !reflect.DeepEqual(names, item.wantNames) && len(item.wantNames)+len(names) > 0 {
+				t.Errorf("failed at item %q: expected names %q, got %q", name, item.wantNames, names)
+			}
+			if !reflect.DeepEqual(vals, item.wantVals) && len(item.wantVals)+len(vals) > 0 {
+				t.Errorf("failed at item %q: expected values %q, got %q", name, item.wantVals, vals)
+			}
+		})
+	}
+}
+
+// splitSetServer rejects the Set requests replacing the path fail,
+// the replaced paths are recorded in the order they are received.
+type splitSetServer struct {
+	gnmi.UnimplementedGNMIServer
+	fail     string
+	mu       sync.Mutex
+	received []string
+}
+
+func (s *splitSetServer) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	p := utils.GnmiPathToXPath(req.GetReplace()[0].GetPath(), false)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, p)
+	if p == s.fail {
+		return nil, status.Error(codes.InvalidArgument, "commit failed")
+	}
+	// one result per operation received
+	rsp := new(gnmi.SetResponse)
+	for _, del := range req.GetDelete() {
+		rsp.Response = append(rsp.Response, &gnmi.UpdateResult{Path: del, Op: gnmi.UpdateResult_DELETE})
+	}
+	for _, upd := range req.GetReplace() {
+		rsp.Response = append(rsp.Response, &gnmi.UpdateResult{Path: upd.GetPath(), Op: gnmi.UpdateResult_REPLACE})
+	}
+	for _, upd := range req.GetUpdate() {
+		rsp.Response = append(rsp.Response, &gnmi.UpdateResult{Path: upd.GetPath(), Op: gnmi.UpdateResult_UPDATE})
+	}
+	return rsp, nil
+}
+
+func TestSendSetChunks(t *testing.T) {
+	tests := map[string]struct {
+		continueOnError bool
+		wantReceived    []string
+		wantSummary     []string
+	}{
+		"stop_at_first_failure": {
+			wantReceived: []string{"acl", "interfaces"},
+			wantSummary: []string{
+				`target "t1": split set: 1/3 request(s) applied, 1 failed, 1 not sent`,
+				"  applied:\n    acl\n",
+				"  failed:\n    interfaces: ",
+				"  not sent:\n    system\n",
+			},
+		},
+		"continue_on_error": {
+			continueOnError: true,
+			wantReceived:    []string{"acl", "interfaces", "system"},
+			wantSummary: []string{
+				`target "t1": split set: 2/3 request(s) applied, 1 failed`,
+				"  applied:\n    acl\n    system\n",
+			},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &splitSetServer{fail: "interfaces"}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, srv)
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			defer a.Cfn()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.Config.LocalFlags.SetContinueOnSplitError = item.continueOnError
+			a.out = new(bytes.Buffer)
+			a.createCollectorDialOpts()
+			insecure := true
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Timeout:  5 * time.Second,
+			}
+			chunks, err := splitSetRequest(rootReplace(`{"system":{},"acl":{},"interfaces":{}}`), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.errCh = make(chan error, len(chunks)*2)
+			r := a.sendSetChunks(a.ctx, tc, chunks)
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if !reflect.DeepEqual(srv.received, item.wantReceived) {
+				t.Errorf("failed at item %q: expected the requests %q, got %q", name, item.wantReceived, srv.received)
+			}
+			if err := a.checkErrors(); err == nil {
+				t.Errorf("failed at item %q: expected an error", name)
+			}
+			summary := new(strings.Builder)
+			printSplitSetSummary(summary, tc.Name, r)
+			for _, s := range item.wantSummary {
+				if !strings.Contains(summary.String(), s) {
+					t.Errorf("failed at item %q: expected the summary to contain %q, got:\n%s", name, s, summary.String())
+				}
+			}
+		})
+	}
+}
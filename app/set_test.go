@@ -0,0 +1,122 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// setServer is a gNMI server that keeps the last received SetRequest.
+type setServer struct {
+	gnmi.UnimplementedGNMIServer
+	reqCh chan *gnmi.SetRequest
+}
+
+func (s *setServer) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	s.reqCh <- req
+	return &gnmi.SetResponse{}, nil
+}
+
+func TestSetUpdateBytesFile(t *testing.T) {
+	data := make([]byte, 256*1024)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err = os.WriteFile(fileName, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &setServer{reqCh: make(chan *gnmi.SetRequest, 1)}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	cfg := config.New()
+	cfg.MaxMsgSize = msgSize
+	cfg.LocalFlags.SetDelimiter = ":::"
+	cfg.LocalFlags.SetBytesFile = []string{"/system/image:::" + fileName}
+	reqs, err := cfg.CreateSetRequest("t1")
+	if err != nil {
+		t.Fatalf("failed to create set request: %v", err)
+	}
+
+	insecure := true
+	tg := target.NewTarget(&types.TargetConfig{
+		Name:     "t1",
+		Address:  l.Addr().String(),
+		Insecure: &insecure,
+		Timeout:  5 * time.Second,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx)
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+	_, err = tg.Set(ctx, reqs[0])
+	if err != nil {
+		t.Fatalf("set request failed: %v", err)
+	}
+
+	req := <-srv.reqCh
+	if len(req.GetUpdate()) != 1 {
+		t.Fatalf("expected 1 update, got %d", len(req.GetUpdate()))
+	}
+	got := sha256.Sum256(req.GetUpdate()[0].GetVal().GetBytesVal())
+	if got != sha256.Sum256(data) {
+		t.Errorf("received bytes value checksum does not match the file checksum")
+	}
+
+	preview := previewBytesValues(req).GetUpdate()[0].GetVal().GetStringVal()
+	if !strings.HasPrefix(preview, "base64:") || !strings.HasSuffix(preview, "(262144 bytes)") {
+		t.Errorf("unexpected bytes value preview: %q", preview)
+	}
+	if req.GetUpdate()[0].GetVal().GetBytesVal() == nil {
+		t.Errorf("previewBytesValues modified the original request")
+	}
+}
+
+func TestSetUpdateBytesFileMaxSize(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "file.bin")
+	err := os.WriteFile(fileName, make([]byte, 1024), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.New()
+	cfg.MaxMsgSize = 512
+	cfg.LocalFlags.SetDelimiter = ":::"
+	cfg.LocalFlags.SetBytesFile = []string{"/system/image:::" + fileName}
+	_, err = cfg.CreateSetRequest("t1")
+	if err == nil {
+		t.Errorf("expected an error for a file larger than max-msg-size")
+	}
+}
@@ -0,0 +1,489 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// yangViolation is a set request value not matching the YANG schema,
+// Pointer is the JSON pointer to the value in the update payload.
+type yangViolation struct {
+	Op      string // update or replace
+	Path    string
+	Pointer string
+	Msg     string
+}
+
+func (v *yangViolation) String() string {
+	if v.Pointer == "" {
+		return fmt.Sprintf("%s %q: %s", v.Op, v.Path, v.Msg)
+	}
+	return fmt.Sprintf("%s %q: %s: %s", v.Op, v.Path, v.Pointer, v.Msg)
+}
+
+// validateSetRequests validates the set requests updates and replaces of all targets
+// against the YANG schema, all the violations are printed before returning an error.
+func (a *App) validateSetRequests() error {
+	err := a.generateYangSchema(a.Config.GlobalFlags.Dir, a.Config.GlobalFlags.File, a.Config.GlobalFlags.Exclude)
+	if err != nil {
+		return err
+	}
+	if a.SchemaTree == nil {
+		return errors.New("no YANG modules loaded, check the --file flag value")
+	}
+	names := make([]string, 0, len(a.Config.Targets))
+	for n := range a.Config.Targets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	// the requests built from flags are the same for all targets,
+	// their violations are reported once.
+	seen := make(map[string]struct{})
+	msgs := make([]string, 0)
+	for _, n := range names {
+		reqs, skipped, err := a.Config.CreateSetRequestForValidation(n)
+		if err != nil {
+			return fmt.Errorf("target %q: failed to create set request: %v", n, err)
+		}
+		for _, req := range reqs {
+			for _, v := range a.validateSetRequest(req, skipped) {
+				msg := v.String()
+				if len(a.Config.SetRequestFile) > 0 {
+					msg = fmt.Sprintf("target %q: %s", n, msg)
+				}
+				if _, ok := seen[msg]; ok {
+					continue
+				}
+				seen[msg] = struct{}{}
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	for _, msg := range msgs {
+		fmt.Fprintf(os.Stderr, "yang validation error: %s\n", msg)
+	}
+	return fmt.Errorf("set request YANG validation failed with %d error(s)", len(msgs))
+}
+
+// validateSetRequest returns the violations found in the replaces and updates of req,
+// the updates present in skipped are not validated.
+func (a *App) validateSetRequest(req *gnmi.SetRequest, skipped map[*gnmi.Update]bool) []*yangViolation {
+	vs := make([]*yangViolation, 0)
+	for _, upd := range req.GetReplace() {
+		if skipped[upd] {
+			continue
+		}
+		vs = append(vs, a.validateUpdate("replace", req.GetPrefix(), upd)...)
+	}
+	for _, upd := range req.GetUpdate() {
+		if skipped[upd] {
+			continue
+		}
+		vs = append(vs, a.validateUpdate("update", req.GetPrefix(), upd)...)
+	}
+	return vs
+}
+
+func (a *App) validateUpdate(op string, prefix *gnmi.Path, upd *gnmi.Update) []*yangViolation {
+	vv := &yangValidator{
+		root: a.SchemaTree,
+		op:   op,
+		path: gnmiFullPathString(prefix, upd.GetPath()),
+	}
+	origin := prefix.GetOrigin()
+	if origin == "" {
+		origin = upd.GetPath().GetOrigin()
+	}
+	// CLI commands are not modeled
+	if origin != "" && origin == a.Config.LocalFlags.SetCliOrigin {
+		return nil
+	}
+	elems := make([]*gnmi.PathElem, 0, len(prefix.GetElem())+len(upd.GetPath().GetElem()))
+	elems = append(elems, prefix.GetElem()...)
+	elems = append(elems, upd.GetPath().GetElem()...)
+	var e *yang.Entry
+	listEntry := false
+	for _, pe := range elems {
+		name := stripModulePrefix(pe.GetName())
+		c := vv.schemaChild(e, name)
+		if c == nil {
+			vv.add("", vv.unknownNodeMsg(e, name))
+			return vv.violations
+		}
+		e = c
+		listEntry = false
+		if !e.IsList() || len(pe.GetKey()) == 0 {
+			continue
+		}
+		for k := range pe.GetKey() {
+			if vv.schemaChild(e, stripModulePrefix(k)) == nil {
+				vv.add("", fmt.Sprintf("unknown key %q in list %q", k, e.Name))
+			}
+		}
+		listEntry = true
+	}
+	v, ok, err := typedValueInterface(upd.GetVal())
+	if err != nil {
+		vv.add("", fmt.Sprintf("invalid JSON value: %v", err))
+		return vv.violations
+	}
+	if !ok {
+		return vv.violations
+	}
+	vv.validateValue(e, v, "", listEntry)
+	return vv.violations
+}
+
+// yangValidator validates a decoded JSON value against a YANG schema tree.
+// A nil *yang.Entry is the schema root, its children are the modules top-level nodes.
+type yangValidator struct {
+	root       *yang.Entry
+	op         string
+	path       string
+	violations []*yangViolation
+}
+
+func (vv *yangValidator) add(ptr, msg string) {
+	vv.violations = append(vv.violations, &yangViolation{
+		Op:      vv.op,
+		Path:    vv.path,
+		Pointer: ptr,
+		Msg:     msg,
+	})
+}
+
+// validateValue validates v against e, listEntry is true if v is a
+// single entry of list e whose keys are set in the path.
+func (vv *yangValidator) validateValue(e *yang.Entry, v interface{}, ptr string, listEntry bool) {
+	switch {
+	case e != nil && e.IsList() && !listEntry:
+		switch v := v.(type) {
+		case []interface{}:
+			for i, item := range v {
+				vv.validateListEntry(e, item, ptr+"/"+strconv.Itoa(i))
+			}
+		case map[string]interface{}:
+			vv.validateListEntry(e, v, ptr)
+		default:
+			vv.add(ptr, fmt.Sprintf("type mismatch: expected list %q, got %s", e.Name, jsonTypeName(v)))
+		}
+	case e == nil || e.Dir != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			vv.add(ptr, fmt.Sprintf("type mismatch: expected an object, got %s", jsonTypeName(v)))
+			return
+		}
+		vv.validateMembers(e, m, ptr)
+	case e.IsLeafList():
+		items, ok := v.([]interface{})
+		if !ok {
+			vv.add(ptr, fmt.Sprintf("type mismatch: expected leaf-list %q, got %s", e.Name, jsonTypeName(v)))
+			return
+		}
+		for i, item := range items {
+			if err := validateLeafValue(e.Type, item); err != nil {
+				vv.add(ptr+"/"+strconv.Itoa(i), err.Error())
+			}
+		}
+	default:
+		if err := validateLeafValue(e.Type, v); err != nil {
+			vv.add(ptr, err.Error())
+		}
+	}
+}
+
+func (vv *yangValidator) validateListEntry(e *yang.Entry, v interface{}, ptr string) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		vv.add(ptr, fmt.Sprintf("type mismatch: expected a %q list entry, got %s", e.Name, jsonTypeName(v)))
+		return
+	}
+	for _, k := range strings.Fields(e.Key) {
+		if !hasMember(m, k) {
+			vv.add(ptr, fmt.Sprintf("missing list key %q", k))
+		}
+	}
+	vv.validateMembers(e, m, ptr)
+}
+
+func (vv *yangValidator) validateMembers(e *yang.Entry, m map[string]interface{}, ptr string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// RFC 7952 metadata annotations
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		name := stripModulePrefix(k)
+		kptr := ptr + "/" + jsonPointerEscaper.Replace(k)
+		c := vv.schemaChild(e, name)
+		if c == nil {
+			vv.add(kptr, vv.unknownNodeMsg(e, name))
+			continue
+		}
+		vv.validateValue(c, m[k], kptr, false)
+	}
+}
+
+// schemaChild returns the child node name of e, looking through choices and cases.
+func (vv *yangValidator) schemaChild(e *yang.Entry, name string) *yang.Entry {
+	if e != nil {
+		return findSchemaChild(e, name)
+	}
+	for _, mod := range vv.root.Dir {
+		if c := findSchemaChild(mod, name); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func (vv *yangValidator) unknownNodeMsg(e *yang.Entry, name string) string {
+	names := make([]string, 0)
+	if e != nil {
+		names = schemaChildNames(e, names)
+	} else {
+		for _, mod := range vv.root.Dir {
+			names = schemaChildNames(mod, names)
+		}
+	}
+	matches := closeMatches(name, names, 3)
+	if len(matches) == 0 {
+		return fmt.Sprintf("unknown node %q", name)
+	}
+	return fmt.Sprintf("unknown node %q, did you mean %q ?", name, strings.Join(matches, `", "`))
+}
+
+func findSchemaChild(e *yang.Entry, name string) *yang.Entry {
+	if c, ok := e.Dir[name]; ok && !c.IsChoice() && !c.IsCase() {
+		return c
+	}
+	for _, c := range e.Dir {
+		if !c.IsChoice() && !c.IsCase() {
+			continue
+		}
+		if gc := findSchemaChild(c, name); gc != nil {
+			return gc
+		}
+	}
+	return nil
+}
+
+func schemaChildNames(e *yang.Entry, names []string) []string {
+	for n, c := range e.Dir {
+		if c.IsChoice() || c.IsCase() {
+			names = schemaChildNames(c, names)
+			continue
+		}
+		names = append(names, n)
+	}
+	return names
+}
+
+// validateLeafValue returns an error if v is not a valid JSON encoded value
+// of YANG type t, as described in RFC 7951.
+func validateLeafValue(t *yang.YangType, v interface{}) error {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yint64,
+		yang.Yuint8, yang.Yuint16, yang.Yuint32, yang.Yuint64:
+		s, err := numberString(t, v)
+		if err != nil {
+			return err
+		}
+		n, err := yang.ParseInt(s)
+		if err != nil {
+			return fmt.Errorf("invalid %s value %s", t.Kind, s)
+		}
+		if !t.Range.Contains(yang.YangRange{{Min: n, Max: n}}) {
+			return fmt.Errorf("%s value %s out of range %s", t.Kind, s, t.Range)
+		}
+	case yang.Ydecimal64:
+		s, err := numberString(t, v)
+		if err != nil {
+			return err
+		}
+		n, err := yang.ParseDecimal(s, uint8(t.FractionDigits))
+		if err != nil {
+			return fmt.Errorf("invalid %s value %s: %v", t.Kind, s, err)
+		}
+		if !t.Range.Contains(yang.YangRange{{Min: n, Max: n}}) {
+			return fmt.Errorf("%s value %s out of range %s", t.Kind, s, t.Range)
+		}
+	case yang.Ybool:
+		if _, ok := v.(bool); !ok {
+			return typeMismatch(t, v)
+		}
+	case yang.Yempty:
+		if l, ok := v.([]interface{}); !ok || len(l) != 1 || l[0] != nil {
+			return fmt.Errorf("type mismatch: expected empty encoded as [null], got %s", jsonTypeName(v))
+		}
+	case yang.Yenum:
+		s, ok := v.(string)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		if t.Enum != nil && !t.Enum.IsDefined(s) {
+			return fmt.Errorf("unknown enum value %q, expected one of %s", s, strings.Join(t.Enum.Names(), ", "))
+		}
+	case yang.Yidentityref:
+		s, ok := v.(string)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		if t.IdentityBase == nil {
+			return nil
+		}
+		name := stripModulePrefix(s)
+		for _, id := range t.IdentityBase.Values {
+			if id.Name == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown identity %q, base %q", s, t.IdentityBase.Name)
+	case yang.Yunion:
+		for _, ut := range t.Type {
+			if validateLeafValue(ut, v) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %s does not match any of the union %q types", jsonValueString(v), t.Name)
+	case yang.Ystring:
+		s, ok := v.(string)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		l := yang.FromInt(int64(utf8.RuneCountInString(s)))
+		if !t.Length.Contains(yang.YangRange{{Min: l, Max: l}}) {
+			return fmt.Errorf("string length %s out of range %s", l, t.Length)
+		}
+	case yang.Ybinary, yang.Ybits, yang.YinstanceIdentifier:
+		if _, ok := v.(string); !ok {
+			return typeMismatch(t, v)
+		}
+	}
+	return nil
+}
+
+// numberString returns the string representation of a numeric value,
+// 64 bits integers and decimal64 values can be encoded as JSON strings.
+func numberString(t *yang.YangType, v interface{}) (string, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.String(), nil
+	case string:
+		switch t.Kind {
+		case yang.Yint64, yang.Yuint64, yang.Ydecimal64:
+			return v, nil
+		}
+	}
+	return "", typeMismatch(t, v)
+}
+
+func typeMismatch(t *yang.YangType, v interface{}) error {
+	return fmt.Errorf("type mismatch: expected %s, got %s %s", t.Kind, jsonTypeName(v), jsonValueString(v))
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonValueString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// typedValueInterface decodes the JSON and scalar typed values,
+// ok is false for the other value types.
+func typedValueInterface(tv *gnmi.TypedValue) (interface{}, bool, error) {
+	var b []byte
+	switch v := tv.GetValue().(type) {
+	case *gnmi.TypedValue_JsonVal:
+		b = v.JsonVal
+	case *gnmi.TypedValue_JsonIetfVal:
+		b = v.JsonIetfVal
+	case *gnmi.TypedValue_StringVal:
+		return v.StringVal, true, nil
+	case *gnmi.TypedValue_IntVal:
+		return json.Number(strconv.FormatInt(v.IntVal, 10)), true, nil
+	case *gnmi.TypedValue_UintVal:
+		return json.Number(strconv.FormatUint(v.UintVal, 10)), true, nil
+	case *gnmi.TypedValue_BoolVal:
+		return v.BoolVal, true, nil
+	case *gnmi.TypedValue_DoubleVal:
+		return json.Number(strconv.FormatFloat(v.DoubleVal, 'f', -1, 64)), true, nil
+	default:
+		return nil, false, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func hasMember(m map[string]interface{}, name string) bool {
+	for k := range m {
+		if stripModulePrefix(k) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stripModulePrefix removes the module name or prefix from a node name.
+func stripModulePrefix(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,125 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/config"
+	"github.com/openconfig/gnmic/utils"
+)
+
+func jsonIetfVal(s string) *gnmi.TypedValue {
+	return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(s)}}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		val  *gnmi.TypedValue
+		want []string
+	}{
+		"valid": {
+			path: "/interfaces/interface[name=e1]/config",
+			val:  jsonIetfVal(`{"mtu": 9000, "enabled": true, "tags": ["core"]}`),
+			want: []string{},
+		},
+		"valid_from_root": {
+			path: "/",
+			val:  jsonIetfVal(`{"test-interfaces:interfaces": {"interface": [{"name": "e1", "config": {"name": "e1"}}]}}`),
+			want: []string{},
+		},
+		"unknown_node": {
+			path: "/interfaces",
+			val:  jsonIetfVal(`{"interface": [{"name": "e1", "config": {"mtuu": 1500}}]}`),
+			want: []string{`replace "/interfaces": /interface/0/config/mtuu: unknown node "mtuu", did you mean "mtu" ?`},
+		},
+		"unknown_path": {
+			path: "/interfaces/interfac",
+			val:  jsonIetfVal(`{}`),
+			want: []string{`replace "/interfaces/interfac": unknown node "interfac", did you mean "interface" ?`},
+		},
+		"type_mismatch": {
+			path: "/interfaces/interface[name=e1]/config",
+			val:  jsonIetfVal(`{"mtu": "1500", "enabled": "true"}`),
+			want: []string{
+				`replace "/interfaces/interface[name=e1]/config": /enabled: type mismatch: expected boolean, got string "true"`,
+				`replace "/interfaces/interface[name=e1]/config": /mtu: type mismatch: expected uint16, got string "1500"`,
+			},
+		},
+		"out_of_range": {
+			path: "/interfaces/interface[name=e1]/config/mtu",
+			val:  jsonIetfVal(`70000`),
+			want: []string{`replace "/interfaces/interface[name=e1]/config/mtu": uint16 value 70000 out of range 0..65535`},
+		},
+		"missing_key": {
+			path: "/interfaces",
+			val:  jsonIetfVal(`{"interface": [{"name": "e1"}, {"config": {"name": "e2"}}]}`),
+			want: []string{`replace "/interfaces": /interface/1: missing list key "name"`},
+		},
+		"leaf_list_item": {
+			path: "/interfaces/interface[name=e1]/config/tags",
+			val:  jsonIetfVal(`["core", 1]`),
+			want: []string{`replace "/interfaces/interface[name=e1]/config/tags": /1: type mismatch: expected string, got number 1`},
+		},
+		"scalar_value": {
+			path: "/interfaces/interface[name=e1]/config/enabled",
+			val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "yes"}},
+			want: []string{`replace "/interfaces/interface[name=e1]/config/enabled": type mismatch: expected boolean, got string "yes"`},
+		},
+		"invalid_json": {
+			path: "/interfaces",
+			val:  jsonIetfVal(`{"interface": `),
+			want: []string{`replace "/interfaces": invalid JSON value: unexpected EOF`},
+		},
+	}
+	root := buildRootEntry()
+	e := generateTestEntry(t)
+	root.Dir[e.Name] = e
+	a := &App{Config: config.New(), SchemaTree: root}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := utils.ParsePath(item.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0)
+			for _, v := range a.validateUpdate("replace", nil, &gnmi.Update{Path: p, Val: item.val}) {
+				got = append(got, v.String())
+			}
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestValidateSetRequestSkipped(t *testing.T) {
+	root := buildRootEntry()
+	e := generateTestEntry(t)
+	root.Dir[e.Name] = e
+	a := &App{Config: config.New(), SchemaTree: root}
+	p, err := utils.ParsePath("/interfaces/interface[name=e1]/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upd := &gnmi.Update{Path: p, Val: jsonIetfVal(`{"vendor-leaf": 1}`)}
+	req := &gnmi.SetRequest{Update: []*gnmi.Update{upd}}
+	if vs := a.validateSetRequest(req, nil); len(vs) != 1 {
+		t.Errorf("expected 1 violation, got %d", len(vs))
+	}
+	if vs := a.validateSetRequest(req, map[*gnmi.Update]bool{upd: true}); len(vs) != 0 {
+		t.Errorf("expected the skipped update not to be validated, got %d violation(s)", len(vs))
+	}
+}
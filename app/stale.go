@@ -0,0 +1,79 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/target"
+)
+
+// actions taken when a target is stale
+const (
+	staleActionWarn      = "warn"
+	staleActionReconnect = "reconnect"
+)
+
+func validateStaleFlags(timeout time.Duration, action string) error {
+	if timeout < 0 {
+		return fmt.Errorf("flag --stale-timeout must not be negative")
+	}
+	switch action {
+	case staleActionWarn:
+	case staleActionReconnect:
+		if timeout == 0 {
+			return fmt.Errorf("flag --stale-action %s requires --stale-timeout", staleActionReconnect)
+		}
+	default:
+		return fmt.Errorf("unknown --stale-action %q, one of: %s, %s", action, staleActionWarn, staleActionReconnect)
+	}
+	return nil
+}
+
+// staleCheckTicker returns a ticker checking the staleness of a target,
+// or nil if --stale-timeout is not set or if target t has no stream subscription.
+func (a *App) staleCheckTicker(t *target.Target) *time.Ticker {
+	if a.Config.LocalFlags.SubscribeStaleTimeout <= 0 {
+		return nil
+	}
+	for _, sub := range t.Subscriptions {
+		mode := strings.ToUpper(sub.Mode)
+		if mode == "" || mode == "STREAM" {
+			return time.NewTicker(a.Config.LocalFlags.SubscribeStaleTimeout / 10)
+		}
+	}
+	return nil
+}
+
+// checkStaleTarget marks target t as degraded if no response was received from it
+// for --stale-timeout, since the later of its last response and lastCheck.
+// With --stale-action reconnect, the target subscriptions are re-established.
+// lastCheck is set to now when the target is found stale, so that the action
+// is repeated only once per stale timeout.
+func (a *App) checkStaleTarget(t *target.Target, now time.Time, lastCheck *time.Time) {
+	since := a.targetLastResponse(t.Config.Name)
+	if since.Before(*lastCheck) {
+		since = *lastCheck
+	}
+	silence := now.Sub(since)
+	if silence < a.Config.LocalFlags.SubscribeStaleTimeout {
+		return
+	}
+	*lastCheck = now
+	err := fmt.Errorf("no update or heartbeat received for %s", silence.Truncate(time.Second))
+	a.Logger.Printf("target %q: warning: %v", t.Config.Name, err)
+	subscribeStaleCounter.WithLabelValues(t.Config.Name).Add(1)
+	a.setTargetState(t.Config.Name, targetStateDegraded, err)
+	if a.Config.LocalFlags.SubscribeStaleAction == staleActionReconnect {
+		a.Logger.Printf("target %q: re-establishing the subscriptions", t.Config.Name)
+		t.ResetSubscriptions()
+	}
+}
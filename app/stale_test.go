@@ -0,0 +1,184 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// pausingServer is a gNMI server sending a single update on the first Subscribe stream
+// then pausing it, the next streams send an update every 50ms.
+type pausingServer struct {
+	gnmi.UnimplementedGNMIServer
+	numStreams int64
+}
+
+func (s *pausingServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	_, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	n := atomic.AddInt64(&s.numStreams, 1)
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(n)}},
+					},
+				},
+			},
+		},
+	}
+	err = stream.Send(rsp)
+	if err != nil {
+		return err
+	}
+	if n == 1 {
+		<-stream.Context().Done()
+		return nil
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+			err = stream.Send(rsp)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func TestStaleTargetReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := new(pausingServer)
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	a := New()
+	a.Logger = log.New(io.Discard, "", 0)
+	a.Config.LocalFlags.SubscribeStaleTimeout = 300 * time.Millisecond
+	a.Config.LocalFlags.SubscribeStaleAction = staleActionReconnect
+	a.createCollectorDialOpts()
+	insecure := true
+	tc := &types.TargetConfig{
+		Name:       "t1",
+		Address:    l.Addr().String(),
+		Insecure:   &insecure,
+		Timeout:    5 * time.Second,
+		RetryTimer: 100 * time.Millisecond,
+		BufferSize: 10,
+	}
+	tg := target.NewTarget(tc)
+	tg.Subscriptions["sub1"] = &types.SubscriptionConfig{Name: "sub1", Mode: "STREAM"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx, a.dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.setTargetState(tc.Name, targetStateSubscribed, nil)
+	go a.StartCollector(ctx)
+	defer close(a.targetsChan)
+	a.targetsChan <- tg
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{Mode: gnmi.SubscriptionList_STREAM},
+		},
+	}
+	go tg.Subscribe(ctx, req, "sub1")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&srv.numStreams) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the stale subscription was not re-established")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// the target is back to subscribed once the new stream sends updates
+	for {
+		tss := a.targetsStatusTable()
+		if len(tss) == 1 && tss[0].State == targetStateSubscribed && tss[0].Reconnects > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected target status: %+v", tss)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := atomic.LoadInt64(&srv.numStreams); n != 2 {
+		t.Errorf("expected 2 subscribe streams, got %d", n)
+	}
+}
+
+func TestValidateStaleFlags(t *testing.T) {
+	tests := map[string]struct {
+		timeout time.Duration
+		action  string
+		wantErr bool
+	}{
+		"disabled": {
+			action: staleActionWarn,
+		},
+		"warn": {
+			timeout: time.Minute,
+			action:  staleActionWarn,
+		},
+		"reconnect": {
+			timeout: time.Minute,
+			action:  staleActionReconnect,
+		},
+		"reconnect_without_timeout": {
+			action:  staleActionReconnect,
+			wantErr: true,
+		},
+		"negative_timeout": {
+			timeout: -time.Second,
+			action:  staleActionWarn,
+			wantErr: true,
+		},
+		"unknown_action": {
+			timeout: time.Minute,
+			action:  "restart",
+			wantErr: true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateStaleFlags(item.timeout, item.action)
+			if (err != nil) != item.wantErr {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected error: %v", item.wantErr)
+				t.Logf("           got: %v", err)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -33,6 +33,69 @@ const (
 
 func (a *App) SubscribePreRunE(cmd *cobra.Command, args []string) error {
 	a.Config.SetLocalFlagsFromFile(cmd)
+	var err error
+	a.Config.LocalFlags.SubscribePath, err = config.MergePaths(a.Context(), a.Config.LocalFlags.SubscribePath, a.Config.LocalFlags.SubscribePathFile)
+	if err != nil {
+		return err
+	}
+	if a.Config.LocalFlags.SubscribeValuesOnly {
+		if strings.ToUpper(a.Config.LocalFlags.SubscribeMode) != "ONCE" {
+			return errors.New("flag --values-only is only supported with --mode once")
+		}
+		if a.Config.Format != "" {
+			return fmt.Errorf("flag --values-only cannot be combined with --format %s", a.Config.Format)
+		}
+	}
+	if !a.Config.LocalFlags.SubscribeCache {
+		if a.Config.LocalFlags.SubscribeServerAddress != "" {
+			return errors.New("flag --server-address requires --cache")
+		}
+		if a.Config.LocalFlags.SubscribeCacheMaxEntries > 0 {
+			return errors.New("flag --cache-max-entries requires --cache")
+		}
+	}
+	if a.Config.LocalFlags.SubscribeTee && a.Config.LocalFlags.SubscribeQuiet {
+		return errors.New("flags --tee and --quiet are mutually exclusive")
+	}
+	if a.Config.LocalFlags.SubscribeMerge {
+		if strings.ToUpper(a.Config.LocalFlags.SubscribeMode) != "ONCE" {
+			return errors.New("flag --merge is only supported with --mode once")
+		}
+		if a.Config.LocalFlags.SubscribeValuesOnly {
+			return errors.New("flags --merge and --values-only are mutually exclusive")
+		}
+	} else if a.Config.LocalFlags.SubscribeOutputDir != "" {
+		return errors.New("flag --output-dir requires --merge")
+	}
+	err = validateStaleFlags(a.Config.LocalFlags.SubscribeStaleTimeout, a.Config.LocalFlags.SubscribeStaleAction)
+	if err != nil {
+		return err
+	}
+	err = validateDownsampleFlags(a.Config.LocalFlags.SubscribeDownsample, a.Config.LocalFlags.SubscribeDownsampleKeep)
+	if err != nil {
+		return err
+	}
+	err = validateDecomposeFlags(a.Config.LocalFlags.SubscribeDecomposeJSON, a.Config.LocalFlags.SubscribeKeepCompound, a.Config.LocalFlags.SubscribeNullAsDelete)
+	if err != nil {
+		return err
+	}
+	if !a.Config.LocalFlags.SubscribeDetectAnomalies && a.Config.LocalFlags.SubscribeDropOutOfOrder {
+		return errors.New("flag --drop-out-of-order requires --detect-anomalies")
+	}
+	if a.Config.LocalFlags.SubscribeAnomaliesMaxEntries < 1 {
+		return errors.New("flag --anomalies-max-entries must be at least 1")
+	}
+	a.sequences = nil
+	if a.Config.LocalFlags.SubscribeSequenceNumbers {
+		a.sequences = newSequencer()
+		a.Logger.Printf("numbering the received notifications, run-id=%s", a.sequences.runID)
+	}
+	if a.Config.LocalFlags.SubscribeAppend && !a.Config.LocalFlags.SubscribeAgain {
+		return errors.New("flag --append requires --again")
+	}
+	if a.Config.LocalFlags.SubscribeAgain && len(a.Config.LocalFlags.SubscribeName) > 0 {
+		return errors.New("flags --again and --name are mutually exclusive")
+	}
 	a.createCollectorDialOpts()
 	return nil
 }
@@ -45,7 +108,7 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 		return a.SubscribeRunPrompt(cmd, args)
 	}
 	//
-	subCfg, err := a.Config.GetSubscriptions(cmd)
+	subCfg, err := a.getSubscriptions(cmd)
 	if err != nil {
 		return fmt.Errorf("failed reading subscriptions config: %v", err)
 	}
@@ -74,6 +137,12 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	if len(subCfg) == 0 && numInputs == 0 {
 		return errors.New("no subscriptions or inputs configuration found")
 	}
+	if a.Config.LocalFlags.SubscribeExpandWildcards {
+		err = a.previewSubscribeWildcards(subCfg)
+		if err != nil {
+			return err
+		}
+	}
 	// only once mode subscriptions requested
 	if allSubscriptionsModeOnce(subCfg) {
 		return a.SubscribeRunONCE(cmd, args, subCfg)
@@ -92,10 +161,11 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	_, err = a.Config.GetTargets()
+	tcs, err := a.Config.GetTargets()
 	if errors.Is(err, config.ErrNoTargetsFound) {
 		if !a.Config.LocalFlags.SubscribeWatchConfig &&
-			len(a.Config.FileConfig.GetStringMap("loader")) == 0 &&
+			!a.Config.LocalFlags.SubscribeWatchFile &&
+			len(a.Config.Loader) == 0 &&
 			!a.Config.UseTunnelServer &&
 			numInputs == 0 {
 			return fmt.Errorf("failed reading targets config: %v", err)
@@ -103,6 +173,10 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	} else if err != nil {
 		return fmt.Errorf("failed reading targets config: %v", err)
 	}
+	err = a.Config.SetMissingCredentials(tcs)
+	if err != nil {
+		return err
+	}
 
 	//
 	for {
@@ -116,13 +190,22 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	}
 
 	a.startAPIServer()
+	a.startMetricsServer()
 	a.startGnmiServer()
 	go a.startCluster()
+	go a.handleStatusSignal(a.ctx)
+	if a.Config.FileConfig.ConfigFileUsed() != "" {
+		go a.handleReloadSignal(a.ctx, cmd)
+	}
+	defer a.startProgress("subscribe", len(a.Config.Targets))()
 	a.startIO()
 
 	if a.Config.LocalFlags.SubscribeWatchConfig {
 		go a.watchConfig()
 	}
+	if a.Config.LocalFlags.SubscribeWatchFile {
+		go a.watchAddressFile()
+	}
 
 	for range a.ctx.Done() {
 		return a.ctx.Err()
@@ -133,12 +216,14 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 //
 func (a *App) subscribeStream(ctx context.Context, tc *types.TargetConfig) {
 	defer a.wg.Done()
+	defer a.recoverTarget(tc.Name)
 	a.TargetSubscribeStream(ctx, tc)
 }
 
 func (a *App) subscribeOnce(ctx context.Context, tc *types.TargetConfig) {
 	defer a.wg.Done()
 	err := a.TargetSubscribeOnce(ctx, tc)
+	a.progressDone(tc.Name, err != nil)
 	if err != nil {
 		a.logError(err)
 	}
@@ -155,9 +240,13 @@ func (a *App) InitSubscribeFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribePrefix, "prefix", "", "", "subscribe request prefix")
 	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.SubscribePath, "path", "", []string{}, "subscribe request paths")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribePathFile, "path-file", "", "", "file with the subscribe request paths, one per line. Merged with the --path values")
 	//cmd.MarkFlagRequired("path")
 	cmd.Flags().Uint32VarP(&a.Config.LocalFlags.SubscribeQos, "qos", "q", 0, "qos marking")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeUpdatesOnly, "updates-only", "", false, "only updates to current state should be sent")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeCache, "cache", "", false, "store the received values in a local cache and serve them over gNMI")
+	cmd.Flags().Int64VarP(&a.Config.LocalFlags.SubscribeCacheMaxEntries, "cache-max-entries", "", 0, "maximum number of values stored in the local cache, the least recently written values are evicted first")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeServerAddress, "server-address", "", "", "address of the gNMI server serving the local cache, defaults to :57401")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeMode, "mode", "", "stream", "one of: once, stream, poll")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeStreamMode, "stream-mode", "", "target-defined", "one of: on-change, sample, target-defined")
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeSampleInterval, "sample-interval", "i", 0,
@@ -166,16 +255,64 @@ func (a *App) InitSubscribeFlags(cmd *cobra.Command) {
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeHeartbearInterval, "heartbeat-interval", "", 0, "heartbeat interval in case suppress-redundant is enabled")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeModel, "model", "", []string{}, "subscribe request used model(s)")
 	cmd.Flags().BoolVar(&a.Config.LocalFlags.SubscribeQuiet, "quiet", false, "suppress stdout printing")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeTee, "tee", "", false, "print the received messages to stdout in addition to the configured outputs, each output writes from its own queue")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeMaxClockSkew, "max-clock-skew", "", 5*time.Minute, "log a warning when a notification timestamp differs from the local receive time by more than this duration, 0 disables the check")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeOverrideTS, "override-timestamps", "", false, "replace the notifications timestamps with the local receive time")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeTarget, "target", "", "", "subscribe request target")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeSetTarget, "set-target", "", false, "set target name in gNMI Path prefix")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeName, "name", "n", []string{}, "reference subscriptions by name, must be defined in gnmic config file")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeOutput, "output", "", []string{}, "reference to output groups by name, must be defined in gnmic config file")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeOutputKeepalive, "output-keepalive", "", 0, "TCP keepalive period of the outputs referenced as tcp://host:port with --output")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeSyslogAddress, "syslog-address", "", "", "address of the syslog server used with --output syslog, formatted as udp://host:port or tcp://host:port, defaults to the local syslog server")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeSyslogTag, "syslog-tag", "", "", "syslog tag used with --output syslog, defaults to gnmic")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeESURL, "es-url", "", "", "Elasticsearch address used with --output elasticsearch, e.g: http://es:9200")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeESIndex, "es-index", "", "", "Elasticsearch index used with --output elasticsearch, the date patterns such as %{+yyyy.MM.dd} are replaced with the event date, defaults to gnmic-%{+yyyy.MM.dd}")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeOTLPEndpoint, "otlp-endpoint", "", "", "OTLP gRPC receiver address used with --output otlp, e.g: otel-collector:4317")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeOTLPInsecure, "otlp-insecure", "", false, "export to the --otlp-endpoint receiver in plaintext instead of TLS")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeWSListen, "ws-listen", "", "", "websocket server address and path used with --output ws, e.g: :8080/stream")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeWatchConfig, "watch-config", "", false, "watch configuration changes, add or delete subscribe targets accordingly")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeWatchFile, "watch-file", "", false, "watch the file set with --address-file, add or delete subscribe targets accordingly")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeValuesOnly, "values-only", "", false, "print the subscribe responses values only, one per line, requires --mode once")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeMerge, "merge", "", false, "merge the responses received until the sync response into a single JSON document per target, requires --mode once")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeMergeTimeout, "merge-timeout", "", time.Minute, "maximum time to wait for the sync response of a target with --merge, the document collected so far is then written with a partial marker")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeOutputDir, "output-dir", "", "", "directory where the merged documents are written as <target>.json, defaults to stdout")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeStaleTimeout, "stale-timeout", "", 0, "time without any update or heartbeat from a target with stream subscriptions after which the target is marked degraded, 0 disables the check")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeStaleAction, "stale-action", "", staleActionWarn, "action taken when a target is stale, one of: warn, reconnect")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeDownsample, "downsample", "", 0, "forward at most one update per target, subscription path and interval to the outputs, on-change updates and deletes are not downsampled, 0 disables downsampling")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeDownsampleKeep, "downsample-keep", "", downsampleKeepFirst, "update forwarded per downsample interval, one of: first, last")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeDecomposeJSON, "decompose-json", "", false, "replace the JSON and JSON_IETF updates holding a container or a list with one update per leaf")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeKeepCompound, "keep-compound", "", false, "with --decompose-json, also forward the original container and list updates")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeNullAsDelete, "null-as-delete", "", false, "with --decompose-json, forward the null JSON values as deletes")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeDetectAnomalies, "detect-anomalies", "", false, "log the updates with a timestamp older than or equal to the previous one of their target and path, counted in the --stats summary")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeDropOutOfOrder, "drop-out-of-order", "", false, "drop the out-of-order and duplicate timestamp updates before the outputs, requires --detect-anomalies")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.SubscribeAnomaliesMaxEntries, "anomalies-max-entries", "", defaultAnomaliesMaxEntries, "maximum number of paths tracked per target by --detect-anomalies, new paths are not tracked once reached")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeSequenceNumbers, "sequence-numbers", "", false, "number the received notifications per target with a seq field carried to the outputs, the highest sequence written by each output is reported in the --stats summary")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeExpandWildcards, "expand-wildcards", "", false, "before subscribing, discover the keys matching the wildcard keys of the subscriptions paths with a Get per target, print the expanded paths and ask for a confirmation")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.SubscribeExpandMax, "expand-max", "", defaultExpandMax, "maximum number of expanded paths printed per path and target by --expand-wildcards, 0 means no limit")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeYes, "yes", "", false, "proceed after the --expand-wildcards preview without asking for a confirmation")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeAgain, "again", "", false, "re-send the last subscribe request(s) built by gnmic, saved in ~/.gnmic/last-subscribe.json, the request flags set on the command line override the saved values")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeAppend, "append", "", false, "with --again, append the --path subscriptions to the saved ones instead of replacing them")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeLogConnState, "log-conn-state", "", false, "log the gRPC connection state transitions of each target, enabled by --debug")
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeBackoff, "backoff", "", 0, "backoff time between subscribe requests")
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeLockRetry, "lock-retry", "", 5*time.Second, "time to wait between target lock attempts")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeHistorySnapshot, "history-snapshot", "", "", "sets the snapshot time in a historical subscription, nanoseconds since Unix epoch or RFC3339 format")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeHistoryStart, "history-start", "", "", "sets the start time in a historical range subscription, nanoseconds since Unix epoch or RFC3339 format")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeHistoryEnd, "history-end", "", "", "sets the end time in a historical range subscription, nanoseconds since Unix epoch or RFC3339 format")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeCalculateRate, "calculate-rate", "", false, "convert numeric values to their per second rate of change using an event-rate processor applied to all outputs")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeMetricsAddress, "metrics-address", "", "", "address of an HTTP server exposing gnmic prometheus metrics under /metrics, e.g: :9805")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeDockerDiscovery, "docker-discovery", "", false, "discover targets from the containers of the local docker daemon")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeDockerAddress, "docker-address", "", "unix:///var/run/docker.sock", "docker daemon address used with --docker-discovery")
+	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.SubscribeDockerFilter, "docker-filter", "", []string{}, "docker containers filter used with --docker-discovery, e.g: label=clab-node-kind=srl")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeDockerPort, "docker-port", "", "label=gnmi-port", "gNMI port of the discovered containers, a port number or label=<label-name>")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeDockerInterval, "docker-interval", "", 30*time.Second, "interval between docker daemon queries, containers are added or removed as they start and stop")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeConsulAddress, "consul-address", "", "", "Consul server address used with --consul-service, defaults to localhost:8500")
+	cmd.Flags().StringArrayVarP(&a.Config.LocalFlags.SubscribeConsulService, "consul-service", "", []string{}, "discover targets from the instances of this Consul service")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeConsulToken, "consul-token", "", "", "Consul ACL token used with --consul-service")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeConsulTLSCaFile, "consul-tls-ca-file", "", "", "CA certificate file used to verify the Consul server certificate")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeConsulTLSCertFile, "consul-tls-cert-file", "", "", "client certificate file used for the connection to Consul")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeConsulTLSKeyFile, "consul-tls-key-file", "", "", "client key file used for the connection to Consul")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeConsulSkipVerify, "consul-tls-skip-verify", "", false, "skip the verification of the Consul server certificate")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeDockerProfile, "docker-profile", "", "", "name of a target profile, defined under target-profiles in the config file, used as configuration of the discovered targets")
 	//
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
@@ -225,9 +362,12 @@ func (a *App) handlePolledSubscriptions() {
 		waitChan := make(chan struct{}, 1)
 		waitChan <- struct{}{}
 		mo := &formatters.MarshalOptions{
-			Multiline: true,
-			Indent:    "  ",
-			Format:    a.Config.Format,
+			Multiline:   true,
+			Indent:      a.Config.JSONIndent,
+			Format:      a.Config.Format,
+			NumAsString: a.Config.NumAsString,
+			IncludeMeta: a.Config.IncludeMeta,
+			Location:    a.location,
 		}
 
 		for {
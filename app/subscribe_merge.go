@@ -0,0 +1,120 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/target"
+)
+
+// key added to a merged document when the target did not send
+// all the sync responses before the merge timeout or an error.
+const mergePartialKey = "partial"
+
+// subscribeOnceMerge sends the once subscriptions to target t and merges the received
+// notifications into a single document, written once all the sync responses are received.
+func (a *App) subscribeOnceMerge(ctx context.Context, t *target.Target, subRequests []subscriptionRequest) error {
+	var cancel context.CancelFunc
+	if a.Config.LocalFlags.SubscribeMergeTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, a.Config.LocalFlags.SubscribeMergeTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	doc := make(map[string]interface{})
+	complete, err := a.mergeOnceSubscriptions(ctx, t, subRequests, doc)
+	if !complete {
+		doc[mergePartialKey] = true
+	}
+	werr := a.writeMergedDocument(t.Config.Name, doc)
+	if err != nil {
+		return fmt.Errorf("target %q: %v", t.Config.Name, err)
+	}
+	if werr != nil {
+		return fmt.Errorf("target %q: failed to write the merged document: %v", t.Config.Name, werr)
+	}
+	return nil
+}
+
+// mergeOnceSubscriptions merges the notifications received for each subscription into doc
+// until its sync response. It returns true if all the sync responses were received.
+func (a *App) mergeOnceSubscriptions(ctx context.Context, t *target.Target, subRequests []subscriptionRequest, doc map[string]interface{}) (bool, error) {
+	for _, sreq := range subRequests {
+		a.Logger.Printf("sending gNMI SubscribeRequest: subscribe='%+v', mode='%+v', encoding='%+v', to %s",
+			sreq.req, sreq.req.GetSubscribe().GetMode(), sreq.req.GetSubscribe().GetEncoding(), t.Config.Name)
+		a.saveRequest(t.Config, saveRPCSubscribe, sreq.req)
+		a.auditRPC(t.Config.Name, auditRPCSubscribe, sreq.req, nil, nil, time.Now())
+		rspCh, errCh := t.SubscribeOnceChan(ctx, sreq.req)
+	RCV:
+		for {
+			select {
+			case <-ctx.Done():
+				return false, a.mergeTimeoutError(sreq.name)
+			case err := <-errCh:
+				if ctx.Err() != nil {
+					return false, a.mergeTimeoutError(sreq.name)
+				}
+				if errors.Is(err, io.EOF) {
+					return false, fmt.Errorf("subscription %q: stream closed before the sync response, the merged document is partial", sreq.name)
+				}
+				return false, fmt.Errorf("subscription %q: %v", sreq.name, err)
+			case rsp := <-rspCh:
+				switch rsp := rsp.Response.(type) {
+				case *gnmi.SubscribeResponse_SyncResponse:
+					a.Logger.Printf("target %q, subscription %q received sync response", t.Config.Name, sreq.name)
+					break RCV
+				case *gnmi.SubscribeResponse_Update:
+					err := mergeNotification(doc, rsp.Update)
+					if err != nil {
+						return false, fmt.Errorf("subscription %q: %v", sreq.name, err)
+					}
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+func (a *App) mergeTimeoutError(subName string) error {
+	return fmt.Errorf("subscription %q: no sync response received within %s, the merged document is partial",
+		subName, a.Config.LocalFlags.SubscribeMergeTimeout)
+}
+
+// writeMergedDocument writes the merged document of target name
+// to <output-dir>/<name>.json or to stdout.
+func (a *App) writeMergedDocument(name string, doc map[string]interface{}) error {
+	if a.Config.LocalFlags.SubscribeOutputDir != "" {
+		b, err := formatters.MarshalJSON(doc, defaultJSONIndent)
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(a.Config.LocalFlags.SubscribeOutputDir, backupDirPerm)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(a.Config.LocalFlags.SubscribeOutputDir, name+".json"), append(b, '\n'), backupFilePerm)
+	}
+	b, err := formatters.MarshalJSON(doc, a.Config.JSONIndent)
+	if err != nil {
+		return err
+	}
+	a.printLock.Lock()
+	defer a.printLock.Unlock()
+	return a.writeOutput(a.printPrefix(name, true), b)
+}
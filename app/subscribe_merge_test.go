@@ -0,0 +1,227 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"google.golang.org/grpc"
+)
+
+// onceServer is a gNMI server replying to a Subscribe request with its notifications,
+// followed by a sync response if sync is true.
+type onceServer struct {
+	gnmi.UnimplementedGNMIServer
+	notifications []*gnmi.Notification
+	sync          bool
+}
+
+func (s *onceServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	_, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	for _, n := range s.notifications {
+		err = stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}})
+		if err != nil {
+			return err
+		}
+	}
+	if !s.sync {
+		<-stream.Context().Done()
+		return nil
+	}
+	return stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+func mustParsePath(t *testing.T, p string) *gnmi.Path {
+	gp, err := utils.ParsePath(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gp
+}
+
+func TestSubscribeOnceMerge(t *testing.T) {
+	notifications := []*gnmi.Notification{
+		{
+			Prefix: mustParsePath(t, "/interfaces/interface[name=e1]"),
+			Update: []*gnmi.Update{
+				{
+					Path: mustParsePath(t, "/config"),
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: []byte(`{"mtu": 1500, "description": "old"}`)}},
+				},
+			},
+		},
+		{
+			Prefix: mustParsePath(t, "/interfaces/interface[name=e2]"),
+			Update: []*gnmi.Update{
+				{
+					Path: mustParsePath(t, "/config/mtu"),
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 9000}},
+				},
+			},
+		},
+		{
+			Delete: []*gnmi.Path{
+				mustParsePath(t, "/interfaces/interface[name=e1]/config/description"),
+				mustParsePath(t, "/interfaces/interface[name=e2]"),
+			},
+		},
+	}
+	tests := map[string]struct {
+		sync    bool
+		want    string
+		wantErr string
+	}{
+		"complete": {
+			sync: true,
+			want: `{"interfaces": {"interface": [{"name": "e1", "config": {"mtu": 1500}}]}}`,
+		},
+		"partial": {
+			sync:    false,
+			want:    `{"interfaces": {"interface": [{"name": "e1", "config": {"mtu": 1500}}]}, "partial": true}`,
+			wantErr: "no sync response received within 500ms",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, &onceServer{notifications: notifications, sync: item.sync})
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.LocalFlags.SubscribeMergeTimeout = 500 * time.Millisecond
+			a.Config.LocalFlags.SubscribeOutputDir = t.TempDir()
+			a.createCollectorDialOpts()
+			insecure := true
+			username, password := "admin", "admin"
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Username: &username,
+				Password: &password,
+				Timeout:  5 * time.Second,
+			}
+			tg, err := a.initTarget(tc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			err = tg.CreateGNMIClient(ctx, a.dialOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &gnmi.SubscribeRequest{
+				Request: &gnmi.SubscribeRequest_Subscribe{
+					Subscribe: &gnmi.SubscriptionList{Mode: gnmi.SubscriptionList_ONCE},
+				},
+			}
+			err = a.subscribeOnceMerge(ctx, tg, []subscriptionRequest{{name: "sub1", req: req}})
+			if item.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if item.wantErr != "" && (err == nil || !strings.Contains(err.Error(), item.wantErr)) {
+				t.Fatalf("expected an error containing %q, got: %v", item.wantErr, err)
+			}
+			b, err := os.ReadFile(filepath.Join(a.Config.LocalFlags.SubscribeOutputDir, "t1.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			err = json.Unmarshal(b, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = json.Unmarshal([]byte(item.want), &want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.want)
+				t.Logf("     got: %s", string(b))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestRemoveDocumentValue(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		want string
+	}{
+		"leaf": {
+			path: "/system/name",
+			want: `{"system": {"domain": "lab"}, "interfaces": {"interface": [{"name": "e1", "mtu": 1500}, {"name": "e2", "mtu": 9000}]}}`,
+		},
+		"list_entry": {
+			path: "/interfaces/interface[name=e1]",
+			want: `{"system": {"name": "r1", "domain": "lab"}, "interfaces": {"interface": [{"name": "e2", "mtu": 9000}]}}`,
+		},
+		"list_entry_leaf": {
+			path: "/interfaces/interface[name=e2]/mtu",
+			want: `{"system": {"name": "r1", "domain": "lab"}, "interfaces": {"interface": [{"name": "e1", "mtu": 1500}, {"name": "e2"}]}}`,
+		},
+		"wildcard": {
+			path: "/interfaces/interface[name=*]",
+			want: `{"system": {"name": "r1", "domain": "lab"}, "interfaces": {}}`,
+		},
+		"unknown": {
+			path: "/interfaces/interface[name=e3]/mtu",
+			want: `{"system": {"name": "r1", "domain": "lab"}, "interfaces": {"interface": [{"name": "e1", "mtu": 1500}, {"name": "e2", "mtu": 9000}]}}`,
+		},
+		"root": {
+			path: "/",
+			want: `{}`,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			var doc, want map[string]interface{}
+			err := json.Unmarshal([]byte(`{"system": {"name": "r1", "domain": "lab"}, "interfaces": {"interface": [{"name": "e1", "mtu": 1500}, {"name": "e2", "mtu": 9000}]}}`), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = json.Unmarshal([]byte(item.want), &want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removeDocumentValue(doc, mustParsePath(t, item.path).GetElem())
+			if !reflect.DeepEqual(doc, want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", want)
+				t.Logf("     got: %v", doc)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -42,6 +42,7 @@ func (a *App) SubscribeRunONCE(cmd *cobra.Command, args []string, subCfg map[str
 	}
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets)
+	defer a.startProgress("subscribe", numTargets)()
 	a.wg.Add(numTargets)
 	for _, tc := range a.Config.Targets {
 		go a.subscribeOnce(a.ctx, tc)
@@ -53,5 +54,6 @@ func (a *App) SubscribeRunONCE(cmd *cobra.Command, args []string, subCfg map[str
 		limiter.Stop()
 	}
 	a.wg.Wait()
+	a.FlushOutputs()
 	return a.checkErrors()
 }
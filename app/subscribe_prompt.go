@@ -25,10 +25,14 @@ func (a *App) SubscribeRunPrompt(cmd *cobra.Command, args []string) error {
 	a.Config.Subscriptions = make(map[string]*types.SubscriptionConfig)
 
 	// read targets
-	_, err := a.Config.GetTargets()
+	tcs, err := a.Config.GetTargets()
 	if err != nil {
 		return fmt.Errorf("failed reading targets config: %v", err)
 	}
+	err = a.Config.SetMissingCredentials(tcs)
+	if err != nil {
+		return err
+	}
 	subCfg, err := a.Config.GetSubscriptions(cmd)
 	if err != nil {
 		return fmt.Errorf("failed reading subscriptions config: %v", err)
@@ -11,6 +11,8 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/fullstorydev/grpcurl"
 	"github.com/openconfig/gnmic/target"
@@ -59,6 +61,7 @@ func (a *App) stopTarget(ctx context.Context, name string) error {
 	t := a.Targets[name]
 	t.StopSubscriptions()
 	delete(a.Targets, name)
+	a.setTargetState(name, targetStateStopped, nil)
 	if a.locker == nil {
 		return nil
 	}
@@ -76,6 +79,7 @@ func (a *App) DeleteTarget(ctx context.Context, name string) error {
 	delete(a.Config.Targets, name)
 	a.configLock.Unlock()
 	a.Logger.Printf("target %q deleted from config", name)
+	a.deleteTargetStatus(name)
 	// delete from oper map
 	a.operLock.Lock()
 	defer a.operLock.Unlock()
@@ -140,3 +144,26 @@ func (a *App) targetConfigExists(name string) bool {
 	a.configLock.RUnlock()
 	return ok
 }
+
+// skippedTargets returns the sorted names of the targets
+// skipped with `enabled: false` or --skip-target.
+func (a *App) skippedTargets() []string {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	names := make([]string, 0, len(a.Config.SkippedTargets))
+	for name := range a.Config.SkippedTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printSkippedTargets writes the skipped targets and the reason they were skipped to w.
+func (a *App) printSkippedTargets(w io.Writer) {
+	for _, name := range a.skippedTargets() {
+		a.configLock.RLock()
+		reason := a.Config.SkippedTargets[name]
+		a.configLock.RUnlock()
+		fmt.Fprintf(w, "target %q: skipped, %s\n", name, reason)
+	}
+}
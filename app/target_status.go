@@ -0,0 +1,193 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// subscribe target worker states
+const (
+	targetStateInitializing = "initializing"
+	targetStateLocking      = "locking"
+	targetStateConnecting   = "connecting"
+	targetStateRetrying     = "retrying"
+	targetStateSubscribed   = "subscribed"
+	targetStateDegraded     = "degraded"
+	targetStateStopped      = "stopped"
+	targetStateFailed       = "failed"
+)
+
+// targetStatus is the runtime status of a subscribe target worker.
+// LargestResponse is the size in bytes of the largest response received.
+type targetStatus struct {
+	Name            string    `json:"name"`
+	State           string    `json:"state"`
+	Since           time.Time `json:"since"`
+	LastError       string    `json:"last-error,omitempty"`
+	Reconnects      uint64    `json:"reconnects"`
+	Responses       uint64    `json:"responses"`
+	LastResponse    time.Time `json:"last-response,omitempty"`
+	LargestResponse int       `json:"largest-response,omitempty"`
+}
+
+// setTargetState sets the state of target name, err is recorded as the target last error if not nil.
+func (a *App) setTargetState(name, state string, err error) {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	ts, ok := a.targetsStatus[name]
+	if !ok {
+		ts = &targetStatus{Name: name}
+		a.targetsStatus[name] = ts
+	}
+	if ts.State != state {
+		ts.State = state
+		ts.Since = time.Now()
+	}
+	if err != nil {
+		ts.LastError = err.Error()
+	}
+}
+
+// targetResponseReceived counts a subscribe response of size bytes received from target name,
+// a retrying or degraded target is back to the subscribed state.
+// It returns true if the response is the largest received from the target, or if the target is not tracked.
+func (a *App) targetResponseReceived(name string, size int) bool {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	ts, ok := a.targetsStatus[name]
+	if !ok {
+		return true
+	}
+	now := time.Now()
+	ts.Responses++
+	ts.LastResponse = now
+	if ts.State == targetStateRetrying || ts.State == targetStateDegraded {
+		ts.State = targetStateSubscribed
+		ts.Since = now
+	}
+	if size <= ts.LargestResponse {
+		return false
+	}
+	ts.LargestResponse = size
+	return true
+}
+
+// targetLastResponse returns the time of the last subscribe response received from target name,
+// or the time it entered its current state if no response was received yet.
+func (a *App) targetLastResponse(name string) time.Time {
+	a.statusLock.RLock()
+	defer a.statusLock.RUnlock()
+	ts, ok := a.targetsStatus[name]
+	if !ok {
+		return time.Time{}
+	}
+	if ts.LastResponse.IsZero() {
+		return ts.Since
+	}
+	return ts.LastResponse
+}
+
+// targetLastError records err as the last error of target name, without changing its state.
+func (a *App) targetLastError(name string, err error) {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	if ts, ok := a.targetsStatus[name]; ok {
+		ts.LastError = err.Error()
+	}
+}
+
+// targetReconnect records a subscription retry of target name.
+func (a *App) targetReconnect(name string) {
+	a.setTargetState(name, targetStateRetrying, nil)
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	a.targetsStatus[name].Reconnects++
+}
+
+func (a *App) deleteTargetStatus(name string) {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	delete(a.targetsStatus, name)
+}
+
+// targetsStatusTable returns a copy of the targets status sorted by target name.
+func (a *App) targetsStatusTable() []targetStatus {
+	a.statusLock.RLock()
+	defer a.statusLock.RUnlock()
+	tss := make([]targetStatus, 0, len(a.targetsStatus))
+	for _, ts := range a.targetsStatus {
+		tss = append(tss, *ts)
+	}
+	sort.Slice(tss, func(i, j int) bool {
+		return tss[i].Name < tss[j].Name
+	})
+	return tss
+}
+
+// formatTargetsStatus renders the targets status as a table.
+func formatTargetsStatus(tss []targetStatus, now time.Time) string {
+	b := new(bytes.Buffer)
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tSTATE\tSINCE\tRESPONSES\tLAST RESPONSE\tRECONNECTS\tLAST ERROR")
+	for _, ts := range tss {
+		lastRsp := "-"
+		if !ts.LastResponse.IsZero() {
+			lastRsp = now.Sub(ts.LastResponse).Truncate(time.Second).String() + " ago"
+		}
+		lastErr := ts.LastError
+		if lastErr == "" {
+			lastErr = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\n",
+			ts.Name, ts.State, now.Sub(ts.Since).Truncate(time.Second),
+			ts.Responses, lastRsp, ts.Reconnects, lastErr)
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// handleStatusSignal writes the targets status table to the log
+// each time the process receives a SIGUSR1.
+func (a *App) handleStatusSignal(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			for _, l := range strings.Split(formatTargetsStatus(a.targetsStatusTable(), time.Now()), "\n") {
+				a.Logger.Print(l)
+			}
+		}
+	}
+}
+
+// recoverTarget is deferred by the target workers, it stops a panic
+// in a target worker from taking the other targets down.
+func (a *App) recoverTarget(name string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	a.Logger.Printf("target %q: worker panic: %v\n%s", name, r, debug.Stack())
+	a.setTargetState(name, targetStateFailed, fmt.Errorf("worker panic: %v", r))
+}
@@ -0,0 +1,67 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTargetsStatus(t *testing.T) {
+	a := New()
+	a.setTargetState("r2", targetStateConnecting, nil)
+	a.setTargetState("r1", targetStateSubscribed, nil)
+	a.targetResponseReceived("r1", 10)
+	a.targetLastError("r2", errors.New("connection refused"))
+	a.targetReconnect("r2")
+	a.targetReconnect("r2")
+	// unknown targets are ignored
+	a.targetResponseReceived("r3", 10)
+
+	tss := a.targetsStatusTable()
+	if len(tss) != 2 || tss[0].Name != "r1" || tss[1].Name != "r2" {
+		t.Fatalf("unexpected targets status table: %+v", tss)
+	}
+	if tss[0].State != targetStateSubscribed || tss[0].Responses != 1 || tss[0].LastResponse.IsZero() {
+		t.Errorf("unexpected r1 status: %+v", tss[0])
+	}
+	if tss[1].State != targetStateRetrying || tss[1].Reconnects != 2 || tss[1].LastError != "connection refused" {
+		t.Errorf("unexpected r2 status: %+v", tss[1])
+	}
+	// a response moves a retrying target back to subscribed
+	a.targetResponseReceived("r2", 10)
+	if ts := a.targetsStatusTable()[1]; ts.State != targetStateSubscribed {
+		t.Errorf("expected r2 to be %q, got %q", targetStateSubscribed, ts.State)
+	}
+
+	table := formatTargetsStatus(a.targetsStatusTable(), time.Now())
+	lines := strings.Split(table, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TARGET") || !strings.HasPrefix(lines[1], "r1 ") {
+		t.Errorf("unexpected status table:\n%s", table)
+	}
+
+	a.deleteTargetStatus("r1")
+	if tss := a.targetsStatusTable(); len(tss) != 1 || tss[0].Name != "r2" {
+		t.Errorf("unexpected targets status after delete: %+v", tss)
+	}
+}
+
+func TestRecoverTarget(t *testing.T) {
+	a := New()
+	func() {
+		defer a.recoverTarget("r1")
+		panic("boom")
+	}()
+	tss := a.targetsStatusTable()
+	if len(tss) != 1 || tss[0].State != targetStateFailed || !strings.Contains(tss[0].LastError, "boom") {
+		t.Errorf("unexpected status after panic: %+v", tss)
+	}
+}
@@ -0,0 +1,78 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// applyTotalTimeout bounds the root context with --total-timeout.
+// The per target RPCs derive their --timeout deadline from the root context,
+// the first deadline reached cancels the RPC.
+func (a *App) applyTotalTimeout() {
+	if a.Config.TotalTimeout <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, a.Config.TotalTimeout)
+	cfn := a.Cfn
+	a.ctx = ctx
+	a.Cfn = func() {
+		cancel()
+		cfn()
+	}
+}
+
+// totalTimeoutReached returns true if the --total-timeout deadline expired.
+func (a *App) totalTimeoutReached() bool {
+	return a.Config.TotalTimeout > 0 && errors.Is(a.ctx.Err(), context.DeadlineExceeded)
+}
+
+// totalTimeoutHint is the hint added to the errors of the targets
+// cancelled by --total-timeout.
+func (a *App) totalTimeoutHint() string {
+	return fmt.Sprintf("total timeout (%s) reached", a.Config.TotalTimeout)
+}
+
+// timedOutError is the text error of a target cancelled by --total-timeout.
+type timedOutError struct {
+	source string
+	err    error
+}
+
+func (e *timedOutError) Error() string {
+	return e.err.Error()
+}
+
+func (e *timedOutError) Unwrap() error {
+	return e.err
+}
+
+// timedOutSources returns the sorted names of the targets cancelled by --total-timeout found in errs.
+func timedOutSources(errs []error) []string {
+	set := make(map[string]struct{})
+	for _, err := range errs {
+		switch err := err.(type) {
+		case *rpcError:
+			if err.TimedOut {
+				set[err.Source] = struct{}{}
+			}
+		case *timedOutError:
+			set[err.source] = struct{}{}
+		}
+	}
+	sources := make([]string, 0, len(set))
+	for s := range set {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+	return sources
+}
@@ -0,0 +1,148 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// slowServer is a gNMI server replying to a Get request after delay,
+// or when the request is cancelled.
+type slowServer struct {
+	gnmi.UnimplementedGNMIServer
+	delay time.Duration
+}
+
+func (s *slowServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{{Timestamp: 42}}}, nil
+}
+
+func startSlowServer(t *testing.T, delay time.Duration) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, &slowServer{delay: delay})
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+	return l.Addr().String()
+}
+
+func TestTotalTimeout(t *testing.T) {
+	tests := map[string]struct {
+		timeout      time.Duration
+		totalTimeout time.Duration
+		format       string
+		// maximum duration of the whole run
+		maxElapsed time.Duration
+		wantErr    string
+		wantOut    []string
+		notWantErr string
+	}{
+		"total_timeout_cancels_slow_target": {
+			timeout:      10 * time.Second,
+			totalTimeout: 300 * time.Millisecond,
+			maxElapsed:   2 * time.Second,
+			wantErr:      "total timeout (300ms) reached, 1 target(s) timed out: slow",
+			wantOut:      []string{"42"},
+		},
+		"total_timeout_json_summary": {
+			timeout:      10 * time.Second,
+			totalTimeout: 300 * time.Millisecond,
+			format:       formatJSON,
+			maxElapsed:   2 * time.Second,
+			wantErr:      "1 target(s) timed out: slow",
+			wantOut: []string{
+				`"code":"DeadlineExceeded","hint":"total timeout (300ms) reached"`,
+				`"rpc":"Get","source":"slow","timed-out":true`,
+				`"sources":["slow"],"timed-out":["slow"]`,
+			},
+		},
+		"per_target_timeout": {
+			timeout:      200 * time.Millisecond,
+			totalTimeout: 10 * time.Second,
+			maxElapsed:   2 * time.Second,
+			wantErr:      "one or more requests failed",
+			notWantErr:   "timed out",
+			wantOut:      []string{"42"},
+		},
+		"no_total_timeout": {
+			timeout:    200 * time.Millisecond,
+			maxElapsed: 2 * time.Second,
+			wantErr:    "one or more requests failed",
+			notWantErr: "timed out",
+		},
+	}
+	fastAddr := startSlowServer(t, 0)
+	slowAddr := startSlowServer(t, time.Minute)
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := New()
+			defer a.Cfn()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Log = true
+			a.Config.Encoding = "json"
+			a.Config.Format = item.format
+			a.Config.TotalTimeout = item.totalTimeout
+			out := new(bytes.Buffer)
+			a.out = out
+			a.createCollectorDialOpts()
+			a.applyTotalTimeout()
+
+			insecure := true
+			targets := []*types.TargetConfig{
+				{Name: "fast", Address: fastAddr, Insecure: &insecure, Timeout: item.timeout},
+				{Name: "slow", Address: slowAddr, Insecure: &insecure, Timeout: item.timeout},
+			}
+			a.errCh = make(chan error, len(targets)*3)
+			start := time.Now()
+			a.wg.Add(len(targets))
+			for _, tc := range targets {
+				go a.GetRequest(a.ctx, tc, &gnmi.GetRequest{})
+			}
+			a.wg.Wait()
+			elapsed := time.Since(start)
+			err := a.checkErrors()
+
+			if elapsed > item.maxElapsed {
+				t.Errorf("failed at item %q: run took %s, expected less than %s", name, elapsed, item.maxElapsed)
+			}
+			if err == nil || !strings.Contains(err.Error(), item.wantErr) {
+				t.Errorf("failed at item %q: expected an error containing %q, got: %v", name, item.wantErr, err)
+			}
+			if item.notWantErr != "" && err != nil && strings.Contains(err.Error(), item.notWantErr) {
+				t.Errorf("failed at item %q: unexpected error %q", name, err)
+			}
+			// the JSON output and the expected strings are compared without spaces
+			got := stripSpaces(out.String())
+			for _, s := range item.wantOut {
+				if !strings.Contains(got, stripSpaces(s)) {
+					t.Errorf("failed at item %q: expected the output to contain %q, got: %s", name, s, got)
+				}
+			}
+		})
+	}
+}
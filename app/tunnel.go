@@ -186,12 +186,9 @@ func (a *App) tunServerHandler(ss tunnel.ServerSession, rwc io.ReadWriteCloser)
 // tunDialerFn is used to build a grpc Option that sets a custom dialer for tunnel targets.
 func (a *App) tunDialerFn(ctx context.Context, tc *types.TargetConfig) func(context.Context, string) (net.Conn, error) {
 	return func(_ context.Context, _ string) (net.Conn, error) {
-		tt := tunnel.Target{ID: tc.Name, Type: tc.TunnelTargetType}
-		a.ttm.RLock()
-		_, ok := a.tunTargets[tt]
-		a.ttm.RUnlock()
+		tt, ok := a.waitTunnelTarget(ctx, tc)
 		if !ok {
-			return nil, fmt.Errorf("unknown tunnel target %+v", tt)
+			return nil, fmt.Errorf("unknown tunnel target %+v", tunnel.Target{ID: tc.Name, Type: tc.TunnelTargetType})
 		}
 		a.Logger.Printf("dialing tunnel connection for tunnel target %q", tc.Name)
 		conn, err := tunnel.ServerConn(ctx, a.tunServer, &tt)
@@ -202,6 +199,59 @@ func (a *App) tunDialerFn(ctx context.Context, tc *types.TargetConfig) func(cont
 	}
 }
 
+// waitTunnelTarget returns the registered tunnel target matching the target config,
+// waiting up to the tunnel server target-wait-time for it to register.
+func (a *App) waitTunnelTarget(ctx context.Context, tc *types.TargetConfig) (tunnel.Target, bool) {
+	if tt, ok := a.lookupTunnelTarget(tc); ok {
+		return tt, true
+	}
+	var waitTime time.Duration
+	if a.Config.TunnelServer != nil {
+		waitTime = a.Config.TunnelServer.TargetWaitTime
+	}
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return tunnel.Target{}, false
+		case <-timer.C:
+			return a.lookupTunnelTarget(tc)
+		case <-ticker.C:
+			if tt, ok := a.lookupTunnelTarget(tc); ok {
+				return tt, true
+			}
+		}
+	}
+}
+
+// lookupTunnelTarget returns the registered tunnel target matching the target config.
+// A target config without a tunnel target type, e.g: set with --address,
+// matches the registered target with the same ID, preferring the GNMI_GNOI type.
+func (a *App) lookupTunnelTarget(tc *types.TargetConfig) (tunnel.Target, bool) {
+	a.ttm.RLock()
+	defer a.ttm.RUnlock()
+	if tc.TunnelTargetType != "" {
+		tt := tunnel.Target{ID: tc.Name, Type: tc.TunnelTargetType}
+		_, ok := a.tunTargets[tt]
+		return tt, ok
+	}
+	var found tunnel.Target
+	var ok bool
+	for tt := range a.tunTargets {
+		if tt.ID != tc.Name {
+			continue
+		}
+		if tt.Type == "GNMI_GNOI" {
+			return tt, true
+		}
+		found, ok = tt, true
+	}
+	return found, ok
+}
+
 func (a *App) getTunnelTargetMatch(tt tunnel.Target) *types.TargetConfig {
 	if len(a.Config.TunnelServer.Targets) == 0 {
 		// no target matches defined, accept only GNMI_GNOI type
@@ -0,0 +1,124 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (a *App) TunnelTargetsRunE(cmd *cobra.Command, args []string) error {
+	a.Config.UseTunnelServer = true
+	err := a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerRecordTargetHandler,
+		DeleteTargetHandler: a.tunServerForgetTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+	if err != nil {
+		return err
+	}
+	wait := a.Config.LocalFlags.TunnelTargetsWait
+	if wait <= 0 {
+		wait = a.Config.TunnelServer.TargetWaitTime
+	}
+	a.Logger.Printf("waiting %s for targets to register with the tunnel server...", wait)
+	select {
+	case <-a.ctx.Done():
+		return a.ctx.Err()
+	case <-time.After(wait):
+	}
+	return a.printTunnelTargets(a.out, a.registeredTunnelTargets())
+}
+
+func (a *App) InitTunnelTargetsFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.TunnelTargetsWait, "wait", "", 0, "time to wait for targets to register, defaults to the tunnel server target-wait-time")
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// tunServerRecordTargetHandler records all the registered targets,
+// regardless of the tunnel server targets matches.
+func (a *App) tunServerRecordTargetHandler(tt tunnel.Target) error {
+	a.Logger.Printf("tunnel server discovered target %+v", tt)
+	a.ttm.Lock()
+	a.tunTargets[tt] = struct{}{}
+	a.ttm.Unlock()
+	return nil
+}
+
+func (a *App) tunServerForgetTargetHandler(tt tunnel.Target) error {
+	a.Logger.Printf("tunnel server target %+v deregister request", tt)
+	a.ttm.Lock()
+	delete(a.tunTargets, tt)
+	a.ttm.Unlock()
+	return nil
+}
+
+// registeredTunnelTargets returns the registered tunnel targets sorted by ID and type.
+func (a *App) registeredTunnelTargets() []tunnel.Target {
+	a.ttm.RLock()
+	defer a.ttm.RUnlock()
+	tts := make([]tunnel.Target, 0, len(a.tunTargets))
+	for tt := range a.tunTargets {
+		tts = append(tts, tt)
+	}
+	sort.Slice(tts, func(i, j int) bool {
+		if tts[i].ID == tts[j].ID {
+			return tts[i].Type < tts[j].Type
+		}
+		return tts[i].ID < tts[j].ID
+	})
+	return tts
+}
+
+func (a *App) printTunnelTargets(w io.Writer, tts []tunnel.Target) error {
+	if a.Config.Format == formatJSON {
+		type tunnelTarget struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		out := make([]tunnelTarget, 0, len(tts))
+		for _, tt := range tts {
+			out = append(out, tunnelTarget{ID: tt.ID, Type: tt.Type})
+		}
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	if len(tts) == 0 {
+		fmt.Fprintln(w, "no registered tunnel targets")
+		return nil
+	}
+	tabData := make([][]string, 0, len(tts))
+	for _, tt := range tts {
+		tabData = append(tabData, []string{tt.ID, tt.Type})
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Type"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+	return nil
+}
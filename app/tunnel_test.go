@@ -0,0 +1,68 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/grpctunnel/tunnel"
+)
+
+var lookupTunnelTargetTestSet = map[string]struct {
+	in    *types.TargetConfig
+	found bool
+	out   tunnel.Target
+}{
+	"id_only": {
+		in:    &types.TargetConfig{Name: "sr1"},
+		found: true,
+		out:   tunnel.Target{ID: "sr1", Type: "GNMI_GNOI"},
+	},
+	"id_only_other_type": {
+		in:    &types.TargetConfig{Name: "sr2"},
+		found: true,
+		out:   tunnel.Target{ID: "sr2", Type: "SSH"},
+	},
+	"id_and_type": {
+		in:    &types.TargetConfig{Name: "sr1", TunnelTargetType: "SSH"},
+		found: true,
+		out:   tunnel.Target{ID: "sr1", Type: "SSH"},
+	},
+	"unknown_id": {
+		in:    &types.TargetConfig{Name: "sr3"},
+		found: false,
+	},
+	"unknown_type": {
+		in:    &types.TargetConfig{Name: "sr2", TunnelTargetType: "GNMI_GNOI"},
+		found: false,
+	},
+}
+
+func TestLookupTunnelTarget(t *testing.T) {
+	a := New()
+	for _, tt := range []tunnel.Target{
+		{ID: "sr1", Type: "SSH"},
+		{ID: "sr1", Type: "GNMI_GNOI"},
+		{ID: "sr2", Type: "SSH"},
+	} {
+		a.tunTargets[tt] = struct{}{}
+	}
+	for name, item := range lookupTunnelTargetTestSet {
+		t.Run(name, func(t *testing.T) {
+			tt, ok := a.lookupTunnelTarget(item.in)
+			if ok != item.found || (ok && tt != item.out) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %+v, %v", item.out, item.found)
+				t.Logf("     got: %+v, %v", tt, ok)
+				t.Fail()
+			}
+		})
+	}
+}
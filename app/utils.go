@@ -9,18 +9,41 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 )
 
-func (a *App) printCapResponse(printPrefix string, msg *gnmi.CapabilityResponse) {
+func (a *App) printCapResponse(printPrefix string, msg *gnmi.CapabilityResponse, meta map[string]string) {
 	sb := strings.Builder{}
 	sb.WriteString("gNMI version: ")
-	sb.WriteString(msg.GNMIVersion)
+	if msg.GetGNMIVersion() == "" {
+		sb.WriteString("not reported")
+	} else {
+		sb.WriteString(msg.GetGNMIVersion())
+	}
 	sb.WriteString("\n")
+	if len(meta) > 0 {
+		sb.WriteString("response metadata:\n")
+		keys := make([]string, 0, len(meta))
+		for k := range meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString("  - ")
+			sb.WriteString(k)
+			sb.WriteString(": ")
+			sb.WriteString(meta[k])
+			sb.WriteString("\n")
+		}
+	}
 	if a.Config.LocalFlags.CapabilitiesVersion {
+		fmt.Fprintf(a.out, "%s\n", indent(printPrefix, sb.String()))
 		return
 	}
 	sb.WriteString("supported models:\n")
@@ -42,6 +65,35 @@ func (a *App) printCapResponse(printPrefix string, msg *gnmi.CapabilityResponse)
 	fmt.Fprintf(a.out, "%s\n", indent(printPrefix, sb.String()))
 }
 
+// writeIndented writes b to w line by line, each line preceded by prefix.
+// Unlike indent, it does not build a copy of the whole output.
+func writeIndented(w io.Writer, prefix string, b []byte) error {
+	prefix = strings.TrimRight(prefix, "\n")
+	for len(b) > 0 {
+		line := b
+		i := bytes.IndexByte(b, '\n')
+		if i >= 0 {
+			line = b[:i]
+			b = b[i+1:]
+		} else {
+			b = nil
+		}
+		_, err := io.WriteString(w, prefix)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(line)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func indent(prefix, s string) string {
 	if prefix == "" {
 		return s
@@ -50,3 +102,12 @@ func indent(prefix, s string) string {
 	lines := strings.Split(s, "\n")
 	return strings.TrimLeft(fmt.Sprintf("%s%s", prefix, strings.Join(lines, prefix)), "\n")
 }
+
+func strInList(s string, ls []string) bool {
+	for _, e := range ls {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
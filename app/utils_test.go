@@ -0,0 +1,83 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var writeIndentedTestSet = map[string]struct {
+	prefix string
+	in     string
+}{
+	"no_prefix": {
+		prefix: "",
+		in:     "{\n  \"a\": 1\n}",
+	},
+	"prefix": {
+		prefix: "[r1] ",
+		in:     "{\n  \"a\": 1\n}",
+	},
+	"single_line": {
+		prefix: "r1\t",
+		in:     "1",
+	},
+}
+
+func TestWriteIndented(t *testing.T) {
+	for name, item := range writeIndentedTestSet {
+		t.Run(name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			err := writeIndented(b, item.prefix, []byte(item.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// same output as the previous indent based printing
+			expected := fmt.Sprintf("%s\n", indent(item.prefix, item.in))
+			if b.String() != expected {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", expected)
+				t.Logf("     got: %q", b.String())
+				t.Fail()
+			}
+		})
+	}
+}
+
+// syntheticOutput returns a formatted output of numLeaves lines.
+func syntheticOutput(numLeaves int) []byte {
+	b := new(bytes.Buffer)
+	b.WriteString("[\n")
+	for i := 0; i < numLeaves; i++ {
+		fmt.Fprintf(b, "  \"/interfaces/interface[name=ethernet-1/%d]/statistics/in-octets\": %d,\n", i, i)
+	}
+	b.WriteString("]")
+	return b.Bytes()
+}
+
+func BenchmarkWriteIndented(b *testing.B) {
+	out := syntheticOutput(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writeIndented(io.Discard, "[r1] ", out)
+	}
+}
+
+func BenchmarkIndent(b *testing.B) {
+	out := syntheticOutput(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fmt.Fprintf(io.Discard, "%s\n", indent("[r1] ", string(out)))
+	}
+}
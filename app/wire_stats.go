@@ -0,0 +1,216 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"github.com/openconfig/gnmic/target"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// wireStats are the gRPC counters of a target, accumulated over all its connections.
+// The wire lengths include the gRPC message framing, headers and trailers,
+// after compression, but not the HTTP/2 and TLS framing.
+type wireStats struct {
+	// set if the target connections use compression
+	compressed bool
+
+	sentWireBytes    uint64
+	sentPayloadBytes uint64
+	sentMsgs         uint64
+	recvWireBytes    uint64
+	recvPayloadBytes uint64
+	recvMsgs         uint64
+	conns            uint64
+}
+
+// wireStatsEnabled returns true if the targets gRPC connections are instrumented,
+// either to print a summary with --stats, to report the progress with --progress
+// or to expose them as self metrics.
+func (a *App) wireStatsEnabled() bool {
+	return a.Config.Stats || a.Config.Progress != "" ||
+		a.Config.LocalFlags.SubscribeMetricsAddress != "" ||
+		(a.Config.APIServer != nil && a.Config.APIServer.EnableMetrics)
+}
+
+// wireStatsDialOpts returns the dial option installing the stats handler
+// of target t, or nil if the stats are disabled.
+func (a *App) wireStatsDialOpts(t *target.Target) []grpc.DialOption {
+	if !a.wireStatsEnabled() {
+		return nil
+	}
+	a.wireStatsLock.Lock()
+	ws, ok := a.wireStats[t.Config.Name]
+	if !ok {
+		ws = &wireStats{compressed: a.Config.Gzip || (t.Config.Gzip != nil && *t.Config.Gzip)}
+		a.wireStats[t.Config.Name] = ws
+	}
+	a.wireStatsLock.Unlock()
+	logger := a.Logger
+	if !a.Config.Debug {
+		logger = log.New(io.Discard, "", 0)
+	}
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(&wireStatsHandler{target: t.Config.Name, stats: ws, logger: logger}),
+	}
+}
+
+// wireStatsHandler is a gRPC stats handler accumulating the wire stats of a target.
+type wireStatsHandler struct {
+	target string
+	stats  *wireStats
+	logger *log.Logger
+}
+
+func (h *wireStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *wireStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.OutPayload:
+		atomic.AddUint64(&h.stats.sentWireBytes, uint64(s.WireLength))
+		atomic.AddUint64(&h.stats.sentPayloadBytes, uint64(s.Length))
+		atomic.AddUint64(&h.stats.sentMsgs, 1)
+	case *stats.InPayload:
+		atomic.AddUint64(&h.stats.recvWireBytes, uint64(s.WireLength))
+		atomic.AddUint64(&h.stats.recvPayloadBytes, uint64(s.Length))
+		atomic.AddUint64(&h.stats.recvMsgs, 1)
+	case *stats.InHeader:
+		atomic.AddUint64(&h.stats.recvWireBytes, uint64(s.WireLength))
+	case *stats.InTrailer:
+		atomic.AddUint64(&h.stats.recvWireBytes, uint64(s.WireLength))
+	}
+}
+
+func (h *wireStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *wireStatsHandler) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		atomic.AddUint64(&h.stats.conns, 1)
+	case *stats.ConnEnd:
+		h.logger.Printf("target %q: gRPC connection closed, %s", h.target, h.stats.snapshot())
+	}
+}
+
+// snapshot returns a copy of ws loaded atomically.
+func (ws *wireStats) snapshot() wireStats {
+	return wireStats{
+		compressed:       ws.compressed,
+		sentWireBytes:    atomic.LoadUint64(&ws.sentWireBytes),
+		sentPayloadBytes: atomic.LoadUint64(&ws.sentPayloadBytes),
+		sentMsgs:         atomic.LoadUint64(&ws.sentMsgs),
+		recvWireBytes:    atomic.LoadUint64(&ws.recvWireBytes),
+		recvPayloadBytes: atomic.LoadUint64(&ws.recvPayloadBytes),
+		recvMsgs:         atomic.LoadUint64(&ws.recvMsgs),
+		conns:            atomic.LoadUint64(&ws.conns),
+	}
+}
+
+func (s wireStats) String() string {
+	return fmt.Sprintf("sent %d message(s), %d wire bytes%s, received %d message(s), %d wire bytes%s, %d connection(s)",
+		s.sentMsgs, s.sentWireBytes, s.compressionSavings(s.sentWireBytes, s.sentPayloadBytes),
+		s.recvMsgs, s.recvWireBytes, s.compressionSavings(s.recvWireBytes, s.recvPayloadBytes),
+		s.conns)
+}
+
+// compressionSavings describes the bytes saved by compression,
+// it returns an empty string if compression is not enabled.
+func (s wireStats) compressionSavings(wire, payload uint64) string {
+	if !s.compressed || payload == 0 {
+		return ""
+	}
+	saved := (float64(payload) - float64(wire)) / float64(payload) * 100
+	return fmt.Sprintf(" (%d uncompressed, %.1f%% saved)", payload, saved)
+}
+
+// PrintStats prints the wire stats summary of each target
+// and the messages counters of each output to stderr if --stats is set.
+func (a *App) PrintStats() {
+	if !a.Config.Stats {
+		return
+	}
+	a.printWireStats(os.Stderr)
+	a.printOutputStats(os.Stderr)
+	a.printSequences(os.Stderr)
+	a.printSkippedTargets(os.Stderr)
+}
+
+// printWireStats writes the wire stats summary of each target to w.
+func (a *App) printWireStats(w io.Writer) {
+	a.wireStatsLock.Lock()
+	defer a.wireStatsLock.Unlock()
+	names := make([]string, 0, len(a.wireStats))
+	for name := range a.wireStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if d, ok := a.anomalyDetectors[name]; ok {
+			fmt.Fprintf(w, "target %q: %s, %s\n", name, a.wireStats[name].snapshot(), d.summary())
+			continue
+		}
+		fmt.Fprintf(w, "target %q: %s\n", name, a.wireStats[name].snapshot())
+	}
+}
+
+var (
+	wireStatsSentBytesDesc = prometheus.NewDesc("gnmic_target_grpc_sent_wire_bytes_total",
+		"Total number of bytes sent to the target on the wire, after compression", []string{"source"}, nil)
+	wireStatsSentPayloadBytesDesc = prometheus.NewDesc("gnmic_target_grpc_sent_payload_bytes_total",
+		"Total number of uncompressed bytes of the messages sent to the target", []string{"source"}, nil)
+	wireStatsSentMsgsDesc = prometheus.NewDesc("gnmic_target_grpc_sent_messages_total",
+		"Total number of gRPC messages sent to the target", []string{"source"}, nil)
+	wireStatsRecvBytesDesc = prometheus.NewDesc("gnmic_target_grpc_received_wire_bytes_total",
+		"Total number of bytes received from the target on the wire, before decompression", []string{"source"}, nil)
+	wireStatsRecvPayloadBytesDesc = prometheus.NewDesc("gnmic_target_grpc_received_payload_bytes_total",
+		"Total number of uncompressed bytes of the messages received from the target", []string{"source"}, nil)
+	wireStatsRecvMsgsDesc = prometheus.NewDesc("gnmic_target_grpc_received_messages_total",
+		"Total number of gRPC messages received from the target", []string{"source"}, nil)
+)
+
+// wireStatsCollector exposes the targets wire stats as Prometheus metrics.
+type wireStatsCollector struct {
+	a *App
+}
+
+func (c *wireStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- wireStatsSentBytesDesc
+	ch <- wireStatsSentPayloadBytesDesc
+	ch <- wireStatsSentMsgsDesc
+	ch <- wireStatsRecvBytesDesc
+	ch <- wireStatsRecvPayloadBytesDesc
+	ch <- wireStatsRecvMsgsDesc
+}
+
+func (c *wireStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.a.wireStatsLock.Lock()
+	defer c.a.wireStatsLock.Unlock()
+	for name, ws := range c.a.wireStats {
+		s := ws.snapshot()
+		ch <- prometheus.MustNewConstMetric(wireStatsSentBytesDesc, prometheus.CounterValue, float64(s.sentWireBytes), name)
+		ch <- prometheus.MustNewConstMetric(wireStatsSentPayloadBytesDesc, prometheus.CounterValue, float64(s.sentPayloadBytes), name)
+		ch <- prometheus.MustNewConstMetric(wireStatsSentMsgsDesc, prometheus.CounterValue, float64(s.sentMsgs), name)
+		ch <- prometheus.MustNewConstMetric(wireStatsRecvBytesDesc, prometheus.CounterValue, float64(s.recvWireBytes), name)
+		ch <- prometheus.MustNewConstMetric(wireStatsRecvPayloadBytesDesc, prometheus.CounterValue, float64(s.recvPayloadBytes), name)
+		ch <- prometheus.MustNewConstMetric(wireStatsRecvMsgsDesc, prometheus.CounterValue, float64(s.recvMsgs), name)
+	}
+}
@@ -0,0 +1,114 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/target"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// getServer is a gNMI server replying to all Get requests with a single large string value.
+type getServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *getServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{{
+			Update: []*gnmi.Update{{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "description"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: strings.Repeat("a", 4096)}},
+			}},
+		}},
+	}, nil
+}
+
+func TestWireStats(t *testing.T) {
+	tests := map[string]struct {
+		gzip bool
+	}{
+		"uncompressed": {},
+		"compressed":   {gzip: true},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := grpc.NewServer()
+			gnmi.RegisterGNMIServer(gs, &getServer{})
+			go gs.Serve(l)
+			defer gs.Stop()
+
+			a := New()
+			a.Logger = log.New(io.Discard, "", 0)
+			a.Config.Stats = true
+			a.Config.Gzip = item.gzip
+			a.createCollectorDialOpts()
+			insecure := true
+			tc := &types.TargetConfig{
+				Name:     "t1",
+				Address:  l.Addr().String(),
+				Insecure: &insecure,
+				Timeout:  5 * time.Second,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, err = a.ClientGet(ctx, tc, &gnmi.GetRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ws, ok := a.wireStats["t1"]
+			if !ok {
+				t.Fatal("expected wire stats for target t1")
+			}
+			s := ws.snapshot()
+			if s.sentMsgs != 1 || s.recvMsgs != 1 {
+				t.Errorf("expected 1 message sent and received, got %d sent and %d received", s.sentMsgs, s.recvMsgs)
+			}
+			if s.recvPayloadBytes < 4096 {
+				t.Errorf("expected at least 4096 payload bytes received, got %d", s.recvPayloadBytes)
+			}
+			if item.gzip && s.recvWireBytes >= s.recvPayloadBytes {
+				t.Errorf("expected the compressed wire bytes %d to be less than the payload bytes %d", s.recvWireBytes, s.recvPayloadBytes)
+			}
+			if !item.gzip && s.recvWireBytes <= s.recvPayloadBytes {
+				t.Errorf("expected the wire bytes %d to include the framing of the payload bytes %d", s.recvWireBytes, s.recvPayloadBytes)
+			}
+			out := new(bytes.Buffer)
+			a.printWireStats(out)
+			if !strings.HasPrefix(out.String(), `target "t1": sent 1 message(s)`) {
+				t.Errorf("unexpected stats summary: %q", out.String())
+			}
+			if strings.Contains(out.String(), "saved") != item.gzip {
+				t.Errorf("unexpected compression savings in the stats summary: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestWireStatsDisabled(t *testing.T) {
+	a := New()
+	tg := target.NewTarget(&types.TargetConfig{Name: "t1"})
+	if opts := a.targetDialOpts(tg); len(opts) != len(a.dialOpts) {
+		t.Errorf("expected no stats handler when the stats are disabled, got %d extra dial options", len(opts)-len(a.dialOpts))
+	}
+}
@@ -51,12 +51,25 @@ type Cache interface {
 	SetLogger(l *log.Logger)
 }
 
+// StatsCache is implemented by the caches bounded in number of entries.
+type StatsCache interface {
+	// Stats returns the current number of entries and the number of evicted entries
+	Stats() *Stats
+}
+
+type Stats struct {
+	Entries   int64
+	Evictions uint64
+}
+
 type Config struct {
 	Type       CacheType     `mapstructure:"type,omitempty" json:"type,omitempty"`
 	Address    string        `mapstructure:"address,omitempty" json:"address,omitempty"`
 	Timeout    time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
 	Expiration time.Duration `mapstructure:"expiration,omitempty" json:"expiration,omitempty"`
 	Debug      bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// OC cfg options
+	MaxEntries int64 `mapstructure:"max-entries,omitempty" json:"max-entries,omitempty"`
 	// NATS, JS and Redis cfg options
 	Username string `mapstructure:"username,omitempty" json:"username,omitempty"`
 	Password string `mapstructure:"password,omitempty" json:"password,omitempty"`
@@ -9,10 +9,12 @@
 package cache
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +32,9 @@ import (
 const (
 	loggingPrefixOC = "[cache:%s] "
 	defaultTimeout  = 10 * time.Second
+	// timestamp of the delete notifications evicting entries,
+	// newer than any cached value, their deletes are not sent to the subscribers.
+	evictionTimestamp = math.MaxInt64
 )
 
 type gnmiCache struct {
@@ -40,6 +45,20 @@ type gnmiCache struct {
 	logger     *log.Logger
 	expiration time.Duration
 	debug      bool
+	// least recently written entries eviction,
+	// disabled if maxEntries is zero.
+	maxEntries int64
+	lrw        *list.List               // of *cacheEntry, most recently written first
+	entries    map[string]*list.Element // key to lrw element
+	evictions  uint64
+}
+
+// cacheEntry is a leaf written to the cache.
+type cacheEntry struct {
+	key    string
+	sub    string
+	target string
+	path   *gnmi.Path
 }
 
 type subCache struct {
@@ -51,6 +70,7 @@ func (gc *gnmiCache) loadConfig(gcc *Config) {
 	gc.expiration = gcc.Expiration
 	gc.logger = log.New(io.Discard, loggingPrefixOC, utils.DefaultLoggingFlags)
 	gc.debug = gcc.Debug
+	gc.maxEntries = gcc.MaxEntries
 }
 
 func newGNMICache(cfg *Config, loggingPrefix string, opts ...Option) *gnmiCache {
@@ -60,7 +80,9 @@ func newGNMICache(cfg *Config, loggingPrefix string, opts ...Option) *gnmiCache
 	gc := &gnmiCache{
 		m: new(sync.Mutex),
 		// match:  match.New(),
-		caches: make(map[string]*subCache),
+		caches:  make(map[string]*subCache),
+		lrw:     list.New(),
+		entries: make(map[string]*list.Element),
 	}
 	cfg.setDefaults()
 
@@ -80,6 +102,10 @@ func newGNMICache(cfg *Config, loggingPrefix string, opts ...Option) *gnmiCache
 func (gc *subCache) update(n *ctree.Leaf) {
 	switch v := n.Value().(type) {
 	case *gnmi.Notification:
+		// evicted values still exist on the target
+		if v.GetTimestamp() == evictionTimestamp && len(v.GetDelete()) > 0 {
+			return
+		}
 		pathElems := path.ToStrings(v.GetPrefix(), true)
 		subscribe.UpdateNotification(gc.match, n, v, pathElems)
 	default:
@@ -142,6 +168,7 @@ func (gc *gnmiCache) Write(ctx context.Context, measName string, m proto.Message
 				gc.logger.Printf("failed to update gNMI cache: %v", err)
 				return
 			}
+			gc.evict(gc.written(measName, notif))
 			return
 		}
 	}
@@ -443,6 +470,80 @@ func (gc *gnmiCache) DeleteTarget(name string) {
 	for _, c := range caches {
 		c.c.Remove(name)
 	}
+	gc.m.Lock()
+	defer gc.m.Unlock()
+	for e := gc.lrw.Front(); e != nil; {
+		next := e.Next()
+		if ce := e.Value.(*cacheEntry); ce.target == name {
+			gc.lrw.Remove(e)
+			delete(gc.entries, ce.key)
+		}
+		e = next
+	}
+}
+
+func (gc *gnmiCache) Stats() *Stats {
+	gc.m.Lock()
+	defer gc.m.Unlock()
+	return &Stats{
+		Entries:   int64(gc.lrw.Len()),
+		Evictions: gc.evictions,
+	}
+}
+
+// written marks the leaves updated by notification n in subscription sub
+// as the most recently written entries.
+// It returns the least recently written entries in excess of maxEntries.
+func (gc *gnmiCache) written(sub string, n *gnmi.Notification) []*cacheEntry {
+	if gc.maxEntries <= 0 {
+		return nil
+	}
+	target := n.GetPrefix().GetTarget()
+	gc.m.Lock()
+	defer gc.m.Unlock()
+	for _, upd := range n.GetUpdate() {
+		p := &gnmi.Path{
+			Origin: n.GetPrefix().GetOrigin(),
+			Elem:   make([]*gnmi.PathElem, 0, len(n.GetPrefix().GetElem())+len(upd.GetPath().GetElem())),
+		}
+		p.Elem = append(p.Elem, n.GetPrefix().GetElem()...)
+		p.Elem = append(p.Elem, upd.GetPath().GetElem()...)
+		key := strings.Join([]string{sub, target, utils.GnmiPathToXPath(p, false)}, "/")
+		if e, ok := gc.entries[key]; ok {
+			gc.lrw.MoveToFront(e)
+			continue
+		}
+		gc.entries[key] = gc.lrw.PushFront(&cacheEntry{key: key, sub: sub, target: target, path: p})
+	}
+	evicted := make([]*cacheEntry, 0)
+	for int64(gc.lrw.Len()) > gc.maxEntries {
+		e := gc.lrw.Back()
+		ce := e.Value.(*cacheEntry)
+		gc.lrw.Remove(e)
+		delete(gc.entries, ce.key)
+		gc.evictions++
+		evicted = append(evicted, ce)
+	}
+	return evicted
+}
+
+// evict drops the given entries from the cache,
+// without sending a delete notification to the subscribers.
+func (gc *gnmiCache) evict(entries []*cacheEntry) {
+	for _, ce := range entries {
+		sCache, ok := gc.getCaches(ce.sub)[ce.sub]
+		if !ok {
+			continue
+		}
+		err := sCache.c.GnmiUpdate(&gnmi.Notification{
+			Timestamp: evictionTimestamp,
+			Prefix:    &gnmi.Path{Target: ce.target, Origin: ce.path.GetOrigin()},
+			Delete:    []*gnmi.Path{{Elem: ce.path.GetElem()}},
+		})
+		if err != nil && gc.debug {
+			gc.logger.Printf("failed to evict %q from cache: %v", ce.key, err)
+		}
+	}
 }
 
 // match client
@@ -212,3 +212,55 @@ func Test_gnmiCache_read(t *testing.T) {
 		})
 	}
 }
+
+func Test_gnmiCache_eviction(t *testing.T) {
+	gc := newGNMICache(&Config{MaxEntries: 2}, "oc", WithLogger(log.Default()))
+	leaf := func(name string, ts int64) *gnmi.SubscribeResponse {
+		return &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{
+					Timestamp: ts,
+					Prefix:    &gnmi.Path{Target: "t1"},
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: name}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: name}},
+						},
+					},
+				},
+			},
+		}
+	}
+	now := time.Now().UnixNano()
+	gc.Write(context.TODO(), "sub1", leaf("a", now))
+	// on-change subscriber
+	ch := make(chan *Notification, 10)
+	remove := gc.getCaches("sub1")["sub1"].match.AddQuery([]string{"t1"}, &matchClient{name: "sub1", ch: ch})
+	defer remove()
+	gc.Write(context.TODO(), "sub1", leaf("b", now+1))
+	// a is now the most recently written entry
+	gc.Write(context.TODO(), "sub1", leaf("a", now+2))
+	gc.Write(context.TODO(), "sub1", leaf("c", now+3))
+
+	stats := gc.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("unexpected cache stats: %+v", stats)
+	}
+	close(ch)
+	for n := range ch {
+		if len(n.Notification.GetDelete()) > 0 {
+			t.Errorf("unexpected delete notification sent to the subscribers: %v", n.Notification)
+		}
+	}
+	for name, expected := range map[string]int{"a": 1, "b": 0, "c": 1} {
+		rsp := gc.read("sub1", "t1", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: name}}})
+		if len(rsp["sub1"]) != expected {
+			t.Errorf("path %q: unexpected response count, got %d, expected %d", name, len(rsp["sub1"]), expected)
+			continue
+		}
+		// the original timestamps are kept
+		if expected > 0 && name == "a" && rsp["sub1"][0].GetTimestamp() != now+2 {
+			t.Errorf("path %q: unexpected timestamp %d", name, rsp["sub1"][0].GetTimestamp())
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+func newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "backup",
+		Short:        "save the targets configuration to timestamped snapshot files",
+		PreRunE:      gApp.BackupPreRunE,
+		RunE:         gApp.BackupRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitBackupFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,31 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command
+func newCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "evaluate a file of checks against the targets values and report pass/fail",
+		Annotations: map[string]string{
+			"--file":   "FILE",
+			"--prefix": "PREFIX",
+			"--type":   "STORE",
+		},
+		PreRunE:      gApp.CheckPreRunE,
+		RunE:         gApp.CheckRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitCheckFlags(cmd)
+	return cmd
+}
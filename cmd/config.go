@@ -0,0 +1,105 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "config",
+		Short:             "configuration file utilities",
+		PersistentPreRunE: gApp.ConfigPreRunE,
+	}
+	return cmd
+}
+
+// configLintCmd represents the config lint command
+func newConfigLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "lint [file]",
+		Short:        "validate a configuration file",
+		Args:         cobra.MaximumNArgs(1),
+		RunE:         gApp.ConfigLintRunE,
+		SilenceUsage: true,
+	}
+	return cmd
+}
+
+// configInitCmd represents the config init command
+func newConfigInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "init",
+		Short:        "generate a commented configuration file",
+		RunE:         gApp.ConfigInitRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigInitFlags(cmd)
+	return cmd
+}
+
+// configShowCmd represents the config show command
+func newConfigShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "show",
+		Short:        "show the configuration read from the configuration file(s)",
+		RunE:         gApp.ConfigShowRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigShowFlags(cmd)
+	return cmd
+}
+
+// configEncryptCmd represents the config encrypt command
+func newConfigEncryptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "encrypt",
+		Short:        "encrypt a configuration value",
+		RunE:         gApp.ConfigEncryptRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigEncryptFlags(cmd)
+	return cmd
+}
+
+// configTargetsCmd represents the config targets command
+func newConfigTargetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "targets",
+		Short:        "list the configured targets",
+		RunE:         gApp.ConfigTargetsRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigTargetsFlags(cmd)
+	return cmd
+}
+
+// configSubscriptionsCmd represents the config subscriptions command
+func newConfigSubscriptionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "subscriptions",
+		Short:        "list the configured subscriptions",
+		RunE:         gApp.ConfigSubscriptionsRunE,
+		SilenceUsage: true,
+	}
+	return cmd
+}
+
+// configOutputsCmd represents the config outputs command
+func newConfigOutputsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "outputs",
+		Short:        "list the configured outputs",
+		RunE:         gApp.ConfigOutputsRunE,
+		SilenceUsage: true,
+	}
+	return cmd
+}
@@ -0,0 +1,47 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// credentialsCmd represents the credentials command
+func newCredentialsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "credentials",
+		Aliases: []string{"creds"},
+		Short:   "manage the targets passwords stored in the OS keyring",
+	}
+	return cmd
+}
+
+// credentialsSetCmd represents the credentials set command
+func newCredentialsSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "set <target>",
+		Short:        "store a target password in the OS keyring",
+		Args:         cobra.ExactArgs(1),
+		RunE:         gApp.CredentialsSetRunE,
+		SilenceUsage: true,
+	}
+	return cmd
+}
+
+// credentialsDeleteCmd represents the credentials delete command
+func newCredentialsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "delete <target>",
+		Short:        "delete a target password from the OS keyring",
+		Args:         cobra.ExactArgs(1),
+		RunE:         gApp.CredentialsDeleteRunE,
+		SilenceUsage: true,
+	}
+	return cmd
+}
@@ -0,0 +1,49 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetClosedStdin runs the get command with a username, without a password
+// and with stdin closed: it must fail right away instead of waiting for a password.
+func TestGetClosedStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	defer r.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"--address", "127.0.0.1:1", "--username", "admin", "--insecure", "get", "--path", "/system"})
+	rootCmd.SilenceUsage = true
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- rootCmd.Execute()
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("expected a missing credentials error")
+		}
+		if !strings.Contains(err.Error(), "missing credentials") || !strings.Contains(err.Error(), "--password") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("get command did not fail within 5s with stdin closed")
+	}
+}
@@ -32,3 +32,20 @@ func newPathCmd() *cobra.Command {
 	gApp.InitPathFlags(cmd)
 	return cmd
 }
+
+// pathSearchCmd represents the path search command
+func newPathSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [keywords]",
+		Short: "list the xpaths matching all the keywords",
+		Annotations: map[string]string{
+			"--file": "YANG",
+			"--dir":  "DIR",
+		},
+		PreRunE:      gApp.PathSearchPreRunE,
+		RunE:         gApp.PathSearchRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitPathSearchFlags(cmd)
+	return cmd
+}
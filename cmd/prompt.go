@@ -102,6 +102,7 @@ var promptQuitCmd = &cobra.Command{
 	Run: func(_ *cobra.Command, _ []string) {
 		// cancel gctx
 		gApp.Cfn()
+		gApp.CloseAuditLog()
 		// save history
 		home, err := homedir.Dir()
 		if err != nil {
@@ -50,23 +50,51 @@ func newRootCmd() *cobra.Command {
 		PersistentPreRunE: gApp.PreRunE,
 	}
 	gApp.InitGlobalFlags()
+	gApp.RootCmd.AddCommand(newAPICmd())
+	gApp.RootCmd.AddCommand(newBackupCmd())
 	gApp.RootCmd.AddCommand(newCompletionCmd())
 	gApp.RootCmd.AddCommand(newCapabilitiesCmd())
+	gApp.RootCmd.AddCommand(newCheckCmd())
+	//
+	configCmd := newConfigCmd()
+	configCmd.AddCommand(newConfigEncryptCmd())
+	configCmd.AddCommand(newConfigInitCmd())
+	configCmd.AddCommand(newConfigLintCmd())
+	configCmd.AddCommand(newConfigShowCmd())
+	configCmd.AddCommand(newConfigTargetsCmd())
+	configCmd.AddCommand(newConfigSubscriptionsCmd())
+	configCmd.AddCommand(newConfigOutputsCmd())
+	gApp.RootCmd.AddCommand(configCmd)
+	//
+	credsCmd := newCredentialsCmd()
+	credsCmd.AddCommand(newCredentialsSetCmd())
+	credsCmd.AddCommand(newCredentialsDeleteCmd())
+	gApp.RootCmd.AddCommand(credsCmd)
+	//
 	gApp.RootCmd.AddCommand(newGetCmd())
 	gApp.RootCmd.AddCommand(newGetSetCmd())
 	gApp.RootCmd.AddCommand(newListenCmd())
-	gApp.RootCmd.AddCommand(newPathCmd())
 	gApp.RootCmd.AddCommand(newDiffCmd())
 	//
+	pathCmd := newPathCmd()
+	pathCmd.AddCommand(newPathSearchCmd())
+	gApp.RootCmd.AddCommand(pathCmd)
+	//
 	genCmd := newGenerateCmd()
 	genCmd.AddCommand(newGenerateSetRequestCmd())
 	genCmd.AddCommand(newGeneratePathCmd())
 	gApp.RootCmd.AddCommand(genCmd)
 	//
 	gApp.RootCmd.AddCommand(newPromptCmd())
+	gApp.RootCmd.AddCommand(newRestoreCmd())
+	gApp.RootCmd.AddCommand(newRunCmd())
 	gApp.RootCmd.AddCommand(newSetCmd())
 	gApp.RootCmd.AddCommand(newSubscribeCmd())
 	//
+	tunnelCmd := newTunnelCmd()
+	tunnelCmd.AddCommand(newTunnelTargetsCmd())
+	gApp.RootCmd.AddCommand(tunnelCmd)
+	//
 	versionCmd := newVersionCmd()
 	versionCmd.AddCommand(newVersionUpgradeCmd())
 	gApp.RootCmd.AddCommand(versionCmd)
@@ -78,7 +106,14 @@ func newRootCmd() *cobra.Command {
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	setupCloseHandler(gApp.Cfn)
-	if err := newRootCmd().Execute(); err != nil {
+	err := newRootCmd().Execute()
+	gApp.FlushOutputs()
+	gApp.CloseOutput()
+	gApp.PrintStats()
+	if err != nil || !gApp.PromptMode {
+		gApp.CloseAuditLog()
+	}
+	if err != nil {
 		//fmt.Println(err)
 		os.Exit(1)
 	}
@@ -109,6 +144,13 @@ func setupCloseHandler(cancelFn context.CancelFunc) {
 		sig := <-c
 		fmt.Printf("\nreceived signal '%s'. terminating...\n", sig.String())
 		cancelFn()
+		// write the messages still queued to the outputs before exiting,
+		// then let the outputs send the messages they buffer.
+		gApp.FlushOutputs()
+		gApp.CloseOutputs()
+		gApp.CloseOutput()
+		gApp.PrintStats()
+		gApp.CloseAuditLog()
 		os.Exit(0)
 	}()
 }
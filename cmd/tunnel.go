@@ -0,0 +1,34 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// tunnelCmd represents the tunnel command
+func newTunnelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tunnel",
+		Short: "gRPC tunnel server utilities",
+	}
+	return cmd
+}
+
+// tunnelTargetsCmd represents the tunnel targets command
+func newTunnelTargetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "targets",
+		Short:        "run the tunnel server and list the registered targets",
+		RunE:         gApp.TunnelTargetsRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitTunnelTargetsFlags(cmd)
+	return cmd
+}
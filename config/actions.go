@@ -12,9 +12,13 @@ import (
 	"fmt"
 
 	"github.com/openconfig/gnmic/actions"
+	"github.com/openconfig/gnmic/types"
 )
 
 func (c *Config) GetActions() (map[string]map[string]interface{}, error) {
+	if c.Actions == nil {
+		c.Actions = make(map[string]map[string]interface{})
+	}
 	for name, actc := range c.FileConfig.GetStringMap("actions") {
 		switch actc := actc.(type) {
 		case map[string]interface{}:
@@ -23,6 +27,9 @@ func (c *Config) GetActions() (map[string]map[string]interface{}, error) {
 			if err != nil {
 				return nil, err
 			}
+			if c.ReadOnly && isMutatingAction(actc) {
+				return nil, fmt.Errorf("action %q: %w", name, types.ErrReadOnly)
+			}
 			// set action name if not configured
 			if cname, ok := actc["name"]; !ok || cname == "" {
 				actc["name"] = name
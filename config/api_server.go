@@ -14,9 +14,10 @@ import (
 )
 
 const (
-	defaultAPIServerAddress = ":7890"
-	defaultAPIServerTimeout = 10 * time.Second
-	trueString              = "true"
+	defaultAPIServerAddress              = ":7890"
+	defaultAPIServerTimeout              = 10 * time.Second
+	defaultAPIServerSubscribeOnceTimeout = 30 * time.Second
+	trueString                           = "true"
 )
 
 type APIServer struct {
@@ -27,15 +28,36 @@ type APIServer struct {
 	CaFile     string `mapstructure:"ca-file,omitempty" json:"ca-file,omitempty"`
 	CertFile   string `mapstructure:"cert-file,omitempty" json:"cert-file,omitempty"`
 	KeyFile    string `mapstructure:"key-file,omitempty" json:"key-file,omitempty"`
+	// static token expected in the Authorization header
+	Token string `mapstructure:"token,omitempty" json:"-"`
+	// allow the RPC endpoints to dial targets missing from the config, using the global flags
+	AllowAdHocTargets bool `mapstructure:"allow-ad-hoc-targets,omitempty" json:"allow-ad-hoc-targets,omitempty"`
 	//
 	EnableMetrics bool `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
 	Debug         bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// RPC endpoints, enabled by the api command
+	EnableRPCs           bool          `mapstructure:"-" json:"enable-rpcs,omitempty"`
+	SubscribeOnceTimeout time.Duration `mapstructure:"subscribe-once-timeout,omitempty" json:"subscribe-once-timeout,omitempty"`
 }
 
 func (c *Config) GetAPIServer() error {
 	if !c.FileConfig.IsSet("api-server") && c.API == "" {
 		return nil
 	}
+	c.readAPIServer()
+	return nil
+}
+
+// SetDefaultAPIServer reads the API server config if present,
+// otherwise it sets an API server config with default values.
+func (c *Config) SetDefaultAPIServer() {
+	if c.APIServer != nil {
+		return
+	}
+	c.readAPIServer()
+}
+
+func (c *Config) readAPIServer() {
 	c.APIServer = new(APIServer)
 	c.APIServer.Address = os.ExpandEnv(c.FileConfig.GetString("api-server/address"))
 	if c.APIServer.Address == "" {
@@ -46,11 +68,13 @@ func (c *Config) GetAPIServer() error {
 	c.APIServer.CaFile = os.ExpandEnv(c.FileConfig.GetString("api-server/ca-file"))
 	c.APIServer.CertFile = os.ExpandEnv(c.FileConfig.GetString("api-server/cert-file"))
 	c.APIServer.KeyFile = os.ExpandEnv(c.FileConfig.GetString("api-server/key-file"))
+	c.APIServer.Token = os.ExpandEnv(c.FileConfig.GetString("api-server/token"))
+	c.APIServer.SubscribeOnceTimeout = c.FileConfig.GetDuration("api-server/subscribe-once-timeout")
+	c.APIServer.AllowAdHocTargets = os.ExpandEnv(c.FileConfig.GetString("api-server/allow-ad-hoc-targets")) == trueString
 
 	c.APIServer.EnableMetrics = os.ExpandEnv(c.FileConfig.GetString("api-server/enable-metrics")) == trueString
 	c.APIServer.Debug = os.ExpandEnv(c.FileConfig.GetString("api-server/debug")) == trueString
 	c.setAPIServerDefaults()
-	return nil
 }
 
 func (c *Config) setAPIServerDefaults() {
@@ -60,4 +84,7 @@ func (c *Config) setAPIServerDefaults() {
 	if c.APIServer.Timeout <= 0 {
 		c.APIServer.Timeout = defaultAPIServerTimeout
 	}
+	if c.APIServer.SubscribeOnceTimeout <= 0 {
+		c.APIServer.SubscribeOnceTimeout = defaultAPIServerSubscribeOnceTimeout
+	}
 }
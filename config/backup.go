@@ -0,0 +1,35 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
+)
+
+// CreateBackupGetRequest returns the GetRequest used to backup the targets configuration.
+func (c *Config) CreateBackupGetRequest() (*gnmi.GetRequest, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w", ErrInvalidConfig)
+	}
+	gnmiOpts := make([]api.GNMIOption, 0, 4+len(c.LocalFlags.BackupPath))
+	gnmiOpts = append(gnmiOpts,
+		api.Encoding(c.Encoding),
+		api.DataType("CONFIG"),
+		api.Prefix(c.LocalFlags.BackupPrefix),
+		api.Target(c.LocalFlags.BackupTarget),
+	)
+	for _, p := range c.LocalFlags.BackupPath {
+		gnmiOpts = append(gnmiOpts, api.Path(strings.TrimSpace(p)))
+	}
+	return api.NewGetRequest(gnmiOpts...)
+}
@@ -0,0 +1,138 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
+	"github.com/openconfig/gnmic/types"
+	"gopkg.in/yaml.v2"
+)
+
+// check operators names and their assertion symbol
+var checkOperators = map[string]string{
+	"equals":     "==",
+	"not-equals": "!=",
+	"regex":      "=~",
+	"lt":         "<",
+	"le":         "<=",
+	"gt":         ">",
+	"ge":         ">=",
+	"exists":     "exists",
+	"absent":     "absent",
+}
+
+// CheckConfig is a single check of a checks file,
+// evaluated against the values returned by a target for Path.
+type CheckConfig struct {
+	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Path     string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Operator string      `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+type checksFile struct {
+	Checks []*CheckConfig `yaml:"checks,omitempty"`
+}
+
+// ReadChecksFile reads and validates the checks file set with --file.
+// The check operators are normalized to their symbol, e.g: `ge` becomes `>=`.
+func (c *Config) ReadChecksFile() ([]*CheckConfig, error) {
+	if c.LocalFlags.CheckFile == "" {
+		return nil, errors.New("missing required flag --file")
+	}
+	b, err := os.ReadFile(c.LocalFlags.CheckFile)
+	if err != nil {
+		return nil, err
+	}
+	cf := new(checksFile)
+	err = yaml.Unmarshal(b, cf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode checks file %q: %v", c.LocalFlags.CheckFile, err)
+	}
+	if len(cf.Checks) == 0 {
+		return nil, fmt.Errorf("checks file %q: no checks found", c.LocalFlags.CheckFile)
+	}
+	for i, ch := range cf.Checks {
+		err = validateCheck(ch)
+		if err != nil {
+			return nil, fmt.Errorf("checks file %q: check %d: %v", c.LocalFlags.CheckFile, i+1, err)
+		}
+	}
+	return cf.Checks, nil
+}
+
+func validateCheck(ch *CheckConfig) error {
+	ch.Path = strings.TrimSpace(ch.Path)
+	if ch.Path == "" {
+		return errors.New("missing path")
+	}
+	if ch.Name == "" {
+		ch.Name = ch.Path
+	}
+	op := strings.ToLower(strings.TrimSpace(ch.Operator))
+	if sym, ok := checkOperators[op]; ok {
+		op = sym
+	}
+	found := false
+	for _, sym := range checkOperators {
+		if op == sym {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%q: unknown operator %q", ch.Name, ch.Operator)
+	}
+	ch.Operator = op
+	switch op {
+	case "exists", "absent":
+		if ch.Value != nil {
+			return fmt.Errorf("%q: operator %q does not take a value", ch.Name, op)
+		}
+	default:
+		if ch.Value == nil {
+			return fmt.Errorf("%q: operator %q requires a value", ch.Name, op)
+		}
+	}
+	return nil
+}
+
+// CreateCheckGetRequest returns the GetRequest retrieving the values of a check path.
+func (c *Config) CreateCheckGetRequest(ch *CheckConfig) (*gnmi.GetRequest, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w", ErrInvalidConfig)
+	}
+	return api.NewGetRequest(
+		api.Encoding(c.Encoding),
+		api.DataType(c.LocalFlags.CheckType),
+		api.Prefix(c.LocalFlags.CheckPrefix),
+		api.Target(c.LocalFlags.CheckTarget),
+		api.Path(ch.Path),
+	)
+}
+
+// CreateCheckSubscribeRequest returns the ONCE mode SubscribeRequest
+// retrieving the values of a check path.
+func (c *Config) CreateCheckSubscribeRequest(ch *CheckConfig) (*gnmi.SubscribeRequest, error) {
+	sc := &types.SubscriptionConfig{
+		Name:     "check",
+		Prefix:   c.LocalFlags.CheckPrefix,
+		Target:   c.LocalFlags.CheckTarget,
+		Paths:    []string{ch.Path},
+		Mode:     "ONCE",
+		Encoding: c.Encoding,
+	}
+	return c.CreateSubscribeRequest(sc, "")
+}
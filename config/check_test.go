@@ -0,0 +1,112 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var readChecksFileTestSet = map[string]struct {
+	in        string
+	operators []string
+	err       bool
+}{
+	"operators": {
+		in: `
+checks:
+  - path: /system/name
+    operator: equals
+    value: r1
+  - name: cpu
+    path: /system/cpu/total
+    operator: "<"
+    value: 80
+  - path: /interface[name=*]/oper-status
+    operator: REGEX
+    value: ^UP$
+  - path: /system/logging
+    operator: absent
+`,
+		operators: []string{"==", "<", "=~", "absent"},
+	},
+	"unknown_operator": {
+		in: `
+checks:
+  - path: /system/name
+    operator: contains
+    value: r1
+`,
+		err: true,
+	},
+	"missing_path": {
+		in: `
+checks:
+  - operator: exists
+`,
+		err: true,
+	},
+	"missing_value": {
+		in: `
+checks:
+  - path: /system/name
+    operator: not-equals
+`,
+		err: true,
+	},
+	"unexpected_value": {
+		in: `
+checks:
+  - path: /system/name
+    operator: exists
+    value: r1
+`,
+		err: true,
+	},
+	"no_checks": {
+		in:  "checks: []",
+		err: true,
+	},
+}
+
+func TestReadChecksFile(t *testing.T) {
+	for name, item := range readChecksFileTestSet {
+		t.Run(name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "checks.yaml")
+			err := os.WriteFile(f, []byte(item.in), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := New()
+			c.LocalFlags.CheckFile = f
+			checks, err := c.ReadChecksFile()
+			if item.err {
+				if err == nil {
+					t.Errorf("failed at item %q: expected an error", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			if len(checks) != len(item.operators) {
+				t.Fatalf("failed at item %q: expected %d checks, got %d", name, len(item.operators), len(checks))
+			}
+			for i, ch := range checks {
+				if ch.Operator != item.operators[i] {
+					t.Errorf("failed at item %q: check %d: expected operator %q, got %q", name, i, item.operators[i], ch.Operator)
+				}
+				if ch.Name == "" {
+					t.Errorf("failed at item %q: check %d: missing default name", name, i)
+				}
+			}
+		})
+	}
+}
@@ -40,6 +40,8 @@ const (
 	configName      = ".gnmic"
 	configLogPrefix = "[config] "
 	envPrefix       = "GNMIC"
+
+	defaultCliOrigin = "cli"
 )
 
 var ErrInvalidConfig = errors.New("invalid configuration")
@@ -62,16 +64,35 @@ type Config struct {
 	Loader        map[string]interface{}               `mapstructure:"loader,omitempty" json:"loader,omitempty" yaml:"loader,omitempty"`
 	Actions       map[string]map[string]interface{}    `mapstructure:"actions,omitempty" json:"actions,omitempty" yaml:"actions,omitempty"`
 	TunnelServer  *tunnelServer                        `mapstructure:"tunnel-server,omitempty" json:"tunnel-server,omitempty" yaml:"tunnel-server,omitempty"`
+	Profiles      map[string]map[string]interface{}    `mapstructure:"profiles,omitempty" json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// targets excluded by `enabled: false` or --skip-target, per name, with the reason
+	SkippedTargets map[string]string `mapstructure:"-" json:"-" yaml:"-"`
+
 	//
 	logger             *log.Logger
 	setRequestTemplate []*template.Template
 	setRequestVars     map[string]interface{}
+	// settings read from the configuration files, before env vars and flags are applied
+	fileSettings map[string]interface{}
+	// scalar keys set by more than one configuration file
+	fileConflicts []string
+	// configuration file setting each key, the last one wins
+	fileOrigins map[string]*fileOrigin
+	// passwords read from the OS keyring or prompted, per target name
+	keyringPasswords map[string]string
+	// set once the OS keyring unavailability warning is printed
+	keyringWarned bool
+	// configuration values encryption key
+	configKey []byte
+	// password prompted once per run, used by all the targets missing one
+	promptedPassword *string
 }
 
 var ValueTypes = []string{"json", "json_ietf", "string", "int", "uint", "bool", "decimal", "float", "bytes", "ascii"}
 
 type GlobalFlags struct {
-	CfgFile       string
+	CfgFile       []string
 	Address       []string      `mapstructure:"address,omitempty" json:"address,omitempty" yaml:"address,omitempty"`
 	Username      string        `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
 	Password      string        `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
@@ -84,11 +105,14 @@ type GlobalFlags struct {
 	TLSMinVersion string        `mapstructure:"tls-min-version,omitempty" json:"tls-min-version,omitempty" yaml:"tls-min-version,omitempty"`
 	TLSMaxVersion string        `mapstructure:"tls-max-version,omitempty" json:"tls-max-version,omitempty" yaml:"tls-max-version,omitempty"`
 	TLSVersion    string        `mapstructure:"tls-version,omitempty" json:"tls-version,omitempty" yaml:"tls-version,omitempty"`
+	TLSPin        []string      `mapstructure:"tls-pin,omitempty" json:"tls-pin,omitempty" yaml:"tls-pin,omitempty"`
 	LogTLSSecret  bool          `mapstructure:"log-tls-secret,omitempty" json:"log-tls-secret,omitempty" yaml:"log-tls-secret,omitempty"`
 	Timeout       time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	TotalTimeout  time.Duration `mapstructure:"total-timeout,omitempty" json:"total-timeout,omitempty" yaml:"total-timeout,omitempty"`
 	Debug         bool          `mapstructure:"debug,omitempty" json:"debug,omitempty" yaml:"debug,omitempty"`
 	SkipVerify    bool          `mapstructure:"skip-verify,omitempty" json:"skip-verify,omitempty" yaml:"skip-verify,omitempty"`
 	NoPrefix      bool          `mapstructure:"no-prefix,omitempty" json:"no-prefix,omitempty" yaml:"no-prefix,omitempty"`
+	PrefixFormat  string        `mapstructure:"prefix-format,omitempty" json:"prefix-format,omitempty" yaml:"prefix-format,omitempty"`
 	ProxyFromEnv  bool          `mapstructure:"proxy-from-env,omitempty" json:"proxy-from-env,omitempty" yaml:"proxy-from-env,omitempty"`
 	Format        string        `mapstructure:"format,omitempty" json:"format,omitempty" yaml:"format,omitempty"`
 	LogFile       string        `mapstructure:"log-file,omitempty" json:"log-file,omitempty" yaml:"log-file,omitempty"`
@@ -98,36 +122,96 @@ type GlobalFlags struct {
 	LogCompress   bool          `mapstructure:"log-compress,omitempty" json:"log-compress,omitempty" yaml:"log-compress,omitempty"`
 	MaxMsgSize    int           `mapstructure:"max-msg-size,omitempty" json:"max-msg-size,omitempty" yaml:"max-msg-size,omitempty"`
 	//PrometheusAddress string        `mapstructure:"prometheus-address,omitempty" json:"prometheus-address,omitempty" yaml:"prometheus-address,omitempty"`
-	PrintRequest     bool          `mapstructure:"print-request,omitempty" json:"print-request,omitempty" yaml:"print-request,omitempty"`
-	Retry            time.Duration `mapstructure:"retry,omitempty" json:"retry,omitempty" yaml:"retry,omitempty"`
-	TargetBufferSize uint          `mapstructure:"target-buffer-size,omitempty" json:"target-buffer-size,omitempty" yaml:"target-buffer-size,omitempty"`
-	ClusterName      string        `mapstructure:"cluster-name,omitempty" json:"cluster-name,omitempty" yaml:"cluster-name,omitempty"`
-	InstanceName     string        `mapstructure:"instance-name,omitempty" json:"instance-name,omitempty" yaml:"instance-name,omitempty"`
-	API              string        `mapstructure:"api,omitempty" json:"api,omitempty" yaml:"api,omitempty"`
-	ProtoFile        []string      `mapstructure:"proto-file,omitempty" json:"proto-file,omitempty" yaml:"proto-file,omitempty"`
-	ProtoDir         []string      `mapstructure:"proto-dir,omitempty" json:"proto-dir,omitempty" yaml:"proto-dir,omitempty"`
-	TargetsFile      string        `mapstructure:"targets-file,omitempty" json:"targets-file,omitempty" yaml:"targets-file,omitempty"`
-	Gzip             bool          `mapstructure:"gzip,omitempty" json:"gzip,omitempty" yaml:"gzip,omitempty"`
-	File             []string      `mapstructure:"file,omitempty" json:"file,omitempty" yaml:"file,omitempty"`
-	Dir              []string      `mapstructure:"dir,omitempty" json:"dir,omitempty" yaml:"dir,omitempty"`
-	Exclude          []string      `mapstructure:"exclude,omitempty" json:"exclude,omitempty" yaml:"exclude,omitempty"`
-	Token            string        `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
-	UseTunnelServer  bool          `mapstructure:"use-tunnel-server,omitempty" json:"use-tunnel-server,omitempty" yaml:"use-tunnel-server,omitempty"`
+	PrintRequest            bool          `mapstructure:"print-request,omitempty" json:"print-request,omitempty" yaml:"print-request,omitempty"`
+	Retry                   time.Duration `mapstructure:"retry,omitempty" json:"retry,omitempty" yaml:"retry,omitempty"`
+	TargetBufferSize        uint          `mapstructure:"target-buffer-size,omitempty" json:"target-buffer-size,omitempty" yaml:"target-buffer-size,omitempty"`
+	ClusterName             string        `mapstructure:"cluster-name,omitempty" json:"cluster-name,omitempty" yaml:"cluster-name,omitempty"`
+	InstanceName            string        `mapstructure:"instance-name,omitempty" json:"instance-name,omitempty" yaml:"instance-name,omitempty"`
+	API                     string        `mapstructure:"api,omitempty" json:"api,omitempty" yaml:"api,omitempty"`
+	ProtoFile               []string      `mapstructure:"proto-file,omitempty" json:"proto-file,omitempty" yaml:"proto-file,omitempty"`
+	ProtoDir                []string      `mapstructure:"proto-dir,omitempty" json:"proto-dir,omitempty" yaml:"proto-dir,omitempty"`
+	TargetsFile             string        `mapstructure:"targets-file,omitempty" json:"targets-file,omitempty" yaml:"targets-file,omitempty"`
+	AddressFile             string        `mapstructure:"address-file,omitempty" json:"address-file,omitempty" yaml:"address-file,omitempty"`
+	Gzip                    bool          `mapstructure:"gzip,omitempty" json:"gzip,omitempty" yaml:"gzip,omitempty"`
+	Stats                   bool          `mapstructure:"stats,omitempty" json:"stats,omitempty" yaml:"stats,omitempty"`
+	OutputFile              string        `mapstructure:"output-file,omitempty" json:"output-file,omitempty" yaml:"output-file,omitempty"`
+	OutputFileSync          bool          `mapstructure:"output-file-sync,omitempty" json:"output-file-sync,omitempty" yaml:"output-file-sync,omitempty"`
+	MaxLines                int           `mapstructure:"max-lines,omitempty" json:"max-lines,omitempty" yaml:"max-lines,omitempty"`
+	Pager                   bool          `mapstructure:"pager,omitempty" json:"pager,omitempty" yaml:"pager,omitempty"`
+	ReadOnly                bool          `mapstructure:"read-only,omitempty" json:"read-only,omitempty" yaml:"read-only,omitempty"`
+	File                    []string      `mapstructure:"file,omitempty" json:"file,omitempty" yaml:"file,omitempty"`
+	Dir                     []string      `mapstructure:"dir,omitempty" json:"dir,omitempty" yaml:"dir,omitempty"`
+	Exclude                 []string      `mapstructure:"exclude,omitempty" json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	Token                   string        `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
+	UseTunnelServer         bool          `mapstructure:"use-tunnel-server,omitempty" json:"use-tunnel-server,omitempty" yaml:"use-tunnel-server,omitempty"`
+	AuditFile               string        `mapstructure:"audit-file,omitempty" json:"audit-file,omitempty" yaml:"audit-file,omitempty"`
+	AuditRPCs               []string      `mapstructure:"audit-rpcs,omitempty" json:"audit-rpcs,omitempty" yaml:"audit-rpcs,omitempty"`
+	EventTag                []string      `mapstructure:"event-tag,omitempty" json:"event-tag,omitempty" yaml:"event-tag,omitempty"`
+	JSONIndent              string        `mapstructure:"json-indent,omitempty" json:"json-indent,omitempty" yaml:"json-indent,omitempty"`
+	NumAsString             bool          `mapstructure:"num-as-string,omitempty" json:"num-as-string,omitempty" yaml:"num-as-string,omitempty"`
+	UseKeyring              bool          `mapstructure:"use-keyring,omitempty" json:"use-keyring,omitempty" yaml:"use-keyring,omitempty"`
+	ConfigKeyFile           string        `mapstructure:"config-key-file,omitempty" json:"config-key-file,omitempty" yaml:"config-key-file,omitempty"`
+	NoPrompt                bool          `mapstructure:"no-prompt,omitempty" json:"no-prompt,omitempty" yaml:"no-prompt,omitempty"`
+	SaveRequest             string        `mapstructure:"save-request,omitempty" json:"save-request,omitempty" yaml:"save-request,omitempty"`
+	Profile                 string        `mapstructure:"profile,omitempty" json:"profile,omitempty" yaml:"profile,omitempty"`
+	Dev                     bool          `mapstructure:"dev,omitempty" json:"dev,omitempty" yaml:"dev,omitempty"`
+	AnyAsBytes              bool          `mapstructure:"any-as-bytes,omitempty" json:"any-as-bytes,omitempty" yaml:"any-as-bytes,omitempty"`
+	TLSIgnoreCertErrors     bool          `mapstructure:"tls-ignore-cert-errors,omitempty" json:"tls-ignore-cert-errors,omitempty" yaml:"tls-ignore-cert-errors,omitempty"`
+	Force                   bool          `mapstructure:"force,omitempty" json:"force,omitempty" yaml:"force,omitempty"`
+	Query                   string        `mapstructure:"query,omitempty" json:"query,omitempty" yaml:"query,omitempty"`
+	QueryRaw                bool          `mapstructure:"query-raw,omitempty" json:"query-raw,omitempty" yaml:"query-raw,omitempty"`
+	ClientID                string        `mapstructure:"client-id,omitempty" json:"client-id,omitempty" yaml:"client-id,omitempty"`
+	AuthScheme              string        `mapstructure:"auth-scheme,omitempty" json:"auth-scheme,omitempty" yaml:"auth-scheme,omitempty"`
+	AuthUsernameKey         string        `mapstructure:"auth-username-key,omitempty" json:"auth-username-key,omitempty" yaml:"auth-username-key,omitempty"`
+	AuthPasswordKey         string        `mapstructure:"auth-password-key,omitempty" json:"auth-password-key,omitempty" yaml:"auth-password-key,omitempty"`
+	Group                   []string      `mapstructure:"group,omitempty" json:"group,omitempty" yaml:"group,omitempty"`
+	SplitMixedSubscriptions bool          `mapstructure:"split-mixed-subscriptions,omitempty" json:"split-mixed-subscriptions,omitempty" yaml:"split-mixed-subscriptions,omitempty"`
+	AllowDuplicateTargets   bool          `mapstructure:"allow-duplicate-targets,omitempty" json:"allow-duplicate-targets,omitempty" yaml:"allow-duplicate-targets,omitempty"`
+	SkipTarget              []string      `mapstructure:"skip-target,omitempty" json:"skip-target,omitempty" yaml:"skip-target,omitempty"`
+	EncodingFallback        []string      `mapstructure:"encoding-fallback,omitempty" json:"encoding-fallback,omitempty" yaml:"encoding-fallback,omitempty"`
+	GRPCRetry               bool          `mapstructure:"grpc-retry,omitempty" json:"grpc-retry,omitempty" yaml:"grpc-retry,omitempty"`
+	GRPCRetryMaxAttempts    int           `mapstructure:"grpc-retry-max-attempts,omitempty" json:"grpc-retry-max-attempts,omitempty" yaml:"grpc-retry-max-attempts,omitempty"`
+	GRPCRetryInitialBackoff time.Duration `mapstructure:"grpc-retry-initial-backoff,omitempty" json:"grpc-retry-initial-backoff,omitempty" yaml:"grpc-retry-initial-backoff,omitempty"`
+	GRPCRetryMaxBackoff     time.Duration `mapstructure:"grpc-retry-max-backoff,omitempty" json:"grpc-retry-max-backoff,omitempty" yaml:"grpc-retry-max-backoff,omitempty"`
+	GRPCRetryCodes          []string      `mapstructure:"grpc-retry-codes,omitempty" json:"grpc-retry-codes,omitempty" yaml:"grpc-retry-codes,omitempty"`
+	GRPCRetrySet            bool          `mapstructure:"grpc-retry-set,omitempty" json:"grpc-retry-set,omitempty" yaml:"grpc-retry-set,omitempty"`
+	RPS                     float64       `mapstructure:"rps,omitempty" json:"rps,omitempty" yaml:"rps,omitempty"`
+	Burst                   int           `mapstructure:"burst,omitempty" json:"burst,omitempty" yaml:"burst,omitempty"`
+	Timezone                string        `mapstructure:"timezone,omitempty" json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	IncludeMeta             bool          `mapstructure:"include-meta,omitempty" json:"include-meta,omitempty" yaml:"include-meta,omitempty"`
+	Progress                string        `mapstructure:"progress,omitempty" json:"progress,omitempty" yaml:"progress,omitempty"`
+	ProgressInterval        time.Duration `mapstructure:"progress-interval,omitempty" json:"progress-interval,omitempty" yaml:"progress-interval,omitempty"`
 }
 
 type LocalFlags struct {
 	// Capabilities
-	CapabilitiesVersion bool `mapstructure:"capabilities-version,omitempty" json:"capabilities-version,omitempty" yaml:"capabilities-version,omitempty"`
+	CapabilitiesVersion       bool   `mapstructure:"capabilities-version,omitempty" json:"capabilities-version,omitempty" yaml:"capabilities-version,omitempty"`
+	CapabilitiesSaveInventory string `mapstructure:"capabilities-save-inventory,omitempty" json:"capabilities-save-inventory,omitempty" yaml:"capabilities-save-inventory,omitempty"`
+	CapabilitiesDiffInventory bool   `mapstructure:"capabilities-diff-inventory,omitempty" json:"capabilities-diff-inventory,omitempty" yaml:"capabilities-diff-inventory,omitempty"`
 	// Get
-	GetPath       []string `mapstructure:"get-path,omitempty" json:"get-path,omitempty" yaml:"get-path,omitempty"`
-	GetPrefix     string   `mapstructure:"get-prefix,omitempty" json:"get-prefix,omitempty" yaml:"get-prefix,omitempty"`
-	GetModel      []string `mapstructure:"get-model,omitempty" json:"get-model,omitempty" yaml:"get-model,omitempty"`
-	GetType       string   `mapstructure:"get-type,omitempty" json:"get-type,omitempty" yaml:"get-type,omitempty"`
-	GetTarget     string   `mapstructure:"get-target,omitempty" json:"get-target,omitempty" yaml:"get-target,omitempty"`
-	GetValuesOnly bool     `mapstructure:"get-values-only,omitempty" json:"get-values-only,omitempty" yaml:"get-values-only,omitempty"`
-	GetProcessor  []string `mapstructure:"get-processor,omitempty" json:"get-processor,omitempty" yaml:"get-processor,omitempty"`
+	GetPath                 []string `mapstructure:"get-path,omitempty" json:"get-path,omitempty" yaml:"get-path,omitempty"`
+	GetPathFile             string   `mapstructure:"get-path-file,omitempty" json:"get-path-file,omitempty" yaml:"get-path-file,omitempty"`
+	GetEncoding             string   `mapstructure:"get-encoding,omitempty" json:"get-encoding,omitempty" yaml:"get-encoding,omitempty"`
+	GetPrefix               string   `mapstructure:"get-prefix,omitempty" json:"get-prefix,omitempty" yaml:"get-prefix,omitempty"`
+	GetModel                []string `mapstructure:"get-model,omitempty" json:"get-model,omitempty" yaml:"get-model,omitempty"`
+	GetType                 string   `mapstructure:"get-type,omitempty" json:"get-type,omitempty" yaml:"get-type,omitempty"`
+	GetTarget               string   `mapstructure:"get-target,omitempty" json:"get-target,omitempty" yaml:"get-target,omitempty"`
+	GetValuesOnly           bool     `mapstructure:"get-values-only,omitempty" json:"get-values-only,omitempty" yaml:"get-values-only,omitempty"`
+	GetProcessor            []string `mapstructure:"get-processor,omitempty" json:"get-processor,omitempty" yaml:"get-processor,omitempty"`
+	GetAssert               []string `mapstructure:"get-assert,omitempty" json:"get-assert,omitempty" yaml:"get-assert,omitempty"`
+	GetSummaryOnly          bool     `mapstructure:"get-summary-only,omitempty" json:"get-summary-only,omitempty" yaml:"get-summary-only,omitempty"`
+	GetAggregate            bool     `mapstructure:"get-aggregate,omitempty" json:"get-aggregate,omitempty" yaml:"get-aggregate,omitempty"`
+	GetBatchSize            int      `mapstructure:"get-batch-size,omitempty" json:"get-batch-size,omitempty" yaml:"get-batch-size,omitempty"`
+	GetContinueOnBatchError bool     `mapstructure:"get-continue-on-batch-error,omitempty" json:"get-continue-on-batch-error,omitempty" yaml:"get-continue-on-batch-error,omitempty"`
+	GetMaxMemory            int      `mapstructure:"get-max-memory,omitempty" json:"get-max-memory,omitempty" yaml:"get-max-memory,omitempty"`
+	GetAgain                bool     `mapstructure:"get-again,omitempty" json:"get-again,omitempty" yaml:"get-again,omitempty"`
+	GetAppend               bool     `mapstructure:"get-append,omitempty" json:"get-append,omitempty" yaml:"get-append,omitempty"`
+	GetExpandWildcards      bool     `mapstructure:"get-expand-wildcards,omitempty" json:"get-expand-wildcards,omitempty" yaml:"get-expand-wildcards,omitempty"`
+	GetExpandMax            int      `mapstructure:"get-expand-max,omitempty" json:"get-expand-max,omitempty" yaml:"get-expand-max,omitempty"`
+	GetYes                  bool     `mapstructure:"get-yes,omitempty" json:"get-yes,omitempty" yaml:"get-yes,omitempty"`
 	// Set
 	SetPrefix         string   `mapstructure:"set-prefix,omitempty" json:"set-prefix,omitempty" yaml:"set-prefix,omitempty"`
+	SetEncoding       string   `mapstructure:"set-encoding,omitempty" json:"set-encoding,omitempty" yaml:"set-encoding,omitempty"`
 	SetDelete         []string `mapstructure:"set-delete,omitempty" json:"set-delete,omitempty" yaml:"set-delete,omitempty"`
 	SetReplace        []string `mapstructure:"set-replace,omitempty" json:"set-replace,omitempty" yaml:"set-replace,omitempty"`
 	SetUpdate         []string `mapstructure:"set-update,omitempty" json:"set-update,omitempty" yaml:"set-update,omitempty"`
@@ -146,28 +230,90 @@ type LocalFlags struct {
 	SetReplaceCliFile string   `mapstructure:"set-replace-cli-file,omitempty" yaml:"set-replace-cli-file,omitempty" json:"set-replace-cli-file,omitempty"`
 	SetUpdateCli      []string `mapstructure:"set-update-cli,omitempty" yaml:"set-update-cli,omitempty" json:"set-update-cli,omitempty"`
 	SetUpdateCliFile  string   `mapstructure:"set-update-cli-file,omitempty" yaml:"set-update-cli-file,omitempty" json:"set-update-cli-file,omitempty"`
+	SetCliOrigin      string   `mapstructure:"set-cli-origin,omitempty" yaml:"set-cli-origin,omitempty" json:"set-cli-origin,omitempty"`
+	SetBytesFile      []string `mapstructure:"set-update-bytes-file,omitempty" yaml:"set-update-bytes-file,omitempty" json:"set-update-bytes-file,omitempty"`
+	SetValidateYang   bool     `mapstructure:"set-validate-yang,omitempty" yaml:"set-validate-yang,omitempty" json:"set-validate-yang,omitempty"`
+	SetAgain          bool     `mapstructure:"set-again,omitempty" yaml:"set-again,omitempty" json:"set-again,omitempty"`
+	SetAppend         bool     `mapstructure:"set-append,omitempty" yaml:"set-append,omitempty" json:"set-append,omitempty"`
+	// Set split by container
+	SetSplitByContainer     bool `mapstructure:"set-split-set-by-container,omitempty" yaml:"set-split-set-by-container,omitempty" json:"set-split-set-by-container,omitempty"`
+	SetSplitDepth           int  `mapstructure:"set-split-depth,omitempty" yaml:"set-split-depth,omitempty" json:"set-split-depth,omitempty"`
+	SetContinueOnSplitError bool `mapstructure:"set-continue-on-split-error,omitempty" yaml:"set-continue-on-split-error,omitempty" json:"set-continue-on-split-error,omitempty"`
 	// Sub
-	SubscribePrefix            string        `mapstructure:"subscribe-prefix,omitempty" json:"subscribe-prefix,omitempty" yaml:"subscribe-prefix,omitempty"`
-	SubscribePath              []string      `mapstructure:"subscribe-path,omitempty" json:"subscribe-path,omitempty" yaml:"subscribe-path,omitempty"`
-	SubscribeQos               uint32        `mapstructure:"subscribe-qos,omitempty" json:"subscribe-qos,omitempty" yaml:"subscribe-qos,omitempty"`
-	SubscribeUpdatesOnly       bool          `mapstructure:"subscribe-updates-only,omitempty" json:"subscribe-updates-only,omitempty" yaml:"subscribe-updates-only,omitempty"`
-	SubscribeMode              string        `mapstructure:"subscribe-mode,omitempty" json:"subscribe-mode,omitempty" yaml:"subscribe-mode,omitempty"`
-	SubscribeStreamMode        string        `mapstructure:"subscribe-stream_mode,omitempty" json:"subscribe-stream-mode,omitempty" yaml:"subscribe-stream-mode,omitempty"`
-	SubscribeSampleInterval    time.Duration `mapstructure:"subscribe-sample-interval,omitempty" json:"subscribe-sample-interval,omitempty" yaml:"subscribe-sample-interval,omitempty"`
-	SubscribeSuppressRedundant bool          `mapstructure:"subscribe-suppress-redundant,omitempty" json:"subscribe-suppress-redundant,omitempty" yaml:"subscribe-suppress-redundant,omitempty"`
-	SubscribeHeartbearInterval time.Duration `mapstructure:"subscribe-heartbear-interval,omitempty" json:"subscribe-heartbear-interval,omitempty" yaml:"subscribe-heartbear-interval,omitempty"`
-	SubscribeModel             []string      `mapstructure:"subscribe-model,omitempty" json:"subscribe-model,omitempty" yaml:"subscribe-model,omitempty"`
-	SubscribeQuiet             bool          `mapstructure:"subscribe-quiet,omitempty" json:"subscribe-quiet,omitempty" yaml:"subscribe-quiet,omitempty"`
-	SubscribeTarget            string        `mapstructure:"subscribe-target,omitempty" json:"subscribe-target,omitempty" yaml:"subscribe-target,omitempty"`
-	SubscribeSetTarget         bool          `mapstructure:"subscribe-set-target,omitempty" json:"subscribe-set-target,omitempty" yaml:"subscribe-set-target,omitempty"`
-	SubscribeName              []string      `mapstructure:"subscribe-name,omitempty" json:"subscribe-name,omitempty" yaml:"subscribe-name,omitempty"`
-	SubscribeOutput            []string      `mapstructure:"subscribe-output,omitempty" json:"subscribe-output,omitempty" yaml:"subscribe-output,omitempty"`
-	SubscribeWatchConfig       bool          `mapstructure:"subscribe-watch-config,omitempty" json:"subscribe-watch-config,omitempty" yaml:"subscribe-watch-config,omitempty"`
-	SubscribeBackoff           time.Duration `mapstructure:"subscribe-backoff,omitempty" json:"subscribe-backoff,omitempty" yaml:"subscribe-backoff,omitempty"`
-	SubscribeLockRetry         time.Duration `mapstructure:"subscribe-lock-retry,omitempty" json:"subscribe-lock-retry,omitempty" yaml:"subscribe-lock-retry,omitempty"`
-	SubscribeHistorySnapshot   string        `mapstructure:"subscribe-history-snapshot,omitempty" json:"subscribe-history-snapshot,omitempty" yaml:"subscribe-history-snapshot,omitempty"`
-	SubscribeHistoryStart      string        `mapstructure:"subscribe-history-start,omitempty" json:"subscribe-history-start,omitempty" yaml:"subscribe-history-start,omitempty"`
-	SubscribeHistoryEnd        string        `mapstructure:"subscribe-history-end,omitempty" json:"subscribe-history-end,omitempty" yaml:"subscribe-history-end,omitempty"`
+	SubscribePrefix              string        `mapstructure:"subscribe-prefix,omitempty" json:"subscribe-prefix,omitempty" yaml:"subscribe-prefix,omitempty"`
+	SubscribePath                []string      `mapstructure:"subscribe-path,omitempty" json:"subscribe-path,omitempty" yaml:"subscribe-path,omitempty"`
+	SubscribePathFile            string        `mapstructure:"subscribe-path-file,omitempty" json:"subscribe-path-file,omitempty" yaml:"subscribe-path-file,omitempty"`
+	SubscribeEncoding            string        `mapstructure:"subscribe-encoding,omitempty" json:"subscribe-encoding,omitempty" yaml:"subscribe-encoding,omitempty"`
+	SubscribeQos                 uint32        `mapstructure:"subscribe-qos,omitempty" json:"subscribe-qos,omitempty" yaml:"subscribe-qos,omitempty"`
+	SubscribeUpdatesOnly         bool          `mapstructure:"subscribe-updates-only,omitempty" json:"subscribe-updates-only,omitempty" yaml:"subscribe-updates-only,omitempty"`
+	SubscribeMode                string        `mapstructure:"subscribe-mode,omitempty" json:"subscribe-mode,omitempty" yaml:"subscribe-mode,omitempty"`
+	SubscribeStreamMode          string        `mapstructure:"subscribe-stream_mode,omitempty" json:"subscribe-stream-mode,omitempty" yaml:"subscribe-stream-mode,omitempty"`
+	SubscribeSampleInterval      time.Duration `mapstructure:"subscribe-sample-interval,omitempty" json:"subscribe-sample-interval,omitempty" yaml:"subscribe-sample-interval,omitempty"`
+	SubscribeSuppressRedundant   bool          `mapstructure:"subscribe-suppress-redundant,omitempty" json:"subscribe-suppress-redundant,omitempty" yaml:"subscribe-suppress-redundant,omitempty"`
+	SubscribeHeartbearInterval   time.Duration `mapstructure:"subscribe-heartbear-interval,omitempty" json:"subscribe-heartbear-interval,omitempty" yaml:"subscribe-heartbear-interval,omitempty"`
+	SubscribeModel               []string      `mapstructure:"subscribe-model,omitempty" json:"subscribe-model,omitempty" yaml:"subscribe-model,omitempty"`
+	SubscribeQuiet               bool          `mapstructure:"subscribe-quiet,omitempty" json:"subscribe-quiet,omitempty" yaml:"subscribe-quiet,omitempty"`
+	SubscribeTee                 bool          `mapstructure:"subscribe-tee,omitempty" json:"subscribe-tee,omitempty" yaml:"subscribe-tee,omitempty"`
+	SubscribeMaxClockSkew        time.Duration `mapstructure:"subscribe-max-clock-skew,omitempty" json:"subscribe-max-clock-skew,omitempty" yaml:"subscribe-max-clock-skew,omitempty"`
+	SubscribeOverrideTS          bool          `mapstructure:"subscribe-override-timestamps,omitempty" json:"subscribe-override-timestamps,omitempty" yaml:"subscribe-override-timestamps,omitempty"`
+	SubscribeTarget              string        `mapstructure:"subscribe-target,omitempty" json:"subscribe-target,omitempty" yaml:"subscribe-target,omitempty"`
+	SubscribeSetTarget           bool          `mapstructure:"subscribe-set-target,omitempty" json:"subscribe-set-target,omitempty" yaml:"subscribe-set-target,omitempty"`
+	SubscribeName                []string      `mapstructure:"subscribe-name,omitempty" json:"subscribe-name,omitempty" yaml:"subscribe-name,omitempty"`
+	SubscribeOutput              []string      `mapstructure:"subscribe-output,omitempty" json:"subscribe-output,omitempty" yaml:"subscribe-output,omitempty"`
+	SubscribeWatchConfig         bool          `mapstructure:"subscribe-watch-config,omitempty" json:"subscribe-watch-config,omitempty" yaml:"subscribe-watch-config,omitempty"`
+	SubscribeWatchFile           bool          `mapstructure:"subscribe-watch-file,omitempty" json:"subscribe-watch-file,omitempty" yaml:"subscribe-watch-file,omitempty"`
+	SubscribeBackoff             time.Duration `mapstructure:"subscribe-backoff,omitempty" json:"subscribe-backoff,omitempty" yaml:"subscribe-backoff,omitempty"`
+	SubscribeLockRetry           time.Duration `mapstructure:"subscribe-lock-retry,omitempty" json:"subscribe-lock-retry,omitempty" yaml:"subscribe-lock-retry,omitempty"`
+	SubscribeHistorySnapshot     string        `mapstructure:"subscribe-history-snapshot,omitempty" json:"subscribe-history-snapshot,omitempty" yaml:"subscribe-history-snapshot,omitempty"`
+	SubscribeHistoryStart        string        `mapstructure:"subscribe-history-start,omitempty" json:"subscribe-history-start,omitempty" yaml:"subscribe-history-start,omitempty"`
+	SubscribeHistoryEnd          string        `mapstructure:"subscribe-history-end,omitempty" json:"subscribe-history-end,omitempty" yaml:"subscribe-history-end,omitempty"`
+	SubscribeOutputKeepalive     time.Duration `mapstructure:"subscribe-output-keepalive,omitempty" json:"subscribe-output-keepalive,omitempty" yaml:"subscribe-output-keepalive,omitempty"`
+	SubscribeSyslogAddress       string        `mapstructure:"subscribe-syslog-address,omitempty" json:"subscribe-syslog-address,omitempty" yaml:"subscribe-syslog-address,omitempty"`
+	SubscribeSyslogTag           string        `mapstructure:"subscribe-syslog-tag,omitempty" json:"subscribe-syslog-tag,omitempty" yaml:"subscribe-syslog-tag,omitempty"`
+	SubscribeESURL               string        `mapstructure:"subscribe-es-url,omitempty" json:"subscribe-es-url,omitempty" yaml:"subscribe-es-url,omitempty"`
+	SubscribeESIndex             string        `mapstructure:"subscribe-es-index,omitempty" json:"subscribe-es-index,omitempty" yaml:"subscribe-es-index,omitempty"`
+	SubscribeOTLPEndpoint        string        `mapstructure:"subscribe-otlp-endpoint,omitempty" json:"subscribe-otlp-endpoint,omitempty" yaml:"subscribe-otlp-endpoint,omitempty"`
+	SubscribeOTLPInsecure        bool          `mapstructure:"subscribe-otlp-insecure,omitempty" json:"subscribe-otlp-insecure,omitempty" yaml:"subscribe-otlp-insecure,omitempty"`
+	SubscribeWSListen            string        `mapstructure:"subscribe-ws-listen,omitempty" json:"subscribe-ws-listen,omitempty" yaml:"subscribe-ws-listen,omitempty"`
+	SubscribeCalculateRate       bool          `mapstructure:"subscribe-calculate-rate,omitempty" json:"subscribe-calculate-rate,omitempty" yaml:"subscribe-calculate-rate,omitempty"`
+	SubscribeMetricsAddress      string        `mapstructure:"subscribe-metrics-address,omitempty" json:"subscribe-metrics-address,omitempty" yaml:"subscribe-metrics-address,omitempty"`
+	SubscribeDockerDiscovery     bool          `mapstructure:"subscribe-docker-discovery,omitempty" json:"subscribe-docker-discovery,omitempty" yaml:"subscribe-docker-discovery,omitempty"`
+	SubscribeDockerAddress       string        `mapstructure:"subscribe-docker-address,omitempty" json:"subscribe-docker-address,omitempty" yaml:"subscribe-docker-address,omitempty"`
+	SubscribeDockerFilter        []string      `mapstructure:"subscribe-docker-filter,omitempty" json:"subscribe-docker-filter,omitempty" yaml:"subscribe-docker-filter,omitempty"`
+	SubscribeDockerPort          string        `mapstructure:"subscribe-docker-port,omitempty" json:"subscribe-docker-port,omitempty" yaml:"subscribe-docker-port,omitempty"`
+	SubscribeDockerInterval      time.Duration `mapstructure:"subscribe-docker-interval,omitempty" json:"subscribe-docker-interval,omitempty" yaml:"subscribe-docker-interval,omitempty"`
+	SubscribeDockerProfile       string        `mapstructure:"subscribe-docker-profile,omitempty" json:"subscribe-docker-profile,omitempty" yaml:"subscribe-docker-profile,omitempty"`
+	SubscribeConsulAddress       string        `mapstructure:"subscribe-consul-address,omitempty" json:"subscribe-consul-address,omitempty" yaml:"subscribe-consul-address,omitempty"`
+	SubscribeConsulService       []string      `mapstructure:"subscribe-consul-service,omitempty" json:"subscribe-consul-service,omitempty" yaml:"subscribe-consul-service,omitempty"`
+	SubscribeConsulToken         string        `mapstructure:"subscribe-consul-token,omitempty" json:"subscribe-consul-token,omitempty" yaml:"subscribe-consul-token,omitempty"`
+	SubscribeConsulTLSCaFile     string        `mapstructure:"subscribe-consul-tls-ca-file,omitempty" json:"subscribe-consul-tls-ca-file,omitempty" yaml:"subscribe-consul-tls-ca-file,omitempty"`
+	SubscribeConsulTLSCertFile   string        `mapstructure:"subscribe-consul-tls-cert-file,omitempty" json:"subscribe-consul-tls-cert-file,omitempty" yaml:"subscribe-consul-tls-cert-file,omitempty"`
+	SubscribeConsulTLSKeyFile    string        `mapstructure:"subscribe-consul-tls-key-file,omitempty" json:"subscribe-consul-tls-key-file,omitempty" yaml:"subscribe-consul-tls-key-file,omitempty"`
+	SubscribeConsulSkipVerify    bool          `mapstructure:"subscribe-consul-tls-skip-verify,omitempty" json:"subscribe-consul-tls-skip-verify,omitempty" yaml:"subscribe-consul-tls-skip-verify,omitempty"`
+	SubscribeValuesOnly          bool          `mapstructure:"subscribe-values-only,omitempty" json:"subscribe-values-only,omitempty" yaml:"subscribe-values-only,omitempty"`
+	SubscribeLogConnState        bool          `mapstructure:"subscribe-log-conn-state,omitempty" json:"subscribe-log-conn-state,omitempty" yaml:"subscribe-log-conn-state,omitempty"`
+	SubscribeCache               bool          `mapstructure:"subscribe-cache,omitempty" json:"subscribe-cache,omitempty" yaml:"subscribe-cache,omitempty"`
+	SubscribeCacheMaxEntries     int64         `mapstructure:"subscribe-cache-max-entries,omitempty" json:"subscribe-cache-max-entries,omitempty" yaml:"subscribe-cache-max-entries,omitempty"`
+	SubscribeServerAddress       string        `mapstructure:"subscribe-server-address,omitempty" json:"subscribe-server-address,omitempty" yaml:"subscribe-server-address,omitempty"`
+	SubscribeMerge               bool          `mapstructure:"subscribe-merge,omitempty" json:"subscribe-merge,omitempty" yaml:"subscribe-merge,omitempty"`
+	SubscribeMergeTimeout        time.Duration `mapstructure:"subscribe-merge-timeout,omitempty" json:"subscribe-merge-timeout,omitempty" yaml:"subscribe-merge-timeout,omitempty"`
+	SubscribeOutputDir           string        `mapstructure:"subscribe-output-dir,omitempty" json:"subscribe-output-dir,omitempty" yaml:"subscribe-output-dir,omitempty"`
+	SubscribeStaleTimeout        time.Duration `mapstructure:"subscribe-stale-timeout,omitempty" json:"subscribe-stale-timeout,omitempty" yaml:"subscribe-stale-timeout,omitempty"`
+	SubscribeStaleAction         string        `mapstructure:"subscribe-stale-action,omitempty" json:"subscribe-stale-action,omitempty" yaml:"subscribe-stale-action,omitempty"`
+	SubscribeDownsample          time.Duration `mapstructure:"subscribe-downsample,omitempty" json:"subscribe-downsample,omitempty" yaml:"subscribe-downsample,omitempty"`
+	SubscribeDownsampleKeep      string        `mapstructure:"subscribe-downsample-keep,omitempty" json:"subscribe-downsample-keep,omitempty" yaml:"subscribe-downsample-keep,omitempty"`
+	SubscribeDecomposeJSON       bool          `mapstructure:"subscribe-decompose-json,omitempty" json:"subscribe-decompose-json,omitempty" yaml:"subscribe-decompose-json,omitempty"`
+	SubscribeKeepCompound        bool          `mapstructure:"subscribe-keep-compound,omitempty" json:"subscribe-keep-compound,omitempty" yaml:"subscribe-keep-compound,omitempty"`
+	SubscribeNullAsDelete        bool          `mapstructure:"subscribe-null-as-delete,omitempty" json:"subscribe-null-as-delete,omitempty" yaml:"subscribe-null-as-delete,omitempty"`
+	SubscribeAgain               bool          `mapstructure:"subscribe-again,omitempty" json:"subscribe-again,omitempty" yaml:"subscribe-again,omitempty"`
+	SubscribeAppend              bool          `mapstructure:"subscribe-append,omitempty" json:"subscribe-append,omitempty" yaml:"subscribe-append,omitempty"`
+	SubscribeDetectAnomalies     bool          `mapstructure:"subscribe-detect-anomalies,omitempty" json:"subscribe-detect-anomalies,omitempty" yaml:"subscribe-detect-anomalies,omitempty"`
+	SubscribeDropOutOfOrder      bool          `mapstructure:"subscribe-drop-out-of-order,omitempty" json:"subscribe-drop-out-of-order,omitempty" yaml:"subscribe-drop-out-of-order,omitempty"`
+	SubscribeAnomaliesMaxEntries int           `mapstructure:"subscribe-anomalies-max-entries,omitempty" json:"subscribe-anomalies-max-entries,omitempty" yaml:"subscribe-anomalies-max-entries,omitempty"`
+	SubscribeSequenceNumbers     bool          `mapstructure:"subscribe-sequence-numbers,omitempty" json:"subscribe-sequence-numbers,omitempty" yaml:"subscribe-sequence-numbers,omitempty"`
+	SubscribeExpandWildcards     bool          `mapstructure:"subscribe-expand-wildcards,omitempty" json:"subscribe-expand-wildcards,omitempty" yaml:"subscribe-expand-wildcards,omitempty"`
+	SubscribeExpandMax           int           `mapstructure:"subscribe-expand-max,omitempty" json:"subscribe-expand-max,omitempty" yaml:"subscribe-expand-max,omitempty"`
+	SubscribeYes                 bool          `mapstructure:"subscribe-yes,omitempty" json:"subscribe-yes,omitempty" yaml:"subscribe-yes,omitempty"`
 	// Path
 	PathPathType   string `mapstructure:"path-path-type,omitempty" json:"path-path-type,omitempty" yaml:"path-path-type,omitempty"`
 	PathWithDescr  bool   `mapstructure:"path-descr,omitempty" json:"path-descr,omitempty" yaml:"path-descr,omitempty"`
@@ -176,6 +322,10 @@ type LocalFlags struct {
 	PathSearch     bool   `mapstructure:"path-search,omitempty" json:"path-search,omitempty" yaml:"path-search,omitempty"`
 	PathState      bool   `mapstructure:"path-state,omitempty" json:"path-state,omitempty" yaml:"path-state,omitempty"`
 	PathConfig     bool   `mapstructure:"path-config,omitempty" json:"path-config,omitempty" yaml:"path-config,omitempty"`
+	// Path Search
+	PathSearchTypes    bool `mapstructure:"search-types,omitempty" json:"search-types,omitempty" yaml:"search-types,omitempty"`
+	PathSearchWithKeys bool `mapstructure:"search-with-keys,omitempty" json:"search-with-keys,omitempty" yaml:"search-with-keys,omitempty"`
+	PathSearchNoCache  bool `mapstructure:"search-no-cache,omitempty" json:"search-no-cache,omitempty" yaml:"search-no-cache,omitempty"`
 	// Prompt
 	PromptFile                  []string `mapstructure:"prompt-file,omitempty" json:"prompt-file,omitempty" yaml:"prompt-file,omitempty"`
 	PromptExclude               []string `mapstructure:"prompt-exclude,omitempty" json:"prompt-exclude,omitempty" yaml:"prompt-exclude,omitempty"`
@@ -211,6 +361,7 @@ type LocalFlags struct {
 	GeneratePath       string `mapstructure:"generate-path,omitempty" json:"generate-path,omitempty" yaml:"generate-path,omitempty"`
 	GenerateCamelCase  bool   `mapstructure:"generate-camel-case,omitempty" json:"generate-camel-case" yaml:"generate-camel-case,omitempty"`
 	GenerateSnakeCase  bool   `mapstructure:"generate-snake-case,omitempty" json:"generate-snake-case" yaml:"generate-snake-case,omitempty"`
+	GenerateState      bool   `mapstructure:"generate-state,omitempty" json:"generate-state,omitempty" yaml:"generate-state,omitempty"`
 	// Generate Set Request
 	GenerateSetRequestUpdatePath  []string `mapstructure:"generate-update-path,omitempty" json:"generate-update-path,omitempty" yaml:"generate-update-path,omitempty"`
 	GenerateSetRequestReplacePath []string `mapstructure:"generate-replace-path,omitempty" json:"generate-replace-path,omitempty" yaml:"generate-replace-path,omitempty"`
@@ -223,6 +374,30 @@ type LocalFlags struct {
 	GeneratePathState         bool   `mapstructure:"generate-path-state,omitempty" json:"generate-path-state,omitempty" yaml:"generate-path-state,omitempty"`
 	GeneratePathConfig        bool   `mapstructure:"generate-path-config,omitempty" json:"generate-path-config,omitempty" yaml:"generate-path-config,omitempty"`
 	GeneratePathWithNonLeaves bool   `mapstructure:"generate-path-with-non-leaves,omitempty" json:"generate-path-with-non-leaves,omitempty" yaml:"generate-path-with-non-leaves,omitempty"`
+	// Config Init
+	ConfigInitOutput  string `mapstructure:"init-output,omitempty" json:"init-output,omitempty" yaml:"init-output,omitempty"`
+	ConfigInitMinimal bool   `mapstructure:"init-minimal,omitempty" json:"init-minimal,omitempty" yaml:"init-minimal,omitempty"`
+	// Config Show
+	ConfigShowResolved bool `mapstructure:"show-resolved,omitempty" json:"show-resolved,omitempty" yaml:"show-resolved,omitempty"`
+	ConfigShowSources  bool `mapstructure:"show-sources,omitempty" json:"show-sources,omitempty" yaml:"show-sources,omitempty"`
+	// Config Targets
+	ConfigTargetsTest bool `mapstructure:"targets-test,omitempty" json:"targets-test,omitempty" yaml:"targets-test,omitempty"`
+	// Config Encrypt
+	ConfigEncryptValue string `mapstructure:"encrypt-value,omitempty" json:"encrypt-value,omitempty" yaml:"encrypt-value,omitempty"`
+	// Tunnel Targets
+	TunnelTargetsWait time.Duration `mapstructure:"targets-wait,omitempty" json:"targets-wait,omitempty" yaml:"targets-wait,omitempty"`
+	// Backup
+	BackupPath     []string `mapstructure:"backup-path,omitempty" json:"backup-path,omitempty" yaml:"backup-path,omitempty"`
+	BackupPrefix   string   `mapstructure:"backup-prefix,omitempty" json:"backup-prefix,omitempty" yaml:"backup-prefix,omitempty"`
+	BackupTarget   string   `mapstructure:"backup-target,omitempty" json:"backup-target,omitempty" yaml:"backup-target,omitempty"`
+	BackupDir      string   `mapstructure:"backup-dir,omitempty" json:"backup-dir,omitempty" yaml:"backup-dir,omitempty"`
+	BackupKeep     int      `mapstructure:"backup-keep,omitempty" json:"backup-keep,omitempty" yaml:"backup-keep,omitempty"`
+	BackupDiffLast bool     `mapstructure:"backup-diff-last,omitempty" json:"backup-diff-last,omitempty" yaml:"backup-diff-last,omitempty"`
+	// Restore
+	RestoreFile     string `mapstructure:"restore-file,omitempty" json:"restore-file,omitempty" yaml:"restore-file,omitempty"`
+	RestoreGranular bool   `mapstructure:"restore-granular,omitempty" json:"restore-granular,omitempty" yaml:"restore-granular,omitempty"`
+	RestoreDryRun   bool   `mapstructure:"restore-dry-run,omitempty" json:"restore-dry-run,omitempty" yaml:"restore-dry-run,omitempty"`
+	RestoreYes      bool   `mapstructure:"restore-yes,omitempty" json:"restore-yes,omitempty" yaml:"restore-yes,omitempty"`
 	//
 	DiffPath    []string `mapstructure:"diff-path,omitempty" json:"diff-path,omitempty" yaml:"diff-path,omitempty"`
 	DiffPrefix  string   `mapstructure:"diff-prefix,omitempty" json:"diff-prefix,omitempty" yaml:"diff-prefix,omitempty"`
@@ -233,6 +408,13 @@ type LocalFlags struct {
 	DiffRef     string   `mapstructure:"diff-ref,omitempty" json:"diff-ref,omitempty" yaml:"diff-ref,omitempty"`
 	DiffCompare []string `mapstructure:"diff-compare,omitempty" json:"diff-compare,omitempty" yaml:"diff-compare,omitempty"`
 	DiffQos     uint32   `mapstructure:"diff-qos,omitempty" json:"diff-qos,omitempty" yaml:"diff-qos,omitempty"`
+	// Check
+	CheckFile         string `mapstructure:"check-file,omitempty" json:"check-file,omitempty" yaml:"check-file,omitempty"`
+	CheckPrefix       string `mapstructure:"check-prefix,omitempty" json:"check-prefix,omitempty" yaml:"check-prefix,omitempty"`
+	CheckTarget       string `mapstructure:"check-target,omitempty" json:"check-target,omitempty" yaml:"check-target,omitempty"`
+	CheckType         string `mapstructure:"check-type,omitempty" json:"check-type,omitempty" yaml:"check-type,omitempty"`
+	CheckSub          bool   `mapstructure:"check-sub,omitempty" json:"check-sub,omitempty" yaml:"check-sub,omitempty"`
+	CheckOnlyFailures bool   `mapstructure:"check-only-failures,omitempty" json:"check-only-failures,omitempty" yaml:"check-only-failures,omitempty"`
 	//
 	TunnelServerSubscribe bool
 }
@@ -253,9 +435,18 @@ func New() *Config {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		log.New(io.Discard, configLogPrefix, utils.DefaultLoggingFlags),
 		nil,
 		make(map[string]interface{}),
+		make(map[string]interface{}),
+		nil,
+		nil,
+		make(map[string]string),
+		false,
+		nil,
+		nil,
 	}
 }
 
@@ -263,18 +454,8 @@ func (c *Config) Load(ctx context.Context) error {
 	c.FileConfig.SetEnvPrefix(envPrefix)
 	c.FileConfig.SetEnvKeyReplacer(strings.NewReplacer("/", "_", "-", "_"))
 	c.FileConfig.AutomaticEnv()
-	if c.GlobalFlags.CfgFile != "" {
-		// configuration file path is explicitly set
-		c.FileConfig.SetConfigFile(c.GlobalFlags.CfgFile)
-		configBytes, err := utils.ReadFile(ctx, c.FileConfig.ConfigFileUsed())
-		if err != nil {
-			return err
-		}
-		err = c.FileConfig.ReadConfig(bytes.NewBuffer(configBytes))
-		if err != nil {
-			return err
-		}
-	} else {
+	files := c.GlobalFlags.CfgFile
+	if len(files) == 0 {
 		// discover gnmic config file
 		home, err := homedir.Dir()
 		if err != nil {
@@ -291,6 +472,15 @@ func (c *Config) Load(ctx context.Context) error {
 				return err
 			}
 		}
+		if c.FileConfig.ConfigFileUsed() != "" {
+			files = []string{c.FileConfig.ConfigFileUsed()}
+		}
+	}
+	if len(files) > 0 {
+		err := c.ReadConfigFiles(ctx, files)
+		if err != nil {
+			return err
+		}
 	}
 
 	err := c.FileConfig.Unmarshal(c)
@@ -302,10 +492,32 @@ func (c *Config) Load(ctx context.Context) error {
 	return c.expandOSPathFlagValues()
 }
 
+// Location returns the location of the timezone flag, an IANA timezone name,
+// `local` or `utc`, case insensitive. An empty timezone is the local timezone.
+func (c *Config) Location() (*time.Location, error) {
+	tz := c.Timezone
+	switch strings.ToLower(tz) {
+	case "", "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", tz, err)
+	}
+	return loc, nil
+}
+
+// SetLogger sets the config logger output and flags and returns them,
+// the log lines timestamps are formatted in the timezone flag location.
 func (c *Config) SetLogger() (io.Writer, int, error) {
 	var f io.Writer = io.Discard
 	var loggingFlags = c.logger.Flags()
-	var err error
+	loc, err := c.Location()
+	if err != nil {
+		return nil, 0, err
+	}
 
 	if c.LogFile != "" {
 		if c.LogMaxSize > 0 {
@@ -332,8 +544,14 @@ func (c *Config) SetLogger() (io.Writer, int, error) {
 	if c.Debug {
 		loggingFlags |= log.Llongfile
 	}
+	f, loggingFlags = utils.LocationLogWriter(f, loggingFlags, loc)
 	c.logger.SetOutput(f)
 	c.logger.SetFlags(loggingFlags)
+	if c.Debug {
+		for _, fc := range c.fileConflicts {
+			c.logger.Print(fc)
+		}
+	}
 	return f, loggingFlags, nil
 }
 
@@ -415,7 +633,7 @@ func (c *Config) CreateGetRequest() (*gnmi.GetRequest, error) {
 	}
 	gnmiOpts := make([]api.GNMIOption, 0, 4+len(c.LocalFlags.GetPath))
 	gnmiOpts = append(gnmiOpts,
-		api.Encoding(c.Encoding),
+		api.Encoding(c.RPCEncoding(RPCGet, nil)),
 		api.DataType(c.LocalFlags.GetType),
 		api.Prefix(c.LocalFlags.GetPrefix),
 		api.Target(c.LocalFlags.GetTarget),
@@ -590,10 +808,14 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 		if len(singleUpdate) < 3 {
 			return nil, fmt.Errorf("invalid inline update format: %s", c.LocalFlags.SetUpdate)
 		}
+		value, err := inlineValue(singleUpdate[2], singleUpdate[1])
+		if err != nil {
+			return nil, err
+		}
 		gnmiOpts = append(gnmiOpts,
 			api.Update(
 				api.Path(strings.TrimSpace(singleUpdate[0])),
-				api.Value(singleUpdate[2], singleUpdate[1]),
+				api.Value(value, singleUpdate[1]),
 			),
 		)
 	}
@@ -603,34 +825,45 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 		if len(singleReplace) < 3 {
 			return nil, fmt.Errorf("invalid inline replace format: %s", c.LocalFlags.SetReplace)
 		}
+		value, err := inlineValue(singleReplace[2], singleReplace[1])
+		if err != nil {
+			return nil, err
+		}
 		gnmiOpts = append(gnmiOpts,
 			api.Replace(
 				api.Path(strings.TrimSpace(singleReplace[0])),
-				api.Value(singleReplace[2], singleReplace[1]),
+				api.Value(value, singleReplace[1]),
 			),
 		)
 	}
 
+	bytesOpts, err := c.updateBytesFiles()
+	if err != nil {
+		return nil, err
+	}
+	gnmiOpts = append(gnmiOpts, bytesOpts...)
+
 	useUpdateFiles := len(c.LocalFlags.SetUpdateFile) > 0 && len(c.LocalFlags.SetUpdateValue) == 0
 	useReplaceFiles := len(c.LocalFlags.SetReplaceFile) > 0 && len(c.LocalFlags.SetReplaceValue) == 0
 
 	for i, p := range c.LocalFlags.SetUpdatePath {
 		var updOpt api.GNMIOption
+		p, encoding := c.setPathEncoding(p, targetName)
 		if useUpdateFiles {
-			updateData, err := readFile(c.LocalFlags.SetUpdateFile[i])
+			updateData, err := c.readSetFile(c.LocalFlags.SetUpdateFile[i], targetName)
 			if err != nil {
 				c.logger.Printf("error reading data from file '%s': %v", c.LocalFlags.SetUpdateFile[i], err)
 				return nil, err
 			}
 			updOpt = api.Update(
-				api.Path(strings.TrimSpace(p)),
-				api.Value(string(bytes.Trim(updateData, " \r\n\t")), c.Encoding),
+				api.Path(p),
+				api.Value(string(bytes.Trim(updateData, " \r\n\t")), encoding),
 			)
 
 		} else {
 			updOpt = api.Update(
-				api.Path(strings.TrimSpace(p)),
-				api.Value(c.LocalFlags.SetUpdateValue[i], c.Encoding),
+				api.Path(p),
+				api.Value(c.LocalFlags.SetUpdateValue[i], encoding),
 			)
 		}
 		gnmiOpts = append(gnmiOpts, updOpt)
@@ -638,21 +871,22 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 
 	for i, p := range c.LocalFlags.SetReplacePath {
 		var replaceOpt api.GNMIOption
+		p, encoding := c.setPathEncoding(p, targetName)
 		if useReplaceFiles {
-			replaceData, err := readFile(c.LocalFlags.SetReplaceFile[i])
+			replaceData, err := c.readSetFile(c.LocalFlags.SetReplaceFile[i], targetName)
 			if err != nil {
 				c.logger.Printf("error reading data from file '%s': %v", c.LocalFlags.SetReplaceFile[i], err)
 				return nil, err
 			}
 			replaceOpt = api.Replace(
-				api.Path(strings.TrimSpace(p)),
-				api.Value(string(bytes.Trim(replaceData, " \r\n\t")), c.Encoding),
+				api.Path(p),
+				api.Value(string(bytes.Trim(replaceData, " \r\n\t")), encoding),
 			)
 
 		} else {
 			replaceOpt = api.Replace(
-				api.Path(strings.TrimSpace(p)),
-				api.Value(c.LocalFlags.SetReplaceValue[i], c.Encoding),
+				api.Path(p),
+				api.Value(c.LocalFlags.SetReplaceValue[i], encoding),
 			)
 		}
 		gnmiOpts = append(gnmiOpts, replaceOpt)
@@ -660,7 +894,7 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 	if len(c.LocalFlags.SetUpdateCli) > 0 {
 		gnmiOpts = append(gnmiOpts,
 			api.Update(
-				api.Path("cli:/"),
+				api.Path(c.cliPath()),
 				api.Value(strings.Join(c.LocalFlags.SetUpdateCli, "\n"), "ascii"),
 			),
 		)
@@ -669,7 +903,7 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 	if len(c.LocalFlags.SetReplaceCli) > 0 {
 		gnmiOpts = append(gnmiOpts,
 			api.Replace(
-				api.Path("cli:/"),
+				api.Path(c.cliPath()),
 				api.Value(strings.Join(c.LocalFlags.SetReplaceCli, "\n"), "ascii"),
 			),
 		)
@@ -682,7 +916,7 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 		}
 		gnmiOpts = append(gnmiOpts,
 			api.Update(
-				api.Path("cli:/"),
+				api.Path(c.cliPath()),
 				api.Value(string(data), "ascii"),
 			),
 		)
@@ -695,7 +929,7 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 		}
 		gnmiOpts = append(gnmiOpts,
 			api.Replace(
-				api.Path("cli:/"),
+				api.Path(c.cliPath()),
 				api.Value(string(data), "ascii"),
 			),
 		)
@@ -705,6 +939,26 @@ func (c *Config) CreateSetRequest(targetName string) ([]*gnmi.SetRequest, error)
 	return []*gnmi.SetRequest{req}, err
 }
 
+// cliPath returns the path of the CLI updates and replaces,
+// made of the origin set with --cli-origin.
+func (c *Config) cliPath() string {
+	origin := c.LocalFlags.SetCliOrigin
+	if origin == "" {
+		origin = defaultCliOrigin
+	}
+	return origin + ":/"
+}
+
+// setPathEncoding returns the path and value encoding of an update or replace path sent to target targetName.
+// The "cli:" path is a shortcut to the CLI origin, its value is sent as ASCII.
+func (c *Config) setPathEncoding(p, targetName string) (string, string) {
+	p = strings.TrimSpace(p)
+	if p == "cli:" || p == "cli:/" {
+		return c.cliPath(), "ascii"
+	}
+	return p, c.RPCEncoding(RPCSet, c.Targets[targetName])
+}
+
 // readFile reads a json or yaml file. the the file is .yaml, converts it to json and returns []byte and an error
 func readFile(name string) ([]byte, error) {
 	data, err := utils.ReadFile(context.TODO(), name)
@@ -750,6 +1004,18 @@ func SanitizeArrayFlagValue(ls []string) []string {
 	return res
 }
 
+// HasSetInput returns true if the set operations or request files are set with the set command flags.
+func (c *Config) HasSetInput() bool {
+	return len(c.LocalFlags.SetDelete)+len(c.LocalFlags.SetUpdate)+len(c.LocalFlags.SetReplace) > 0 ||
+		len(c.LocalFlags.SetUpdatePath)+len(c.LocalFlags.SetReplacePath) > 0 ||
+		len(c.LocalFlags.SetRequestFile) > 0 ||
+		len(c.LocalFlags.SetReplaceCli) > 0 ||
+		len(c.LocalFlags.SetUpdateCli) > 0 ||
+		len(c.LocalFlags.SetReplaceCliFile) > 0 ||
+		len(c.LocalFlags.SetUpdateCliFile) > 0 ||
+		len(c.LocalFlags.SetBytesFile) > 0
+}
+
 func (c *Config) ValidateSetInput() error {
 	var err error
 	c.LocalFlags.SetDelete = SanitizeArrayFlagValue(c.LocalFlags.SetDelete)
@@ -781,13 +1047,7 @@ func (c *Config) ValidateSetInput() error {
 	if err != nil {
 		return err
 	}
-	if (len(c.LocalFlags.SetDelete)+len(c.LocalFlags.SetUpdate)+len(c.LocalFlags.SetReplace)) == 0 &&
-		(len(c.LocalFlags.SetUpdatePath)+len(c.LocalFlags.SetReplacePath)) == 0 &&
-		len(c.LocalFlags.SetRequestFile) == 0 &&
-		len(c.LocalFlags.SetReplaceCli) == 0 &&
-		len(c.LocalFlags.SetUpdateCli) == 0 &&
-		len(c.LocalFlags.SetReplaceCliFile) == 0 &&
-		len(c.LocalFlags.SetUpdateCliFile) == 0 {
+	if !c.HasSetInput() && !c.LocalFlags.SetAgain {
 		return errors.New("no paths or request file provided")
 	}
 	if len(c.LocalFlags.SetUpdateFile) > 0 && len(c.LocalFlags.SetUpdateValue) > 0 {
@@ -802,6 +1062,9 @@ func (c *Config) ValidateSetInput() error {
 	if len(c.LocalFlags.SetReplacePath) != len(c.LocalFlags.SetReplaceValue) && len(c.LocalFlags.SetReplacePath) != len(c.LocalFlags.SetReplaceFile) {
 		return errors.New("missing replace value/file or path")
 	}
+	if c.LocalFlags.SetValidateYang && len(c.GlobalFlags.File) == 0 {
+		return errors.New("--validate-yang requires the YANG files to be set with --file")
+	}
 	return nil
 }
 
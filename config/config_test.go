@@ -14,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/api"
@@ -32,64 +33,59 @@ var createGetRequestTestSet = map[string]struct {
 	},
 	"unknown_encoding_type": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "dummy",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			LocalFlags: LocalFlags{},
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
 	},
 	"invalid_prefix": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPrefix: "/invalid/]prefix",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
 	},
 	"invalid_path": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPrefix: "/invalid/]path",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
 	},
 	"unknown_data_type": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPrefix: "/valid/path",
 				GetType:   "dummy",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
 	},
 	"basic_get_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPath: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -105,14 +101,13 @@ var createGetRequestTestSet = map[string]struct {
 	},
 	"get_request_with_type": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPath: []string{"/valid/path"},
 				GetType: "state",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -129,13 +124,12 @@ var createGetRequestTestSet = map[string]struct {
 	},
 	"get_request_with_encoding": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "proto",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPath: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -152,14 +146,13 @@ var createGetRequestTestSet = map[string]struct {
 	},
 	"get_request_with_prefix": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "proto",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPrefix: "/valid/prefix",
 				GetPath:   []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Prefix: &gnmi.Path{
@@ -182,16 +175,15 @@ var createGetRequestTestSet = map[string]struct {
 	},
 	"get_request_with_2_paths": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				GetPath: []string{
 					"/valid/path1",
 					"/valid/path2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -221,12 +213,11 @@ var createSetRequestTestSet = map[string]struct {
 
 	"set_update_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelimiter: ":::",
 				SetUpdate:    []string{"/valid/path:::json:::value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -249,12 +240,11 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_replace_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelimiter: ":::",
 				SetReplace:   []string{"/valid/path:::json:::value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -277,11 +267,10 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_delete_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelete: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -297,15 +286,14 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_multiple_update_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelimiter: ":::",
 				SetUpdate: []string{
 					"/valid/path1:::json:::value1",
 					"/valid/path2:::json_ietf:::value2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -341,15 +329,14 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_multiple_replace_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelimiter: ":::",
 				SetReplace: []string{
 					"/valid/path1:::json:::value1",
 					"/valid/path2:::json_ietf:::value2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -385,14 +372,13 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_multiple_delete_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelete: []string{
 					"/valid/path1",
 					"/valid/path2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -414,14 +400,13 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_combined_request": {
 		in: &Config{
-			GlobalFlags{},
-			LocalFlags{
+			GlobalFlags: GlobalFlags{},
+			LocalFlags: LocalFlags{
 				SetDelimiter: ":::",
 				SetUpdate:    []string{"/valid/path1:::json:::value1"},
 				SetReplace:   []string{"/valid/path2:::json:::value2"},
 				SetDelete:    []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -467,14 +452,13 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_update_path_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				SetUpdatePath:  []string{"/valid/path"},
 				SetUpdateValue: []string{"value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -497,14 +481,13 @@ var createSetRequestTestSet = map[string]struct {
 	},
 	"set_replace_path_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{
+			LocalFlags: LocalFlags{
 				SetReplacePath:  []string{"/valid/path"},
 				SetReplaceValue: []string{"value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -611,3 +594,54 @@ func TestExecPathTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigLocation(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		"empty": {
+			in:   "",
+			want: time.Local.String(),
+		},
+		"local": {
+			in:   "Local",
+			want: time.Local.String(),
+		},
+		"utc": {
+			in:   "utc",
+			want: "UTC",
+		},
+		"iana": {
+			in:   "Europe/Paris",
+			want: "Europe/Paris",
+		},
+		"invalid": {
+			in:      "Mars/Olympus_Mons",
+			wantErr: true,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			c.Timezone = item.in
+			loc, err := c.Location()
+			if item.wantErr {
+				if err == nil {
+					t.Errorf("failed at item %q: expected an error", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed at item %q: unexpected error: %v", name, err)
+			}
+			if loc.String() != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.want)
+				t.Logf("     got: %s", loc)
+				t.Fail()
+			}
+		})
+	}
+}
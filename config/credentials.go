@@ -0,0 +1,77 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+// ErrMissingCredentials is returned when a target has a username without a password
+// and the password cannot be prompted.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// CanPrompt returns true if the missing credentials can be prompted:
+// --no-prompt is not set and stdin is a terminal.
+func (c *Config) CanPrompt() bool {
+	return !c.NoPrompt && isTerminal(os.Stdin)
+}
+
+// SetMissingCredentials prompts the password of the targets tcs set with a username only,
+// in the targets names order.
+// It is called by the commands connecting to the targets, reading, linting
+// or listing the targets configuration does not require their credentials.
+func (c *Config) SetMissingCredentials(tcs map[string]*types.TargetConfig) error {
+	names := make([]string, 0, len(tcs))
+	for n := range tcs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		err := c.setMissingCredentials(tcs[n])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMissingCredentials prompts the password of a target set with a username only.
+// The prompted password is used by all the targets missing one,
+// so it is prompted at most once per run.
+// A target without a username is left as is, it authenticates
+// with a password only, a token, a client certificate or not at all.
+func (c *Config) setMissingCredentials(tc *types.TargetConfig) error {
+	if tc.Username == nil || *tc.Username == "" {
+		return nil
+	}
+	if tc.Password != nil && *tc.Password != "" {
+		return nil
+	}
+	if c.promptedPassword == nil {
+		if !c.CanPrompt() {
+			return fmt.Errorf("target %q: %w: username %q set without a password and prompting is disabled by --no-prompt or a non-terminal stdin, "+
+				"set the password with the --password flag, the password key of the target or of the config file, "+
+				"the GNMIC_PASSWORD env var, or the OS keyring with --use-keyring",
+				tc.Name, ErrMissingCredentials, *tc.Username)
+		}
+		password, err := ReadPassword("password")
+		if err != nil {
+			return err
+		}
+		c.promptedPassword = &password
+	}
+	password := *c.promptedPassword
+	tc.Password = &password
+	return nil
+}
@@ -0,0 +1,75 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMissingCredentials(t *testing.T) {
+	c := New()
+	c.NoPrompt = true
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "username": "admin"},
+	})
+	// the targets configuration is read without the credentials
+	tcs, err := c.GetTargets()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = c.SetMissingCredentials(tcs)
+	if !errors.Is(err, ErrMissingCredentials) {
+		t.Fatalf("expected a missing credentials error, got %v", err)
+	}
+	for _, s := range []string{`"router1"`, "--password", "GNMIC_PASSWORD", "--use-keyring"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected the error to mention %s, got: %v", s, err)
+		}
+	}
+
+	c = New()
+	c.NoPrompt = true
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "username": "admin", "password": "pass1"},
+		"router2": map[string]interface{}{"address": "10.0.0.2:57400"},
+	})
+	tcs, err = c.GetTargets()
+	if err == nil {
+		err = c.SetMissingCredentials(tcs)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPromptedPasswordReused(t *testing.T) {
+	c := New()
+	c.NoPrompt = true
+	prompted := "pass1"
+	c.promptedPassword = &prompted
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "username": "admin"},
+		"router2": map[string]interface{}{"address": "10.0.0.2:57400", "username": "admin"},
+		"router3": map[string]interface{}{"address": "10.0.0.3:57400", "username": "admin", "password": "pass3"},
+	})
+	targets, err := c.GetTargets()
+	if err == nil {
+		err = c.SetMissingCredentials(targets)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, exp := range map[string]string{"router1": "pass1", "router2": "pass1", "router3": "pass3"} {
+		if *targets[name].Password != exp {
+			t.Errorf("target %q: expected password %q, got %q", name, exp, *targets[name].Password)
+		}
+	}
+}
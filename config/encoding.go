@@ -0,0 +1,70 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"strings"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+// RPC names used to resolve the per command encoding.
+const (
+	RPCGet       = "get"
+	RPCSet       = "set"
+	RPCSubscribe = "subscribe"
+)
+
+// EncodingKeys are the per command encoding configuration keys,
+// they have no flag and are only read from the configuration file.
+var EncodingKeys = []string{
+	RPCGet + "-encoding",
+	RPCSet + "-encoding",
+	RPCSubscribe + "-encoding",
+}
+
+// RPCEncoding returns the encoding of the rpc requests sent to target tc,
+// resolved in order from the <rpc>-encoding key, the target encoding and the global encoding.
+// tc can be nil.
+func (c *Config) RPCEncoding(rpc string, tc *types.TargetConfig) string {
+	var enc string
+	switch rpc {
+	case RPCGet:
+		enc = c.LocalFlags.GetEncoding
+	case RPCSet:
+		enc = c.LocalFlags.SetEncoding
+	case RPCSubscribe:
+		enc = c.LocalFlags.SubscribeEncoding
+	}
+	if enc != "" {
+		return enc
+	}
+	if tc != nil && tc.Encoding != "" {
+		return tc.Encoding
+	}
+	return c.Encoding
+}
+
+// subscriptionEncoding returns the encoding of subscription sc sent to target tc.
+// The subscriptions read from the configuration default to the subscribe-encoding key,
+// then to the global encoding. Without the subscribe-encoding key, a subscription encoding
+// equal to the global one is overridden by the target encoding.
+// tc can be nil.
+func (c *Config) subscriptionEncoding(sc *types.SubscriptionConfig, tc *types.TargetConfig) string {
+	enc := sc.Encoding
+	if enc == "" || (c.LocalFlags.SubscribeEncoding == "" && strings.EqualFold(enc, c.Encoding)) {
+		if rpcEnc := c.RPCEncoding(RPCSubscribe, tc); rpcEnc != "" {
+			enc = rpcEnc
+		}
+	}
+	if enc == "" {
+		return subscriptionDefaultEncoding
+	}
+	return enc
+}
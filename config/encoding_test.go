@@ -0,0 +1,160 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestRPCEncoding(t *testing.T) {
+	tests := map[string]struct {
+		global string
+		cmd    string
+		target string
+		noTgt  bool
+		rpc    string
+		want   string
+	}{
+		"global": {
+			global: "json",
+			rpc:    RPCGet,
+			want:   "json",
+		},
+		"target_over_global": {
+			global: "json",
+			target: "proto",
+			rpc:    RPCGet,
+			want:   "proto",
+		},
+		"command_over_target": {
+			global: "json",
+			target: "proto",
+			cmd:    "json_ietf",
+			rpc:    RPCSet,
+			want:   "json_ietf",
+		},
+		"command_over_global": {
+			global: "json",
+			cmd:    "proto",
+			rpc:    RPCSubscribe,
+			want:   "proto",
+		},
+		"no_target": {
+			global: "json",
+			noTgt:  true,
+			rpc:    RPCGet,
+			want:   "json",
+		},
+		"other_command_key": {
+			global: "json",
+			target: "ascii",
+			cmd:    "proto",
+			rpc:    "capabilities",
+			want:   "ascii",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			c.Encoding = item.global
+			c.LocalFlags.GetEncoding = item.cmd
+			c.LocalFlags.SetEncoding = item.cmd
+			c.LocalFlags.SubscribeEncoding = item.cmd
+			var tc *types.TargetConfig
+			if !item.noTgt {
+				tc = &types.TargetConfig{Name: "t1", Encoding: item.target}
+			}
+			got := c.RPCEncoding(item.rpc, tc)
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestCreateSubscribeRequestEncoding(t *testing.T) {
+	tests := map[string]struct {
+		global       string
+		subscribe    string
+		target       string
+		subscription string
+		want         gnmi.Encoding
+	}{
+		"default": {
+			want: gnmi.Encoding_JSON,
+		},
+		"global": {
+			global: "ascii",
+			want:   gnmi.Encoding_ASCII,
+		},
+		"target": {
+			global: "json",
+			target: "proto",
+			want:   gnmi.Encoding_PROTO,
+		},
+		"subscribe_key": {
+			global:    "json",
+			target:    "ascii",
+			subscribe: "proto",
+			want:      gnmi.Encoding_PROTO,
+		},
+		"global_subscription": {
+			global:       "json",
+			target:       "proto",
+			subscription: "JSON",
+			want:         gnmi.Encoding_PROTO,
+		},
+		"subscription_with_target": {
+			global:       "json",
+			target:       "proto",
+			subscription: "ascii",
+			want:         gnmi.Encoding_ASCII,
+		},
+		"subscription": {
+			global:       "json",
+			target:       "ascii",
+			subscribe:    "proto",
+			subscription: "json_ietf",
+			want:         gnmi.Encoding_JSON_IETF,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			c.Encoding = item.global
+			c.LocalFlags.SubscribeEncoding = item.subscribe
+			sc := &types.SubscriptionConfig{
+				Name:     "sub1",
+				Paths:    []string{"/interfaces"},
+				Mode:     "once",
+				Encoding: item.subscription,
+			}
+			c.Targets = map[string]*types.TargetConfig{
+				"t1": {Name: "t1", Encoding: item.target},
+			}
+			req, err := c.CreateSubscribeRequest(sc, "t1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := req.GetSubscribe().GetEncoding()
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.want)
+				t.Logf("     got: %s", got)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openconfig/gnmic/types"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// encryptedValuePrefix marks an encrypted configuration value.
+	encryptedValuePrefix = "enc:"
+	// configKeyEnv is the environment variable holding the configuration encryption key.
+	configKeyEnv = "GNMIC_CONFIG_KEY"
+
+	// argon2id parameters deriving the AES-256 key from the configuration key,
+	// the salt is random and stored with each encrypted value.
+	kdfSaltSize = 16
+	kdfTime     = 1
+	kdfMemory   = 64 * 1024 // KiB
+	kdfThreads  = 4
+	kdfKeySize  = 32
+)
+
+// ErrNoConfigKey is returned when an encrypted value is found
+// and no encryption key is set.
+var ErrNoConfigKey = fmt.Errorf("no configuration encryption key, set env var %s or flag --config-key-file", configKeyEnv)
+
+// IsEncrypted returns true if the configuration value s is encrypted.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, encryptedValuePrefix)
+}
+
+// EncryptValue encrypts s with AES-GCM using a key derived from key with argon2id and a random salt.
+// The returned value is formatted as `enc:<base64(salt|nonce|ciphertext)>`.
+func EncryptValue(key []byte, s string) (string, error) {
+	salt := make([]byte, kdfSaltSize)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", err
+	}
+	b := gcm.Seal(append(salt, nonce...), nonce, []byte(s), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// DecryptValue decrypts a value produced by EncryptValue.
+// Values without the `enc:` prefix are returned as is.
+func DecryptValue(key []byte, s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %v", err)
+	}
+	if len(key) == 0 {
+		return "", ErrNoConfigKey
+	}
+	if len(b) < kdfSaltSize {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+	gcm, err := newGCM(key, b[:kdfSaltSize])
+	if err != nil {
+		return "", err
+	}
+	b = b[kdfSaltSize:]
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+	pt, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %v", err)
+	}
+	return string(pt), nil
+}
+
+// newGCM returns an AES-256-GCM cipher, the AES key is derived from key and salt with argon2id.
+func newGCM(key, salt []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, ErrNoConfigKey
+	}
+	k := argon2.IDKey(key, salt, kdfTime, kdfMemory, kdfThreads, kdfKeySize)
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ConfigKey returns the configuration encryption key,
+// read from env var GNMIC_CONFIG_KEY or from the file set with --config-key-file.
+func (c *Config) ConfigKey() ([]byte, error) {
+	if c.configKey != nil {
+		return c.configKey, nil
+	}
+	if k := os.Getenv(configKeyEnv); k != "" {
+		c.configKey = []byte(k)
+		return c.configKey, nil
+	}
+	if c.ConfigKeyFile == "" {
+		return nil, ErrNoConfigKey
+	}
+	b, err := os.ReadFile(c.ConfigKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration key file: %v", err)
+	}
+	k := strings.TrimSpace(string(b))
+	if k == "" {
+		return nil, fmt.Errorf("configuration key file %q is empty", c.ConfigKeyFile)
+	}
+	c.configKey = []byte(k)
+	return c.configKey, nil
+}
+
+// decryptTargetSecrets decrypts the target password and token if they are encrypted.
+// The error identifies the target and the field, never the values.
+func (c *Config) decryptTargetSecrets(tc *types.TargetConfig) error {
+	for field, v := range map[string]**string{
+		"password": &tc.Password,
+		"token":    &tc.Token,
+	} {
+		if *v == nil || !IsEncrypted(**v) {
+			continue
+		}
+		key, err := c.ConfigKey()
+		if err != nil {
+			return fmt.Errorf("target %q: failed to decrypt %s: %w", tc.Name, field, err)
+		}
+		pt, err := DecryptValue(key, **v)
+		if err != nil {
+			return fmt.Errorf("target %q: failed to decrypt %s: %v", tc.Name, field, err)
+		}
+		// the pointer might be shared with the global flags,
+		// do not overwrite the value it points to.
+		*v = &pt
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncryptValue(t *testing.T) {
+	key := []byte("s3cr3t-k3y")
+	enc, err := EncryptValue(key, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "admin") {
+		t.Fatalf("unexpected encrypted value %q", enc)
+	}
+	enc2, err := EncryptValue(key, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc == enc2 {
+		t.Errorf("expected a random salt and nonce per encryption")
+	}
+	dec, err := DecryptValue(key, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != "admin" {
+		t.Errorf("expected %q, got %q", "admin", dec)
+	}
+	_, err = DecryptValue([]byte("wrong-key"), enc)
+	if err == nil {
+		t.Errorf("expected decryption with the wrong key to fail")
+	}
+	_, err = DecryptValue(key, encryptedValuePrefix+"c2hvcnQ=")
+	if err == nil {
+		t.Errorf("expected a value shorter than the salt to fail")
+	}
+	dec, err = DecryptValue(key, "plain")
+	if err != nil || dec != "plain" {
+		t.Errorf("expected a plain value to be returned as is, got %q, %v", dec, err)
+	}
+}
+
+func TestDecryptTargetSecrets(t *testing.T) {
+	key := []byte("s3cr3t-k3y")
+	good, err := EncryptValue(key, "pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := EncryptValue([]byte("other-key"), "pass2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configKeyEnv, string(key))
+
+	c := New()
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "password": good},
+	})
+	targets, err := c.GetTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *targets["router1"].Password != "pass1" {
+		t.Errorf("expected router1 password to be decrypted, got %q", *targets["router1"].Password)
+	}
+
+	c = New()
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "password": good},
+		"router2": map[string]interface{}{"address": "10.0.0.2:57400", "password": bad},
+	})
+	_, err = c.GetTargets()
+	if err == nil {
+		t.Fatal("expected an error decrypting router2 password")
+	}
+	if !strings.Contains(err.Error(), `"router2"`) || strings.Contains(err.Error(), "pass1") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -19,6 +19,10 @@ func envToMap() map[string]interface{} {
 		if !strings.HasPrefix(e, envPrefix) {
 			continue
 		}
+		// the configuration encryption key is not a configuration value
+		if strings.HasPrefix(e, configKeyEnv+"=") {
+			continue
+		}
 		e = strings.ToLower(strings.Replace(e, envPrefix+"_", "", 1))
 		pair := strings.SplitN(e, "=", 2)
 		items := strings.Split(pair[0], "_")
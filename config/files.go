@@ -0,0 +1,80 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/openconfig/gnmic/utils"
+	"github.com/spf13/viper"
+)
+
+// ReadConfigFiles reads the configuration files in order,
+// the keys set in a file override the ones set in the previous files.
+// Maps, such as targets or subscriptions, are merged by name:
+// a later entry only overrides the fields it sets.
+// The watched configuration file is the last one.
+func (c *Config) ReadConfigFiles(ctx context.Context, files []string) error {
+	merged := viper.NewWithOptions(viper.KeyDelimiter("/"))
+	origins := make(map[string]string)
+	values := make(map[string]interface{})
+	c.fileConflicts = make([]string, 0)
+	c.fileOrigins = make(map[string]*fileOrigin)
+	for i, file := range files {
+		b, err := utils.ReadFile(ctx, file)
+		if err != nil {
+			return err
+		}
+		fv := viper.NewWithOptions(viper.KeyDelimiter("/"))
+		fv.SetConfigFile(file)
+		err = fv.ReadConfig(bytes.NewBuffer(b))
+		if err != nil {
+			return fmt.Errorf("failed to read configuration file %q: %v", file, err)
+		}
+		lines := keyLines(file, b)
+		for _, k := range fv.AllKeys() {
+			c.fileOrigins[k] = &fileOrigin{file: file, line: lines[k]}
+			v := fv.Get(k)
+			if prev, ok := origins[k]; ok && !reflect.DeepEqual(values[k], v) {
+				c.fileConflicts = append(c.fileConflicts,
+					fmt.Sprintf("key %q set in %q is overridden by %q", k, prev, file))
+			}
+			origins[k] = file
+			values[k] = v
+		}
+		err = merged.MergeConfigMap(fv.AllSettings())
+		if err != nil {
+			return fmt.Errorf("failed to merge configuration file %q: %v", file, err)
+		}
+		if i == 0 {
+			// reset the settings read from a previous load
+			c.FileConfig.SetConfigFile(file)
+			err = c.FileConfig.ReadConfig(bytes.NewBuffer(b))
+		} else {
+			err = c.FileConfig.MergeConfigMap(fv.AllSettings())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to merge configuration file %q: %v", file, err)
+		}
+	}
+	if len(files) > 0 {
+		c.FileConfig.SetConfigFile(files[len(files)-1])
+	}
+	c.fileSettings = merged.AllSettings()
+	return nil
+}
+
+// FileSettings returns the settings read from the configuration files,
+// without the environment variables and the flags values.
+func (c *Config) FileSettings() map[string]interface{} {
+	return c.fileSettings
+}
@@ -0,0 +1,78 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sharedConfigFile = `
+username: admin
+password: admin
+skip-verify: true
+timeout: 10s
+targets:
+  router1:
+    address: 10.0.0.1:57400
+    username: user1
+  router2:
+    address: 10.0.0.2:57400
+`
+
+const siteConfigFile = `
+timeout: 30s
+skip-verify: true
+targets:
+  router1:
+    password: pass1
+  router3:
+    address: 10.0.0.3:57400
+`
+
+func TestReadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		filepath.Join(dir, "shared.yaml"),
+		filepath.Join(dir, "site.yaml"),
+	}
+	for i, content := range []string{sharedConfigFile, siteConfigFile} {
+		err := os.WriteFile(files[i], []byte(content), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := New()
+	c.GlobalFlags.CfgFile = files
+	err := c.Load(context.Background())
+	if err != nil {
+		t.Fatalf("failed to load config files: %v", err)
+	}
+	if c.Username != "admin" || c.Timeout.String() != "30s" {
+		t.Errorf("unexpected global values: username=%q timeout=%s", c.Username, c.Timeout)
+	}
+	if c.FileConfig.ConfigFileUsed() != files[1] {
+		t.Errorf("expected the last file to be used, got %q", c.FileConfig.ConfigFileUsed())
+	}
+	targets := c.FileSettings()["targets"].(map[string]interface{})
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 merged targets, got %v", targets)
+	}
+	router1 := targets["router1"].(map[string]interface{})
+	if router1["address"] != "10.0.0.1:57400" || router1["username"] != "user1" || router1["password"] != "pass1" {
+		t.Errorf("unexpected router1 merged fields: %v", router1)
+	}
+	// only timeout has a different value in both files
+	if len(c.fileConflicts) != 1 || !strings.Contains(c.fileConflicts[0], `"timeout"`) {
+		t.Errorf("unexpected conflicts: %v", c.fileConflicts)
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 const (
 	defaultAddress           = ":57400"
+	defaultCacheAddress      = ":57401"
 	defaultMaxSubscriptions  = 64
 	defaultMaxUnaryRPC       = 64
 	minimumSampleInterval    = 1 * time.Millisecond
@@ -48,6 +49,8 @@ type gnmiServer struct {
 	ServiceRegistration *serviceRegistration `mapstructure:"service-registration,omitempty" json:"service-registration,omitempty"`
 	// cache config
 	Cache *cache.Config `mapstructure:"cache,omitempty" json:"cache,omitempty"`
+	// serve Get requests from the cache instead of the targets
+	GetFromCache bool `mapstructure:"get-from-cache,omitempty" json:"get-from-cache,omitempty"`
 }
 
 type serviceRegistration struct {
@@ -66,6 +69,7 @@ type serviceRegistration struct {
 
 func (c *Config) GetGNMIServer() error {
 	if !c.FileConfig.IsSet("gnmi-server") {
+		c.setGnmiServerFromFlags()
 		return nil
 	}
 	c.GnmiServer = new(gnmiServer)
@@ -95,6 +99,7 @@ func (c *Config) GetGNMIServer() error {
 
 	c.GnmiServer.EnableMetrics = os.ExpandEnv(c.FileConfig.GetString("gnmi-server/enable-metrics")) == trueString
 	c.GnmiServer.Debug = os.ExpandEnv(c.FileConfig.GetString("gnmi-server/debug")) == trueString
+	c.GnmiServer.GetFromCache = os.ExpandEnv(c.FileConfig.GetString("gnmi-server/get-from-cache")) == trueString
 	c.setGnmiServerDefaults()
 
 	if c.FileConfig.IsSet("gnmi-server/service-registration") {
@@ -126,10 +131,34 @@ func (c *Config) GetGNMIServer() error {
 		//
 		c.GnmiServer.Cache.FetchBatchSize = c.FileConfig.GetInt("gnmi-server/cache/fetch-batch-size")
 		c.GnmiServer.Cache.FetchWaitTime = c.FileConfig.GetDuration("gnmi-server/cache/fetch-wait-time")
+		c.GnmiServer.Cache.MaxEntries = c.FileConfig.GetInt64("gnmi-server/cache/max-entries")
 	}
+	c.setGnmiServerFromFlags()
 	return nil
 }
 
+// setGnmiServerFromFlags enables the gNMI server serving Get and Subscribe ONCE
+// requests from a local cache if the subscribe flag --cache is set.
+func (c *Config) setGnmiServerFromFlags() {
+	if !c.LocalFlags.SubscribeCache {
+		return
+	}
+	if c.GnmiServer == nil {
+		c.GnmiServer = &gnmiServer{Address: defaultCacheAddress}
+		c.setGnmiServerDefaults()
+	}
+	if c.LocalFlags.SubscribeServerAddress != "" {
+		c.GnmiServer.Address = c.LocalFlags.SubscribeServerAddress
+	}
+	if c.GnmiServer.Cache == nil {
+		c.GnmiServer.Cache = new(cache.Config)
+	}
+	if c.LocalFlags.SubscribeCacheMaxEntries > 0 {
+		c.GnmiServer.Cache.MaxEntries = c.LocalFlags.SubscribeCacheMaxEntries
+	}
+	c.GnmiServer.GetFromCache = true
+}
+
 func (c *Config) setGnmiServerDefaults() {
 	if c.GnmiServer.Address == "" {
 		c.GnmiServer.Address = defaultAddress
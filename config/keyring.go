@@ -0,0 +1,132 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/openconfig/gnmic/types"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// keyringService is the service name the targets passwords
+// are stored under in the OS keyring.
+const keyringService = "gnmic"
+
+var (
+	// ErrKeyringNotFound is returned when the OS keyring has no password for a target.
+	ErrKeyringNotFound = errors.New("password not found in the OS keyring")
+	// ErrKeyringUnavailable is returned when no OS keyring backend is available.
+	ErrKeyringUnavailable = errors.New("OS keyring unavailable")
+)
+
+// KeyringGetPassword returns the password of target stored in the OS keyring.
+func KeyringGetPassword(target string) (string, error) {
+	pass, err := keyring.Get(keyringService, target)
+	return pass, keyringError(err)
+}
+
+// KeyringSetPassword stores the password of target in the OS keyring.
+func KeyringSetPassword(target, password string) error {
+	return keyringError(keyring.Set(keyringService, target, password))
+}
+
+// KeyringDeletePassword deletes the password of target from the OS keyring.
+func KeyringDeletePassword(target string) error {
+	return keyringError(keyring.Delete(keyringService, target))
+}
+
+func keyringError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, keyring.ErrNotFound):
+		return ErrKeyringNotFound
+	default:
+		return fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+}
+
+// setKeyringPassword sets the password of a target without one
+// from the OS keyring.
+// If the keyring has no entry for the target and prompting is allowed,
+// the password is prompted and optionally saved in the keyring.
+func (c *Config) setKeyringPassword(tc *types.TargetConfig) error {
+	if !c.UseKeyring || (tc.Password != nil && *tc.Password != "") {
+		return nil
+	}
+	if pass, ok := c.keyringPasswords[tc.Name]; ok {
+		tc.Password = &pass
+		return nil
+	}
+	pass, err := KeyringGetPassword(tc.Name)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrKeyringNotFound):
+		if !c.CanPrompt() {
+			return nil
+		}
+		pass, err = ReadPassword(fmt.Sprintf("password for target %q", tc.Name))
+		if err != nil {
+			return err
+		}
+		if confirm(fmt.Sprintf("save the password of target %q in the OS keyring", tc.Name)) {
+			err = KeyringSetPassword(tc.Name, pass)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to save the password of target %q: %v\n", tc.Name, err)
+			}
+		}
+	default:
+		if !c.keyringWarned {
+			c.keyringWarned = true
+			fmt.Fprintf(os.Stderr, "warning: %v, using the configured passwords\n", err)
+		}
+		c.logger.Printf("target %q: %v", tc.Name, err)
+		return nil
+	}
+	c.keyringPasswords[tc.Name] = pass
+	tc.Password = &pass
+	return nil
+}
+
+// ReadPassword reads a password from stdin,
+// masking it if stdin is a terminal.
+func ReadPassword(label string) (string, error) {
+	if isTerminal(os.Stdin) {
+		p := promptui.Prompt{
+			Label: label,
+			Mask:  '*',
+		}
+		return p.Run()
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func confirm(label string) bool {
+	p := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	_, err := p.Run()
+	return err == nil
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
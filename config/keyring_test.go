@@ -0,0 +1,56 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringPasswords(t *testing.T) {
+	keyring.MockInit()
+	err := KeyringSetPassword("router1", "pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New()
+	c.UseKeyring = true
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400", "password": ""},
+		"router2": map[string]interface{}{"address": "10.0.0.2:57400", "password": "pass2"},
+	})
+	targets, err := c.GetTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *targets["router1"].Password != "pass1" {
+		t.Errorf("expected router1 password from the keyring, got %q", *targets["router1"].Password)
+	}
+	if *targets["router2"].Password != "pass2" {
+		t.Errorf("expected router2 configured password, got %q", *targets["router2"].Password)
+	}
+
+	err = KeyringDeletePassword("router1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = KeyringGetPassword("router1")
+	if !errors.Is(err, ErrKeyringNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	keyring.MockInitWithError(errors.New("no backend"))
+	_, err = KeyringGetPassword("router1")
+	if !errors.Is(err, ErrKeyringUnavailable) {
+		t.Errorf("expected an unavailable error, got %v", err)
+	}
+}
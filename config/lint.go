@@ -0,0 +1,371 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/spf13/viper"
+)
+
+// LintIssue is a problem found in a configuration file,
+// Key is the path to the offending key, e.g: targets/router1/username.
+type LintIssue struct {
+	Key     string
+	Message string
+}
+
+func (li *LintIssue) String() string {
+	if li.Key == "" {
+		return li.Message
+	}
+	return fmt.Sprintf("%s: %s", li.Key, li.Message)
+}
+
+// Lint validates the configuration read by v against the configuration schema.
+// flagKeys are the top level keys matching a command flag,
+// `<flag>` for the global flags and `<cmd>-<flag>` for the local ones.
+// It returns the unknown keys, missing required fields, values that cannot be decoded
+// and references to undefined subscriptions, outputs or processors.
+func Lint(v *viper.Viper, flagKeys []string) []*LintIssue {
+	l := &linter{
+		settings: v.AllSettings(),
+		flagKeys: make(map[string]struct{}, len(flagKeys)),
+		issues:   make([]*LintIssue, 0),
+	}
+	for _, k := range flagKeys {
+		l.flagKeys[k] = struct{}{}
+	}
+	l.lintGlobals()
+	l.lintSubscriptions()
+	l.lintOutputs()
+	l.lintProcessors()
+	l.lintTargets()
+	sort.Slice(l.issues, func(i, j int) bool {
+		if l.issues[i].Key == l.issues[j].Key {
+			return l.issues[i].Message < l.issues[j].Message
+		}
+		return l.issues[i].Key < l.issues[j].Key
+	})
+	return l.issues
+}
+
+type linter struct {
+	settings map[string]interface{}
+	flagKeys map[string]struct{}
+	issues   []*LintIssue
+}
+
+func (l *linter) addIssue(key, format string, args ...interface{}) {
+	l.issues = append(l.issues, &LintIssue{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// sectionKeys returns the top level keys of the configuration sections,
+// i.e. the keys of the Config struct fields which are not flags.
+func sectionKeys() map[string]struct{} {
+	keys := map[string]struct{}{
+		// global event processors, prepended to all the outputs processors
+		"event-processors": {},
+	}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		keys[tag] = struct{}{}
+	}
+	return keys
+}
+
+// decode decodes input into out, reporting the keys unknown to out
+// and the values that cannot be decoded under key.
+func (l *linter) decode(key string, input interface{}, out interface{}) {
+	md := new(mapstructure.Metadata)
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Metadata:         md,
+		Result:           out,
+	})
+	if err != nil {
+		l.addIssue(key, "%v", err)
+		return
+	}
+	err = dec.Decode(input)
+	if err != nil {
+		if merr, ok := err.(*mapstructure.Error); ok {
+			for _, e := range merr.Errors {
+				l.addIssue(key, "%s", e)
+			}
+		} else {
+			l.addIssue(key, "%v", err)
+		}
+	}
+	sort.Strings(md.Unused)
+	for _, k := range md.Unused {
+		l.addIssue(key+"/"+k, "unknown key")
+	}
+}
+
+func (l *linter) lintGlobals() {
+	sections := sectionKeys()
+	flags := make(map[string]interface{})
+	keys := make([]string, 0, len(l.settings))
+	for k := range l.settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, ok := sections[k]; ok {
+			continue
+		}
+		if _, ok := l.flagKeys[k]; !ok {
+			l.addIssue(k, "unknown key")
+			continue
+		}
+		flags[k] = l.settings[k]
+	}
+	// decode the flags values to check their types.
+	// the unknown keys are already reported above.
+	gf := new(GlobalFlags)
+	lf := new(LocalFlags)
+	for _, out := range []interface{}{gf, lf} {
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           out,
+		})
+		if err != nil {
+			l.addIssue("", "%v", err)
+			continue
+		}
+		err = dec.Decode(flags)
+		if merr, ok := err.(*mapstructure.Error); ok {
+			for _, e := range merr.Errors {
+				l.addIssue("", "%s", e)
+			}
+		} else if err != nil {
+			l.addIssue("", "%v", err)
+		}
+	}
+	for _, k := range EncodingKeys {
+		if enc, ok := l.settings[k].(string); ok {
+			l.lintEncoding(k, enc)
+		}
+	}
+	l.lintReferences("subscribe-name", "subscriptions", l.settings["subscribe-name"])
+	l.lintReferences("subscribe-output", "outputs", l.settings["subscribe-output"])
+	l.lintReferences("event-processors", "processors", l.settings["event-processors"])
+}
+
+func (l *linter) lintSubscriptions() {
+	for name, sub := range l.section("subscriptions") {
+		key := "subscriptions/" + name
+		def, pathConfigs, err := decodePathConfigs(sub)
+		if err != nil {
+			l.addIssue(key, "%v", err)
+			continue
+		}
+		sc := new(types.SubscriptionConfig)
+		l.decode(key, def, sc)
+		if len(sc.Paths) == 0 {
+			l.addIssue(key, "missing required field %q", "paths")
+		}
+		if sc.Mode != "" {
+			if _, ok := gnmi.SubscriptionList_Mode_value[strings.ToUpper(sc.Mode)]; !ok {
+				l.addIssue(key+"/mode", "invalid value %q", sc.Mode)
+			}
+		}
+		if sc.StreamMode != "" {
+			if _, ok := gnmi.SubscriptionMode_value[strings.ToUpper(strings.ReplaceAll(sc.StreamMode, "-", "_"))]; !ok {
+				l.addIssue(key+"/stream-mode", "invalid value %q", sc.StreamMode)
+			}
+		}
+		l.lintEncoding(key+"/encoding", sc.Encoding)
+		if pathConfigs != nil {
+			sc.PathConfigs = pathConfigs
+			_ = setDefaults(sc)
+			err = validatePathConfigs(sc)
+			if err != nil {
+				l.addIssue(key, "%v", err)
+			}
+		}
+	}
+}
+
+// lintEncoding reports enc if it is not a gNMI encoding name.
+func (l *linter) lintEncoding(key, enc string) {
+	if enc == "" {
+		return
+	}
+	if _, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(enc, "-", "_"))]; !ok {
+		l.addIssue(key, "invalid value %q", enc)
+	}
+}
+
+func (l *linter) lintOutputs() {
+	for name, out := range l.section("outputs") {
+		key := "outputs/" + name
+		outCfg, ok := out.(map[string]interface{})
+		if !ok {
+			l.addIssue(key, "expecting a map, got %T", out)
+			continue
+		}
+		outType, ok := outCfg["type"]
+		if !ok {
+			l.addIssue(key, "missing required field %q", "type")
+		} else if _, ok := outputs.OutputTypes[fmt.Sprintf("%v", outType)]; !ok {
+			l.addIssue(key+"/type", "unknown output type %q", outType)
+		} else if format, ok := outCfg["format"]; ok {
+			l.lintOutputFormat(key+"/format", fmt.Sprintf("%v", outType), fmt.Sprintf("%v", format))
+		}
+		l.lintReferences(key+"/event-processors", "processors", outCfg["event-processors"])
+	}
+}
+
+// lintOutputFormat reports format if the output type typ does not support it or ignores it.
+func (l *linter) lintOutputFormat(key, typ, format string) {
+	if format == "" {
+		return
+	}
+	formats, ok := outputs.OutputFormats[typ]
+	if !ok {
+		l.addIssue(key, "format is ignored by output type %q", typ)
+		return
+	}
+	if !outputs.FormatSupported(typ, format) {
+		l.addIssue(key, "format %q not supported by output type %q, expecting one of: %s", format, typ, strings.Join(formats, ", "))
+	}
+}
+
+func (l *linter) lintProcessors() {
+	for name, p := range l.section("processors") {
+		key := "processors/" + name
+		pCfg, ok := p.(map[string]interface{})
+		if !ok || len(pCfg) == 0 {
+			l.addIssue(key, "expecting a map with a single processor type, got %v", p)
+			continue
+		}
+		if len(pCfg) > 1 {
+			l.addIssue(key, "expecting a single processor type, got %d", len(pCfg))
+		}
+		for pType := range pCfg {
+			if !strInlist(pType, formatters.EventProcessorTypes) {
+				l.addIssue(key+"/"+pType, "unknown processor type")
+			}
+		}
+	}
+}
+
+func (l *linter) lintTargets() {
+	groups := make(map[string]struct{})
+	for name, t := range l.section("targets") {
+		key := "targets/" + name
+		if t == nil {
+			continue
+		}
+		tc := new(types.TargetConfig)
+		l.decode(key, t, tc)
+		l.lintEncoding(key+"/encoding", tc.Encoding)
+		for _, enc := range tc.EncodingFallback {
+			l.lintEncoding(key+"/encoding-fallback", enc)
+		}
+		if tc.AuthScheme != "" && !strInlist(tc.AuthScheme, types.AuthSchemes) {
+			l.addIssue(key+"/auth-scheme", "invalid value %q", tc.AuthScheme)
+		}
+		l.lintReferences(key+"/subscriptions", "subscriptions", tc.Subscriptions)
+		l.lintReferences(key+"/outputs", "outputs", tc.Outputs)
+		l.lintGroups(key+"/groups", t, tc.Groups)
+		for _, g := range tc.Groups {
+			groups[g] = struct{}{}
+		}
+	}
+	var names []string
+	switch g := l.settings["group"].(type) {
+	case []interface{}:
+		for _, n := range g {
+			names = append(names, fmt.Sprintf("%v", n))
+		}
+	case string:
+		names = SanitizeArrayFlagValue([]string{g})
+	}
+	for _, n := range names {
+		if _, ok := groups[n]; !ok {
+			l.addIssue("group", "reference to undefined target group %q", n)
+		}
+	}
+}
+
+// lintGroups reports an empty groups list or an empty group name in the target t groups.
+func (l *linter) lintGroups(key string, t interface{}, groups []string) {
+	if tm, ok := t.(map[string]interface{}); ok {
+		if _, ok := tm["groups"]; ok && len(groups) == 0 {
+			l.addIssue(key, "empty groups list")
+			return
+		}
+	}
+	for _, g := range groups {
+		if strings.TrimSpace(g) == "" {
+			l.addIssue(key, "empty group name")
+		}
+	}
+}
+
+// section returns the entries of the top level map section.
+func (l *linter) section(name string) map[string]interface{} {
+	v, ok := l.settings[name]
+	if !ok || v == nil {
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		l.addIssue(name, "expecting a map, got %T", v)
+		return nil
+	}
+	return m
+}
+
+// lintReferences reports the names in refs not defined in the top level map section.
+func (l *linter) lintReferences(key, section string, refs interface{}) {
+	defined, _ := l.settings[section].(map[string]interface{})
+	var names []string
+	switch refs := refs.(type) {
+	case nil:
+		return
+	case []string:
+		names = refs
+	case []interface{}:
+		for _, r := range refs {
+			names = append(names, fmt.Sprintf("%v", r))
+		}
+	case string:
+		names = SanitizeArrayFlagValue([]string{refs})
+	default:
+		l.addIssue(key, "expecting a list, got %T", refs)
+		return
+	}
+	for _, n := range names {
+		// outputs can be defined inline as a URL, e.g: tcp://collector:9000
+		if section == "outputs" && strings.Contains(n, "://") {
+			continue
+		}
+		if _, ok := defined[strings.ToLower(n)]; !ok {
+			l.addIssue(key, "reference to undefined %s %q", strings.TrimSuffix(section, "s"), n)
+		}
+	}
+}
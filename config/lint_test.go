@@ -0,0 +1,247 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var lintFlagKeys = []string{"username", "password", "timeout", "insecure", "subscribe-name", "subscribe-output", "group"}
+
+var lintTestSet = map[string]struct {
+	in  []byte
+	out []string
+}{
+	"valid": {
+		in: []byte(`
+username: admin
+timeout: 10s
+targets:
+  router1:
+    address: 10.0.0.1:57400
+    subscriptions:
+      - sub1
+    outputs:
+      - out1
+subscriptions:
+  sub1:
+    paths:
+      - /interfaces
+    mode: stream
+    stream-mode: on-change
+outputs:
+  out1:
+    type: file
+    event-processors:
+      - proc1
+processors:
+  proc1:
+    event-drop:
+      value-names:
+        - ".*"
+subscribe-output:
+  - tcp://collector:9000
+`),
+		out: []string{},
+	},
+	"unknown_keys": {
+		in: []byte(`
+user-name: admin
+targets:
+  router1:
+    user-name: admin
+`),
+		out: []string{
+			"targets/router1/user-name: unknown key",
+			"user-name: unknown key",
+		},
+	},
+	"missing_required_fields": {
+		in: []byte(`
+subscriptions:
+  sub1:
+    mode: once
+outputs:
+  out1:
+    format: json
+`),
+		out: []string{
+			"outputs/out1: missing required field \"type\"",
+			"subscriptions/sub1: missing required field \"paths\"",
+		},
+	},
+	"invalid_values": {
+		in: []byte(`
+subscriptions:
+  sub1:
+    paths:
+      - /interfaces
+    mode: streaming
+    encoding: xml
+outputs:
+  out1:
+    type: kafkaa
+processors:
+  proc1:
+    event-dropp:
+      value-names:
+        - ".*"
+`),
+		out: []string{
+			"outputs/out1/type: unknown output type \"kafkaa\"",
+			"processors/proc1/event-dropp: unknown processor type",
+			"subscriptions/sub1/encoding: invalid value \"xml\"",
+			"subscriptions/sub1/mode: invalid value \"streaming\"",
+		},
+	},
+	"path_stream_options": {
+		in: []byte(`
+subscriptions:
+  sub1:
+    paths:
+      - /interfaces
+      - path: /system
+        mode: on-change
+        heartbeat-interval: 60s
+  sub2:
+    stream-mode: sample
+    paths:
+      - path: /interfaces
+        heartbeat-interval: 60s
+  sub3:
+    paths:
+      - mode: sample
+`),
+		out: []string{
+			"subscriptions/sub2: paths[0]: heartbeat-interval requires mode on-change or suppress-redundant, got mode \"sample\"",
+			"subscriptions/sub3: paths[0]: missing path",
+		},
+	},
+	"output_formats": {
+		in: []byte(`
+outputs:
+  out1:
+    type: file
+    format: proto
+  out2:
+    type: prometheus
+    format: event
+  out3:
+    type: kafka
+    format: proto
+`),
+		out: []string{
+			"outputs/out1/format: format \"proto\" not supported by output type \"file\", expecting one of: json, protojson, prototext, event, flat",
+			"outputs/out2/format: format is ignored by output type \"prometheus\"",
+		},
+	},
+	"dangling_references": {
+		in: []byte(`
+subscribe-name: sub1,sub3
+targets:
+  router1:
+    subscriptions:
+      - sub2
+    outputs:
+      - out1
+subscriptions:
+  sub1:
+    paths:
+      - /interfaces
+outputs:
+  out2:
+    type: file
+    event-processors:
+      - proc1
+`),
+		out: []string{
+			"outputs/out2/event-processors: reference to undefined processor \"proc1\"",
+			"subscribe-name: reference to undefined subscription \"sub3\"",
+			"targets/router1/outputs: reference to undefined output \"out1\"",
+			"targets/router1/subscriptions: reference to undefined subscription \"sub2\"",
+		},
+	},
+	"target_groups": {
+		in: []byte(`
+group: core,edge
+targets:
+  router1:
+    groups:
+      - core
+  router2:
+    groups: []
+  router3:
+    groups:
+      - ""
+`),
+		out: []string{
+			"group: reference to undefined target group \"edge\"",
+			"targets/router2/groups: empty groups list",
+			"targets/router3/groups: empty group name",
+		},
+	},
+}
+
+func TestLint(t *testing.T) {
+	for name, item := range lintTestSet {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(bytes.NewBuffer(item.in))
+			if err != nil {
+				t.Fatalf("failed reading config: %v", err)
+			}
+			issues := Lint(cfg.FileConfig, lintFlagKeys)
+			got := make([]string, 0, len(issues))
+			for _, i := range issues {
+				got = append(got, i.String())
+			}
+			if !reflect.DeepEqual(got, item.out) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.out)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+// the duration parsing error text depends on the Go version,
+// "unknown unit" or "invalid duration", only the key and the error kind are checked.
+func TestLintBadDurations(t *testing.T) {
+	cfg := New()
+	cfg.FileConfig.SetConfigType("yaml")
+	err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(`
+timeout: 10x
+targets:
+  router1:
+    timeout: forever
+`))
+	if err != nil {
+		t.Fatalf("failed reading config: %v", err)
+	}
+	issues := Lint(cfg.FileConfig, lintFlagKeys)
+	prefixes := []string{
+		"error decoding 'timeout'",
+		"targets/router1: error decoding 'timeout'",
+	}
+	if len(issues) != len(prefixes) {
+		t.Fatalf("expected %d issues, got %d: %v", len(prefixes), len(issues), issues)
+	}
+	for i, issue := range issues {
+		msg := issue.String()
+		if !strings.HasPrefix(msg, prefixes[i]) ||
+			!(strings.Contains(msg, "invalid duration") || strings.Contains(msg, "unknown unit")) {
+			t.Errorf("unexpected issue %d: %q", i, msg)
+		}
+	}
+}
@@ -11,6 +11,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/openconfig/gnmic/loaders"
 	_ "github.com/openconfig/gnmic/loaders/all"
@@ -24,6 +25,15 @@ func (c *Config) GetLoader() error {
 		}
 		return nil
 	}
+	if c.FileConfig.GetBool("subscribe-docker-discovery") {
+		var err error
+		c.Loader, err = c.dockerDiscoveryLoader()
+		return err
+	}
+	if len(c.FileConfig.GetStringSlice("subscribe-consul-service")) > 0 {
+		c.Loader = c.consulDiscoveryLoader()
+		return nil
+	}
 
 	c.Loader = c.FileConfig.GetStringMap("loader")
 	for k, v := range c.Loader {
@@ -48,3 +58,81 @@ func (c *Config) GetLoader() error {
 	return fmt.Errorf("field 'type' not a string, found a %T", c.Loader["type"])
 
 }
+
+// dockerDiscoveryLoader builds a docker loader configuration
+// from the subscribe command --docker-* flags.
+func (c *Config) dockerDiscoveryLoader() (map[string]interface{}, error) {
+	containerFilter := make(map[string]string)
+	for _, f := range c.FileConfig.GetStringSlice("subscribe-docker-filter") {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("invalid docker filter %q, expected format is key=value, e.g: label=clab-node-kind=srl", f)
+		}
+		// a label filter value can itself be a key=value pair,
+		// the docker loader expects it to be split between the map key and value.
+		if lk, lv, ok := strings.Cut(v, "="); ok {
+			k = k + "=" + lk
+			v = lv
+		}
+		containerFilter[k] = v
+	}
+	filter := map[string]interface{}{
+		"containers": []interface{}{containerFilter},
+		"port":       c.FileConfig.GetString("subscribe-docker-port"),
+	}
+	if profileName := c.FileConfig.GetString("subscribe-docker-profile"); profileName != "" {
+		profile := c.FileConfig.GetStringMap(fmt.Sprintf("target-profiles/%s", profileName))
+		if len(profile) == 0 {
+			return nil, fmt.Errorf("target profile %q not found", profileName)
+		}
+		for k, v := range profile {
+			profile[k] = convert(v)
+		}
+		expandMapEnv(profile)
+		filter["config"] = profile
+	}
+	ld := map[string]interface{}{
+		"type":    "docker",
+		"address": c.FileConfig.GetString("subscribe-docker-address"),
+		"filters": []interface{}{filter},
+	}
+	if interval := c.FileConfig.GetDuration("subscribe-docker-interval"); interval > 0 {
+		ld["interval"] = interval.String()
+	}
+	if c.Debug {
+		c.logger.Printf("docker discovery loader config: %+v", ld)
+	}
+	return ld, nil
+}
+
+// consulDiscoveryLoader builds a consul loader configuration
+// from the subscribe command --consul-* flags.
+func (c *Config) consulDiscoveryLoader() map[string]interface{} {
+	services := make([]interface{}, 0)
+	for _, name := range c.FileConfig.GetStringSlice("subscribe-consul-service") {
+		services = append(services, map[string]interface{}{"name": name})
+	}
+	ld := map[string]interface{}{
+		"type":     "consul",
+		"services": services,
+	}
+	if addr := c.FileConfig.GetString("subscribe-consul-address"); addr != "" {
+		ld["address"] = addr
+	}
+	if token := c.FileConfig.GetString("subscribe-consul-token"); token != "" {
+		ld["token"] = token
+	}
+	tls := make(map[string]interface{})
+	for _, k := range []string{"ca-file", "cert-file", "key-file"} {
+		if v := c.FileConfig.GetString("subscribe-consul-tls-" + k); v != "" {
+			tls[k] = v
+		}
+	}
+	if c.FileConfig.GetBool("subscribe-consul-tls-skip-verify") {
+		tls["skip-verify"] = true
+	}
+	if len(tls) > 0 {
+		ld["tls"] = tls
+	}
+	return ld
+}
@@ -0,0 +1,124 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var getLoaderTestSet = map[string]struct {
+	in      []byte
+	out     map[string]interface{}
+	wantErr bool
+}{
+	"docker_discovery": {
+		in: []byte(`
+subscribe-docker-discovery: true
+subscribe-docker-address: unix:///var/run/docker.sock
+subscribe-docker-filter:
+  - label=clab-node-kind=srl
+  - status=running
+subscribe-docker-port: label=gnmi-port
+subscribe-docker-interval: 1m
+subscribe-docker-profile: srl
+target-profiles:
+  srl:
+    username: admin
+    password: admin
+    skip-verify: true
+`),
+		out: map[string]interface{}{
+			"type":    "docker",
+			"address": "unix:///var/run/docker.sock",
+			"filters": []interface{}{
+				map[string]interface{}{
+					"containers": []interface{}{
+						map[string]string{
+							"label=clab-node-kind": "srl",
+							"status":               "running",
+						},
+					},
+					"port": "label=gnmi-port",
+					"config": map[string]interface{}{
+						"username":    "admin",
+						"password":    "admin",
+						"skip-verify": true,
+					},
+				},
+			},
+			"interval": "1m0s",
+		},
+	},
+	"consul_discovery": {
+		in: []byte(`
+subscribe-consul-address: consul.example.com:8501
+subscribe-consul-service:
+  - gnmi
+subscribe-consul-token: secret
+subscribe-consul-tls-ca-file: /etc/ssl/consul-ca.pem
+`),
+		out: map[string]interface{}{
+			"type":    "consul",
+			"address": "consul.example.com:8501",
+			"token":   "secret",
+			"services": []interface{}{
+				map[string]interface{}{"name": "gnmi"},
+			},
+			"tls": map[string]interface{}{
+				"ca-file": "/etc/ssl/consul-ca.pem",
+			},
+		},
+	},
+	"docker_discovery_unknown_profile": {
+		in: []byte(`
+subscribe-docker-discovery: true
+subscribe-docker-profile: srl
+`),
+		wantErr: true,
+	},
+	"docker_discovery_invalid_filter": {
+		in: []byte(`
+subscribe-docker-discovery: true
+subscribe-docker-filter:
+  - label
+`),
+		wantErr: true,
+	},
+}
+
+func TestGetLoader(t *testing.T) {
+	for name, data := range getLoaderTestSet {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetLogger()
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(bytes.NewBuffer(data.in))
+			if err != nil {
+				t.Fatalf("failed reading config: %v", err)
+			}
+			err = cfg.GetLoader()
+			if data.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got loader: %+v", cfg.Loader)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed getting loader: %v", err)
+			}
+			if !reflect.DeepEqual(cfg.Loader, data.out) {
+				t.Errorf("failed at %q", name)
+				t.Logf("exp value: %+v", data.out)
+				t.Logf("got value: %+v", cfg.Loader)
+			}
+		})
+	}
+}
@@ -10,22 +10,59 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/openconfig/gnmic/outputs"
 	_ "github.com/openconfig/gnmic/outputs/all"
 )
 
+const (
+	// DefaultStdoutOutput is the name of the output printing
+	// the received messages to stdout.
+	DefaultStdoutOutput = "default-stdout"
+	// defaultTeeQueueSize is the queue size set on the outputs
+	// without one when --tee is set.
+	defaultTeeQueueSize = 1000
+)
+
+// outputDefaultFormat returns the format of the output name of type typ without one:
+// the global format if the output type supports it, otherwise the output type default format.
+func (c *Config) outputDefaultFormat(name, typ string) string {
+	format := c.FileConfig.GetString("format")
+	if format == "" {
+		return ""
+	}
+	if _, ok := outputs.OutputFormats[typ]; ok && !outputs.FormatSupported(typ, format) {
+		c.logger.Printf("output %q: format %q not supported by output type %q, using the output default format", name, format, typ)
+		return ""
+	}
+	return format
+}
+
 func (c *Config) GetOutputs() (map[string]map[string]interface{}, error) {
 	outDef := c.FileConfig.GetStringMap("outputs")
-	if len(outDef) == 0 && !c.FileConfig.GetBool("subscribe-quiet") {
+	tee := c.FileConfig.GetBool("subscribe-tee")
+	if (len(outDef) == 0 || tee) && !c.FileConfig.GetBool("subscribe-quiet") {
+		indent := c.FileConfig.GetString("json-indent")
 		stdoutConfig := map[string]interface{}{
 			"type":      "file",
 			"file-type": "stdout",
 			"format":    c.FileConfig.GetString("format"),
+			"multiline": indent != "",
+			"indent":    indent,
+		}
+		if c.FileConfig.GetBool("include-meta") {
+			stdoutConfig["include-meta"] = true
+		}
+		if query := c.FileConfig.GetString("query"); query != "" {
+			stdoutConfig["query"] = query
+			stdoutConfig["query-raw"] = c.FileConfig.GetBool("query-raw")
 		}
-		outDef["default-stdout"] = stdoutConfig
+		outDef[DefaultStdoutOutput] = stdoutConfig
 	}
 	for name, outputCfg := range outDef {
 		outputCfgconv := convert(outputCfg)
@@ -38,7 +75,13 @@ func (c *Config) GetOutputs() (map[string]map[string]interface{}, error) {
 				if _, ok := outputs.Outputs[outType.(string)]; ok {
 					format, ok := outCfg["format"]
 					if !ok || (ok && format == "") {
-						outCfg["format"] = c.FileConfig.GetString("format")
+						outCfg["format"] = c.outputDefaultFormat(name, outType.(string))
+					}
+					if _, ok := outCfg["num-as-string"]; !ok && c.FileConfig.GetBool("num-as-string") {
+						outCfg["num-as-string"] = true
+					}
+					if _, ok := outCfg["sync"]; !ok && outType == "file" && c.FileConfig.GetBool("output-file-sync") {
+						outCfg["sync"] = true
 					}
 					c.Outputs[name] = outCfg
 					continue
@@ -55,30 +98,224 @@ func (c *Config) GetOutputs() (map[string]map[string]interface{}, error) {
 		expandMapEnv(c.Outputs[n], "msg-template", "target-template")
 	}
 	namedOutputs := c.FileConfig.GetStringSlice("subscribe-output")
-	if len(namedOutputs) == 0 {
-		if c.Debug {
-			c.logger.Printf("outputs: %+v", c.Outputs)
-		}
-		return c.Outputs, nil
+	if tee && len(namedOutputs) > 0 && !strInlist(DefaultStdoutOutput, namedOutputs) {
+		namedOutputs = append(namedOutputs, DefaultStdoutOutput)
 	}
-	filteredOutputs := make(map[string]map[string]interface{})
+	// the outputs built from the --output values are added
+	// before the global event processors and queue settings are applied.
 	notFound := make([]string, 0)
 	for _, name := range namedOutputs {
-		if o, ok := c.Outputs[name]; ok {
-			filteredOutputs[name] = o
-		} else {
+		if _, ok := c.Outputs[name]; ok {
+			continue
+		}
+		o, err := c.outputFromFlags(name)
+		if err != nil {
+			return nil, err
+		}
+		if o == nil {
 			notFound = append(notFound, name)
+			continue
 		}
+		c.Outputs[name] = o
 	}
 	if len(notFound) > 0 {
 		return nil, fmt.Errorf("named output(s) not found in config file: %v", notFound)
 	}
+	globalProcessors := c.FileConfig.GetStringSlice("event-processors")
+	if c.FileConfig.GetBool("subscribe-calculate-rate") {
+		globalProcessors = append(globalProcessors, calculateRateProcessor)
+	}
+	if len(globalProcessors) > 0 {
+		for n := range c.Outputs {
+			c.Outputs[n]["event-processors"] = prependEventProcessors(globalProcessors, c.Outputs[n]["event-processors"])
+		}
+	}
+	if tee {
+		// each output writes from its own queue,
+		// a slow output does not delay the others.
+		for n := range c.Outputs {
+			if _, ok := c.Outputs[n]["queue-size"]; !ok {
+				c.Outputs[n]["queue-size"] = defaultTeeQueueSize
+			}
+		}
+	}
+	if len(namedOutputs) == 0 {
+		if c.Debug {
+			c.logger.Printf("outputs: %+v", c.Outputs)
+		}
+		return c.Outputs, nil
+	}
+	filteredOutputs := make(map[string]map[string]interface{})
+	for _, name := range namedOutputs {
+		filteredOutputs[name] = c.Outputs[name]
+	}
 	if c.Debug {
 		c.logger.Printf("outputs: %+v", filteredOutputs)
 	}
 	return filteredOutputs, nil
 }
 
+// outputFromFlags builds the config of an output referenced with --output
+// and not defined in the config file, from its URL or from the subscribe flags.
+// It returns nil if name is not such an output.
+func (c *Config) outputFromFlags(name string) (map[string]interface{}, error) {
+	if o := c.socketOutputFromURL(name); o != nil {
+		return o, nil
+	}
+	switch name {
+	case "syslog":
+		return c.syslogOutputFromFlags(), nil
+	case "elasticsearch":
+		return c.elasticsearchOutputFromFlags()
+	case "otlp":
+		return c.otlpOutputFromFlags()
+	case "ws":
+		return c.wsOutputFromFlags()
+	}
+	return nil, nil
+}
+
+// socketOutputFromURL builds a tcp or udp output config from
+// an --output value formatted as tcp://host:port or udp://host:port.
+// It returns nil if the value is not such a URL.
+func (c *Config) socketOutputFromURL(s string) map[string]interface{} {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	switch u.Scheme {
+	case "tcp", "udp":
+	default:
+		return nil
+	}
+	o := map[string]interface{}{
+		"type":      u.Scheme,
+		"address":   u.Host,
+		"format":    c.FileConfig.GetString("format"),
+		"delimiter": "\n",
+	}
+	if u.Scheme == "tcp" {
+		if ka := c.FileConfig.GetDuration("subscribe-output-keepalive"); ka > 0 {
+			o["keep-alive"] = ka
+		}
+	}
+	return o
+}
+
+// syslogOutputFromFlags builds a syslog output config from
+// the subscribe flags --syslog-address and --syslog-tag.
+// It is used when --output syslog does not reference an output
+// defined in the config file.
+func (c *Config) syslogOutputFromFlags() map[string]interface{} {
+	o := map[string]interface{}{
+		"type": "syslog",
+	}
+	if addr := c.FileConfig.GetString("subscribe-syslog-address"); addr != "" {
+		o["address"] = addr
+	}
+	if tag := c.FileConfig.GetString("subscribe-syslog-tag"); tag != "" {
+		o["tag"] = tag
+	}
+	return o
+}
+
+// elasticsearchOutputFromFlags builds an elasticsearch output config from
+// the subscribe flags --es-url and --es-index.
+// It is used when --output elasticsearch does not reference an output
+// defined in the config file.
+func (c *Config) elasticsearchOutputFromFlags() (map[string]interface{}, error) {
+	u := c.FileConfig.GetString("subscribe-es-url")
+	if u == "" {
+		return nil, errors.New("--output elasticsearch requires --es-url, or an output named elasticsearch in the config file")
+	}
+	o := map[string]interface{}{
+		"type": "elasticsearch",
+		"url":  u,
+	}
+	if index := c.FileConfig.GetString("subscribe-es-index"); index != "" {
+		o["index"] = index
+	}
+	return o, nil
+}
+
+// otlpOutputFromFlags builds an otlp output config from
+// the subscribe flags --otlp-endpoint and --otlp-insecure.
+// It is used when --output otlp does not reference an output
+// defined in the config file.
+func (c *Config) otlpOutputFromFlags() (map[string]interface{}, error) {
+	endpoint := c.FileConfig.GetString("subscribe-otlp-endpoint")
+	if endpoint == "" {
+		return nil, errors.New("--output otlp requires --otlp-endpoint, or an output named otlp in the config file")
+	}
+	o := map[string]interface{}{
+		"type":     "otlp",
+		"endpoint": endpoint,
+	}
+	if c.FileConfig.GetBool("subscribe-otlp-insecure") {
+		o["insecure"] = true
+	}
+	return o, nil
+}
+
+// wsOutputFromFlags builds a websocket output config from
+// the subscribe flag --ws-listen, formatted as [host]:port[/path].
+// The listener uses TLS if the global flags --tls-cert and --tls-key are set.
+// It is used when --output ws does not reference an output
+// defined in the config file.
+func (c *Config) wsOutputFromFlags() (map[string]interface{}, error) {
+	listen := c.FileConfig.GetString("subscribe-ws-listen")
+	if listen == "" {
+		return nil, errors.New("--output ws requires --ws-listen, or an output named ws in the config file")
+	}
+	o := map[string]interface{}{
+		"type":   "websocket",
+		"listen": listen,
+	}
+	if i := strings.Index(listen, "/"); i >= 0 {
+		o["listen"] = listen[:i]
+		o["path"] = listen[i:]
+	}
+	cert, key := c.FileConfig.GetString("tls-cert"), c.FileConfig.GetString("tls-key")
+	if cert != "" && key != "" {
+		o["tls"] = map[string]interface{}{
+			"cert-file": cert,
+			"key-file":  key,
+		}
+	}
+	return o, nil
+}
+
+// prependEventProcessors returns the list of event processors names
+// of an output, preceded by the globally configured ones it does not list.
+// The output list is kept in its configured order.
+func prependEventProcessors(global []string, outProcs interface{}) []interface{} {
+	own := make([]interface{}, 0)
+	listed := make(map[string]struct{})
+	switch outProcs := outProcs.(type) {
+	case []interface{}:
+		for _, p := range outProcs {
+			if ps, ok := p.(string); ok {
+				listed[ps] = struct{}{}
+			}
+			own = append(own, p)
+		}
+	case []string:
+		for _, p := range outProcs {
+			listed[p] = struct{}{}
+			own = append(own, p)
+		}
+	}
+	procs := make([]interface{}, 0, len(global)+len(own))
+	for _, p := range global {
+		if _, ok := listed[p]; ok {
+			continue
+		}
+		listed[p] = struct{}{}
+		procs = append(procs, p)
+	}
+	return append(procs, own...)
+}
+
 func convert(i interface{}) interface{} {
 	switch x := i.(type) {
 	case map[interface{}]interface{}:
@@ -68,6 +68,199 @@ outputs:
 			},
 		},
 	},
+	"socket_output_url": {
+		in: []byte(`
+subscribe-output:
+  - tcp://collector:9000
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+`),
+		out: map[string]map[string]interface{}{
+			"tcp://collector:9000": {
+				"type":      "tcp",
+				"address":   "collector:9000",
+				"format":    "",
+				"delimiter": "\n",
+			},
+		},
+	},
+	"socket_output_url_calculate_rate": {
+		in: []byte(`
+subscribe-output:
+  - udp://collector:9000
+subscribe-calculate-rate: true
+event-processors:
+  - proc1
+`),
+		out: map[string]map[string]interface{}{
+			"udp://collector:9000": {
+				"type":             "udp",
+				"address":          "collector:9000",
+				"format":           "",
+				"delimiter":        "\n",
+				"event-processors": []interface{}{"proc1", calculateRateProcessor},
+			},
+		},
+	},
+	"elasticsearch_from_flags": {
+		in: []byte(`
+subscribe-output:
+  - elasticsearch
+subscribe-es-url: http://es:9200
+subscribe-es-index: gnmi-%{+yyyy.MM.dd}
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+`),
+		out: map[string]map[string]interface{}{
+			"elasticsearch": {
+				"type":  "elasticsearch",
+				"url":   "http://es:9200",
+				"index": "gnmi-%{+yyyy.MM.dd}",
+			},
+		},
+	},
+	"otlp_from_flags": {
+		in: []byte(`
+subscribe-output:
+  - otlp
+subscribe-otlp-endpoint: otel-collector:4317
+subscribe-otlp-insecure: true
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+`),
+		out: map[string]map[string]interface{}{
+			"otlp": {
+				"type":     "otlp",
+				"endpoint": "otel-collector:4317",
+				"insecure": true,
+			},
+		},
+	},
+	"ws_from_flags": {
+		in: []byte(`
+subscribe-output:
+  - ws
+subscribe-ws-listen: :8080/stream
+tls-cert: server.crt
+tls-key: server.key
+`),
+		out: map[string]map[string]interface{}{
+			"ws": {
+				"type":   "websocket",
+				"listen": ":8080",
+				"path":   "/stream",
+				"tls": map[string]interface{}{
+					"cert-file": "server.crt",
+					"key-file":  "server.key",
+				},
+			},
+		},
+	},
+	"tee_outputs": {
+		in: []byte(`
+subscribe-tee: true
+subscribe-output:
+  - output2
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+  output2:
+    type: nats
+    queue-size: 10
+`),
+		out: map[string]map[string]interface{}{
+			"output2": {
+				"type":       "nats",
+				"format":     "",
+				"queue-size": 10,
+			},
+			"default-stdout": {
+				"type":       "file",
+				"file-type":  "stdout",
+				"format":     "",
+				"multiline":  false,
+				"indent":     "",
+				"queue-size": defaultTeeQueueSize,
+			},
+		},
+	},
+	"global_format": {
+		in: []byte(`
+format: proto
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+  output2:
+    type: nats
+  output3:
+    type: file
+    file-type: stderr
+    format: event
+`),
+		out: map[string]map[string]interface{}{
+			"output1": {
+				"type":      "file",
+				"file-type": "stdout",
+				"format":    "",
+			},
+			"output2": {
+				"type":   "nats",
+				"format": "proto",
+			},
+			"output3": {
+				"type":      "file",
+				"file-type": "stderr",
+				"format":    "event",
+			},
+		},
+	},
+	"global_event_processors": {
+		in: []byte(`
+event-processors:
+  - proc1
+  - proc2
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+  output2:
+    type: nats
+    event-processors:
+      - proc3
+      - proc1
+  output3:
+    type: nats
+    event-processors:
+      - proc2
+      - proc1
+`),
+		out: map[string]map[string]interface{}{
+			"output1": {
+				"type":             "file",
+				"file-type":        "stdout",
+				"format":           "",
+				"event-processors": []interface{}{"proc1", "proc2"},
+			},
+			"output2": {
+				"type":             "nats",
+				"format":           "",
+				"event-processors": []interface{}{"proc2", "proc3", "proc1"},
+			},
+			"output3": {
+				"type":             "nats",
+				"format":           "",
+				"event-processors": []interface{}{"proc2", "proc1"},
+			},
+		},
+	},
 }
 
 func TestGetOutputs(t *testing.T) {
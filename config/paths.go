@@ -0,0 +1,73 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmic/utils"
+)
+
+// ReadPathFile reads a list of xpaths from file, one per line.
+// Empty lines and lines starting with '#' are ignored.
+// Each path is validated, the returned error includes the file line number.
+func ReadPathFile(ctx context.Context, file string) ([]string, error) {
+	b, err := utils.ReadFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		_, err = utils.ParsePath(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid path %q: %v", file, lineNum, line, err)
+		}
+		paths = append(paths, line)
+	}
+	if err = sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read path file %q: %v", file, err)
+	}
+	return paths, nil
+}
+
+// MergePaths appends the paths read from file (if not empty) to paths,
+// removing the duplicates while preserving the order.
+func MergePaths(ctx context.Context, paths []string, file string) ([]string, error) {
+	var filePaths []string
+	if file != "" {
+		var err error
+		filePaths, err = ReadPathFile(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res := make([]string, 0, len(paths)+len(filePaths))
+	seen := make(map[string]struct{}, len(paths)+len(filePaths))
+	for _, ps := range [][]string{paths, filePaths} {
+		for _, p := range ps {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,77 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergePaths(t *testing.T) {
+	tests := map[string]struct {
+		paths   []string
+		file    string
+		want    []string
+		wantErr string
+	}{
+		"flags_only": {
+			paths: []string{"/system/name", "/interfaces"},
+			want:  []string{"/system/name", "/interfaces"},
+		},
+		"file_only": {
+			file: "# system\n/system/name\n\n  /interfaces/interface[name=e1]/state  \n",
+			want: []string{"/system/name", "/interfaces/interface[name=e1]/state"},
+		},
+		"merged_dedup": {
+			paths: []string{"/interfaces", "/system/name", "/interfaces"},
+			file:  "/system/name\n/network-instances\n/interfaces\n",
+			want:  []string{"/interfaces", "/system/name", "/network-instances"},
+		},
+		"invalid_path": {
+			paths:   []string{"/system/name"},
+			file:    "/system/name\n# comment\n/interfaces/interface[name=e1/state\n",
+			wantErr: ":3: invalid path \"/interfaces/interface[name=e1/state\"",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := ""
+			if item.file != "" {
+				file = filepath.Join(t.TempDir(), "paths.txt")
+				err := os.WriteFile(file, []byte(item.file), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := MergePaths(context.TODO(), item.paths, file)
+			if item.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), item.wantErr) {
+					t.Logf("failed at item %q", name)
+					t.Logf("expected an error containing: %q", item.wantErr)
+					t.Logf("                         got: %v", err)
+					t.Fail()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
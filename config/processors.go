@@ -14,8 +14,21 @@ import (
 	"github.com/openconfig/gnmic/formatters"
 )
 
+// calculateRateProcessor is the name of the event-rate processor
+// added to all outputs when the subscribe flag --calculate-rate is set.
+const calculateRateProcessor = "calculate-rate"
+
 func (c *Config) GetEventProcessors() (map[string]map[string]interface{}, error) {
 	eps := c.FileConfig.GetStringMap("processors")
+	if c.FileConfig.GetBool("subscribe-calculate-rate") {
+		if _, ok := eps[calculateRateProcessor]; !ok {
+			eps[calculateRateProcessor] = map[string]interface{}{
+				"event-rate": map[string]interface{}{
+					"value-names": []interface{}{".*"},
+				},
+			}
+		}
+	}
 	for name, epc := range eps {
 		switch epc := epc.(type) {
 		case map[string]interface{}:
@@ -0,0 +1,223 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/types"
+	"github.com/spf13/cobra"
+)
+
+// DevProfile is the name of the built-in development profile, selected with --dev.
+const DevProfile = "dev"
+
+// addresses probed in order by the dev profile when no target is configured.
+var devProfileAddresses = []string{"localhost:9339", "localhost:57400"}
+
+const devProfileProbeTimeout = 500 * time.Millisecond
+
+// built-in profiles, a profile with the same name in the config file
+// overrides their values key by key.
+var builtinProfiles = map[string]map[string]interface{}{
+	DevProfile: {
+		"insecure": true,
+		"encoding": "json_ietf",
+		"timeout":  "2s",
+		"retry":    "2s",
+	},
+}
+
+// a profile key is not applied if one of these flags is set,
+// e.g: --insecure is not compatible with the TLS flags.
+var profileKeyConflicts = map[string][]string{
+	"insecure": {"skip-verify", "tls-ca", "tls-cert", "tls-key", "tls-version", "tls-min-version", "tls-max-version"},
+}
+
+// ProfileName returns the name of the selected profile, if any.
+func (c *Config) ProfileName() string {
+	if c.Dev {
+		return DevProfile
+	}
+	return c.Profile
+}
+
+// GetProfile returns the flags bundle of profile name,
+// built from the built-in profile and the config file `profiles` section.
+func (c *Config) GetProfile(name string) (map[string]interface{}, error) {
+	bp, builtin := builtinProfiles[name]
+	fp, ok := c.Profiles[name]
+	if !builtin && !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	p := make(map[string]interface{}, len(bp)+len(fp))
+	for k, v := range bp {
+		p[k] = v
+	}
+	for k, v := range fp {
+		p[k] = v
+	}
+	return p, nil
+}
+
+// ApplyProfile sets the flags of the selected profile on the root command
+// and on the executed command cmd.
+// The flags set on the command line, in the config file or with environment variables
+// are not overridden.
+func (c *Config) ApplyProfile(cmd *cobra.Command) error {
+	name := c.ProfileName()
+	if name == "" {
+		return nil
+	}
+	if c.Dev && c.Profile != "" && c.Profile != DevProfile {
+		return fmt.Errorf("flag --dev cannot be combined with --profile %s", c.Profile)
+	}
+	p, err := c.GetProfile(name)
+	if err != nil {
+		return err
+	}
+	root := cmd.Root()
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "profile" || k == "dev" {
+			return fmt.Errorf("profile %q: key %q cannot be set in a profile", name, k)
+		}
+		fcmd, fName, ok := profileKeyFlag(root, cmd, k)
+		if !ok {
+			return fmt.Errorf("profile %q: unknown flag %q", name, k)
+		}
+		if fcmd == nil || c.flagIsSetByUser(fcmd, fName, k) {
+			// local flag of another command or set explicitly
+			continue
+		}
+		if c.profileKeyConflicts(root, k) {
+			continue
+		}
+		c.setFlagValue(fcmd, fName, p[k])
+	}
+	if name == DevProfile {
+		return c.setDevProfileAddress()
+	}
+	return nil
+}
+
+// profileKeyFlag finds the flag set by profile key k.
+// It returns the command owning the flag and the flag name,
+// the command is nil if k is a local flag of a command other than cmd.
+func profileKeyFlag(root, cmd *cobra.Command, k string) (*cobra.Command, string, bool) {
+	if root.PersistentFlags().Lookup(k) != nil {
+		return root, k, true
+	}
+	if fName := strings.TrimPrefix(k, cmd.Name()+"-"); fName != k && cmd.LocalFlags().Lookup(fName) != nil {
+		return cmd, fName, true
+	}
+	found := false
+	visitCommands(root, func(sc *cobra.Command) {
+		if fName := strings.TrimPrefix(k, sc.Name()+"-"); fName != k && sc.LocalFlags().Lookup(fName) != nil {
+			found = true
+		}
+	})
+	return nil, "", found
+}
+
+func visitCommands(cmd *cobra.Command, fn func(*cobra.Command)) {
+	for _, sc := range cmd.Commands() {
+		fn(sc)
+		visitCommands(sc, fn)
+	}
+}
+
+// flagIsSetByUser returns true if the flag fName of cmd is set on the command line,
+// or if its config key is set in the config file or as an environment variable.
+func (c *Config) flagIsSetByUser(cmd *cobra.Command, fName, key string) bool {
+	f := cmd.Flags().Lookup(fName)
+	if f != nil && f.Changed {
+		return true
+	}
+	return c.FileConfig.IsSet(key)
+}
+
+func (c *Config) profileKeyConflicts(root *cobra.Command, k string) bool {
+	for _, cf := range profileKeyConflicts[k] {
+		if c.flagIsSetByUser(root, cf, cf) {
+			return true
+		}
+	}
+	return false
+}
+
+// setDevProfileAddress checks that the dev profile is only used with loopback addresses.
+// If no target is configured, the first reachable address of devProfileAddresses is used.
+func (c *Config) setDevProfileAddress() error {
+	for _, addr := range c.Address {
+		if !isLoopbackAddress(addr) {
+			return fmt.Errorf("profile %q refuses non-loopback address %q", DevProfile, addr)
+		}
+	}
+	if len(c.Address) > 0 || c.AddressFile != "" || c.TargetsFile != "" || c.FileConfig.IsSet("targets") {
+		return nil
+	}
+	c.Address = []string{probeAddresses(devProfileAddresses, devProfileProbeTimeout)}
+	return nil
+}
+
+// checkProfileAddress returns an error if the dev profile is selected
+// and the target address is not a loopback address.
+func (c *Config) checkProfileAddress(tc *types.TargetConfig) error {
+	if c.ProfileName() != DevProfile {
+		return nil
+	}
+	for _, addr := range strings.Split(tc.Address, ",") {
+		if !isLoopbackAddress(strings.TrimSpace(addr)) {
+			return fmt.Errorf("profile %q refuses non-loopback address %q of target %q", DevProfile, addr, tc.Name)
+		}
+	}
+	return nil
+}
+
+// isLoopbackAddress returns true if addr is a unix socket, localhost
+// or a loopback IP address, with or without a port.
+// Names other than localhost are not resolved.
+func isLoopbackAddress(addr string) bool {
+	if strings.HasPrefix(addr, "unix://") {
+		return true
+	}
+	addr = strings.TrimPrefix(addr, types.DNSAddressPrefix)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// probeAddresses returns the first address of addrs accepting a TCP connection,
+// or the first address if none does.
+func probeAddresses(addrs []string, timeout time.Duration) string {
+	for _, addr := range addrs {
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err == nil {
+			conn.Close()
+			return addr
+		}
+	}
+	return addrs[0]
+}
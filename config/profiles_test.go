@@ -0,0 +1,166 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var isLoopbackAddressTestSet = map[string]bool{
+	"localhost":         true,
+	"localhost:9339":    true,
+	"127.0.0.1:57400":   true,
+	"127.1.2.3":         true,
+	"[::1]:9339":        true,
+	"::1":               true,
+	"unix:///tmp/gnmi":  true,
+	"dns:///localhost":  true,
+	"10.0.0.1:57400":    false,
+	"router1:57400":     false,
+	"[2001:db8::1]:830": false,
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for addr, exp := range isLoopbackAddressTestSet {
+		if got := isLoopbackAddress(addr); got != exp {
+			t.Errorf("address %q: expected %v, got %v", addr, exp, got)
+		}
+	}
+}
+
+func TestProbeAddresses(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// a closed port
+	cl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := cl.Addr().String()
+	cl.Close()
+
+	addr := probeAddresses([]string{closed, l.Addr().String()}, time.Second)
+	if addr != l.Addr().String() {
+		t.Errorf("expected %q, got %q", l.Addr().String(), addr)
+	}
+	addr = probeAddresses([]string{closed}, time.Second)
+	if addr != closed {
+		t.Errorf("expected the first address %q, got %q", closed, addr)
+	}
+}
+
+func newProfileTestCmd(c *Config, args ...string) (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "gnmic"}
+	root.PersistentFlags().StringSliceVarP(&c.Address, "address", "a", []string{}, "")
+	root.PersistentFlags().StringVarP(&c.Encoding, "encoding", "e", "json", "")
+	root.PersistentFlags().BoolVarP(&c.Insecure, "insecure", "", false, "")
+	root.PersistentFlags().StringVarP(&c.TLSCa, "tls-ca", "", "", "")
+	root.PersistentFlags().DurationVarP(&c.Timeout, "timeout", "", 10*time.Second, "")
+	root.PersistentFlags().DurationVarP(&c.Retry, "retry", "", 10*time.Second, "")
+	root.PersistentFlags().StringVarP(&c.Profile, "profile", "", "", "")
+	root.PersistentFlags().BoolVarP(&c.Dev, "dev", "", false, "")
+	get := &cobra.Command{Use: "get"}
+	get.Flags().StringVarP(&c.LocalFlags.GetType, "type", "t", "ALL", "")
+	sub := &cobra.Command{Use: "subscribe"}
+	sub.Flags().StringVarP(&c.LocalFlags.SubscribeMode, "mode", "", "stream", "")
+	root.AddCommand(get, sub)
+	root.ParseFlags(args)
+	get.ParseFlags(nil)
+	return root, get
+}
+
+func TestApplyProfile(t *testing.T) {
+	c := New()
+	c.Profiles = map[string]map[string]interface{}{
+		"lab": {
+			"encoding":       "proto",
+			"timeout":        "30s",
+			"get-type":       "STATE",
+			"subscribe-mode": "once",
+		},
+	}
+	c.FileConfig.Set("timeout", "20s")
+	_, get := newProfileTestCmd(c, "--profile", "lab", "--encoding", "json")
+	err := c.ApplyProfile(get)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Encoding != "json" {
+		t.Errorf("expected the command line encoding to be kept, got %q", c.Encoding)
+	}
+	if c.Timeout != 10*time.Second {
+		t.Errorf("expected the timeout set in the config file not to be overridden, got %s", c.Timeout)
+	}
+	if c.LocalFlags.GetType != "STATE" {
+		t.Errorf("expected get type STATE, got %q", c.LocalFlags.GetType)
+	}
+	if c.LocalFlags.SubscribeMode != "stream" {
+		t.Errorf("expected the subscribe mode to be left unchanged, got %q", c.LocalFlags.SubscribeMode)
+	}
+
+	c = New()
+	_, get = newProfileTestCmd(c, "--profile", "unknown")
+	if err = c.ApplyProfile(get); err == nil {
+		t.Errorf("expected an unknown profile error")
+	}
+
+	c = New()
+	c.Profiles = map[string]map[string]interface{}{"lab": {"not-a-flag": "x"}}
+	_, get = newProfileTestCmd(c, "--profile", "lab")
+	if err = c.ApplyProfile(get); err == nil {
+		t.Errorf("expected an unknown flag error")
+	}
+}
+
+func TestApplyDevProfile(t *testing.T) {
+	c := New()
+	_, get := newProfileTestCmd(c, "--dev", "-a", "localhost:57400")
+	err := c.ApplyProfile(get)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.Insecure || c.Encoding != "json_ietf" || c.Timeout != 2*time.Second {
+		t.Errorf("dev profile not applied: insecure=%v, encoding=%q, timeout=%s", c.Insecure, c.Encoding, c.Timeout)
+	}
+	if len(c.Address) != 1 || c.Address[0] != "localhost:57400" {
+		t.Errorf("expected the address to be kept, got %v", c.Address)
+	}
+
+	c = New()
+	_, get = newProfileTestCmd(c, "--dev", "--tls-ca", "ca.pem", "-a", "127.0.0.1")
+	err = c.ApplyProfile(get)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Insecure {
+		t.Errorf("expected --insecure not to be set with --tls-ca")
+	}
+
+	c = New()
+	_, get = newProfileTestCmd(c, "--dev", "-a", "10.0.0.1:57400")
+	if err = c.ApplyProfile(get); err == nil {
+		t.Errorf("expected a non-loopback address error")
+	}
+
+	c = New()
+	c.Dev = true
+	c.FileConfig.Set("targets", map[string]interface{}{
+		"router1": map[string]interface{}{"address": "10.0.0.1:57400"},
+	})
+	if _, err = c.GetTargets(); err == nil {
+		t.Errorf("expected a non-loopback target address error")
+	}
+}
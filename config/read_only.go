@@ -0,0 +1,21 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+// gNMI action RPCs modifying the targets configuration.
+var mutatingActionRPCs = []string{"set", "set-update", "set-replace", "set-delete", "delete"}
+
+// isMutatingAction returns true if the action config acfg is a gnmi action sending a Set RPC.
+func isMutatingAction(acfg map[string]interface{}) bool {
+	if aType, _ := acfg["type"].(string); aType != "gnmi" {
+		return false
+	}
+	rpc, _ := acfg["rpc"].(string)
+	return strInlist(rpc, mutatingActionRPCs)
+}
@@ -0,0 +1,97 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestGetActionsReadOnly(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		readOnly bool
+		wantErr  bool
+	}{
+		"set_action": {
+			in: `
+actions:
+  act1:
+    type: gnmi
+    rpc: set
+    paths: [/system/name]
+    values: [r1]
+`,
+			readOnly: true,
+			wantErr:  true,
+		},
+		"delete_action": {
+			in: `
+actions:
+  act1:
+    type: gnmi
+    rpc: delete
+    paths: [/system/name]
+`,
+			readOnly: true,
+			wantErr:  true,
+		},
+		"get_action": {
+			in: `
+actions:
+  act1:
+    type: gnmi
+    rpc: get
+    paths: [/system/name]
+`,
+			readOnly: true,
+		},
+		"http_action": {
+			in: `
+actions:
+  act1:
+    type: http
+    url: http://localhost:8080
+`,
+			readOnly: true,
+		},
+		"set_action_not_read_only": {
+			in: `
+actions:
+  act1:
+    type: gnmi
+    rpc: set-replace
+    paths: [/system/name]
+    values: [r1]
+`,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetLogger()
+			cfg.ReadOnly = item.readOnly
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(item.in))
+			if err != nil {
+				t.Fatalf("failed reading config: %v", err)
+			}
+			_, err = cfg.GetActions()
+			if item.wantErr != errors.Is(err, types.ErrReadOnly) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected read-only error: %v", item.wantErr)
+				t.Logf("                     got: %v", err)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/api"
+	"github.com/openconfig/gnmic/types"
+)
+
+const defaultRestoreEncoding = "json_ietf"
+
+// RestoreEncoding returns the encoding of the restore requests sent to target tc,
+// resolved like the Set RPCs encoding, json_ietf unless an encoding is explicitly set.
+// tc can be nil.
+func (c *Config) RestoreEncoding(tc *types.TargetConfig) string {
+	enc := defaultRestoreEncoding
+	if c.LocalFlags.SetEncoding != "" || (tc != nil && tc.Encoding != "") || c.FileConfig.IsSet("encoding") {
+		enc = c.RPCEncoding(RPCSet, tc)
+	}
+	return strings.ReplaceAll(strings.ToLower(enc), "-", "_")
+}
+
+// ReadRestoreFile reads the configuration snapshot set with --file.
+// Numbers are kept as json.Number to be restored as they were saved.
+func (c *Config) ReadRestoreFile() (map[string]interface{}, error) {
+	if c.LocalFlags.RestoreFile == "" {
+		return nil, errors.New("missing required flag --file")
+	}
+	b, err := os.ReadFile(c.LocalFlags.RestoreFile)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %q: %v", c.LocalFlags.RestoreFile, err)
+	}
+	return doc, nil
+}
+
+// CreateRestoreGetRequest returns the GetRequest used to retrieve
+// the current configuration of target tc before restoring a snapshot.
+func (c *Config) CreateRestoreGetRequest(tc *types.TargetConfig) (*gnmi.GetRequest, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w", ErrInvalidConfig)
+	}
+	return api.NewGetRequest(
+		api.Encoding(c.RestoreEncoding(tc)),
+		api.DataType("CONFIG"),
+		api.Path("/"),
+	)
+}
+
+// CreateRestoreSetRequest returns a SetRequest replacing the configuration of target tc with doc.
+// If granular is true, each top-level container is replaced with its own update,
+// otherwise a single replace of the root path is used.
+func (c *Config) CreateRestoreSetRequest(tc *types.TargetConfig, doc map[string]interface{}, granular bool) (*gnmi.SetRequest, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w", ErrInvalidConfig)
+	}
+	encoding := c.RestoreEncoding(tc)
+	req := new(gnmi.SetRequest)
+	if !granular {
+		val, err := restoreValue(doc, encoding)
+		if err != nil {
+			return nil, err
+		}
+		req.Replace = append(req.Replace, &gnmi.Update{Path: new(gnmi.Path), Val: val})
+		return req, nil
+	}
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val, err := restoreValue(doc[k], encoding)
+		if err != nil {
+			return nil, fmt.Errorf("container %q: %v", k, err)
+		}
+		// the container name is used as is, it might include a module prefix
+		req.Replace = append(req.Replace, &gnmi.Update{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: k}}},
+			Val:  val,
+		})
+	}
+	return req, nil
+}
+
+func restoreValue(v interface{}, encoding string) (*gnmi.TypedValue, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	switch encoding {
+	case "json":
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: b}}, nil
+	case "json_ietf":
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: b}}, nil
+	default:
+		return nil, fmt.Errorf("encoding %q not supported for restore, use json or json_ietf", encoding)
+	}
+}
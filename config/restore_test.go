@@ -0,0 +1,107 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const restoreSnapshot = `{
+  "interfaces": {"interface": [{"name": "e1", "mtu": 9000}]},
+  "openconfig-system:system": {"config": {"hostname": "r1"}}
+}`
+
+func TestCreateRestoreSetRequest(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "snapshot.json")
+	err := os.WriteFile(f, []byte(restoreSnapshot), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.LocalFlags.RestoreFile = f
+	doc, err := c.ReadRestoreFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := c.CreateRestoreSetRequest(nil, doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &gnmi.SetRequest{
+		Replace: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{},
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+					JsonIetfVal: []byte(`{"interfaces":{"interface":[{"mtu":9000,"name":"e1"}]},"openconfig-system:system":{"config":{"hostname":"r1"}}}`),
+				}},
+			},
+		},
+	}
+	if !proto.Equal(req, expected) {
+		t.Errorf("unexpected root replace request:\n%v", req)
+	}
+
+	c.Encoding = "JSON"
+	c.FileConfig.Set("encoding", "JSON")
+	req, err = c.CreateRestoreSetRequest(nil, doc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = &gnmi.SetRequest{
+		Replace: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}}},
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+					JsonVal: []byte(`{"interface":[{"mtu":9000,"name":"e1"}]}`),
+				}},
+			},
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "openconfig-system:system"}}},
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+					JsonVal: []byte(`{"config":{"hostname":"r1"}}`),
+				}},
+			},
+		},
+	}
+	if !proto.Equal(req, expected) {
+		t.Errorf("unexpected granular replace request:\n%v", req)
+	}
+
+	// the set-encoding and target encodings apply as for the Set RPCs
+	c.LocalFlags.SetEncoding = "json_ietf"
+	req, err = c.CreateRestoreSetRequest(&types.TargetConfig{Name: "t1", Encoding: "json"}, doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.GetReplace()[0].GetVal().GetJsonIetfVal() == nil {
+		t.Errorf("expected a json_ietf value with set-encoding json_ietf, got: %v", req)
+	}
+	c.LocalFlags.SetEncoding = ""
+	req, err = c.CreateRestoreSetRequest(&types.TargetConfig{Name: "t1", Encoding: "json_ietf"}, doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.GetReplace()[0].GetVal().GetJsonIetfVal() == nil {
+		t.Errorf("expected a json_ietf value with the target encoding json_ietf, got: %v", req)
+	}
+
+	c.Encoding = "proto"
+	c.FileConfig.Set("encoding", "proto")
+	_, err = c.CreateRestoreSetRequest(nil, doc, false)
+	if err == nil {
+		t.Errorf("expected an error with encoding proto")
+	}
+}
@@ -0,0 +1,166 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// run script steps RPCs
+const (
+	RunRPCGet       = "get"
+	RunRPCSet       = "set"
+	RunRPCSubscribe = "subscribe"
+	RunRPCSleep     = "sleep"
+)
+
+// RunScript is a sequence of gNMI operations executed by the run command.
+type RunScript struct {
+	// initial variables, available to the steps of all the targets
+	Vars  map[string]interface{} `yaml:"vars,omitempty"`
+	Steps []*RunStep             `yaml:"steps,omitempty"`
+}
+
+// RunStep is a single operation of a run script.
+type RunStep struct {
+	Name string `yaml:"name,omitempty"`
+	// one of get, set, subscribe (ONCE mode) or sleep
+	RPC string `yaml:"rpc,omitempty"`
+	// targets names, all the targets if empty
+	Targets  []string `yaml:"targets,omitempty"`
+	Prefix   string   `yaml:"prefix,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
+	Type     string   `yaml:"type,omitempty"`
+	Encoding string   `yaml:"encoding,omitempty"`
+	// set operations
+	Updates  []*RunSetValue `yaml:"updates,omitempty"`
+	Replaces []*RunSetValue `yaml:"replaces,omitempty"`
+	Deletes  []string       `yaml:"deletes,omitempty"`
+	// checks evaluated against the get or subscribe response values
+	Expect []*CheckConfig `yaml:"expect,omitempty"`
+	// variables set from the get or subscribe response values, name to path
+	Capture map[string]string `yaml:"capture,omitempty"`
+	// time to wait after the step
+	Sleep           string `yaml:"sleep,omitempty"`
+	ContinueOnError bool   `yaml:"continue-on-error,omitempty"`
+
+	SleepDuration time.Duration `yaml:"-"`
+}
+
+// RunSetValue is an update or replace of a set step,
+// a string value is a template executed with the target variables.
+type RunSetValue struct {
+	Path     string      `yaml:"path,omitempty"`
+	Value    interface{} `yaml:"value,omitempty"`
+	Encoding string      `yaml:"encoding,omitempty"`
+}
+
+// ReadRunScript reads and validates the run script file.
+func ReadRunScript(file string) (*RunScript, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	rs := new(RunScript)
+	err = yaml.Unmarshal(b, rs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode run script %q: %v", file, err)
+	}
+	if len(rs.Steps) == 0 {
+		return nil, fmt.Errorf("run script %q: no steps found", file)
+	}
+	for k, v := range rs.Vars {
+		rs.Vars[k] = utils.Convert(v)
+	}
+	for i, st := range rs.Steps {
+		err = validateRunStep(st)
+		if err != nil {
+			return nil, fmt.Errorf("run script %q: step %d: %v", file, i+1, err)
+		}
+		if st.Name == "" {
+			st.Name = fmt.Sprintf("step%d", i+1)
+		}
+	}
+	return rs, nil
+}
+
+// HasSet returns true if the script has at least one set step.
+func (rs *RunScript) HasSet() bool {
+	for _, st := range rs.Steps {
+		if st.RPC == RunRPCSet {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRunStep(st *RunStep) error {
+	st.RPC = strings.ToLower(strings.TrimSpace(st.RPC))
+	if st.Sleep != "" {
+		d, err := time.ParseDuration(st.Sleep)
+		if err != nil {
+			return fmt.Errorf("invalid sleep %q: %v", st.Sleep, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("invalid sleep %q: must not be negative", st.Sleep)
+		}
+		st.SleepDuration = d
+	}
+	switch st.RPC {
+	case RunRPCGet, RunRPCSubscribe:
+		if len(st.Paths) == 0 {
+			return fmt.Errorf("rpc %q requires at least one path", st.RPC)
+		}
+	case RunRPCSet:
+		if len(st.Updates)+len(st.Replaces)+len(st.Deletes) == 0 {
+			return errors.New("rpc \"set\" requires at least one update, replace or delete")
+		}
+		for _, sv := range append(st.Updates[:len(st.Updates):len(st.Updates)], st.Replaces...) {
+			if strings.TrimSpace(sv.Path) == "" {
+				return errors.New("set value with an empty path")
+			}
+			sv.Value = utils.Convert(sv.Value)
+		}
+		if len(st.Expect) > 0 || len(st.Capture) > 0 {
+			return errors.New("rpc \"set\" does not support expect and capture")
+		}
+	case RunRPCSleep:
+		if st.SleepDuration == 0 {
+			return errors.New("rpc \"sleep\" requires a sleep duration")
+		}
+	case "":
+		return errors.New("missing rpc")
+	default:
+		return fmt.Errorf("unknown rpc %q, must be one of %s, %s, %s or %s",
+			st.RPC, RunRPCGet, RunRPCSet, RunRPCSubscribe, RunRPCSleep)
+	}
+	for i, ch := range st.Expect {
+		if ch.Path == "" {
+			// the expectation applies to all the step values
+			ch.Path = "/"
+		}
+		err := validateCheck(ch)
+		if err != nil {
+			return fmt.Errorf("expect %d: %v", i+1, err)
+		}
+	}
+	for name, p := range st.Capture {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("capture %q: missing path", name)
+		}
+	}
+	return nil
+}
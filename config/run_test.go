@@ -0,0 +1,188 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var readRunScriptTestSet = map[string]struct {
+	in     string
+	names  []string
+	hasSet bool
+	err    bool
+}{
+	"steps": {
+		in: `
+vars:
+  desc: test
+steps:
+  - name: read
+    rpc: GET
+    paths:
+      - /system/name
+    capture:
+      hostname: /system/name/host-name
+  - rpc: set
+    updates:
+      - path: /system/description
+        value: "{{ .Vars.hostname }}"
+    sleep: 1s
+  - rpc: sleep
+    sleep: 2s
+  - rpc: subscribe
+    paths:
+      - /system/description
+    expect:
+      - operator: equals
+        value: r1
+`,
+		names:  []string{"read", "step2", "step3", "step4"},
+		hasSet: true,
+	},
+	"no_steps": {
+		in:  "steps: []",
+		err: true,
+	},
+	"missing_rpc": {
+		in: `
+steps:
+  - paths:
+      - /system/name
+`,
+		err: true,
+	},
+	"unknown_rpc": {
+		in: `
+steps:
+  - rpc: capabilities
+`,
+		err: true,
+	},
+	"get_without_paths": {
+		in: `
+steps:
+  - rpc: get
+`,
+		err: true,
+	},
+	"empty_set": {
+		in: `
+steps:
+  - rpc: set
+`,
+		err: true,
+	},
+	"set_with_expect": {
+		in: `
+steps:
+  - rpc: set
+    deletes:
+      - /system/description
+    expect:
+      - operator: exists
+`,
+		err: true,
+	},
+	"sleep_without_duration": {
+		in: `
+steps:
+  - rpc: sleep
+`,
+		err: true,
+	},
+	"invalid_sleep": {
+		in: `
+steps:
+  - rpc: get
+    paths:
+      - /system/name
+    sleep: soon
+`,
+		err: true,
+	},
+	"invalid_expect": {
+		in: `
+steps:
+  - rpc: get
+    paths:
+      - /system/name
+    expect:
+      - operator: contains
+        value: r1
+`,
+		err: true,
+	},
+}
+
+func TestReadRunScript(t *testing.T) {
+	for name, item := range readRunScriptTestSet {
+		t.Run(name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "script.yaml")
+			err := os.WriteFile(f, []byte(item.in), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rs, err := ReadRunScript(f)
+			if item.err {
+				if err == nil {
+					t.Errorf("failed at item %q: expected an error", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			if len(rs.Steps) != len(item.names) {
+				t.Fatalf("failed at item %q: expected %d steps, got %d", name, len(item.names), len(rs.Steps))
+			}
+			for i, st := range rs.Steps {
+				if st.Name != item.names[i] {
+					t.Errorf("failed at item %q: step %d: expected name %q, got %q", name, i, item.names[i], st.Name)
+				}
+			}
+			if rs.HasSet() != item.hasSet {
+				t.Errorf("failed at item %q: expected HasSet %v", name, item.hasSet)
+			}
+		})
+	}
+}
+
+func TestReadRunScriptDefaults(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "script.yaml")
+	err := os.WriteFile(f, []byte(`
+steps:
+  - rpc: Get
+    paths:
+      - /system/name
+    sleep: 500ms
+    expect:
+      - operator: exists
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := ReadRunScript(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := rs.Steps[0]
+	if st.RPC != RunRPCGet {
+		t.Errorf("expected rpc %q, got %q", RunRPCGet, st.RPC)
+	}
+	if st.SleepDuration != 500*time.Millisecond {
+		t.Errorf("expected sleep 500ms, got %s", st.SleepDuration)
+	}
+	if st.Expect[0].Path != "/" {
+		t.Errorf("expected default expect path \"/\", got %q", st.Expect[0].Path)
+	}
+}
@@ -11,6 +11,7 @@ package config
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,13 +27,15 @@ import (
 )
 
 const (
-	varFileSuffix = "_vars"
+	varFileSuffix     = "_vars"
+	base64ValuePrefix = "base64:"
 )
 
 type UpdateItem struct {
-	Path     string      `json:"path,omitempty" yaml:"path,omitempty"`
-	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
-	Encoding string      `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Path           string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Value          interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Encoding       string      `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	SkipValidation bool        `json:"skip-validation,omitempty" yaml:"skip-validation,omitempty"`
 }
 
 type SetRequestFile struct {
@@ -101,19 +104,39 @@ func (c *Config) readTemplateVarsFile() error {
 }
 
 func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest, error) {
+	reqs, _, err := c.createSetRequestFromFile(targetName)
+	return reqs, err
+}
+
+// CreateSetRequestForValidation creates the set requests of target targetName
+// and returns the updates and replaces marked with skip-validation in the request files.
+func (c *Config) CreateSetRequestForValidation(targetName string) ([]*gnmi.SetRequest, map[*gnmi.Update]bool, error) {
+	if len(c.SetRequestFile) > 0 {
+		return c.createSetRequestFromFile(targetName)
+	}
+	reqs, err := c.CreateSetRequest(targetName)
+	return reqs, nil, err
+}
+
+func (c *Config) createSetRequestFromFile(targetName string) ([]*gnmi.SetRequest, map[*gnmi.Update]bool, error) {
 	if len(c.setRequestTemplate) == 0 {
-		return nil, errors.New("missing set request template")
+		return nil, nil, errors.New("missing set request template")
 	}
 	reqs := make([]*gnmi.SetRequest, 0, len(c.setRequestTemplate))
+	skipped := make(map[*gnmi.Update]bool)
 	buf := new(bytes.Buffer)
 	for _, srf := range c.setRequestTemplate {
 		buf.Reset()
-		err := srf.Execute(buf, templateInput{
+		in := templateInput{
 			TargetName: targetName,
 			Vars:       c.setRequestVars,
-		})
+		}
+		if tc, ok := c.Targets[targetName]; ok {
+			in.Address = tc.Address
+		}
+		err := srf.Execute(buf, in)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if c.Debug {
 			c.logger.Printf("target %q template result:\n%s", targetName, buf.String())
@@ -122,7 +145,7 @@ func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest
 		reqFile := new(SetRequestFile)
 		err = yaml.Unmarshal(buf.Bytes(), reqFile)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		gnmiOpts := make([]api.GNMIOption, 0)
 		buf.Reset()
@@ -133,12 +156,12 @@ func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest
 
 			enc := upd.Encoding
 			if enc == "" {
-				enc = c.GlobalFlags.Encoding
+				enc = c.RPCEncoding(RPCSet, c.Targets[targetName])
 			}
 			buf.Reset()
 			err = json.NewEncoder(buf).Encode(convert(upd.Value))
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			gnmiOpts = append(gnmiOpts,
 				api.Update(
@@ -153,12 +176,12 @@ func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest
 			}
 			enc := upd.Encoding
 			if enc == "" {
-				enc = c.GlobalFlags.Encoding
+				enc = c.RPCEncoding(RPCSet, c.Targets[targetName])
 			}
 			buf.Reset()
 			err = json.NewEncoder(buf).Encode(convert(upd.Value))
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			gnmiOpts = append(gnmiOpts, api.Replace(
 				api.Path(strings.TrimSpace(upd.Path)),
@@ -172,14 +195,96 @@ func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest
 
 		setReq, err := api.NewSetRequest(gnmiOpts...)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		// the request updates and replaces are in the request file order
+		for i, upd := range reqFile.Updates {
+			if upd.SkipValidation && i < len(setReq.Update) {
+				skipped[setReq.Update[i]] = true
+			}
+		}
+		for i, upd := range reqFile.Replaces {
+			if upd.SkipValidation && i < len(setReq.Replace) {
+				skipped[setReq.Replace[i]] = true
+			}
 		}
 		reqs = append(reqs, setReq)
 	}
-	return reqs, nil
+	return reqs, skipped, nil
 }
 
 type templateInput struct {
 	TargetName string
+	Address    string
 	Vars       map[string]interface{}
 }
+
+// readSetFile reads an --update-file or --replace-file and executes its content as a template,
+// using the name, address and vars of target targetName as input.
+// Unlike the request files, a missing variable is an error.
+// YAML files are converted to JSON once rendered.
+func (c *Config) readSetFile(fileName string, targetName string) ([]byte, error) {
+	b, err := utils.ReadFile(context.TODO(), fileName)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := utils.CreateTemplate(filepath.Base(fileName), string(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %q: %v", fileName, err)
+	}
+	in := templateInput{TargetName: targetName}
+	if tc, ok := c.Targets[targetName]; ok {
+		in.Address = tc.Address
+		in.Vars = tc.Vars
+	}
+	buf := new(bytes.Buffer)
+	err = tpl.Option("missingkey=error").Execute(buf, in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render file %q: %v", fileName, err)
+	}
+	switch filepath.Ext(fileName) {
+	case ".yaml", ".yml":
+		return toJSONBytes(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// inlineValue decodes an inline value of type bytes
+// if it is base64 encoded, e.g: base64:SGVsbG8=
+func inlineValue(value, typ string) (string, error) {
+	if typ != "bytes" || !strings.HasPrefix(value, base64ValuePrefix) {
+		return value, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, base64ValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 value: %v", err)
+	}
+	return string(b), nil
+}
+
+// updateBytesFiles builds the updates set with --update-bytes-file,
+// formatted as path:::file, the file content is sent as a bytes value.
+func (c *Config) updateBytesFiles() ([]api.GNMIOption, error) {
+	opts := make([]api.GNMIOption, 0, len(c.LocalFlags.SetBytesFile))
+	for _, u := range c.LocalFlags.SetBytesFile {
+		p, fileName, ok := strings.Cut(u, c.LocalFlags.SetDelimiter)
+		p = strings.TrimSpace(p)
+		fileName = strings.TrimSpace(fileName)
+		if !ok || p == "" || fileName == "" {
+			return nil, fmt.Errorf("invalid update bytes file format %q, expected path%sfile", u, c.LocalFlags.SetDelimiter)
+		}
+		fi, err := os.Stat(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if c.MaxMsgSize > 0 && fi.Size() > int64(c.MaxMsgSize) {
+			return nil, fmt.Errorf("file %q size (%d bytes) exceeds the max-msg-size (%d bytes)", fileName, fi.Size(), c.MaxMsgSize)
+		}
+		b, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, api.Update(api.Path(p), api.Value(string(b), "bytes")))
+	}
+	return opts, nil
+}
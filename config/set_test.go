@@ -9,12 +9,15 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"text/template"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/testutils"
+	"github.com/openconfig/gnmic/types"
 )
 
 var createSetRequestFromFileTestSet = map[string]struct {
@@ -26,12 +29,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 
 	"set_update_request_from_file": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"updates": [
 					{
@@ -40,7 +42,6 @@ var createSetRequestFromFileTestSet = map[string]struct {
 					}
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -63,12 +64,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_replace_request_from_file": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"replaces": [
 					{
@@ -77,7 +77,6 @@ var createSetRequestFromFileTestSet = map[string]struct {
 					}
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -100,18 +99,16 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_delete_request_from_file": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"deletes": [
 					"valid/path"
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -127,12 +124,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_multiple_update_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"updates": [
 					{
@@ -146,7 +142,6 @@ var createSetRequestFromFileTestSet = map[string]struct {
 					}
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -182,12 +177,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_multiple_replace_request_from_file": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"replaces": [
 					{
@@ -201,7 +195,6 @@ var createSetRequestFromFileTestSet = map[string]struct {
 					}
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -237,19 +230,17 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_multiple_delete_request_from_file": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`{
 				"deletes": [
 					"valid/path1",
 					"valid/path2"
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -271,12 +262,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"set_combined_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{template.Must(template.New("set-request").Parse(`{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{template.Must(template.New("set-request").Parse(`{
 				"updates": [
 					{
 						"path": "/valid/path1",
@@ -293,7 +283,6 @@ var createSetRequestFromFileTestSet = map[string]struct {
 					"valid/path"
 				]
 			}`))},
-			nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -339,12 +328,11 @@ var createSetRequestFromFileTestSet = map[string]struct {
 	},
 	"template_based_set_request": {
 		in: &Config{
-			GlobalFlags{
+			GlobalFlags: GlobalFlags{
 				Encoding: "json",
 			},
-			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			[]*template.Template{
+			LocalFlags: LocalFlags{},
+			setRequestTemplate: []*template.Template{
 				template.Must(template.New("set-request").Parse(`replaces:
 {{- range $interface := index .Vars .TargetName "interfaces" }}
   - path: "/interface[name={{ index $interface "name" }}]"
@@ -360,7 +348,7 @@ var createSetRequestFromFileTestSet = map[string]struct {
               - ip-prefix: {{ index $subinterface "ipv4-address"}}
 {{- end }}
 {{- end }}`))},
-			map[string]interface{}{
+			setRequestVars: map[string]interface{}{
 				"target1": map[string]interface{}{
 					"interfaces": []interface{}{
 						map[string]interface{}{
@@ -422,3 +410,118 @@ func TestCreateSetRequestFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSetRequestCli(t *testing.T) {
+	cfg := New()
+	cfg.Encoding = "json"
+	cfg.LocalFlags.SetDelimiter = ":::"
+	cfg.LocalFlags.SetCliOrigin = "nokia-cli"
+	cfg.LocalFlags.SetUpdate = []string{"/system/name:::string:::router1"}
+	cfg.LocalFlags.SetUpdateCli = []string{"interface ethernet-1/1 admin-state enable"}
+	cfg.LocalFlags.SetReplacePath = []string{"cli:"}
+	cfg.LocalFlags.SetReplaceValue = []string{"system name router1"}
+
+	reqs, err := cfg.CreateSetRequest("")
+	if err != nil {
+		t.Fatalf("failed to create set request: %v", err)
+	}
+	exp := &gnmi.SetRequest{
+		Replace: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{Origin: "nokia-cli"},
+				Val: &gnmi.TypedValue{
+					Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "system name router1"},
+				},
+			},
+		},
+		Update: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{
+					Elem: []*gnmi.PathElem{
+						{Name: "system"},
+						{Name: "name"},
+					},
+				},
+				Val: &gnmi.TypedValue{
+					Value: &gnmi.TypedValue_StringVal{StringVal: "router1"},
+				},
+			},
+			{
+				Path: &gnmi.Path{Origin: "nokia-cli"},
+				Val: &gnmi.TypedValue{
+					Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "interface ethernet-1/1 admin-state enable"},
+				},
+			},
+		},
+	}
+	if len(reqs) != 1 || !testutils.SetRequestsEqual(reqs[0], exp) {
+		t.Errorf("unexpected set request")
+		t.Logf("exp value: %+v", exp)
+		t.Logf("got value: %+v", reqs)
+	}
+}
+
+func TestCreateSetRequestForValidation(t *testing.T) {
+	cfg := New()
+	cfg.Encoding = "json_ietf"
+	cfg.LocalFlags.SetRequestFile = []string{"request.yaml"}
+	cfg.setRequestTemplate = []*template.Template{
+		template.Must(template.New("set-request").Parse(`
+updates:
+  - path: /system/name
+    value: router1
+  - path: /system/vendor-extension
+    value: {}
+    skip-validation: true
+replaces:
+  - path: /interfaces
+    value: {}
+    skip-validation: true
+`)),
+	}
+	reqs, skipped, err := cfg.CreateSetRequestForValidation("t1")
+	if err != nil {
+		t.Fatalf("failed to create set request: %v", err)
+	}
+	if len(reqs) != 1 || len(reqs[0].Update) != 2 || len(reqs[0].Replace) != 1 {
+		t.Fatalf("unexpected set requests: %+v", reqs)
+	}
+	if skipped[reqs[0].Update[0]] {
+		t.Errorf("update 0 unexpectedly skipped")
+	}
+	if !skipped[reqs[0].Update[1]] {
+		t.Errorf("update 1 not skipped")
+	}
+	if !skipped[reqs[0].Replace[0]] {
+		t.Errorf("replace 0 not skipped")
+	}
+}
+
+func TestCreateSetRequestUpdateFileTemplate(t *testing.T) {
+	dir := t.TempDir()
+	updFile := filepath.Join(dir, "update.yaml")
+	err := os.WriteFile(updFile, []byte("description: {{ .Vars.description }} on {{ .TargetName }}\nmtu: 9000\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := New()
+	cfg.Encoding = "json"
+	cfg.LocalFlags.SetUpdatePath = []string{"/interfaces/interface[name=e1]/config"}
+	cfg.LocalFlags.SetUpdateFile = []string{updFile}
+	cfg.Targets = map[string]*types.TargetConfig{
+		"r1": {Name: "r1", Address: "10.0.0.1:57400", Vars: map[string]interface{}{"description": "uplink"}},
+		"r2": {Name: "r2", Address: "10.0.0.2:57400"},
+	}
+	reqs, err := cfg.CreateSetRequest("r1")
+	if err != nil {
+		t.Fatalf("failed to create set request: %v", err)
+	}
+	want := `{"description":"uplink on r1","mtu":9000}`
+	if len(reqs) != 1 || len(reqs[0].Update) != 1 || string(reqs[0].Update[0].GetVal().GetJsonVal()) != want {
+		t.Errorf("unexpected set request, expected an update with value %s, got: %+v", want, reqs)
+	}
+	_, err = cfg.CreateSetRequest("r2")
+	if err == nil || !strings.Contains(err.Error(), "failed to render file") {
+		t.Errorf("expected a rendering error for a target without the variable, got: %v", err)
+	}
+}
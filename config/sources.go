@@ -0,0 +1,115 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sources of a configuration value, by precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// ValueSource is the effective value of a configuration key and where it comes from.
+type ValueSource struct {
+	Key    string      `json:"key,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+	Source string      `json:"source,omitempty"`
+	// flag or environment variable name
+	Name string `json:"name,omitempty"`
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+func (vs *ValueSource) String() string {
+	switch vs.Source {
+	case SourceFlag:
+		return "flag --" + vs.Name
+	case SourceEnv:
+		return "env " + vs.Name
+	case SourceFile:
+		if vs.Line > 0 {
+			return "file " + vs.File + ":" + strconv.Itoa(vs.Line)
+		}
+		return "file " + vs.File
+	}
+	return vs.Source
+}
+
+// fileOrigin is the configuration file setting a key, with the key line if known.
+type fileOrigin struct {
+	file string
+	line int
+}
+
+// KeySource returns the source of the value of key, other than a command line flag:
+// an environment variable, the last configuration file setting it or the default value.
+func (c *Config) KeySource(key string) *ValueSource {
+	vs := &ValueSource{
+		Key:    key,
+		Value:  c.FileConfig.Get(key),
+		Source: SourceDefault,
+	}
+	envName := KeyEnvName(key)
+	if _, ok := os.LookupEnv(envName); ok {
+		vs.Source = SourceEnv
+		vs.Name = envName
+		return vs
+	}
+	if fo, ok := c.fileOrigins[key]; ok {
+		vs.Source = SourceFile
+		vs.File = fo.file
+		vs.Line = fo.line
+	}
+	return vs
+}
+
+// KeyEnvName returns the name of the environment variable setting key.
+func KeyEnvName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.NewReplacer("/", "_", "-", "_").Replace(key))
+}
+
+// keyLines returns the line of each key of a YAML or JSON configuration file,
+// the nested keys are joined with a "/". It returns nil for the other file formats.
+func keyLines(file string, b []byte) map[string]int {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil
+	}
+	var doc yaml.Node
+	err := yaml.Unmarshal(b, &doc)
+	if err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	lines := make(map[string]int)
+	walkKeyLines(doc.Content[0], "", lines)
+	return lines
+}
+
+func walkKeyLines(n *yaml.Node, prefix string, lines map[string]int) {
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		// viper keys are case insensitive
+		k := prefix + strings.ToLower(n.Content[i].Value)
+		lines[k] = n.Content[i].Line
+		walkKeyLines(n.Content[i+1], k+"/", lines)
+	}
+}
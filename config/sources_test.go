@@ -0,0 +1,87 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeySource(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		filepath.Join(dir, "shared.yaml"),
+		filepath.Join(dir, "site.yaml"),
+	}
+	for i, content := range []string{sharedConfigFile, siteConfigFile} {
+		err := os.WriteFile(files[i], []byte(content), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the tls-ca file existence is checked when loading the config
+	caFile := filepath.Join(dir, "ca.pem")
+	err := os.WriteFile(caFile, nil, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GNMIC_TLS_CA", caFile)
+	c := New()
+	c.GlobalFlags.CfgFile = files
+	err = c.Load(context.Background())
+	if err != nil {
+		t.Fatalf("failed to load config files: %v", err)
+	}
+	tests := map[string]struct {
+		key  string
+		want string
+	}{
+		"first_file": {
+			key:  "username",
+			want: "file " + files[0] + ":2",
+		},
+		"overridden": {
+			key:  "timeout",
+			want: "file " + files[1] + ":2",
+		},
+		"same_value": {
+			key:  "skip-verify",
+			want: "file " + files[1] + ":3",
+		},
+		"nested": {
+			key:  "targets/router1/address",
+			want: "file " + files[0] + ":8",
+		},
+		"nested_merged": {
+			key:  "targets/router1/password",
+			want: "file " + files[1] + ":6",
+		},
+		"env": {
+			key:  "tls-ca",
+			want: "env GNMIC_TLS_CA",
+		},
+		"default": {
+			key:  "debug",
+			want: "default",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := c.KeySource(item.key).String()
+			if got != item.want {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", item.want)
+				t.Logf("     got: %s", got)
+				t.Fail()
+			}
+		})
+	}
+}
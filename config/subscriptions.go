@@ -14,12 +14,12 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/api"
 	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -27,22 +27,24 @@ const (
 	subscriptionDefaultMode       = "STREAM"
 	subscriptionDefaultStreamMode = "TARGET_DEFINED"
 	subscriptionDefaultEncoding   = "JSON"
+	// name of the subscription built from the cli flags
+	subscriptionDefaultName = "sub-1"
 )
 
 func (c *Config) GetSubscriptions(cmd *cobra.Command) (map[string]*types.SubscriptionConfig, error) {
 	if len(c.LocalFlags.SubscribePath) > 0 && len(c.LocalFlags.SubscribeName) > 0 {
-		return nil, fmt.Errorf("flags --path and --name cannot be mixed")
+		return nil, fmt.Errorf("flags --path or --path-file and --name cannot be mixed")
 	}
 	// subscriptions from cli flags
 	if len(c.LocalFlags.SubscribePath) > 0 {
 		sub := new(types.SubscriptionConfig)
-		sub.Name = fmt.Sprintf("default-%d", time.Now().Unix())
+		sub.Name = subscriptionDefaultName
 		sub.Paths = c.LocalFlags.SubscribePath
 		sub.Prefix = c.LocalFlags.SubscribePrefix
 		sub.Target = c.LocalFlags.SubscribeTarget
 		sub.SetTarget = c.LocalFlags.SubscribeSetTarget
 		sub.Mode = c.LocalFlags.SubscribeMode
-		sub.Encoding = c.Encoding
+		sub.Encoding = c.RPCEncoding(RPCSubscribe, nil)
 		if flagIsSet(cmd, "qos") {
 			sub.Qos = &c.LocalFlags.SubscribeQos
 		}
@@ -80,6 +82,10 @@ func (c *Config) GetSubscriptions(cmd *cobra.Command) (map[string]*types.Subscri
 	}
 	for sn, s := range subDef {
 		sub := new(types.SubscriptionConfig)
+		def, pathConfigs, err := decodePathConfigs(s)
+		if err != nil {
+			return nil, fmt.Errorf("subscription %q: %v", sn, err)
+		}
 		decoder, err := mapstructure.NewDecoder(
 			&mapstructure.DecoderConfig{
 				DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
@@ -88,15 +94,20 @@ func (c *Config) GetSubscriptions(cmd *cobra.Command) (map[string]*types.Subscri
 		if err != nil {
 			return nil, err
 		}
-		err = decoder.Decode(s)
+		err = decoder.Decode(def)
 		if err != nil {
 			return nil, err
 		}
 		sub.Name = sn
+		sub.PathConfigs = pathConfigs
 
 		// inherit global "subscribe-*" option if it's not set
 		c.setSubscriptionDefaults(sub, cmd)
 		expandSubscriptionEnv(sub)
+		err = validatePathConfigs(sub)
+		if err != nil {
+			return nil, fmt.Errorf("subscription %q: %v", sn, err)
+		}
 		c.Subscriptions[sn] = sub
 	}
 	if len(c.LocalFlags.SubscribeName) == 0 {
@@ -139,7 +150,7 @@ func (c *Config) setSubscriptionDefaults(sub *types.SubscriptionConfig, cmd *cob
 		sub.HeartbeatInterval = &c.LocalFlags.SubscribeHeartbearInterval
 	}
 	if sub.Encoding == "" {
-		sub.Encoding = c.Encoding
+		sub.Encoding = c.RPCEncoding(RPCSubscribe, nil)
 	}
 	if sub.Mode == "" {
 		sub.Mode = c.LocalFlags.SubscribeMode
@@ -180,15 +191,23 @@ func (c *Config) GetSubscriptionsFromFile() []*types.SubscriptionConfig {
 	return subscriptions
 }
 
-func (*Config) CreateSubscribeRequest(sc *types.SubscriptionConfig, target string) (*gnmi.SubscribeRequest, error) {
+// CreateSubscribeRequest creates the subscribe request of subscription sc sent to target,
+// its encoding is resolved with subscriptionEncoding.
+// target can be empty.
+func (c *Config) CreateSubscribeRequest(sc *types.SubscriptionConfig, target string) (*gnmi.SubscribeRequest, error) {
 	err := setDefaults(sc)
 	if err != nil {
 		return nil, err
 	}
+	err = validateOrigins(sc)
+	if err != nil {
+		return nil, err
+	}
+	encoding := c.subscriptionEncoding(sc, c.Targets[target])
 	gnmiOpts := make([]api.GNMIOption, 0)
 	gnmiOpts = append(gnmiOpts,
 		api.Prefix(sc.Prefix),
-		api.Encoding(sc.Encoding),
+		api.Encoding(encoding),
 		api.SubscriptionListMode(sc.Mode),
 		api.UpdatesOnly(sc.UpdatesOnly),
 	)
@@ -211,26 +230,27 @@ func (*Config) CreateSubscribeRequest(sc *types.SubscriptionConfig, target strin
 		gnmiOpts = append(gnmiOpts, api.Target(target))
 	}
 	// add gNMI subscriptions
-	for _, p := range sc.Paths {
+	for i, p := range sc.Paths {
 		subGnmiOpts := make([]api.GNMIOption, 0, 2)
 		switch gnmi.SubscriptionList_Mode(gnmi.SubscriptionList_Mode_value[strings.ToUpper(sc.Mode)]) {
 		case gnmi.SubscriptionList_STREAM:
-			subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode))
-			switch gnmi.SubscriptionMode(gnmi.SubscriptionMode_value[strings.Replace(strings.ToUpper(sc.StreamMode), "-", "_", -1)]) {
+			streamMode, sampleInterval, heartbeatInterval, suppressRedundant := sc.PathStreamOptions(i)
+			subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(streamMode))
+			switch streamModeValue(streamMode) {
 			case gnmi.SubscriptionMode_ON_CHANGE:
-				if sc.HeartbeatInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
+				if heartbeatInterval != nil {
+					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*heartbeatInterval))
 				}
 			case gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_TARGET_DEFINED:
-				if sc.SampleInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.SampleInterval(*sc.SampleInterval))
+				if sampleInterval != nil {
+					subGnmiOpts = append(subGnmiOpts, api.SampleInterval(*sampleInterval))
 				}
-				subGnmiOpts = append(subGnmiOpts, api.SuppressRedundant(sc.SuppressRedundant))
-				if sc.SuppressRedundant && sc.HeartbeatInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
+				subGnmiOpts = append(subGnmiOpts, api.SuppressRedundant(suppressRedundant))
+				if suppressRedundant && heartbeatInterval != nil {
+					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*heartbeatInterval))
 				}
 			default:
-				return nil, fmt.Errorf("unknown stream subscription mode %s", sc.StreamMode)
+				return nil, fmt.Errorf("unknown stream subscription mode %s", streamMode)
 			}
 		default:
 			// poll and once subscription modes
@@ -247,6 +267,155 @@ func (*Config) CreateSubscribeRequest(sc *types.SubscriptionConfig, target strin
 	return api.NewSubscribeRequest(gnmiOpts...)
 }
 
+// validateOrigins checks that the origins of the subscription paths are not combined with a prefix origin.
+// Without a prefix origin, each path carries its own origin and the paths of
+// a subscription list can have different origins.
+func validateOrigins(sc *types.SubscriptionConfig) error {
+	if sc.Prefix == "" {
+		return nil
+	}
+	prefix, err := utils.ParsePath(sc.Prefix)
+	if err != nil || prefix.GetOrigin() == "" {
+		// an invalid prefix is reported when the request is built
+		return nil
+	}
+	for _, p := range sc.Paths {
+		gp, err := utils.ParsePath(p)
+		if err != nil {
+			continue
+		}
+		if gp.GetOrigin() != "" {
+			return fmt.Errorf("subscription %q: the origin of path %q cannot be combined with the prefix origin %q, set the origin in the paths only",
+				sc.Name, p, prefix.GetOrigin())
+		}
+	}
+	return nil
+}
+
+// PathsOrigins returns the sorted distinct origins of the paths of subscription sc,
+// a path without origin counts as the empty origin.
+func PathsOrigins(sc *types.SubscriptionConfig) []string {
+	set := make(map[string]struct{})
+	for _, p := range sc.Paths {
+		gp, err := utils.ParsePath(p)
+		if err != nil {
+			continue
+		}
+		set[gp.GetOrigin()] = struct{}{}
+	}
+	origins := make([]string, 0, len(set))
+	for o := range set {
+		origins = append(origins, o)
+	}
+	sort.Strings(origins)
+	return origins
+}
+
+// decodePathConfigs decodes the paths entries of the subscription definition s
+// defined as an object with a path and its stream options.
+// It returns a copy of s where the object entries are replaced by their path,
+// to be decoded as a SubscriptionConfig, and the path configs aligned with the paths,
+// nil for the xpath entries. The path configs are nil if all the entries are xpaths.
+func decodePathConfigs(s interface{}) (interface{}, []*types.PathConfig, error) {
+	sm, ok := s.(map[string]interface{})
+	if !ok {
+		return s, nil, nil
+	}
+	entries, ok := sm["paths"].([]interface{})
+	if !ok {
+		return s, nil, nil
+	}
+	var pathConfigs []*types.PathConfig
+	paths := make([]interface{}, len(entries))
+	for i, e := range entries {
+		// yaml decodes the object entries with interface{} keys
+		if m, ok := e.(map[interface{}]interface{}); ok {
+			e = convert(m)
+		}
+		em, ok := e.(map[string]interface{})
+		if !ok {
+			paths[i] = e
+			continue
+		}
+		pc := new(types.PathConfig)
+		decoder, err := mapstructure.NewDecoder(
+			&mapstructure.DecoderConfig{
+				DecodeHook:  mapstructure.StringToTimeDurationHookFunc(),
+				ErrorUnused: true,
+				Result:      pc,
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+		err = decoder.Decode(em)
+		if err != nil {
+			return nil, nil, fmt.Errorf("paths[%d]: %v", i, err)
+		}
+		if pc.Path == "" {
+			return nil, nil, fmt.Errorf("paths[%d]: missing path", i)
+		}
+		if pathConfigs == nil {
+			pathConfigs = make([]*types.PathConfig, len(entries))
+		}
+		pathConfigs[i] = pc
+		paths[i] = pc.Path
+	}
+	if pathConfigs == nil {
+		return s, nil, nil
+	}
+	// copy the definition, the config file settings are not modified
+	nsm := make(map[string]interface{}, len(sm))
+	for k, v := range sm {
+		nsm[k] = v
+	}
+	nsm["paths"] = paths
+	return nsm, pathConfigs, nil
+}
+
+// validatePathConfigs validates the stream options of the subscription sc paths
+// defined as an object: a heartbeat interval is only sent with the on-change mode,
+// or with suppress-redundant in the sample and target-defined modes.
+func validatePathConfigs(sc *types.SubscriptionConfig) error {
+	for i, pc := range sc.PathConfigs {
+		if pc == nil {
+			continue
+		}
+		if sc.Mode != "" && !strings.EqualFold(sc.Mode, "stream") {
+			return fmt.Errorf("paths[%d]: stream options require the subscription mode stream, got %q", i, sc.Mode)
+		}
+		mode, _, _, suppressRedundant := sc.PathStreamOptions(i)
+		if mode == "" {
+			mode = subscriptionDefaultStreamMode
+		}
+		switch streamModeValue(mode) {
+		case gnmi.SubscriptionMode_ON_CHANGE:
+			if pc.SampleInterval != nil {
+				return fmt.Errorf("paths[%d]: sample-interval is not supported with mode %q", i, mode)
+			}
+			if pc.SuppressRedundant != nil && *pc.SuppressRedundant {
+				return fmt.Errorf("paths[%d]: suppress-redundant is not supported with mode %q", i, mode)
+			}
+		case gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_TARGET_DEFINED:
+			if pc.HeartbeatInterval != nil && !suppressRedundant {
+				return fmt.Errorf("paths[%d]: heartbeat-interval requires mode on-change or suppress-redundant, got mode %q", i, mode)
+			}
+		default:
+			return fmt.Errorf("paths[%d]: unknown mode %q", i, mode)
+		}
+	}
+	return nil
+}
+
+// streamModeValue returns the gNMI subscription mode named mode, e.g: on-change.
+// It returns -1 if mode is unknown.
+func streamModeValue(mode string) gnmi.SubscriptionMode {
+	v, ok := gnmi.SubscriptionMode_value[strings.ReplaceAll(strings.ToUpper(mode), "-", "_")]
+	if !ok {
+		return -1
+	}
+	return gnmi.SubscriptionMode(v)
+}
+
 func setDefaults(sc *types.SubscriptionConfig) error {
 	if len(sc.Paths) == 0 {
 		return fmt.Errorf("missing path(s) in subscription '%s'", sc.Name)
@@ -257,6 +426,19 @@ func setDefaults(sc *types.SubscriptionConfig) error {
 	if strings.ToUpper(sc.Mode) == "STREAM" && sc.StreamMode == "" {
 		sc.StreamMode = subscriptionDefaultStreamMode
 	}
+	return nil
+}
+
+// ResolveSubscription sets the defaults of subscription sc: its mode, stream mode and encoding,
+// the encoding is resolved from the subscribe-encoding key and the global encoding.
+func (c *Config) ResolveSubscription(sc *types.SubscriptionConfig) error {
+	err := setDefaults(sc)
+	if err != nil {
+		return err
+	}
+	if sc.Encoding == "" {
+		sc.Encoding = c.RPCEncoding(RPCSubscribe, nil)
+	}
 	if sc.Encoding == "" {
 		sc.Encoding = subscriptionDefaultEncoding
 	}
@@ -293,6 +475,11 @@ func expandSubscriptionEnv(sc *types.SubscriptionConfig) {
 	for i := range sc.Paths {
 		sc.Paths[i] = os.ExpandEnv(sc.Paths[i])
 	}
+	for _, pc := range sc.PathConfigs {
+		if pc != nil {
+			pc.Path = os.ExpandEnv(pc.Path)
+		}
+	}
 	sc.Mode = os.ExpandEnv(sc.Mode)
 	sc.StreamMode = os.ExpandEnv(sc.StreamMode)
 	sc.Encoding = os.ExpandEnv(sc.Encoding)
@@ -16,12 +16,14 @@ import (
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"github.com/openconfig/gnmic/testutils"
 	"github.com/openconfig/gnmic/types"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
 )
 
 var getSubscriptionsTestSet = map[string]struct {
@@ -62,6 +64,33 @@ subscriptions:
 		},
 		outErr: nil,
 	},
+	"with_subscribe_encoding": {
+		in: []byte(`
+encoding: json
+subscribe-encoding: proto
+subscriptions:
+  sub1:
+    paths: 
+      - /valid/path
+  sub2:
+    paths: 
+      - /valid/path2
+    encoding: ascii
+`),
+		out: map[string]*types.SubscriptionConfig{
+			"sub1": {
+				Name:     "sub1",
+				Paths:    []string{"/valid/path"},
+				Encoding: "proto",
+			},
+			"sub2": {
+				Name:     "sub2",
+				Paths:    []string{"/valid/path2"},
+				Encoding: "ascii",
+			},
+		},
+		outErr: nil,
+	},
 	"2_subs": {
 		in: []byte(`
 subscriptions:
@@ -603,6 +632,65 @@ func TestConfig_CreateSubscribeRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "multi_origin_paths",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths: []string{
+						"native:/srl_nokia-interfaces/interface",
+						"openconfig:/interfaces",
+						"/system",
+					},
+					Mode:     "once",
+					Encoding: "json_ietf",
+				},
+			},
+			want: &gnmi.SubscribeRequest{
+				Request: &gnmi.SubscribeRequest_Subscribe{
+					Subscribe: &gnmi.SubscriptionList{
+						Subscription: []*gnmi.Subscription{
+							{
+								Path: &gnmi.Path{
+									Origin: "native",
+									Elem: []*gnmi.PathElem{
+										{Name: "srl_nokia-interfaces"},
+										{Name: "interface"},
+									},
+								},
+							},
+							{
+								Path: &gnmi.Path{
+									Origin: "openconfig",
+									Elem:   []*gnmi.PathElem{{Name: "interfaces"}},
+								},
+							},
+							{
+								Path: &gnmi.Path{
+									Elem: []*gnmi.PathElem{{Name: "system"}},
+								},
+							},
+						},
+						Mode:     gnmi.SubscriptionList_ONCE,
+						Encoding: gnmi.Encoding_JSON_IETF,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "prefix_origin_with_path_origin",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Prefix: "openconfig:/interfaces",
+					Paths: []string{
+						"native:/srl_nokia-interfaces/interface",
+					},
+					Mode:     "once",
+					Encoding: "json_ietf",
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -639,3 +727,199 @@ func TestConfig_CreateSubscribeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSubscriptionsPathConfigs(t *testing.T) {
+	in := []byte(`
+subscriptions:
+  sub1:
+    stream-mode: sample
+    sample-interval: 10s
+    paths:
+      - /interfaces/interface/state/counters
+      - path: /interfaces/interface/state/oper-status
+        mode: on-change
+        heartbeat-interval: 60s
+      - path: /system/state
+        suppress-redundant: true
+        heartbeat-interval: 5m
+`)
+	cfg := New()
+	cfg.FileConfig.SetConfigType("yaml")
+	err := cfg.FileConfig.ReadConfig(bytes.NewBuffer(in))
+	if err != nil {
+		t.Fatalf("failed reading config: %v", err)
+	}
+	subs, err := cfg.GetSubscriptions(nil)
+	if err != nil {
+		t.Fatalf("failed getting subscriptions: %v", err)
+	}
+	req, err := cfg.CreateSubscribeRequest(subs["sub1"], "")
+	if err != nil {
+		t.Fatalf("failed creating subscribe request: %v", err)
+	}
+	want := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Mode: gnmi.SubscriptionList_STREAM,
+				Subscription: []*gnmi.Subscription{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+							{Name: "interfaces"},
+							{Name: "interface"},
+							{Name: "state"},
+							{Name: "counters"},
+						}},
+						Mode:           gnmi.SubscriptionMode_SAMPLE,
+						SampleInterval: uint64(10 * time.Second),
+					},
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+							{Name: "interfaces"},
+							{Name: "interface"},
+							{Name: "state"},
+							{Name: "oper-status"},
+						}},
+						Mode:              gnmi.SubscriptionMode_ON_CHANGE,
+						HeartbeatInterval: uint64(time.Minute),
+					},
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+							{Name: "system"},
+							{Name: "state"},
+						}},
+						Mode:              gnmi.SubscriptionMode_SAMPLE,
+						SampleInterval:    uint64(10 * time.Second),
+						SuppressRedundant: true,
+						HeartbeatInterval: uint64(5 * time.Minute),
+					},
+				},
+			},
+		},
+	}
+	if !proto.Equal(req, want) {
+		t.Errorf("unexpected subscribe request:\ngot:  %v\nwant: %v", req, want)
+	}
+}
+
+func TestGetSubscriptionsPathConfigsErrors(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		outErr string
+	}{
+		"missing_path": {
+			in: `
+subscriptions:
+  sub1:
+    paths:
+      - /system
+      - mode: on-change
+`,
+			outErr: `subscription "sub1": paths[1]: missing path`,
+		},
+		"unknown_field": {
+			in: `
+subscriptions:
+  sub1:
+    paths:
+      - path: /system
+        sample: 10s
+`,
+			outErr: `subscription "sub1": paths[0]: 1 error(s) decoding:
+
+* '' has invalid keys: sample`,
+		},
+		"not_stream": {
+			in: `
+subscriptions:
+  sub1:
+    mode: once
+    paths:
+      - path: /system
+        mode: on-change
+`,
+			outErr: `subscription "sub1": paths[0]: stream options require the subscription mode stream, got "once"`,
+		},
+		"on_change_sample_interval": {
+			in: `
+subscriptions:
+  sub1:
+    paths:
+      - /system
+      - path: /interfaces
+        mode: on-change
+        sample-interval: 10s
+`,
+			outErr: `subscription "sub1": paths[1]: sample-interval is not supported with mode "on-change"`,
+		},
+		"heartbeat_without_suppress_redundant": {
+			in: `
+subscriptions:
+  sub1:
+    stream-mode: sample
+    paths:
+      - path: /interfaces
+        heartbeat-interval: 60s
+`,
+			outErr: `subscription "sub1": paths[0]: heartbeat-interval requires mode on-change or suppress-redundant, got mode "sample"`,
+		},
+		"unknown_mode": {
+			in: `
+subscriptions:
+  sub1:
+    paths:
+      - path: /interfaces
+        mode: on-update
+`,
+			outErr: `subscription "sub1": paths[0]: unknown mode "on-update"`,
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(strings.NewReader(item.in))
+			if err != nil {
+				t.Fatalf("failed reading config: %v", err)
+			}
+			_, err = cfg.GetSubscriptions(nil)
+			if err == nil {
+				t.Errorf("failed at item %q: expected error %q", name, item.outErr)
+				return
+			}
+			if err.Error() != item.outErr {
+				t.Errorf("failed at item %q: expected error %q, got %q", name, item.outErr, err)
+			}
+		})
+	}
+}
+
+func TestPathsOrigins(t *testing.T) {
+	tests := map[string]struct {
+		paths []string
+		want  []string
+	}{
+		"no_origin": {
+			paths: []string{"/a", "/b"},
+			want:  []string{""},
+		},
+		"single_origin": {
+			paths: []string{"openconfig:/a", "openconfig:/b"},
+			want:  []string{"openconfig"},
+		},
+		"mixed": {
+			paths: []string{"openconfig:/a", "native:/b", "/c"},
+			want:  []string{"", "native", "openconfig"},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := PathsOrigins(&types.SubscriptionConfig{Paths: item.paths})
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.want)
+				t.Logf("     got: %q", got)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -18,42 +18,214 @@ import (
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/openconfig/gnmic/types"
+	"gopkg.in/yaml.v2"
 )
 
 const (
 	defaultTargetBufferSize = 100
+	// event tag set to the comma separated groups of a target
+	groupsEventTag = "groups"
 )
 
 var ErrNoTargetsFound = errors.New("no targets found")
 
+// GetTargets returns the targets set with --group, --address or defined in the address file
+// or in the config file. The targets resolving to the same address are merged into one,
+// unless --allow-duplicate-targets is set: the config file targets take precedence
+// over the --address ones, then the first config target in name order
+// or the first --address value is kept.
 func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
-	var err error
+	if len(c.Group) > 0 {
+		return c.getGroupTargets()
+	}
 	// case address is defined in .Address
 	if len(c.Address) > 0 {
+		tcs := make([]*types.TargetConfig, 0, len(c.Address))
 		for _, addr := range c.Address {
-			tc := &types.TargetConfig{
-				Name:    addr,
-				Address: addr,
-			}
-			err = c.SetTargetConfigDefaults(tc)
+			tc, err := c.addressTargetConfig(addr)
 			if err != nil {
 				return nil, err
 			}
-			c.Targets[tc.Name] = tc
+			tcs = append(tcs, tc)
+		}
+		for name, tc := range c.dedupTargets(tcs) {
+			c.Targets[name] = tc
+		}
+		err := c.skipTargets()
+		if err != nil {
+			return nil, err
 		}
 		if c.Debug {
 			c.logger.Printf("targets: %v", c.Targets)
 		}
 		return c.Targets, nil
 	}
-	// case targets is defined in config file
-	targetsInt := c.FileConfig.Get("targets")
+	// case targets is defined in an address file or in config file
+	targets, err := c.readTargetsConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.Targets = c.dedupTargets(sortedTargets(targets))
+	c.setTargetsSubscriptions()
+	err = c.skipTargets()
+	if err != nil {
+		return nil, err
+	}
+	if c.Debug {
+		c.logger.Printf("targets: %v", c.Targets)
+	}
+	return c.Targets, nil
+}
+
+// getGroupTargets returns the configured targets member of at least one of the --group groups,
+// and the targets created from the --address flag values.
+func (c *Config) getGroupTargets() (map[string]*types.TargetConfig, error) {
+	configured, err := c.readTargetsConfig()
+	if err != nil && !errors.Is(err, ErrNoTargetsFound) {
+		return nil, err
+	}
+	defined := make(map[string]struct{})
+	for _, tc := range configured {
+		for _, g := range tc.Groups {
+			defined[g] = struct{}{}
+		}
+	}
+	for _, g := range c.Group {
+		if _, ok := defined[g]; !ok {
+			groups := make([]string, 0, len(defined))
+			for dg := range defined {
+				groups = append(groups, dg)
+			}
+			sort.Strings(groups)
+			return nil, fmt.Errorf("unknown target group %q, defined groups: %q", g, groups)
+		}
+	}
+	c.Targets = make(map[string]*types.TargetConfig)
+	for name, tc := range configured {
+		if tc.InGroup(c.Group...) {
+			c.Targets[name] = tc
+		}
+	}
+	c.setTargetsSubscriptions()
+	// the addresses are added to the group targets,
+	// which take precedence if they are duplicates
+	tcs := sortedTargets(c.Targets)
+	for _, addr := range c.Address {
+		if _, ok := c.Targets[addr]; ok {
+			continue
+		}
+		tc, err := c.addressTargetConfig(addr)
+		if err != nil {
+			return nil, err
+		}
+		tcs = append(tcs, tc)
+	}
+	c.Targets = c.dedupTargets(tcs)
+	err = c.skipTargets()
+	if err != nil {
+		return nil, err
+	}
+	if c.Debug {
+		c.logger.Printf("groups %q targets: %v", c.Group, c.Targets)
+	}
+	return c.Targets, nil
+}
+
+// skipTargets removes the targets disabled with `enabled: false` or named with --skip-target,
+// they are recorded in SkippedTargets with the reason they were skipped.
+// ErrNoTargetsFound is returned if all the targets are skipped.
+func (c *Config) skipTargets() error {
+	c.SkippedTargets = make(map[string]string)
+	skip := make(map[string]bool, len(c.SkipTarget))
+	for _, name := range c.SkipTarget {
+		skip[name] = false
+	}
+	for name, tc := range c.Targets {
+		var reason string
+		switch {
+		case !tc.IsEnabled():
+			reason = "disabled in the config"
+		case hasKey(skip, name), hasKey(skip, tc.Name):
+			reason = "skipped with --skip-target"
+		default:
+			continue
+		}
+		skip[name], skip[tc.Name] = true, true
+		delete(c.Targets, name)
+		c.SkippedTargets[name] = reason
+		c.logger.Printf("target %q skipped: %s", name, reason)
+	}
+	for _, name := range c.SkipTarget {
+		if !skip[name] {
+			c.logger.Printf("--skip-target %q: unknown target", name)
+		}
+	}
+	if len(c.Targets) == 0 && len(c.SkippedTargets) > 0 {
+		return ErrNoTargetsFound
+	}
+	return nil
+}
+
+func hasKey(m map[string]bool, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
+// addressTargetConfig returns the configuration of a target
+// set with the --address flag, with the global defaults.
+func (c *Config) addressTargetConfig(addr string) (*types.TargetConfig, error) {
+	tc := &types.TargetConfig{
+		Name:    addr,
+		Address: addr,
+	}
+	err := c.SetTargetConfigDefaults(tc)
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkProfileAddress(tc)
+	if err != nil {
+		return nil, err
+	}
+	err = c.decryptTargetSecrets(tc)
+	if err != nil {
+		return nil, err
+	}
+	err = c.setKeyringPassword(tc)
+	if err != nil {
+		return nil, err
+	}
+	if c.Debug {
+		c.logger.Printf("target %q: connection security mode: %s", tc.Name, tc.SecurityMode())
+	}
+	return tc, nil
+}
+
+// readTargetsConfig reads the targets defined in the address file or in the config file.
+func (c *Config) readTargetsConfig() (map[string]*types.TargetConfig, error) {
+	var err error
+	var targetsInt interface{}
+	if c.AddressFile != "" {
+		targetsInt, err = readAddressFile(c.AddressFile)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		targetsInt = c.FileConfig.Get("targets")
+	}
 	targetsMap := make(map[string]interface{})
 	switch targetsInt := targetsInt.(type) {
 	case string:
 		for _, addr := range strings.Split(targetsInt, " ") {
 			targetsMap[addr] = nil
 		}
+	case []interface{}:
+		for _, addr := range targetsInt {
+			addr, ok := addr.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected target address format, got a %T", addr)
+			}
+			targetsMap[addr] = nil
+		}
 	case map[string]interface{}:
 		targetsMap = targetsInt
 	case nil:
@@ -93,12 +265,21 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 		if tc.Name == "" {
 			tc.Name = name
 		}
+		err = c.validateTargetSecurity(tc)
+		if err != nil {
+			return nil, err
+		}
 		err = c.SetTargetConfigDefaults(tc)
 		if err != nil {
 			return nil, err
 		}
+		err = c.checkProfileAddress(tc)
+		if err != nil {
+			return nil, err
+		}
 		if c.Debug {
 			c.logger.Printf("read target config: %s", tc)
+			c.logger.Printf("target %q: connection security mode: %s", tc.Name, tc.SecurityMode())
 		}
 		err = expandCertPaths(tc)
 		if err != nil {
@@ -108,29 +289,49 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 		// as part of a StringMap or interface{}:
 		// read the target password as a string to maintain its case.
 		// if it's not an empty string set it explicitly
-		pass := c.FileConfig.GetString(fmt.Sprintf("targets/%s/password", name))
-		if pass != "" {
-			*tc.Password = pass
+		if c.AddressFile == "" {
+			pass := c.FileConfig.GetString(fmt.Sprintf("targets/%s/password", name))
+			if pass != "" {
+				*tc.Password = pass
+			}
 		}
 		expandTargetEnv(tc)
+		err = c.decryptTargetSecrets(tc)
+		if err != nil {
+			return nil, err
+		}
+		err = c.setKeyringPassword(tc)
+		if err != nil {
+			return nil, err
+		}
 		newTargetsConfig[name] = tc
 	}
-	c.Targets = newTargetsConfig
+	return newTargetsConfig, nil
+}
 
+// setTargetsSubscriptions sets the --name subscriptions on all the targets.
+func (c *Config) setTargetsSubscriptions() {
 	subNames := c.FileConfig.GetStringSlice("subscribe-name")
-	if len(subNames) == 0 {
-		if c.Debug {
-			c.logger.Printf("targets: %v", c.Targets)
+	if len(subNames) > 0 {
+		for n := range c.Targets {
+			c.Targets[n].Subscriptions = subNames
 		}
-		return c.Targets, nil
 	}
-	for n := range c.Targets {
-		c.Targets[n].Subscriptions = subNames
+}
+
+// readAddressFile reads a YAML file containing either a list of target addresses,
+// or a map of target names to target configurations, like the config file targets section.
+func readAddressFile(name string) (interface{}, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
 	}
-	if c.Debug {
-		c.logger.Printf("targets: %v", c.Targets)
+	var targets interface{}
+	err = yaml.Unmarshal(b, &targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse address file %q: %v", name, err)
 	}
-	return c.Targets, nil
+	return convert(targets), nil
 }
 
 func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
@@ -140,6 +341,12 @@ func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
 		addrs := make([]string, 0, len(addrList))
 		for _, addr := range addrList {
 			addr = strings.TrimSpace(addr)
+			// the port is added to the resolved name
+			var scheme string
+			if strings.HasPrefix(addr, types.DNSAddressPrefix) {
+				scheme = types.DNSAddressPrefix
+				addr = strings.TrimPrefix(addr, scheme)
+			}
 			if !c.UseTunnelServer {
 				_, _, err := net.SplitHostPort(addr)
 				if err != nil {
@@ -152,7 +359,7 @@ func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
 					}
 				}
 			}
-			addrs = append(addrs, addr)
+			addrs = append(addrs, scheme+addr)
 		}
 		tc.Address = strings.Join(addrs, ",")
 	}
@@ -199,18 +406,113 @@ func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
 	if tc.TLSMaxVersion == "" {
 		tc.TLSMaxVersion = c.TLSMaxVersion
 	}
+	if len(tc.TLSPin) == 0 {
+		tc.TLSPin = c.TLSPin
+	}
 	if tc.LogTLSSecret == nil {
 		tc.LogTLSSecret = &c.LogTLSSecret
 	}
+	if c.TLSIgnoreCertErrors {
+		tc.TLSIgnoreCertErrors = true
+	}
 	if tc.Gzip == nil {
 		tc.Gzip = &c.Gzip
 	}
+	if tc.ClientID == "" {
+		tc.ClientID = c.ClientID
+	}
+	if tc.AuthScheme == "" {
+		tc.AuthScheme = c.AuthScheme
+	}
+	if tc.AuthScheme != "" && !strInlist(tc.AuthScheme, types.AuthSchemes) {
+		return fmt.Errorf("target %q: unknown auth-scheme %q, expected one of %q", tc.Name, tc.AuthScheme, types.AuthSchemes)
+	}
+	if tc.AuthUsernameKey == "" {
+		tc.AuthUsernameKey = c.AuthUsernameKey
+	}
+	if tc.AuthPasswordKey == "" {
+		tc.AuthPasswordKey = c.AuthPasswordKey
+	}
+	if tc.SplitMixedSubscriptions == nil {
+		tc.SplitMixedSubscriptions = &c.SplitMixedSubscriptions
+	}
+	if len(tc.EncodingFallback) == 0 {
+		tc.EncodingFallback = c.EncodingFallback
+	}
 	if tc.BufferSize == 0 {
 		tc.BufferSize = defaultTargetBufferSize
 	}
+	if len(tc.Groups) > 0 {
+		if tc.EventTags == nil {
+			tc.EventTags = make(map[string]string, 1)
+		}
+		// target event-tags take precedence
+		if _, ok := tc.EventTags[groupsEventTag]; !ok {
+			tc.EventTags[groupsEventTag] = strings.Join(tc.Groups, ",")
+		}
+	}
+	if len(c.EventTag) > 0 {
+		evTags, err := c.globalEventTags()
+		if err != nil {
+			return err
+		}
+		if tc.EventTags == nil {
+			tc.EventTags = make(map[string]string, len(evTags))
+		}
+		for k, v := range evTags {
+			// target event-tags take precedence
+			if _, ok := tc.EventTags[k]; !ok {
+				tc.EventTags[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// validateTargetSecurity returns an error if target tc, as read from the targets config,
+// connects in plaintext while setting TLS options, unless --force is set.
+func (c *Config) validateTargetSecurity(tc *types.TargetConfig) error {
+	insecure := c.Insecure
+	if tc.Insecure != nil {
+		insecure = *tc.Insecure
+	}
+	if !insecure || c.Force {
+		return nil
+	}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{name: "skip-verify", set: tc.SkipVerify != nil && *tc.SkipVerify},
+		{name: "tls-ca", set: tc.TLSCA != nil && *tc.TLSCA != ""},
+		{name: "tls-cert", set: tc.TLSCert != nil && *tc.TLSCert != ""},
+		{name: "tls-key", set: tc.TLSKey != nil && *tc.TLSKey != ""},
+		{name: "tls-version", set: tc.TLSVersion != ""},
+		{name: "tls-max-version", set: tc.TLSMaxVersion != ""},
+		{name: "tls-min-version", set: tc.TLSMinVersion != ""},
+		{name: "tls-pin", set: len(tc.TLSPin) > 0},
+	} {
+		if f.set {
+			return fmt.Errorf("target %q: insecure and %s are mutually exclusive, set --force to connect in plaintext anyway", tc.Name, f.name)
+		}
+	}
 	return nil
 }
 
+// globalEventTags parses the --event-tag flag values into a map.
+func (c *Config) globalEventTags() (map[string]string, error) {
+	evTags := make(map[string]string, len(c.EventTag))
+	for _, et := range c.EventTag {
+		k, v, ok := strings.Cut(et, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("malformed event tag %q, expected format key=value", et)
+		}
+		evTags[k] = strings.TrimSpace(v)
+	}
+	return evTags, nil
+}
+
 func (c *Config) TargetsList() []*types.TargetConfig {
 	targets := make([]*types.TargetConfig, 0, len(c.Targets))
 	for _, tc := range c.Targets {
@@ -0,0 +1,297 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/types"
+)
+
+// targetsResolveTimeout bounds the resolution of the targets host names
+// when looking for duplicates.
+const targetsResolveTimeout = 5 * time.Second
+
+// lookupHost resolves the targets host names when looking for duplicates.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// dedupTargets returns the targets by name without the duplicates:
+// a target with the same normalized address and the same connection settings
+// as a previous one is merged into it, so targets is expected in precedence order.
+func (c *Config) dedupTargets(targets []*types.TargetConfig) map[string]*types.TargetConfig {
+	result := make(map[string]*types.TargetConfig, len(targets))
+	if c.AllowDuplicateTargets || len(targets) < 2 {
+		for _, tc := range targets {
+			result[tc.Name] = tc
+		}
+		return result
+	}
+	resolved := c.resolveTargetsHosts(targets)
+	byAddress := make(map[string][]*types.TargetConfig, len(targets))
+	for _, tc := range targets {
+		key := c.targetAddressKey(tc, resolved)
+		if kept := c.sameConnectionTarget(byAddress[key], tc, key); kept != nil {
+			c.mergeDuplicateTarget(kept, tc, key)
+			continue
+		}
+		byAddress[key] = append(byAddress[key], tc)
+		result[tc.Name] = tc
+	}
+	return result
+}
+
+// sortedTargets returns the targets sorted by name.
+func sortedTargets(targets map[string]*types.TargetConfig) []*types.TargetConfig {
+	tcs := make([]*types.TargetConfig, 0, len(targets))
+	for _, tc := range targets {
+		tcs = append(tcs, tc)
+	}
+	sort.Slice(tcs, func(i, j int) bool {
+		return tcs[i].Name < tcs[j].Name
+	})
+	return tcs
+}
+
+// MergeDuplicateTarget merges target tc, e.g: discovered by a loader, into the configured
+// target with the same normalized address and connection settings, if any, and reports whether it did.
+// It does nothing if --allow-duplicate-targets is set or a target named tc.Name exists.
+func (c *Config) MergeDuplicateTarget(tc *types.TargetConfig) bool {
+	if c.AllowDuplicateTargets || len(c.Targets) == 0 {
+		return false
+	}
+	if _, ok := c.Targets[tc.Name]; ok {
+		return false
+	}
+	kept := sortedTargets(c.Targets)
+	resolved := c.resolveTargetsHosts(append(kept, tc))
+	key := c.targetAddressKey(tc, resolved)
+	sameAddress := make([]*types.TargetConfig, 0, 1)
+	for _, k := range kept {
+		if c.targetAddressKey(k, resolved) == key {
+			sameAddress = append(sameAddress, k)
+		}
+	}
+	if k := c.sameConnectionTarget(sameAddress, tc, key); k != nil {
+		c.mergeDuplicateTarget(k, tc, key)
+		return true
+	}
+	return false
+}
+
+// sameConnectionTarget returns the first of the targets with the same address as tc
+// that also has the same connection settings and subscriptions.
+// It logs a warning if targets has the same address but none matches.
+func (c *Config) sameConnectionTarget(targets []*types.TargetConfig, tc *types.TargetConfig, address string) *types.TargetConfig {
+	if len(targets) == 0 {
+		return nil
+	}
+	settings := connectionSettings(tc)
+	for _, kept := range targets {
+		if reflect.DeepEqual(connectionSettings(kept), settings) {
+			return kept
+		}
+	}
+	c.logger.Printf("warning: target %q has the same address as target %q (%s) but different credentials, TLS settings or subscriptions, not merged",
+		tc.Name, targets[0].Name, address)
+	return nil
+}
+
+// connectionSettings returns the fields of tc two duplicate targets must share to be merged:
+// how they connect and authenticate, and their subscriptions.
+func connectionSettings(tc *types.TargetConfig) *types.TargetConfig {
+	cs := &types.TargetConfig{
+		Username:        tc.Username,
+		Password:        tc.Password,
+		Token:           tc.Token,
+		AuthScheme:      tc.AuthScheme,
+		AuthUsernameKey: tc.AuthUsernameKey,
+		AuthPasswordKey: tc.AuthPasswordKey,
+		Insecure:        tc.Insecure,
+		SkipVerify:      tc.SkipVerify,
+		TLSCA:           tc.TLSCA,
+		TLSCert:         tc.TLSCert,
+		TLSKey:          tc.TLSKey,
+		TLSMinVersion:   tc.TLSMinVersion,
+		TLSMaxVersion:   tc.TLSMaxVersion,
+		TLSVersion:      tc.TLSVersion,
+		TLSPin:          tc.TLSPin,
+		Proxy:           tc.Proxy,
+		Encoding:        tc.Encoding,
+		Gzip:            tc.Gzip,
+	}
+	if len(tc.Subscriptions) > 0 {
+		cs.Subscriptions = append(make([]string, 0, len(tc.Subscriptions)), tc.Subscriptions...)
+		sort.Strings(cs.Subscriptions)
+	}
+	return cs
+}
+
+// targetHosts returns the host and port pairs of the addresses of target tc,
+// the addresses without a port are returned as is, with an empty port.
+// It returns nil for the targets addresses that are names, e.g: tunnel targets.
+func (c *Config) targetHosts(tc *types.TargetConfig) [][2]string {
+	if c.UseTunnelServer || strings.HasPrefix(tc.Address, "unix://") {
+		return nil
+	}
+	hosts := make([][2]string, 0, 1)
+	for _, addr := range strings.Split(tc.Address, ",") {
+		addr = strings.TrimPrefix(strings.TrimSpace(addr), types.DNSAddressPrefix)
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			hosts = append(hosts, [2]string{addr, ""})
+			continue
+		}
+		hosts = append(hosts, [2]string{host, port})
+	}
+	return hosts
+}
+
+// resolveTargetsHosts resolves concurrently the host names of the targets addresses,
+// within targetsResolveTimeout. The IP addresses are not resolved.
+// It returns the IP addresses of each resolved, normalized, host name.
+func (c *Config) resolveTargetsHosts(targets []*types.TargetConfig) map[string][]string {
+	hosts := make(map[string]struct{})
+	for _, tc := range targets {
+		for _, hp := range c.targetHosts(tc) {
+			if hp[1] == "" || net.ParseIP(hp[0]) != nil {
+				continue
+			}
+			hosts[normalizeHost(hp[0])] = struct{}{}
+		}
+	}
+	resolved := make(map[string][]string, len(hosts))
+	if len(hosts) == 0 {
+		return resolved
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), targetsResolveTimeout)
+	defer cancel()
+	mu := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(hosts))
+	for host := range hosts {
+		go func(host string) {
+			defer wg.Done()
+			addrs, err := lookupHost(ctx, host)
+			if err != nil && c.Debug {
+				c.logger.Printf("failed to resolve target host %q: %v", host, err)
+			}
+			ips := make([]string, 0, len(addrs))
+			for _, addr := range addrs {
+				if ip := net.ParseIP(addr); ip != nil {
+					ips = append(ips, ip.String())
+				}
+			}
+			if len(ips) == 0 {
+				return
+			}
+			mu.Lock()
+			resolved[host] = ips
+			mu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+	return resolved
+}
+
+// targetAddressKey returns the normalized address of target tc:
+// its sorted addresses, with the port, the host names replaced
+// by their IP addresses found in resolved.
+func (c *Config) targetAddressKey(tc *types.TargetConfig, resolved map[string][]string) string {
+	hosts := c.targetHosts(tc)
+	// the tunnel targets addresses are names
+	if hosts == nil {
+		return tc.Address
+	}
+	keys := make([]string, 0, len(hosts))
+	for _, hp := range hosts {
+		if hp[1] == "" {
+			keys = append(keys, hp[0])
+			continue
+		}
+		for _, ip := range hostIPs(hp[0], resolved) {
+			keys = append(keys, net.JoinHostPort(ip, hp[1]))
+		}
+	}
+	sort.Strings(keys)
+	// remove the addresses resolved more than once
+	uniq := keys[:0]
+	for i, k := range keys {
+		if i == 0 || k != keys[i-1] {
+			uniq = append(uniq, k)
+		}
+	}
+	return strings.Join(uniq, ",")
+}
+
+// hostIPs returns the IP addresses of host,
+// or the normalized host name itself if it did not resolve.
+func hostIPs(host string, resolved map[string][]string) []string {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}
+	}
+	host = normalizeHost(host)
+	if ips, ok := resolved[host]; ok {
+		return ips
+	}
+	return []string{host}
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// mergeDuplicateTarget merges the metadata of target dup into target kept:
+// dup's name is added to kept's aliases, the tags, groups and outputs
+// are merged, kept's event tags take precedence.
+func (c *Config) mergeDuplicateTarget(kept, dup *types.TargetConfig, address string) {
+	kept.Aliases = appendMissing(kept.Aliases, dup.Name)
+	kept.Aliases = appendMissing(kept.Aliases, dup.Aliases...)
+	kept.Tags = appendMissing(kept.Tags, dup.Tags...)
+	// an empty list means all the outputs
+	if len(kept.Outputs) > 0 && len(dup.Outputs) > 0 {
+		kept.Outputs = appendMissing(kept.Outputs, dup.Outputs...)
+	} else {
+		kept.Outputs = nil
+	}
+	// the groups event tag is updated if it was set from the groups
+	groupsTag := kept.EventTags[groupsEventTag] == strings.Join(kept.Groups, ",")
+	kept.Groups = appendMissing(kept.Groups, dup.Groups...)
+	if kept.EventTags == nil && (len(dup.EventTags) > 0 || len(kept.Groups) > 0) {
+		kept.EventTags = make(map[string]string, len(dup.EventTags)+1)
+	}
+	for k, v := range dup.EventTags {
+		if _, ok := kept.EventTags[k]; !ok {
+			kept.EventTags[k] = v
+		}
+	}
+	if groupsTag && len(kept.Groups) > 0 {
+		kept.EventTags[groupsEventTag] = strings.Join(kept.Groups, ",")
+	}
+	c.logger.Printf("target %q has the same address as target %q (%s), merged into it", dup.Name, kept.Name, address)
+}
+
+// appendMissing appends the values of vs not in s to s.
+func appendMissing(s []string, vs ...string) []string {
+OUTER:
+	for _, v := range vs {
+		for _, e := range s {
+			if e == v {
+				continue OUTER
+			}
+		}
+		s = append(s, v)
+	}
+	return s
+}
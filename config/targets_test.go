@@ -10,8 +10,13 @@ package config
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"net"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -34,18 +39,45 @@ address: 10.1.1.1
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(false),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
+			},
+		},
+		outErr: nil,
+	},
+	"from_dns_address": {
+		in: []byte(`
+port: 57400
+username: admin
+password: admin
+address: dns:///gateway.example.net
+`),
+		out: map[string]*types.TargetConfig{
+			"dns:///gateway.example.net": {
+				Address:                 "dns:///gateway.example.net:57400",
+				Name:                    "dns:///gateway.example.net",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -59,18 +91,19 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(false),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -85,18 +118,54 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
+			},
+		},
+		outErr: nil,
+	},
+	"global_event_tags": {
+		in: []byte(`
+event-tag:
+  - site=ams01
+  - role=spine
+targets:
+  10.1.1.1:57400:
+    username: admin
+    password: admin
+    event-tags:
+      role: leaf
+`),
+		out: map[string]*types.TargetConfig{
+			"10.1.1.1:57400": {
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
+				EventTags: map[string]string{
+					"site": "ams01",
+					"role": "leaf",
+				},
 			},
 		},
 		outErr: nil,
@@ -113,32 +182,34 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(false),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 			"10.1.1.2:57400": {
-				Address:      "10.1.1.2:57400",
-				Name:         "10.1.1.2:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(false),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.2:57400",
+				Name:                    "10.1.1.2:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -156,32 +227,34 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 			"10.1.1.2:57400": {
-				Address:      "10.1.1.2:57400",
-				Name:         "10.1.1.2:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.2:57400",
+				Name:                    "10.1.1.2:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -200,32 +273,34 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(true),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(true),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 			"10.1.1.2:57400": {
-				Address:      "10.1.1.2:57400",
-				Name:         "10.1.1.2:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.2:57400",
+				Name:                    "10.1.1.2:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -248,18 +323,19 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"10.1.1.1:57400": {
-				Address:      "10.1.1.1:57400",
-				Name:         "10.1.1.1:57400",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(true),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400",
+				Name:                    "10.1.1.1:57400",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(true),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 				Subscriptions: []string{
 					"sub1",
 				},
@@ -281,18 +357,19 @@ targets:
 `),
 		out: map[string]*types.TargetConfig{
 			"target1": {
-				Address:      "10.1.1.1:57400,10.1.1.2:57400",
-				Name:         "target1",
-				Password:     pointer.ToString("admin"),
-				Username:     pointer.ToString("admin"),
-				Token:        pointer.ToString(""),
-				TLSCert:      pointer.ToString(""),
-				TLSKey:       pointer.ToString(""),
-				LogTLSSecret: pointer.ToBool(false),
-				Insecure:     pointer.ToBool(false),
-				SkipVerify:   pointer.ToBool(false),
-				Gzip:         pointer.ToBool(false),
-				BufferSize:   uint(100),
+				Address:                 "10.1.1.1:57400,10.1.1.2:57400",
+				Name:                    "target1",
+				Password:                pointer.ToString("admin"),
+				Username:                pointer.ToString("admin"),
+				Token:                   pointer.ToString(""),
+				TLSCert:                 pointer.ToString(""),
+				TLSKey:                  pointer.ToString(""),
+				LogTLSSecret:            pointer.ToBool(false),
+				Insecure:                pointer.ToBool(false),
+				SkipVerify:              pointer.ToBool(false),
+				Gzip:                    pointer.ToBool(false),
+				SplitMixedSubscriptions: pointer.ToBool(false),
+				BufferSize:              uint(100),
 			},
 		},
 		outErr: nil,
@@ -336,3 +413,498 @@ func TestGetTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTargetsFromAddressFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "targets.yaml")
+	err := os.WriteFile(fileName, []byte(`
+- 10.1.1.1
+- 10.1.1.2:57401
+`), 0600)
+	if err != nil {
+		t.Fatalf("failed writing address file: %v", err)
+	}
+	cfg := New()
+	cfg.SetLogger()
+	cfg.FileConfig.SetConfigType("yaml")
+	err = cfg.FileConfig.ReadConfig(bytes.NewBuffer([]byte(`
+port: 57400
+address-file: ` + fileName)))
+	if err != nil {
+		t.Fatalf("failed reading config: %v", err)
+	}
+	err = cfg.FileConfig.Unmarshal(cfg)
+	if err != nil {
+		t.Fatalf("failed fileConfig.Unmarshal: %v", err)
+	}
+	outs, err := cfg.GetTargets()
+	if err != nil {
+		t.Fatalf("failed getting targets: %v", err)
+	}
+	for name, addr := range map[string]string{
+		"10.1.1.1":       "10.1.1.1:57400",
+		"10.1.1.2:57401": "10.1.1.2:57401",
+	} {
+		tc, ok := outs[name]
+		if !ok {
+			t.Errorf("missing target %q", name)
+			continue
+		}
+		if tc.Address != addr {
+			t.Errorf("target %q: expected address %q, got %q", name, addr, tc.Address)
+		}
+	}
+	// a malformed file does not change the current targets
+	err = os.WriteFile(fileName, []byte("10.1.1.1: [\n"), 0600)
+	if err != nil {
+		t.Fatalf("failed writing address file: %v", err)
+	}
+	_, err = cfg.GetTargets()
+	if err == nil {
+		t.Errorf("expected an error reading a malformed address file")
+	}
+	if len(cfg.Targets) != 2 {
+		t.Errorf("expected the current targets to be kept, got %v", cfg.Targets)
+	}
+}
+
+func TestGetTargetsSecurity(t *testing.T) {
+	for name, item := range map[string]struct {
+		in       string
+		insecure bool
+		force    bool
+		err      bool
+	}{
+		"insecure_tls_ca": {
+			in: `
+targets:
+  router1:
+    insecure: true
+    tls-ca: ca.pem
+`,
+			err: true,
+		},
+		"insecure_skip_verify": {
+			in: `
+targets:
+  router1:
+    insecure: true
+    skip-verify: true
+`,
+			err: true,
+		},
+		"global_insecure_tls_cert": {
+			in: `
+targets:
+  router1:
+    tls-cert: cert.pem
+    tls-key: key.pem
+`,
+			insecure: true,
+			err:      true,
+		},
+		"insecure_tls_version": {
+			in: `
+targets:
+  router1:
+    insecure: true
+    tls-version: "1.2"
+`,
+			err: true,
+		},
+		"forced": {
+			in: `
+targets:
+  router1:
+    insecure: true
+    tls-ca: ca.pem
+`,
+			force: true,
+		},
+		"insecure_only": {
+			in: `
+targets:
+  router1:
+    insecure: true
+`,
+		},
+		"target_tls_global_insecure_overridden": {
+			in: `
+targets:
+  router1:
+    insecure: false
+    skip-verify: true
+`,
+			insecure: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetLogger()
+			cfg.Insecure = item.insecure
+			cfg.Force = item.force
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(strings.NewReader(item.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = cfg.GetTargets()
+			if item.err {
+				if err == nil {
+					t.Errorf("failed at item %q: expected an error", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("failed at item %q: %v", name, err)
+			}
+		})
+	}
+}
+
+func TestGetTargetsGroups(t *testing.T) {
+	in := `
+port: 57400
+targets:
+  router1:
+    groups:
+      - core
+      - amsterdam
+  router2:
+    groups:
+      - edge
+  router3:
+`
+	for name, item := range map[string]struct {
+		groups  []string
+		address []string
+		out     []string
+		err     string
+	}{
+		"single_group": {
+			groups: []string{"core"},
+			out:    []string{"router1"},
+		},
+		"multiple_groups": {
+			groups: []string{"amsterdam", "edge"},
+			out:    []string{"router1", "router2"},
+		},
+		"group_and_address": {
+			groups:  []string{"edge"},
+			address: []string{"10.0.0.1"},
+			out:     []string{"10.0.0.1", "router2"},
+		},
+		"unknown_group": {
+			groups: []string{"core", "paris"},
+			err:    `unknown target group "paris", defined groups: ["amsterdam" "core" "edge"]`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetLogger()
+			cfg.Group = item.groups
+			cfg.Address = item.address
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tcs, err := cfg.GetTargets()
+			if item.err != "" {
+				if err == nil || err.Error() != item.err {
+					t.Errorf("failed at item %q: expected error %q, got %v", name, item.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			got := make([]string, 0, len(tcs))
+			for n := range tcs {
+				got = append(got, n)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, item.out) {
+				t.Errorf("failed at item %q: expected targets %v, got %v", name, item.out, got)
+			}
+			if tc, ok := tcs["router1"]; ok && tc.EventTags["groups"] != "core,amsterdam" {
+				t.Errorf("failed at item %q: expected the groups event tag, got %v", name, tc.EventTags)
+			}
+		})
+	}
+}
+
+func TestGetTargetsSkipped(t *testing.T) {
+	in := `
+port: 57400
+targets:
+  router1:
+  router2:
+    enabled: false
+  router3:
+    enabled: true
+`
+	for name, item := range map[string]struct {
+		skip    []string
+		address []string
+		out     []string
+		skipped map[string]string
+		err     error
+	}{
+		"disabled": {
+			out:     []string{"router1", "router3"},
+			skipped: map[string]string{"router2": "disabled in the config"},
+		},
+		"skip_target": {
+			skip: []string{"router3", "router4"},
+			out:  []string{"router1"},
+			skipped: map[string]string{
+				"router2": "disabled in the config",
+				"router3": "skipped with --skip-target",
+			},
+		},
+		"skip_address": {
+			address: []string{"10.0.0.1", "10.0.0.2"},
+			skip:    []string{"10.0.0.2"},
+			out:     []string{"10.0.0.1"},
+			skipped: map[string]string{"10.0.0.2": "skipped with --skip-target"},
+		},
+		"all_skipped": {
+			skip: []string{"router1", "router3"},
+			err:  ErrNoTargetsFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetLogger()
+			cfg.SkipTarget = item.skip
+			cfg.Address = item.address
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tcs, err := cfg.GetTargets()
+			if item.err != nil {
+				if !errors.Is(err, item.err) {
+					t.Errorf("failed at item %q: expected error %v, got %v", name, item.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			got := make([]string, 0, len(tcs))
+			for n := range tcs {
+				got = append(got, n)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, item.out) {
+				t.Errorf("failed at item %q: expected targets %v, got %v", name, item.out, got)
+			}
+			if !reflect.DeepEqual(cfg.SkippedTargets, item.skipped) {
+				t.Errorf("failed at item %q: expected skipped targets %v, got %v", name, item.skipped, cfg.SkippedTargets)
+			}
+		})
+	}
+}
+
+func TestGetTargetsDuplicates(t *testing.T) {
+	hosts := map[string][]string{
+		"router1.lab":  {"10.0.0.1"},
+		"router1.mgmt": {"10.0.0.1", "2001:db8::1"},
+	}
+	defer func(f func(context.Context, string) ([]string, error)) { lookupHost = f }(lookupHost)
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if net.ParseIP(host) != nil {
+			t.Errorf("unexpected resolution of IP address %q", host)
+		}
+		if ips, ok := hosts[host]; ok {
+			return ips, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	for name, item := range map[string]struct {
+		in        string
+		address   []string
+		allowDups bool
+		out       []string
+		aliases   map[string][]string
+		tags      map[string][]string
+	}{
+		"hostname_and_ip": {
+			in: `
+targets:
+  r1:
+    address: 10.0.0.1
+    tags: [core]
+  router1:
+    address: router1.lab:57400
+    tags: [amsterdam]
+  router2:
+    address: 10.0.0.2
+`,
+			out:     []string{"r1", "router2"},
+			aliases: map[string][]string{"r1": {"router1"}},
+			tags:    map[string][]string{"r1": {"core", "amsterdam"}},
+		},
+		"different_credentials": {
+			in: `
+targets:
+  r1:
+    address: 10.0.0.1
+    username: admin
+    password: admin
+  router1:
+    address: router1.lab:57400
+    username: operator
+    password: operator
+`,
+			out: []string{"r1", "router1"},
+		},
+		"different_subscriptions": {
+			in: `
+targets:
+  r1:
+    address: 10.0.0.1
+    subscriptions: [sub1]
+  router1:
+    address: router1.lab:57400
+    subscriptions: [sub2]
+  router1-lab:
+    address: router1.lab:57400
+    subscriptions: [sub2]
+`,
+			out:     []string{"r1", "router1"},
+			aliases: map[string][]string{"router1": {"router1-lab"}},
+		},
+		"different_port": {
+			in: `
+targets:
+  router1:
+    address: router1.lab:57400
+  router1-gnmi:
+    address: 10.0.0.1:9339
+`,
+			out: []string{"router1", "router1-gnmi"},
+		},
+		"multiple_addresses": {
+			in: `
+targets:
+  router1:
+    address: router1.mgmt
+  router1-v6:
+    address: "[2001:db8::1]:57400,10.0.0.1"
+`,
+			out:     []string{"router1"},
+			aliases: map[string][]string{"router1": {"router1-v6"}},
+		},
+		"unresolved": {
+			in: `
+targets:
+  router3:
+    address: router3.lab
+  router3-fqdn:
+    address: ROUTER3.LAB.
+`,
+			out:     []string{"router3"},
+			aliases: map[string][]string{"router3": {"router3-fqdn"}},
+		},
+		"addresses": {
+			address: []string{"router1.lab", "10.0.0.1:57400", "10.0.0.2"},
+			out:     []string{"10.0.0.2", "router1.lab"},
+			aliases: map[string][]string{"router1.lab": {"10.0.0.1:57400"}},
+		},
+		"allow_duplicates": {
+			in: `
+targets:
+  r1:
+    address: 10.0.0.1
+  router1:
+    address: router1.lab
+`,
+			allowDups: true,
+			out:       []string{"r1", "router1"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := New()
+			cfg.Address = item.address
+			cfg.AllowDuplicateTargets = item.allowDups
+			cfg.FileConfig.SetConfigType("yaml")
+			err := cfg.FileConfig.ReadConfig(strings.NewReader("port: 57400\n" + item.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tcs, err := cfg.GetTargets()
+			if err != nil {
+				t.Fatalf("failed at item %q: %v", name, err)
+			}
+			got := make([]string, 0, len(tcs))
+			for n := range tcs {
+				got = append(got, n)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, item.out) {
+				t.Errorf("failed at item %q: expected targets %v, got %v", name, item.out, got)
+			}
+			for n, aliases := range item.aliases {
+				if tc, ok := tcs[n]; !ok || !reflect.DeepEqual(tc.Aliases, aliases) {
+					t.Errorf("failed at item %q: expected target %q aliases %v, got %+v", name, n, aliases, tc)
+				}
+			}
+			for n, tags := range item.tags {
+				if tc, ok := tcs[n]; !ok || !reflect.DeepEqual(tc.Tags, tags) {
+					t.Errorf("failed at item %q: expected target %q tags %v, got %+v", name, n, tags, tc)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeDuplicateTarget(t *testing.T) {
+	defer func(f func(context.Context, string) ([]string, error)) { lookupHost = f }(lookupHost)
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if host == "router1.lab" {
+			return []string{"10.0.0.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	cfg := New()
+	cfg.Targets = map[string]*types.TargetConfig{
+		"router1": {
+			Name:          "router1",
+			Address:       "router1.lab:57400",
+			Subscriptions: []string{"sub1", "sub2"},
+			EventTags:     map[string]string{"site": "ams"},
+		},
+	}
+	discovered := &types.TargetConfig{
+		Name:          "10.0.0.1:57400",
+		Address:       "10.0.0.1:57400",
+		Subscriptions: []string{"sub2", "sub1"},
+		EventTags:     map[string]string{"site": "par", "role": "core"},
+	}
+	if !cfg.MergeDuplicateTarget(discovered) {
+		t.Fatalf("expected target %q to be merged", discovered.Name)
+	}
+	tc := cfg.Targets["router1"]
+	if !reflect.DeepEqual(tc.Aliases, []string{"10.0.0.1:57400"}) {
+		t.Errorf("unexpected aliases: %v", tc.Aliases)
+	}
+	if !reflect.DeepEqual(tc.EventTags, map[string]string{"site": "ams", "role": "core"}) {
+		t.Errorf("unexpected event tags: %v", tc.EventTags)
+	}
+	other := &types.TargetConfig{Name: "router2", Address: "10.0.0.2:57400"}
+	if cfg.MergeDuplicateTarget(other) {
+		t.Errorf("unexpected merge of target %q", other.Name)
+	}
+	insecure := true
+	otherTLS := &types.TargetConfig{Name: "r1-insecure", Address: "10.0.0.1:57400", Subscriptions: []string{"sub1", "sub2"}, Insecure: &insecure}
+	if cfg.MergeDuplicateTarget(otherTLS) {
+		t.Errorf("unexpected merge of target %q with different TLS settings", otherTLS.Name)
+	}
+	cfg.AllowDuplicateTargets = true
+	if cfg.MergeDuplicateTarget(&types.TargetConfig{Name: "r1", Address: "10.0.0.1:57400"}) {
+		t.Errorf("unexpected merge with allow-duplicate-targets")
+	}
+}
@@ -50,10 +50,11 @@ type targetMatch struct {
 }
 
 func (c *Config) GetTunnelServer() error {
+	c.TunnelServer = new(tunnelServer)
 	if !c.FileConfig.IsSet("tunnel-server") {
+		c.setTunnelServerDefaults()
 		return nil
 	}
-	c.TunnelServer = new(tunnelServer)
 	c.TunnelServer.Address = os.ExpandEnv(c.FileConfig.GetString("tunnel-server/address"))
 	c.TunnelServer.SkipVerify = os.ExpandEnv(c.FileConfig.GetString("tunnel-server/skip-verify")) == "true"
 	c.TunnelServer.CaFile = os.ExpandEnv(c.FileConfig.GetString("tunnel-server/ca-file"))
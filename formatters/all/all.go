@@ -12,6 +12,7 @@ import (
 	_ "github.com/openconfig/gnmic/formatters/event_add_tag"
 	_ "github.com/openconfig/gnmic/formatters/event_allow"
 	_ "github.com/openconfig/gnmic/formatters/event_convert"
+	_ "github.com/openconfig/gnmic/formatters/event_convert_scale"
 	_ "github.com/openconfig/gnmic/formatters/event_data_convert"
 	_ "github.com/openconfig/gnmic/formatters/event_date_string"
 	_ "github.com/openconfig/gnmic/formatters/event_delete"
@@ -22,6 +23,7 @@ import (
 	_ "github.com/openconfig/gnmic/formatters/event_jq"
 	_ "github.com/openconfig/gnmic/formatters/event_merge"
 	_ "github.com/openconfig/gnmic/formatters/event_override_ts"
+	_ "github.com/openconfig/gnmic/formatters/event_rate"
 	_ "github.com/openconfig/gnmic/formatters/event_starlark"
 	_ "github.com/openconfig/gnmic/formatters/event_strings"
 	_ "github.com/openconfig/gnmic/formatters/event_to_tag"
@@ -11,6 +11,7 @@ package formatters
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	flattener "github.com/karimra/go-map-flattener"
@@ -22,11 +23,14 @@ import (
 // The name is derived from the subscription in case the update was received in a subscribeResponse
 // the tags are derived from the keys in gNMI path as well as some metadata from the subscription.
 type EventMsg struct {
-	Name      string                 `json:"name,omitempty"`
-	Timestamp int64                  `json:"timestamp,omitempty"`
-	Tags      map[string]string      `json:"tags,omitempty"`
-	Values    map[string]interface{} `json:"values,omitempty"`
-	Deletes   []string               `json:"deletes,omitempty"`
+	Name          string                 `json:"name,omitempty"`
+	Timestamp     int64                  `json:"timestamp,omitempty"`
+	RecvTimestamp int64                  `json:"recv-timestamp,omitempty"`
+	Seq           uint64                 `json:"seq,omitempty"`
+	RunID         string                 `json:"run-id,omitempty"`
+	Tags          map[string]string      `json:"tags,omitempty"`
+	Values        map[string]interface{} `json:"values,omitempty"`
+	Deletes       []string               `json:"deletes,omitempty"`
 }
 
 func (e *EventMsg) String() string {
@@ -40,6 +44,10 @@ func ResponseToEventMsgs(name string, rsp *gnmi.SubscribeResponse, meta map[stri
 		return nil, nil
 	}
 	evs := make([]*EventMsg, 0)
+	// the receive timestamp and the sequence number are not added as tags
+	recvTS, _ := strconv.ParseInt(meta["recv-timestamp"], 10, 64)
+	seq, _ := strconv.ParseUint(meta["seq"], 10, 64)
+	runID := meta["run-id"]
 	switch rsp := rsp.Response.(type) {
 	case *gnmi.SubscribeResponse_Update:
 		namePrefix, prefixTags := TagsFromGNMIPath(rsp.Update.Prefix)
@@ -49,8 +57,10 @@ func ResponseToEventMsgs(name string, rsp *gnmi.SubscribeResponse, meta map[stri
 			if err != nil {
 				return nil, err
 			}
+			e.RecvTimestamp = recvTS
+			e.Seq, e.RunID = seq, runID
 			for k, v := range meta {
-				if k == "format" {
+				if eventMetaField(k) {
 					continue
 				}
 				if _, ok := e.Tags[k]; ok {
@@ -69,17 +79,20 @@ func ResponseToEventMsgs(name string, rsp *gnmi.SubscribeResponse, meta map[stri
 		// notification deletes
 		if len(rsp.Update.Delete) > 0 {
 			e := &EventMsg{
-				Name:      name,
-				Timestamp: rsp.Update.Timestamp,
-				Tags:      make(map[string]string),
-				Deletes:   make([]string, 0, len(rsp.Update.Delete)),
+				Name:          name,
+				Timestamp:     rsp.Update.Timestamp,
+				RecvTimestamp: recvTS,
+				Seq:           seq,
+				RunID:         runID,
+				Tags:          make(map[string]string),
+				Deletes:       make([]string, 0, len(rsp.Update.Delete)),
 			}
 			// build tags
 			for k, v := range prefixTags {
 				e.Tags[k] = v
 			}
 			for k, v := range meta {
-				if k == "format" {
+				if eventMetaField(k) {
 					continue
 				}
 				if _, ok := e.Tags[k]; ok {
@@ -98,6 +111,16 @@ func ResponseToEventMsgs(name string, rsp *gnmi.SubscribeResponse, meta map[stri
 	return evs, nil
 }
 
+// eventMetaField returns true if the meta key k is not added to the events tags,
+// either ignored or set as a dedicated event field.
+func eventMetaField(k string) bool {
+	switch k {
+	case "format", "recv-timestamp", "seq", "run-id":
+		return true
+	}
+	return false
+}
+
 func GetResponseToEventMsgs(rsp *gnmi.GetResponse, meta map[string]string, eps ...EventProcessor) ([]*EventMsg, error) {
 	if rsp == nil {
 		return nil, nil
@@ -142,6 +165,11 @@ func updateToEvent(name, prefix string, ts int64, upd *gnmi.Update, tags map[str
 		e.Tags[k] = v
 	}
 	pathName, pTags := TagsFromGNMIPath(upd.Path)
+	// a path origin leads the name if the prefix has none
+	if o := upd.GetPath().GetOrigin(); o != "" && (prefix == "" || strings.HasPrefix(prefix, "/")) {
+		pathName = strings.TrimPrefix(pathName, o+":")
+		prefix = o + ":" + prefix
+	}
 	psb := strings.Builder{}
 	psb.WriteString(strings.TrimRight(prefix, "/"))
 	psb.WriteString("/")
@@ -222,6 +250,8 @@ func getValueFlat(prefix string, updValue *gnmi.TypedValue) (map[string]interfac
 	case *gnmi.TypedValue_FloatVal:
 		//lint:ignore SA1019 still need GetFloatVal for backward compatibility
 		values[prefix] = updValue.GetFloatVal()
+	case *gnmi.TypedValue_DoubleVal:
+		values[prefix] = updValue.GetDoubleVal()
 	case *gnmi.TypedValue_IntVal:
 		values[prefix] = updValue.GetIntVal()
 	case *gnmi.TypedValue_StringVal:
@@ -292,6 +322,12 @@ func (e *EventMsg) ToMap() map[string]interface{} {
 	if len(e.Deletes) > 0 {
 		m["deletes"] = e.Deletes
 	}
+	if e.Seq != 0 {
+		m["seq"] = e.Seq
+	}
+	if e.RunID != "" {
+		m["run-id"] = e.RunID
+	}
 	return m
 }
 
@@ -356,6 +392,24 @@ func EventFromMap(m map[string]interface{}) (*EventMsg, error) {
 			return nil, fmt.Errorf("could not convert map to event message, name it not a string")
 		}
 	}
+	if v, ok := m["seq"]; ok {
+		switch v := v.(type) {
+		case float64:
+			e.Seq = uint64(v)
+		default:
+			switch i := num64(v).(type) {
+			case int64:
+				e.Seq = uint64(i)
+			case uint64:
+				e.Seq = i
+			default:
+				return nil, fmt.Errorf("could not convert map to event message, seq is not an integer")
+			}
+		}
+	}
+	if v, ok := m["run-id"]; ok {
+		e.RunID, _ = v.(string)
+	}
 	return e, nil
 }
 
@@ -0,0 +1,223 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_convert_scale
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/bits"
+	"os"
+	"regexp"
+
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+)
+
+const (
+	processorType = "event-convert-scale"
+	loggingPrefix = "[" + processorType + "] "
+)
+
+var errOverflow = errors.New("integer overflow")
+
+// convertScale applies a multiplier, a divisor and an offset
+// to the numeric values with a name matching a rule's regex.
+type convertScale struct {
+	Rules []*rule `mapstructure:"rules,omitempty" json:"rules,omitempty"`
+	Debug bool    `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	logger *log.Logger
+}
+
+type rule struct {
+	// regex matched with the value name
+	ValueName string `mapstructure:"value-name,omitempty" json:"value-name,omitempty"`
+	// the value is multiplied by Multiplier
+	Multiplier float64 `mapstructure:"multiplier,omitempty" json:"multiplier,omitempty"`
+	// the value is divided by Divisor
+	Divisor float64 `mapstructure:"divisor,omitempty" json:"divisor,omitempty"`
+	// Offset is added to the value after scaling
+	Offset float64 `mapstructure:"offset,omitempty" json:"offset,omitempty"`
+	// replacement string of ValueName, used to rename the value
+	Rename string `mapstructure:"rename,omitempty" json:"rename,omitempty"`
+
+	re *regexp.Regexp
+	// true if the scaling can be done using integer arithmetic
+	integer bool
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &convertScale{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (c *convertScale) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, c)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i, r := range c.Rules {
+		if r == nil {
+			return fmt.Errorf("rule %d: empty rule", i)
+		}
+		if r.Multiplier == 0 && r.Divisor == 0 {
+			return fmt.Errorf("rule %d: one of multiplier or divisor must be set", i)
+		}
+		if r.Multiplier == 0 {
+			r.Multiplier = 1
+		}
+		if r.Divisor == 0 {
+			r.Divisor = 1
+		}
+		r.re, err = regexp.Compile(r.ValueName)
+		if err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+		r.integer = r.Divisor == 1 &&
+			r.Multiplier == math.Trunc(r.Multiplier) && math.Abs(r.Multiplier) < math.MaxInt64 &&
+			r.Offset == math.Trunc(r.Offset) && math.Abs(r.Offset) < math.MaxInt64
+	}
+	if c.logger.Writer() != io.Discard {
+		b, err := json.Marshal(c)
+		if err != nil {
+			c.logger.Printf("initialized processor '%s': %+v", processorType, c)
+			return nil
+		}
+		c.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (c *convertScale) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		// collect the names first so that
+		// renamed values are not processed again.
+		names := make([]string, 0, len(e.Values))
+		for k := range e.Values {
+			names = append(names, k)
+		}
+		for _, k := range names {
+			for _, r := range c.Rules {
+				if !r.re.MatchString(k) {
+					continue
+				}
+				nv, err := r.scale(e.Values[k])
+				if errors.Is(err, errOverflow) {
+					c.logger.Printf("warning: value %q=%v overflows when scaled, converted to float64 with possible loss of precision", k, e.Values[k])
+				} else if err != nil {
+					c.logger.Printf("value %q not scaled: %v", k, err)
+					break
+				}
+				if r.Rename != "" {
+					delete(e.Values, k)
+					k = r.re.ReplaceAllString(k, r.Rename)
+				}
+				e.Values[k] = nv
+				break
+			}
+		}
+	}
+	return es
+}
+
+func (c *convertScale) WithLogger(l *log.Logger) {
+	if c.Debug && l != nil {
+		c.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if c.Debug {
+		c.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (c *convertScale) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (c *convertScale) WithActions(act map[string]map[string]interface{}) {}
+
+// scale returns the scaled value.
+// integer values are kept as integers when possible,
+// if the result overflows it is returned as a float64 along with errOverflow.
+func (r *rule) scale(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case int:
+		return r.scaleInt(int64(v))
+	case int8:
+		return r.scaleInt(int64(v))
+	case int16:
+		return r.scaleInt(int64(v))
+	case int32:
+		return r.scaleInt(int64(v))
+	case int64:
+		return r.scaleInt(v)
+	case uint:
+		return r.scaleUint(uint64(v))
+	case uint8:
+		return r.scaleUint(uint64(v))
+	case uint16:
+		return r.scaleUint(uint64(v))
+	case uint32:
+		return r.scaleUint(uint64(v))
+	case uint64:
+		return r.scaleUint(v)
+	case float32:
+		return r.scaleFloat(float64(v)), nil
+	case float64:
+		return r.scaleFloat(v), nil
+	default:
+		return nil, fmt.Errorf("not a numeric value, type %T", v)
+	}
+}
+
+func (r *rule) scaleFloat(f float64) float64 {
+	return f*r.Multiplier/r.Divisor + r.Offset
+}
+
+func (r *rule) scaleInt(i int64) (interface{}, error) {
+	if !r.integer {
+		return r.scaleFloat(float64(i)), nil
+	}
+	m := int64(r.Multiplier)
+	o := int64(r.Offset)
+	p := i * m
+	if i != 0 && (p/i != m || (i == -1 && m == math.MinInt64) || (m == -1 && i == math.MinInt64)) {
+		return r.scaleFloat(float64(i)), errOverflow
+	}
+	s := p + o
+	if (o > 0 && s < p) || (o < 0 && s > p) {
+		return r.scaleFloat(float64(i)), errOverflow
+	}
+	return s, nil
+}
+
+func (r *rule) scaleUint(u uint64) (interface{}, error) {
+	if !r.integer || r.Multiplier < 0 || r.Offset < 0 {
+		return r.scaleFloat(float64(u)), nil
+	}
+	hi, p := bits.Mul64(u, uint64(r.Multiplier))
+	if hi != 0 {
+		return r.scaleFloat(float64(u)), errOverflow
+	}
+	s, carry := bits.Add64(p, uint64(r.Offset), 0)
+	if carry != 0 {
+		return r.scaleFloat(float64(u)), errOverflow
+	}
+	return s, nil
+}
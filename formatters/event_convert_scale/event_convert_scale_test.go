@@ -0,0 +1,181 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_convert_scale
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+type item struct {
+	input  []*formatters.EventMsg
+	output []*formatters.EventMsg
+}
+
+var testset = map[string]struct {
+	processorType string
+	processor     map[string]interface{}
+	tests         []item
+}{
+	"octets_to_bits": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"value-name": "^(.*)out-octets$",
+					"multiplier": 8,
+					"rename":     "${1}out-bits",
+				},
+			},
+		},
+		tests: []item{
+			{
+				input:  nil,
+				output: nil,
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-octets": uint64(100),
+							"/interface/in-octets":  uint64(100),
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-bits":  uint64(800),
+							"/interface/in-octets": uint64(100),
+						},
+					},
+				},
+			},
+			// overflow
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-octets": uint64(math.MaxUint64),
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-bits": float64(math.MaxUint64) * 8,
+						},
+					},
+				},
+			},
+			// non numeric
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-octets": "100",
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/out-octets": "100",
+						},
+					},
+				},
+			},
+		},
+	},
+	"centi_degrees": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"value-name": "temperature$",
+					"divisor":    100,
+				},
+				map[string]interface{}{
+					"value-name": "offset$",
+					"multiplier": 2,
+					"offset":     -10,
+				},
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"temperature": int32(2345),
+							"offset":      int64(4),
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"temperature": float64(23.45),
+							"offset":      int64(-2),
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestEventConvertScale(t *testing.T) {
+	for name, ts := range testset {
+		if pi, ok := formatters.EventProcessors[ts.processorType]; ok {
+			t.Log("found processor")
+			p := pi()
+			err := p.Init(ts.processor, formatters.WithLogger(nil))
+			if err != nil {
+				t.Errorf("failed to initialize processors: %v", err)
+				return
+			}
+			for i, item := range ts.tests {
+				t.Run(name, func(t *testing.T) {
+					t.Logf("running test item %d", i)
+					outs := p.Apply(item.input...)
+					for j := range outs {
+						if !reflect.DeepEqual(outs[j], item.output[j]) {
+							t.Logf("failed at %s item %d, index %d", name, i, j)
+							t.Logf("expected: %#v", item.output[j])
+							t.Logf("     got: %#v", outs[j])
+							t.Fail()
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEventConvertScaleInit(t *testing.T) {
+	pi, ok := formatters.EventProcessors[processorType]
+	if !ok {
+		t.Fatalf("processor %q not registered", processorType)
+	}
+	err := pi().Init(map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"value-name": ".*",
+				"offset":     1,
+			},
+		},
+	})
+	if err == nil {
+		t.Errorf("expected an error for a rule without multiplier and divisor")
+	}
+}
@@ -0,0 +1,274 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_rate
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+)
+
+const (
+	processorType = "event-rate"
+	loggingPrefix = "[" + processorType + "] "
+
+	defaultExpiration = 10 * time.Minute
+	defaultMaxEntries = 100000
+)
+
+// rate replaces the values with key matching one of regexes,
+// with the per second rate of change since the previous value
+type rate struct {
+	Values      []string      `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	Suffix      string        `mapstructure:"suffix,omitempty" json:"suffix,omitempty"`
+	CounterMax  uint64        `mapstructure:"counter-max,omitempty" json:"counter-max,omitempty"`
+	ResetAsZero bool          `mapstructure:"reset-as-zero,omitempty" json:"reset-as-zero,omitempty"`
+	Expiration  time.Duration `mapstructure:"expiration,omitempty" json:"expiration,omitempty"`
+	MaxEntries  int           `mapstructure:"max-entries,omitempty" json:"max-entries,omitempty"`
+	Debug       bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	values []*regexp.Regexp
+	logger *log.Logger
+
+	m         *sync.Mutex
+	prev      map[string]*sample
+	lastSweep time.Time
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+	lastSeen  time.Time
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &rate{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (r *rate) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, r)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.values = make([]*regexp.Regexp, 0, len(r.Values))
+	for _, reg := range r.Values {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		r.values = append(r.values, re)
+	}
+	if r.Expiration <= 0 {
+		r.Expiration = defaultExpiration
+	}
+	if r.MaxEntries <= 0 {
+		r.MaxEntries = defaultMaxEntries
+	}
+	r.m = new(sync.Mutex)
+	r.prev = make(map[string]*sample)
+	r.lastSweep = time.Now()
+	if r.logger.Writer() != io.Discard {
+		b, err := json.Marshal(r)
+		if err != nil {
+			r.logger.Printf("initialized processor '%s': %+v", processorType, r)
+			return nil
+		}
+		r.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (r *rate) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	r.m.Lock()
+	defer r.m.Unlock()
+	now := time.Now()
+	if now.Sub(r.lastSweep) >= r.Expiration {
+		r.expire(now)
+	}
+	res := make([]*formatters.EventMsg, 0, len(es))
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		hadValues := len(e.Values) > 0
+		// collect the matching names first so that
+		// added rate values are not processed again.
+		names := make([]string, 0, len(e.Values))
+		for k := range e.Values {
+			if r.match(k) {
+				names = append(names, k)
+			}
+		}
+		for _, k := range names {
+			v := e.Values[k]
+			fv, ok := toFloat(v)
+			if !ok {
+				continue
+			}
+			rv, ok := r.compute(r.key(e, k), fv, e.Timestamp, now)
+			if r.Suffix != "" {
+				if ok {
+					e.Values[k+r.Suffix] = rv
+				}
+				continue
+			}
+			if !ok {
+				delete(e.Values, k)
+				continue
+			}
+			e.Values[k] = rv
+		}
+		if hadValues && len(e.Values) == 0 {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func (r *rate) WithLogger(l *log.Logger) {
+	if r.Debug && l != nil {
+		r.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if r.Debug {
+		r.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (r *rate) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (r *rate) WithActions(act map[string]map[string]interface{}) {}
+
+func (r *rate) match(k string) bool {
+	for _, re := range r.values {
+		if re.MatchString(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// compute stores the new sample and returns the rate since the previous one.
+// it returns false if no rate can be emitted.
+func (r *rate) compute(key string, v float64, ts int64, now time.Time) (float64, bool) {
+	prev, ok := r.prev[key]
+	if !ok {
+		if len(r.prev) >= r.MaxEntries {
+			r.logger.Printf("max entries %d reached, not tracking %q", r.MaxEntries, key)
+			return 0, false
+		}
+		r.prev[key] = &sample{value: v, timestamp: ts, lastSeen: now}
+		return 0, false
+	}
+	dt := float64(ts-prev.timestamp) / float64(time.Second)
+	if dt <= 0 {
+		// out of order or duplicate sample
+		return 0, false
+	}
+	old := prev.value
+	delta := v - old
+	prev.value = v
+	prev.timestamp = ts
+	prev.lastSeen = now
+	if delta < 0 {
+		switch {
+		case r.CounterMax > 0 && old <= float64(r.CounterMax):
+			// counter wrapped around
+			delta = float64(r.CounterMax) - old + v + 1
+		case r.ResetAsZero:
+			r.logger.Printf("counter reset detected for %q", key)
+			return 0, true
+		default:
+			r.logger.Printf("counter reset detected for %q", key)
+			return 0, false
+		}
+	}
+	return delta / dt, true
+}
+
+// expire removes the entries not seen for the configured expiration duration.
+func (r *rate) expire(now time.Time) {
+	r.lastSweep = now
+	expired := 0
+	for k, s := range r.prev {
+		if now.Sub(s.lastSeen) >= r.Expiration {
+			delete(r.prev, k)
+			expired++
+		}
+	}
+	r.logger.Printf("tracked entries: %d, expired: %d", len(r.prev), expired)
+}
+
+// key builds a unique identifier for a value from the event name,
+// its tags (including the source) and the value name.
+func (r *rate) key(e *formatters.EventMsg, vn string) string {
+	tks := make([]string, 0, len(e.Tags))
+	for k := range e.Tags {
+		tks = append(tks, k)
+	}
+	sort.Strings(tks)
+	sb := new(strings.Builder)
+	sb.WriteString(e.Name)
+	for _, k := range tks {
+		sb.WriteString("|")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(e.Tags[k])
+	}
+	sb.WriteString("|")
+	sb.WriteString(vn)
+	return sb.String()
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,239 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_rate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+type item struct {
+	input  []*formatters.EventMsg
+	output []*formatters.EventMsg
+}
+
+var testset = map[string]struct {
+	processorType string
+	processor     map[string]interface{}
+	tests         []item
+}{
+	"replace": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"value-names": []string{"octets$"},
+		},
+		tests: []item{
+			// nil msg
+			{
+				input:  nil,
+				output: []*formatters.EventMsg{},
+			},
+			// first sample, no rate
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 1_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"in-octets": uint64(100), "oper-state": "up"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 1_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"oper-state": "up"},
+					},
+				},
+			},
+			// second sample, 10s later
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 11_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"in-octets": uint64(1100), "oper-state": "up"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 11_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"in-octets": float64(100), "oper-state": "up"},
+					},
+				},
+			},
+			// different target, first sample, event dropped
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 11_000_000_000,
+						Tags:      map[string]string{"source": "r2"},
+						Values:    map[string]interface{}{"in-octets": uint64(1100)},
+					},
+				},
+				output: []*formatters.EventMsg{},
+			},
+			// counter reset, value dropped
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 21_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"in-octets": uint64(10), "oper-state": "up"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 21_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"oper-state": "up"},
+					},
+				},
+			},
+			// non numeric value untouched
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 31_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"description-octets": "n/a"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 31_000_000_000,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"description-octets": "n/a"},
+					},
+				},
+			},
+		},
+	},
+	"suffix": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"value-names":   []string{".*"},
+			"suffix":        "_rate",
+			"reset-as-zero": true,
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 1_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 100},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 1_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 100},
+					},
+				},
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 3_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 300},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 3_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 300, "in-octets_rate": float64(100)},
+					},
+				},
+			},
+			// reset
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 5_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 0},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 5_000_000_000,
+						Values:    map[string]interface{}{"in-octets": 0, "in-octets_rate": float64(0)},
+					},
+				},
+			},
+		},
+	},
+	"counter_wrap": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"value-names": []string{".*"},
+			"counter-max": 255,
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 1_000_000_000,
+						Values:    map[string]interface{}{"counter": uint8(250)},
+					},
+				},
+				output: []*formatters.EventMsg{},
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Timestamp: 2_000_000_000,
+						Values:    map[string]interface{}{"counter": uint8(4)},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Timestamp: 2_000_000_000,
+						Values:    map[string]interface{}{"counter": float64(10)},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestEventRate(t *testing.T) {
+	for name, ts := range testset {
+		if pi, ok := formatters.EventProcessors[ts.processorType]; ok {
+			t.Log("found processor")
+			p := pi()
+			err := p.Init(ts.processor, formatters.WithLogger(nil))
+			if err != nil {
+				t.Errorf("failed to initialize processors: %v", err)
+				return
+			}
+			// items within a set depend on each other, run them sequentially
+			t.Run(name, func(t *testing.T) {
+				for i, item := range ts.tests {
+					t.Logf("running test item %d", i)
+					outs := p.Apply(item.input...)
+					if len(outs) != len(item.output) {
+						t.Logf("failed at %s item %d, expected %d msgs, got %d", name, i, len(item.output), len(outs))
+						t.Fail()
+						continue
+					}
+					for j := range outs {
+						if !reflect.DeepEqual(outs[j], item.output[j]) {
+							t.Logf("failed at %s item %d, index %d", name, i, j)
+							t.Logf("expected: %#v", item.output[j])
+							t.Logf("     got: %#v", outs[j])
+							t.Fail()
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -24,6 +24,24 @@ type item struct {
 }
 
 var eventMsgtestSet = map[string][]item{
+	"sequence": {
+		{
+			ev: &EventMsg{
+				Timestamp: 100,
+				Seq:       7,
+				RunID:     "run1",
+				Tags:      map[string]string{"source": "t1"},
+			},
+			m: map[string]interface{}{
+				"timestamp": int64(100),
+				"seq":       uint64(7),
+				"run-id":    "run1",
+				"tags": map[string]interface{}{
+					"source": "t1",
+				},
+			},
+		},
+	},
 	"nil": {
 		{
 			ev: nil,
@@ -394,3 +412,92 @@ func Test_getValueFlat(t *testing.T) {
 		})
 	}
 }
+
+func TestResponseToEventMsgsSequence(t *testing.T) {
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 100,
+				Update: []*gnmi.Update{{
+					Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}}},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+				}},
+				Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "b"}}}},
+			},
+		},
+	}
+	evs, err := ResponseToEventMsgs("sub1", rsp, map[string]string{"source": "t1", "seq": "42", "run-id": "run1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	for _, ev := range evs {
+		if ev.Seq != 42 || ev.RunID != "run1" {
+			t.Errorf("unexpected seq %d and run-id %q", ev.Seq, ev.RunID)
+		}
+		if _, ok := ev.Tags["seq"]; ok {
+			t.Errorf("the seq is not expected as a tag: %v", ev.Tags)
+		}
+		if _, ok := ev.Tags["run-id"]; ok {
+			t.Errorf("the run-id is not expected as a tag: %v", ev.Tags)
+		}
+		if ev.Tags["source"] != "t1" {
+			t.Errorf("expected the source tag, got: %v", ev.Tags)
+		}
+	}
+}
+
+func TestResponseToEventMsgsPathOrigin(t *testing.T) {
+	tests := map[string]struct {
+		prefix *gnmi.Path
+		path   *gnmi.Path
+		want   string
+	}{
+		"path_origin": {
+			path: &gnmi.Path{Origin: "native", Elem: []*gnmi.PathElem{{Name: "a"}, {Name: "b"}}},
+			want: "native:/a/b",
+		},
+		"path_origin_with_prefix": {
+			prefix: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}}},
+			path:   &gnmi.Path{Origin: "openconfig", Elem: []*gnmi.PathElem{{Name: "b"}}},
+			want:   "openconfig:/a/b",
+		},
+		"prefix_origin": {
+			prefix: &gnmi.Path{Origin: "openconfig", Elem: []*gnmi.PathElem{{Name: "a"}}},
+			path:   &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "b"}}},
+			want:   "openconfig:/a/b",
+		},
+		"no_origin": {
+			path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}, {Name: "b"}}},
+			want: "/a/b",
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			rsp := &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{
+					Update: &gnmi.Notification{
+						Timestamp: 100,
+						Prefix:    item.prefix,
+						Update: []*gnmi.Update{{
+							Path: item.path,
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+						}},
+					},
+				},
+			}
+			evs, err := ResponseToEventMsgs("sub1", rsp, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(evs) != 1 {
+				t.Fatalf("failed at item %q: expected 1 event, got %d", name, len(evs))
+			}
+			if _, ok := evs[0].Values[item.want]; !ok {
+				t.Errorf("failed at item %q: expected value %q, got: %v", name, item.want, evs[0].Values)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ package formatters
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -36,44 +37,49 @@ func responseFlat(msg proto.Message) (map[string]interface{}, error) {
 	case *gnmi.GetResponse:
 		rs := make(map[string]interface{})
 		for _, n := range msg.GetNotification() {
-			prefix := utils.GnmiPathToXPath(n.GetPrefix(), false)
-			for _, u := range n.GetUpdate() {
-				p := utils.GnmiPathToXPath(u.GetPath(), false)
-				vmap, err := getValueFlat(filepath.Join(prefix, p), u.GetVal())
-				if err != nil {
-					return nil, err
-				}
-				if len(vmap) == 0 {
-					rs[p] = "{}"
-					continue
-				}
-				for p, v := range vmap {
-					rs[p] = v
-				}
+			err := notificationFlat(rs, n)
+			if err != nil {
+				return nil, err
 			}
 		}
 		return rs, nil
 	case *gnmi.SubscribeResponse:
 		rs := make(map[string]interface{})
-		n := msg.GetUpdate()
-		if n != nil {
-			prefix := utils.GnmiPathToXPath(n.GetPrefix(), false)
-			for _, u := range n.GetUpdate() {
-				p := utils.GnmiPathToXPath(u.GetPath(), false)
-				vmap, err := getValueFlat(filepath.Join(prefix, p), u.GetVal())
-				if err != nil {
-					return nil, err
-				}
-				if len(vmap) == 0 {
-					rs[p] = "{}"
-					continue
-				}
-				for p, v := range vmap {
-					rs[p] = v
-				}
-			}
+		err := notificationFlat(rs, msg.GetUpdate())
+		if err != nil {
+			return nil, err
 		}
 		return rs, nil
 	}
 	return nil, errors.New("unsupported message type")
 }
+
+// notificationFlat adds the flattened updates of notification n to rs.
+func notificationFlat(rs map[string]interface{}, n *gnmi.Notification) error {
+	if n == nil {
+		return nil
+	}
+	prefix := utils.GnmiPathToXPath(n.GetPrefix(), false)
+	for _, u := range n.GetUpdate() {
+		p := utils.GnmiPathToXPath(u.GetPath(), false)
+		vmap, err := getValueFlat(filepath.Join(prefix, p), u.GetVal())
+		if err != nil {
+			return err
+		}
+		if len(vmap) == 0 {
+			rs[p] = "{}"
+			continue
+		}
+		for p, v := range vmap {
+			rs[p] = v
+		}
+	}
+	return nil
+}
+
+// notificationHeader returns the header line printed before the flattened updates
+// of notification n when the notification meta is included.
+func notificationHeader(n *gnmi.Notification) string {
+	return fmt.Sprintf("notification: prefix=%q origin=%q target=%q",
+		utils.GnmiPathToXPath(n.GetPrefix(), false), n.GetPrefix().GetOrigin(), n.GetPrefix().GetTarget())
+}
@@ -10,7 +10,7 @@ package formatters
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -22,23 +22,51 @@ import (
 )
 
 type MarshalOptions struct {
-	Multiline  bool
-	Indent     string
-	Format     string
-	OverrideTS bool
-	ValuesOnly bool
+	Multiline   bool
+	Indent      string
+	Format      string
+	OverrideTS  bool
+	ValuesOnly  bool
+	NumAsString bool
+	// IncludeMeta adds the notifications prefix origin and target
+	// to the json and flat formats.
+	IncludeMeta bool
+	// Location is the timezone of the human-readable timestamps,
+	// the local timezone if nil.
+	Location *time.Location
+}
+
+// timestamp returns the time of the nanoseconds timestamp ts,
+// in the options location.
+func (o *MarshalOptions) timestamp(ts int64) time.Time {
+	t := time.Unix(0, ts)
+	if o.Location != nil {
+		return t.In(o.Location)
+	}
+	return t
 }
 
 // Marshal //
 func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps ...EventProcessor) ([]byte, error) {
 	msg = o.OverrideTimestamp(msg)
+	if o.ValuesOnly {
+		switch msg.ProtoReflect().Interface().(type) {
+		case *gnmi.GetResponse, *gnmi.SubscribeResponse:
+			return valuesOnly(msg)
+		}
+	}
 	switch o.Format {
 	default: // json
 		return o.FormatJSON(msg, meta)
 	case "proto":
 		return proto.Marshal(msg)
 	case "protojson":
-		return protojson.MarshalOptions{Multiline: o.Multiline, Indent: o.Indent}.Marshal(msg)
+		b, err := protojson.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		// protojson output is not stable, normalize it
+		return normalizeJSON(b, o.jsonIndent())
 	case "prototext":
 		return prototext.MarshalOptions{Multiline: o.Multiline, Indent: o.Indent}.Marshal(msg)
 	case "event":
@@ -56,11 +84,8 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 				if err != nil {
 					return nil, fmt.Errorf("failed converting response to events: %v", err)
 				}
-				if o.Multiline {
-					b, err = json.MarshalIndent(events, "", o.Indent)
-				} else {
-					b, err = json.Marshal(events)
-				}
+				o.eventsNumToString(events)
+				b, err = o.marshalJSON(events)
 				if err != nil {
 					return nil, fmt.Errorf("failed marshaling format 'event': %v", err)
 				}
@@ -71,12 +96,9 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 			if err != nil {
 				return nil, fmt.Errorf("failed converting response to events: %v", err)
 			}
+			o.eventsNumToString(events)
 
-			if o.Multiline {
-				b, err = json.MarshalIndent(events, "", o.Indent)
-			} else {
-				b, err = json.Marshal(events)
-			}
+			b, err = o.marshalJSON(events)
 			if err != nil {
 				return nil, fmt.Errorf("failed marshaling format 'event': %v", err)
 			}
@@ -85,6 +107,12 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 			return nil, fmt.Errorf("format 'event' not supported for msg type %T", msg.ProtoReflect().Interface())
 		}
 	case "flat":
+		if m, ok := msg.ProtoReflect().Interface().(*gnmi.SetResponse); ok {
+			return o.setResponseFlat(m), nil
+		}
+		if o.IncludeMeta {
+			return flatWithMeta(msg, meta)
+		}
 		flatMsg, err := responseFlat(msg)
 		if err != nil {
 			return nil, err
@@ -100,14 +128,79 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 		}
 		sort.Strings(sortedPaths)
 
+		prefix := flatPrefix(msg, meta)
 		buf := new(bytes.Buffer)
 		for _, p := range sortedPaths {
-			buf.WriteString(fmt.Sprintf("%s: %v\n", p, flatMsg[p]))
+			buf.WriteString(fmt.Sprintf("%s%s: %v\n", prefix, p, flatMsg[p]))
 		}
 		return buf.Bytes(), nil
 	}
 }
 
+// flatWithMeta formats the notifications of msg in the flat format,
+// each one preceded by a header line with its prefix origin and target.
+func flatWithMeta(msg proto.Message, meta map[string]string) ([]byte, error) {
+	var notifications []*gnmi.Notification
+	switch msg := msg.ProtoReflect().Interface().(type) {
+	case *gnmi.GetResponse:
+		notifications = msg.GetNotification()
+	case *gnmi.SubscribeResponse:
+		if n := msg.GetUpdate(); n != nil {
+			notifications = []*gnmi.Notification{n}
+		}
+	default:
+		return nil, errors.New("unsupported message type")
+	}
+	prefix := flatPrefix(msg, meta)
+	buf := new(bytes.Buffer)
+	for _, n := range notifications {
+		rs := make(map[string]interface{})
+		err := notificationFlat(rs, n)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(fmt.Sprintf("%s%s\n", prefix, notificationHeader(n)))
+		sortedPaths := make([]string, 0, len(rs))
+		for k := range rs {
+			sortedPaths = append(sortedPaths, k)
+		}
+		sort.Strings(sortedPaths)
+		for _, p := range sortedPaths {
+			buf.WriteString(fmt.Sprintf("%s%s: %v\n", prefix, p, rs[p]))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// flatPrefix returns the prefix of the flat lines of a subscribe response,
+// identifying the source and subscription it was received from.
+func flatPrefix(msg proto.Message, meta map[string]string) string {
+	if _, ok := msg.ProtoReflect().Interface().(*gnmi.SubscribeResponse); !ok {
+		return ""
+	}
+	sub, ok := meta["subscription-name"]
+	if !ok {
+		return ""
+	}
+	if src, ok := meta["source"]; ok {
+		return fmt.Sprintf("[%s/%s] ", src, sub)
+	}
+	return fmt.Sprintf("[%s] ", sub)
+}
+
+// eventsNumToString converts the integer values of the events to strings
+// if NumAsString is set.
+func (o *MarshalOptions) eventsNumToString(evs []*EventMsg) {
+	if !o.NumAsString {
+		return
+	}
+	for _, e := range evs {
+		for k, v := range e.Values {
+			e.Values[k] = numToString(v)
+		}
+	}
+}
+
 func (o *MarshalOptions) OverrideTimestamp(msg proto.Message) proto.Message {
 	if o.OverrideTS {
 		ts := time.Now().UnixNano()
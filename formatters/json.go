@@ -9,15 +9,46 @@
 package formatters
 
 import (
+	"bytes"
 	"encoding/json"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/utils"
 	"google.golang.org/protobuf/proto"
 )
 
+// jsonMetaKnownKeys are the meta keys either mapped to a dedicated field
+// of the JSON subscribe response or not relevant to it.
+// The remaining keys (e.g. event tags) are added under the "meta" field.
+var jsonMetaKnownKeys = map[string]struct{}{
+	"source":              {},
+	"system-name":         {},
+	"subscription-name":   {},
+	"subscription-mode":   {},
+	"subscription-target": {},
+	"format":              {},
+	"recv-timestamp":      {},
+	"seq":                 {},
+	"run-id":              {},
+}
+
+// jsonExtraMeta returns the meta keys not in jsonMetaKnownKeys, or nil if there is none.
+func jsonExtraMeta(meta map[string]string) map[string]interface{} {
+	var extra map[string]interface{}
+	for k, v := range meta {
+		if _, ok := jsonMetaKnownKeys[k]; ok {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[k] = v
+	}
+	return extra
+}
+
 // FormatJSON formats a proto.Message and returns a []byte and an error
 func (o *MarshalOptions) FormatJSON(m proto.Message, meta map[string]string) ([]byte, error) {
 	if m == nil {
@@ -27,7 +58,7 @@ func (o *MarshalOptions) FormatJSON(m proto.Message, meta map[string]string) ([]
 	case *gnmi.CapabilityRequest:
 		return o.formatCapabilitiesRequest(m)
 	case *gnmi.CapabilityResponse:
-		return o.formatCapabilitiesResponse(m)
+		return o.formatCapabilitiesResponse(m, meta)
 	case *gnmi.GetRequest:
 		return o.formatGetRequest(m)
 	case *gnmi.GetResponse:
@@ -79,10 +110,7 @@ func (o *MarshalOptions) formatsubscribeRequest(m *gnmi.SubscribeRequest) ([]byt
 		// 		msg.Aliases[a.Alias] = utils.GnmiPathToXPath(a.Path, false)
 		// 	}
 	}
-	if o.Multiline {
-		return json.MarshalIndent(msg, "", o.Indent)
-	}
-	return json.Marshal(msg)
+	return o.marshalJSON(msg)
 }
 
 func (o *MarshalOptions) formatSubscribeResponse(m *gnmi.SubscribeResponse, meta map[string]string) ([]byte, error) {
@@ -91,13 +119,16 @@ func (o *MarshalOptions) formatSubscribeResponse(m *gnmi.SubscribeResponse, meta
 		msg := NotificationRspMsg{
 			Timestamp: m.Update.Timestamp,
 		}
-		t := time.Unix(0, m.Update.Timestamp)
+		t := o.timestamp(m.Update.Timestamp)
 		msg.Time = &t
 		if meta == nil {
 			meta = make(map[string]string)
 		}
 		msg.Prefix = utils.GnmiPathToXPath(m.Update.GetPrefix(), false)
 		msg.Target = m.Update.Prefix.GetTarget()
+		if o.IncludeMeta {
+			msg.Origin = m.Update.GetPrefix().GetOrigin()
+		}
 		if s, ok := meta["source"]; ok {
 			msg.Source = s
 		}
@@ -107,6 +138,17 @@ func (o *MarshalOptions) formatSubscribeResponse(m *gnmi.SubscribeResponse, meta
 		if s, ok := meta["subscription-name"]; ok {
 			msg.SubscriptionName = s
 		}
+		if s, ok := meta["subscription-mode"]; ok {
+			msg.SubscriptionMode = s
+		}
+		if s, ok := meta["recv-timestamp"]; ok {
+			msg.RecvTimestamp, _ = strconv.ParseInt(s, 10, 64)
+		}
+		if s, ok := meta["seq"]; ok {
+			msg.Seq, _ = strconv.ParseUint(s, 10, 64)
+		}
+		msg.RunID = meta["run-id"]
+		msg.Meta = jsonExtraMeta(meta)
 		for i, upd := range m.Update.Update {
 			if upd.Path == nil {
 				upd.Path = new(gnmi.Path)
@@ -119,6 +161,9 @@ func (o *MarshalOptions) formatSubscribeResponse(m *gnmi.SubscribeResponse, meta
 			if err != nil {
 				return nil, err
 			}
+			if o.NumAsString {
+				value = numToString(value)
+			}
 			msg.Updates = append(msg.Updates,
 				update{
 					Path:   utils.GnmiPathToXPath(upd.Path, false),
@@ -129,10 +174,7 @@ func (o *MarshalOptions) formatSubscribeResponse(m *gnmi.SubscribeResponse, meta
 		for _, del := range m.Update.Delete {
 			msg.Deletes = append(msg.Deletes, utils.GnmiPathToXPath(del, false))
 		}
-		if o.Multiline {
-			return json.MarshalIndent(msg, "", o.Indent)
-		}
-		return json.Marshal(msg)
+		return o.marshalJSON(msg)
 	}
 	return nil, nil
 }
@@ -144,14 +186,14 @@ func (o *MarshalOptions) formatCapabilitiesRequest(m *gnmi.CapabilityRequest) ([
 	for _, e := range m.Extension {
 		capReq.Extensions = append(capReq.Extensions, e.String())
 	}
-	if o.Multiline {
-		return json.MarshalIndent(capReq, "", o.Indent)
-	}
-	return json.Marshal(capReq)
+	return o.marshalJSON(capReq)
 }
 
-func (o *MarshalOptions) formatCapabilitiesResponse(m *gnmi.CapabilityResponse) ([]byte, error) {
+func (o *MarshalOptions) formatCapabilitiesResponse(m *gnmi.CapabilityResponse, meta map[string]string) ([]byte, error) {
 	capRspMsg := capResponse{}
+	if o.IncludeMeta {
+		capRspMsg.Meta = jsonExtraMeta(meta)
+	}
 	capRspMsg.Version = m.GetGNMIVersion()
 	for _, sm := range m.SupportedModels {
 		capRspMsg.SupportedModels = append(capRspMsg.SupportedModels,
@@ -164,10 +206,7 @@ func (o *MarshalOptions) formatCapabilitiesResponse(m *gnmi.CapabilityResponse)
 	for _, se := range m.SupportedEncodings {
 		capRspMsg.Encodings = append(capRspMsg.Encodings, se.String())
 	}
-	if o.Multiline {
-		return json.MarshalIndent(capRspMsg, "", o.Indent)
-	}
-	return json.Marshal(capRspMsg)
+	return o.marshalJSON(capRspMsg)
 }
 
 func (o *MarshalOptions) formatGetRequest(m *gnmi.GetRequest) ([]byte, error) {
@@ -189,10 +228,7 @@ func (o *MarshalOptions) formatGetRequest(m *gnmi.GetRequest) ([]byte, error) {
 				Version:      um.GetVersion(),
 			})
 	}
-	if o.Multiline {
-		return json.MarshalIndent(msg, "", o.Indent)
-	}
-	return json.Marshal(msg)
+	return o.marshalJSON(msg)
 }
 
 func (o *MarshalOptions) formatGetResponse(m *gnmi.GetResponse, meta map[string]string) ([]byte, error) {
@@ -204,16 +240,22 @@ func (o *MarshalOptions) formatGetResponse(m *gnmi.GetResponse, meta map[string]
 			Deletes: make([]string, 0, len(notif.GetDelete())),
 		}
 		msg.Timestamp = notif.Timestamp
-		t := time.Unix(0, notif.Timestamp)
+		t := o.timestamp(notif.Timestamp)
 		msg.Time = &t
 		if meta == nil {
 			meta = make(map[string]string)
 		}
 		msg.Prefix = utils.GnmiPathToXPath(notif.GetPrefix(), false)
 		msg.Target = notif.GetPrefix().GetTarget()
+		if o.IncludeMeta {
+			msg.Origin = notif.GetPrefix().GetOrigin()
+		}
 		if s, ok := meta["source"]; ok {
 			msg.Source = s
 		}
+		if o.IncludeMeta {
+			msg.Meta = jsonExtraMeta(meta)
+		}
 		for i, upd := range notif.GetUpdate() {
 			pathElems := make([]string, 0, len(upd.GetPath().GetElem()))
 			for _, pElem := range upd.GetPath().GetElem() {
@@ -223,6 +265,9 @@ func (o *MarshalOptions) formatGetResponse(m *gnmi.GetResponse, meta map[string]
 			if err != nil {
 				return nil, err
 			}
+			if o.NumAsString {
+				value = numToString(value)
+			}
 			msg.Updates = append(msg.Updates,
 				update{
 					Path:   utils.GnmiPathToXPath(upd.GetPath(), false),
@@ -235,21 +280,7 @@ func (o *MarshalOptions) formatGetResponse(m *gnmi.GetResponse, meta map[string]
 		}
 		notifications = append(notifications, msg)
 	}
-	if o.ValuesOnly {
-		result := make([]interface{}, 0, len(notifications))
-		for _, n := range notifications {
-			for _, u := range n.Updates {
-				for _, v := range u.Values {
-					result = append(result, v)
-				}
-			}
-		}
-		return json.MarshalIndent(result, "", "  ")
-	}
-	if o.Multiline {
-		return json.MarshalIndent(notifications, "", o.Indent)
-	}
-	return json.Marshal(notifications)
+	return o.marshalJSON(notifications)
 }
 
 func (o *MarshalOptions) formatSetRequest(m *gnmi.SetRequest) ([]byte, error) {
@@ -279,10 +310,7 @@ func (o *MarshalOptions) formatSetRequest(m *gnmi.SetRequest) ([]byte, error) {
 			Val:  upd.Val.String(),
 		})
 	}
-	if o.Multiline {
-		return json.MarshalIndent(req, "", o.Indent)
-	}
-	return json.Marshal(req)
+	return o.marshalJSON(req)
 }
 
 func (o *MarshalOptions) formatSetResponse(m *gnmi.SetResponse, meta map[string]string) ([]byte, error) {
@@ -290,7 +318,7 @@ func (o *MarshalOptions) formatSetResponse(m *gnmi.SetResponse, meta map[string]
 	msg.Prefix = utils.GnmiPathToXPath(m.GetPrefix(), false)
 	msg.Target = m.GetPrefix().GetTarget()
 	msg.Timestamp = m.Timestamp
-	msg.Time = time.Unix(0, m.Timestamp)
+	msg.Time = o.timestamp(m.Timestamp)
 	if meta == nil {
 		meta = make(map[string]string)
 	}
@@ -298,15 +326,50 @@ func (o *MarshalOptions) formatSetResponse(m *gnmi.SetResponse, meta map[string]
 	if s, ok := meta["source"]; ok {
 		msg.Source = s
 	}
+	if o.IncludeMeta {
+		msg.Meta = jsonExtraMeta(meta)
+	}
 	for _, u := range m.GetResponse() {
-		msg.Results = append(msg.Results, updateResultMsg{
-			Operation: u.Op.String(),
-			Path:      utils.GnmiPathToXPath(u.GetPath(), false),
-			Target:    u.GetPath().GetTarget(),
-		})
+		msg.Results = append(msg.Results, setResultMsg(u))
 	}
-	if o.Multiline {
-		return json.MarshalIndent(msg, "", o.Indent)
+	msg.Error = newGNMIErrorMsg(SetResponseError(m))
+	return o.marshalJSON(msg)
+}
+
+// MarshalJSON returns the JSON encoding of v with all its object keys sorted,
+// so that the output is byte-stable.
+// The output is indented with indent, or compact if indent is empty.
+func MarshalJSON(v interface{}, indent string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(msg)
+	return normalizeJSON(b, indent)
+}
+
+// normalizeJSON sorts the object keys of the JSON document b and indents it.
+func normalizeJSON(b []byte, indent string) ([]byte, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// keep numbers as is
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	if indent == "" {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", indent)
+}
+
+func (o *MarshalOptions) jsonIndent() string {
+	if !o.Multiline {
+		return ""
+	}
+	return o.Indent
+}
+
+func (o *MarshalOptions) marshalJSON(v interface{}) ([]byte, error) {
+	return MarshalJSON(v, o.jsonIndent())
 }
@@ -0,0 +1,344 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+const goldenTimestamp = 1672531200000000000 // 2023-01-01T00:00:00Z
+
+var goldenCapResponse = &gnmi.CapabilityResponse{
+	GNMIVersion: "0.8.0",
+	SupportedModels: []*gnmi.ModelData{
+		{Name: "nokia-conf", Organization: "Nokia", Version: "22.10.R1"},
+		{Name: "openconfig-interfaces", Organization: "OpenConfig working group", Version: "2.4.3"},
+	},
+	SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF, gnmi.Encoding_ASCII},
+}
+
+var goldenTestSet = map[string]struct {
+	mo   *MarshalOptions
+	msg  proto.Message
+	meta map[string]string
+}{
+	"capabilities_response": {
+		mo:  &MarshalOptions{Multiline: true, Indent: "  "},
+		msg: goldenCapResponse,
+	},
+	"capabilities_response_protojson": {
+		mo:  &MarshalOptions{Format: "protojson"},
+		msg: goldenCapResponse,
+	},
+	"get_response": {
+		mo: &MarshalOptions{Multiline: true, Indent: "  "},
+		msg: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Timestamp: goldenTimestamp,
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "interfaces"},
+								{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+								{Name: "state"},
+							}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+								JsonVal: []byte(`{"oper-status":"UP","mtu":9000,"counters":{"out-octets":"200","in-octets":"100"}}`),
+							}},
+						},
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "interfaces"},
+								{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+								{Name: "description"},
+							}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "uplink"}},
+						},
+					},
+				},
+			},
+		},
+		meta: map[string]string{"source": "router1"},
+	},
+	"subscribe_response_compact": {
+		mo: &MarshalOptions{},
+		msg: &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{
+					Timestamp: goldenTimestamp,
+					Prefix: &gnmi.Path{
+						Target: "t1",
+						Elem: []*gnmi.PathElem{
+							{Name: "interfaces"},
+							{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+						},
+					},
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "statistics"}, {Name: "in-octets"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}},
+						},
+					},
+				},
+			},
+		},
+		meta: map[string]string{
+			"source":            "router1",
+			"subscription-name": "sub1",
+			"subscription-mode": "STREAM",
+			"region":            "emea",
+		},
+	},
+}
+
+func TestMarshalGolden(t *testing.T) {
+	// the JSON time field is formatted in the local time zone
+	loc := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = loc }()
+
+	for name, item := range goldenTestSet {
+		t.Run(name, func(t *testing.T) {
+			b, err := item.mo.Marshal(item.msg, item.meta)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			b = append(b, '\n')
+			goldenFile := filepath.Join("testdata", name+".golden")
+			if *updateGolden {
+				err = os.WriteFile(goldenFile, b, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, expected) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %s", expected)
+				t.Logf("     got: %s", b)
+				t.Fail()
+			}
+			// output is byte-stable
+			for i := 0; i < 10; i++ {
+				nb, err := item.mo.Marshal(item.msg, item.meta)
+				if err != nil {
+					t.Fatalf("failed to marshal: %v", err)
+				}
+				if !bytes.Equal(append(nb, '\n'), b) {
+					t.Fatalf("output changed between runs: %s", nb)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	v := map[string]interface{}{
+		"b": []interface{}{1, "x"},
+		"a": struct {
+			Z string `json:"z"`
+			Y int    `json:"y"`
+		}{Z: "z", Y: 1},
+	}
+	b, err := MarshalJSON(v, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"a":{"y":1,"z":"z"},"b":[1,"x"]}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+	b, err = MarshalJSON(v, "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = "{\n\t\"a\": {\n\t\t\"y\": 1,\n\t\t\"z\": \"z\"\n\t},\n\t\"b\": [\n\t\t1,\n\t\t\"x\"\n\t]\n}"
+	if string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
+
+var numAsStringTestSet = map[string]*gnmi.TypedValue{
+	"2^53-1":      {Value: &gnmi.TypedValue_UintVal{UintVal: 1<<53 - 1}},
+	"2^53":        {Value: &gnmi.TypedValue_UintVal{UintVal: 1 << 53}},
+	"2^53+1":      {Value: &gnmi.TypedValue_UintVal{UintVal: 1<<53 + 1}},
+	"max_uint64":  {Value: &gnmi.TypedValue_UintVal{UintVal: math.MaxUint64}},
+	"-(2^53+1)":   {Value: &gnmi.TypedValue_IntVal{IntVal: -(1<<53 + 1)}},
+	"min_int64":   {Value: &gnmi.TypedValue_IntVal{IntVal: math.MinInt64}},
+	"leaf_list":   {Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{Element: []*gnmi.TypedValue{{Value: &gnmi.TypedValue_UintVal{UintVal: math.MaxUint64}}}}}},
+	"float_value": {Value: &gnmi.TypedValue_DoubleVal{DoubleVal: 1.5}},
+}
+
+func numAsStringExpected(tv *gnmi.TypedValue) interface{} {
+	switch v := tv.Value.(type) {
+	case *gnmi.TypedValue_UintVal:
+		return strconv.FormatUint(v.UintVal, 10)
+	case *gnmi.TypedValue_IntVal:
+		return strconv.FormatInt(v.IntVal, 10)
+	case *gnmi.TypedValue_LeaflistVal:
+		l := make([]interface{}, 0)
+		for _, e := range v.LeaflistVal.GetElement() {
+			l = append(l, numAsStringExpected(e))
+		}
+		return l
+	}
+	return json.Number("1.5")
+}
+
+func TestNumAsString(t *testing.T) {
+	for name, tv := range numAsStringTestSet {
+		t.Run(name, func(t *testing.T) {
+			rsp := &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{
+					Update: &gnmi.Notification{
+						Timestamp: goldenTimestamp,
+						Update: []*gnmi.Update{{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+							Val:  tv,
+						}},
+					},
+				},
+			}
+			for _, format := range []string{"json", "event"} {
+				mo := &MarshalOptions{Format: format, NumAsString: true}
+				b, err := mo.Marshal(rsp, map[string]string{"source": "t1", "subscription-name": "sub1"})
+				if err != nil {
+					t.Fatalf("format %s: failed to marshal: %v", format, err)
+				}
+				d := json.NewDecoder(bytes.NewReader(b))
+				d.UseNumber()
+				var v interface{}
+				switch format {
+				case "json":
+					msg := new(NotificationRspMsg)
+					err = d.Decode(msg)
+					if err == nil {
+						v = msg.Updates[0].Values["counter"]
+					}
+				case "event":
+					evs := make([]*EventMsg, 0)
+					err = d.Decode(&evs)
+					if err == nil {
+						v = evs[0].Values["/counter"]
+					}
+				}
+				if err != nil {
+					t.Fatalf("format %s: failed to decode %s: %v", format, string(b), err)
+				}
+				if !reflect.DeepEqual(v, numAsStringExpected(tv)) {
+					t.Logf("failed at item %q", name)
+					t.Errorf("format %s: expected %#v, got %#v", format, numAsStringExpected(tv), v)
+				}
+			}
+		})
+	}
+}
+
+func TestIncludeMeta(t *testing.T) {
+	notif := &gnmi.Notification{
+		Timestamp: goldenTimestamp,
+		Prefix:    &gnmi.Path{Origin: "openconfig", Target: "t1", Elem: []*gnmi.PathElem{{Name: "system"}}},
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "hostname"}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "r1"}},
+		}},
+	}
+	msgs := map[string]proto.Message{
+		"subscribe_response": &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notif}},
+		"get_response":       &gnmi.GetResponse{Notification: []*gnmi.Notification{notif}},
+	}
+	wantFlat := `notification: prefix="openconfig:system" origin="openconfig" target="t1"` + "\n" +
+		"openconfig:system/hostname: r1\n"
+	for name, msg := range msgs {
+		t.Run(name, func(t *testing.T) {
+			for _, includeMeta := range []bool{false, true} {
+				mo := &MarshalOptions{Format: "json", IncludeMeta: includeMeta}
+				b, err := mo.Marshal(msg, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var nm NotificationRspMsg
+				switch msg.(type) {
+				case *gnmi.GetResponse:
+					nms := make([]NotificationRspMsg, 0)
+					err = json.Unmarshal(b, &nms)
+					if err == nil && len(nms) == 1 {
+						nm = nms[0]
+					}
+				default:
+					err = json.Unmarshal(b, &nm)
+				}
+				if err != nil {
+					t.Fatalf("failed to decode %s: %v", string(b), err)
+				}
+				wantOrigin := ""
+				if includeMeta {
+					wantOrigin = "openconfig"
+				}
+				if nm.Origin != wantOrigin || nm.Target != "t1" {
+					t.Logf("failed at item %q, include-meta=%v", name, includeMeta)
+					t.Errorf("unexpected origin=%q, target=%q", nm.Origin, nm.Target)
+				}
+			}
+			mo := &MarshalOptions{Format: "flat", IncludeMeta: true}
+			b, err := mo.Marshal(msg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != wantFlat {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", wantFlat)
+				t.Logf("     got: %q", string(b))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestIncludeMetaResponseMetadata(t *testing.T) {
+	meta := map[string]string{"source": "t1", "header:sw-version": "23.3.1"}
+	msgs := map[string]proto.Message{
+		"get_response":          &gnmi.GetResponse{Notification: []*gnmi.Notification{{Timestamp: goldenTimestamp}}},
+		"set_response":          &gnmi.SetResponse{Timestamp: goldenTimestamp},
+		"capabilities_response": &gnmi.CapabilityResponse{GNMIVersion: "0.8.0"},
+	}
+	for name, msg := range msgs {
+		for _, includeMeta := range []bool{false, true} {
+			mo := &MarshalOptions{Format: "json", IncludeMeta: includeMeta}
+			b, err := mo.Marshal(msg, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := bytes.Contains(b, []byte(`"meta":{"header:sw-version":"23.3.1"}`))
+			if found != includeMeta {
+				t.Logf("failed at item %q, include-meta=%v", name, includeMeta)
+				t.Errorf("unexpected output: %s", string(b))
+			}
+		}
+	}
+}
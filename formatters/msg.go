@@ -10,6 +10,7 @@ package formatters
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -20,10 +21,15 @@ type NotificationRspMsg struct {
 	Source           string                 `json:"source,omitempty"`
 	SystemName       string                 `json:"system-name,omitempty"`
 	SubscriptionName string                 `json:"subscription-name,omitempty"`
+	SubscriptionMode string                 `json:"subscription-mode,omitempty"`
 	Timestamp        int64                  `json:"timestamp,omitempty"`
 	Time             *time.Time             `json:"time,omitempty"`
+	RecvTimestamp    int64                  `json:"recv-timestamp,omitempty"`
+	Seq              uint64                 `json:"seq,omitempty"`
+	RunID            string                 `json:"run-id,omitempty"`
 	Prefix           string                 `json:"prefix,omitempty"`
 	Target           string                 `json:"target,omitempty"`
+	Origin           string                 `json:"origin,omitempty"`
 	Updates          []update               `json:"updates,omitempty"`
 	Deletes          []string               `json:"deletes,omitempty"`
 }
@@ -35,9 +41,11 @@ type capRequest struct {
 	Extensions []string `json:"extensions,omitempty"`
 }
 type capResponse struct {
-	Version         string   `json:"version,omitempty"`
+	Version         string   `json:"version"`
 	SupportedModels []model  `json:"supported-models,omitempty"`
 	Encodings       []string `json:"encodings,omitempty"`
+	// response headers and trailers
+	Meta map[string]interface{} `json:"meta,omitempty"`
 }
 type model struct {
 	Name         string `json:"name,omitempty"`
@@ -61,12 +69,25 @@ type setRspMsg struct {
 	Prefix    string            `json:"prefix,omitempty"`
 	Target    string            `json:"target,omitempty"`
 	Results   []updateResultMsg `json:"results,omitempty"`
+	Error     *gnmiErrorMsg     `json:"error,omitempty"`
+	// response headers and trailers
+	Meta map[string]interface{} `json:"meta,omitempty"`
 }
 
 type updateResultMsg struct {
-	Operation string `json:"operation,omitempty"`
-	Path      string `json:"path,omitempty"`
-	Target    string `json:"target,omitempty"`
+	Operation string        `json:"operation,omitempty"`
+	Path      string        `json:"path,omitempty"`
+	Target    string        `json:"target,omitempty"`
+	Timestamp int64         `json:"timestamp,omitempty"`
+	Result    string        `json:"result,omitempty"`
+	Error     *gnmiErrorMsg `json:"error,omitempty"`
+}
+
+// gnmiErrorMsg is the JSON representation of the deprecated gnmi.Error message,
+// still sent by some targets in the SetResponse and its UpdateResults.
+type gnmiErrorMsg struct {
+	Code    uint32 `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type setReqMsg struct {
@@ -109,6 +130,41 @@ type subscription struct {
 	HeartbeatInterval uint64 `json:"heartbeat-interval,omitempty"`
 }
 
+// numToString returns the integers in v as strings,
+// JSON decoders using float64 numbers lose precision above 2^53.
+func numToString(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, vv := range v {
+			l = append(l, numToString(vv))
+		}
+		return l
+	case *gnmi.ScalarArray:
+		// leaf-list values are returned by getValue as a ScalarArray
+		l := make([]interface{}, 0, len(v.GetElement()))
+		for _, tv := range v.GetElement() {
+			ev, err := getValue(tv)
+			if err != nil {
+				return v
+			}
+			l = append(l, numToString(ev))
+		}
+		return l
+	}
+	return v
+}
+
+// GetValue returns the value of a gNMI TypedValue,
+// JSON encoded values are decoded.
+func GetValue(updValue *gnmi.TypedValue) (interface{}, error) {
+	return getValue(updValue)
+}
+
 func getValue(updValue *gnmi.TypedValue) (interface{}, error) {
 	if updValue == nil {
 		return nil, nil
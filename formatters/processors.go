@@ -39,6 +39,8 @@ var EventProcessorTypes = []string{
 	"event-data-convert",
 	"event-value-tag",
 	"event-starlark",
+	"event-rate",
+	"event-convert-scale",
 }
 
 type Initializer func() EventProcessor
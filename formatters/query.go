@@ -0,0 +1,72 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// Query is a compiled jq expression applied to JSON documents.
+type Query struct {
+	code *gojq.Code
+	// Raw prints the string results without quotes.
+	Raw bool
+}
+
+// NewQuery parses and compiles the jq expression expr.
+func NewQuery(expr string, raw bool) (*Query, error) {
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query %q: %v", expr, err)
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile query %q: %v", expr, err)
+	}
+	return &Query{code: code, Raw: raw}, nil
+}
+
+// Apply runs the query on the JSON document b and returns its results separated by a new line.
+// The results are indented with indent, the strings are not quoted if the query is raw.
+// It returns an empty slice if the query has no result.
+func (q *Query) Apply(b []byte, indent string) ([]byte, error) {
+	var input interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// keep numbers as is
+	dec.UseNumber()
+	err := dec.Decode(&input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode query input: %v", err)
+	}
+	results := make([][]byte, 0)
+	iter := q.code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+		if s, ok := v.(string); ok && q.Raw {
+			results = append(results, []byte(s))
+			continue
+		}
+		r, err := MarshalJSON(v, indent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal query result: %v", err)
+		}
+		results = append(results, r)
+	}
+	return bytes.Join(results, []byte("\n")), nil
+}
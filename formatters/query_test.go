@@ -0,0 +1,99 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"testing"
+)
+
+var queryTestSet = map[string]struct {
+	expr   string
+	raw    bool
+	indent string
+	in     string
+	out    string
+	err    bool
+}{
+	"identity": {
+		expr: ".",
+		in:   `{"b":1,"a":"x"}`,
+		out:  `{"a":"x","b":1}`,
+	},
+	"select": {
+		expr: `.updates[].values | to_entries[] | select(.value > 100) | .key`,
+		in:   `{"updates":[{"values":{"in-octets":150}},{"values":{"out-octets":50}}]}`,
+		out:  `"in-octets"`,
+	},
+	"select_raw": {
+		expr: `.updates[].values | to_entries[] | select(.value > 100) | .key`,
+		raw:  true,
+		in:   `{"updates":[{"values":{"in-octets":150}},{"values":{"out-octets":500}}]}`,
+		out:  "in-octets\nout-octets",
+	},
+	"raw_non_string": {
+		expr: `.a`,
+		raw:  true,
+		in:   `{"a":{"b":true}}`,
+		out:  `{"b":true}`,
+	},
+	"indent": {
+		expr:   `.a`,
+		indent: "  ",
+		in:     `{"a":{"b":1}}`,
+		out:    "{\n  \"b\": 1\n}",
+	},
+	"large_number": {
+		expr: `.a`,
+		in:   `{"a":18446744073709551615}`,
+		out:  `18446744073709551615`,
+	},
+	"no_result": {
+		expr: `.a | select(. > 1)`,
+		in:   `{"a":1}`,
+		out:  ``,
+	},
+	"runtime_error": {
+		expr: `.a | keys`,
+		in:   `{"a":1}`,
+		err:  true,
+	},
+}
+
+func TestQueryApply(t *testing.T) {
+	for name, item := range queryTestSet {
+		q, err := NewQuery(item.expr, item.raw)
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		b, err := q.Apply([]byte(item.in), item.indent)
+		if item.err {
+			if err == nil {
+				t.Errorf("failed at item %q: expected an error, got %s", name, string(b))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		if string(b) != item.out {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.out, string(b))
+		}
+	}
+}
+
+func TestNewQueryError(t *testing.T) {
+	for _, expr := range []string{".a |", "undefined_func(1)", "{"} {
+		_, err := NewQuery(expr, false)
+		if err == nil {
+			t.Errorf("failed at item %q: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/utils"
+)
+
+const (
+	setResultOK     = "ok"
+	setResultFailed = "failed"
+)
+
+// SetResultError returns the error reported by a target for an UpdateResult
+// in its deprecated message field, or nil if the operation succeeded.
+func SetResultError(u *gnmi.UpdateResult) *gnmi.Error {
+	if e := u.GetMessage(); e != nil && (e.GetCode() != 0 || e.GetMessage() != "") {
+		return e
+	}
+	return nil
+}
+
+// SetResponseError returns the message level error of a SetResponse,
+// or nil if there is none.
+func SetResponseError(m *gnmi.SetResponse) *gnmi.Error {
+	if e := m.GetMessage(); e != nil && (e.GetCode() != 0 || e.GetMessage() != "") {
+		return e
+	}
+	return nil
+}
+
+func newGNMIErrorMsg(e *gnmi.Error) *gnmiErrorMsg {
+	if e == nil {
+		return nil
+	}
+	return &gnmiErrorMsg{Code: e.GetCode(), Message: e.GetMessage()}
+}
+
+func setResultMsg(u *gnmi.UpdateResult) updateResultMsg {
+	rm := updateResultMsg{
+		Operation: u.GetOp().String(),
+		Path:      utils.GnmiPathToXPath(u.GetPath(), false),
+		Target:    u.GetPath().GetTarget(),
+		Timestamp: u.GetTimestamp(),
+		Result:    setResultOK,
+	}
+	if e := SetResultError(u); e != nil {
+		rm.Result = setResultFailed
+		rm.Error = newGNMIErrorMsg(e)
+	}
+	return rm
+}
+
+// setResponseFlat formats a SetResponse as text,
+// one line per operation result in the order they were received.
+func (o *MarshalOptions) setResponseFlat(m *gnmi.SetResponse) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "timestamp: %d (%s)\n", m.GetTimestamp(), o.timestamp(m.GetTimestamp()).Format(time.RFC3339Nano))
+	if p := utils.GnmiPathToXPath(m.GetPrefix(), false); p != "" {
+		fmt.Fprintf(buf, "prefix: %s\n", p)
+	}
+	for _, u := range m.GetResponse() {
+		rm := setResultMsg(u)
+		fmt.Fprintf(buf, "%s %s: %s", rm.Operation, rm.Path, rm.Result)
+		if rm.Error != nil {
+			fmt.Fprintf(buf, ": code %d: %s", rm.Error.Code, rm.Error.Message)
+		}
+		buf.WriteString("\n")
+	}
+	if e := SetResponseError(m); e != nil {
+		fmt.Fprintf(buf, "error: code %d: %s\n", e.GetCode(), e.GetMessage())
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,78 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// valuesOnly returns the values of the updates in msg, one value per line,
+// in the order they appear in the message.
+// Complex values are printed as compact JSON.
+func valuesOnly(msg proto.Message) ([]byte, error) {
+	var notifications []*gnmi.Notification
+	switch msg := msg.ProtoReflect().Interface().(type) {
+	case *gnmi.GetResponse:
+		notifications = msg.GetNotification()
+	case *gnmi.SubscribeResponse:
+		if n := msg.GetUpdate(); n != nil {
+			notifications = append(notifications, n)
+		}
+	default:
+		return nil, fmt.Errorf("values only not supported for msg type %T", msg)
+	}
+	buf := new(bytes.Buffer)
+	for _, n := range notifications {
+		for _, upd := range n.GetUpdate() {
+			s, err := valueString(upd.GetVal())
+			if err != nil {
+				return nil, err
+			}
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(s)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func valueString(tv *gnmi.TypedValue) (string, error) {
+	if ll := tv.GetLeaflistVal(); ll != nil {
+		values := make([]interface{}, 0, len(ll.GetElement()))
+		for _, e := range ll.GetElement() {
+			v, err := getValue(e)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, v)
+		}
+		b, err := MarshalJSON(values, "")
+		return string(b), err
+	}
+	v, err := getValue(tv)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}:
+		b, err := MarshalJSON(v, "")
+		return string(b), err
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
@@ -0,0 +1,93 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+var valuesOnlyTestSet = map[string]struct {
+	msg proto.Message
+	out string
+}{
+	"get_response": {
+		msg: &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "ifindex"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}},
+						},
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "description"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "uplink 1"}},
+						},
+					},
+				},
+				{
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "state"}}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+								JsonVal: []byte("{\n  \"admin-state\": \"enable\",\n  \"mtu\": 9000\n}"),
+							}},
+						},
+					},
+				},
+			},
+		},
+		out: "42\nuplink 1\n{\"admin-state\":\"enable\",\"mtu\":9000}",
+	},
+	"subscribe_response_leaflist": {
+		msg: &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "servers"}}},
+							Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{
+								LeaflistVal: &gnmi.ScalarArray{Element: []*gnmi.TypedValue{
+									{Value: &gnmi.TypedValue_StringVal{StringVal: "1.1.1.1"}},
+									{Value: &gnmi.TypedValue_StringVal{StringVal: "8.8.8.8"}},
+								}},
+							}},
+						},
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "enabled"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: true}},
+						},
+					},
+				},
+			},
+		},
+		out: "[\"1.1.1.1\",\"8.8.8.8\"]\ntrue",
+	},
+}
+
+func TestValuesOnly(t *testing.T) {
+	for name, item := range valuesOnlyTestSet {
+		t.Run(name, func(t *testing.T) {
+			mo := &MarshalOptions{Multiline: true, Indent: "  ", ValuesOnly: true}
+			b, err := mo.Marshal(item.msg, nil)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			if string(b) != item.out {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %q", item.out)
+				t.Logf("     got: %q", string(b))
+				t.Fail()
+			}
+		})
+	}
+}
@@ -78,6 +78,9 @@ type cfg struct {
 	Password string `mapstructure:"password,omitempty" json:"password,omitempty"`
 	// Consul token
 	Token string `mapstructure:"token,omitempty" json:"token,omitempty"`
+	// TLS config of the connection to Consul,
+	// if set, HTTPS is used
+	TLS *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
 	// enable debug
 	Debug bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
 	// KV based target config loading
@@ -107,6 +110,12 @@ type serviceDef struct {
 	Config map[string]interface{} `mapstructure:"config,omitempty" json:"config,omitempty"`
 }
 
+// serviceUpdate holds the current instances of a watched service.
+type serviceUpdate struct {
+	service *serviceDef
+	entries []*api.ServiceEntry
+}
+
 func (c *consulLoader) Init(ctx context.Context, cfg map[string]interface{}, logger *log.Logger, opts ...loaders.Option) error {
 	err := loaders.DecodeConfig(cfg, c.cfg)
 	if err != nil {
@@ -166,23 +175,33 @@ CLIENT:
 		time.Sleep(2 * time.Second)
 		goto CLIENT
 	}
-	sChan := make(chan []*api.ServiceEntry)
+	sChan := make(chan *serviceUpdate)
 	go func() {
+		// the targets set is built from the latest instances of all the watched services,
+		// so that an update of one service does not remove the targets of the others.
+		entries := make(map[*serviceDef][]*api.ServiceEntry)
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case ses, ok := <-sChan:
+			case su, ok := <-sChan:
 				if !ok {
 					return
 				}
+				entries[su.service] = su.entries
 				tcs := make(map[string]*types.TargetConfig)
-				for _, se := range ses {
-					tc, err := c.serviceEntryToTargetConfig(se)
-					if err != nil {
-						c.logger.Printf("Failed to convert service entry %+v to a target config: %v", se, err)
+				for _, ses := range entries {
+					for _, se := range ses {
+						tc, err := c.serviceEntryToTargetConfig(se)
+						if err != nil {
+							c.logger.Printf("Failed to convert service entry %+v to a target config: %v", se, err)
+							continue
+						}
+						if tc == nil || tc.Name == "" {
+							continue
+						}
+						tcs[tc.Name] = tc
 					}
-					tcs[tc.Name] = tc
 				}
 				c.updateTargets(ctx, tcs, opChan)
 			}
@@ -190,7 +209,7 @@ CLIENT:
 	}()
 	for _, s := range c.cfg.Services {
 		go func(s *serviceDef) {
-			err := c.startServicesWatch(ctx, s.Name, s.Tags, sChan, time.Minute)
+			err := c.startServicesWatch(ctx, s, sChan, time.Minute)
 			if err != nil {
 				c.logger.Printf("service %q watch stopped: %v", s.Name, err)
 			}
@@ -235,6 +254,10 @@ func (c *consulLoader) RunOnce(ctx context.Context) (map[string]*types.TargetCon
 				tc, err := c.serviceEntryToTargetConfig(se)
 				if err != nil {
 					c.logger.Printf("failed to convert service %+v to target config: %v", se, err)
+					continue
+				}
+				if tc == nil || tc.Name == "" {
+					continue
 				}
 				result[tc.Name] = tc
 			case <-ctx.Done():
@@ -272,6 +295,15 @@ func (c *consulLoader) initClient() error {
 			Password: c.cfg.Password,
 		}
 	}
+	if c.cfg.TLS != nil {
+		clientConfig.Scheme = "https"
+		clientConfig.TLSConfig = api.TLSConfig{
+			CAFile:             c.cfg.TLS.CaFile,
+			CertFile:           c.cfg.TLS.CertFile,
+			KeyFile:            c.cfg.TLS.KeyFile,
+			InsecureSkipVerify: c.cfg.TLS.SkipVerify,
+		}
+	}
 	c.client, err = api.NewClient(clientConfig)
 	return err
 }
@@ -292,7 +324,7 @@ func (c *consulLoader) setDefaults() error {
 	return nil
 }
 
-func (c *consulLoader) startServicesWatch(ctx context.Context, serviceName string, tags []string, sChan chan<- []*api.ServiceEntry, watchTimeout time.Duration) error {
+func (c *consulLoader) startServicesWatch(ctx context.Context, s *serviceDef, sChan chan<- *serviceUpdate, watchTimeout time.Duration) error {
 	if watchTimeout <= 0 {
 		watchTimeout = defaultWatchTimeout
 	}
@@ -309,11 +341,11 @@ func (c *consulLoader) startServicesWatch(ctx context.Context, serviceName strin
 			return ctx.Err()
 		default:
 			if c.cfg.Debug {
-				c.logger.Printf("(re)starting watch service=%q, index=%d", serviceName, qOpts.WaitIndex)
+				c.logger.Printf("(re)starting watch service=%q, index=%d", s.Name, qOpts.WaitIndex)
 			}
-			index, err = c.watch(qOpts.WithContext(ctx), serviceName, tags, sChan)
+			index, err = c.watch(qOpts.WithContext(ctx), s, sChan)
 			if err != nil {
-				c.logger.Printf("service %q watch failed: %v", serviceName, err)
+				c.logger.Printf("service %q watch failed: %v", s.Name, err)
 			}
 			if index == 1 {
 				qOpts.WaitIndex = index
@@ -332,22 +364,21 @@ func (c *consulLoader) startServicesWatch(ctx context.Context, serviceName strin
 	}
 }
 
-func (c *consulLoader) watch(qOpts *api.QueryOptions, serviceName string, tags []string, sChan chan<- []*api.ServiceEntry) (uint64, error) {
-	se, meta, err := c.client.Health().ServiceMultipleTags(serviceName, tags, true, qOpts)
+func (c *consulLoader) watch(qOpts *api.QueryOptions, s *serviceDef, sChan chan<- *serviceUpdate) (uint64, error) {
+	se, meta, err := c.client.Health().ServiceMultipleTags(s.Name, s.Tags, true, qOpts)
 	if err != nil {
 		return 0, err
 	}
 	if meta.LastIndex == qOpts.WaitIndex {
-		c.logger.Printf("service=%q did not change", serviceName)
+		c.logger.Printf("service=%q did not change", s.Name)
 		return meta.LastIndex, nil
 	}
-	if err != nil {
-		return meta.LastIndex, err
-	}
 	if len(se) == 0 {
+		// no instances left, remove the service targets
+		sChan <- &serviceUpdate{service: s}
 		return 1, nil
 	}
-	sChan <- se
+	sChan <- &serviceUpdate{service: s, entries: se}
 	return meta.LastIndex, nil
 }
 
@@ -370,6 +401,16 @@ func (c *consulLoader) serviceEntryToTargetConfig(se *api.ServiceEntry) (*types.
 			}
 			tc.Address = net.JoinHostPort(tc.Address, strconv.Itoa(se.Service.Port))
 			tc.Name = se.Service.ID
+			// service metadata become event tags,
+			// unless already set in the service config
+			if len(se.Service.Meta) > 0 && tc.EventTags == nil {
+				tc.EventTags = make(map[string]string, len(se.Service.Meta))
+			}
+			for k, v := range se.Service.Meta {
+				if _, ok := tc.EventTags[k]; !ok {
+					tc.EventTags[k] = v
+				}
+			}
 			return tc, nil
 		}
 	}
@@ -395,9 +436,11 @@ func (c *consulLoader) updateTargets(ctx context.Context, tcs map[string]*types.
 	c.m.Lock()
 	for _, add := range targetOp.Add {
 		c.lastTargets[add.Name] = add
+		c.logger.Printf("target %q added, address=%q", add.Name, add.Address)
 	}
 	for _, del := range targetOp.Del {
 		delete(c.lastTargets, del)
+		c.logger.Printf("target %q removed", del)
 	}
 	c.m.Unlock()
 	opChan <- targetOp
@@ -0,0 +1,72 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package consul_loader
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/openconfig/gnmic/types"
+)
+
+func TestServiceEntryToTargetConfig(t *testing.T) {
+	c := &consulLoader{
+		cfg: &cfg{
+			Services: []*serviceDef{
+				{
+					Name: "gnmi",
+					Config: map[string]interface{}{
+						"insecure": true,
+						"event-tags": map[string]string{
+							"site": "dc1",
+						},
+					},
+				},
+			},
+		},
+	}
+	se := &api.ServiceEntry{
+		Node: &api.Node{Address: "10.0.0.1"},
+		Service: &api.AgentService{
+			ID:      "router1",
+			Service: "gnmi",
+			Port:    57400,
+			Meta: map[string]string{
+				"site": "dc2",
+				"role": "spine",
+			},
+		},
+	}
+	insecure := true
+	exp := &types.TargetConfig{
+		Name:     "router1",
+		Address:  "10.0.0.1:57400",
+		Insecure: &insecure,
+		EventTags: map[string]string{
+			"site": "dc1",
+			"role": "spine",
+		},
+	}
+	tc, err := c.serviceEntryToTargetConfig(se)
+	if err != nil {
+		t.Fatalf("failed to convert service entry: %v", err)
+	}
+	if !reflect.DeepEqual(tc, exp) {
+		t.Errorf("unexpected target config")
+		t.Logf("exp value: %+v", exp)
+		t.Logf("got value: %+v", tc)
+	}
+	// unknown service
+	se.Service.Service = "other"
+	tc, err = c.serviceEntryToTargetConfig(se)
+	if err != nil || tc != nil {
+		t.Errorf("expected a nil target config, got %+v, err=%v", tc, err)
+	}
+}
@@ -9,6 +9,10 @@
 package main
 
 import (
+	// embed the timezone database for the --timezone flag,
+	// the container images do not include it.
+	_ "time/tzdata"
+
 	"github.com/openconfig/gnmic/cmd"
 )
 
@@ -9,6 +9,8 @@
 package all
 
 import (
+	_ "github.com/openconfig/gnmic/outputs/elasticsearch_output"
+	_ "github.com/openconfig/gnmic/outputs/exec_output"
 	_ "github.com/openconfig/gnmic/outputs/file"
 	_ "github.com/openconfig/gnmic/outputs/gnmi_output"
 	_ "github.com/openconfig/gnmic/outputs/influxdb_output"
@@ -16,9 +18,12 @@ import (
 	_ "github.com/openconfig/gnmic/outputs/nats_outputs/jetstream"
 	_ "github.com/openconfig/gnmic/outputs/nats_outputs/nats"
 	_ "github.com/openconfig/gnmic/outputs/nats_outputs/stan"
+	_ "github.com/openconfig/gnmic/outputs/otlp_output"
 	_ "github.com/openconfig/gnmic/outputs/prometheus_output/prometheus_output"
 	_ "github.com/openconfig/gnmic/outputs/prometheus_output/prometheus_write_output"
 	_ "github.com/openconfig/gnmic/outputs/snmp_output"
+	_ "github.com/openconfig/gnmic/outputs/syslog_output"
 	_ "github.com/openconfig/gnmic/outputs/tcp_output"
 	_ "github.com/openconfig/gnmic/outputs/udp_output"
+	_ "github.com/openconfig/gnmic/outputs/websocket_output"
 )
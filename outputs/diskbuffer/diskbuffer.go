@@ -0,0 +1,369 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diskbuffer implements an on-disk FIFO of records,
+// used by outputs to spool messages while their destination is unavailable.
+//
+// Records are appended to segment files, each record is written as
+// a 4 bytes big endian length, a 4 bytes CRC32 (IEEE) of the payload, then the payload.
+// A truncated or corrupted record ends its segment, so that after a crash
+// the buffer resumes from the last complete record.
+package diskbuffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt     = ".seg"
+	headerSize     = 8
+	minSegmentSize = 64 * 1024
+	maxSegmentSize = 64 * 1024 * 1024
+)
+
+var ErrRecordTooLarge = errors.New("record larger than the buffer max size")
+
+type segment struct {
+	id      uint64
+	size    int64
+	records int
+}
+
+// Buffer is a size capped, segmented, on-disk FIFO of records.
+type Buffer struct {
+	dir         string
+	maxSize     int64
+	segmentSize int64
+	logger      *log.Logger
+
+	m        *sync.Mutex
+	segments []*segment
+	w        *os.File
+	size     int64
+	records  int
+}
+
+// Open opens or creates a buffer in directory dir.
+// The total size of the buffer is capped to maxSize bytes,
+// the oldest segments are removed when the cap is exceeded.
+func Open(dir string, maxSize int64, logger *log.Logger) (*Buffer, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid buffer max size %d", maxSize)
+	}
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	b := &Buffer{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: maxSize / 8,
+		logger:      logger,
+		m:           new(sync.Mutex),
+	}
+	if b.segmentSize < minSegmentSize {
+		b.segmentSize = minSegmentSize
+	}
+	if b.segmentSize > maxSegmentSize {
+		b.segmentSize = maxSegmentSize
+	}
+	err = b.load()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// load reads the existing segments, truncating them after their last complete record.
+func (b *Buffer) load() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := &segment{id: id}
+		seg.size, seg.records, err = b.recover(b.segmentPath(id))
+		if err != nil {
+			return err
+		}
+		if seg.records == 0 {
+			os.Remove(b.segmentPath(id))
+			continue
+		}
+		b.segments = append(b.segments, seg)
+		b.size += seg.size
+		b.records += seg.records
+	}
+	sort.Slice(b.segments, func(i, j int) bool {
+		return b.segments[i].id < b.segments[j].id
+	})
+	if b.records > 0 {
+		b.logger.Printf("loaded %d buffered record(s) from %q", b.records, b.dir)
+	}
+	return nil
+}
+
+// recover returns the size and number of complete records of a segment file,
+// and truncates any incomplete record at its end.
+func (b *Buffer) recover(path string) (int64, int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	var size int64
+	var records int
+	r := bufio.NewReader(f)
+	for {
+		rec, err := readRecord(r, b.maxSize-headerSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				b.logger.Printf("segment %q: truncating after %d record(s): %v", path, records, err)
+			}
+			break
+		}
+		size += int64(headerSize + len(rec))
+		records++
+	}
+	return size, records, f.Truncate(size)
+}
+
+// Write appends a record to the buffer.
+func (b *Buffer) Write(rec []byte) error {
+	recSize := int64(headerSize + len(rec))
+	if recSize > b.maxSize {
+		return ErrRecordTooLarge
+	}
+	b.m.Lock()
+	defer b.m.Unlock()
+	if b.w == nil || b.segments[len(b.segments)-1].size+recSize > b.segmentSize {
+		err := b.rotate()
+		if err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, headerSize, recSize)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(rec)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(rec))
+	buf = append(buf, rec...)
+	_, err := b.w.Write(buf)
+	if err != nil {
+		return err
+	}
+	seg := b.segments[len(b.segments)-1]
+	seg.size += recSize
+	seg.records++
+	b.size += recSize
+	b.records++
+	b.enforceMaxSize()
+	return nil
+}
+
+// Drain calls fn with each buffered record, oldest first.
+// A segment is removed once all its records were successfully handled by fn.
+// If fn returns an error, Drain stops and the records of the current segment
+// are kept, they will be handled again by the next call to Drain.
+func (b *Buffer) Drain(fn func([]byte) error) error {
+	return b.DrainBatch(1, func(recs [][]byte) error {
+		return fn(recs[0])
+	})
+}
+
+// DrainBatch is like Drain but calls fn with up to size records at a time.
+// A batch never spans two segments, so that a segment is only removed
+// once all its records were successfully handled by fn.
+func (b *Buffer) DrainBatch(size int, fn func([][]byte) error) error {
+	if size <= 0 {
+		size = 1
+	}
+	b.m.Lock()
+	// new records are written to a new segment while draining
+	err := b.closeWriter()
+	if err != nil {
+		b.m.Unlock()
+		return err
+	}
+	segs := make([]*segment, len(b.segments))
+	copy(segs, b.segments)
+	b.m.Unlock()
+
+	for _, seg := range segs {
+		err = b.drainSegment(seg, size, fn)
+		if err != nil {
+			return err
+		}
+		b.m.Lock()
+		b.removeSegment(seg)
+		b.m.Unlock()
+	}
+	return nil
+}
+
+func (b *Buffer) drainSegment(seg *segment, size int, fn func([][]byte) error) error {
+	f, err := os.Open(b.segmentPath(seg.id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// removed by enforceMaxSize
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	batch := make([][]byte, 0, size)
+	for {
+		rec, err := readRecord(r, b.maxSize-headerSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				b.logger.Printf("segment %d: skipping remaining records: %v", seg.id, err)
+			}
+			break
+		}
+		batch = append(batch, rec)
+		if len(batch) < size {
+			continue
+		}
+		err = fn(batch)
+		if err != nil {
+			return err
+		}
+		batch = make([][]byte, 0, size)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return fn(batch)
+}
+
+// Len returns the number of buffered records.
+func (b *Buffer) Len() int {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.records
+}
+
+// Size returns the size in bytes of the buffered records.
+func (b *Buffer) Size() int64 {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.size
+}
+
+// Close closes the current segment file, the buffered records are kept on disk.
+func (b *Buffer) Close() error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.closeWriter()
+}
+
+func (b *Buffer) rotate() error {
+	err := b.closeWriter()
+	if err != nil {
+		return err
+	}
+	var id uint64
+	if len(b.segments) > 0 {
+		id = b.segments[len(b.segments)-1].id + 1
+	}
+	b.w, err = os.OpenFile(b.segmentPath(id), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	b.segments = append(b.segments, &segment{id: id})
+	return nil
+}
+
+func (b *Buffer) closeWriter() error {
+	if b.w == nil {
+		return nil
+	}
+	err := b.w.Close()
+	b.w = nil
+	return err
+}
+
+// enforceMaxSize removes the oldest segments, except the one being written,
+// until the buffer size is below its max size.
+func (b *Buffer) enforceMaxSize() {
+	dropped := 0
+	for b.size > b.maxSize && len(b.segments) > 1 {
+		dropped += b.segments[0].records
+		b.removeSegment(b.segments[0])
+	}
+	if dropped > 0 {
+		b.logger.Printf("buffer max size %d reached, dropped %d oldest record(s)", b.maxSize, dropped)
+	}
+}
+
+func (b *Buffer) removeSegment(seg *segment) {
+	for i, s := range b.segments {
+		if s != seg {
+			continue
+		}
+		err := os.Remove(b.segmentPath(seg.id))
+		if err != nil && !os.IsNotExist(err) {
+			b.logger.Printf("failed to remove segment %d: %v", seg.id, err)
+		}
+		b.size -= seg.size
+		b.records -= seg.records
+		b.segments = append(b.segments[:i], b.segments[i+1:]...)
+		return
+	}
+}
+
+func (b *Buffer) segmentPath(id uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// readRecord reads a record of at most maxLen bytes,
+// a larger length in the header is a corrupted record.
+func readRecord(r io.Reader, maxLen int64) ([]byte, error) {
+	hdr := make([]byte, headerSize)
+	n, err := io.ReadFull(r, hdr)
+	if err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("incomplete record header: %v", err)
+	}
+	recLen := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	if recLen > maxLen {
+		return nil, fmt.Errorf("corrupted record header: length %d exceeds the maximum record size %d", recLen, maxLen)
+	}
+	rec := make([]byte, recLen)
+	_, err = io.ReadFull(r, rec)
+	if err != nil {
+		return nil, fmt.Errorf("incomplete record: %v", err)
+	}
+	if crc32.ChecksumIEEE(rec) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return nil, errors.New("record checksum mismatch")
+	}
+	return rec, nil
+}
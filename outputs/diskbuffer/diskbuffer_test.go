@@ -0,0 +1,187 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package diskbuffer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func drainAll(t *testing.T, b *Buffer) []string {
+	recs := make([]string, 0)
+	err := b.Drain(func(rec []byte) error {
+		recs = append(recs, string(rec))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	return recs
+}
+
+func TestBufferWriteDrain(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(dir, 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	exp := []string{"rec1", "rec2", "rec3"}
+	for _, r := range exp {
+		if err := b.Write([]byte(r)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if b.Len() != len(exp) {
+		t.Errorf("expected %d records, got %d", len(exp), b.Len())
+	}
+	got := drainAll(t, b)
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	if b.Len() != 0 || b.Size() != 0 {
+		t.Errorf("expected an empty buffer, got %d records, %d bytes", b.Len(), b.Size())
+	}
+}
+
+func TestBufferDrainError(t *testing.T) {
+	b, err := Open(t.TempDir(), 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	b.Write([]byte("rec1"))
+	b.Write([]byte("rec2"))
+	errFail := errors.New("destination down")
+	err = b.Drain(func(rec []byte) error {
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("expected drain error, got %v", err)
+	}
+	// records are kept and delivered again
+	got := drainAll(t, b)
+	if !reflect.DeepEqual(got, []string{"rec1", "rec2"}) {
+		t.Errorf("unexpected records after failed drain: %v", got)
+	}
+}
+
+func TestBufferDrainBatch(t *testing.T) {
+	b, err := Open(t.TempDir(), 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		b.Write([]byte(fmt.Sprintf("rec%d", i)))
+	}
+	// records written after a drain go to a new segment
+	b.Drain(func([]byte) error { return errors.New("destination down") })
+	b.Write([]byte("rec5"))
+	batches := make([][]string, 0)
+	err = b.DrainBatch(2, func(recs [][]byte) error {
+		batch := make([]string, 0, len(recs))
+		for _, rec := range recs {
+			batch = append(batch, string(rec))
+		}
+		batches = append(batches, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	exp := [][]string{{"rec0", "rec1"}, {"rec2", "rec3"}, {"rec4"}, {"rec5"}}
+	if !reflect.DeepEqual(batches, exp) {
+		t.Errorf("expected batches %v, got %v", exp, batches)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected an empty buffer, got %d records", b.Len())
+	}
+}
+
+func TestBufferReopenTruncated(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(dir, 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	b.Write([]byte("rec1"))
+	b.Write([]byte("rec2"))
+	b.Close()
+	// simulate a crash in the middle of a record write
+	f, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExt)), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("failed to open segment: %v", err)
+	}
+	f.Write([]byte{0, 0, 0, 10, 1, 2})
+	f.Close()
+
+	b, err = Open(dir, 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if b.Len() != 2 {
+		t.Errorf("expected 2 records after reopen, got %d", b.Len())
+	}
+	b.Write([]byte("rec3"))
+	got := drainAll(t, b)
+	if !reflect.DeepEqual(got, []string{"rec1", "rec2", "rec3"}) {
+		t.Errorf("unexpected records after reopen: %v", got)
+	}
+}
+
+func TestBufferReopenCorruptedLength(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(dir, 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	b.Write([]byte("rec1"))
+	b.Close()
+	// a torn header announcing a ~4GiB record
+	f, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExt)), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("failed to open segment: %v", err)
+	}
+	f.Write([]byte{0xff, 0xff, 0xff, 0xf0, 1, 2, 3, 4, 5, 6})
+	f.Close()
+
+	b, err = Open(dir, 1024*1024, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	got := drainAll(t, b)
+	if !reflect.DeepEqual(got, []string{"rec1"}) {
+		t.Errorf("unexpected records after reopen: %v", got)
+	}
+}
+
+func TestBufferMaxSize(t *testing.T) {
+	// segments are at least minSegmentSize bytes
+	b, err := Open(t.TempDir(), 2*minSegmentSize, nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	rec := make([]byte, 1024-headerSize)
+	for i := 0; i < 300; i++ {
+		if err := b.Write(rec); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if b.Size() > 2*minSegmentSize {
+		t.Errorf("buffer size %d exceeds max size %d", b.Size(), 2*minSegmentSize)
+	}
+	if b.Len() >= 300 {
+		t.Errorf("expected old records to be dropped, got %d records", b.Len())
+	}
+	if err := b.Write(make([]byte, 3*minSegmentSize)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("expected ErrRecordTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch_output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// bulkResponse is the part of the bulk API response
+// reporting the result of each document.
+type bulkResponse struct {
+	Errors bool                           `json:"errors,omitempty"`
+	Items  []map[string]*bulkItemResponse `json:"items,omitempty"`
+}
+
+type bulkItemResponse struct {
+	Status int             `json:"status,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// deadLetter is a dead letter file entry.
+type deadLetter struct {
+	Time     string          `json:"time"`
+	Index    string          `json:"index"`
+	Status   int             `json:"status,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Document json.RawMessage `json:"document"`
+}
+
+// worker sends the buffered documents once batch-size documents are buffered
+// or every flush-interval. The in-flight bulk request is not canceled once ctx is done,
+// Close waits for it, then sends the documents left.
+func (e *esOutput) worker(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.Cfg.FlushInterval)
+	defer ticker.Stop()
+	e.batch = make([]*document, 0, e.Cfg.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case doc := <-e.buffer:
+			e.batch = append(e.batch, doc)
+			if len(e.batch) < e.Cfg.BatchSize {
+				continue
+			}
+			e.batch = append(make([]*document, 0, e.Cfg.BatchSize), e.flush(ctx, e.batch)...)
+		case <-ticker.C:
+			if len(e.batch) == 0 {
+				continue
+			}
+			e.batch = append(make([]*document, 0, e.Cfg.BatchSize), e.flush(ctx, e.batch)...)
+		}
+	}
+}
+
+// drain sends batch and the documents left in the buffer within the output timeout.
+func (e *esOutput) drain(batch []*document) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.Cfg.Timeout)
+	defer cancel()
+	for {
+		select {
+		case doc := <-e.buffer:
+			batch = append(batch, doc)
+			if len(batch) < e.Cfg.BatchSize {
+				continue
+			}
+			e.dropUnsent(e.flush(ctx, batch), ctx.Err())
+			batch = make([]*document, 0, e.Cfg.BatchSize)
+		default:
+			if len(batch) > 0 {
+				e.dropUnsent(e.flush(ctx, batch), ctx.Err())
+			}
+			if e.Cfg.Debug {
+				e.logger.Printf("buffer drained")
+			}
+			return
+		}
+	}
+}
+
+// flush sends docs with a bulk request, the documents throttled by Elasticsearch
+// and the failed requests are retried up to max-retries times with an exponential backoff.
+// Each bulk request is bounded by the output timeout and is not canceled by ctx,
+// flush returns the documents left to send once ctx is done.
+func (e *esOutput) flush(ctx context.Context, docs []*document) []*document {
+	backoff := e.Cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil {
+			return docs
+		}
+		reqCtx, cancel := context.WithTimeout(context.Background(), e.Cfg.Timeout)
+		retry, err := e.bulk(reqCtx, docs)
+		cancel()
+		if err != nil {
+			e.logger.Printf("bulk request failed: %v", err)
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt >= e.Cfg.MaxRetries {
+			numberOfFailedDocs.WithLabelValues(e.name, "retries_exhausted").Add(float64(len(retry)))
+			e.WriteError(fmt.Errorf("dropped %d document(s) after %d retries", len(retry), e.Cfg.MaxRetries))
+			e.logger.Printf("dropping %d document(s) after %d retries", len(retry), e.Cfg.MaxRetries)
+			return nil
+		}
+		if e.Cfg.Debug {
+			e.logger.Printf("retrying %d document(s) in %s", len(retry), backoff)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retry
+		case <-timer.C:
+		}
+		docs = retry
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// dropUnsent records the documents docs left unsent by the drain because of err.
+func (e *esOutput) dropUnsent(docs []*document, err error) {
+	if len(docs) == 0 {
+		return
+	}
+	numberOfFailedDocs.WithLabelValues(e.name, "canceled").Add(float64(len(docs)))
+	e.WriteError(err)
+	e.logger.Printf("dropping %d document(s): %v", len(docs), err)
+}
+
+// bulk sends docs with a single bulk request and returns the documents to retry.
+// The documents rejected by Elasticsearch are written to the dead letter file.
+func (e *esOutput) bulk(ctx context.Context, docs []*document) ([]*document, error) {
+	req, err := e.bulkRequest(ctx, docs)
+	if err != nil {
+		// the documents cannot be sent as is
+		e.reject(docs, 0, nil)
+		return nil, err
+	}
+	numberOfBulkRequests.WithLabelValues(e.name).Inc()
+	start := time.Now()
+	rsp, err := e.httpClient.Do(req)
+	if err != nil {
+		return docs, err
+	}
+	defer rsp.Body.Close()
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return docs, err
+	}
+	if e.Cfg.Debug {
+		e.logger.Printf("bulk request of %d document(s) done in %s: status=%s", len(docs), time.Since(start), rsp.Status)
+	}
+	switch {
+	case rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500:
+		return docs, fmt.Errorf("status=%s, body=%s", rsp.Status, truncate(body))
+	case rsp.StatusCode >= 300:
+		reason, _ := json.Marshal(truncate(body))
+		e.reject(docs, rsp.StatusCode, reason)
+		return nil, fmt.Errorf("status=%s, body=%s", rsp.Status, truncate(body))
+	}
+	br := new(bulkResponse)
+	err = json.Unmarshal(body, br)
+	if err != nil {
+		return docs, fmt.Errorf("failed to decode bulk response: %v", err)
+	}
+	if !br.Errors {
+		numberOfIndexedDocs.WithLabelValues(e.name).Add(float64(len(docs)))
+		return nil, nil
+	}
+	if len(br.Items) != len(docs) {
+		return docs, fmt.Errorf("unexpected bulk response: %d items for %d documents", len(br.Items), len(docs))
+	}
+	retry := make([]*document, 0)
+	indexed := 0
+	for i, item := range br.Items {
+		for _, r := range item {
+			switch {
+			case r.Status == http.StatusTooManyRequests:
+				retry = append(retry, docs[i])
+			case r.Status >= 300:
+				e.reject(docs[i:i+1], r.Status, r.Error)
+			default:
+				indexed++
+			}
+		}
+	}
+	numberOfIndexedDocs.WithLabelValues(e.name).Add(float64(indexed))
+	return retry, nil
+}
+
+// bulkRequest returns the bulk API request indexing docs.
+func (e *esOutput) bulkRequest(ctx context.Context, docs []*document) (*http.Request, error) {
+	body := new(bytes.Buffer)
+	var w io.Writer = body
+	var zw *gzip.Writer
+	if e.Cfg.Gzip {
+		zw = gzip.NewWriter(body)
+		w = zw
+	}
+	for _, doc := range docs {
+		action, err := json.Marshal(map[string]map[string]string{"index": {"_index": doc.index}})
+		if err != nil {
+			return nil, err
+		}
+		w.Write(action)
+		w.Write([]byte("\n"))
+		w.Write(doc.body)
+		w.Write([]byte("\n"))
+	}
+	if zw != nil {
+		err := zw.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.bulkURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("User-Agent", userAgent)
+	if e.Cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	switch {
+	case e.Cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.Cfg.APIKey)
+	case e.Cfg.Username != "":
+		req.SetBasicAuth(e.Cfg.Username, e.Cfg.Password)
+	}
+	for k, v := range e.Cfg.Headers {
+		req.Header.Add(k, v)
+	}
+	return req, nil
+}
+
+// reject writes the documents permanently rejected by Elasticsearch to the dead letter file,
+// they are only logged without one.
+func (e *esOutput) reject(docs []*document, status int, reason json.RawMessage) {
+	numberOfFailedDocs.WithLabelValues(e.name, "rejected").Add(float64(len(docs)))
+	e.WriteError(fmt.Errorf("%d document(s) rejected with status %d", len(docs), status))
+	if e.deadLetter == nil {
+		for _, doc := range docs {
+			e.logger.Printf("document rejected: status=%d, error=%s, index=%s, document=%s", status, reason, doc.index, doc.body)
+		}
+		return
+	}
+	e.deadLetterLock.Lock()
+	defer e.deadLetterLock.Unlock()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, doc := range docs {
+		b, err := json.Marshal(&deadLetter{
+			Time:     now,
+			Index:    doc.index,
+			Status:   status,
+			Error:    reason,
+			Document: doc.body,
+		})
+		if err != nil {
+			e.logger.Printf("failed to marshal dead letter: %v", err)
+			continue
+		}
+		_, err = e.deadLetter.Write(append(b, '\n'))
+		if err != nil {
+			e.logger.Printf("failed to write dead letter: %v", err)
+		}
+	}
+}
+
+// maximum number of response body bytes logged on a failed request
+const maxLoggedBodySize = 1024
+
+func truncate(b []byte) string {
+	if len(b) <= maxLoggedBodySize {
+		return string(b)
+	}
+	return string(b[:maxLoggedBodySize]) + "..."
+}
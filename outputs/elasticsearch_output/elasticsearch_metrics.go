@@ -0,0 +1,53 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch_output
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var numberOfIndexedDocs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "elasticsearch_output",
+	Name:      "number_documents_indexed_total",
+	Help:      "Number of documents indexed by elasticsearch output",
+}, []string{"name"})
+
+var numberOfFailedDocs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "elasticsearch_output",
+	Name:      "number_documents_failed_total",
+	Help:      "Number of documents not indexed by elasticsearch output",
+}, []string{"name", "reason"})
+
+var numberOfBulkRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "elasticsearch_output",
+	Name:      "number_bulk_requests_total",
+	Help:      "Number of bulk requests sent by elasticsearch output",
+}, []string{"name"})
+
+func initMetrics(name string) {
+	numberOfIndexedDocs.WithLabelValues(name).Add(0)
+	numberOfFailedDocs.WithLabelValues(name, "").Add(0)
+	numberOfBulkRequests.WithLabelValues(name).Add(0)
+}
+
+func registerMetrics(reg *prometheus.Registry, name string) error {
+	initMetrics(name)
+	var err error
+	if err = reg.Register(numberOfIndexedDocs); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfFailedDocs); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfBulkRequests); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,450 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch_output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	outputType          = "elasticsearch"
+	loggingPrefix       = "[elasticsearch_output:%s] "
+	defaultIndex        = "gnmic-%{+yyyy.MM.dd}"
+	defaultBatchSize    = 500
+	defaultBufferSize   = 10000
+	defaultFlushPeriod  = 5 * time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 5
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+	userAgent           = "gNMIc elasticsearch"
+)
+
+func init() {
+	outputs.Register(outputType, func() outputs.Output {
+		return &esOutput{
+			Cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			done:   make(chan struct{}),
+		}
+	})
+}
+
+type esOutput struct {
+	outputs.WriteErrorReporter
+
+	Cfg *Config
+
+	name       string
+	logger     *log.Logger
+	evps       []formatters.EventProcessor
+	httpClient *http.Client
+	bulkURL    string
+	buffer     chan *document
+	// documents read from the buffer and not sent yet,
+	// only accessed by the worker, then by Close once the worker is done.
+	batch []*document
+
+	deadLetterLock *sync.Mutex
+	deadLetter     *os.File
+
+	cancelFn  context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type Config struct {
+	// Elasticsearch address, e.g: http://es:9200
+	URL string `mapstructure:"url,omitempty" json:"url,omitempty"`
+	// index name, the date patterns %{+<format>} are replaced with the event date
+	Index    string            `mapstructure:"index,omitempty" json:"index,omitempty"`
+	Username string            `mapstructure:"username,omitempty" json:"username,omitempty"`
+	Password string            `mapstructure:"password,omitempty" json:"-"`
+	APIKey   string            `mapstructure:"api-key,omitempty" json:"-"`
+	Headers  map[string]string `mapstructure:"headers,omitempty" json:"headers,omitempty"`
+	TLS      *tlsConfig        `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	Gzip     bool              `mapstructure:"gzip,omitempty" json:"gzip,omitempty"`
+	// maximum number of documents per bulk request
+	BatchSize int `mapstructure:"batch-size,omitempty" json:"batch-size,omitempty"`
+	// maximum time a document waits for its batch to be sent
+	FlushInterval time.Duration `mapstructure:"flush-interval,omitempty" json:"flush-interval,omitempty"`
+	BufferSize    int           `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	Timeout       time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	MaxRetries    int           `mapstructure:"max-retries,omitempty" json:"max-retries,omitempty"`
+	RetryBackoff  time.Duration `mapstructure:"retry-backoff,omitempty" json:"retry-backoff,omitempty"`
+	// file where the documents rejected by Elasticsearch are appended
+	DeadLetterFile  string   `mapstructure:"dead-letter-file,omitempty" json:"dead-letter-file,omitempty"`
+	EnableMetrics   bool     `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EventProcessors []string `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+type tlsConfig struct {
+	CAFile     string `mapstructure:"ca-file,omitempty" json:"ca-file,omitempty"`
+	CertFile   string `mapstructure:"cert-file,omitempty" json:"cert-file,omitempty"`
+	KeyFile    string `mapstructure:"key-file,omitempty" json:"key-file,omitempty"`
+	SkipVerify bool   `mapstructure:"skip-verify,omitempty" json:"skip-verify,omitempty"`
+}
+
+// document is an event ready to be indexed.
+type document struct {
+	index string
+	body  []byte
+}
+
+// esDocument is the indexed document structure, the same for all the events.
+type esDocument struct {
+	Timestamp string                 `json:"@timestamp"`
+	Name      string                 `json:"name,omitempty"`
+	Tags      map[string]string      `json:"tags,omitempty"`
+	Values    map[string]interface{} `json:"values,omitempty"`
+	Deletes   []string               `json:"deletes,omitempty"`
+}
+
+func (e *esOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && e.logger != nil {
+		e.logger.SetOutput(logger.Writer())
+		e.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (e *esOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) {
+	for _, epName := range e.Cfg.EventProcessors {
+		if epCfg, ok := ps[epName]; ok {
+			epType := ""
+			for k := range epCfg {
+				epType = k
+				break
+			}
+			if in, ok := formatters.EventProcessors[epType]; ok {
+				ep := in()
+				err := ep.Init(epCfg[epType],
+					formatters.WithLogger(logger),
+					formatters.WithTargets(tcs),
+					formatters.WithActions(acts),
+				)
+				if err != nil {
+					e.logger.Printf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
+					continue
+				}
+				e.evps = append(e.evps, ep)
+				e.logger.Printf("added event processor '%s' of type=%s to elasticsearch output", epName, epType)
+				continue
+			}
+			e.logger.Printf("%q event processor has an unknown type=%q", epName, epType)
+			continue
+		}
+		e.logger.Printf("%q event processor not found!", epName)
+	}
+}
+
+func (e *esOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, e.Cfg)
+	if err != nil {
+		return err
+	}
+	e.name = name
+	e.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	err = e.setDefaults()
+	if err != nil {
+		return err
+	}
+	err = e.createHTTPClient()
+	if err != nil {
+		return err
+	}
+	if e.Cfg.DeadLetterFile != "" {
+		e.deadLetter, err = os.OpenFile(e.Cfg.DeadLetterFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open dead letter file: %v", err)
+		}
+		e.deadLetterLock = new(sync.Mutex)
+	}
+	e.buffer = make(chan *document, e.Cfg.BufferSize)
+
+	ctx, e.cancelFn = context.WithCancel(ctx)
+	go e.worker(ctx)
+	e.logger.Printf("initialized elasticsearch output: %s", e.String())
+	return nil
+}
+
+func (e *esOutput) setDefaults() error {
+	if e.Cfg.URL == "" {
+		return errors.New("missing url field")
+	}
+	u, err := url.Parse(e.Cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", e.Cfg.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid url %q: scheme must be http or https", e.Cfg.URL)
+	}
+	e.bulkURL = strings.TrimSuffix(e.Cfg.URL, "/") + "/_bulk"
+	if e.Cfg.Index == "" {
+		e.Cfg.Index = defaultIndex
+	}
+	if idx := indexName(e.Cfg.Index, time.Now()); idx != strings.ToLower(idx) {
+		return fmt.Errorf("invalid index %q: index names must be lowercase", e.Cfg.Index)
+	}
+	if e.Cfg.APIKey != "" && e.Cfg.Username != "" {
+		return errors.New("fields api-key and username are mutually exclusive")
+	}
+	if e.Cfg.BatchSize <= 0 {
+		e.Cfg.BatchSize = defaultBatchSize
+	}
+	if e.Cfg.FlushInterval <= 0 {
+		e.Cfg.FlushInterval = defaultFlushPeriod
+	}
+	if e.Cfg.BufferSize <= 0 {
+		e.Cfg.BufferSize = defaultBufferSize
+	}
+	if e.Cfg.Timeout <= 0 {
+		e.Cfg.Timeout = defaultTimeout
+	}
+	if e.Cfg.MaxRetries < 0 {
+		return errors.New("max-retries cannot be negative")
+	}
+	if e.Cfg.MaxRetries == 0 {
+		e.Cfg.MaxRetries = defaultMaxRetries
+	}
+	if e.Cfg.RetryBackoff <= 0 {
+		e.Cfg.RetryBackoff = defaultRetryBackoff
+	}
+	return nil
+}
+
+func (e *esOutput) createHTTPClient() error {
+	c := &http.Client{
+		Timeout: e.Cfg.Timeout,
+	}
+	if e.Cfg.TLS != nil {
+		tlsCfg, err := utils.NewTLSConfig(
+			e.Cfg.TLS.CAFile,
+			e.Cfg.TLS.CertFile,
+			e.Cfg.TLS.KeyFile,
+			e.Cfg.TLS.SkipVerify,
+			false)
+		if err != nil {
+			return err
+		}
+		c.Transport = &http.Transport{
+			TLSClientConfig: tlsCfg,
+		}
+	}
+	e.httpClient = c
+	return nil
+}
+
+func (e *esOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	switch rsp := m.(type) {
+	case *gnmi.SubscribeResponse:
+		name := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			name = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(name, rsp, meta, e.evps...)
+		if err != nil {
+			e.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			e.queue(ev)
+		}
+	}
+}
+
+func (e *esOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range e.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		e.queue(pev)
+	}
+}
+
+// queue adds the document of event ev to the buffer,
+// it is dropped if the buffer is full.
+func (e *esOutput) queue(ev *formatters.EventMsg) {
+	doc, err := e.newDocument(ev)
+	if err != nil {
+		e.logger.Printf("failed to create document: %v", err)
+		return
+	}
+	select {
+	case e.buffer <- doc:
+	default:
+		numberOfFailedDocs.WithLabelValues(e.name, "buffer_full").Inc()
+		e.WriteError(outputs.ErrBufferFull)
+		e.logger.Printf("buffer full, dropping event: %s", ev)
+	}
+}
+
+// newDocument returns the document of event ev, indexed in the index of the event date.
+func (e *esOutput) newDocument(ev *formatters.EventMsg) (*document, error) {
+	ts := time.Now()
+	if ev.Timestamp > 0 {
+		ts = time.Unix(0, ev.Timestamp)
+	}
+	doc := &esDocument{
+		Timestamp: ts.UTC().Format(time.RFC3339Nano),
+		Name:      ev.Name,
+		Deletes:   ev.Deletes,
+	}
+	if len(ev.Tags) > 0 {
+		doc.Tags = make(map[string]string, len(ev.Tags))
+		for k, v := range ev.Tags {
+			doc.Tags[fieldName(k)] = v
+		}
+	}
+	if len(ev.Values) > 0 {
+		doc.Values = make(map[string]interface{}, len(ev.Values))
+		for k, v := range ev.Values {
+			doc.Values[fieldName(k)] = v
+		}
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &document{index: indexName(e.Cfg.Index, ts), body: b}, nil
+}
+
+// Close stops the worker and sends the buffered documents,
+// it returns once they are sent or the output timeout is reached.
+func (e *esOutput) Close() error {
+	e.closeOnce.Do(func() {
+		if e.cancelFn == nil {
+			return
+		}
+		e.cancelFn()
+		<-e.done
+		e.drain(e.batch)
+		if e.deadLetter != nil {
+			e.deadLetter.Close()
+		}
+	})
+	return nil
+}
+
+func (e *esOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !e.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, e.name); err != nil {
+		e.logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (e *esOutput) String() string {
+	b, err := json.Marshal(e.Cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (e *esOutput) SetName(name string)                             {}
+func (e *esOutput) SetClusterName(name string)                      {}
+func (e *esOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+// fieldName returns k with its dots replaced,
+// Elasticsearch maps a dotted field name to nested objects.
+func fieldName(k string) string {
+	return strings.ReplaceAll(k, ".", "_")
+}
+
+// dateTokens maps the date pattern tokens to their Go layout.
+var dateTokens = map[string]string{
+	"yyyy": "2006",
+	"YYYY": "2006",
+	"yy":   "06",
+	"MM":   "01",
+	"dd":   "02",
+	"HH":   "15",
+	"mm":   "04",
+	"ss":   "05",
+}
+
+// indexName replaces the date patterns %{+<format>} of index with date t in UTC,
+// e.g: gnmic-%{+yyyy.MM.dd} is replaced with gnmic-2023.03.16.
+func indexName(index string, t time.Time) string {
+	if !strings.Contains(index, "%{+") {
+		return index
+	}
+	t = t.UTC()
+	sb := new(strings.Builder)
+	for {
+		i := strings.Index(index, "%{+")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(index[i:], "}")
+		if j < 0 {
+			break
+		}
+		sb.WriteString(index[:i])
+		sb.WriteString(t.Format(dateLayout(index[i+3 : i+j])))
+		index = index[i+j+1:]
+	}
+	sb.WriteString(index)
+	return sb.String()
+}
+
+// dateLayout converts a date pattern format to a Go time layout.
+func dateLayout(format string) string {
+	sb := new(strings.Builder)
+	for i := 0; i < len(format); {
+		j := i
+		for j < len(format) && format[j] == format[i] {
+			j++
+		}
+		if l, ok := dateTokens[format[i:j]]; ok {
+			sb.WriteString(l)
+		} else {
+			sb.WriteString(format[i:j])
+		}
+		i = j
+	}
+	return sb.String()
+}
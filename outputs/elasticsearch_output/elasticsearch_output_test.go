@@ -0,0 +1,239 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch_output
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+func TestIndexName(t *testing.T) {
+	ts := time.Date(2023, 3, 16, 20, 53, 20, 0, time.UTC)
+	for name, item := range map[string]struct {
+		in  string
+		out string
+	}{
+		"no_pattern":  {in: "gnmic", out: "gnmic"},
+		"daily":       {in: "gnmi-%{+yyyy.MM.dd}", out: "gnmi-2023.03.16"},
+		"hourly":      {in: "gnmi-%{+yyyy.MM.dd.HH}", out: "gnmi-2023.03.16.20"},
+		"two_digits":  {in: "gnmi-%{+yy-MM}", out: "gnmi-23-03"},
+		"multiple":    {in: "%{+yyyy}-gnmi-%{+MM}", out: "2023-gnmi-03"},
+		"not_closed":  {in: "gnmi-%{+yyyy", out: "gnmi-%{+yyyy"},
+		"with_suffix": {in: "gnmi-%{+yyyy}-lab", out: "gnmi-2023-lab"},
+	} {
+		if out := indexName(item.in, ts); out != item.out {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.out, out)
+		}
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	e := &esOutput{Cfg: &Config{Index: "gnmi-%{+yyyy.MM.dd}"}}
+	doc, err := e.newDocument(&formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: time.Date(2023, 3, 16, 20, 53, 20, 0, time.UTC).UnixNano(),
+		Tags:      map[string]string{"source": "10.1.1.1:57400", "neighbor_address": "10.0.0.1", "a.b": "c"},
+		Values:    map[string]interface{}{"/bgp/neighbor[address=10.0.0.1]/state": "up"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.index != "gnmi-2023.03.16" {
+		t.Errorf("unexpected index %q", doc.index)
+	}
+	want := `{"@timestamp":"2023-03-16T20:53:20Z","name":"sub1","tags":{"a_b":"c","neighbor_address":"10.0.0.1","source":"10.1.1.1:57400"},"values":{"/bgp/neighbor[address=10_0_0_1]/state":"up"}}`
+	if string(doc.body) != want {
+		t.Errorf("unexpected document:\nexpected: %s\n     got: %s", want, doc.body)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	for name, item := range map[string]struct {
+		cfg *Config
+		err bool
+	}{
+		"defaults":        {cfg: &Config{URL: "http://es:9200"}},
+		"missing_url":     {cfg: &Config{}, err: true},
+		"invalid_scheme":  {cfg: &Config{URL: "es:9200"}, err: true},
+		"uppercase_index": {cfg: &Config{URL: "http://es:9200", Index: "Gnmi-%{+yyyy}"}, err: true},
+		"api_key_and_basic_auth": {
+			cfg: &Config{URL: "http://es:9200", APIKey: "key", Username: "elastic"},
+			err: true,
+		},
+	} {
+		e := &esOutput{Cfg: item.cfg}
+		err := e.setDefaults()
+		if item.err {
+			if err == nil {
+				t.Errorf("failed at item %q: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		if e.bulkURL != "http://es:9200/_bulk" || e.Cfg.Index != defaultIndex || e.Cfg.BatchSize != defaultBatchSize {
+			t.Errorf("failed at item %q: unexpected defaults: %+v", name, e.Cfg)
+		}
+	}
+}
+
+// TestBulk sends 3 documents to a server throttling the first request,
+// then rejecting the second document.
+func TestBulk(t *testing.T) {
+	m := new(sync.Mutex)
+	requests := 0
+	received := make([]string, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		requests++
+		if r.URL.Path != "/_bulk" || r.Header.Get("Authorization") != "ApiKey secret" || r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lines := 0
+		sc := bufio.NewScanner(zr)
+		for sc.Scan() {
+			lines++
+			if lines%2 == 0 {
+				received = append(received, sc.Text())
+			}
+		}
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			io.WriteString(w, `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}},{"index":{"status":429}}]}`)
+		default:
+			io.WriteString(w, `{"errors":false,"items":[{"index":{"status":201}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	deadLetterFile := filepath.Join(t.TempDir(), "dead-letter.json")
+	e := &esOutput{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+		done:   make(chan struct{}),
+	}
+	err := e.Init(context.Background(), "es1", map[string]interface{}{
+		"url":              srv.URL,
+		"index":            "gnmi",
+		"api-key":          "secret",
+		"gzip":             true,
+		"batch-size":       3,
+		"flush-interval":   time.Hour,
+		"retry-backoff":    time.Millisecond,
+		"dead-letter-file": deadLetterFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		e.WriteEvent(context.Background(), &formatters.EventMsg{
+			Name:   "sub1",
+			Values: map[string]interface{}{"/value": v},
+		})
+	}
+	// the batch is full, wait for its retries
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.Lock()
+		n := requests
+		m.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 bulk requests, got %d", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	e.Close()
+
+	m.Lock()
+	if len(received) != 7 || !strings.Contains(received[6], `"c"`) {
+		t.Errorf("unexpected received documents: %q", received)
+	}
+	m.Unlock()
+	b, err := os.ReadFile(deadLetterFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := new(deadLetter)
+	err = json.Unmarshal(b, dl)
+	if err != nil {
+		t.Fatalf("unexpected dead letter file content %q: %v", b, err)
+	}
+	if dl.Index != "gnmi" || dl.Status != 400 || !strings.Contains(string(dl.Document), `"b"`) ||
+		!strings.Contains(string(dl.Error), "mapper_parsing_exception") {
+		t.Errorf("unexpected dead letter: %s", b)
+	}
+}
+
+// TestCloseDrains checks that the buffered documents are sent on close.
+func TestCloseDrains(t *testing.T) {
+	m := new(sync.Mutex)
+	docs := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		m.Lock()
+		docs += strings.Count(string(b), "\n") / 2
+		m.Unlock()
+		io.WriteString(w, `{"errors":false}`)
+	}))
+	defer srv.Close()
+
+	e := &esOutput{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+		done:   make(chan struct{}),
+	}
+	err := e.Init(context.Background(), "es1", map[string]interface{}{
+		"url":            srv.URL,
+		"batch-size":     2,
+		"flush-interval": time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		e.WriteEvent(context.Background(), &formatters.EventMsg{
+			Name:   "sub1",
+			Values: map[string]interface{}{"/value": i},
+		})
+	}
+	e.Close()
+	m.Lock()
+	defer m.Unlock()
+	if docs != 5 {
+		t.Errorf("expected 5 documents sent on close, got %d", docs)
+	}
+}
@@ -0,0 +1,53 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_output
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var numberOfRunCmds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "exec_output",
+	Name:      "number_commands_run_total",
+	Help:      "Number of commands successfully run by exec output",
+}, []string{"name"})
+
+var numberOfDroppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "exec_output",
+	Name:      "number_events_dropped_total",
+	Help:      "Number of events dropped by exec output because of a full buffer or the rate limit",
+}, []string{"name", "reason"})
+
+var numberOfFailedCmds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "exec_output",
+	Name:      "number_commands_failed_total",
+	Help:      "Number of failed command runs by exec output",
+}, []string{"name", "reason"})
+
+func initMetrics(name string) {
+	numberOfRunCmds.WithLabelValues(name).Add(0)
+	numberOfDroppedEvents.WithLabelValues(name, "").Add(0)
+	numberOfFailedCmds.WithLabelValues(name, "").Add(0)
+}
+
+func registerMetrics(reg *prometheus.Registry, name string) error {
+	initMetrics(name)
+	var err error
+	if err = reg.Register(numberOfRunCmds); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfDroppedEvents); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfFailedCmds); err != nil {
+		return err
+	}
+	return nil
+}
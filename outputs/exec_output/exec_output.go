@@ -0,0 +1,445 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultBufferSize  = 1000
+	defaultConcurrency = 1
+	defaultTimeout     = 10 * time.Second
+	// maximum number of stderr bytes logged when a command fails
+	maxStderrLogSize = 1024
+	// number of rate limited paths above which the expired ones are forgotten
+	maxRateLimitedPaths = 10000
+	loggingPrefix       = "[exec_output:%s] "
+)
+
+func init() {
+	outputs.Register("exec", func() outputs.Output {
+		return &execOutput{
+			Cfg:     &Config{},
+			logger:  log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			m:       new(sync.Mutex),
+			lastRun: make(map[string]time.Time),
+		}
+	})
+}
+
+type execOutput struct {
+	outputs.WriteErrorReporter
+
+	Cfg *Config
+
+	name     string
+	cancelFn context.CancelFunc
+	buffer   chan *formatters.EventMsg
+	logger   *log.Logger
+	evps     []formatters.EventProcessor
+
+	cmdTpl  *template.Template
+	argsTpl []*template.Template
+
+	m       *sync.Mutex
+	lastRun map[string]time.Time
+}
+
+type Config struct {
+	// command to run for each event, a GoTemplate executed using the event as input.
+	Command string `mapstructure:"command,omitempty" json:"command,omitempty"`
+	// command arguments, each one is a GoTemplate executed using the event as input.
+	Args            []string      `mapstructure:"args,omitempty" json:"args,omitempty"`
+	Concurrency     int           `mapstructure:"concurrency,omitempty" json:"concurrency,omitempty"`
+	Timeout         time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	RateLimit       time.Duration `mapstructure:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+	BufferSize      uint          `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	EnableMetrics   bool          `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EventProcessors []string      `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+func (e *execOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && e.logger != nil {
+		e.logger.SetOutput(logger.Writer())
+		e.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (e *execOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) {
+	for _, epName := range e.Cfg.EventProcessors {
+		if epCfg, ok := ps[epName]; ok {
+			epType := ""
+			for k := range epCfg {
+				epType = k
+				break
+			}
+			if in, ok := formatters.EventProcessors[epType]; ok {
+				ep := in()
+				err := ep.Init(epCfg[epType],
+					formatters.WithLogger(logger),
+					formatters.WithTargets(tcs),
+					formatters.WithActions(acts),
+				)
+				if err != nil {
+					e.logger.Printf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
+					continue
+				}
+				e.evps = append(e.evps, ep)
+				e.logger.Printf("added event processor '%s' of type=%s to exec output", epName, epType)
+				continue
+			}
+			e.logger.Printf("%q event processor has an unknown type=%q", epName, epType)
+			continue
+		}
+		e.logger.Printf("%q event processor not found!", epName)
+	}
+}
+
+func (e *execOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, e.Cfg)
+	if err != nil {
+		return err
+	}
+	e.name = name
+	e.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	err = e.setDefaults()
+	if err != nil {
+		return err
+	}
+	e.cmdTpl, err = utils.CreateTemplate(fmt.Sprintf("%s-command", name), e.Cfg.Command)
+	if err != nil {
+		return err
+	}
+	e.cmdTpl = e.cmdTpl.Funcs(outputs.TemplateFuncs)
+	e.argsTpl = make([]*template.Template, 0, len(e.Cfg.Args))
+	for i, arg := range e.Cfg.Args {
+		tpl, err := utils.CreateTemplate(fmt.Sprintf("%s-arg-%d", name, i), arg)
+		if err != nil {
+			return err
+		}
+		e.argsTpl = append(e.argsTpl, tpl.Funcs(outputs.TemplateFuncs))
+	}
+	e.buffer = make(chan *formatters.EventMsg, e.Cfg.BufferSize)
+
+	go func() {
+		<-ctx.Done()
+		e.Close()
+	}()
+	ctx, e.cancelFn = context.WithCancel(ctx)
+	for i := 0; i < e.Cfg.Concurrency; i++ {
+		go e.worker(ctx)
+	}
+	e.logger.Printf("initialized exec output: %s", e.String())
+	return nil
+}
+
+func (e *execOutput) setDefaults() error {
+	if e.Cfg.Command == "" {
+		return errors.New("missing command")
+	}
+	if e.Cfg.Concurrency <= 0 {
+		e.Cfg.Concurrency = defaultConcurrency
+	}
+	if e.Cfg.Timeout <= 0 {
+		e.Cfg.Timeout = defaultTimeout
+	}
+	if e.Cfg.BufferSize == 0 {
+		e.Cfg.BufferSize = defaultBufferSize
+	}
+	return nil
+}
+
+func (e *execOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	switch rsp := m.(type) {
+	case *gnmi.SubscribeResponse:
+		name := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			name = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(name, rsp, meta, e.evps...)
+		if err != nil {
+			e.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			e.WriteEvent(ctx, ev)
+		}
+	}
+}
+
+// WriteEvent queues a command run for each value of the event,
+// unless the same path of the same source ran less than rate-limit ago.
+func (e *execOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	for _, sev := range splitEvent(ev) {
+		if !e.allow(sev.Tags["source"]+eventPath(sev), time.Now()) {
+			numberOfDroppedEvents.WithLabelValues(e.name, "rate_limited").Inc()
+			if e.Cfg.Debug {
+				e.logger.Printf("rate limited, dropping event: %s", sev)
+			}
+			continue
+		}
+		select {
+		case e.buffer <- sev:
+		default:
+			numberOfDroppedEvents.WithLabelValues(e.name, "buffer_full").Inc()
+			e.WriteError(outputs.ErrBufferFull)
+			e.logger.Printf("buffer full, dropping event: %s", sev)
+		}
+	}
+}
+
+func (e *execOutput) Close() error {
+	e.cancelFn()
+	return nil
+}
+
+func (e *execOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !e.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, e.name); err != nil {
+		e.logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (e *execOutput) String() string {
+	b, err := json.Marshal(e.Cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (e *execOutput) SetName(name string)                             {}
+func (e *execOutput) SetClusterName(name string)                      {}
+func (e *execOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func (e *execOutput) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-e.buffer:
+			e.run(ctx, ev)
+		}
+	}
+}
+
+// run executes the command for event ev, passing it as JSON on stdin
+// and as GNMI_* environment variables.
+func (e *execOutput) run(ctx context.Context, ev *formatters.EventMsg) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		e.logger.Printf("failed to marshal event: %v", err)
+		return
+	}
+	name, args, err := e.commandLine(b)
+	if err != nil {
+		numberOfFailedCmds.WithLabelValues(e.name, "template_error").Inc()
+		e.WriteError(err)
+		e.logger.Printf("failed to build command: %v, event: %s", err, b)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.Cfg.Timeout)
+	defer cancel()
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Env = append(os.Environ(), eventEnv(ev)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	if err == nil {
+		numberOfRunCmds.WithLabelValues(e.name).Inc()
+		if e.Cfg.Debug {
+			e.logger.Printf("command %q ran in %s, stdout: %q, event: %s", name, time.Since(start), stdout.String(), b)
+		}
+		return
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		numberOfFailedCmds.WithLabelValues(e.name, "timeout").Inc()
+		e.WriteError(ctx.Err())
+		e.logger.Printf("command %q timed out after %s, event: %s", name, e.Cfg.Timeout, b)
+	case errors.As(err, &exitErr):
+		numberOfFailedCmds.WithLabelValues(e.name, "exit_error").Inc()
+		e.WriteError(err)
+		e.logger.Printf("command %q exited with code %d, stderr: %q, event: %s",
+			name, exitErr.ExitCode(), truncate(stderr.String(), maxStderrLogSize), b)
+	default:
+		numberOfFailedCmds.WithLabelValues(e.name, "start_error").Inc()
+		e.WriteError(err)
+		e.logger.Printf("failed to run command %q: %v, event: %s", name, err, b)
+	}
+}
+
+// commandLine executes the command and arguments templates using the JSON event b.
+func (e *execOutput) commandLine(b []byte) (string, []string, error) {
+	name, err := outputs.ExecTemplate(b, e.cmdTpl)
+	if err != nil {
+		return "", nil, err
+	}
+	cmdName := strings.TrimSpace(string(name))
+	if cmdName == "" {
+		return "", nil, errors.New("command template rendered an empty command")
+	}
+	args := make([]string, 0, len(e.argsTpl))
+	for _, tpl := range e.argsTpl {
+		arg, err := outputs.ExecTemplate(b, tpl)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, string(arg))
+	}
+	return cmdName, args, nil
+}
+
+// allow reports whether the command can run for key at time now,
+// recording the run if it can.
+func (e *execOutput) allow(key string, now time.Time) bool {
+	if e.Cfg.RateLimit <= 0 {
+		return true
+	}
+	e.m.Lock()
+	defer e.m.Unlock()
+	if last, ok := e.lastRun[key]; ok && now.Sub(last) < e.Cfg.RateLimit {
+		return false
+	}
+	if len(e.lastRun) >= maxRateLimitedPaths {
+		for k, last := range e.lastRun {
+			if now.Sub(last) >= e.Cfg.RateLimit {
+				delete(e.lastRun, k)
+			}
+		}
+	}
+	e.lastRun[key] = now
+	return true
+}
+
+// splitEvent returns an event per value of ev,
+// each one carrying the name, timestamp and tags of ev.
+func splitEvent(ev *formatters.EventMsg) []*formatters.EventMsg {
+	if len(ev.Values) <= 1 {
+		return []*formatters.EventMsg{ev}
+	}
+	paths := make([]string, 0, len(ev.Values))
+	for p := range ev.Values {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	evs := make([]*formatters.EventMsg, 0, len(paths))
+	for _, p := range paths {
+		evs = append(evs, &formatters.EventMsg{
+			Name:          ev.Name,
+			Timestamp:     ev.Timestamp,
+			RecvTimestamp: ev.RecvTimestamp,
+			Tags:          ev.Tags,
+			Values:        map[string]interface{}{p: ev.Values[p]},
+		})
+	}
+	if len(ev.Deletes) > 0 {
+		evs = append(evs, &formatters.EventMsg{
+			Name:          ev.Name,
+			Timestamp:     ev.Timestamp,
+			RecvTimestamp: ev.RecvTimestamp,
+			Tags:          ev.Tags,
+			Deletes:       ev.Deletes,
+		})
+	}
+	return evs
+}
+
+// eventPath returns the path of the value of a split event,
+// or its deleted paths.
+func eventPath(ev *formatters.EventMsg) string {
+	for p := range ev.Values {
+		return p
+	}
+	return strings.Join(ev.Deletes, ",")
+}
+
+// eventEnv returns the environment variables describing a split event.
+func eventEnv(ev *formatters.EventMsg) []string {
+	env := []string{
+		"GNMI_SUBSCRIPTION=" + ev.Name,
+		"GNMI_SOURCE=" + ev.Tags["source"],
+		"GNMI_TIMESTAMP=" + strconv.FormatInt(ev.Timestamp, 10),
+	}
+	for p, v := range ev.Values {
+		env = append(env, "GNMI_PATH="+p, "GNMI_VALUE="+valueString(v))
+	}
+	if len(ev.Deletes) > 0 {
+		env = append(env, "GNMI_DELETES="+strings.Join(ev.Deletes, ","))
+	}
+	return env
+}
+
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,112 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_output
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+func TestSplitEvent(t *testing.T) {
+	tags := map[string]string{"source": "r1"}
+	tests := map[string]struct {
+		in   *formatters.EventMsg
+		want []*formatters.EventMsg
+	}{
+		"single_value": {
+			in: &formatters.EventMsg{Name: "sub1", Tags: tags, Values: map[string]interface{}{"/a": 1}},
+			want: []*formatters.EventMsg{
+				{Name: "sub1", Tags: tags, Values: map[string]interface{}{"/a": 1}},
+			},
+		},
+		"multiple_values": {
+			in: &formatters.EventMsg{Name: "sub1", Tags: tags, Values: map[string]interface{}{"/b": 2, "/a": 1}, Deletes: []string{"/c"}},
+			want: []*formatters.EventMsg{
+				{Name: "sub1", Tags: tags, Values: map[string]interface{}{"/a": 1}},
+				{Name: "sub1", Tags: tags, Values: map[string]interface{}{"/b": 2}},
+				{Name: "sub1", Tags: tags, Deletes: []string{"/c"}},
+			},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := splitEvent(item.in)
+			if !reflect.DeepEqual(got, item.want) {
+				t.Logf("failed at item %q", name)
+				t.Logf("expected: %v", item.want)
+				t.Logf("     got: %v", got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	e := &execOutput{
+		Cfg:     &Config{RateLimit: time.Second},
+		m:       new(sync.Mutex),
+		lastRun: make(map[string]time.Time),
+	}
+	now := time.Now()
+	if !e.allow("r1/a", now) {
+		t.Errorf("expected the first run to be allowed")
+	}
+	if e.allow("r1/a", now.Add(500*time.Millisecond)) {
+		t.Errorf("expected a run within the rate limit to be dropped")
+	}
+	if !e.allow("r2/a", now.Add(500*time.Millisecond)) {
+		t.Errorf("expected a run for another key to be allowed")
+	}
+	if !e.allow("r1/a", now.Add(time.Second)) {
+		t.Errorf("expected a run after the rate limit to be allowed")
+	}
+}
+
+func TestRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	e := &execOutput{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := e.Init(ctx, "exec1", map[string]interface{}{
+		"command": "sh",
+		"args": []string{
+			"-c",
+			`printf '%s %s %s ' "$GNMI_SOURCE" "$GNMI_PATH" "$GNMI_VALUE" > "$0"; cat >> "$0"`,
+			out,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.run(ctx, &formatters.EventMsg{
+		Name:   "sub1",
+		Tags:   map[string]string{"source": "r1"},
+		Values: map[string]interface{}{"/interface/oper-state": "down"},
+	})
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `r1 /interface/oper-state down {"name":"sub1","tags":{"source":"r1"},"values":{"/interface/oper-state":"down"}}`
+	if string(b) != want {
+		t.Errorf("expected %q, got %q", want, string(b))
+	}
+}
@@ -9,6 +9,7 @@
 package file
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -16,6 +17,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 	"text/template"
 	"time"
 
@@ -32,6 +34,7 @@ const (
 	defaultFormat           = "json"
 	defaultWriteConcurrency = 1000
 	defaultSeparator        = "\n"
+	defaultFlushOnIdle      = time.Second
 	loggingPrefix           = "[file_output:%s] "
 )
 
@@ -46,6 +49,8 @@ func init() {
 
 // File //
 type File struct {
+	outputs.WriteErrorReporter
+
 	Cfg    *Config
 	file   *os.File
 	logger *log.Logger
@@ -55,24 +60,40 @@ type File struct {
 
 	targetTpl *template.Template
 	msgTpl    *template.Template
+	query     *formatters.Query
+
+	// buffered writer, set if buffer-size is set
+	m      *sync.Mutex
+	w      *bufio.Writer
+	writes chan struct{}
+	// highest sequence numbers written, per target
+	seqs outputs.SequenceTracker
 }
 
 // Config //
 type Config struct {
-	FileName           string   `mapstructure:"filename,omitempty"`
-	FileType           string   `mapstructure:"file-type,omitempty"`
-	Format             string   `mapstructure:"format,omitempty"`
-	Multiline          bool     `mapstructure:"multiline,omitempty"`
-	Indent             string   `mapstructure:"indent,omitempty"`
-	Separator          string   `mapstructure:"separator,omitempty"`
-	OverrideTimestamps bool     `mapstructure:"override-timestamps,omitempty"`
-	AddTarget          string   `mapstructure:"add-target,omitempty"`
-	TargetTemplate     string   `mapstructure:"target-template,omitempty"`
-	EventProcessors    []string `mapstructure:"event-processors,omitempty"`
-	MsgTemplate        string   `mapstructure:"msg-template,omitempty"`
-	ConcurrencyLimit   int      `mapstructure:"concurrency-limit,omitempty"`
-	EnableMetrics      bool     `mapstructure:"enable-metrics,omitempty"`
-	Debug              bool     `mapstructure:"debug,omitempty"`
+	FileName           string        `mapstructure:"filename,omitempty"`
+	FileType           string        `mapstructure:"file-type,omitempty"`
+	Format             string        `mapstructure:"format,omitempty"`
+	Multiline          bool          `mapstructure:"multiline,omitempty"`
+	Indent             string        `mapstructure:"indent,omitempty"`
+	Separator          string        `mapstructure:"separator,omitempty"`
+	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool          `mapstructure:"num-as-string,omitempty"`
+	IncludeMeta        bool          `mapstructure:"include-meta,omitempty"`
+	AddTarget          string        `mapstructure:"add-target,omitempty"`
+	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
+	EventProcessors    []string      `mapstructure:"event-processors,omitempty"`
+	MsgTemplate        string        `mapstructure:"msg-template,omitempty"`
+	Query              string        `mapstructure:"query,omitempty"`
+	QueryRaw           bool          `mapstructure:"query-raw,omitempty"`
+	ConcurrencyLimit   int           `mapstructure:"concurrency-limit,omitempty"`
+	BufferSize         int           `mapstructure:"buffer-size,omitempty"`
+	FlushInterval      time.Duration `mapstructure:"flush-interval,omitempty"`
+	FlushOnIdle        time.Duration `mapstructure:"flush-on-idle,omitempty"`
+	Sync               bool          `mapstructure:"sync,omitempty"`
+	EnableMetrics      bool          `mapstructure:"enable-metrics,omitempty"`
+	Debug              bool          `mapstructure:"debug,omitempty"`
 }
 
 func (f *File) String() string {
@@ -164,8 +185,10 @@ func (f *File) Init(ctx context.Context, name string, cfg map[string]interface{}
 		f.Cfg.Format = defaultFormat
 	}
 	if f.Cfg.FileType == "stdout" || f.Cfg.FileType == "stderr" {
-		f.Cfg.Indent = "  "
-		f.Cfg.Multiline = true
+		// multiline by default, unless explicitly disabled
+		if _, ok := cfg["multiline"]; !ok || f.Cfg.Multiline {
+			f.Cfg.Multiline = true
+		}
 	}
 	if f.Cfg.Multiline && f.Cfg.Indent == "" {
 		f.Cfg.Indent = "  "
@@ -175,12 +198,23 @@ func (f *File) Init(ctx context.Context, name string, cfg map[string]interface{}
 	}
 
 	f.sem = semaphore.NewWeighted(int64(f.Cfg.ConcurrencyLimit))
+	f.m = new(sync.Mutex)
+	if f.Cfg.FlushOnIdle == 0 {
+		f.Cfg.FlushOnIdle = defaultFlushOnIdle
+	}
+	if f.Cfg.BufferSize > 0 {
+		f.w = bufio.NewWriterSize(syncWriter{f}, f.Cfg.BufferSize)
+		f.writes = make(chan struct{}, 1)
+		go f.flushLoop(ctx)
+	}
 
 	f.mo = &formatters.MarshalOptions{
-		Multiline:  f.Cfg.Multiline,
-		Indent:     f.Cfg.Indent,
-		Format:     f.Cfg.Format,
-		OverrideTS: f.Cfg.OverrideTimestamps,
+		Multiline:   f.Cfg.Multiline,
+		Indent:      f.Cfg.Indent,
+		Format:      f.Cfg.Format,
+		OverrideTS:  f.Cfg.OverrideTimestamps,
+		NumAsString: f.Cfg.NumAsString,
+		IncludeMeta: f.Cfg.IncludeMeta,
 	}
 	if f.Cfg.TargetTemplate == "" {
 		f.targetTpl = outputs.DefaultTargetTemplate
@@ -200,6 +234,18 @@ func (f *File) Init(ctx context.Context, name string, cfg map[string]interface{}
 		f.msgTpl = f.msgTpl.Funcs(outputs.TemplateFuncs)
 	}
 
+	if f.Cfg.Query != "" {
+		switch f.Cfg.Format {
+		case "json", "protojson", "event":
+		default:
+			return fmt.Errorf("query requires a JSON format, got format %q", f.Cfg.Format)
+		}
+		f.query, err = formatters.NewQuery(f.Cfg.Query, f.Cfg.QueryRaw)
+		if err != nil {
+			return err
+		}
+	}
+
 	f.logger.Printf("initialized file output: %s", f.String())
 	go func() {
 		<-ctx.Done()
@@ -235,9 +281,29 @@ func (f *File) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta)
 			f.logger.Printf("failed marshaling proto msg: %v", err)
 		}
 		numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "marshal_error").Inc()
+		f.WriteError(err)
 		return
 	}
 
+	if f.query != nil && len(b) > 0 {
+		indent := ""
+		if f.Cfg.Multiline {
+			indent = f.Cfg.Indent
+		}
+		b, err = f.query.Apply(b, indent)
+		if err != nil {
+			if f.Cfg.Debug {
+				f.logger.Printf("failed to apply query: %v", err)
+			}
+			numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "query_error").Inc()
+			f.WriteError(err)
+			return
+		}
+		if len(b) == 0 {
+			return
+		}
+	}
+
 	if f.msgTpl != nil && len(b) > 0 {
 		b, err = outputs.ExecTemplate(b, f.msgTpl)
 		if err != nil {
@@ -245,27 +311,118 @@ func (f *File) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta)
 				log.Printf("failed to execute template: %v", err)
 			}
 			numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "template_error").Inc()
+			f.WriteError(err)
 			return
 		}
 	}
 
-	n, err := f.file.Write(append(b, []byte(f.Cfg.Separator)...))
+	n, err := f.write(append(b, []byte(f.Cfg.Separator)...))
 	if err != nil {
 		if f.Cfg.Debug {
 			f.logger.Printf("failed to write to file '%s': %v", f.file.Name(), err)
 		}
 		numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "write_error").Inc()
+		f.WriteError(err)
 		return
 	}
 	numberOfWrittenBytes.WithLabelValues(f.file.Name()).Add(float64(n))
 	numberOfWrittenMsgs.WithLabelValues(f.file.Name()).Inc()
+	f.seqs.Record(meta)
+}
+
+// write writes b to the buffer if the output is buffered, to the file otherwise.
+func (f *File) write(b []byte) (int, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.w == nil {
+		return syncWriter{f}.Write(b)
+	}
+	n, err := f.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	select {
+	case f.writes <- struct{}{}:
+	default:
+	}
+	return n, nil
+}
+
+// flushLoop flushes the buffer every flush-interval and when no message
+// was written for flush-on-idle, so that readers such as `tail -f` do not lag at low rates.
+func (f *File) flushLoop(ctx context.Context) {
+	var intervalCh <-chan time.Time
+	if f.Cfg.FlushInterval > 0 {
+		ticker := time.NewTicker(f.Cfg.FlushInterval)
+		defer ticker.Stop()
+		intervalCh = ticker.C
+	}
+	idle := time.NewTimer(0)
+	if !idle.Stop() {
+		<-idle.C
+	}
+	defer idle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.writes:
+			if f.Cfg.FlushOnIdle < 0 {
+				continue
+			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(f.Cfg.FlushOnIdle)
+		case <-idle.C:
+			f.flush()
+		case <-intervalCh:
+			f.flush()
+		}
+	}
+}
+
+// flush writes the buffered messages to the file.
+func (f *File) flush() {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.w == nil || f.w.Buffered() == 0 {
+		return
+	}
+	err := f.w.Flush()
+	if err != nil {
+		f.logger.Printf("failed to flush file '%s': %v", f.file.Name(), err)
+	}
+}
+
+// syncWriter writes to the output file and commits its content
+// to disk if sync is set, it is used with the lock held.
+type syncWriter struct {
+	f *File
+}
+
+func (w syncWriter) Write(b []byte) (int, error) {
+	n, err := w.f.file.Write(b)
+	if err != nil || !w.f.Cfg.Sync || w.f.file == os.Stdout || w.f.file == os.Stderr {
+		return n, err
+	}
+	return n, w.f.file.Sync()
 }
 
 func (f *File) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
 
+// WrittenSequences implements outputs.SequenceReporter.
+func (f *File) WrittenSequences() map[string]uint64 {
+	return f.seqs.WrittenSequences()
+}
+
 // Close //
 func (f *File) Close() error {
 	f.logger.Printf("closing file '%s' output", f.file.Name())
+	f.flush()
 	return f.file.Close()
 }
 
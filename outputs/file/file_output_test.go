@@ -0,0 +1,207 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/outputs"
+)
+
+var testRsp = &gnmi.SubscribeResponse{
+	Response: &gnmi.SubscribeResponse_Update{
+		Update: &gnmi.Notification{
+			Timestamp: 1,
+			Prefix:    &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}}},
+			Update: []*gnmi.Update{
+				{
+					Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interface", Key: map[string]string{"name": "e1"}}, {Name: "mtu"}}},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 1500}},
+				},
+			},
+		},
+	},
+}
+
+func newTestFile(tb testing.TB, ctx context.Context, cfg map[string]interface{}) *File {
+	tb.Helper()
+	cfg["filename"] = filepath.Join(tb.TempDir(), "out.json")
+	f := &File{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+	}
+	err := f.Init(ctx, "test", cfg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return f
+}
+
+func fileSize(t *testing.T, f *File) int64 {
+	fi, err := os.Stat(f.Cfg.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestFlushOnIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newTestFile(t, ctx, map[string]interface{}{
+		"buffer-size":   1 << 20,
+		"flush-on-idle": "50ms",
+	})
+	f.Write(ctx, testRsp, outputs.Meta{"source": "r1"})
+	if n := fileSize(t, f); n != 0 {
+		t.Fatalf("expected the message to be buffered, file size is %d", n)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for fileSize(t, f) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the buffer was not flushed after the idle period")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseFlushes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newTestFile(t, ctx, map[string]interface{}{
+		"buffer-size":   1 << 20,
+		"flush-on-idle": "-1s",
+		"sync":          true,
+	})
+	f.Write(ctx, testRsp, outputs.Meta{"source": "r1"})
+	if n := fileSize(t, f); n != 0 {
+		t.Fatalf("expected the message to be buffered, file size is %d", n)
+	}
+	err := f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileSize(t, f) == 0 {
+		t.Fatal("expected the buffer to be flushed on close")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		raw   bool
+		out   string
+	}{
+		"value": {
+			query: ".updates[].values[]",
+			out:   "1500\n",
+		},
+		"raw_string": {
+			query: ".source",
+			raw:   true,
+			out:   "r1\n",
+		},
+		"no_result": {
+			query: ".updates[].values[] | select(. > 9000)",
+			out:   "",
+		},
+	}
+	for name, item := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		f := newTestFile(t, ctx, map[string]interface{}{
+			"query":     item.query,
+			"query-raw": item.raw,
+		})
+		f.Write(ctx, testRsp, outputs.Meta{"source": "r1"})
+		b, err := os.ReadFile(f.Cfg.FileName)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != item.out {
+			t.Errorf("failed at item %q: expected %q, got %q", name, item.out, string(b))
+		}
+	}
+}
+
+func TestQueryInitError(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"parse_error":  {"query": ".updates[] |"},
+		"proto_format": {"query": ".", "format": "prototext"},
+	} {
+		cfg["filename"] = filepath.Join(t.TempDir(), "out.json")
+		f := &File{
+			Cfg:    &Config{},
+			logger: log.New(io.Discard, "", 0),
+		}
+		err := f.Init(context.Background(), "test", cfg)
+		if err == nil {
+			t.Errorf("failed at item %q: expected an error", name)
+		}
+	}
+}
+
+func TestWriteErrorHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := &File{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+	}
+	var errs []error
+	err := f.Init(ctx, "test", map[string]interface{}{
+		"filename": filepath.Join(t.TempDir(), "out.json"),
+		"query":    `error("boom")`,
+	}, outputs.WithWriteErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(ctx, testRsp, outputs.Meta{"source": "r1"})
+	if len(errs) != 1 {
+		t.Errorf("expected 1 reported write error, got %d: %v", len(errs), errs)
+	}
+}
+
+// BenchmarkWrite compares the write throughput of the file output
+// buffering modes, with and without fsync.
+// Run with: go test ./outputs/file/ -bench Write -benchmem
+func BenchmarkWrite(b *testing.B) {
+	benchmarks := map[string]map[string]interface{}{
+		"unbuffered":            {},
+		"unbuffered_sync":       {"sync": true},
+		"buffered_64KiB":        {"buffer-size": 64 << 10},
+		"buffered_64KiB_sync":   {"buffer-size": 64 << 10, "sync": true},
+		"buffered_1MiB_flush":   {"buffer-size": 1 << 20, "flush-interval": "100ms"},
+		"buffered_1MiB_no_idle": {"buffer-size": 1 << 20, "flush-on-idle": "-1s"},
+	}
+	for name, cfg := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			f := newTestFile(b, ctx, cfg)
+			meta := outputs.Meta{"source": "r1"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f.Write(ctx, testRsp, meta)
+			}
+			b.StopTimer()
+			f.Close()
+		})
+	}
+}
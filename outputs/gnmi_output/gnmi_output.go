@@ -180,6 +180,14 @@ func (g *gNMIOutput) SetName(string) {}
 
 func (g *gNMIOutput) SetClusterName(string) {}
 
+// SetReadOnly makes the gNMI server refuse the Set RPCs.
+func (g *gNMIOutput) SetReadOnly(readOnly bool) {
+	if g.srv == nil {
+		return
+	}
+	g.srv.readOnly = readOnly
+}
+
 func (g *gNMIOutput) SetTargetsConfig(tcs map[string]*types.TargetConfig) {
 	if g.srv == nil {
 		return
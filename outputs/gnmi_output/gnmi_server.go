@@ -55,6 +55,8 @@ type server struct {
 	//
 	mu      *sync.RWMutex
 	targets map[string]*types.TargetConfig
+	// Set RPCs are refused
+	readOnly bool
 }
 
 type matchClient struct {
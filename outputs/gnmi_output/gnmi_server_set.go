@@ -25,6 +25,9 @@ import (
 )
 
 func (s *server) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	if s.readOnly {
+		return nil, status.Error(codes.FailedPrecondition, types.ErrReadOnly.Error())
+	}
 	ok := s.unaryRPCsem.TryAcquire(1)
 	if !ok {
 		return nil, status.Errorf(codes.ResourceExhausted, "max number of Unary RPC reached")
@@ -98,7 +101,9 @@ func (s *server) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetRespon
 			if creq.GetPrefix().GetTarget() == "" || creq.GetPrefix().GetTarget() == "*" {
 				creq.Prefix.Target = name
 			}
+			start := time.Now()
 			res, err := t.Set(ctx, creq)
+			types.AuditSet(name, creq, res, err, start)
 			if err != nil {
 				s.l.Printf("target %q err: %v", name, err)
 				errChan <- fmt.Errorf("target %q err: %v", name, err)
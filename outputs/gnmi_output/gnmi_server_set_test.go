@@ -0,0 +1,30 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gnmi_output
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetReadOnly(t *testing.T) {
+	g := &gNMIOutput{cfg: new(config)}
+	g.srv = g.newServer()
+	g.SetReadOnly(true)
+	_, err := g.srv.Set(context.Background(), &gnmi.SetRequest{
+		Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "system"}}}},
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected a FailedPrecondition error, got: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_output
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs/diskbuffer"
+)
+
+const (
+	defaultBufferMaxSize    = 1024 * 1024 * 1024 // 1GiB
+	defaultBufferDrainTimer = time.Second
+)
+
+var errServerDown = errors.New("influxdb server unavailable")
+
+func (i *influxDBOutput) initBuffer(ctx context.Context) error {
+	var err error
+	i.buffer, err = diskbuffer.Open(i.Cfg.BufferPath, i.Cfg.BufferMaxSize, i.logger)
+	if err != nil {
+		return err
+	}
+	go i.drainBuffer(ctx)
+	return nil
+}
+
+// send writes the event to the workers, unless the server is unavailable
+// or older events are still buffered on disk, in which case
+// the event is appended to the disk buffer.
+func (i *influxDBOutput) send(ctx context.Context, ev *formatters.EventMsg) {
+	if i.buffer != nil && (!i.up.Load() || i.buffer.Len() > 0) {
+		i.spool(ev)
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-i.reset:
+		if i.buffer != nil {
+			i.spool(ev)
+		}
+	case i.eventChan <- ev:
+	}
+}
+
+// spool writes the event to the disk buffer in line protocol.
+func (i *influxDBOutput) spool(ev *formatters.EventMsg) {
+	if len(ev.Values) == 0 {
+		return
+	}
+	rec := write.PointToLineProtocol(i.eventToPoint(ev), i.precision())
+	err := i.buffer.Write([]byte(rec))
+	if err != nil {
+		i.logger.Printf("failed to write event to disk buffer: %v", err)
+	}
+}
+
+// drainBuffer periodically writes the buffered records to the server, oldest first,
+// while the server is available.
+// The records are written in batches using the blocking write API,
+// a batch is removed from the buffer only once the server accepted it.
+func (i *influxDBOutput) drainBuffer(ctx context.Context) {
+	ticker := time.NewTicker(defaultBufferDrainTimer)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !i.up.Load() || i.buffer.Len() == 0 {
+				continue
+			}
+			writer := i.client.WriteAPIBlocking(i.Cfg.Org, i.Cfg.Bucket)
+			count := 0
+			err := i.buffer.DrainBatch(int(i.Cfg.BatchSize), func(recs [][]byte) error {
+				if !i.up.Load() {
+					return errServerDown
+				}
+				lines := make([]string, 0, len(recs))
+				for _, rec := range recs {
+					lines = append(lines, string(rec))
+				}
+				err := writer.WriteRecord(ctx, lines...)
+				if err != nil {
+					return err
+				}
+				count += len(recs)
+				return nil
+			})
+			if err != nil {
+				i.logger.Printf("disk buffer drain interrupted after %d record(s): %v", count, err)
+				continue
+			}
+			if i.Cfg.Debug {
+				i.logger.Printf("drained %d record(s) from disk buffer", count)
+			}
+		}
+	}
+}
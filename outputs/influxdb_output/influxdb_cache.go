@@ -83,10 +83,9 @@ func (i *influxDBOutput) readCache(ctx context.Context, name string) {
 	}
 
 	for _, ev := range events {
-		select {
-		case <-i.reset:
+		if ctx.Err() != nil {
 			return
-		case i.eventChan <- ev:
 		}
+		i.send(ctx, ev)
 	}
 }
@@ -17,14 +17,17 @@ import (
 	"log"
 	"math"
 	"strings"
+	"sync/atomic"
 	"text/template"
 	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmic/cache"
 	"github.com/openconfig/gnmic/formatters"
 	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/outputs/diskbuffer"
 	"github.com/openconfig/gnmic/types"
 	"github.com/openconfig/gnmic/utils"
 	"github.com/prometheus/client_golang/prometheus"
@@ -55,6 +58,8 @@ func init() {
 }
 
 type influxDBOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg       *Config
 	client    influxdb2.Client
 	logger    *log.Logger
@@ -63,6 +68,7 @@ type influxDBOutput struct {
 	reset     chan struct{}
 	startSig  chan struct{}
 	wasUP     bool
+	up        atomic.Bool
 	evps      []formatters.EventProcessor
 	dbVersion string
 
@@ -71,6 +77,8 @@ type influxDBOutput struct {
 	gnmiCache   cache.Cache
 	cacheTicker *time.Ticker
 	done        chan struct{}
+
+	buffer *diskbuffer.Buffer
 }
 
 type Config struct {
@@ -92,6 +100,8 @@ type Config struct {
 	TimestampPrecision string        `mapstructure:"timestamp-precision,omitempty"`
 	CacheConfig        *cache.Config `mapstructure:"cache,omitempty"`
 	CacheFlushTimer    time.Duration `mapstructure:"cache-flush-timer,omitempty"`
+	BufferPath         string        `mapstructure:"buffer-path,omitempty"`
+	BufferMaxSize      int64         `mapstructure:"buffer-max-size,omitempty"`
 }
 
 func (k *influxDBOutput) String() string {
@@ -171,6 +181,12 @@ func (i *influxDBOutput) Init(ctx context.Context, name string, cfg map[string]i
 	}
 
 	ctx, i.cancelFn = context.WithCancel(ctx)
+	if i.Cfg.BufferPath != "" {
+		err = i.initBuffer(ctx)
+		if err != nil {
+			return err
+		}
+	}
 CRCLIENT:
 	i.client = influxdb2.NewClientWithOptions(i.Cfg.URL, i.Cfg.Token, i.clientOpts())
 	// start influx health check
@@ -178,8 +194,11 @@ CRCLIENT:
 		err = i.health(ctx)
 		if err != nil {
 			i.logger.Printf("failed to check influxdb health: %v", err)
-			time.Sleep(10 * time.Second)
-			goto CRCLIENT
+			// with a disk buffer, events are spooled until the server is up
+			if i.buffer == nil {
+				time.Sleep(10 * time.Second)
+				goto CRCLIENT
+			}
 		}
 		go i.healthCheck(ctx)
 	}
@@ -209,6 +228,15 @@ func (i *influxDBOutput) setDefaults() {
 	if i.Cfg.HealthCheckPeriod != 0 && i.Cfg.HealthCheckPeriod < minHealthCheckPeriod {
 		i.Cfg.HealthCheckPeriod = minHealthCheckPeriod
 	}
+	if i.Cfg.BufferPath != "" {
+		if i.Cfg.BufferMaxSize <= 0 {
+			i.Cfg.BufferMaxSize = defaultBufferMaxSize
+		}
+		// the health check detects the server outages
+		if i.Cfg.HealthCheckPeriod == 0 {
+			i.Cfg.HealthCheckPeriod = minHealthCheckPeriod
+		}
+	}
 	if i.Cfg.CacheConfig != nil {
 		if i.Cfg.CacheFlushTimer == 0 {
 			i.Cfg.CacheFlushTimer = defaultCacheFlushTimer
@@ -241,13 +269,10 @@ func (i *influxDBOutput) Write(ctx context.Context, rsp proto.Message, meta outp
 			return
 		}
 		for _, ev := range events {
-			select {
-			case <-ctx.Done():
-				return
-			case <-i.reset:
+			if ctx.Err() != nil {
 				return
-			case i.eventChan <- ev:
 			}
+			i.send(ctx, ev)
 		}
 	}
 }
@@ -264,7 +289,7 @@ func (i *influxDBOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg
 			evs = proc.Apply(evs...)
 		}
 		for _, pev := range evs {
-			i.eventChan <- pev
+			i.send(ctx, pev)
 		}
 	}
 }
@@ -275,6 +300,9 @@ func (i *influxDBOutput) Close() error {
 		i.stopCache()
 	}
 	i.cancelFn()
+	if i.buffer != nil {
+		i.buffer.Close()
+	}
 	i.logger.Printf("closed.")
 	return nil
 }
@@ -296,6 +324,7 @@ func (i *influxDBOutput) health(ctx context.Context) error {
 	res, err := i.client.Health(ctx)
 	if err != nil {
 		i.logger.Printf("failed health check: %v", err)
+		i.up.Store(false)
 		if i.wasUP {
 			close(i.reset)
 			i.reset = make(chan struct{})
@@ -310,6 +339,7 @@ func (i *influxDBOutput) health(ctx context.Context) error {
 		if err != nil {
 			i.logger.Printf("failed to marshal health check result: %v", err)
 			i.logger.Printf("health check result: %+v", res)
+			i.up.Store(false)
 			if i.wasUP {
 				close(i.reset)
 				i.reset = make(chan struct{})
@@ -317,12 +347,14 @@ func (i *influxDBOutput) health(ctx context.Context) error {
 			return err
 		}
 		i.wasUP = true
+		i.up.Store(true)
 		close(i.startSig)
 		i.startSig = make(chan struct{})
 		i.logger.Printf("health check result: %s", string(b))
 		return nil
 	}
 	i.wasUP = true
+	i.up.Store(true)
 	close(i.startSig)
 	i.startSig = make(chan struct{})
 	i.logger.Print("health check result is nil")
@@ -351,32 +383,37 @@ START:
 			if len(ev.Values) == 0 {
 				continue
 			}
-			for n, v := range ev.Values {
-				switch v := v.(type) {
-				//lint:ignore SA1019 still need DecimalVal for backward compatibility
-				case *gnmi.Decimal64:
-					ev.Values[n] = float64(v.Digits) / math.Pow10(int(v.Precision))
-				}
-			}
-			if ev.Timestamp == 0 || i.Cfg.OverrideTimestamps {
-				ev.Timestamp = time.Now().UnixNano()
-			}
-			if subscriptionName, ok := ev.Tags["subscription-name"]; ok {
-				ev.Name = subscriptionName
-				delete(ev.Tags, "subscription-name")
-			}
-			i.convertUints(ev)
-			writer.WritePoint(influxdb2.NewPoint(ev.Name, ev.Tags, ev.Values, time.Unix(0, ev.Timestamp)))
+			writer.WritePoint(i.eventToPoint(ev))
 		case <-i.reset:
 			firstStart = false
 			i.logger.Printf("resetting worker-%d...", idx)
 			goto START
 		case err := <-writer.Errors():
 			i.logger.Printf("worker-%d write error: %v", idx, err)
+			i.WriteError(err)
 		}
 	}
 }
 
+func (i *influxDBOutput) eventToPoint(ev *formatters.EventMsg) *write.Point {
+	for n, v := range ev.Values {
+		switch v := v.(type) {
+		//lint:ignore SA1019 still need DecimalVal for backward compatibility
+		case *gnmi.Decimal64:
+			ev.Values[n] = float64(v.Digits) / math.Pow10(int(v.Precision))
+		}
+	}
+	if ev.Timestamp == 0 || i.Cfg.OverrideTimestamps {
+		ev.Timestamp = time.Now().UnixNano()
+	}
+	if subscriptionName, ok := ev.Tags["subscription-name"]; ok {
+		ev.Name = subscriptionName
+		delete(ev.Tags, "subscription-name")
+	}
+	i.convertUints(ev)
+	return influxdb2.NewPoint(ev.Name, ev.Tags, ev.Values, time.Unix(0, ev.Timestamp))
+}
+
 func (i *influxDBOutput) SetName(name string)                             {}
 func (i *influxDBOutput) SetClusterName(name string)                      {}
 func (i *influxDBOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
@@ -412,16 +449,21 @@ func (i *influxDBOutput) clientOpts() *influxdb2.Options {
 			InsecureSkipVerify: true,
 		})
 	}
+	iopts.SetPrecision(i.precision())
+	if i.Cfg.Debug {
+		iopts.SetLogLevel(3)
+	}
+	return iopts
+}
+
+func (i *influxDBOutput) precision() time.Duration {
 	switch i.Cfg.TimestampPrecision {
 	case "s":
-		iopts.SetPrecision(time.Second)
+		return time.Second
 	case "ms":
-		iopts.SetPrecision(time.Millisecond)
+		return time.Millisecond
 	case "us":
-		iopts.SetPrecision(time.Microsecond)
+		return time.Microsecond
 	}
-	if i.Cfg.Debug {
-		iopts.SetLogLevel(3)
-	}
-	return iopts
+	return time.Nanosecond
 }
@@ -54,6 +54,8 @@ func init() {
 
 // KafkaOutput //
 type KafkaOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg      *Config
 	logger   sarama.StdLogger
 	mo       *formatters.MarshalOptions
@@ -84,6 +86,7 @@ type Config struct {
 	Debug              bool             `mapstructure:"debug,omitempty"`
 	BufferSize         int              `mapstructure:"buffer-size,omitempty"`
 	OverrideTimestamps bool             `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool             `mapstructure:"num-as-string,omitempty"`
 	EnableMetrics      bool             `mapstructure:"enable-metrics,omitempty"`
 	EventProcessors    []string         `mapstructure:"event-processors,omitempty"`
 }
@@ -154,8 +157,9 @@ func (k *KafkaOutput) Init(ctx context.Context, name string, cfg map[string]inte
 	}
 	k.msgChan = make(chan *outputs.ProtoMsg, uint(k.Cfg.BufferSize))
 	k.mo = &formatters.MarshalOptions{
-		Format:     k.Cfg.Format,
-		OverrideTS: k.Cfg.OverrideTimestamps,
+		Format:      k.Cfg.Format,
+		OverrideTS:  k.Cfg.OverrideTimestamps,
+		NumAsString: k.Cfg.NumAsString,
 	}
 
 	if k.Cfg.TargetTemplate == "" {
@@ -257,6 +261,7 @@ func (k *KafkaOutput) Write(ctx context.Context, rsp proto.Message, meta outputs
 		if k.Cfg.EnableMetrics {
 			kafkaNumberOfFailSendMsgs.WithLabelValues(k.Cfg.Name, "timeout").Inc()
 		}
+		k.WriteError(wctx.Err())
 		return
 	}
 }
@@ -314,6 +319,7 @@ CRPROD:
 				if k.Cfg.EnableMetrics {
 					kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "marshal_error").Inc()
 				}
+				k.WriteError(err)
 				continue
 			}
 
@@ -324,6 +330,7 @@ CRPROD:
 						log.Printf("failed to execute template: %v", err)
 					}
 					kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "template_error").Inc()
+					k.WriteError(err)
 					return
 				}
 			}
@@ -345,6 +352,7 @@ CRPROD:
 				if k.Cfg.EnableMetrics {
 					kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "send_error").Inc()
 				}
+				k.WriteError(err)
 				producer.Close()
 				time.Sleep(k.Cfg.RecoveryWaitTime)
 				goto CRPROD
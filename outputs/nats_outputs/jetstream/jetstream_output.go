@@ -80,6 +80,7 @@ type config struct {
 	TargetTemplate     string              `mapstructure:"target-template,omitempty" json:"target-template,omitempty"`
 	MsgTemplate        string              `mapstructure:"msg-template,omitempty" json:"msg-template,omitempty"`
 	OverrideTimestamps bool                `mapstructure:"override-timestamps,omitempty" json:"override-timestamps,omitempty"`
+	NumAsString        bool                `mapstructure:"num-as-string,omitempty" json:"num-as-string,omitempty"`
 	NumWorkers         int                 `mapstructure:"num-workers,omitempty" json:"num-workers,omitempty"`
 	WriteTimeout       time.Duration       `mapstructure:"write-timeout,omitempty" json:"write-timeout,omitempty"`
 	Debug              bool                `mapstructure:"debug,omitempty" json:"debug,omitempty"`
@@ -106,6 +107,8 @@ type tls struct {
 
 // jetstreamOutput //
 type jetstreamOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg      *config
 	ctx      context.Context
 	cancelFn context.CancelFunc
@@ -140,8 +143,9 @@ func (n *jetstreamOutput) Init(ctx context.Context, name string, cfg map[string]
 	n.msgChan = make(chan *outputs.ProtoMsg)
 	initMetrics()
 	n.mo = &formatters.MarshalOptions{
-		Format:     n.Cfg.Format,
-		OverrideTS: n.Cfg.OverrideTimestamps,
+		Format:      n.Cfg.Format,
+		OverrideTS:  n.Cfg.OverrideTimestamps,
+		NumAsString: n.Cfg.NumAsString,
 	}
 	if n.Cfg.TargetTemplate == "" {
 		n.targetTpl = outputs.DefaultTargetTemplate
@@ -246,6 +250,7 @@ func (n *jetstreamOutput) Write(ctx context.Context, rsp proto.Message, meta out
 		if n.Cfg.EnableMetrics {
 			jetStreamNumberOfFailSendMsgs.WithLabelValues(n.Cfg.Name, "timeout").Inc()
 		}
+		n.WriteError(wctx.Err())
 		return
 	}
 }
@@ -412,6 +417,7 @@ CRCONN:
 					if n.Cfg.EnableMetrics {
 						jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "marshal_error").Inc()
 					}
+					n.WriteError(err)
 					continue
 				}
 
@@ -422,6 +428,7 @@ CRCONN:
 							log.Printf("failed to execute template: %v", err)
 						}
 						jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "template_error").Inc()
+						n.WriteError(err)
 						return
 					}
 				}
@@ -434,6 +441,7 @@ CRCONN:
 					if n.Cfg.EnableMetrics {
 						jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "subject_name_error").Inc()
 					}
+					n.WriteError(err)
 					continue
 				}
 				var start time.Time
@@ -448,6 +456,7 @@ CRCONN:
 					if n.Cfg.EnableMetrics {
 						jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "publish_error").Inc()
 					}
+					n.WriteError(err)
 					natsConn.Close()
 					time.Sleep(cfg.ConnectTimeWait)
 					goto CRCONN
@@ -53,6 +53,8 @@ func init() {
 
 // NatsOutput //
 type NatsOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg      *Config
 	ctx      context.Context
 	cancelFn context.CancelFunc
@@ -80,6 +82,7 @@ type Config struct {
 	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
 	MsgTemplate        string        `mapstructure:"msg-template,omitempty"`
 	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool          `mapstructure:"num-as-string,omitempty"`
 	NumWorkers         int           `mapstructure:"num-workers,omitempty"`
 	WriteTimeout       time.Duration `mapstructure:"write-timeout,omitempty"`
 	Debug              bool          `mapstructure:"debug,omitempty"`
@@ -156,8 +159,9 @@ func (n *NatsOutput) Init(ctx context.Context, name string, cfg map[string]inter
 	n.msgChan = make(chan *outputs.ProtoMsg)
 	initMetrics()
 	n.mo = &formatters.MarshalOptions{
-		Format:     n.Cfg.Format,
-		OverrideTS: n.Cfg.OverrideTimestamps,
+		Format:      n.Cfg.Format,
+		OverrideTS:  n.Cfg.OverrideTimestamps,
+		NumAsString: n.Cfg.NumAsString,
 	}
 	if n.Cfg.TargetTemplate == "" {
 		n.targetTpl = outputs.DefaultTargetTemplate
@@ -240,6 +244,7 @@ func (n *NatsOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.
 		if n.Cfg.EnableMetrics {
 			NatsNumberOfFailSendMsgs.WithLabelValues(n.Cfg.Name, "timeout").Inc()
 		}
+		n.WriteError(wctx.Err())
 		return
 	}
 }
@@ -351,6 +356,7 @@ CRCONN:
 				if n.Cfg.EnableMetrics {
 					NatsNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "marshal_error").Inc()
 				}
+				n.WriteError(err)
 				continue
 			}
 
@@ -361,6 +367,7 @@ CRCONN:
 						log.Printf("failed to execute template: %v", err)
 					}
 					NatsNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "template_error").Inc()
+					n.WriteError(err)
 					return
 				}
 			}
@@ -378,6 +385,7 @@ CRCONN:
 				if n.Cfg.EnableMetrics {
 					NatsNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "publish_error").Inc()
 				}
+				n.WriteError(err)
 				natsConn.Close()
 				time.Sleep(cfg.ConnectTimeWait)
 				goto CRCONN
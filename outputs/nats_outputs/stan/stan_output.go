@@ -56,6 +56,8 @@ func init() {
 
 // StanOutput //
 type StanOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg      *Config
 	cancelFn context.CancelFunc
 	logger   *log.Logger
@@ -82,6 +84,7 @@ type Config struct {
 	AddTarget          string        `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
 	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool          `mapstructure:"num-as-string,omitempty"`
 	RecoveryWaitTime   time.Duration `mapstructure:"recovery-wait-time,omitempty"`
 	NumWorkers         int           `mapstructure:"num-workers,omitempty"`
 	Debug              bool          `mapstructure:"debug,omitempty"`
@@ -155,8 +158,9 @@ func (s *StanOutput) Init(ctx context.Context, name string, cfg map[string]inter
 	s.msgChan = make(chan *outputs.ProtoMsg)
 
 	s.mo = &formatters.MarshalOptions{
-		Format:     s.Cfg.Format,
-		OverrideTS: s.Cfg.OverrideTimestamps,
+		Format:      s.Cfg.Format,
+		OverrideTS:  s.Cfg.OverrideTimestamps,
+		NumAsString: s.Cfg.NumAsString,
 	}
 
 	if s.Cfg.TargetTemplate == "" {
@@ -241,6 +245,7 @@ func (s *StanOutput) Write(ctx context.Context, rsp protoreflect.ProtoMessage, m
 		if s.Cfg.EnableMetrics {
 			StanNumberOfFailSendMsgs.WithLabelValues(s.Cfg.Name, "timeout").Inc()
 		}
+		s.WriteError(wctx.Err())
 		return
 	}
 }
@@ -332,6 +337,7 @@ CRCONN:
 				if s.Cfg.EnableMetrics {
 					StanNumberOfFailSendMsgs.WithLabelValues(c.Name, "marshal_error").Inc()
 				}
+				s.WriteError(err)
 				continue
 			}
 			subject := s.subjectName(c, m.GetMeta())
@@ -344,6 +350,7 @@ CRCONN:
 				if s.Cfg.EnableMetrics {
 					StanNumberOfFailSendMsgs.WithLabelValues(c.Name, "publish_error").Inc()
 				}
+				s.WriteError(err)
 				stanConn.Close()
 				stanConn.NatsConn().Close()
 				time.Sleep(c.RecoveryWaitTime)
@@ -9,6 +9,7 @@
 package outputs
 
 import (
+	"errors"
 	"log"
 
 	"github.com/openconfig/gnmic/types"
@@ -55,3 +56,53 @@ func WithTargetsConfig(tcs map[string]*types.TargetConfig) Option {
 		o.SetTargetsConfig(tcs)
 	}
 }
+
+// ReadOnlyOutput is implemented by the outputs sending Set RPCs to the targets.
+type ReadOnlyOutput interface {
+	SetReadOnly(bool)
+}
+
+// WithReadOnly makes the outputs sending Set RPCs to the targets refuse them.
+func WithReadOnly(readOnly bool) Option {
+	return func(o Output) {
+		if ro, ok := o.(ReadOnlyOutput); ok {
+			ro.SetReadOnly(readOnly)
+		}
+	}
+}
+
+// ErrBufferFull is reported by the outputs dropping a message
+// because their internal buffer is full.
+var ErrBufferFull = errors.New("buffer full")
+
+// WriteErrorOutput is implemented by the outputs reporting their failed writes.
+type WriteErrorOutput interface {
+	SetWriteErrorHandler(func(error))
+}
+
+// WithWriteErrorHandler sets the function called by the output
+// each time it fails to write a message.
+func WithWriteErrorHandler(fn func(error)) Option {
+	return func(o Output) {
+		if we, ok := o.(WriteErrorOutput); ok {
+			we.SetWriteErrorHandler(fn)
+		}
+	}
+}
+
+// WriteErrorReporter implements WriteErrorOutput,
+// it is embedded by the outputs reporting their failed writes.
+type WriteErrorReporter struct {
+	writeErrorHandler func(error)
+}
+
+func (r *WriteErrorReporter) SetWriteErrorHandler(fn func(error)) {
+	r.writeErrorHandler = fn
+}
+
+// WriteError reports a failed write to the handler, if any.
+func (r *WriteErrorReporter) WriteError(err error) {
+	if r.writeErrorHandler != nil {
+		r.writeErrorHandler(err)
+	}
+}
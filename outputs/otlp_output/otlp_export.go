@@ -0,0 +1,162 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp_output
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// worker exports the buffered datapoints once batch-size datapoints are buffered
+// or every flush-interval. The datapoints not exported once ctx is done
+// are exported by Close.
+func (o *otlpOutput) worker(ctx context.Context) {
+	defer close(o.done)
+	ticker := time.NewTicker(o.Cfg.FlushInterval)
+	defer ticker.Stop()
+	o.batch = make([]*dataPoint, 0, o.Cfg.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case dp := <-o.buffer:
+			o.batch = append(o.batch, dp)
+			if len(o.batch) < o.Cfg.BatchSize {
+				continue
+			}
+			o.flush(ctx, o.batch)
+			o.batch = make([]*dataPoint, 0, o.Cfg.BatchSize)
+		case <-ticker.C:
+			if len(o.batch) == 0 {
+				continue
+			}
+			o.flush(ctx, o.batch)
+			o.batch = make([]*dataPoint, 0, o.Cfg.BatchSize)
+		}
+	}
+}
+
+// drain exports batch and the datapoints left in the buffer within the output timeout.
+func (o *otlpOutput) drain(batch []*dataPoint) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.Cfg.Timeout)
+	defer cancel()
+	for {
+		select {
+		case dp := <-o.buffer:
+			batch = append(batch, dp)
+			if len(batch) < o.Cfg.BatchSize {
+				continue
+			}
+			o.flush(ctx, batch)
+			batch = make([]*dataPoint, 0, o.Cfg.BatchSize)
+		default:
+			if len(batch) > 0 {
+				o.flush(ctx, batch)
+			}
+			if o.Cfg.Debug {
+				o.logger.Printf("buffer drained")
+			}
+			return
+		}
+	}
+}
+
+// flush exports dps, the export requests failing with a retryable status code
+// are retried up to max-retries times with an exponential backoff.
+func (o *otlpOutput) flush(ctx context.Context, dps []*dataPoint) {
+	req := &rawMessage{b: exportRequest(o.resource, scopeName, dps)}
+	backoff := o.Cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := o.export(ctx, req, len(dps))
+		if err == nil {
+			return
+		}
+		o.logger.Printf("export request failed: %v", err)
+		if !retryable(err) {
+			numberOfFailedDataPoints.WithLabelValues(o.name, "rejected").Add(float64(len(dps)))
+			o.WriteError(err)
+			return
+		}
+		if attempt >= o.Cfg.MaxRetries {
+			numberOfFailedDataPoints.WithLabelValues(o.name, "retries_exhausted").Add(float64(len(dps)))
+			o.WriteError(err)
+			o.logger.Printf("dropping %d datapoint(s) after %d retries", len(dps), o.Cfg.MaxRetries)
+			return
+		}
+		if o.Cfg.Debug {
+			o.logger.Printf("retrying %d datapoint(s) in %s", len(dps), backoff)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			numberOfFailedDataPoints.WithLabelValues(o.name, "canceled").Add(float64(len(dps)))
+			o.WriteError(ctx.Err())
+			o.logger.Printf("dropping %d datapoint(s): %v", len(dps), ctx.Err())
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// export sends the export request req of n datapoints.
+// The datapoints partially rejected by the receiver are logged, not retried.
+func (o *otlpOutput) export(ctx context.Context, req *rawMessage, n int) error {
+	ctx, cancel := context.WithTimeout(ctx, o.Cfg.Timeout)
+	defer cancel()
+	if len(o.Cfg.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Cfg.Headers))
+	}
+	numberOfExportRequests.WithLabelValues(o.name).Inc()
+	start := time.Now()
+	rsp := new(rawMessage)
+	err := o.conn.Invoke(ctx, exportMethod, req, rsp)
+	if err != nil {
+		return err
+	}
+	if o.Cfg.Debug {
+		o.logger.Printf("export request of %d datapoint(s) done in %s", n, time.Since(start))
+	}
+	rejected, msg, err := partialSuccess(rsp.b)
+	if err != nil {
+		o.logger.Printf("failed to decode export response: %v", err)
+	}
+	if rejected > 0 {
+		numberOfFailedDataPoints.WithLabelValues(o.name, "rejected").Add(float64(rejected))
+		o.WriteError(fmt.Errorf("%d datapoint(s) rejected: %s", rejected, msg))
+		o.logger.Printf("%d datapoint(s) rejected: %s", rejected, msg)
+	}
+	numberOfExportedDataPoints.WithLabelValues(o.name).Add(float64(int64(n) - rejected))
+	return nil
+}
+
+// retryable reports whether the export error err is temporary,
+// see https://opentelemetry.io/docs/specs/otlp/#failures
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.Aborted,
+		codes.OutOfRange,
+		codes.Unavailable,
+		codes.DataLoss:
+		return true
+	}
+	return false
+}
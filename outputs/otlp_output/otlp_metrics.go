@@ -0,0 +1,64 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp_output
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var numberOfExportedDataPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "otlp_output",
+	Name:      "number_datapoints_exported_total",
+	Help:      "Number of datapoints exported by otlp output",
+}, []string{"name"})
+
+var numberOfFailedDataPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "otlp_output",
+	Name:      "number_datapoints_failed_total",
+	Help:      "Number of datapoints not exported by otlp output",
+}, []string{"name", "reason"})
+
+var numberOfSkippedUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "otlp_output",
+	Name:      "number_updates_skipped_total",
+	Help:      "Number of non numeric values skipped by otlp output",
+}, []string{"name"})
+
+var numberOfExportRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "otlp_output",
+	Name:      "number_export_requests_total",
+	Help:      "Number of export requests sent by otlp output",
+}, []string{"name"})
+
+func initMetrics(name string) {
+	numberOfExportedDataPoints.WithLabelValues(name).Add(0)
+	numberOfFailedDataPoints.WithLabelValues(name, "").Add(0)
+	numberOfSkippedUpdates.WithLabelValues(name).Add(0)
+	numberOfExportRequests.WithLabelValues(name).Add(0)
+}
+
+func registerMetrics(reg *prometheus.Registry, name string) error {
+	initMetrics(name)
+	var err error
+	if err = reg.Register(numberOfExportedDataPoints); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfFailedDataPoints); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfSkippedUpdates); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfExportRequests); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp_output
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	outputType          = "otlp"
+	loggingPrefix       = "[otlp_output:%s] "
+	defaultServiceName  = "gnmic"
+	defaultBatchSize    = 1000
+	defaultBufferSize   = 10000
+	defaultFlushPeriod  = 10 * time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 5
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+	scopeName           = "gnmic"
+
+	serviceNameAttribute       = "service.name"
+	serviceInstanceIDAttribute = "service.instance.id"
+)
+
+func init() {
+	outputs.Register(outputType, func() outputs.Output {
+		return &otlpOutput{
+			Cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			done:   make(chan struct{}),
+		}
+	})
+}
+
+type otlpOutput struct {
+	outputs.WriteErrorReporter
+
+	Cfg *Config
+
+	name     string
+	logger   *log.Logger
+	evps     []formatters.EventProcessor
+	conn     *grpc.ClientConn
+	resource []keyValue
+	buffer   chan *dataPoint
+	// datapoints read from the buffer and not exported yet,
+	// only accessed by the worker, then by Close once the worker is done.
+	batch []*dataPoint
+
+	cancelFn  context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type Config struct {
+	// OTLP gRPC receiver address, e.g: otel-collector:4317
+	Endpoint string `mapstructure:"endpoint,omitempty" json:"endpoint,omitempty"`
+	// plaintext connection to the receiver
+	Insecure bool       `mapstructure:"insecure,omitempty" json:"insecure,omitempty"`
+	TLS      *tlsConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	// gRPC metadata sent with each export request, e.g: an authorization header
+	Headers map[string]string `mapstructure:"headers,omitempty" json:"-"`
+	// resource attributes of the exported metrics, service.name defaults to gnmic
+	ResourceAttributes map[string]string `mapstructure:"resource-attributes,omitempty" json:"resource-attributes,omitempty"`
+	// set as the service.instance.id resource attribute, defaults to the gnmic instance name
+	CollectorID string `mapstructure:"collector-id,omitempty" json:"collector-id,omitempty"`
+	// maximum number of datapoints per export request
+	BatchSize int `mapstructure:"batch-size,omitempty" json:"batch-size,omitempty"`
+	// maximum time a datapoint waits for its batch to be exported
+	FlushInterval   time.Duration `mapstructure:"flush-interval,omitempty" json:"flush-interval,omitempty"`
+	BufferSize      int           `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	Timeout         time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	MaxRetries      int           `mapstructure:"max-retries,omitempty" json:"max-retries,omitempty"`
+	RetryBackoff    time.Duration `mapstructure:"retry-backoff,omitempty" json:"retry-backoff,omitempty"`
+	EnableMetrics   bool          `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EventProcessors []string      `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+type tlsConfig struct {
+	CAFile     string `mapstructure:"ca-file,omitempty" json:"ca-file,omitempty"`
+	CertFile   string `mapstructure:"cert-file,omitempty" json:"cert-file,omitempty"`
+	KeyFile    string `mapstructure:"key-file,omitempty" json:"key-file,omitempty"`
+	SkipVerify bool   `mapstructure:"skip-verify,omitempty" json:"skip-verify,omitempty"`
+}
+
+func (o *otlpOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && o.logger != nil {
+		o.logger.SetOutput(logger.Writer())
+		o.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (o *otlpOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) {
+	for _, epName := range o.Cfg.EventProcessors {
+		if epCfg, ok := ps[epName]; ok {
+			epType := ""
+			for k := range epCfg {
+				epType = k
+				break
+			}
+			if in, ok := formatters.EventProcessors[epType]; ok {
+				ep := in()
+				err := ep.Init(epCfg[epType],
+					formatters.WithLogger(logger),
+					formatters.WithTargets(tcs),
+					formatters.WithActions(acts),
+				)
+				if err != nil {
+					o.logger.Printf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
+					continue
+				}
+				o.evps = append(o.evps, ep)
+				o.logger.Printf("added event processor '%s' of type=%s to otlp output", epName, epType)
+				continue
+			}
+			o.logger.Printf("%q event processor has an unknown type=%q", epName, epType)
+			continue
+		}
+		o.logger.Printf("%q event processor not found!", epName)
+	}
+}
+
+func (o *otlpOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, o.Cfg)
+	if err != nil {
+		return err
+	}
+	o.name = name
+	o.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+
+	for _, opt := range opts {
+		opt(o)
+	}
+	err = o.setDefaults()
+	if err != nil {
+		return err
+	}
+	o.resource = resourceAttributes(o.Cfg)
+	o.conn, err = o.dial(ctx)
+	if err != nil {
+		return err
+	}
+	o.buffer = make(chan *dataPoint, o.Cfg.BufferSize)
+
+	ctx, o.cancelFn = context.WithCancel(ctx)
+	go o.worker(ctx)
+	o.logger.Printf("initialized otlp output: %s", o.String())
+	return nil
+}
+
+func (o *otlpOutput) setDefaults() error {
+	if o.Cfg.Endpoint == "" {
+		return errors.New("missing endpoint field")
+	}
+	if o.Cfg.Insecure && o.Cfg.TLS != nil {
+		return errors.New("fields insecure and tls are mutually exclusive")
+	}
+	if o.Cfg.BatchSize <= 0 {
+		o.Cfg.BatchSize = defaultBatchSize
+	}
+	if o.Cfg.FlushInterval <= 0 {
+		o.Cfg.FlushInterval = defaultFlushPeriod
+	}
+	if o.Cfg.BufferSize <= 0 {
+		o.Cfg.BufferSize = defaultBufferSize
+	}
+	if o.Cfg.Timeout <= 0 {
+		o.Cfg.Timeout = defaultTimeout
+	}
+	if o.Cfg.MaxRetries < 0 {
+		return errors.New("max-retries cannot be negative")
+	}
+	if o.Cfg.MaxRetries == 0 {
+		o.Cfg.MaxRetries = defaultMaxRetries
+	}
+	if o.Cfg.RetryBackoff <= 0 {
+		o.Cfg.RetryBackoff = defaultRetryBackoff
+	}
+	return nil
+}
+
+// resourceAttributes returns the configured resource attributes sorted by key,
+// with the service.name and service.instance.id defaults.
+func resourceAttributes(cfg *Config) []keyValue {
+	attrs := make(map[string]string, len(cfg.ResourceAttributes)+2)
+	attrs[serviceNameAttribute] = defaultServiceName
+	if cfg.CollectorID != "" {
+		attrs[serviceInstanceIDAttribute] = cfg.CollectorID
+	}
+	for k, v := range cfg.ResourceAttributes {
+		attrs[k] = v
+	}
+	return sortedKeyValues(attrs)
+}
+
+// dial returns the connection to the receiver, established in the background.
+func (o *otlpOutput) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	}
+	if o.Cfg.Insecure {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		tlsCfg := new(tls.Config)
+		if o.Cfg.TLS != nil {
+			cfg, err := utils.NewTLSConfig(
+				o.Cfg.TLS.CAFile,
+				o.Cfg.TLS.CertFile,
+				o.Cfg.TLS.KeyFile,
+				o.Cfg.TLS.SkipVerify,
+				false)
+			if err != nil {
+				return nil, err
+			}
+			if cfg != nil {
+				tlsCfg = cfg
+			}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	}
+	conn, err := grpc.DialContext(ctx, o.Cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to %q: %v", o.Cfg.Endpoint, err)
+	}
+	return conn, nil
+}
+
+func (o *otlpOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	switch rsp := m.(type) {
+	case *gnmi.SubscribeResponse:
+		name := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			name = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(name, rsp, meta, o.evps...)
+		if err != nil {
+			o.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			o.queue(ev)
+		}
+	}
+}
+
+func (o *otlpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range o.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		o.queue(pev)
+	}
+}
+
+// queue adds the datapoints of event ev to the buffer,
+// they are dropped if the buffer is full.
+func (o *otlpOutput) queue(ev *formatters.EventMsg) {
+	for _, dp := range o.dataPoints(ev) {
+		select {
+		case o.buffer <- dp:
+		default:
+			numberOfFailedDataPoints.WithLabelValues(o.name, "buffer_full").Inc()
+			o.WriteError(outputs.ErrBufferFull)
+			if o.Cfg.Debug {
+				o.logger.Printf("buffer full, dropping datapoint %q", dp.name)
+			}
+		}
+	}
+}
+
+// dataPoints returns a gauge datapoint per numeric value of event ev,
+// with the event tags as attributes. The other values are skipped.
+func (o *otlpOutput) dataPoints(ev *formatters.EventMsg) []*dataPoint {
+	if len(ev.Values) == 0 {
+		return nil
+	}
+	ts := ev.Timestamp
+	if ts <= 0 {
+		ts = time.Now().UnixNano()
+	}
+	attrs := sortedKeyValues(ev.Tags)
+	dps := make([]*dataPoint, 0, len(ev.Values))
+	for k, v := range ev.Values {
+		dp := &dataPoint{
+			name:       k,
+			attributes: attrs,
+			timeNano:   uint64(ts),
+		}
+		if !setValue(dp, v) {
+			numberOfSkippedUpdates.WithLabelValues(o.name).Inc()
+			if o.Cfg.Debug {
+				o.logger.Printf("skipping non numeric value %q: %v", k, v)
+			}
+			continue
+		}
+		dps = append(dps, dp)
+	}
+	sort.Slice(dps, func(i, j int) bool {
+		return dps[i].name < dps[j].name
+	})
+	return dps
+}
+
+// setValue sets v as the value of datapoint dp
+// and reports whether v is numeric.
+func setValue(dp *dataPoint, v interface{}) bool {
+	switch v := v.(type) {
+	case int:
+		dp.isInt, dp.intValue = true, int64(v)
+	case int8:
+		dp.isInt, dp.intValue = true, int64(v)
+	case int16:
+		dp.isInt, dp.intValue = true, int64(v)
+	case int32:
+		dp.isInt, dp.intValue = true, int64(v)
+	case int64:
+		dp.isInt, dp.intValue = true, v
+	case uint:
+		return setUint(dp, uint64(v))
+	case uint8:
+		dp.isInt, dp.intValue = true, int64(v)
+	case uint16:
+		dp.isInt, dp.intValue = true, int64(v)
+	case uint32:
+		dp.isInt, dp.intValue = true, int64(v)
+	case uint64:
+		return setUint(dp, v)
+	case float32:
+		dp.doubleValue = float64(v)
+	case float64:
+		dp.doubleValue = v
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			dp.isInt, dp.intValue = true, i
+			return true
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return false
+		}
+		dp.doubleValue = f
+		//lint:ignore SA1019 still need DecimalVal for backward compatibility
+	case *gnmi.Decimal64:
+		dp.doubleValue = float64(v.Digits) / math.Pow10(int(v.Precision))
+	default:
+		return false
+	}
+	return true
+}
+
+// setUint sets v as an int value if it fits, as a double otherwise.
+func setUint(dp *dataPoint, v uint64) bool {
+	if v > math.MaxInt64 {
+		dp.doubleValue = float64(v)
+		return true
+	}
+	dp.isInt, dp.intValue = true, int64(v)
+	return true
+}
+
+func sortedKeyValues(m map[string]string) []keyValue {
+	kvs := make([]keyValue, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, keyValue{key: k, value: v})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].key < kvs[j].key
+	})
+	return kvs
+}
+
+// Close stops the worker and exports the buffered datapoints,
+// it returns once they are exported or the output timeout is reached.
+func (o *otlpOutput) Close() error {
+	var err error
+	o.closeOnce.Do(func() {
+		if o.cancelFn == nil {
+			return
+		}
+		o.cancelFn()
+		<-o.done
+		o.drain(o.batch)
+		err = o.conn.Close()
+	})
+	return err
+}
+
+func (o *otlpOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !o.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, o.name); err != nil {
+		o.logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (o *otlpOutput) String() string {
+	b, err := json.Marshal(o.Cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// SetName sets the gnmic instance name as the default collector-id.
+func (o *otlpOutput) SetName(name string) {
+	if o.Cfg.CollectorID == "" {
+		o.Cfg.CollectorID = name
+	}
+}
+
+func (o *otlpOutput) SetClusterName(name string)                      {}
+func (o *otlpOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
@@ -0,0 +1,277 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp_output
+
+import (
+	"context"
+	"io"
+	"log"
+	"math"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/formatters"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// receiver is an in-process OTLP metrics receiver,
+// failing the first export request with status Unavailable.
+type receiver struct {
+	m        *sync.Mutex
+	requests [][]byte
+	headers  []metadata.MD
+}
+
+func (r *receiver) handle(srv interface{}, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	if method != exportMethod {
+		return status.Errorf(codes.Unimplemented, "unknown method %q", method)
+	}
+	req := new(rawMessage)
+	err := stream.RecvMsg(req)
+	if err != nil {
+		return err
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	r.m.Lock()
+	r.requests = append(r.requests, req.b)
+	r.headers = append(r.headers, md)
+	n := len(r.requests)
+	r.m.Unlock()
+	if n == 1 {
+		return status.Error(codes.Unavailable, "not ready")
+	}
+	return stream.SendMsg(&rawMessage{})
+}
+
+func TestExport(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &receiver{m: new(sync.Mutex)}
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(r.handle),
+	)
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	o := &otlpOutput{
+		Cfg:    &Config{},
+		logger: log.New(io.Discard, "", 0),
+		done:   make(chan struct{}),
+	}
+	err = o.Init(context.Background(), "otlp1", map[string]interface{}{
+		"endpoint":            l.Addr().String(),
+		"insecure":            true,
+		"headers":             map[string]string{"authorization": "Bearer secret"},
+		"resource-attributes": map[string]string{"deployment.environment": "lab"},
+		"collector-id":        "gnmic1",
+		"flush-interval":      time.Hour,
+		"retry-backoff":       time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2023, 3, 16, 20, 53, 20, 0, time.UTC).UnixNano()
+	o.WriteEvent(context.Background(), &formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: ts,
+		Tags:      map[string]string{"source": "router1:57400", "interface_name": "ethernet-1/1"},
+		Values: map[string]interface{}{
+			"/interface/statistics/in-octets": "100",
+			"/interface/statistics/in-rate":   1.5,
+			"/interface/oper-state":           "up",
+		},
+	})
+	o.WriteEvent(context.Background(), &formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: ts,
+		Tags:      map[string]string{"source": "router1:57400", "interface_name": "ethernet-1/2"},
+		Values:    map[string]interface{}{"/interface/statistics/in-octets": uint64(200)},
+	})
+	// the buffered datapoints are exported on close
+	o.Close()
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	if len(r.requests) != 2 {
+		t.Fatalf("expected the export request to be retried once, got %d request(s)", len(r.requests))
+	}
+	if v := r.headers[1].Get("authorization"); len(v) != 1 || v[0] != "Bearer secret" {
+		t.Errorf("unexpected authorization header: %v", v)
+	}
+	req := decode(t, r.requests[1])
+	if len(req[1]) != 1 {
+		t.Fatalf("expected a single resource metrics, got %d", len(req[1]))
+	}
+	rm := decode(t, req[1][0].([]byte))
+	resource := attributes(t, decode(t, rm[1][0].([]byte))[1])
+	wantResource := map[string]string{
+		"service.name":           "gnmic",
+		"service.instance.id":    "gnmic1",
+		"deployment.environment": "lab",
+	}
+	if !reflect.DeepEqual(resource, wantResource) {
+		t.Errorf("unexpected resource attributes: %v", resource)
+	}
+	sm := decode(t, rm[2][0].([]byte))
+	if scope := string(decode(t, sm[1][0].([]byte))[1][0].([]byte)); scope != "gnmic" {
+		t.Errorf("unexpected scope name %q", scope)
+	}
+	type point struct {
+		value interface{}
+		attrs map[string]string
+	}
+	got := make(map[string][]point)
+	for _, mb := range sm[2] {
+		m := decode(t, mb.([]byte))
+		name := string(m[1][0].([]byte))
+		if len(m[5]) != 1 {
+			t.Fatalf("metric %q is not a gauge", name)
+		}
+		for _, dpb := range decode(t, m[5][0].([]byte))[1] {
+			dp := decode(t, dpb.([]byte))
+			if tn := dp[3][0].(uint64); tn != uint64(ts) {
+				t.Errorf("metric %q: unexpected datapoint time %d", name, tn)
+			}
+			p := point{attrs: attributes(t, dp[7])}
+			switch {
+			case len(dp[6]) == 1:
+				p.value = int64(dp[6][0].(uint64))
+			case len(dp[4]) == 1:
+				p.value = math.Float64frombits(dp[4][0].(uint64))
+			}
+			got[name] = append(got[name], p)
+		}
+	}
+	want := map[string][]point{
+		"/interface/statistics/in-octets": {
+			{value: int64(100), attrs: map[string]string{"source": "router1:57400", "interface_name": "ethernet-1/1"}},
+			{value: int64(200), attrs: map[string]string{"source": "router1:57400", "interface_name": "ethernet-1/2"}},
+		},
+		"/interface/statistics/in-rate": {
+			{value: 1.5, attrs: map[string]string{"source": "router1:57400", "interface_name": "ethernet-1/1"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected datapoints:\nexpected: %v\n     got: %v", want, got)
+	}
+}
+
+func TestDataPoints(t *testing.T) {
+	o := &otlpOutput{Cfg: &Config{}, logger: log.New(io.Discard, "", 0)}
+	dps := o.dataPoints(&formatters.EventMsg{
+		Timestamp: 1,
+		Values: map[string]interface{}{
+			"int":     int32(-3),
+			"uint":    uint64(math.MaxUint64),
+			"float":   float32(0.5),
+			"string":  "2.5",
+			"enabled": true,
+			"state":   "up",
+		},
+	})
+	got := make(map[string]interface{}, len(dps))
+	for _, dp := range dps {
+		if dp.isInt {
+			got[dp.name] = dp.intValue
+		} else {
+			got[dp.name] = dp.doubleValue
+		}
+	}
+	want := map[string]interface{}{
+		"int":    int64(-3),
+		"uint":   float64(math.MaxUint64),
+		"float":  0.5,
+		"string": 2.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected datapoints: %v", got)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	for name, item := range map[string]struct {
+		cfg *Config
+		err bool
+	}{
+		"defaults":             {cfg: &Config{Endpoint: "collector:4317"}},
+		"missing_endpoint":     {cfg: &Config{}, err: true},
+		"insecure_and_tls":     {cfg: &Config{Endpoint: "collector:4317", Insecure: true, TLS: &tlsConfig{}}, err: true},
+		"negative_max_retries": {cfg: &Config{Endpoint: "collector:4317", MaxRetries: -1}, err: true},
+	} {
+		o := &otlpOutput{Cfg: item.cfg}
+		err := o.setDefaults()
+		if item.err {
+			if err == nil {
+				t.Errorf("failed at item %q: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed at item %q: %v", name, err)
+			continue
+		}
+		if o.Cfg.BatchSize != defaultBatchSize || o.Cfg.MaxRetries != defaultMaxRetries {
+			t.Errorf("failed at item %q: unexpected defaults: %+v", name, o.Cfg)
+		}
+	}
+}
+
+// decode returns the fields of the protobuf message b by number,
+// the length delimited values as []byte, the others as uint64.
+func decode(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	t.Helper()
+	fields := make(map[protowire.Number][]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("failed to decode message: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatalf("failed to decode message: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		fields[num] = append(fields[num], v)
+	}
+	return fields
+}
+
+// attributes returns the encoded KeyValues kvs with string values.
+func attributes(t *testing.T, kvs []interface{}) map[string]string {
+	t.Helper()
+	attrs := make(map[string]string, len(kvs))
+	for _, kvb := range kvs {
+		kv := decode(t, kvb.([]byte))
+		value := decode(t, kv[2][0].([]byte))
+		attrs[string(kv[1][0].([]byte))] = string(value[1][0].([]byte))
+	}
+	return attrs
+}
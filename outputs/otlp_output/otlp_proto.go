@@ -0,0 +1,214 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp_output
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// the OTLP metrics export RPC, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/collector/metrics/v1/metrics_service.proto
+const exportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// dataPoint is a gauge datapoint of the metric name,
+// the value is either intValue or doubleValue.
+type dataPoint struct {
+	name        string
+	attributes  []keyValue
+	timeNano    uint64
+	isInt       bool
+	intValue    int64
+	doubleValue float64
+}
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+// rawMessage is a protobuf encoded message,
+// sent and received as is by rawCodec.
+type rawMessage struct {
+	b []byte
+}
+
+// rawCodec is a gRPC codec sending the already encoded OTLP messages,
+// the OTLP protobuf definitions are encoded with the protowire package.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return m.b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	m.b = append(m.b[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// exportRequest returns the ExportMetricsServiceRequest of the gauge datapoints dps,
+// grouped by metric name in a single ResourceMetrics with the resource attributes.
+func exportRequest(resource []keyValue, scope string, dps []*dataPoint) []byte {
+	// metrics in the order of their first datapoint
+	names := make([]string, 0)
+	byName := make(map[string][]*dataPoint)
+	for _, dp := range dps {
+		if _, ok := byName[dp.name]; !ok {
+			names = append(names, dp.name)
+		}
+		byName[dp.name] = append(byName[dp.name], dp)
+	}
+	// ScopeMetrics
+	var sm []byte
+	sm = protowire.AppendTag(sm, 1, protowire.BytesType)
+	sm = protowire.AppendBytes(sm, appendString(nil, 1, scope))
+	for _, name := range names {
+		sm = protowire.AppendTag(sm, 2, protowire.BytesType)
+		sm = protowire.AppendBytes(sm, gaugeMetric(name, byName[name]))
+	}
+	// Resource
+	var res []byte
+	for _, kv := range resource {
+		res = protowire.AppendTag(res, 1, protowire.BytesType)
+		res = protowire.AppendBytes(res, kv.marshal())
+	}
+	// ResourceMetrics
+	var rm []byte
+	rm = protowire.AppendTag(rm, 1, protowire.BytesType)
+	rm = protowire.AppendBytes(rm, res)
+	rm = protowire.AppendTag(rm, 2, protowire.BytesType)
+	rm = protowire.AppendBytes(rm, sm)
+	// ExportMetricsServiceRequest
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	return protowire.AppendBytes(req, rm)
+}
+
+// gaugeMetric returns the Metric name with a Gauge of datapoints dps.
+func gaugeMetric(name string, dps []*dataPoint) []byte {
+	var g []byte
+	for _, dp := range dps {
+		g = protowire.AppendTag(g, 1, protowire.BytesType)
+		g = protowire.AppendBytes(g, dp.marshal())
+	}
+	m := appendString(nil, 1, name)
+	m = protowire.AppendTag(m, 5, protowire.BytesType)
+	return protowire.AppendBytes(m, g)
+}
+
+// marshal returns the NumberDataPoint dp.
+func (dp *dataPoint) marshal() []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, dp.timeNano)
+	if dp.isInt {
+		b = protowire.AppendTag(b, 6, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(dp.intValue))
+	} else {
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(dp.doubleValue))
+	}
+	for _, kv := range dp.attributes {
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, kv.marshal())
+	}
+	return b
+}
+
+// marshal returns the KeyValue kv with a string AnyValue.
+func (kv keyValue) marshal() []byte {
+	b := appendString(nil, 1, kv.key)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, appendString(nil, 1, kv.value))
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// partialSuccess returns the number of datapoints rejected by the receiver
+// and the error message of the ExportMetricsServiceResponse b.
+func partialSuccess(b []byte) (int64, string, error) {
+	ps, err := bytesField(b, 1)
+	if err != nil || ps == nil {
+		return 0, "", err
+	}
+	var rejected int64
+	var msg string
+	for len(ps) > 0 {
+		num, typ, n := protowire.ConsumeTag(ps)
+		if n < 0 {
+			return 0, "", protowire.ParseError(n)
+		}
+		ps = ps[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(ps)
+			if n < 0 {
+				return 0, "", protowire.ParseError(n)
+			}
+			rejected = int64(v)
+			ps = ps[n:]
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(ps)
+			if n < 0 {
+				return 0, "", protowire.ParseError(n)
+			}
+			msg = v
+			ps = ps[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, ps)
+			if n < 0 {
+				return 0, "", protowire.ParseError(n)
+			}
+			ps = ps[n:]
+		}
+	}
+	return rejected, msg, nil
+}
+
+// bytesField returns the last value of the length delimited field num of message b.
+func bytesField(b []byte, num protowire.Number) ([]byte, error) {
+	var v []byte
+	for len(b) > 0 {
+		fnum, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if fnum == num && typ == protowire.BytesType {
+			fv, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			v = fv
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(fnum, typ, b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return v, nil
+}
@@ -59,6 +59,33 @@ var OutputTypes = map[string]struct{}{
 	"gnmi":             {},
 	"jetstream":        {},
 	"snmp":             {},
+	"syslog":           {},
+	"exec":             {},
+	"elasticsearch":    {},
+	"otlp":             {},
+	"websocket":        {},
+}
+
+// OutputFormats are the formats supported by the output types with a `format` field,
+// the other output types ignore the format.
+var OutputFormats = map[string][]string{
+	"file":      {"json", "protojson", "prototext", "event", "flat"},
+	"kafka":     {"json", "protojson", "prototext", "event", "proto"},
+	"nats":      {"json", "protojson", "event", "proto"},
+	"stan":      {"json", "protojson", "event", "proto"},
+	"jetstream": {"json", "protojson", "prototext", "event", "proto", "flat"},
+	"tcp":       {"json", "protojson", "prototext", "event", "proto", "flat"},
+	"udp":       {"json", "protojson", "prototext", "event", "proto", "flat"},
+}
+
+// FormatSupported returns true if output type typ has a `format` field accepting format.
+func FormatSupported(typ, format string) bool {
+	for _, f := range OutputFormats[typ] {
+		if f == format {
+			return true
+		}
+	}
+	return false
 }
 
 func Register(name string, initFn Initializer) {
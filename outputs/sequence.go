@@ -0,0 +1,65 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"strconv"
+	"sync"
+)
+
+// meta keys set on the received notifications with --sequence-numbers
+const (
+	MetaSeq   = "seq"
+	MetaRunID = "run-id"
+)
+
+// SequenceReporter is implemented by the outputs reporting
+// the highest sequence number they successfully wrote, per source.
+type SequenceReporter interface {
+	WrittenSequences() map[string]uint64
+}
+
+// SequenceTracker records the highest sequence number written per source.
+// Its zero value is ready to use, it is safe for concurrent use.
+type SequenceTracker struct {
+	m    sync.Mutex
+	seqs map[string]uint64
+}
+
+// Record records the sequence number of a written message from its meta,
+// the messages without a sequence number are ignored.
+func (s *SequenceTracker) Record(meta Meta) {
+	v, ok := meta[MetaSeq]
+	if !ok {
+		return
+	}
+	seq, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.seqs == nil {
+		s.seqs = make(map[string]uint64)
+	}
+	if seq > s.seqs[meta["source"]] {
+		s.seqs[meta["source"]] = seq
+	}
+}
+
+// WrittenSequences returns a copy of the highest sequence numbers written per source.
+func (s *SequenceTracker) WrittenSequences() map[string]uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+	seqs := make(map[string]uint64, len(s.seqs))
+	for k, v := range s.seqs {
+		seqs[k] = v
+	}
+	return seqs
+}
@@ -53,6 +53,8 @@ func init() {
 }
 
 type snmpOutput struct {
+	outputs.WriteErrorReporter
+
 	name       string
 	cfg        *Config
 	logger     *log.Logger
@@ -407,6 +409,7 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 	} else {
 		err = errors.New("missing 'source' or 'target' field")
 		snmpNumberOfFailedTrapGeneration.WithLabelValues(s.name, fmt.Sprintf("%d", idx), err.Error()).Inc()
+		s.WriteError(err)
 		return err
 	}
 	//
@@ -423,6 +426,7 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 	if err != nil {
 		err = fmt.Errorf("failed to build PDU from trigger: %v", err)
 		snmpNumberOfFailedTrapGeneration.WithLabelValues(s.name, fmt.Sprintf("%d", idx), err.Error()).Inc()
+		s.WriteError(err)
 		return err
 	}
 
@@ -434,6 +438,7 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 			err = fmt.Errorf("failed to build PDU from binding index %d: %v", i, err)
 			s.logger.Printf("%v", err)
 			snmpNumberOfFailedTrapGeneration.WithLabelValues(s.name, fmt.Sprintf("%d", idx), err.Error()).Inc()
+			s.WriteError(err)
 			continue
 		}
 
@@ -447,6 +452,7 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 	})
 	if err != nil {
 		snmpNumberOfTrapSendFailureTraps.WithLabelValues(s.name, fmt.Sprintf("%d", idx), err.Error()).Inc()
+		s.WriteError(err)
 		return fmt.Errorf("failed to send trap: %v", err)
 	}
 	snmpTrapGenerationDuration.WithLabelValues(s.name, fmt.Sprintf("%d", idx)).Set(float64(time.Since(start).Nanoseconds()))
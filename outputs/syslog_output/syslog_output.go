@@ -0,0 +1,368 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/syslog"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultRetryTimer     = 2 * time.Second
+	defaultBufferSize     = 1000
+	defaultTag            = "gnmic"
+	defaultFacility       = "local0"
+	defaultSeverity       = "info"
+	defaultMaxMessageSize = 1024
+	truncatedMarker       = "..."
+	loggingPrefix         = "[syslog_output:%s] "
+)
+
+var facilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+var severities = map[string]syslog.Priority{
+	"emerg":   syslog.LOG_EMERG,
+	"alert":   syslog.LOG_ALERT,
+	"crit":    syslog.LOG_CRIT,
+	"err":     syslog.LOG_ERR,
+	"warning": syslog.LOG_WARNING,
+	"notice":  syslog.LOG_NOTICE,
+	"info":    syslog.LOG_INFO,
+	"debug":   syslog.LOG_DEBUG,
+}
+
+func init() {
+	outputs.Register("syslog", func() outputs.Output {
+		return &syslogOutput{
+			Cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+}
+
+type syslogOutput struct {
+	outputs.WriteErrorReporter
+
+	Cfg *Config
+
+	name     string
+	network  string
+	raddr    string
+	priority syslog.Priority
+	cancelFn context.CancelFunc
+	buffer   chan []byte
+	logger   *log.Logger
+	evps     []formatters.EventProcessor
+
+	msgTpl *template.Template
+}
+
+type Config struct {
+	// syslog server address, formatted as udp://host:port or tcp://host:port.
+	// if empty, the local syslog server is used.
+	Address         string        `mapstructure:"address,omitempty" json:"address,omitempty"`
+	Tag             string        `mapstructure:"tag,omitempty" json:"tag,omitempty"`
+	Facility        string        `mapstructure:"facility,omitempty" json:"facility,omitempty"`
+	Severity        string        `mapstructure:"severity,omitempty" json:"severity,omitempty"`
+	MaxMessageSize  int           `mapstructure:"max-message-size,omitempty" json:"max-message-size,omitempty"`
+	MsgTemplate     string        `mapstructure:"msg-template,omitempty" json:"msg-template,omitempty"`
+	BufferSize      uint          `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	RetryInterval   time.Duration `mapstructure:"retry-interval,omitempty" json:"retry-interval,omitempty"`
+	EnableMetrics   bool          `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EventProcessors []string      `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+func (s *syslogOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && s.logger != nil {
+		s.logger.SetOutput(logger.Writer())
+		s.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (s *syslogOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) {
+	for _, epName := range s.Cfg.EventProcessors {
+		if epCfg, ok := ps[epName]; ok {
+			epType := ""
+			for k := range epCfg {
+				epType = k
+				break
+			}
+			if in, ok := formatters.EventProcessors[epType]; ok {
+				ep := in()
+				err := ep.Init(epCfg[epType],
+					formatters.WithLogger(logger),
+					formatters.WithTargets(tcs),
+					formatters.WithActions(acts),
+				)
+				if err != nil {
+					s.logger.Printf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
+					continue
+				}
+				s.evps = append(s.evps, ep)
+				s.logger.Printf("added event processor '%s' of type=%s to syslog output", epName, epType)
+				continue
+			}
+			s.logger.Printf("%q event processor has an unknown type=%q", epName, epType)
+			continue
+		}
+		s.logger.Printf("%q event processor not found!", epName)
+	}
+}
+
+func (s *syslogOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, s.Cfg)
+	if err != nil {
+		return err
+	}
+	s.name = name
+	s.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	err = s.setDefaults()
+	if err != nil {
+		return err
+	}
+	if s.Cfg.MsgTemplate != "" {
+		s.msgTpl, err = utils.CreateTemplate(fmt.Sprintf("%s-msg-template", name), s.Cfg.MsgTemplate)
+		if err != nil {
+			return err
+		}
+		s.msgTpl = s.msgTpl.Funcs(outputs.TemplateFuncs)
+	}
+	s.buffer = make(chan []byte, s.Cfg.BufferSize)
+
+	go func() {
+		<-ctx.Done()
+		s.Close()
+	}()
+	ctx, s.cancelFn = context.WithCancel(ctx)
+	go s.start(ctx)
+	s.logger.Printf("initialized syslog output: %s", s.String())
+	return nil
+}
+
+func (s *syslogOutput) setDefaults() error {
+	if s.Cfg.Address != "" {
+		u, err := url.Parse(s.Cfg.Address)
+		if err != nil {
+			return fmt.Errorf("failed to parse address: %v", err)
+		}
+		switch u.Scheme {
+		case "udp", "tcp":
+		default:
+			return fmt.Errorf("unsupported address scheme %q, must be one of udp or tcp", u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("missing host in address %q", s.Cfg.Address)
+		}
+		s.network = u.Scheme
+		s.raddr = u.Host
+	}
+	if s.Cfg.Tag == "" {
+		s.Cfg.Tag = defaultTag
+	}
+	if s.Cfg.Facility == "" {
+		s.Cfg.Facility = defaultFacility
+	}
+	if s.Cfg.Severity == "" {
+		s.Cfg.Severity = defaultSeverity
+	}
+	facility, ok := facilities[strings.ToLower(s.Cfg.Facility)]
+	if !ok {
+		return fmt.Errorf("unknown facility %q", s.Cfg.Facility)
+	}
+	severity, ok := severities[strings.ToLower(s.Cfg.Severity)]
+	if !ok {
+		return fmt.Errorf("unknown severity %q", s.Cfg.Severity)
+	}
+	s.priority = facility | severity
+	if s.Cfg.MaxMessageSize <= len(truncatedMarker) {
+		s.Cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	if s.Cfg.BufferSize == 0 {
+		s.Cfg.BufferSize = defaultBufferSize
+	}
+	if s.Cfg.RetryInterval == 0 {
+		s.Cfg.RetryInterval = defaultRetryTimer
+	}
+	return nil
+}
+
+func (s *syslogOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	switch rsp := m.(type) {
+	case *gnmi.SubscribeResponse:
+		name := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			name = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(name, rsp, meta, s.evps...)
+		if err != nil {
+			s.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			s.WriteEvent(ctx, ev)
+		}
+	}
+}
+
+func (s *syslogOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		s.logger.Printf("failed to marshal event: %v", err)
+		return
+	}
+	if s.msgTpl != nil {
+		b, err = outputs.ExecTemplate(b, s.msgTpl)
+		if err != nil {
+			if s.Cfg.Debug {
+				s.logger.Printf("failed to execute template: %v", err)
+			}
+			numberOfFailSendMsgs.WithLabelValues(s.name, "template_error").Inc()
+			s.WriteError(err)
+			return
+		}
+	}
+	if len(b) == 0 {
+		return
+	}
+	b = truncate(b, s.Cfg.MaxMessageSize)
+	select {
+	case s.buffer <- b:
+	default:
+		numberOfDroppedMsgs.WithLabelValues(s.name).Inc()
+		s.WriteError(outputs.ErrBufferFull)
+		s.logger.Printf("buffer full, dropping message")
+	}
+}
+
+func (s *syslogOutput) Close() error {
+	s.cancelFn()
+	return nil
+}
+
+func (s *syslogOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !s.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, s.name); err != nil {
+		s.logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (s *syslogOutput) String() string {
+	b, err := json.Marshal(s.Cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (s *syslogOutput) SetName(name string)                             {}
+func (s *syslogOutput) SetClusterName(name string)                      {}
+func (s *syslogOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func (s *syslogOutput) start(ctx context.Context) {
+	var w *syslog.Writer
+	var err error
+DIAL:
+	if ctx.Err() != nil {
+		return
+	}
+	// an empty network and raddr connect to the local syslog server
+	w, err = syslog.Dial(s.network, s.raddr, s.priority, s.Cfg.Tag)
+	if err != nil {
+		s.logger.Printf("failed to dial syslog server: %v", err)
+		time.Sleep(s.Cfg.RetryInterval)
+		goto DIAL
+	}
+	defer w.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-s.buffer:
+			_, err = w.Write(b)
+			if err != nil {
+				numberOfFailSendMsgs.WithLabelValues(s.name, "write_error").Inc()
+				s.WriteError(err)
+				s.logger.Printf("failed to write syslog message: %v", err)
+				continue
+			}
+			numberOfSentMsgs.WithLabelValues(s.name).Inc()
+		}
+	}
+}
+
+// truncate shortens b to max bytes, replacing its end with an ellipsis marker.
+// It does not split a multi-byte UTF-8 character.
+func truncate(b []byte, max int) []byte {
+	if len(b) <= max {
+		return b
+	}
+	n := max - len(truncatedMarker)
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	tb := make([]byte, 0, n+len(truncatedMarker))
+	tb = append(tb, b[:n]...)
+	return append(tb, truncatedMarker...)
+}
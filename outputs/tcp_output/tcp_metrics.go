@@ -0,0 +1,53 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp_output
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var numberOfSentMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "tcp_output",
+	Name:      "number_messages_sent_total",
+	Help:      "Number of messages sent by tcp output",
+}, []string{"name"})
+
+var numberOfDroppedMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "tcp_output",
+	Name:      "number_messages_dropped_total",
+	Help:      "Number of messages dropped by tcp output because its buffer is full",
+}, []string{"name"})
+
+var numberOfFailSendMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "tcp_output",
+	Name:      "number_messages_send_fail_total",
+	Help:      "Number of failed message sends by tcp output",
+}, []string{"name"})
+
+func initMetrics(name string) {
+	numberOfSentMsgs.WithLabelValues(name).Add(0)
+	numberOfDroppedMsgs.WithLabelValues(name).Add(0)
+	numberOfFailSendMsgs.WithLabelValues(name).Add(0)
+}
+
+func registerMetrics(reg *prometheus.Registry, name string) error {
+	initMetrics(name)
+	var err error
+	if err = reg.Register(numberOfSentMsgs); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfDroppedMsgs); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfFailSendMsgs); err != nil {
+		return err
+	}
+	return nil
+}
@@ -27,9 +27,11 @@ import (
 )
 
 const (
-	defaultRetryTimer = 2 * time.Second
-	defaultNumWorkers = 1
-	loggingPrefix     = "[tcp_output:%s] "
+	defaultRetryTimer    = 2 * time.Second
+	defaultMaxRetryTimer = 1 * time.Minute
+	defaultNumWorkers    = 1
+	defaultBufferSize    = 1000
+	loggingPrefix        = "[tcp_output:%s] "
 )
 
 func init() {
@@ -42,8 +44,11 @@ func init() {
 }
 
 type TCPOutput struct {
+	outputs.WriteErrorReporter
+
 	Cfg *Config
 
+	name     string
 	cancelFn context.CancelFunc
 	buffer   chan []byte
 	limiter  *time.Ticker
@@ -62,8 +67,11 @@ type Config struct {
 	AddTarget          string        `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
 	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool          `mapstructure:"num-as-string,omitempty"`
 	KeepAlive          time.Duration `mapstructure:"keep-alive,omitempty"`
 	RetryInterval      time.Duration `mapstructure:"retry-interval,omitempty"`
+	MaxRetryInterval   time.Duration `mapstructure:"max-retry-interval,omitempty"`
+	Delimiter          string        `mapstructure:"delimiter,omitempty"`
 	NumWorkers         int           `mapstructure:"num-workers,omitempty"`
 	EnableMetrics      bool          `mapstructure:"enable-metrics,omitempty"`
 	EventProcessors    []string      `mapstructure:"event-processors,omitempty"`
@@ -110,6 +118,7 @@ func (t *TCPOutput) Init(ctx context.Context, name string, cfg map[string]interf
 	if err != nil {
 		return err
 	}
+	t.name = name
 	t.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
 
 	for _, opt := range opts {
@@ -119,6 +128,9 @@ func (t *TCPOutput) Init(ctx context.Context, name string, cfg map[string]interf
 	if err != nil {
 		return fmt.Errorf("wrong address format: %v", err)
 	}
+	if t.Cfg.BufferSize == 0 {
+		t.Cfg.BufferSize = defaultBufferSize
+	}
 	t.buffer = make(chan []byte, t.Cfg.BufferSize)
 	if t.Cfg.Rate > 0 {
 		t.limiter = time.NewTicker(t.Cfg.Rate)
@@ -126,13 +138,20 @@ func (t *TCPOutput) Init(ctx context.Context, name string, cfg map[string]interf
 	if t.Cfg.RetryInterval == 0 {
 		t.Cfg.RetryInterval = defaultRetryTimer
 	}
+	if t.Cfg.MaxRetryInterval < t.Cfg.RetryInterval {
+		t.Cfg.MaxRetryInterval = defaultMaxRetryTimer
+		if t.Cfg.MaxRetryInterval < t.Cfg.RetryInterval {
+			t.Cfg.MaxRetryInterval = t.Cfg.RetryInterval
+		}
+	}
 	if t.Cfg.NumWorkers < 1 {
 		t.Cfg.NumWorkers = defaultNumWorkers
 	}
 
 	t.mo = &formatters.MarshalOptions{
-		Format:     t.Cfg.Format,
-		OverrideTS: t.Cfg.OverrideTimestamps,
+		Format:      t.Cfg.Format,
+		OverrideTS:  t.Cfg.OverrideTimestamps,
+		NumAsString: t.Cfg.NumAsString,
 	}
 
 	if t.Cfg.TargetTemplate == "" {
@@ -173,7 +192,18 @@ func (t *TCPOutput) Write(ctx context.Context, m proto.Message, meta outputs.Met
 			t.logger.Printf("failed marshaling proto msg: %v", err)
 			return
 		}
-		t.buffer <- b
+		if t.Cfg.Delimiter != "" {
+			b = append(b, t.Cfg.Delimiter...)
+		}
+		// do not block the caller if the buffer is full,
+		// e.g: while the connection is being re-established.
+		select {
+		case t.buffer <- b:
+		default:
+			numberOfDroppedMsgs.WithLabelValues(t.name).Inc()
+			t.WriteError(outputs.ErrBufferFull)
+			t.logger.Printf("buffer full, dropping message")
+		}
 	}
 }
 
@@ -186,7 +216,15 @@ func (t *TCPOutput) Close() error {
 	}
 	return nil
 }
-func (t *TCPOutput) RegisterMetrics(reg *prometheus.Registry) {}
+
+func (t *TCPOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !t.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, t.name); err != nil {
+		t.logger.Printf("failed to register metric: %v", err)
+	}
+}
 
 func (t *TCPOutput) String() string {
 	b, err := json.Marshal(t)
@@ -198,44 +236,86 @@ func (t *TCPOutput) String() string {
 
 func (t *TCPOutput) start(ctx context.Context, idx int) {
 	workerLogPrefix := fmt.Sprintf("worker-%d", idx)
-START:
+	defer t.Close()
+	retryInterval := t.Cfg.RetryInterval
+	for {
+		conn, err := t.dial()
+		if err != nil {
+			t.logger.Printf("%s failed to dial TCP: %v", workerLogPrefix, err)
+			if !t.backoff(ctx, &retryInterval) {
+				return
+			}
+			continue
+		}
+		retryInterval = t.Cfg.RetryInterval
+		err = t.send(ctx, conn)
+		conn.Close()
+		if err == nil {
+			return
+		}
+		t.logger.Printf("%s failed sending tcp bytes: %v", workerLogPrefix, err)
+		if !t.backoff(ctx, &retryInterval) {
+			return
+		}
+	}
+}
+
+func (t *TCPOutput) dial() (*net.TCPConn, error) {
 	tcpAddr, err := net.ResolveTCPAddr("tcp", t.Cfg.Address)
 	if err != nil {
-		t.logger.Printf("%s failed to resolve address: %v", workerLogPrefix, err)
-		time.Sleep(t.Cfg.RetryInterval)
-		goto START
+		return nil, err
 	}
 	conn, err := net.DialTCP("tcp", nil, tcpAddr)
 	if err != nil {
-		t.logger.Printf("%s failed to dial TCP: %v", workerLogPrefix, err)
-		time.Sleep(t.Cfg.RetryInterval)
-		goto START
+		return nil, err
 	}
-	defer conn.Close()
 	if t.Cfg.KeepAlive > 0 {
 		conn.SetKeepAlive(true)
 		conn.SetKeepAlivePeriod(t.Cfg.KeepAlive)
 	}
-	defer t.Close()
+	return conn, nil
+}
+
+// send writes the buffered messages to conn until the context is done
+// or a write fails.
+func (t *TCPOutput) send(ctx context.Context, conn *net.TCPConn) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case b := <-t.buffer:
 			if t.limiter != nil {
 				<-t.limiter.C
 			}
-			_, err = conn.Write(b)
+			_, err := conn.Write(b)
 			if err != nil {
-				t.logger.Printf("%s failed sending tcp bytes: %v", workerLogPrefix, err)
-				conn.Close()
-				time.Sleep(t.Cfg.RetryInterval)
-				goto START
+				numberOfFailSendMsgs.WithLabelValues(t.name).Inc()
+				t.WriteError(err)
+				return err
 			}
+			numberOfSentMsgs.WithLabelValues(t.name).Inc()
 		}
 	}
 }
 
+// backoff waits for the current retry interval then doubles it,
+// up to the max retry interval.
+// it returns false if the context is done while waiting.
+func (t *TCPOutput) backoff(ctx context.Context, interval *time.Duration) bool {
+	timer := time.NewTimer(*interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+	*interval *= 2
+	if *interval > t.Cfg.MaxRetryInterval {
+		*interval = t.Cfg.MaxRetryInterval
+	}
+	return true
+}
+
 func (t *TCPOutput) SetName(name string)                             {}
 func (t *TCPOutput) SetClusterName(name string)                      {}
 func (s *TCPOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
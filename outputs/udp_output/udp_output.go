@@ -28,6 +28,7 @@ import (
 
 const (
 	defaultRetryTimer = 2 * time.Second
+	defaultBufferSize = 1000
 	loggingPrefix     = "[udp_output:%s] "
 )
 
@@ -41,8 +42,11 @@ func init() {
 }
 
 type UDPSock struct {
+	outputs.WriteErrorReporter
+
 	Cfg *Config
 
+	name     string
 	conn     *net.UDPConn
 	cancelFn context.CancelFunc
 	buffer   chan []byte
@@ -62,6 +66,7 @@ type Config struct {
 	AddTarget          string        `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
 	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
+	NumAsString        bool          `mapstructure:"num-as-string,omitempty"`
 	RetryInterval      time.Duration `mapstructure:"retry-interval,omitempty"`
 	EnableMetrics      bool          `mapstructure:"enable-metrics,omitempty"`
 	EventProcessors    []string      `mapstructure:"event-processors,omitempty"`
@@ -110,6 +115,7 @@ func (u *UDPSock) Init(ctx context.Context, name string, cfg map[string]interfac
 	if err != nil {
 		return err
 	}
+	u.name = name
 	u.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
 
 	for _, opt := range opts {
@@ -123,6 +129,9 @@ func (u *UDPSock) Init(ctx context.Context, name string, cfg map[string]interfac
 		u.Cfg.RetryInterval = defaultRetryTimer
 	}
 
+	if u.Cfg.BufferSize == 0 {
+		u.Cfg.BufferSize = defaultBufferSize
+	}
 	u.buffer = make(chan []byte, u.Cfg.BufferSize)
 	if u.Cfg.Rate > 0 {
 		u.limiter = time.NewTicker(u.Cfg.Rate)
@@ -133,8 +142,9 @@ func (u *UDPSock) Init(ctx context.Context, name string, cfg map[string]interfac
 	}()
 	ctx, u.cancelFn = context.WithCancel(ctx)
 	u.mo = &formatters.MarshalOptions{
-		Format:     u.Cfg.Format,
-		OverrideTS: u.Cfg.OverrideTimestamps,
+		Format:      u.Cfg.Format,
+		OverrideTS:  u.Cfg.OverrideTimestamps,
+		NumAsString: u.Cfg.NumAsString,
 	}
 	if u.Cfg.TargetTemplate == "" {
 		u.targetTpl = outputs.DefaultTargetTemplate
@@ -167,7 +177,15 @@ func (u *UDPSock) Write(ctx context.Context, m proto.Message, meta outputs.Meta)
 			u.logger.Printf("failed marshaling proto msg: %v", err)
 			return
 		}
-		u.buffer <- b
+		// do not block the caller if the buffer is full,
+		// e.g: while the socket is being re-created.
+		select {
+		case u.buffer <- b:
+		default:
+			numberOfDroppedMsgs.WithLabelValues(u.name).Inc()
+			u.WriteError(outputs.ErrBufferFull)
+			u.logger.Printf("buffer full, dropping message")
+		}
 	}
 }
 
@@ -181,7 +199,14 @@ func (u *UDPSock) Close() error {
 	return nil
 }
 
-func (u *UDPSock) RegisterMetrics(reg *prometheus.Registry) {}
+func (u *UDPSock) RegisterMetrics(reg *prometheus.Registry) {
+	if !u.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, u.name); err != nil {
+		u.logger.Printf("failed to register metric: %v", err)
+	}
+}
 
 func (u *UDPSock) String() string {
 	b, err := json.Marshal(u)
@@ -222,10 +247,13 @@ DIAL:
 			}
 			_, err = u.conn.Write(b)
 			if err != nil {
+				numberOfFailSendMsgs.WithLabelValues(u.name).Inc()
+				u.WriteError(err)
 				u.logger.Printf("failed sending udp bytes: %v", err)
 				time.Sleep(u.Cfg.RetryInterval)
 				goto DIAL
 			}
+			numberOfSentMsgs.WithLabelValues(u.name).Inc()
 		}
 	}
 }
@@ -0,0 +1,275 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket_output
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RFC 6455 constants
+const (
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+
+	closeNormal          = 1000
+	closeGoingAway       = 1001
+	closeProtocolError   = 1002
+	closePolicyViolation = 1008
+	closeMessageTooBig   = 1009
+
+	// maximum payload of a frame received from a client,
+	// the clients are not expected to send data frames.
+	maxReadPayload     = 4096
+	maxControlPayload  = 125
+	closeFrameDeadline = time.Second
+)
+
+var (
+	errFrameTooBig   = errors.New("frame too big")
+	errUnmaskedFrame = errors.New("unmasked client frame")
+)
+
+// client is a websocket connection, its frames are written by its writer goroutine only.
+type client struct {
+	id      string
+	conn    net.Conn
+	reader  *bufio.Reader
+	filters [][]string
+
+	send chan []byte
+	pong chan []byte
+	done chan struct{}
+
+	closeOnce   sync.Once
+	closeCode   uint16
+	closeReason string
+}
+
+func newClient(conn net.Conn, reader *bufio.Reader, filters [][]string, bufferSize int) *client {
+	return &client{
+		id:      conn.RemoteAddr().String(),
+		conn:    conn,
+		reader:  reader,
+		filters: filters,
+		send:    make(chan []byte, bufferSize),
+		pong:    make(chan []byte, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// close stops the client, its writer sends a close frame with code and reason
+// then closes the connection.
+func (c *client) close(code uint16, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+	})
+}
+
+// writer writes the queued messages and the pong frames as they come,
+// then the close frame once the client is closed.
+func (c *client) writer(writeTimeout time.Duration) error {
+	defer c.conn.Close()
+	for {
+		select {
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(closeFrameDeadline))
+			return writeFrame(c.conn, opClose, closePayload(c.closeCode, c.closeReason))
+		case b := <-c.pong:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := writeFrame(c.conn, opPong, b); err != nil {
+				c.close(closeGoingAway, "")
+				return err
+			}
+		case b := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := writeFrame(c.conn, opText, b); err != nil {
+				c.close(closeGoingAway, "")
+				return err
+			}
+		}
+	}
+}
+
+// reader reads the client frames: pings are answered, a close frame closes the client.
+// Data frames are discarded.
+func (c *client) readLoop() {
+	for {
+		op, payload, err := readFrame(c.reader)
+		switch {
+		case errors.Is(err, errFrameTooBig):
+			c.close(closeMessageTooBig, "")
+			return
+		case errors.Is(err, errUnmaskedFrame):
+			c.close(closeProtocolError, "unmasked frame")
+			return
+		case err != nil:
+			c.close(closeGoingAway, "")
+			return
+		}
+		switch op {
+		case opPing:
+			select {
+			case c.pong <- payload:
+			default:
+			}
+		case opClose:
+			c.close(closeNormal, "")
+			return
+		case opPong, opText, opBinary, opContinuation:
+		default:
+			c.close(closeProtocolError, "unknown opcode")
+			return
+		}
+	}
+}
+
+// upgrade validates the websocket handshake request r and switches the connection protocol.
+// It returns the hijacked connection and its buffered reader.
+func upgrade(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.Reader, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, nil, fmt.Errorf("unexpected method %q", r.Method)
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return nil, nil, errors.New("not a websocket upgrade request")
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return nil, nil, fmt.Errorf("unsupported websocket version %q", r.Header.Get("Sec-Websocket-Version"))
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key header", http.StatusBadRequest)
+		return nil, nil, errors.New("missing Sec-WebSocket-Key header")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = io.WriteString(conn, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: "+acceptKey(key)+"\r\n\r\n")
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, brw.Reader, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContains reports whether the comma separated values of header k contain token v.
+func headerContains(h http.Header, k, v string) bool {
+	for _, hv := range h.Values(k) {
+		for _, t := range strings.Split(hv, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrame writes an unmasked, unfragmented, server frame.
+func writeFrame(w io.Writer, op byte, payload []byte) error {
+	hdr := make([]byte, 2, 10)
+	hdr[0] = 0x80 | op
+	l := len(payload)
+	switch {
+	case l <= maxControlPayload:
+		hdr[1] = byte(l)
+	case l <= 0xffff:
+		hdr[1] = 126
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(l))
+	default:
+		hdr[1] = 127
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(l))
+	}
+	_, err := w.Write(append(hdr, payload...))
+	return err
+}
+
+// readFrame reads a masked client frame and returns its opcode and unmasked payload.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, nil, err
+	}
+	op := hdr[0] & 0x0f
+	masked := hdr[1]&0x80 != 0
+	l := uint64(hdr[1] & 0x7f)
+	switch l {
+	case 126:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, nil, err
+		}
+		l = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, nil, err
+		}
+		l = binary.BigEndian.Uint64(b)
+	}
+	if l > maxReadPayload || (op >= opClose && l > maxControlPayload) {
+		return 0, nil, errFrameTooBig
+	}
+	if !masked {
+		return 0, nil, errUnmaskedFrame
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(r, mask); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, l)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+func closePayload(code uint16, reason string) []byte {
+	b := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(reason)), code)
+	return append(b, reason...)
+}
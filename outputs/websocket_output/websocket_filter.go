@@ -0,0 +1,106 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket_output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+// parseFilters parses the path filters of a client,
+// each filter is a path prefix in which a * element matches any element, e.g: /interfaces/interface/*/counters.
+// The list keys, the origin and the trailing slash are ignored.
+func parseFilters(paths []string) ([][]string, error) {
+	filters := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		elems := pathElems(p)
+		if len(elems) == 0 {
+			return nil, fmt.Errorf("invalid path filter %q", p)
+		}
+		filters = append(filters, elems)
+	}
+	return filters, nil
+}
+
+// pathElems returns the elements names of xpath p, without the list keys and the origin.
+func pathElems(p string) []string {
+	if i := strings.Index(p, ":"); i >= 0 && i < strings.Index(p, "/") {
+		p = p[i+1:]
+	}
+	var sb strings.Builder
+	depth := 0
+	for _, r := range p {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	elems := make([]string, 0)
+	for _, e := range strings.Split(sb.String(), "/") {
+		if e != "" {
+			elems = append(elems, e)
+		}
+	}
+	return elems
+}
+
+// matchFilters reports whether the value or delete path p matches one of the filters.
+func matchFilters(p string, filters [][]string) bool {
+	elems := pathElems(p)
+FILTERS:
+	for _, f := range filters {
+		if len(f) > len(elems) {
+			continue
+		}
+		for i, fe := range f {
+			if fe != "*" && fe != elems[i] {
+				continue FILTERS
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// filterEvent returns a copy of event ev with only the values and deletes matching the filters,
+// or nil if none matches.
+func filterEvent(ev *formatters.EventMsg, filters [][]string) *formatters.EventMsg {
+	fev := &formatters.EventMsg{
+		Name:          ev.Name,
+		Timestamp:     ev.Timestamp,
+		RecvTimestamp: ev.RecvTimestamp,
+		Tags:          ev.Tags,
+	}
+	for k, v := range ev.Values {
+		if !matchFilters(k, filters) {
+			continue
+		}
+		if fev.Values == nil {
+			fev.Values = make(map[string]interface{})
+		}
+		fev.Values[k] = v
+	}
+	for _, d := range ev.Deletes {
+		if matchFilters(d, filters) {
+			fev.Deletes = append(fev.Deletes, d)
+		}
+	}
+	if len(fev.Values) == 0 && len(fev.Deletes) == 0 {
+		return nil
+	}
+	return fev
+}
@@ -0,0 +1,64 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket_output
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var numberOfClients = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "websocket_output",
+	Name:      "number_of_clients",
+	Help:      "Number of clients connected to websocket output",
+}, []string{"name"})
+
+var numberOfSentMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "websocket_output",
+	Name:      "number_messages_sent_total",
+	Help:      "Number of messages queued to the clients of websocket output",
+}, []string{"name"})
+
+var numberOfSlowClients = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "websocket_output",
+	Name:      "number_slow_clients_disconnected_total",
+	Help:      "Number of clients disconnected by websocket output because their send buffer was full",
+}, []string{"name"})
+
+var numberOfRejectedClients = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "websocket_output",
+	Name:      "number_clients_rejected_total",
+	Help:      "Number of clients rejected by websocket output because max-clients was reached",
+}, []string{"name"})
+
+func initMetrics(name string) {
+	numberOfClients.WithLabelValues(name).Set(0)
+	numberOfSentMessages.WithLabelValues(name).Add(0)
+	numberOfSlowClients.WithLabelValues(name).Add(0)
+	numberOfRejectedClients.WithLabelValues(name).Add(0)
+}
+
+func registerMetrics(reg *prometheus.Registry, name string) error {
+	initMetrics(name)
+	var err error
+	if err = reg.Register(numberOfClients); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfSentMessages); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfSlowClients); err != nil {
+		return err
+	}
+	if err = reg.Register(numberOfRejectedClients); err != nil {
+		return err
+	}
+	return nil
+}
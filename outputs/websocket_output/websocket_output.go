@@ -0,0 +1,382 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket_output
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/formatters"
+	"github.com/openconfig/gnmic/outputs"
+	"github.com/openconfig/gnmic/types"
+	"github.com/openconfig/gnmic/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	outputType              = "websocket"
+	loggingPrefix           = "[websocket_output:%s] "
+	defaultPath             = "/"
+	defaultClientBufferSize = 1000
+	defaultWriteTimeout     = 10 * time.Second
+	shutdownTimeout         = 5 * time.Second
+	filterQueryParam        = "path"
+)
+
+func init() {
+	outputs.Register(outputType, func() outputs.Output {
+		return &wsOutput{
+			Cfg:     &Config{},
+			logger:  log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			clients: make(map[*client]struct{}),
+		}
+	})
+}
+
+type wsOutput struct {
+	Cfg *Config
+
+	name   string
+	logger *log.Logger
+	evps   []formatters.EventProcessor
+	server *http.Server
+
+	m       sync.RWMutex
+	clients map[*client]struct{}
+	closed  bool
+	wg      sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+type Config struct {
+	// listen address of the HTTP server, e.g: :8080
+	Listen string `mapstructure:"listen,omitempty" json:"listen,omitempty"`
+	// HTTP path of the websocket endpoint, defaults to /
+	Path string     `mapstructure:"path,omitempty" json:"path,omitempty"`
+	TLS  *tlsConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	// maximum number of messages queued for a client,
+	// a client with a full buffer is disconnected.
+	ClientBufferSize int `mapstructure:"client-buffer-size,omitempty" json:"client-buffer-size,omitempty"`
+	// maximum number of connected clients, 0 means no limit.
+	MaxClients      int           `mapstructure:"max-clients,omitempty" json:"max-clients,omitempty"`
+	WriteTimeout    time.Duration `mapstructure:"write-timeout,omitempty" json:"write-timeout,omitempty"`
+	EnableMetrics   bool          `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EventProcessors []string      `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+type tlsConfig struct {
+	CertFile string `mapstructure:"cert-file,omitempty" json:"cert-file,omitempty"`
+	KeyFile  string `mapstructure:"key-file,omitempty" json:"key-file,omitempty"`
+}
+
+func (o *wsOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && o.logger != nil {
+		o.logger.SetOutput(logger.Writer())
+		o.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (o *wsOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) {
+	for _, epName := range o.Cfg.EventProcessors {
+		if epCfg, ok := ps[epName]; ok {
+			epType := ""
+			for k := range epCfg {
+				epType = k
+				break
+			}
+			if in, ok := formatters.EventProcessors[epType]; ok {
+				ep := in()
+				err := ep.Init(epCfg[epType],
+					formatters.WithLogger(logger),
+					formatters.WithTargets(tcs),
+					formatters.WithActions(acts),
+				)
+				if err != nil {
+					o.logger.Printf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
+					continue
+				}
+				o.evps = append(o.evps, ep)
+				o.logger.Printf("added event processor '%s' of type=%s to websocket output", epName, epType)
+				continue
+			}
+			o.logger.Printf("%q event processor has an unknown type=%q", epName, epType)
+			continue
+		}
+		o.logger.Printf("%q event processor not found!", epName)
+	}
+}
+
+func (o *wsOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, o.Cfg)
+	if err != nil {
+		return err
+	}
+	o.name = name
+	o.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+
+	for _, opt := range opts {
+		opt(o)
+	}
+	err = o.setDefaults()
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(o.Cfg.Path, o.handle)
+	o.server = &http.Server{
+		Handler: mux,
+		// the websocket connections are hijacked HTTP/1.1 connections
+		TLSNextProto: make(map[string]func(*http.Server, *tls.Conn, http.Handler)),
+	}
+	var listener net.Listener
+	listener, err = net.Listen("tcp", o.Cfg.Listen)
+	if err != nil {
+		return err
+	}
+	if o.Cfg.TLS != nil {
+		tlsCfg, err := utils.NewTLSConfig("", o.Cfg.TLS.CertFile, o.Cfg.TLS.KeyFile, false, true)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsCfg)
+	}
+	go func() {
+		err := o.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			o.logger.Printf("websocket server error: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		o.Close()
+	}()
+	o.logger.Printf("initialized websocket output: %s", o.String())
+	return nil
+}
+
+func (o *wsOutput) setDefaults() error {
+	if o.Cfg.Listen == "" {
+		return errors.New("missing listen field")
+	}
+	if o.Cfg.Path == "" {
+		o.Cfg.Path = defaultPath
+	}
+	if !strings.HasPrefix(o.Cfg.Path, "/") {
+		o.Cfg.Path = "/" + o.Cfg.Path
+	}
+	if o.Cfg.ClientBufferSize <= 0 {
+		o.Cfg.ClientBufferSize = defaultClientBufferSize
+	}
+	if o.Cfg.MaxClients < 0 {
+		return errors.New("max-clients cannot be negative")
+	}
+	if o.Cfg.WriteTimeout <= 0 {
+		o.Cfg.WriteTimeout = defaultWriteTimeout
+	}
+	return nil
+}
+
+// handle upgrades the request to a websocket connection
+// and registers it as a client, with the path filters set as query parameters.
+func (o *wsOutput) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != o.Cfg.Path {
+		http.NotFound(w, r)
+		return
+	}
+	filters, err := parseFilters(r.URL.Query()[filterQueryParam])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o.m.RLock()
+	full := o.Cfg.MaxClients > 0 && len(o.clients) >= o.Cfg.MaxClients
+	o.m.RUnlock()
+	if full {
+		http.Error(w, "too many clients", http.StatusServiceUnavailable)
+		numberOfRejectedClients.WithLabelValues(o.name).Inc()
+		return
+	}
+	conn, reader, err := upgrade(w, r)
+	if err != nil {
+		o.logger.Printf("client %s: websocket upgrade failed: %v", r.RemoteAddr, err)
+		return
+	}
+	c := newClient(conn, reader, filters, o.Cfg.ClientBufferSize)
+	if !o.addClient(c) {
+		c.close(closeGoingAway, "server shutting down")
+		c.writer(o.Cfg.WriteTimeout)
+		return
+	}
+	o.logger.Printf("client %s connected, filters: %v", c.id, r.URL.Query()[filterQueryParam])
+	go func() {
+		defer o.wg.Done()
+		err := c.writer(o.Cfg.WriteTimeout)
+		if err != nil && o.Cfg.Debug {
+			o.logger.Printf("client %s: %v", c.id, err)
+		}
+		o.removeClient(c)
+		o.logger.Printf("client %s disconnected: code=%d %s", c.id, c.closeCode, c.closeReason)
+	}()
+	go c.readLoop()
+}
+
+// addClient registers client c, it returns false if the output is closed.
+func (o *wsOutput) addClient(c *client) bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed {
+		return false
+	}
+	o.wg.Add(1)
+	o.clients[c] = struct{}{}
+	numberOfClients.WithLabelValues(o.name).Set(float64(len(o.clients)))
+	return true
+}
+
+func (o *wsOutput) removeClient(c *client) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	delete(o.clients, c)
+	numberOfClients.WithLabelValues(o.name).Set(float64(len(o.clients)))
+}
+
+func (o *wsOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	switch rsp := m.(type) {
+	case *gnmi.SubscribeResponse:
+		name := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			name = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(name, rsp, meta, o.evps...)
+		if err != nil {
+			o.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			o.broadcast(ev)
+		}
+	}
+}
+
+func (o *wsOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range o.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		o.broadcast(pev)
+	}
+}
+
+// broadcast queues event ev, as a JSON text frame, to the clients its values match the filters of.
+// A client with a full send buffer is disconnected.
+func (o *wsOutput) broadcast(ev *formatters.EventMsg) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+	if len(o.clients) == 0 {
+		return
+	}
+	var all []byte
+	for c := range o.clients {
+		var b []byte
+		var err error
+		if len(c.filters) == 0 {
+			if all == nil {
+				all, err = json.Marshal(ev)
+			}
+			b = all
+		} else {
+			fev := filterEvent(ev, c.filters)
+			if fev == nil {
+				continue
+			}
+			b, err = json.Marshal(fev)
+		}
+		if err != nil {
+			o.logger.Printf("failed to marshal event: %v", err)
+			return
+		}
+		select {
+		case <-c.done:
+		case c.send <- b:
+			numberOfSentMessages.WithLabelValues(o.name).Inc()
+		default:
+			o.logger.Printf("client %s: send buffer full, disconnecting", c.id)
+			numberOfSlowClients.WithLabelValues(o.name).Inc()
+			c.close(closePolicyViolation, "send buffer full")
+		}
+	}
+}
+
+// Close stops the HTTP server and closes the clients connections with a going away close frame.
+func (o *wsOutput) Close() error {
+	var err error
+	o.closeOnce.Do(func() {
+		o.m.Lock()
+		o.closed = true
+		for c := range o.clients {
+			c.close(closeGoingAway, "server shutting down")
+		}
+		o.m.Unlock()
+		if o.server != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			err = o.server.Shutdown(ctx)
+		}
+		o.wg.Wait()
+	})
+	return err
+}
+
+func (o *wsOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !o.Cfg.EnableMetrics {
+		return
+	}
+	if err := registerMetrics(reg, o.name); err != nil {
+		o.logger.Printf("failed to register metric: %v", err)
+	}
+}
+
+func (o *wsOutput) String() string {
+	b, err := json.Marshal(o.Cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (o *wsOutput) SetName(name string)                             {}
+func (o *wsOutput) SetClusterName(name string)                      {}
+func (o *wsOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
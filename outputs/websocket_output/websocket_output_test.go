@@ -0,0 +1,279 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket_output
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/formatters"
+)
+
+var filterEventTestSet = map[string]struct {
+	filters []string
+	in      *formatters.EventMsg
+	out     *formatters.EventMsg
+}{
+	"prefix": {
+		filters: []string{"/interfaces/interface/state/counters"},
+		in: &formatters.EventMsg{
+			Name: "sub1",
+			Tags: map[string]string{"interface_name": "ethernet-1/1"},
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/counters/in-octets": 42,
+				"/interfaces/interface/state/oper-status":        "UP",
+			},
+		},
+		out: &formatters.EventMsg{
+			Name: "sub1",
+			Tags: map[string]string{"interface_name": "ethernet-1/1"},
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/counters/in-octets": 42,
+			},
+		},
+	},
+	"wildcard_keys_origin": {
+		filters: []string{"openconfig:/interfaces/interface[name=*]/*/oper-status"},
+		in: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/counters/in-octets": 42,
+				"/interfaces/interface/state/oper-status":        "UP",
+			},
+		},
+		out: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/oper-status": "UP",
+			},
+		},
+	},
+	"deletes": {
+		filters: []string{"/system"},
+		in: &formatters.EventMsg{
+			Deletes: []string{"/system/name", "/interfaces/interface"},
+		},
+		out: &formatters.EventMsg{
+			Deletes: []string{"/system/name"},
+		},
+	},
+	"no_match": {
+		filters: []string{"/system"},
+		in: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/oper-status": "UP",
+			},
+		},
+		out: nil,
+	},
+}
+
+func TestFilterEvent(t *testing.T) {
+	for name, item := range filterEventTestSet {
+		t.Run(name, func(t *testing.T) {
+			filters, err := parseFilters(item.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := filterEvent(item.in, filters)
+			if !reflect.DeepEqual(out, item.out) {
+				t.Errorf("failed at item %q: expected %v, got %v", name, item.out, out)
+			}
+		})
+	}
+	_, err := parseFilters([]string{"/"})
+	if err == nil {
+		t.Errorf("expected an error for an empty path filter")
+	}
+}
+
+func newTestOutput(t *testing.T) (*wsOutput, *httptest.Server) {
+	o := &wsOutput{
+		Cfg:     &Config{Listen: ":0"},
+		logger:  log.New(io.Discard, "", 0),
+		clients: make(map[*client]struct{}),
+	}
+	err := o.setDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o, httptest.NewServer(http.HandlerFunc(o.handle))
+}
+
+// dial opens a websocket connection to the test server srv.
+func dial(t *testing.T, srv *httptest.Server, query string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "GET /%s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n",
+		query, srv.Listener.Addr())
+	r := bufio.NewReader(conn)
+	rsp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected handshake response status: %s", rsp.Status)
+	}
+	// RFC 6455 section 1.3 example
+	if accept := rsp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected Sec-WebSocket-Accept: %q", accept)
+	}
+	return conn, r
+}
+
+// readServerFrame reads an unmasked server frame.
+func readServerFrame(t *testing.T, conn net.Conn, r *bufio.Reader) (byte, []byte) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		t.Fatal(err)
+	}
+	l := uint64(hdr[1] & 0x7f)
+	switch l {
+	case 126:
+		b := make([]byte, 2)
+		io.ReadFull(r, b)
+		l = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		io.ReadFull(r, b)
+		l = binary.BigEndian.Uint64(b)
+	}
+	payload := make([]byte, l)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return hdr[0] & 0x0f, payload
+}
+
+// writeClientFrame writes a masked client frame.
+func writeClientFrame(conn net.Conn, op byte, payload []byte) error {
+	mask := []byte{1, 2, 3, 4}
+	b := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	b = append(b, mask...)
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+	_, err := conn.Write(b)
+	return err
+}
+
+func waitClients(t *testing.T, o *wsOutput, n int) {
+	for i := 0; i < 100; i++ {
+		o.m.RLock()
+		l := len(o.clients)
+		o.m.RUnlock()
+		if l == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d clients", n)
+}
+
+func TestWebsocketOutput(t *testing.T) {
+	o, srv := newTestOutput(t)
+	defer srv.Close()
+
+	conn1, r1 := dial(t, srv, "")
+	defer conn1.Close()
+	conn2, r2 := dial(t, srv, "?path=/system")
+	defer conn2.Close()
+	waitClients(t, o, 2)
+
+	o.WriteEvent(context.Background(), &formatters.EventMsg{
+		Name:   "sub1",
+		Values: map[string]interface{}{"/interfaces/interface/state/oper-status": "UP"},
+	})
+	o.WriteEvent(context.Background(), &formatters.EventMsg{
+		Name:   "sub1",
+		Values: map[string]interface{}{"/system/name": "r1"},
+	})
+	// the first client receives both events
+	for _, v := range []string{"oper-status", "/system/name"} {
+		op, b := readServerFrame(t, conn1, r1)
+		if op != opText || !strings.Contains(string(b), v) {
+			t.Errorf("unexpected frame: op=%d, payload=%s", op, b)
+		}
+	}
+	// the second client only the one matching its filter
+	op, b := readServerFrame(t, conn2, r2)
+	ev := new(formatters.EventMsg)
+	if err := json.Unmarshal(b, ev); err != nil {
+		t.Fatal(err)
+	}
+	if op != opText || ev.Values["/system/name"] != "r1" {
+		t.Errorf("unexpected frame: op=%d, payload=%s", op, b)
+	}
+
+	// ping
+	if err := writeClientFrame(conn1, opPing, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	op, b = readServerFrame(t, conn1, r1)
+	if op != opPong || string(b) != "hi" {
+		t.Errorf("unexpected pong frame: op=%d, payload=%s", op, b)
+	}
+
+	// shutdown
+	err := o.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		conn net.Conn
+		r    *bufio.Reader
+	}{{conn1, r1}, {conn2, r2}} {
+		op, b := readServerFrame(t, c.conn, c.r)
+		if op != opClose || len(b) < 2 || binary.BigEndian.Uint16(b) != closeGoingAway {
+			t.Errorf("unexpected close frame: op=%d, payload=%v", op, b)
+		}
+	}
+	waitClients(t, o, 0)
+}
+
+func TestSlowClient(t *testing.T) {
+	o, srv := newTestOutput(t)
+	srv.Close()
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	c := newClient(conn, bufio.NewReader(conn), nil, 1)
+	o.clients[c] = struct{}{}
+
+	ev := &formatters.EventMsg{Values: map[string]interface{}{"/system/name": "r1"}}
+	o.broadcast(ev)
+	select {
+	case <-c.done:
+		t.Fatalf("client closed with a free send buffer")
+	default:
+	}
+	o.broadcast(ev)
+	select {
+	case <-c.done:
+	default:
+		t.Fatalf("client not closed with a full send buffer")
+	}
+	if c.closeCode != closePolicyViolation {
+		t.Errorf("unexpected close code: %d", c.closeCode)
+	}
+}
@@ -0,0 +1,122 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrEncodingFallback is sent on the target errors channel when a subscription
+// rejected for its encoding is sent again with the next fallback encoding.
+var ErrEncodingFallback = errors.New("encoding rejected")
+
+// words found, next to "encoding", in the messages of the targets
+// rejecting a request for its encoding.
+var encodingRejectedWords = []string{
+	"unsupported",
+	"not supported",
+	"does not support",
+	"not implemented",
+	"unimplemented",
+	"unknown",
+	"invalid",
+	"cannot",
+}
+
+// IsEncodingRejected returns true if err is a gRPC status error rejecting
+// the request encoding: an Unimplemented or InvalidArgument code
+// with a message reporting an unsupported encoding.
+func IsEncodingRejected(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Unimplemented, codes.InvalidArgument:
+	default:
+		return false
+	}
+	msg := strings.ToLower(st.Message())
+	if !strings.Contains(msg, "encoding") {
+		return false
+	}
+	for _, w := range encodingRejectedWords {
+		if strings.Contains(msg, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEncoding returns the gNMI encoding named enc, case insensitive.
+func ParseEncoding(enc string) (gnmi.Encoding, bool) {
+	v, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(enc, "-", "_"))]
+	return gnmi.Encoding(v), ok
+}
+
+// NextEncoding returns the encoding to send again a request sent with encoding cur
+// and rejected with err: the encoding following cur in the fallback list,
+// or its first one if cur is not in the list.
+// It returns false if err does not reject the encoding or if the list is exhausted.
+func NextEncoding(fallback []string, cur gnmi.Encoding, err error) (gnmi.Encoding, bool) {
+	if len(fallback) == 0 || !IsEncodingRejected(err) {
+		return 0, false
+	}
+	next := 0
+	for i, name := range fallback {
+		if enc, ok := ParseEncoding(name); ok && enc == cur {
+			next = i + 1
+			break
+		}
+	}
+	for ; next < len(fallback); next++ {
+		if enc, ok := ParseEncoding(fallback[next]); ok && enc != cur {
+			return enc, true
+		}
+	}
+	return 0, false
+}
+
+// EncodingName returns the lower case name of encoding enc, as set in the configuration.
+func EncodingName(enc gnmi.Encoding) string {
+	return strings.ToLower(enc.String())
+}
+
+// nextEncoding returns the encoding to send again the request req of subscription subName
+// rejected with err, from the target encoding-fallback list.
+// The fallback is reported on the target errors channel.
+func (t *Target) nextEncoding(req *gnmi.SubscribeRequest, subName string, err error) (gnmi.Encoding, bool) {
+	cur := req.GetSubscribe().GetEncoding()
+	next, ok := NextEncoding(t.Config.EncodingFallback, cur, err)
+	if !ok {
+		return 0, false
+	}
+	t.errors <- &TargetError{
+		SubscriptionName: subName,
+		Err: fmt.Errorf("%w: encoding %s: %v, falling back to encoding %s",
+			ErrEncodingFallback, EncodingName(cur), err, EncodingName(next)),
+	}
+	return next, true
+}
+
+// withSubscribeEncoding returns a copy of the subscribe request req with the encoding enc.
+func withSubscribeEncoding(req *gnmi.SubscribeRequest, enc gnmi.Encoding) *gnmi.SubscribeRequest {
+	req = proto.Clone(req).(*gnmi.SubscribeRequest)
+	if sub := req.GetSubscribe(); sub != nil {
+		sub.Encoding = enc
+	}
+	return req
+}
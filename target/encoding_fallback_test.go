@@ -0,0 +1,210 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsEncodingRejected(t *testing.T) {
+	for name, item := range map[string]struct {
+		err  error
+		want bool
+	}{
+		// rejection messages in the forms returned by Nokia SR Linux and Arista EOS
+		"srl_invalid_argument": {
+			err:  status.Error(codes.InvalidArgument, "Unsupported encoding JSON for path /interface[name=ethernet-1/1]/statistics"),
+			want: true,
+		},
+		"eos_unimplemented": {
+			err:  status.Error(codes.Unimplemented, "unsupported encoding: JSON_IETF"),
+			want: true,
+		},
+		"not_supported": {
+			err:  status.Error(codes.InvalidArgument, "requested encoding PROTO is not supported"),
+			want: true,
+		},
+		"wrapped_status": {
+			err:  &StreamError{Err: status.Error(codes.Unimplemented, "encoding ASCII not implemented")},
+			want: true,
+		},
+		"invalid_path": {
+			err: status.Error(codes.InvalidArgument, "invalid path /foo"),
+		},
+		"unimplemented_rpc": {
+			err: status.Error(codes.Unimplemented, "unknown service gnmi.gNMI"),
+		},
+		"other_code": {
+			err: status.Error(codes.NotFound, "unsupported encoding JSON"),
+		},
+		"not_a_status": {
+			err: errors.New("unsupported encoding JSON"),
+		},
+	} {
+		if got := IsEncodingRejected(item.err); got != item.want {
+			t.Errorf("failed at item %q: expected %v, got %v", name, item.want, got)
+		}
+	}
+}
+
+func TestNextEncoding(t *testing.T) {
+	rejected := status.Error(codes.InvalidArgument, "unsupported encoding")
+	for name, item := range map[string]struct {
+		fallback []string
+		cur      gnmi.Encoding
+		err      error
+		want     gnmi.Encoding
+		ok       bool
+	}{
+		"first_fallback": {
+			fallback: []string{"JSON", "PROTO"},
+			cur:      gnmi.Encoding_JSON_IETF,
+			err:      rejected,
+			want:     gnmi.Encoding_JSON,
+			ok:       true,
+		},
+		"next_fallback": {
+			fallback: []string{"json", "proto"},
+			cur:      gnmi.Encoding_JSON,
+			err:      rejected,
+			want:     gnmi.Encoding_PROTO,
+			ok:       true,
+		},
+		"exhausted": {
+			fallback: []string{"JSON", "PROTO"},
+			cur:      gnmi.Encoding_PROTO,
+			err:      rejected,
+		},
+		"skip_current": {
+			fallback: []string{"json_ietf", "json"},
+			cur:      gnmi.Encoding_JSON_IETF,
+			err:      rejected,
+			want:     gnmi.Encoding_JSON,
+			ok:       true,
+		},
+		"no_fallback": {
+			cur: gnmi.Encoding_JSON_IETF,
+			err: rejected,
+		},
+		"other_error": {
+			fallback: []string{"JSON"},
+			cur:      gnmi.Encoding_JSON_IETF,
+			err:      status.Error(codes.Unavailable, "connection refused"),
+		},
+	} {
+		got, ok := NextEncoding(item.fallback, item.cur, item.err)
+		if ok != item.ok || got != item.want {
+			t.Errorf("failed at item %q: expected (%v, %v), got (%v, %v)", name, item.want, item.ok, got, ok)
+		}
+	}
+}
+
+// encodingServer rejects the subscriptions with an encoding other than accepted,
+// and replies to the others with an update and a sync response.
+type encodingServer struct {
+	gnmi.UnimplementedGNMIServer
+	accepted gnmi.Encoding
+	received chan gnmi.Encoding
+}
+
+func (s *encodingServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	enc := req.GetSubscribe().GetEncoding()
+	s.received <- enc
+	if enc != s.accepted {
+		return status.Errorf(codes.InvalidArgument, "unsupported encoding %s", enc)
+	}
+	err = stream.Send(updateRsp(ifacePath))
+	if err != nil {
+		return err
+	}
+	err = stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+	if err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestSubscribeEncodingFallback(t *testing.T) {
+	srv := &encodingServer{
+		accepted: gnmi.Encoding_PROTO,
+		received: make(chan gnmi.Encoding, 10),
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	insecure := true
+	tg := NewTarget(&types.TargetConfig{
+		Name:             "t1",
+		Address:          l.Addr().String(),
+		Insecure:         &insecure,
+		Timeout:          5 * time.Second,
+		BufferSize:       10,
+		RetryTimer:       time.Minute,
+		EncodingFallback: []string{"JSON", "PROTO"},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx)
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+
+	go tg.Subscribe(ctx, streamRequest(gnmi.SubscriptionMode_SAMPLE), "sub1")
+
+	rspCh, errCh := tg.ReadSubscriptions()
+	fallbacks := 0
+	for {
+		select {
+		case tErr := <-errCh:
+			if !errors.Is(tErr.Err, ErrEncodingFallback) {
+				t.Fatalf("unexpected error: %v", tErr.Err)
+			}
+			fallbacks++
+			continue
+		case rsp := <-rspCh:
+			if rsp.Encoding != "proto" {
+				t.Errorf("expected the response encoding to be proto, got %q", rsp.Encoding)
+			}
+		case <-ctx.Done():
+			t.Fatal("no response received")
+		}
+		break
+	}
+	if fallbacks != 2 {
+		t.Errorf("expected 2 fallbacks, got %d", fallbacks)
+	}
+	want := []gnmi.Encoding{gnmi.Encoding_JSON_IETF, gnmi.Encoding_JSON, gnmi.Encoding_PROTO}
+	for i, enc := range want {
+		if got := <-srv.received; got != enc {
+			t.Errorf("request %d: expected encoding %s, got %s", i, enc, got)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetaHeaderPrefix prefixes the response header keys in the Meta map.
+	MetaHeaderPrefix = "header:"
+	// MetaTrailerPrefix prefixes the response trailer keys in the Meta map.
+	MetaTrailerPrefix = "trailer:"
+	// MetaEncoding is the Meta key of the encoding a request fell back to.
+	MetaEncoding = "encoding"
+)
+
+// RPCMetadata holds the headers and trailers returned by a target for an RPC.
+type RPCMetadata struct {
+	Header  metadata.MD
+	Trailer metadata.MD
+	// encoding the request was sent with, set if it fell back from the configured one
+	Encoding string
+}
+
+type rpcMetadataKey struct{}
+
+// WithRPCMetadata returns a copy of ctx capturing in md the headers
+// and trailers of the Capabilities, Get and Set RPCs sent with it.
+func WithRPCMetadata(ctx context.Context, md *RPCMetadata) context.Context {
+	return context.WithValue(ctx, rpcMetadataKey{}, md)
+}
+
+// RPCMetadataFromContext returns the RPCMetadata set in ctx by WithRPCMetadata, or nil.
+func RPCMetadataFromContext(ctx context.Context) *RPCMetadata {
+	md, _ := ctx.Value(rpcMetadataKey{}).(*RPCMetadata)
+	return md
+}
+
+func callOptions(ctx context.Context) []grpc.CallOption {
+	md := RPCMetadataFromContext(ctx)
+	if md == nil {
+		return nil
+	}
+	return []grpc.CallOption{grpc.Header(&md.Header), grpc.Trailer(&md.Trailer)}
+}
+
+// Empty returns true if no header or trailer, other than the gRPC reserved ones, was received.
+func (md *RPCMetadata) Empty() bool {
+	return md == nil || (len(userMetadata(md.Header)) == 0 && len(userMetadata(md.Trailer)) == 0)
+}
+
+// Meta returns the headers and trailers as a flat map, the keys prefixed with
+// MetaHeaderPrefix or MetaTrailerPrefix and the values of a key joined with a comma.
+func (md *RPCMetadata) Meta() map[string]string {
+	m := make(map[string]string)
+	if md == nil {
+		return m
+	}
+	for k, vs := range userMetadata(md.Header) {
+		m[MetaHeaderPrefix+k] = strings.Join(vs, ",")
+	}
+	for k, vs := range userMetadata(md.Trailer) {
+		m[MetaTrailerPrefix+k] = strings.Join(vs, ",")
+	}
+	if md.Encoding != "" {
+		m[MetaEncoding] = md.Encoding
+	}
+	return m
+}
+
+func (md *RPCMetadata) String() string {
+	if md == nil {
+		return ""
+	}
+	return fmt.Sprintf("header: %s, trailer: %s", FormatMD(md.Header), FormatMD(md.Trailer))
+}
+
+// FormatMD returns the keys of md, other than the gRPC reserved ones,
+// sorted and formatted as `key=value`.
+func FormatMD(md metadata.MD) string {
+	md = userMetadata(md)
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, fmt.Sprintf("%s=%s", k, strings.Join(md[k], ",")))
+	}
+	return strings.Join(kvs, " ")
+}
+
+// userMetadata returns md without the gRPC reserved and HTTP/2 keys.
+func userMetadata(md metadata.MD) metadata.MD {
+	if len(md) == 0 {
+		return nil
+	}
+	umd := make(metadata.MD, len(md))
+	for k, vs := range md {
+		if strings.HasPrefix(k, "grpc-") || strings.HasPrefix(k, ":") || k == "content-type" {
+			continue
+		}
+		umd[k] = vs
+	}
+	return umd
+}
+
+// StreamError is a subscribe stream termination error along with
+// the trailer sent by the target, which often carries the actual close reason.
+type StreamError struct {
+	Err     error
+	Trailer metadata.MD
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("stream closed by target: %s (%v)", FormatMD(e.Trailer), e.Err)
+}
+
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+func (e *StreamError) GRPCStatus() *status.Status {
+	return status.Convert(e.Err)
+}
+
+// streamError returns err wrapped in a StreamError if the terminated stream has a trailer.
+func streamError(stream grpc.ClientStream, err error) error {
+	tr := userMetadata(stream.Trailer())
+	if len(tr) == 0 {
+		return err
+	}
+	return &StreamError{Err: err, Trailer: tr}
+}
+
+// streamHeader returns the header of stream, once its first response is received.
+// It returns a non nil MD to be fetched once per stream.
+func streamHeader(stream grpc.ClientStream) metadata.MD {
+	md, err := stream.Header()
+	if err != nil {
+		return metadata.MD{}
+	}
+	if umd := userMetadata(md); umd != nil {
+		return umd
+	}
+	return metadata.MD{}
+}
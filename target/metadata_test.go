@@ -0,0 +1,56 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRPCMetadataMeta(t *testing.T) {
+	md := &RPCMetadata{
+		Header:  metadata.Pairs("content-type", "application/grpc", "sw-version", "23.3.1"),
+		Trailer: metadata.Pairs("grpc-status", "0", "reason", "a", "reason", "b"),
+	}
+	meta := md.Meta()
+	if len(meta) != 2 || meta["header:sw-version"] != "23.3.1" || meta["trailer:reason"] != "a,b" {
+		t.Errorf("unexpected meta: %v", meta)
+	}
+	if md.Empty() {
+		t.Errorf("expected a non empty metadata")
+	}
+	md = &RPCMetadata{Header: metadata.Pairs("content-type", "application/grpc")}
+	if !md.Empty() {
+		t.Errorf("expected an empty metadata, got %v", md.Meta())
+	}
+	md = nil
+	if !md.Empty() {
+		t.Errorf("expected a nil metadata to be empty")
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	tr := metadata.Pairs("close-reason", "maintenance")
+	err := error(&StreamError{Err: status.Error(codes.Unavailable, "transport is closing"), Trailer: tr})
+	if err.Error() != "stream closed by target: close-reason=maintenance (rpc error: code = Unavailable desc = transport is closing)" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the status code to be preserved, got %v", status.Code(err))
+	}
+	err = &StreamError{Err: io.EOF, Trailer: tr}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected the error to wrap io.EOF")
+	}
+}
@@ -0,0 +1,86 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// splitSubscription tracks the sync responses of the streams of a split subscription.
+type splitSubscription struct {
+	m      *sync.Mutex
+	parts  int
+	synced map[string]struct{}
+}
+
+func newSplitSubscription(parts int) *splitSubscription {
+	return &splitSubscription{
+		m:      new(sync.Mutex),
+		parts:  parts,
+		synced: make(map[string]struct{}, parts),
+	}
+}
+
+// sync records the sync response of stream streamName,
+// it returns true if all the streams are synced.
+func (s *splitSubscription) sync(streamName string) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.synced[streamName] = struct{}{}
+	return len(s.synced) == s.parts
+}
+
+// SplitMixedModes splits the stream subscribe request req mixing subscription modes,
+// e.g: ON_CHANGE and SAMPLE, into one request per mode, in the order of the modes first appearance.
+// The other request fields are copied to all the requests.
+// It returns req alone if it is not a stream subscription list or has a single mode.
+func SplitMixedModes(req *gnmi.SubscribeRequest) []*gnmi.SubscribeRequest {
+	sl := req.GetSubscribe()
+	if sl.GetMode() != gnmi.SubscriptionList_STREAM {
+		return []*gnmi.SubscribeRequest{req}
+	}
+	modes := make([]gnmi.SubscriptionMode, 0, 1)
+	seen := make(map[gnmi.SubscriptionMode]struct{})
+	for _, sub := range sl.GetSubscription() {
+		if _, ok := seen[sub.GetMode()]; !ok {
+			seen[sub.GetMode()] = struct{}{}
+			modes = append(modes, sub.GetMode())
+		}
+	}
+	if len(modes) < 2 {
+		return []*gnmi.SubscribeRequest{req}
+	}
+	reqs := make([]*gnmi.SubscribeRequest, 0, len(modes))
+	for _, mode := range modes {
+		part := proto.Clone(req).(*gnmi.SubscribeRequest)
+		subs := make([]*gnmi.Subscription, 0, len(sl.GetSubscription()))
+		for _, sub := range part.GetSubscribe().GetSubscription() {
+			if sub.GetMode() == mode {
+				subs = append(subs, sub)
+			}
+		}
+		part.GetSubscribe().Subscription = subs
+		reqs = append(reqs, part)
+	}
+	return reqs
+}
+
+// streamModeName returns the name of the subscription mode of the split request req,
+// e.g: on-change.
+func streamModeName(req *gnmi.SubscribeRequest) string {
+	mode := gnmi.SubscriptionMode_TARGET_DEFINED
+	if subs := req.GetSubscribe().GetSubscription(); len(subs) > 0 {
+		mode = subs[0].GetMode()
+	}
+	return strings.ToLower(strings.ReplaceAll(mode.String(), "_", "-"))
+}
@@ -0,0 +1,163 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+func streamRequest(modes ...gnmi.SubscriptionMode) *gnmi.SubscribeRequest {
+	sl := &gnmi.SubscriptionList{
+		Mode:     gnmi.SubscriptionList_STREAM,
+		Encoding: gnmi.Encoding_JSON_IETF,
+	}
+	for i, m := range modes {
+		sl.Subscription = append(sl.Subscription, &gnmi.Subscription{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "p", Key: map[string]string{"i": string(rune('a' + i))}}}},
+			Mode: m,
+		})
+	}
+	return &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Subscribe{Subscribe: sl}}
+}
+
+func TestSplitMixedModes(t *testing.T) {
+	for name, item := range map[string]struct {
+		req   *gnmi.SubscribeRequest
+		parts [][]string
+	}{
+		"single_mode": {
+			req:   streamRequest(gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_SAMPLE),
+			parts: [][]string{{"a", "b"}},
+		},
+		"mixed_modes": {
+			req:   streamRequest(gnmi.SubscriptionMode_ON_CHANGE, gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_ON_CHANGE),
+			parts: [][]string{{"a", "c"}, {"b"}},
+		},
+		"once": {
+			req: &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Subscribe{Subscribe: &gnmi.SubscriptionList{
+				Mode:         gnmi.SubscriptionList_ONCE,
+				Subscription: streamRequest(gnmi.SubscriptionMode_ON_CHANGE, gnmi.SubscriptionMode_SAMPLE).GetSubscribe().GetSubscription(),
+			}}},
+			parts: [][]string{{"a", "b"}},
+		},
+	} {
+		reqs := SplitMixedModes(item.req)
+		if len(reqs) != len(item.parts) {
+			t.Errorf("failed at item %q: expected %d requests, got %d", name, len(item.parts), len(reqs))
+			continue
+		}
+		for i, req := range reqs {
+			subs := req.GetSubscribe().GetSubscription()
+			if len(subs) != len(item.parts[i]) {
+				t.Errorf("failed at item %q: request %d: expected %d subscriptions, got %d", name, i, len(item.parts[i]), len(subs))
+				continue
+			}
+			for j, sub := range subs {
+				if k := sub.GetPath().GetElem()[0].GetKey()["i"]; k != item.parts[i][j] {
+					t.Errorf("failed at item %q: request %d: expected subscription %q, got %q", name, i, item.parts[i][j], k)
+				}
+				// ONCE and POLL lists are not split, their subscriptions modes are ignored
+				if req.GetSubscribe().GetMode() == gnmi.SubscriptionList_STREAM && sub.GetMode() != subs[0].GetMode() {
+					t.Errorf("failed at item %q: request %d mixes modes", name, i)
+				}
+			}
+			if req.GetSubscribe().GetEncoding() != item.req.GetSubscribe().GetEncoding() {
+				t.Errorf("failed at item %q: request %d: the encoding is not copied", name, i)
+			}
+		}
+	}
+}
+
+func TestSubscribeSplit(t *testing.T) {
+	srv := &scriptedServer{
+		rsps: []*gnmi.SubscribeResponse{
+			updateRsp(ifacePath),
+			{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}},
+		},
+		subList: make(chan *gnmi.SubscriptionList, 2),
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	insecure := true
+	tg := NewTarget(&types.TargetConfig{
+		Name:       "t1",
+		Address:    l.Addr().String(),
+		Insecure:   &insecure,
+		Timeout:    5 * time.Second,
+		BufferSize: 10,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx)
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+
+	reqs := SplitMixedModes(streamRequest(gnmi.SubscriptionMode_ON_CHANGE, gnmi.SubscriptionMode_SAMPLE))
+	go tg.SubscribeSplit(ctx, reqs, "sub1")
+
+	modes := make(map[gnmi.SubscriptionMode]struct{})
+	for i := 0; i < 2; i++ {
+		select {
+		case sl := <-srv.subList:
+			if len(sl.GetSubscription()) != 1 {
+				t.Fatalf("expected a single subscription per stream, got %v", sl.GetSubscription())
+			}
+			modes[sl.GetSubscription()[0].GetMode()] = struct{}{}
+		case <-ctx.Done():
+			t.Fatalf("subscribe request %d not received", i)
+		}
+	}
+	if len(modes) != 2 {
+		t.Errorf("expected one stream per mode, got %v", modes)
+	}
+	rspCh, _ := tg.ReadSubscriptions()
+	var updates, syncs int
+	for updates+syncs < 3 {
+		select {
+		case rsp := <-rspCh:
+			if rsp.SubscriptionName != "sub1" {
+				t.Errorf("unexpected subscription name %q", rsp.SubscriptionName)
+			}
+			if rsp.Response.GetSyncResponse() {
+				syncs++
+				if updates != 2 {
+					t.Errorf("sync response received before the updates of all the streams")
+				}
+				continue
+			}
+			updates++
+		case <-ctx.Done():
+			t.Fatalf("received %d updates and %d sync responses", updates, syncs)
+		}
+	}
+	select {
+	case rsp := <-rspCh:
+		t.Errorf("unexpected response: %v", rsp.Response)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if n := tg.NumberOfActiveSubscriptions(); n != 1 {
+		t.Errorf("expected 1 active subscription, got %d", n)
+	}
+}
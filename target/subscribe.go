@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jhump/protoreflect/dynamic"
@@ -21,12 +22,46 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+// ErrSubscribeRetry is sent to the target errors channel
+// when a subscription is about to be re-established.
+var ErrSubscribeRetry = errors.New("retrying")
+
 // Subscribe sends a gnmi.SubscribeRequest to the target *t, responses and error are sent to the target channels
 func (t *Target) Subscribe(ctx context.Context, req *gnmi.SubscribeRequest, subscriptionName string) {
+	t.subscribe(ctx, req, subscriptionName, subscriptionName, nil)
+}
+
+// SubscribeSplit sends the parts of the subscribe request of subscription subscriptionName,
+// as returned by SplitMixedModes, on one stream each.
+// The responses of all the streams are sent to the target channels with the subscription name,
+// the sync response is sent once all the streams are synced.
+func (t *Target) SubscribeSplit(ctx context.Context, reqs []*gnmi.SubscribeRequest, subscriptionName string) {
+	if len(reqs) == 1 {
+		t.Subscribe(ctx, reqs[0], subscriptionName)
+		return
+	}
+	split := newSplitSubscription(len(reqs))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(reqs))
+	for _, req := range reqs {
+		go func(req *gnmi.SubscribeRequest) {
+			defer wg.Done()
+			streamName := fmt.Sprintf("%s/%s", subscriptionName, streamModeName(req))
+			t.subscribe(ctx, req, streamName, subscriptionName, split)
+		}(req)
+	}
+	wg.Wait()
+}
+
+// subscribe sends req on the stream streamName of subscription subscriptionName,
+// split is set if the stream is a part of a split subscription.
+func (t *Target) subscribe(ctx context.Context, req *gnmi.SubscribeRequest, streamName, subscriptionName string, split *splitSubscription) {
 	var subscribeClient gnmi.GNMI_SubscribeClient
 	var nctx context.Context
 	var cancel context.CancelFunc
 	var err error
+	// set once the subscription fell back to another encoding
+	var encoding string
 SUBSC:
 	select {
 	case <-ctx.Done():
@@ -34,12 +69,7 @@ SUBSC:
 	default:
 		nctx, cancel = context.WithCancel(ctx)
 		defer cancel()
-		if t.Config.Username != nil && *t.Config.Username != "" {
-			nctx = metadata.AppendToOutgoingContext(nctx, "username", *t.Config.Username)
-		}
-		if t.Config.Password != nil && *t.Config.Password != "" {
-			nctx = metadata.AppendToOutgoingContext(nctx, "password", *t.Config.Password)
-		}
+		nctx = t.Config.AuthContext(nctx)
 		subscribeClient, err = t.Client.Subscribe(nctx)
 		if err != nil {
 			t.errors <- &TargetError{
@@ -52,13 +82,17 @@ SUBSC:
 		}
 	}
 	t.m.Lock()
-	if cfn, ok := t.subscribeCancelFn[subscriptionName]; ok {
+	if cfn, ok := t.subscribeCancelFn[streamName]; ok {
 		cfn()
 	}
-	t.SubscribeClients[subscriptionName] = subscribeClient
-	t.subscribeCancelFn[subscriptionName] = cancel
+	t.SubscribeClients[streamName] = subscribeClient
+	t.subscribeCancelFn[streamName] = cancel
+	t.streamSubs[streamName] = subscriptionName
 	subConfig := t.Subscriptions[subscriptionName]
 	t.m.Unlock()
+	backend := t.streamBackend(subscribeClient)
+	// fetched once the first response is received
+	var header metadata.MD
 	err = subscribeClient.Send(req)
 	if err != nil {
 		t.errors <- &TargetError{
@@ -77,48 +111,77 @@ SUBSC:
 				return
 			}
 			response, err := subscribeClient.Recv()
+			recvTS := time.Now().UnixNano()
 			if err != nil {
+				if next, ok := t.nextEncoding(req, subscriptionName, err); ok {
+					req, encoding = withSubscribeEncoding(req, next), EncodingName(next)
+					cancel()
+					goto SUBSC
+				}
 				t.errors <- &TargetError{
 					SubscriptionName: subscriptionName,
-					Err:              err,
+					Err:              streamError(subscribeClient, err),
 				}
 				t.errors <- &TargetError{
 					SubscriptionName: subscriptionName,
-					Err:              fmt.Errorf("retrying in %s", t.Config.RetryTimer),
+					Err:              fmt.Errorf("%w in %s", ErrSubscribeRetry, t.Config.RetryTimer),
 				}
 				cancel()
 				time.Sleep(t.Config.RetryTimer)
 				goto SUBSC
 			}
+			if header == nil {
+				header = streamHeader(subscribeClient)
+			}
+			if split != nil && response.GetSyncResponse() && !split.sync(streamName) {
+				continue
+			}
 			t.subscribeResponses <- &SubscribeResponse{
 				SubscriptionName:   subscriptionName,
 				SubscriptionConfig: subConfig,
 				Response:           response,
+				RecvTimestamp:      recvTS,
+				Backend:            backend,
+				Header:             header,
+				Encoding:           encoding,
 			}
 		}
 	case gnmi.SubscriptionList_ONCE:
 		for {
 			response, err := subscribeClient.Recv()
+			recvTS := time.Now().UnixNano()
 			if err != nil {
+				if next, ok := t.nextEncoding(req, subscriptionName, err); ok {
+					req, encoding = withSubscribeEncoding(req, next), EncodingName(next)
+					cancel()
+					goto SUBSC
+				}
 				t.errors <- &TargetError{
 					SubscriptionName: subscriptionName,
-					Err:              err,
+					Err:              streamError(subscribeClient, err),
 				}
 				if errors.Is(err, io.EOF) {
 					return
 				}
 				t.errors <- &TargetError{
 					SubscriptionName: subscriptionName,
-					Err:              fmt.Errorf("retrying in %d", t.Config.RetryTimer),
+					Err:              fmt.Errorf("%w in %s", ErrSubscribeRetry, t.Config.RetryTimer),
 				}
 				cancel()
 				time.Sleep(t.Config.RetryTimer)
 				goto SUBSC
 			}
+			if header == nil {
+				header = streamHeader(subscribeClient)
+			}
 			t.subscribeResponses <- &SubscribeResponse{
 				SubscriptionName:   subscriptionName,
 				SubscriptionConfig: subConfig,
 				Response:           response,
+				RecvTimestamp:      recvTS,
+				Backend:            backend,
+				Header:             header,
+				Encoding:           encoding,
 			}
 			switch response.Response.(type) {
 			case *gnmi.SubscribeResponse_SyncResponse:
@@ -142,6 +205,7 @@ SUBSC:
 					continue
 				}
 				response, err := subscribeClient.Recv()
+				recvTS := time.Now().UnixNano()
 				if err != nil {
 					t.errors <- &TargetError{
 						SubscriptionName: subscriptionName,
@@ -149,10 +213,17 @@ SUBSC:
 					}
 					continue
 				}
+				if header == nil {
+					header = streamHeader(subscribeClient)
+				}
 				t.subscribeResponses <- &SubscribeResponse{
 					SubscriptionName:   subscriptionName,
 					SubscriptionConfig: subConfig,
 					Response:           response,
+					RecvTimestamp:      recvTS,
+					Backend:            backend,
+					Header:             header,
+					Encoding:           encoding,
 				}
 			case <-nctx.Done():
 				return
@@ -168,7 +239,7 @@ func (t *Target) SubscribeOnceChan(ctx context.Context, req *gnmi.SubscribeReque
 		nctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
-		nctx = metadata.AppendToOutgoingContext(nctx, "username", *t.Config.Username, "password", *t.Config.Password)
+		nctx = t.Config.AuthContext(nctx)
 		subscribeClient, err := t.Client.Subscribe(nctx)
 		if err != nil {
 			errCh <- err
@@ -229,6 +300,44 @@ func (t *Target) NumberOfOnceSubscriptions() int {
 	return num
 }
 
+// NumberOfActiveSubscriptions returns the number of subscriptions
+// with a subscribe client created, a split subscription is counted once.
+func (t *Target) NumberOfActiveSubscriptions() int {
+	t.m.Lock()
+	defer t.m.Unlock()
+	names := make(map[string]struct{}, len(t.SubscribeClients))
+	for streamName := range t.SubscribeClients {
+		names[t.streamSubs[streamName]] = struct{}{}
+	}
+	return len(names)
+}
+
+// subscriptionStreams returns the names of the streams of subscription name,
+// a split subscription has one stream per subscription mode.
+// It must be called with t.m locked.
+func (t *Target) subscriptionStreams(name string) []string {
+	streams := make([]string, 0, 1)
+	for streamName, subName := range t.streamSubs {
+		if subName == name {
+			streams = append(streams, streamName)
+		}
+	}
+	return streams
+}
+
+// stopSubscriptionStreams cancels and removes the streams of subscription name.
+// It must be called with t.m locked.
+func (t *Target) stopSubscriptionStreams(name string) {
+	for _, streamName := range t.subscriptionStreams(name) {
+		if cfn, ok := t.subscribeCancelFn[streamName]; ok {
+			cfn()
+		}
+		delete(t.subscribeCancelFn, streamName)
+		delete(t.SubscribeClients, streamName)
+		delete(t.streamSubs, streamName)
+	}
+}
+
 func (t *Target) DecodeProtoBytes(resp *gnmi.SubscribeResponse) error {
 	if t.RootDesc == nil {
 		return nil
@@ -257,16 +366,22 @@ func (t *Target) DecodeProtoBytes(resp *gnmi.SubscribeResponse) error {
 func (t *Target) DeleteSubscription(name string) {
 	t.m.Lock()
 	defer t.m.Unlock()
-	t.subscribeCancelFn[name]()
-	delete(t.subscribeCancelFn, name)
-	delete(t.SubscribeClients, name)
+	t.stopSubscriptionStreams(name)
 	delete(t.Subscriptions, name)
 }
 
+// ResetSubscriptions closes the streams of the target subscriptions,
+// each subscription is re-established after the target retry timer.
+func (t *Target) ResetSubscriptions() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	for _, cfn := range t.subscribeCancelFn {
+		cfn()
+	}
+}
+
 func (t *Target) StopSubscription(name string) {
 	t.m.Lock()
 	defer t.m.Unlock()
-	t.subscribeCancelFn[name]()
-	delete(t.subscribeCancelFn, name)
-	delete(t.SubscribeClients, name)
+	t.stopSubscriptionStreams(name)
 }
@@ -0,0 +1,157 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+)
+
+// scriptedServer is a gNMI server replying to a Subscribe request
+// with a fixed list of responses.
+type scriptedServer struct {
+	gnmi.UnimplementedGNMIServer
+	rsps []*gnmi.SubscribeResponse
+	// received subscription list
+	subList chan *gnmi.SubscriptionList
+}
+
+func (s *scriptedServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.subList <- req.GetSubscribe()
+	for _, rsp := range s.rsps {
+		err = stream.Send(rsp)
+		if err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func updateRsp(prefix *gnmi.Path) *gnmi.SubscribeResponse {
+	return &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{Prefix: prefix},
+		},
+	}
+}
+
+var ifacePath = &gnmi.Path{
+	Elem: []*gnmi.PathElem{
+		{Name: "interfaces"},
+		{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+	},
+}
+
+func TestSubscribeRecvTimestamp(t *testing.T) {
+	skewed := time.Now().Add(-time.Hour).UnixNano()
+	rsp := updateRsp(ifacePath)
+	rsp.GetUpdate().Timestamp = skewed
+	srv := &scriptedServer{
+		rsps:    []*gnmi.SubscribeResponse{rsp},
+		subList: make(chan *gnmi.SubscriptionList, 1),
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	insecure := true
+	tg := NewTarget(&types.TargetConfig{
+		Name:       "t1",
+		Address:    l.Addr().String(),
+		Insecure:   &insecure,
+		Timeout:    5 * time.Second,
+		BufferSize: 10,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx)
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+
+	start := time.Now().UnixNano()
+	go tg.Subscribe(ctx, &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Mode: gnmi.SubscriptionList_STREAM,
+			},
+		},
+	}, "sub1")
+
+	rspCh, _ := tg.ReadSubscriptions()
+	select {
+	case r := <-rspCh:
+		if r.RecvTimestamp < start || r.RecvTimestamp > time.Now().UnixNano() {
+			t.Errorf("unexpected receive timestamp %d", r.RecvTimestamp)
+		}
+		if r.Response.GetUpdate().GetTimestamp() != skewed {
+			t.Errorf("the target timestamp should not be modified")
+		}
+		if skew := ClockSkew(r.Response, r.RecvTimestamp); skew < time.Hour {
+			t.Errorf("expected a clock skew of at least 1h, got %s", skew)
+		}
+	case <-ctx.Done():
+		t.Fatal("response not received")
+	}
+}
+
+var clockSkewTestSet = map[string]struct {
+	rsp    *gnmi.SubscribeResponse
+	recvTS int64
+	out    time.Duration
+}{
+	"target_behind": {
+		rsp:    &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{Timestamp: 1000}}},
+		recvTS: 3000,
+		out:    2000,
+	},
+	"target_ahead": {
+		rsp:    &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{Timestamp: 5000}}},
+		recvTS: 3000,
+		out:    2000,
+	},
+	"no_timestamp": {
+		rsp:    &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}}},
+		recvTS: 3000,
+		out:    0,
+	},
+	"sync_response": {
+		rsp:    &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}},
+		recvTS: 3000,
+		out:    0,
+	},
+}
+
+func TestClockSkew(t *testing.T) {
+	for name, item := range clockSkewTestSet {
+		t.Run(name, func(t *testing.T) {
+			if skew := ClockSkew(item.rsp, item.recvTS); skew != item.out {
+				t.Logf("failed at item %q", name)
+				t.Errorf("expected %s, got %s", item.out, skew)
+			}
+		})
+	}
+}
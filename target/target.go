@@ -10,10 +10,12 @@ package target
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -21,7 +23,9 @@ import (
 	"github.com/openconfig/gnmic/types"
 	"golang.org/x/net/proxy"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 type TargetError struct {
@@ -34,6 +38,29 @@ type SubscribeResponse struct {
 	SubscriptionName   string
 	SubscriptionConfig *types.SubscriptionConfig
 	Response           *gnmi.SubscribeResponse
+	// local time the response was received at, in nanoseconds since Unix epoch
+	RecvTimestamp int64
+	// address of the backend serving the stream, set if the target address is balanced
+	Backend string
+	// header received on the stream establishment, without the gRPC reserved keys
+	Header metadata.MD
+	// encoding the subscription was sent with, set if it fell back from the configured one
+	Encoding string
+}
+
+// ClockSkew returns the absolute difference between the timestamp of
+// the notification in rsp and the local receive timestamp recvTS.
+// It returns 0 if rsp is not a notification or has no timestamp.
+func ClockSkew(rsp *gnmi.SubscribeResponse, recvTS int64) time.Duration {
+	ts := rsp.GetUpdate().GetTimestamp()
+	if ts == 0 || recvTS == 0 {
+		return 0
+	}
+	d := time.Duration(recvTS - ts)
+	if d < 0 {
+		return -d
+	}
+	return d
 }
 
 // Target represents a gNMI enabled box
@@ -44,9 +71,10 @@ type Target struct {
 	m                  *sync.Mutex
 	conn               *grpc.ClientConn
 	Client             gnmi.GNMIClient                      `json:"-"`
-	SubscribeClients   map[string]gnmi.GNMI_SubscribeClient `json:"-"` // subscription name to subscribeClient
+	SubscribeClients   map[string]gnmi.GNMI_SubscribeClient `json:"-"` // subscription (or split stream) name to subscribeClient
 	subscribeCancelFn  map[string]context.CancelFunc
-	pollChan           chan string // subscription name to be polled
+	streamSubs         map[string]string // stream name to subscription name
+	pollChan           chan string       // subscription name to be polled
 	subscribeResponses chan *SubscribeResponse
 	errors             chan *TargetError
 	stopped            bool
@@ -63,6 +91,7 @@ func NewTarget(c *types.TargetConfig) *Target {
 		m:                  new(sync.Mutex),
 		SubscribeClients:   make(map[string]gnmi.GNMI_SubscribeClient),
 		subscribeCancelFn:  make(map[string]context.CancelFunc),
+		streamSubs:         make(map[string]string),
 		pollChan:           make(chan string),
 		subscribeResponses: make(chan *SubscribeResponse, c.BufferSize),
 		errors:             make(chan *TargetError, c.BufferSize),
@@ -72,6 +101,19 @@ func NewTarget(c *types.TargetConfig) *Target {
 }
 
 // CreateGNMIClient //
+// streamBackend returns the address of the backend serving stream
+// if the target address is balanced across multiple backends.
+func (t *Target) streamBackend(stream grpc.ClientStream) string {
+	if !t.Config.Balanced() {
+		return ""
+	}
+	p, ok := peer.FromContext(stream.Context())
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
 func (t *Target) CreateGNMIClient(ctx context.Context, opts ...grpc.DialOption) error {
 	tOpts, err := t.Config.GrpcDialOptions()
 	if err != nil {
@@ -146,35 +188,20 @@ func (t *Target) CreateGNMIClient(ctx context.Context, opts ...grpc.DialOption)
 
 // Capabilities sends a gnmi.CapabilitiesRequest to the target *t and returns a gnmi.CapabilitiesResponse and an error
 func (t *Target) Capabilities(ctx context.Context, ext ...*gnmi_ext.Extension) (*gnmi.CapabilityResponse, error) {
-	if t.Config.Username != nil && *t.Config.Username != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "username", *t.Config.Username)
-	}
-	if t.Config.Password != nil && *t.Config.Password != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "password", *t.Config.Password)
-	}
-	return t.Client.Capabilities(ctx, &gnmi.CapabilityRequest{Extension: ext})
+	ctx = t.Config.AuthContext(ctx)
+	return t.Client.Capabilities(ctx, &gnmi.CapabilityRequest{Extension: ext}, callOptions(ctx)...)
 }
 
 // Get sends a gnmi.GetRequest to the target *t and returns a gnmi.GetResponse and an error
 func (t *Target) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
-	if t.Config.Username != nil && *t.Config.Username != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "username", *t.Config.Username)
-	}
-	if t.Config.Password != nil && *t.Config.Password != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "password", *t.Config.Password)
-	}
-	return t.Client.Get(ctx, req)
+	ctx = t.Config.AuthContext(ctx)
+	return t.Client.Get(ctx, req, callOptions(ctx)...)
 }
 
 // Set sends a gnmi.SetRequest to the target *t and returns a gnmi.SetResponse and an error
 func (t *Target) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
-	if t.Config.Username != nil && *t.Config.Username != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "username", *t.Config.Username)
-	}
-	if t.Config.Password != nil && *t.Config.Password != "" {
-		ctx = metadata.AppendToOutgoingContext(ctx, "password", *t.Config.Password)
-	}
-	return t.Client.Set(ctx, req)
+	ctx = t.Config.AuthContext(ctx)
+	return t.Client.Set(ctx, req, callOptions(ctx)...)
 }
 
 func (t *Target) StopSubscriptions() {
@@ -206,3 +233,33 @@ func (t *Target) ConnState() string {
 	}
 	return t.conn.GetState().String()
 }
+
+// WatchConnState calls fn on every connectivity state transition
+// of the target gRPC connection, until ctx is done or the connection is closed.
+func (t *Target) WatchConnState(ctx context.Context, fn func(from, to connectivity.State)) {
+	conn := t.conn
+	if conn == nil {
+		return
+	}
+	s := conn.GetState()
+	for conn.WaitForStateChange(ctx, s) {
+		ns := conn.GetState()
+		fn(s, ns)
+		if ns == connectivity.Shutdown {
+			return
+		}
+		s = ns
+	}
+}
+
+// MarshalJSON adds the connection state to the target JSON encoding.
+func (t *Target) MarshalJSON() ([]byte, error) {
+	type target Target
+	return json.Marshal(&struct {
+		*target
+		ConnState string `json:"conn-state,omitempty"`
+	}{
+		target:    (*target)(t),
+		ConnState: t.ConnState(),
+	})
+}
@@ -0,0 +1,249 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWatchConnState(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, &gnmi.UnimplementedGNMIServer{})
+	go gs.Serve(l)
+
+	insecure := true
+	tg := NewTarget(&types.TargetConfig{
+		Name:     "t1",
+		Address:  l.Addr().String(),
+		Insecure: &insecure,
+		Timeout:  5 * time.Second,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx)
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+
+	b, err := json.Marshal(tg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]interface{})
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["conn-state"] != connectivity.Ready.String() {
+		t.Errorf("expected conn-state %q, got %v", connectivity.Ready, m["conn-state"])
+	}
+
+	transitions := make(chan connectivity.State, 10)
+	go tg.WatchConnState(ctx, func(from, to connectivity.State) {
+		transitions <- to
+	})
+	// give the watcher time to start before breaking the connection
+	time.Sleep(100 * time.Millisecond)
+	gs.Stop()
+	select {
+	case s := <-transitions:
+		if s == connectivity.Ready {
+			t.Errorf("unexpected transition to %s", s)
+		}
+	case <-ctx.Done():
+		t.Fatal("no connection state transition reported")
+	}
+}
+
+// mdServer is a gNMI server recording the metadata of the received RPCs.
+type mdServer struct {
+	gnmi.UnimplementedGNMIServer
+	mds chan metadata.MD
+}
+
+func (s *mdServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mds <- md
+	return &gnmi.GetResponse{}, nil
+}
+
+func (s *mdServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	s.mds <- md
+	_, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	return stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+func TestClientIdentification(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &mdServer{mds: make(chan metadata.MD, 2)}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	insecure := true
+	username := ""
+	password := ""
+	tg := NewTarget(&types.TargetConfig{
+		Name:      "t1",
+		Address:   l.Addr().String(),
+		Insecure:  &insecure,
+		Username:  &username,
+		Password:  &password,
+		Timeout:   5 * time.Second,
+		UserAgent: "collector/1.0",
+		ClientID:  "collector-1",
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tg.CreateGNMIClient(ctx, grpc.WithUserAgent("gnmic/dev"))
+	if err != nil {
+		t.Fatalf("failed to create gNMI client: %v", err)
+	}
+	defer tg.Close()
+
+	_, err = tg.Get(ctx, &gnmi.GetRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tg.SubscribeOnce(ctx, &gnmi.SubscribeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rpc := range []string{"Get", "Subscribe"} {
+		md := <-srv.mds
+		if ua := md.Get("user-agent"); len(ua) != 1 || !strings.HasPrefix(ua[0], "collector/1.0") {
+			t.Errorf("%s: expected the target user-agent, got %v", rpc, ua)
+		}
+		if id := md.Get(types.ClientIDMetadataKey); len(id) != 1 || id[0] != "collector-1" {
+			t.Errorf("%s: expected client-id %q, got %v", rpc, "collector-1", id)
+		}
+	}
+}
+
+func TestAuthScheme(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &mdServer{mds: make(chan metadata.MD, 2)}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	type item struct {
+		scheme      string
+		usernameKey string
+		passwordKey string
+		// expected metadata, an empty value means the key is absent
+		md map[string]string
+	}
+	var tests = map[string]item{
+		"default": {
+			md: map[string]string{"username": "admin", "password": "secret"},
+		},
+		"metadata": {
+			scheme: types.AuthSchemeMetadata,
+			md:     map[string]string{"username": "admin", "password": "secret"},
+		},
+		"metadata_custom_keys": {
+			scheme:      types.AuthSchemeMetadata,
+			usernameKey: "x-user",
+			passwordKey: "X-Pass",
+			md:          map[string]string{"x-user": "admin", "x-pass": "secret", "username": "", "password": ""},
+		},
+		"metadata_per_rpc": {
+			scheme: types.AuthSchemeMetadataPerRPC,
+			md:     map[string]string{"username": "admin", "password": "secret"},
+		},
+		"metadata_per_rpc_custom_keys": {
+			scheme:      types.AuthSchemeMetadataPerRPC,
+			usernameKey: "x-user",
+			passwordKey: "x-pass",
+			md:          map[string]string{"x-user": "admin", "x-pass": "secret", "username": "", "password": ""},
+		},
+		"none": {
+			scheme: types.AuthSchemeNone,
+			md:     map[string]string{"username": "", "password": ""},
+		},
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			insecure := true
+			username := "admin"
+			password := "secret"
+			tg := NewTarget(&types.TargetConfig{
+				Name:            "t1",
+				Address:         l.Addr().String(),
+				Insecure:        &insecure,
+				Username:        &username,
+				Password:        &password,
+				Timeout:         5 * time.Second,
+				AuthScheme:      item.scheme,
+				AuthUsernameKey: item.usernameKey,
+				AuthPasswordKey: item.passwordKey,
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			err := tg.CreateGNMIClient(ctx)
+			if err != nil {
+				t.Fatalf("failed to create gNMI client: %v", err)
+			}
+			defer tg.Close()
+
+			_, err = tg.Get(ctx, &gnmi.GetRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tg.SubscribeOnce(ctx, &gnmi.SubscribeRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rpc := range []string{"Get", "Subscribe"} {
+				md := <-srv.mds
+				for k, v := range item.md {
+					got := md.Get(k)
+					if v == "" {
+						if len(got) != 0 {
+							t.Errorf("failed at item %q: %s: expected no %q metadata, got %v", name, rpc, k, got)
+						}
+						continue
+					}
+					if len(got) != 1 || got[0] != v {
+						t.Errorf("failed at item %q: %s: expected %q metadata %q, got %v", name, rpc, k, v, got)
+					}
+				}
+			}
+		})
+	}
+}
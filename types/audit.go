@@ -0,0 +1,45 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// SetAuditFunc records a Set RPC sent to target, started at start,
+// with its response or error.
+type SetAuditFunc func(target string, req *gnmi.SetRequest, rsp *gnmi.SetResponse, err error, start time.Time)
+
+var (
+	setAuditMu sync.RWMutex
+	setAudit   SetAuditFunc
+)
+
+// RegisterSetAudit sets the function recording the Set RPCs sent
+// outside of the gnmic commands, by the gnmi actions or the gnmi output.
+// A nil fn unregisters it.
+func RegisterSetAudit(fn SetAuditFunc) {
+	setAuditMu.Lock()
+	defer setAuditMu.Unlock()
+	setAudit = fn
+}
+
+// AuditSet records a Set RPC with the registered SetAuditFunc, if any.
+func AuditSet(target string, req *gnmi.SetRequest, rsp *gnmi.SetResponse, err error, start time.Time) {
+	setAuditMu.RLock()
+	fn := setAudit
+	setAuditMu.RUnlock()
+	if fn == nil {
+		return
+	}
+	fn(target, req, rsp, err, start)
+}
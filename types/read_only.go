@@ -0,0 +1,14 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "errors"
+
+// ErrReadOnly is returned when a Set RPC is attempted with the read-only mode enabled.
+var ErrReadOnly = errors.New("read-only mode is enabled, Set RPCs are refused: remove the --read-only flag and the read-only config key to allow them")
@@ -36,6 +36,42 @@ type SubscriptionConfig struct {
 	SuppressRedundant bool           `mapstructure:"suppress-redundant,omitempty" json:"suppress-redundant,omitempty"`
 	UpdatesOnly       bool           `mapstructure:"updates-only,omitempty" json:"updates-only,omitempty"`
 	History           *HistoryConfig `mapstructure:"history,omitempty" json:"history,omitempty"`
+	// per path stream options, PathConfigs[i] applies to Paths[i] if not nil.
+	// set from the paths entries defined as an object instead of an xpath.
+	PathConfigs []*PathConfig `mapstructure:"-" json:"path-configs,omitempty"`
+}
+
+// PathConfig is a subscription path with its own stream options,
+// the options not set are inherited from the subscription.
+type PathConfig struct {
+	Path              string         `mapstructure:"path,omitempty" json:"path,omitempty"`
+	Mode              string         `mapstructure:"mode,omitempty" json:"mode,omitempty"`
+	SampleInterval    *time.Duration `mapstructure:"sample-interval,omitempty" json:"sample-interval,omitempty"`
+	HeartbeatInterval *time.Duration `mapstructure:"heartbeat-interval,omitempty" json:"heartbeat-interval,omitempty"`
+	SuppressRedundant *bool          `mapstructure:"suppress-redundant,omitempty" json:"suppress-redundant,omitempty"`
+}
+
+// PathStreamOptions returns the stream mode, sample interval, heartbeat interval and suppress redundant
+// of the subscription path at index i: the path options if set, the subscription ones otherwise.
+func (sc *SubscriptionConfig) PathStreamOptions(i int) (string, *time.Duration, *time.Duration, bool) {
+	mode, sample, heartbeat, suppress := sc.StreamMode, sc.SampleInterval, sc.HeartbeatInterval, sc.SuppressRedundant
+	if i >= len(sc.PathConfigs) || sc.PathConfigs[i] == nil {
+		return mode, sample, heartbeat, suppress
+	}
+	pc := sc.PathConfigs[i]
+	if pc.Mode != "" {
+		mode = pc.Mode
+	}
+	if pc.SampleInterval != nil {
+		sample = pc.SampleInterval
+	}
+	if pc.HeartbeatInterval != nil {
+		heartbeat = pc.HeartbeatInterval
+	}
+	if pc.SuppressRedundant != nil {
+		suppress = *pc.SuppressRedundant
+	}
+	return mode, sample, heartbeat, suppress
 }
 
 type HistoryConfig struct {
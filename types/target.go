@@ -9,6 +9,7 @@
 package types
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -23,35 +24,90 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/credentials/oauth"
 	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+)
+
+// DNSAddressPrefix is the prefix of a target address resolved by gRPC to all its DNS records,
+// the RPCs are balanced across the resolved backends, e.g: dns:///gateway.example.net:57400
+const DNSAddressPrefix = "dns:///"
+
+// ClientIDMetadataKey is the metadata key the target client-id is sent with on every RPC.
+const ClientIDMetadataKey = "client-id"
+
+// target username and password auth schemes
+const (
+	// the credentials are added as metadata to each RPC by the client
+	AuthSchemeMetadata = "metadata"
+	// the credentials are sent as gRPC call credentials, requested by gRPC on every RPC
+	AuthSchemeMetadataPerRPC = "metadata-per-rpc"
+	// the credentials are not sent
+	AuthSchemeNone = "none"
+)
+
+// AuthSchemes lists the supported auth schemes.
+var AuthSchemes = []string{AuthSchemeMetadata, AuthSchemeMetadataPerRPC, AuthSchemeNone}
+
+// default metadata keys the username and password are sent with.
+const (
+	DefaultAuthUsernameKey = "username"
+	DefaultAuthPasswordKey = "password"
+)
+
+// connection security modes
+const (
+	SecurityModePlaintext     = "plaintext"
+	SecurityModeTLSVerified   = "tls-verified"
+	SecurityModeTLSSkipVerify = "tls-skip-verify"
+	SecurityModeMTLS          = "mtls"
 )
 
 // TargetConfig //
 type TargetConfig struct {
-	Name          string            `mapstructure:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
-	Address       string            `mapstructure:"address,omitempty" json:"address,omitempty" yaml:"address,omitempty"`
-	Username      *string           `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
-	Password      *string           `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
-	Timeout       time.Duration     `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
-	Insecure      *bool             `mapstructure:"insecure,omitempty" json:"insecure,omitempty" yaml:"insecure,omitempty"`
-	TLSCA         *string           `mapstructure:"tls-ca,omitempty" json:"tls-ca,omitempty" yaml:"tlsca,omitempty"`
-	TLSCert       *string           `mapstructure:"tls-cert,omitempty" json:"tls-cert,omitempty" yaml:"tls-cert,omitempty"`
-	TLSKey        *string           `mapstructure:"tls-key,omitempty" json:"tls-key,omitempty" yaml:"tls-key,omitempty"`
-	SkipVerify    *bool             `mapstructure:"skip-verify,omitempty" json:"skip-verify,omitempty" yaml:"skip-verify,omitempty"`
-	Subscriptions []string          `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
-	Outputs       []string          `mapstructure:"outputs,omitempty" json:"outputs,omitempty" yaml:"outputs,omitempty"`
-	BufferSize    uint              `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty" yaml:"buffer-size,omitempty"`
-	RetryTimer    time.Duration     `mapstructure:"retry,omitempty" json:"retry-timer,omitempty" yaml:"retry-timer,omitempty"`
-	TLSMinVersion string            `mapstructure:"tls-min-version,omitempty" json:"tls-min-version,omitempty" yaml:"tls-min-version,omitempty"`
-	TLSMaxVersion string            `mapstructure:"tls-max-version,omitempty" json:"tls-max-version,omitempty" yaml:"tls-max-version,omitempty"`
-	TLSVersion    string            `mapstructure:"tls-version,omitempty" json:"tls-version,omitempty" yaml:"tls-version,omitempty"`
-	LogTLSSecret  *bool             `mapstructure:"log-tls-secret,omitempty" json:"log-tls-secret,omitempty" yaml:"log-tls-secret,omitempty"`
-	ProtoFiles    []string          `mapstructure:"proto-files,omitempty" json:"proto-files,omitempty" yaml:"proto-files,omitempty"`
-	ProtoDirs     []string          `mapstructure:"proto-dirs,omitempty" json:"proto-dirs,omitempty" yaml:"proto-dirs,omitempty"`
-	Tags          []string          `mapstructure:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
-	EventTags     map[string]string `mapstructure:"event-tags,omitempty" json:"event-tags,omitempty" yaml:"event-tags,omitempty"`
-	Gzip          *bool             `mapstructure:"gzip,omitempty" json:"gzip,omitempty" yaml:"gzip,omitempty"`
-	Token         *string           `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
-	Proxy         string            `mapstructure:"proxy,omitempty" json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	Name          string                 `mapstructure:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Address       string                 `mapstructure:"address,omitempty" json:"address,omitempty" yaml:"address,omitempty"`
+	Username      *string                `mapstructure:"username,omitempty" json:"username,omitempty" yaml:"username,omitempty"`
+	Password      *string                `mapstructure:"password,omitempty" json:"password,omitempty" yaml:"password,omitempty"`
+	Timeout       time.Duration          `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Encoding      string                 `mapstructure:"encoding,omitempty" json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Insecure      *bool                  `mapstructure:"insecure,omitempty" json:"insecure,omitempty" yaml:"insecure,omitempty"`
+	TLSCA         *string                `mapstructure:"tls-ca,omitempty" json:"tls-ca,omitempty" yaml:"tlsca,omitempty"`
+	TLSCert       *string                `mapstructure:"tls-cert,omitempty" json:"tls-cert,omitempty" yaml:"tls-cert,omitempty"`
+	TLSKey        *string                `mapstructure:"tls-key,omitempty" json:"tls-key,omitempty" yaml:"tls-key,omitempty"`
+	SkipVerify    *bool                  `mapstructure:"skip-verify,omitempty" json:"skip-verify,omitempty" yaml:"skip-verify,omitempty"`
+	Subscriptions []string               `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+	Outputs       []string               `mapstructure:"outputs,omitempty" json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	BufferSize    uint                   `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty" yaml:"buffer-size,omitempty"`
+	RetryTimer    time.Duration          `mapstructure:"retry,omitempty" json:"retry-timer,omitempty" yaml:"retry-timer,omitempty"`
+	TLSMinVersion string                 `mapstructure:"tls-min-version,omitempty" json:"tls-min-version,omitempty" yaml:"tls-min-version,omitempty"`
+	TLSMaxVersion string                 `mapstructure:"tls-max-version,omitempty" json:"tls-max-version,omitempty" yaml:"tls-max-version,omitempty"`
+	TLSVersion    string                 `mapstructure:"tls-version,omitempty" json:"tls-version,omitempty" yaml:"tls-version,omitempty"`
+	TLSPin        []string               `mapstructure:"tls-pin,omitempty" json:"tls-pin,omitempty" yaml:"tls-pin,omitempty"`
+	LogTLSSecret  *bool                  `mapstructure:"log-tls-secret,omitempty" json:"log-tls-secret,omitempty" yaml:"log-tls-secret,omitempty"`
+	ProtoFiles    []string               `mapstructure:"proto-files,omitempty" json:"proto-files,omitempty" yaml:"proto-files,omitempty"`
+	ProtoDirs     []string               `mapstructure:"proto-dirs,omitempty" json:"proto-dirs,omitempty" yaml:"proto-dirs,omitempty"`
+	Tags          []string               `mapstructure:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
+	Groups        []string               `mapstructure:"groups,omitempty" json:"groups,omitempty" yaml:"groups,omitempty"`
+	Aliases       []string               `mapstructure:"-" json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	EventTags     map[string]string      `mapstructure:"event-tags,omitempty" json:"event-tags,omitempty" yaml:"event-tags,omitempty"`
+	Vars          map[string]interface{} `mapstructure:"vars,omitempty" json:"vars,omitempty" yaml:"vars,omitempty"`
+	Gzip          *bool                  `mapstructure:"gzip,omitempty" json:"gzip,omitempty" yaml:"gzip,omitempty"`
+	Token         *string                `mapstructure:"token,omitempty" json:"token,omitempty" yaml:"token,omitempty"`
+	Proxy         string                 `mapstructure:"proxy,omitempty" json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	UserAgent     string                 `mapstructure:"user-agent,omitempty" json:"user-agent,omitempty" yaml:"user-agent,omitempty"`
+	ClientID      string                 `mapstructure:"client-id,omitempty" json:"client-id,omitempty" yaml:"client-id,omitempty"`
+	// how the username and password are sent: metadata, metadata-per-rpc or none
+	AuthScheme string `mapstructure:"auth-scheme,omitempty" json:"auth-scheme,omitempty" yaml:"auth-scheme,omitempty"`
+	// metadata keys the username and password are sent with
+	AuthUsernameKey string `mapstructure:"auth-username-key,omitempty" json:"auth-username-key,omitempty" yaml:"auth-username-key,omitempty"`
+	AuthPasswordKey string `mapstructure:"auth-password-key,omitempty" json:"auth-password-key,omitempty" yaml:"auth-password-key,omitempty"`
+	// stream subscriptions mixing modes are sent as one request per mode
+	SplitMixedSubscriptions *bool `mapstructure:"split-mixed-subscriptions,omitempty" json:"split-mixed-subscriptions,omitempty" yaml:"split-mixed-subscriptions,omitempty"`
+	// certificate and CA loading failures are ignored instead of failing the connection
+	TLSIgnoreCertErrors bool `mapstructure:"tls-ignore-cert-errors,omitempty" json:"tls-ignore-cert-errors,omitempty" yaml:"tls-ignore-cert-errors,omitempty"`
+	// encodings the Get and Subscribe requests rejected for their encoding are sent again with, in order
+	EncodingFallback []string `mapstructure:"encoding-fallback,omitempty" json:"encoding-fallback,omitempty" yaml:"encoding-fallback,omitempty"`
+	// a disabled target is kept in the config but skipped by all the commands
+	Enabled *bool `mapstructure:"enabled,omitempty" json:"enabled,omitempty" yaml:"enabled,omitempty"`
 	//
 	TunnelTargetType string `mapstructure:"-" json:"tunnel-target-type,omitempty" yaml:"tunnel-target-type,omitempty"`
 }
@@ -70,6 +126,11 @@ func (tc TargetConfig) String() string {
 	return string(b)
 }
 
+// IsEnabled returns false if the target is disabled with `enabled: false`.
+func (tc *TargetConfig) IsEnabled() bool {
+	return tc.Enabled == nil || *tc.Enabled
+}
+
 // NewTLSConfig //
 func (tc *TargetConfig) NewTLSConfig() (*tls.Config, error) {
 	var ca, cert, key string
@@ -82,12 +143,29 @@ func (tc *TargetConfig) NewTLSConfig() (*tls.Config, error) {
 	if tc.TLSKey != nil {
 		key = *tc.TLSKey
 	}
-	tlsConfig, err := utils.NewTLSConfig(ca, cert, key, *tc.SkipVerify, false)
+	var tlsConfig *tls.Config
+	var err error
+	if tc.TLSIgnoreCertErrors {
+		tlsConfig, err = utils.NewTLSConfigIgnoreErrors(ca, cert, key, *tc.SkipVerify, false, func(err error) {
+			fmt.Fprintf(os.Stderr, "warning: target %q: %v, ignored with tls-ignore-cert-errors\n", tc.Name, err)
+		})
+	} else {
+		tlsConfig, err = utils.NewTLSConfig(ca, cert, key, *tc.SkipVerify, false)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if tlsConfig == nil {
-		return nil, nil
+		if len(tc.TLSPin) == 0 {
+			return nil, nil
+		}
+		tlsConfig = new(tls.Config)
+	}
+	if len(tc.TLSPin) > 0 {
+		err = utils.PinCertificate(tlsConfig, tc.TLSPin)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %v", tc.Name, err)
+		}
 	}
 	if tc.LogTLSSecret != nil && *tc.LogTLSSecret {
 		logPath := tc.Name + ".tlssecret.log"
@@ -110,6 +188,21 @@ func (tc *TargetConfig) GrpcDialOptions() ([]grpc.DialOption, error) {
 	if tc.Gzip != nil && *tc.Gzip {
 		tOpts = append(tOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
 	}
+	// user-agent, overrides the default one
+	if tc.UserAgent != "" {
+		tOpts = append(tOpts, grpc.WithUserAgent(tc.UserAgent))
+	}
+	// client-id metadata
+	if tc.ClientID != "" {
+		tOpts = append(tOpts,
+			grpc.WithChainUnaryInterceptor(clientIDUnaryInterceptor(tc.ClientID)),
+			grpc.WithChainStreamInterceptor(clientIDStreamInterceptor(tc.ClientID)),
+		)
+	}
+	// username and password call credentials
+	if tc.GetAuthScheme() == AuthSchemeMetadataPerRPC {
+		tOpts = append(tOpts, grpc.WithPerRPCCredentials(authCredentials{tc: tc}))
+	}
 	// insecure
 	if tc.Insecure != nil && *tc.Insecure {
 		tOpts = append(tOpts,
@@ -125,6 +218,14 @@ func (tc *TargetConfig) GrpcDialOptions() ([]grpc.DialOption, error) {
 		return nil, err
 	}
 	tOpts = append(tOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	// a pin mismatch fails the dial right away,
+	// with the presented certificate fingerprint in the error.
+	if len(tc.TLSPin) > 0 {
+		tOpts = append(tOpts,
+			grpc.WithReturnConnectionError(),
+			grpc.FailOnNonTempDialError(true),
+		)
+	}
 	// token credentials
 	if tc.Token != nil && *tc.Token != "" {
 		tOpts = append(tOpts,
@@ -141,6 +242,118 @@ func (tc *TargetConfig) GrpcDialOptions() ([]grpc.DialOption, error) {
 	return tOpts, nil
 }
 
+// clientIDUnaryInterceptor adds the client-id metadata key to the unary RPCs.
+func clientIDUnaryInterceptor(clientID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, ClientIDMetadataKey, clientID)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// clientIDStreamInterceptor adds the client-id metadata key to the streaming RPCs.
+func clientIDStreamInterceptor(clientID string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = metadata.AppendToOutgoingContext(ctx, ClientIDMetadataKey, clientID)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// GetAuthScheme returns the target auth scheme, metadata if not set.
+func (tc *TargetConfig) GetAuthScheme() string {
+	if tc.AuthScheme == "" {
+		return AuthSchemeMetadata
+	}
+	return tc.AuthScheme
+}
+
+// authMetadata returns the metadata key value pairs of the set username and password.
+func (tc *TargetConfig) authMetadata() []string {
+	kv := make([]string, 0, 4)
+	if tc.Username != nil && *tc.Username != "" {
+		key := tc.AuthUsernameKey
+		if key == "" {
+			key = DefaultAuthUsernameKey
+		}
+		kv = append(kv, key, *tc.Username)
+	}
+	if tc.Password != nil && *tc.Password != "" {
+		key := tc.AuthPasswordKey
+		if key == "" {
+			key = DefaultAuthPasswordKey
+		}
+		kv = append(kv, key, *tc.Password)
+	}
+	return kv
+}
+
+// AuthContext returns ctx with the username and password metadata added,
+// if the target auth scheme is metadata.
+func (tc *TargetConfig) AuthContext(ctx context.Context) context.Context {
+	if tc.GetAuthScheme() != AuthSchemeMetadata {
+		return ctx
+	}
+	kv := tc.authMetadata()
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// authCredentials sends the target username and password as call credentials.
+// They are read on every RPC, a password set after the dial is picked up.
+type authCredentials struct {
+	tc *TargetConfig
+}
+
+func (c authCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	kv := c.tc.authMetadata()
+	md := make(map[string]string, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		md[kv[i]] = kv[i+1]
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity allows the credentials on insecure connections,
+// the same as the metadata auth scheme.
+func (c authCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// Balanced returns true if the target address is resolved
+// to multiple backends the RPCs are balanced across.
+func (tc *TargetConfig) Balanced() bool {
+	return strings.HasPrefix(tc.Address, DNSAddressPrefix)
+}
+
+// SecurityMode returns the effective security mode of the target connection:
+// plaintext, tls-skip-verify if the target certificate is not verified,
+// mtls if a client certificate is presented, tls-verified otherwise.
+func (tc *TargetConfig) SecurityMode() string {
+	switch {
+	case tc.Insecure != nil && *tc.Insecure:
+		return SecurityModePlaintext
+	case tc.SkipVerify != nil && *tc.SkipVerify:
+		return SecurityModeTLSSkipVerify
+	case tc.TLSCert != nil && *tc.TLSCert != "" && tc.TLSKey != nil && *tc.TLSKey != "":
+		return SecurityModeMTLS
+	default:
+		return SecurityModeTLSVerified
+	}
+}
+
+// InGroup returns true if the target is a member of at least one of groups.
+func (tc *TargetConfig) InGroup(groups ...string) bool {
+	for _, g := range groups {
+		for _, tg := range tc.Groups {
+			if tg == g {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (tc *TargetConfig) UsernameString() string {
 	if tc.Username == nil {
 		return notApplicable
@@ -0,0 +1,58 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"io"
+	"log"
+	"time"
+)
+
+const logTimeFlags = log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC
+
+// locationLogWriter prepends the log lines with their date and time
+// in a given location, the standard logger only supports local and UTC.
+type locationLogWriter struct {
+	w     io.Writer
+	loc   *time.Location
+	flags int
+}
+
+// LocationLogWriter returns a writer and the logger flags formatting
+// the date and time set in flags in the location loc.
+// The returned flags have the date and time flags cleared,
+// the returned writer adds them to each line it writes.
+// w and flags are returned as is if loc is nil or the local timezone.
+func LocationLogWriter(w io.Writer, flags int, loc *time.Location) (io.Writer, int) {
+	if loc == nil || loc == time.Local || w == io.Discard ||
+		flags&(log.Ldate|log.Ltime|log.Lmicroseconds) == 0 {
+		return w, flags
+	}
+	return &locationLogWriter{w: w, loc: loc, flags: flags}, flags &^ logTimeFlags
+}
+
+func (l *locationLogWriter) Write(p []byte) (int, error) {
+	now := time.Now().In(l.loc)
+	b := make([]byte, 0, 27+len(p))
+	if l.flags&log.Ldate != 0 {
+		b = now.AppendFormat(b, "2006/01/02 ")
+	}
+	if l.flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		if l.flags&log.Lmicroseconds != 0 {
+			b = now.AppendFormat(b, "15:04:05.000000 ")
+		} else {
+			b = now.AppendFormat(b, "15:04:05 ")
+		}
+	}
+	b = append(b, p...)
+	if _, err := l.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,43 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestLocationLogWriter(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*3600)
+	buf := new(bytes.Buffer)
+	w, flags := LocationLogWriter(buf, DefaultLoggingFlags, loc)
+	if flags != log.Lmsgprefix {
+		t.Fatalf("unexpected flags: %d", flags)
+	}
+	logger := log.New(w, "[gnmic] ", flags)
+	now := time.Now().In(loc)
+	logger.Print("msg")
+	re := regexp.MustCompile(`^(\d{4}/\d{2}/\d{2}) \d{2}:\d{2}:\d{2}\.\d{6} \[gnmic\] msg\n$`)
+	m := re.FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("unexpected log line: %q", buf.String())
+	}
+	// the date is in the writer location, not local.
+	if m[1] != now.Format("2006/01/02") && m[1] != time.Now().In(loc).Format("2006/01/02") {
+		t.Errorf("unexpected date %q, expected %q", m[1], now.Format("2006/01/02"))
+	}
+
+	w, flags = LocationLogWriter(buf, DefaultLoggingFlags, time.Local)
+	if w != buf || flags != DefaultLoggingFlags {
+		t.Errorf("expected the local location to keep the writer and flags")
+	}
+}
@@ -13,64 +13,60 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 )
 
+// CertificatePinPrefix is the prefix of the certificate pins,
+// the only supported fingerprint algorithm is SHA-256.
+const CertificatePinPrefix = "sha256:"
+
 // NewTLSConfig generates a *tls.Config based on given CA, certificate, key files and skipVerify flag
 // if certificate and key are missing a self signed key pair is generated.
 // The certificates paths can be local or remote, http(s) and (s)ftp are supported for remote files.
+// A certificate or CA loading failure is returned as an error naming the file and the problem.
 func NewTLSConfig(ca, cert, key string, skipVerify, genSelfSigned bool) (*tls.Config, error) {
-	if !(skipVerify || ca != "" || (cert != "" && key != "")) {
+	return newTLSConfig(ca, cert, key, skipVerify, genSelfSigned, nil)
+}
+
+// NewTLSConfigIgnoreErrors generates a *tls.Config like NewTLSConfig,
+// but a certificate or CA loading failure is passed to onErr
+// and the corresponding setting is skipped instead of failing:
+// no client certificate is sent and the system roots are used.
+func NewTLSConfigIgnoreErrors(ca, cert, key string, skipVerify, genSelfSigned bool, onErr func(error)) (*tls.Config, error) {
+	if onErr == nil {
+		onErr = func(error) {}
+	}
+	return newTLSConfig(ca, cert, key, skipVerify, genSelfSigned, onErr)
+}
+
+func newTLSConfig(ca, cert, key string, skipVerify, genSelfSigned bool, onErr func(error)) (*tls.Config, error) {
+	if !(skipVerify || ca != "" || cert != "" || key != "") {
 		return nil, nil
 	}
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: skipVerify,
 	}
-	if cert != "" && key != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		var certBytes, keyBytes []byte
-
-		errCh := make(chan error, 2)
-		wg := new(sync.WaitGroup)
-		wg.Add(2)
-		go func() {
-			defer wg.Done()
-			var err error
-			certBytes, err = ReadFile(ctx, cert)
-			if err != nil {
-				errCh <- err
-				return
-			}
-		}()
-		go func() {
-			defer wg.Done()
-			var err error
-			keyBytes, err = ReadFile(ctx, key)
-			if err != nil {
-				errCh <- err
-				return
-			}
-		}()
-		wg.Wait()
-		close(errCh)
-		for err := range errCh {
-			return nil, err
-		}
-		certificate, err := tls.X509KeyPair(certBytes, keyBytes)
-		if err != nil {
+	if cert != "" || key != "" {
+		certificate, err := loadKeyPair(cert, key)
+		switch {
+		case err == nil:
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		case onErr == nil:
 			return nil, err
+		default:
+			onErr(err)
 		}
-
-		tlsConfig.Certificates = []tls.Certificate{certificate}
 	} else if genSelfSigned {
 		cert, err := SelfSignedCerts()
 		if err != nil {
@@ -79,21 +75,80 @@ func NewTLSConfig(ca, cert, key string, skipVerify, genSelfSigned bool) (*tls.Co
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 	if ca != "" {
-		certPool := x509.NewCertPool()
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		caFile, err := ReadFile(ctx, ca)
-		if err != nil {
+		certPool, err := loadCertPool(ca)
+		switch {
+		case err == nil:
+			tlsConfig.RootCAs = certPool
+		case onErr == nil:
 			return nil, err
+		default:
+			onErr(err)
 		}
-		if ok := certPool.AppendCertsFromPEM(caFile); !ok {
-			return nil, errors.New("failed to append certificate")
-		}
-		tlsConfig.RootCAs = certPool
 	}
 	return tlsConfig, nil
 }
 
+// loadKeyPair reads the certificate and key files and parses them as a key pair.
+func loadKeyPair(cert, key string) (tls.Certificate, error) {
+	if cert == "" {
+		return tls.Certificate{}, fmt.Errorf("TLS key %q is set without a certificate", key)
+	}
+	if key == "" {
+		return tls.Certificate{}, fmt.Errorf("TLS certificate %q is set without a key", cert)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var certBytes, keyBytes []byte
+
+	errCh := make(chan error, 2)
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var err error
+		certBytes, err = ReadFile(ctx, cert)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to read TLS certificate file %q: %v", cert, err)
+			return
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		keyBytes, err = ReadFile(ctx, key)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to read TLS key file %q: %v", key, err)
+			return
+		}
+	}()
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return tls.Certificate{}, err
+	}
+	certificate, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load TLS key pair from certificate %q and key %q: %v", cert, key, err)
+	}
+	return certificate, nil
+}
+
+// loadCertPool reads the CA file and returns a pool with its PEM certificates.
+func loadCertPool(ca string) (*x509.CertPool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	caFile, err := ReadFile(ctx, ca)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA file %q: %v", ca, err)
+	}
+	certPool := x509.NewCertPool()
+	if ok := certPool.AppendCertsFromPEM(caFile); !ok {
+		return nil, fmt.Errorf("TLS CA file %q does not contain any valid PEM certificate", ca)
+	}
+	return certPool, nil
+}
+
 func SelfSignedCerts() (tls.Certificate, error) {
 	notBefore := time.Now()
 	notAfter := notBefore.Add(365 * 24 * time.Hour)
@@ -128,3 +183,52 @@ func SelfSignedCerts() (tls.Certificate, error) {
 	pem.Encode(keyBuff, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
 	return tls.X509KeyPair(certBuff.Bytes(), keyBuff.Bytes())
 }
+
+// ParseCertificatePin parses a certificate pin formatted as sha256:<fingerprint>,
+// the fingerprint is hex encoded, its bytes can be separated by colons.
+func ParseCertificatePin(pin string) ([]byte, error) {
+	if !strings.HasPrefix(strings.ToLower(pin), CertificatePinPrefix) {
+		return nil, fmt.Errorf("invalid certificate pin %q, expected format %s<fingerprint>", pin, CertificatePinPrefix)
+	}
+	fp := strings.ReplaceAll(pin[len(CertificatePinPrefix):], ":", "")
+	b, err := hex.DecodeString(fp)
+	if err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate pin %q, expected a hex encoded SHA-256 fingerprint", pin)
+	}
+	return b, nil
+}
+
+// CertificateFingerprint returns the pin of the DER encoded certificate raw,
+// formatted as sha256:<hex fingerprint>.
+func CertificateFingerprint(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return CertificatePinPrefix + hex.EncodeToString(sum[:])
+}
+
+// PinCertificate makes tlsConfig accept the server certificate only if its fingerprint
+// matches one of the pins, instead of verifying it against the root CAs.
+func PinCertificate(tlsConfig *tls.Config, pins []string) error {
+	fps := make([][]byte, 0, len(pins))
+	for _, pin := range pins {
+		fp, err := ParseCertificatePin(pin)
+		if err != nil {
+			return err
+		}
+		fps = append(fps, fp)
+	}
+	// the chain is not verified, the pin is the trust anchor.
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("certificate pin mismatch: no certificate presented")
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		for _, fp := range fps {
+			if bytes.Equal(sum[:], fp) {
+				return nil
+			}
+		}
+		return fmt.Errorf("certificate pin mismatch: presented certificate fingerprint is %s", CertificateFingerprint(rawCerts[0]))
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// © 2022 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestKeyPair writes a self-signed certificate and its key
+// as PEM files in dir and returns their paths.
+func writeTestKeyPair(t *testing.T, dir, name string) (string, string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+".key")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	cert1, _ := writeTestKeyPair(t, dir, "client1")
+	_, key2 := writeTestKeyPair(t, dir, "client2")
+	notPEM := filepath.Join(dir, "ca.txt")
+	err := os.WriteFile(notPEM, []byte("not a certificate"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a directory cannot be read as a file
+	unreadable := filepath.Join(dir, "unreadable")
+	err = os.Mkdir(unreadable, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		ca, cert, key string
+		errContains   []string
+	}{
+		"missing_ca": {
+			ca:          filepath.Join(dir, "missing.pem"),
+			errContains: []string{"TLS CA file", "missing.pem"},
+		},
+		"ca_without_certificate": {
+			ca:          notPEM,
+			errContains: []string{notPEM, "does not contain any valid PEM certificate"},
+		},
+		"unreadable_ca": {
+			ca:          unreadable,
+			errContains: []string{"failed to read TLS CA file", unreadable},
+		},
+		"mismatched_key_pair": {
+			cert:        cert1,
+			key:         key2,
+			errContains: []string{"failed to load TLS key pair", cert1, key2},
+		},
+		"unreadable_key": {
+			cert:        cert1,
+			key:         unreadable,
+			errContains: []string{"failed to read TLS key file", unreadable},
+		},
+		"cert_without_key": {
+			cert:        cert1,
+			errContains: []string{cert1, "without a key"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTLSConfig(tt.ca, tt.cert, tt.key, false, false)
+			if err == nil {
+				t.Fatalf("failed at item %q: expected an error", name)
+			}
+			for _, s := range tt.errContains {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("failed at item %q: expected the error to contain %q, got: %v", name, s, err)
+				}
+			}
+			// lenient mode
+			var ignored []error
+			tlsConfig, err := NewTLSConfigIgnoreErrors(tt.ca, tt.cert, tt.key, false, false, func(err error) {
+				ignored = append(ignored, err)
+			})
+			if err != nil {
+				t.Fatalf("failed at item %q: unexpected error with ignored cert errors: %v", name, err)
+			}
+			if len(ignored) != 1 {
+				t.Errorf("failed at item %q: expected 1 ignored error, got %v", name, ignored)
+			}
+			if tlsConfig == nil || len(tlsConfig.Certificates) != 0 || tlsConfig.RootCAs != nil {
+				t.Errorf("failed at item %q: expected a TLS config without certificates and CA: %+v", name, tlsConfig)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeTestKeyPair(t, dir, "client")
+	tlsConfig, err := NewTLSConfig(cert, cert, key, false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs == nil {
+		t.Errorf("expected a client certificate and a CA pool: %+v", tlsConfig)
+	}
+}
+
+var parseCertificatePinTestSet = map[string]struct {
+	in    string
+	valid bool
+}{
+	"hex": {
+		in:    "sha256:" + strings.Repeat("ab", 32),
+		valid: true,
+	},
+	"colons_upper": {
+		in:    "SHA256:" + strings.TrimSuffix(strings.Repeat("AB:", 32), ":"),
+		valid: true,
+	},
+	"missing_prefix": {
+		in: strings.Repeat("ab", 32),
+	},
+	"sha1": {
+		in: "sha1:" + strings.Repeat("ab", 20),
+	},
+	"short": {
+		in: "sha256:" + strings.Repeat("ab", 20),
+	},
+	"not_hex": {
+		in: "sha256:" + strings.Repeat("zz", 32),
+	},
+}
+
+func TestParseCertificatePin(t *testing.T) {
+	for name, item := range parseCertificatePinTestSet {
+		t.Run(name, func(t *testing.T) {
+			b, err := ParseCertificatePin(item.in)
+			if item.valid && (err != nil || len(b) != 32) {
+				t.Errorf("failed at item %q: unexpected error: %v", name, err)
+			}
+			if !item.valid && err == nil {
+				t.Errorf("failed at item %q: expected an error", name)
+			}
+		})
+	}
+}
+
+func TestPinCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "router1")
+	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := CertificateFingerprint(serverCert.Certificate[0])
+	otherPin := CertificatePinPrefix + strings.Repeat("00", 32)
+
+	handshake := func(pins []string) error {
+		clientCfg := new(tls.Config)
+		err := PinCertificate(clientCfg, pins)
+		if err != nil {
+			return err
+		}
+		l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		go func() {
+			sconn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer sconn.Close()
+			sconn.(*tls.Conn).Handshake()
+		}()
+		cconn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cconn.Close()
+		return tls.Client(cconn, clientCfg).Handshake()
+	}
+	// the self-signed certificate is accepted if one of the pins matches
+	err = handshake([]string{otherPin, strings.ToUpper(fingerprint)})
+	if err != nil {
+		t.Errorf("unexpected handshake error: %v", err)
+	}
+	err = handshake([]string{otherPin})
+	if err == nil || !strings.Contains(err.Error(), fingerprint) {
+		t.Errorf("expected a pin mismatch error with the fingerprint %s, got: %v", fingerprint, err)
+	}
+	err = handshake([]string{"sha256:abc"})
+	if err == nil {
+		t.Errorf("expected an invalid pin error")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"log"
 	"net"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -67,11 +68,19 @@ func GnmiPathToXPath(p *gnmi.Path, noKeys bool) string {
 	for i, pe := range elems {
 		sb.WriteString(pe.GetName())
 		if !noKeys {
-			for k, v := range pe.GetKey() {
+			keys := pe.GetKey()
+			// sort the key names so that the same path always
+			// produces the same xpath string
+			names := make([]string, 0, len(keys))
+			for k := range keys {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			for _, k := range names {
 				sb.WriteString("[")
 				sb.WriteString(k)
 				sb.WriteString("=")
-				sb.WriteString(v)
+				sb.WriteString(keys[k])
 				sb.WriteString("]")
 			}
 		}